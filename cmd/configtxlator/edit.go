@@ -0,0 +1,262 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/alecthomas/units"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-config/protolator/protoext/ordererext"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/internal/configtxlator/edit"
+	"github.com/pkg/errors"
+)
+
+// edit command line flags
+var (
+	editCmd = app.Command("edit", "Modifies the config contained in a config block and emits the config update envelope to be signed.")
+
+	editAddOrg           = editCmd.Command("add_org", "Adds an organization, as printed by 'configtxgen -printOrg', to the config.")
+	editAddOrgSource     = editAddOrg.Flag("block", "A file containing the config block.").Required().File()
+	editAddOrgName       = editAddOrg.Flag("name", "The name of the organization.").Required().String()
+	editAddOrgDefinition = editAddOrg.Flag("org", "A file containing the JSON definition of the organization.").Required().File()
+	editAddOrgSection    = editAddOrg.Flag("section", "The section of the config to add the organization to.").Default("application").Enum("application", "orderer", "consortium")
+	editAddOrgConsortium = editAddOrg.Flag("consortium", "The name of the consortium, for the consortium section.").String()
+	editAddOrgDest       = editAddOrg.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	editRemoveOrg           = editCmd.Command("remove_org", "Removes an organization from the config.")
+	editRemoveOrgSource     = editRemoveOrg.Flag("block", "A file containing the config block.").Required().File()
+	editRemoveOrgName       = editRemoveOrg.Flag("name", "The name of the organization.").Required().String()
+	editRemoveOrgSection    = editRemoveOrg.Flag("section", "The section of the config to remove the organization from.").Default("application").Enum("application", "orderer", "consortium")
+	editRemoveOrgConsortium = editRemoveOrg.Flag("consortium", "The name of the consortium, for the consortium section.").String()
+	editRemoveOrgDest       = editRemoveOrg.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	editAnchorPeers       = editCmd.Command("anchor_peers", "Sets the anchor peers of an application organization.")
+	editAnchorPeersSource = editAnchorPeers.Flag("block", "A file containing the config block.").Required().File()
+	editAnchorPeersOrg    = editAnchorPeers.Flag("name", "The name of the organization.").Required().String()
+	editAnchorPeersPeers  = editAnchorPeers.Flag("peer", "The address of an anchor peer as <host>:<port> (may be repeated).").Strings()
+	editAnchorPeersDest   = editAnchorPeers.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	editBatch                  = editCmd.Command("batch", "Changes the batch size and batch timeout of the orderer.")
+	editBatchSource            = editBatch.Flag("block", "A file containing the config block.").Required().File()
+	editBatchMaxMessageCount   = editBatch.Flag("max-message-count", "The maximum number of messages in a batch.").Uint32()
+	editBatchAbsoluteMaxBytes  = editBatch.Flag("absolute-max-bytes", "The absolute maximum size of a batch, e.g. '10MB'.").Bytes()
+	editBatchPreferredMaxBytes = editBatch.Flag("preferred-max-bytes", "The preferred maximum size of a batch, e.g. '2MB'.").Bytes()
+	editBatchTimeout           = editBatch.Flag("timeout", "The amount of time to wait before creating a batch, e.g. '2s'.").String()
+	editBatchDest              = editBatch.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	editAddConsenter              = editCmd.Command("add_consenter", "Adds a consenter to the etcdraft consensus metadata.")
+	editAddConsenterSource        = editAddConsenter.Flag("block", "A file containing the config block.").Required().File()
+	editAddConsenterHost          = editAddConsenter.Flag("host", "The host of the consenter.").Required().String()
+	editAddConsenterPort          = editAddConsenter.Flag("port", "The cluster port of the consenter.").Required().Uint32()
+	editAddConsenterClientTLSCert = editAddConsenter.Flag("client-tls-cert", "A file containing the PEM encoded client TLS certificate of the consenter.").Required().File()
+	editAddConsenterServerTLSCert = editAddConsenter.Flag("server-tls-cert", "A file containing the PEM encoded server TLS certificate of the consenter.").Required().File()
+	editAddConsenterDest          = editAddConsenter.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	editRemoveConsenter       = editCmd.Command("remove_consenter", "Removes a consenter from the etcdraft consensus metadata.")
+	editRemoveConsenterSource = editRemoveConsenter.Flag("block", "A file containing the config block.").Required().File()
+	editRemoveConsenterHost   = editRemoveConsenter.Flag("host", "The host of the consenter.").Required().String()
+	editRemoveConsenterPort   = editRemoveConsenter.Flag("port", "The cluster port of the consenter.").Required().Uint32()
+	editRemoveConsenterDest   = editRemoveConsenter.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	editCapabilities        = editCmd.Command("capabilities", "Enables and disables capabilities.")
+	editCapabilitiesSource  = editCapabilities.Flag("block", "A file containing the config block.").Required().File()
+	editCapabilitiesSection = editCapabilities.Flag("section", "The section of the config whose capabilities are modified.").Default("channel").Enum("channel", "orderer", "application")
+	editCapabilitiesAdd     = editCapabilities.Flag("add", "A capability to enable, e.g. 'V2_0' (may be repeated).").Strings()
+	editCapabilitiesRemove  = editCapabilities.Flag("remove", "A capability to disable (may be repeated).").Strings()
+	editCapabilitiesDest    = editCapabilities.Flag("output", "A file to write the config update envelope to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+)
+
+// runEdit executes the edit sub-command selected on the command line
+func runEdit(command string) {
+	var err error
+	switch command {
+	case editAddOrg.FullCommand():
+		defer (*editAddOrgDefinition).Close()
+		err = editConfig(*editAddOrgSource, *editAddOrgDest, func(e *edit.Editor) error {
+			path, err := orgSectionPath(*editAddOrgSection, *editAddOrgConsortium)
+			if err != nil {
+				return err
+			}
+			org := &cb.ConfigGroup{}
+			if err := protolator.DeepUnmarshalJSON(*editAddOrgDefinition, &ordererext.DynamicOrdererOrgGroup{ConfigGroup: org}); err != nil {
+				return errors.Wrap(err, "error decoding organization definition")
+			}
+			return e.AddOrg(path, *editAddOrgName, org)
+		})
+	case editRemoveOrg.FullCommand():
+		err = editConfig(*editRemoveOrgSource, *editRemoveOrgDest, func(e *edit.Editor) error {
+			path, err := orgSectionPath(*editRemoveOrgSection, *editRemoveOrgConsortium)
+			if err != nil {
+				return err
+			}
+			return e.RemoveOrg(path, *editRemoveOrgName)
+		})
+	case editAnchorPeers.FullCommand():
+		err = editConfig(*editAnchorPeersSource, *editAnchorPeersDest, func(e *edit.Editor) error {
+			anchorPeers, err := parseAnchorPeers(*editAnchorPeersPeers)
+			if err != nil {
+				return err
+			}
+			return e.SetAnchorPeers(*editAnchorPeersOrg, anchorPeers)
+		})
+	case editBatch.FullCommand():
+		err = editConfig(*editBatchSource, *editBatchDest, func(e *edit.Editor) error {
+			if *editBatchMaxMessageCount != 0 || *editBatchAbsoluteMaxBytes != 0 || *editBatchPreferredMaxBytes != 0 {
+				absoluteMaxBytes, err := batchBytes("absolute-max-bytes", *editBatchAbsoluteMaxBytes)
+				if err != nil {
+					return err
+				}
+				preferredMaxBytes, err := batchBytes("preferred-max-bytes", *editBatchPreferredMaxBytes)
+				if err != nil {
+					return err
+				}
+				err = e.SetBatchSize(*editBatchMaxMessageCount, absoluteMaxBytes, preferredMaxBytes)
+				if err != nil {
+					return err
+				}
+			}
+			if *editBatchTimeout != "" {
+				return e.SetBatchTimeout(*editBatchTimeout)
+			}
+			return nil
+		})
+	case editAddConsenter.FullCommand():
+		defer (*editAddConsenterClientTLSCert).Close()
+		defer (*editAddConsenterServerTLSCert).Close()
+		err = editConfig(*editAddConsenterSource, *editAddConsenterDest, func(e *edit.Editor) error {
+			clientTLSCert, err := ioutil.ReadAll(*editAddConsenterClientTLSCert)
+			if err != nil {
+				return errors.Wrap(err, "error reading client TLS certificate")
+			}
+			serverTLSCert, err := ioutil.ReadAll(*editAddConsenterServerTLSCert)
+			if err != nil {
+				return errors.Wrap(err, "error reading server TLS certificate")
+			}
+			return e.AddConsenter(&etcdraft.Consenter{
+				Host:          *editAddConsenterHost,
+				Port:          *editAddConsenterPort,
+				ClientTlsCert: clientTLSCert,
+				ServerTlsCert: serverTLSCert,
+			})
+		})
+	case editRemoveConsenter.FullCommand():
+		err = editConfig(*editRemoveConsenterSource, *editRemoveConsenterDest, func(e *edit.Editor) error {
+			return e.RemoveConsenter(*editRemoveConsenterHost, *editRemoveConsenterPort)
+		})
+	case editCapabilities.FullCommand():
+		err = editConfig(*editCapabilitiesSource, *editCapabilitiesDest, func(e *edit.Editor) error {
+			var path []string
+			switch *editCapabilitiesSection {
+			case "orderer":
+				path = []string{channelconfig.OrdererGroupKey}
+			case "application":
+				path = []string{channelconfig.ApplicationGroupKey}
+			}
+			return e.SetCapabilities(path, *editCapabilitiesAdd, *editCapabilitiesRemove)
+		})
+	}
+
+	if err != nil {
+		app.Fatalf("Error editing config: %s", err)
+	}
+}
+
+// editConfig applies the modification to the config contained in the
+// config block read from input, and writes the resulting config update
+// envelope to output
+func editConfig(input, output *os.File, modify func(*edit.Editor) error) error {
+	defer input.Close()
+	defer output.Close()
+
+	in, err := ioutil.ReadAll(input)
+	if err != nil {
+		return errors.Wrapf(err, "error reading config block")
+	}
+
+	block := &cb.Block{}
+	err = proto.Unmarshal(in, block)
+	if err != nil {
+		return errors.Wrapf(err, "error unmarshaling config block")
+	}
+
+	editor, err := edit.NewEditor(block)
+	if err != nil {
+		return err
+	}
+
+	if err := modify(editor); err != nil {
+		return err
+	}
+
+	envelope, err := editor.Envelope()
+	if err != nil {
+		return errors.WithMessage(err, "error computing config update")
+	}
+
+	out, err := proto.Marshal(envelope)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling config update envelope")
+	}
+
+	_, err = output.Write(out)
+	if err != nil {
+		return errors.Wrapf(err, "error writing config update envelope")
+	}
+
+	return nil
+}
+
+func orgSectionPath(section, consortium string) ([]string, error) {
+	switch section {
+	case "orderer":
+		return []string{channelconfig.OrdererGroupKey}, nil
+	case "consortium":
+		if consortium == "" {
+			return nil, errors.New("the consortium must be specified for the consortium section")
+		}
+		return []string{channelconfig.ConsortiumsGroupKey, consortium}, nil
+	default:
+		return []string{channelconfig.ApplicationGroupKey}, nil
+	}
+}
+
+func parseAnchorPeers(addresses []string) ([]*pb.AnchorPeer, error) {
+	var anchorPeers []*pb.AnchorPeer
+	for _, address := range addresses {
+		host, portString, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid anchor peer address '%s'", address)
+		}
+
+		port, err := strconv.ParseUint(portString, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port in anchor peer address '%s'", address)
+		}
+
+		anchorPeers = append(anchorPeers, &pb.AnchorPeer{Host: host, Port: int32(port)})
+	}
+	return anchorPeers, nil
+}
+
+// batchBytes converts the size given to a batch size flag to the size of the batch
+// size config, rejecting the sizes which don't fit in it
+func batchBytes(flag string, size units.Base2Bytes) (uint32, error) {
+	if size < 0 || size > math.MaxUint32 {
+		return 0, errors.Errorf("invalid %s '%s', expected less than 4GB", flag, size)
+	}
+	return uint32(size), nil
+}
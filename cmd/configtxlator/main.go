@@ -7,12 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-config/protolator"
@@ -22,10 +25,17 @@ import (
 	_ "github.com/hyperledger/fabric-protos-go/orderer"
 	_ "github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
 	_ "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/internal/configtxlator/metadata"
+	"github.com/hyperledger/fabric/internal/configtxlator/policy"
 	"github.com/hyperledger/fabric/internal/configtxlator/rest"
 	"github.com/hyperledger/fabric/internal/configtxlator/update"
+	"github.com/hyperledger/fabric/internal/configtxlator/workflow"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
 
 	"github.com/gorilla/handlers"
 	"github.com/pkg/errors"
@@ -41,6 +51,9 @@ var (
 	port     = start.Flag("port", "The port on which the REST server will listen").Default("7059").Int()
 	cors     = start.Flag("CORS", "Allowable CORS domains, e.g. '*' or 'www.example.com' (may be repeated).").Strings()
 
+	workflowEnabled = start.Flag("workflow", "Enable the REST API which collects the signatures of pending config updates.").Bool()
+	workflowDir     = start.Flag("workflow-dir", "A directory in which pending config updates are persisted. If unset, they are kept in memory only.").String()
+
 	protoEncode       = app.Command("proto_encode", "Converts a JSON document to protobuf.")
 	protoEncodeType   = protoEncode.Flag("type", "The type of protobuf structure to encode to.  For example, 'common.Config'.").Required().String()
 	protoEncodeSource = protoEncode.Flag("input", "A file containing the JSON document.").Default(os.Stdin.Name()).File()
@@ -57,6 +70,32 @@ var (
 	computeUpdateChannelID = computeUpdate.Flag("channel_id", "The name of the channel for this update.").Required().String()
 	computeUpdateDest      = computeUpdate.Flag("output", "A file to write the JSON document to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 
+	policyCmd = app.Command("policy", "Inspects and simulates the policies of a channel config block.")
+
+	policyList       = policyCmd.Command("list", "Lists every policy defined in a config block.")
+	policyListSource = policyList.Flag("block", "A file containing the config block.").Required().File()
+	policyListDest   = policyList.Flag("output", "A file to write the output to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	policyEvaluate          = policyCmd.Command("evaluate", "Evaluates a policy against a set of certificates and MSP roles.")
+	policyEvaluateSource    = policyEvaluate.Flag("block", "A file containing the config block.").Required().File()
+	policyEvaluatePath      = policyEvaluate.Flag("path", "The path of the channel config policy to evaluate, e.g. '/Channel/Application/Admins'.").String()
+	policyEvaluateSignature = policyEvaluate.Flag("signature-policy", "A signature policy to evaluate instead of a channel config policy, e.g. \"AND('Org1MSP.peer','Org2MSP.peer')\".").String()
+	policyEvaluateRoles     = policyEvaluate.Flag("role", "An MSP role to evaluate the policy against, e.g. 'Org1MSP.admin' (may be repeated).").Strings()
+	policyEvaluateCerts     = policyEvaluate.Flag("cert", "A PEM certificate to evaluate the policy against, as <MSP ID>:<file> (may be repeated).").Strings()
+	policyEvaluateDest      = policyEvaluate.Flag("output", "A file to write the output to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	policyInquire          = policyCmd.Command("inquire", "Prints the minimal combinations of organizations which may satisfy a policy.")
+	policyInquireSource    = policyInquire.Flag("block", "A file containing the config block.").Required().File()
+	policyInquirePath      = policyInquire.Flag("path", "The path of the channel config policy to inquire, e.g. '/Channel/Application/Admins'.").String()
+	policyInquireSignature = policyInquire.Flag("signature-policy", "A signature policy to inquire instead of a channel config policy.").String()
+	policyInquireDest      = policyInquire.Flag("output", "A file to write the output to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
+	lint             = app.Command("lint", "Checks a channel config block for expiring certificates, unsupported capabilities and other problems.")
+	lintSource       = lint.Flag("block", "A file containing the config block.").Required().File()
+	lintExpiryWindow = lint.Flag("expiry-window", "Report certificates expiring within this period, e.g. '720h'.").Default(channelconfig.DefaultLintExpiryWindow.String()).Duration()
+	lintFormat       = lint.Flag("format", "The output format.").Default("text").Enum("text", "json")
+	lintDest         = lint.Flag("output", "A file to write the findings to.").Default(os.Stdout.Name()).OpenFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+
 	version = app.Command("version", "Show version information")
 )
 
@@ -64,10 +103,10 @@ var logger = flogging.MustGetLogger("configtxlator")
 
 func main() {
 	kingpin.Version("0.0.1")
-	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	switch command := kingpin.MustParse(app.Parse(os.Args[1:])); command {
 	// "start" command
 	case start.FullCommand():
-		startServer(fmt.Sprintf("%s:%d", *hostname, *port), *cors)
+		startServer(fmt.Sprintf("%s:%d", *hostname, *port), *cors, *workflowEnabled, *workflowDir)
 	// "proto_encode" command
 	case protoEncode.FullCommand():
 		defer (*protoEncodeSource).Close()
@@ -91,14 +130,49 @@ func main() {
 		if err != nil {
 			app.Fatalf("Error computing update: %s", err)
 		}
+	case policyList.FullCommand():
+		defer (*policyListSource).Close()
+		defer (*policyListDest).Close()
+		err := listPolicies(*policyListSource, *policyListDest)
+		if err != nil {
+			app.Fatalf("Error listing policies: %s", err)
+		}
+	case policyEvaluate.FullCommand():
+		defer (*policyEvaluateSource).Close()
+		defer (*policyEvaluateDest).Close()
+		err := evaluatePolicy(*policyEvaluateSource, *policyEvaluateDest, *policyEvaluatePath, *policyEvaluateSignature, *policyEvaluateRoles, *policyEvaluateCerts)
+		if err != nil {
+			app.Fatalf("Error evaluating policy: %s", err)
+		}
+	case policyInquire.FullCommand():
+		defer (*policyInquireSource).Close()
+		defer (*policyInquireDest).Close()
+		err := inquirePolicy(*policyInquireSource, *policyInquireDest, *policyInquirePath, *policyInquireSignature)
+		if err != nil {
+			app.Fatalf("Error inquiring policy: %s", err)
+		}
+	// "lint" command
+	case lint.FullCommand():
+		defer (*lintSource).Close()
+		defer (*lintDest).Close()
+		errorCount, err := lintConfig(*lintSource, *lintDest, *lintExpiryWindow, *lintFormat)
+		if err != nil {
+			app.Fatalf("Error linting config: %s", err)
+		}
+		if errorCount > 0 {
+			app.Fatalf("Config has %d error(s)", errorCount)
+		}
 	// "version" command
 	case version.FullCommand():
 		printVersion()
+	// "edit" sub-commands
+	default:
+		runEdit(command)
 	}
 
 }
 
-func startServer(address string, cors []string) {
+func startServer(address string, cors []string, workflowEnabled bool, workflowDir string) {
 	var err error
 
 	listener, err := net.Listen("tcp", address)
@@ -106,17 +180,28 @@ func startServer(address string, cors []string) {
 		app.Fatalf("Could not bind to address '%s': %s", address, err)
 	}
 
+	router := rest.NewRouter()
+	// configtxlator only exposes POST APIs unless the workflow is enabled
+	allowedMethods := []string{http.MethodPost}
+	if workflowEnabled {
+		service, err := workflow.NewService(workflowDir, factory.GetDefault())
+		if err != nil {
+			app.Fatalf("Could not start the config update workflow: %s", err)
+		}
+		router = rest.NewWorkflowRouter(service)
+		allowedMethods = append(allowedMethods, http.MethodGet, http.MethodDelete)
+		logger.Info("Config update workflow enabled")
+	}
+
 	if len(cors) > 0 {
 		origins := handlers.AllowedOrigins(cors)
-		// Note, configtxlator only exposes POST APIs for the time being, this
-		// list will need to be expanded if new non-POST APIs are added
-		methods := handlers.AllowedMethods([]string{http.MethodPost})
+		methods := handlers.AllowedMethods(allowedMethods)
 		headers := handlers.AllowedHeaders([]string{"Content-Type"})
 		logger.Infof("Serving HTTP requests on %s with CORS %v", listener.Addr(), cors)
-		err = http.Serve(listener, handlers.CORS(origins, methods, headers)(rest.NewRouter()))
+		err = http.Serve(listener, handlers.CORS(origins, methods, headers)(router))
 	} else {
 		logger.Infof("Serving HTTP requests on %s", listener.Addr())
-		err = http.Serve(listener, rest.NewRouter())
+		err = http.Serve(listener, router)
 	}
 
 	app.Fatalf("Error starting server:[%s]\n", err)
@@ -218,3 +303,184 @@ func computeUpdt(original, updated, output *os.File, channelID string) error {
 
 	return nil
 }
+
+func newPolicySimulator(input *os.File) (*policy.Simulator, error) {
+	in, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading config block")
+	}
+
+	block := &cb.Block{}
+	err = proto.Unmarshal(in, block)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error unmarshaling config block")
+	}
+
+	return policy.NewSimulator(block, factory.GetDefault())
+}
+
+func selectPolicy(simulator *policy.Simulator, path, signaturePolicy string) (policies.Policy, error) {
+	switch {
+	case path != "" && signaturePolicy != "":
+		return nil, errors.New("only one of path and signature policy may be specified")
+	case path != "":
+		return simulator.ChannelPolicy(path)
+	case signaturePolicy != "":
+		return simulator.SignaturePolicy(signaturePolicy)
+	default:
+		return nil, errors.New("either a path or a signature policy must be specified")
+	}
+}
+
+func listPolicies(input, output *os.File) error {
+	simulator, err := newPolicySimulator(input)
+	if err != nil {
+		return err
+	}
+
+	infos, err := simulator.Policies()
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		_, err = fmt.Fprintf(output, "%s\t%s\t%s\t(mod_policy: %s)\n", info.Path, info.Type, info.Rule, info.ModPolicy)
+		if err != nil {
+			return errors.Wrapf(err, "error writing output")
+		}
+	}
+
+	return nil
+}
+
+func evaluatePolicy(input, output *os.File, path, signaturePolicy string, roles, certs []string) error {
+	simulator, err := newPolicySimulator(input)
+	if err != nil {
+		return err
+	}
+
+	pol, err := selectPolicy(simulator, path, signaturePolicy)
+	if err != nil {
+		return err
+	}
+
+	var identities []msp.Identity
+	for _, role := range roles {
+		identity, err := policy.RoleIdentity(role)
+		if err != nil {
+			return err
+		}
+		identities = append(identities, identity)
+	}
+
+	for _, cert := range certs {
+		i := strings.Index(cert, ":")
+		if i <= 0 {
+			return errors.Errorf("invalid certificate '%s', expected <MSP ID>:<file>", cert)
+		}
+		pemBytes, err := ioutil.ReadFile(cert[i+1:])
+		if err != nil {
+			return errors.Wrapf(err, "error reading certificate")
+		}
+		identity, err := simulator.CertIdentity(cert[:i], pemBytes)
+		if err != nil {
+			return err
+		}
+		identities = append(identities, identity)
+	}
+
+	_, err = fmt.Fprintln(output, simulator.Evaluate(pol, identities))
+	if err != nil {
+		return errors.Wrapf(err, "error writing output")
+	}
+
+	return nil
+}
+
+func inquirePolicy(input, output *os.File, path, signaturePolicy string) error {
+	simulator, err := newPolicySimulator(input)
+	if err != nil {
+		return err
+	}
+
+	pol, err := selectPolicy(simulator, path, signaturePolicy)
+	if err != nil {
+		return err
+	}
+
+	combinations, err := policy.OrgCombinations(pol)
+	if err != nil {
+		return err
+	}
+
+	if len(combinations) == 0 {
+		_, err = fmt.Fprintln(output, "No satisfying combination of organizations found")
+		if err != nil {
+			return errors.Wrapf(err, "error writing output")
+		}
+		return nil
+	}
+
+	for _, combination := range combinations {
+		_, err = fmt.Fprintln(output, strings.Join(combination, " + "))
+		if err != nil {
+			return errors.Wrapf(err, "error writing output")
+		}
+	}
+
+	return nil
+}
+
+// lintConfig writes the findings of the channel config linter for the
+// config block and returns the number of findings of severity error
+func lintConfig(input, output *os.File, expiryWindow time.Duration, format string) (int, error) {
+	in, err := ioutil.ReadAll(input)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error reading config block")
+	}
+
+	block := &cb.Block{}
+	err = proto.Unmarshal(in, block)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error unmarshaling config block")
+	}
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return 0, errors.WithMessage(err, "error extracting config envelope from block")
+	}
+
+	bundle, err := channelconfig.NewBundleFromEnvelope(envelope, factory.GetDefault())
+	if err != nil {
+		return 0, errors.WithMessage(err, "error creating channel config")
+	}
+
+	findings := channelconfig.Lint(bundle, channelconfig.LintOptions{ExpiryWindow: expiryWindow})
+
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == channelconfig.LintError {
+			errorCount++
+		}
+	}
+
+	if format == "json" {
+		if findings == nil {
+			findings = []*channelconfig.LintFinding{}
+		}
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "\t")
+		if err := encoder.Encode(findings); err != nil {
+			return 0, errors.Wrapf(err, "error writing output")
+		}
+		return errorCount, nil
+	}
+
+	for _, finding := range findings {
+		if _, err := fmt.Fprintln(output, finding); err != nil {
+			return 0, errors.Wrapf(err, "error writing output")
+		}
+	}
+
+	return errorCount, nil
+}
@@ -8,11 +8,14 @@ package cauthdsl
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	cb "github.com/hyperledger/fabric-protos-go/common"
 	mb "github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
 	"go.uber.org/zap/zapcore"
 )
@@ -90,3 +93,79 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal) (func([]
 		return nil, fmt.Errorf("Unknown type: %T:%v", t, t)
 	}
 }
+
+// explain recursively builds an explanation of the evaluation of the
+// given identities against the policy, mirroring the semantics of the
+// function returned by compile
+func explain(policy *cb.SignaturePolicy, principals []*mb.MSPPrincipal, identities []msp.Identity, used []bool) *policies.Explanation {
+	switch t := policy.Type.(type) {
+	case *cb.SignaturePolicy_NOutOf_:
+		explanation := &policies.Explanation{
+			Policy:          fmt.Sprintf("OutOf(%d)", t.NOutOf.N),
+			Required:        int(t.NOutOf.N),
+			SubExplanations: make([]*policies.Explanation, len(t.NOutOf.Rules)),
+		}
+		verified := int32(0)
+		_used := make([]bool, len(used))
+		for i, rule := range t.NOutOf.Rules {
+			copy(_used, used)
+			explanation.SubExplanations[i] = explain(rule, principals, identities, _used)
+			if explanation.SubExplanations[i].Satisfied {
+				verified++
+				copy(used, _used)
+			}
+		}
+		explanation.Satisfied = verified >= t.NOutOf.N
+		return explanation
+	case *cb.SignaturePolicy_SignedBy:
+		principal := principals[t.SignedBy]
+		explanation := &policies.Explanation{
+			Policy: fmt.Sprintf("SignedBy(%s)", principalLabel(principal)),
+		}
+		for i, identity := range identities {
+			if used[i] {
+				continue
+			}
+			if identity.SatisfiesPrincipal(principal) != nil {
+				continue
+			}
+			used[i] = true
+			explanation.Satisfied = true
+			explanation.SatisfiedBy = []string{policies.IdentityLabel(identity)}
+			return explanation
+		}
+		explanation.Reason = "no unused identity satisfies the principal"
+		return explanation
+	default:
+		return &policies.Explanation{
+			Policy: fmt.Sprintf("%T", t),
+			Reason: "unknown policy type",
+		}
+	}
+}
+
+// principalLabel returns a human readable representation of a principal
+// which, for roles, matches the syntax of the policy language
+func principalLabel(principal *mb.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case mb.MSPPrincipal_ROLE:
+		role := &mb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			break
+		}
+		return fmt.Sprintf("'%s.%s'", role.MspIdentifier, strings.ToLower(role.Role.String()))
+	case mb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			break
+		}
+		return fmt.Sprintf("'%s.OU(%s)'", ou.MspIdentifier, ou.OrganizationalUnitIdentifier)
+	case mb.MSPPrincipal_IDENTITY:
+		id := &mb.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, id); err != nil {
+			break
+		}
+		return fmt.Sprintf("'%s.identity'", id.Mspid)
+	}
+	return fmt.Sprintf("%s(%x)", principal.PrincipalClassification, principal.Principal)
+}
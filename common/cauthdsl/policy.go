@@ -103,11 +103,28 @@ func (p *policy) EvaluateIdentities(identities []msp.Identity) error {
 
 	ok := p.evaluator(identities, make([]bool, len(identities)))
 	if !ok {
-		return errors.New("signature set did not satisfy policy")
+		return policies.NewEvaluationError(
+			errors.New("signature set did not satisfy policy"),
+			func() *policies.Explanation { return p.ExplainIdentities(identities) },
+		)
 	}
 	return nil
 }
 
+// ExplainSignedData returns an explanation of the evaluation of
+// the valid identities in the signature set against the policy
+func (p *policy) ExplainSignedData(signatureSet []*protoutil.SignedData) *policies.Explanation {
+	ids := policies.SignatureSetToValidIdentities(signatureSet, p.deserializer)
+
+	return p.ExplainIdentities(ids)
+}
+
+// ExplainIdentities returns an explanation of the evaluation of
+// the identities against the policy
+func (p *policy) ExplainIdentities(identities []msp.Identity) *policies.Explanation {
+	return explain(p.signaturePolicyEnvelope.Rule, p.signaturePolicyEnvelope.Identities, identities, make([]bool, len(identities)))
+}
+
 func (p *policy) Convert() (*cb.SignaturePolicyEnvelope, error) {
 	if p.signaturePolicyEnvelope == nil {
 		return nil, errors.New("nil policy field")
@@ -150,3 +150,42 @@ func TestConverter(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, cp, policydsl.RejectAllPolicy)
 }
+
+func TestExplain(t *testing.T) {
+	pp := &EnvelopeBasedPolicyProvider{Deserializer: &mockDeserializer{}}
+	p, err := pp.NewPolicy(policydsl.Envelope(policydsl.And(policydsl.SignedBy(0), policydsl.SignedBy(1)), signers))
+	assert.NoError(t, err)
+
+	err = p.EvaluateSignedData([]*protoutil.SignedData{{Identity: signers[0]}})
+	assert.EqualError(t, err, "signature set did not satisfy policy")
+
+	explanation, ok := policies.ExplanationFromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, "OutOf(2)", explanation.Policy)
+	assert.False(t, explanation.Satisfied)
+	assert.Equal(t, 2, explanation.Required)
+	assert.Len(t, explanation.SubExplanations, 2)
+	assert.True(t, explanation.SubExplanations[0].Satisfied)
+	assert.Equal(t, []string{"Mock:signer0"}, explanation.SubExplanations[0].SatisfiedBy)
+	assert.False(t, explanation.SubExplanations[1].Satisfied)
+	assert.Equal(t, "no unused identity satisfies the principal", explanation.SubExplanations[1].Reason)
+
+	explanation = policies.ExplainSignedData(p, []*protoutil.SignedData{{Identity: signers[0]}, {Identity: signers[1]}})
+	assert.True(t, explanation.Satisfied)
+	assert.Equal(t, []string{"Mock:signer1"}, explanation.SubExplanations[1].SatisfiedBy)
+
+	// the same identity cannot be used to satisfy two principals
+	explanation = policies.ExplainSignedData(p, []*protoutil.SignedData{{Identity: signers[0]}, {Identity: signers[0]}})
+	assert.False(t, explanation.Satisfied)
+}
+
+func TestExplainPrincipalLabel(t *testing.T) {
+	pp := &EnvelopeBasedPolicyProvider{Deserializer: &mockDeserializer{}}
+	p, err := pp.NewPolicy(policydsl.SignedByMspPeer("Org1MSP"))
+	assert.NoError(t, err)
+
+	explanation := policies.ExplainIdentities(p, nil)
+	assert.Equal(t, "OutOf(1)", explanation.Policy)
+	assert.Equal(t, "SignedBy('Org1MSP.peer')", explanation.SubExplanations[0].Policy)
+	assert.False(t, explanation.Satisfied)
+}
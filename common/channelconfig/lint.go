@@ -0,0 +1,437 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// DefaultLintExpiryWindow is the period before the expiration of a
+// certificate during which the linter reports it
+const DefaultLintExpiryWindow = 30 * 24 * time.Hour
+
+// LintSeverity indicates how serious a LintFinding is
+type LintSeverity string
+
+const (
+	// LintError findings make the channel, or part of it, unusable
+	LintError LintSeverity = "ERROR"
+	// LintWarning findings are likely to cause problems in the future
+	LintWarning LintSeverity = "WARNING"
+	// LintInfo findings point out opportunities, such as capability upgrades
+	LintInfo LintSeverity = "INFO"
+)
+
+// Names of the checks performed by Lint
+const (
+	LintCheckMSPCertExpiry       = "msp-cert-expiry"
+	LintCheckConsenterCertExpiry = "consenter-cert-expiry"
+	LintCheckNodeOUs             = "node-ous"
+	LintCheckCapabilities        = "capabilities"
+	LintCheckACLPolicies         = "acl-policies"
+	LintCheckAnchorPeers         = "anchor-peers"
+)
+
+// LintFinding is a problem detected in the config of a channel
+type LintFinding struct {
+	Severity LintSeverity `json:"severity"`
+	Check    string       `json:"check"`
+	Path     string       `json:"path"`
+	Message  string       `json:"message"`
+	// MSPID is the ID of the MSP the finding is about, if any
+	MSPID string `json:"msp_id,omitempty"`
+
+	// consenterCert is the DER encoded certificate of the consenter
+	// the finding is about, if any
+	consenterCert []byte
+}
+
+func (f *LintFinding) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", f.Severity, f.Check, f.Path, f.Message)
+}
+
+// LintOptions tunes the checks performed by Lint
+type LintOptions struct {
+	// Now is the time against which certificate expiration is checked,
+	// defaults to the current time
+	Now time.Time
+	// ExpiryWindow is the period before the expiration of a certificate
+	// during which it is reported, defaults to DefaultLintExpiryWindow.
+	// Only expired certificates are reported if it is negative.
+	ExpiryWindow time.Duration
+}
+
+// Lint inspects the config of a channel and reports the problems found:
+// expired or expiring MSP and consenter certificates, MSPs without NodeOUs,
+// capabilities which are not supported by this binary or could be upgraded,
+// ACLs referencing policies which do not exist and application organizations
+// without anchor peers. Findings are sorted by decreasing severity.
+func Lint(res Resources, opts LintOptions) []*LintFinding {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.ExpiryWindow == 0 {
+		opts.ExpiryWindow = DefaultLintExpiryWindow
+	}
+
+	var channelGroup *cb.ConfigGroup
+	if validator := res.ConfigtxValidator(); validator != nil {
+		if config := validator.ConfigProto(); config != nil {
+			channelGroup = config.ChannelGroup
+		}
+	}
+
+	l := &linter{opts: opts}
+	if channelGroup != nil {
+		l.lintMSPs(fmt.Sprintf("/%s", ChannelGroupKey), channelGroup)
+		l.lintACLs(channelGroup, res)
+	}
+	l.lintCapabilities(channelGroup, res)
+	l.lintConsenters(res)
+	l.lintAnchorPeers(res)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return severityRank(l.findings[i].Severity) < severityRank(l.findings[j].Severity)
+	})
+	return l.findings
+}
+
+func severityRank(severity LintSeverity) int {
+	switch severity {
+	case LintError:
+		return 0
+	case LintWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type linter struct {
+	opts     LintOptions
+	findings []*LintFinding
+}
+
+func (l *linter) report(severity LintSeverity, check, path, format string, args ...interface{}) *LintFinding {
+	finding := &LintFinding{
+		Severity: severity,
+		Check:    check,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	l.findings = append(l.findings, finding)
+	return finding
+}
+
+// lintMSPs walks the config groups and inspects the MSP of every
+// organization found
+func (l *linter) lintMSPs(path string, group *cb.ConfigGroup) {
+	if value, ok := group.Values[MSPKey]; ok {
+		l.lintMSP(path, value)
+	}
+
+	for _, name := range sortedKeys(group.Groups) {
+		l.lintMSPs(path+"/"+name, group.Groups[name])
+	}
+}
+
+func (l *linter) lintMSP(path string, value *cb.ConfigValue) {
+	mspConfig := &mspprotos.MSPConfig{}
+	if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+		l.report(LintError, LintCheckMSPCertExpiry, path, "cannot unmarshal MSP config: %s", err)
+		return
+	}
+	if mspConfig.Type != int32(msp.FABRIC) {
+		return
+	}
+
+	fabricConfig := &mspprotos.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+		l.report(LintError, LintCheckMSPCertExpiry, path, "cannot unmarshal fabric MSP config: %s", err)
+		return
+	}
+
+	first := len(l.findings)
+	defer func() {
+		for _, finding := range l.findings[first:] {
+			finding.MSPID = fabricConfig.Name
+		}
+	}()
+
+	for _, certs := range []struct {
+		kind  string
+		certs [][]byte
+	}{
+		{"root CA", fabricConfig.RootCerts},
+		{"intermediate CA", fabricConfig.IntermediateCerts},
+		{"admin", fabricConfig.Admins},
+		{"TLS root CA", fabricConfig.TlsRootCerts},
+		{"TLS intermediate CA", fabricConfig.TlsIntermediateCerts},
+	} {
+		for _, pemBytes := range certs.certs {
+			l.lintCert(LintCheckMSPCertExpiry, path, fmt.Sprintf("MSP %s %s certificate", fabricConfig.Name, certs.kind), pemBytes)
+		}
+	}
+
+	if fabricConfig.FabricNodeOus == nil || !fabricConfig.FabricNodeOus.Enable {
+		l.report(LintWarning, LintCheckNodeOUs, path, "MSP %s does not enable NodeOUs, admins must be listed explicitly and roles cannot be told apart by certificate", fabricConfig.Name)
+	}
+}
+
+func (l *linter) lintCert(check, path, what string, pemBytes []byte) *LintFinding {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return l.report(LintError, check, path, "%s is not PEM encoded", what)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return l.report(LintError, check, path, "%s cannot be parsed: %s", what, err)
+	}
+
+	subject := cert.Subject.CommonName
+	if subject == "" {
+		subject = cert.Subject.String()
+	}
+
+	if !l.opts.Now.Before(cert.NotAfter) {
+		return l.report(LintError, check, path, "%s '%s' expired on %s", what, subject, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if l.opts.ExpiryWindow < 0 || cert.NotAfter.Sub(l.opts.Now) >= l.opts.ExpiryWindow {
+		return nil
+	}
+	// A consenter whose TLS certificate expires is cut off from the cluster, and
+	// renewing it takes a config update, so it must be addressed ahead of time
+	severity := LintWarning
+	if check == LintCheckConsenterCertExpiry {
+		severity = LintError
+	}
+	return l.report(severity, check, path, "%s '%s' expires on %s", what, subject, cert.NotAfter.UTC().Format(time.RFC3339))
+}
+
+func (l *linter) lintConsenters(res Resources) {
+	oc, ok := res.OrdererConfig()
+	if !ok || oc.ConsensusType() != "etcdraft" {
+		return
+	}
+
+	path := fmt.Sprintf("/%s/%s/%s", ChannelGroupKey, OrdererGroupKey, ConsensusTypeKey)
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(oc.ConsensusMetadata(), metadata); err != nil {
+		l.report(LintError, LintCheckConsenterCertExpiry, path, "cannot unmarshal etcdraft metadata: %s", err)
+		return
+	}
+
+	for _, consenter := range metadata.Consenters {
+		endpoint := fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)
+		for _, cert := range []struct {
+			kind string
+			pem  []byte
+		}{
+			{"client", consenter.ClientTlsCert},
+			{"server", consenter.ServerTlsCert},
+		} {
+			if finding := l.lintCert(LintCheckConsenterCertExpiry, path, fmt.Sprintf("consenter %s %s TLS certificate", endpoint, cert.kind), cert.pem); finding != nil {
+				finding.consenterCert = derOf(cert.pem)
+			}
+		}
+	}
+}
+
+// lintCapabilities reports the capabilities required by the channel which
+// this binary does not support, and the capability levels which do not
+// enable the latest capability supported by this binary
+func (l *linter) lintCapabilities(channelGroup *cb.ConfigGroup, res Resources) {
+	check := func(path string, group *cb.ConfigGroup, supported error, latest string) {
+		if supported != nil {
+			l.report(LintError, LintCheckCapabilities, path, "%s", supported)
+			return
+		}
+		if group == nil {
+			return
+		}
+		required := &cb.Capabilities{}
+		if value, ok := group.Values[CapabilitiesKey]; ok {
+			if err := proto.Unmarshal(value.Value, required); err != nil {
+				l.report(LintError, LintCheckCapabilities, path, "cannot unmarshal capabilities: %s", err)
+				return
+			}
+		}
+		if _, ok := required.Capabilities[latest]; !ok {
+			l.report(LintInfo, LintCheckCapabilities, path, "capability %s is supported by this binary but not enabled", latest)
+		}
+	}
+
+	subGroup := func(name string) *cb.ConfigGroup {
+		if channelGroup == nil {
+			return nil
+		}
+		return channelGroup.Groups[name]
+	}
+
+	if cc := res.ChannelConfig(); cc != nil {
+		check(fmt.Sprintf("/%s", ChannelGroupKey), channelGroup, cc.Capabilities().Supported(), capabilities.ChannelV2_0)
+	}
+	if oc, ok := res.OrdererConfig(); ok {
+		path := fmt.Sprintf("/%s/%s", ChannelGroupKey, OrdererGroupKey)
+		check(path, subGroup(OrdererGroupKey), oc.Capabilities().Supported(), capabilities.OrdererV2_0)
+	}
+	if ac, ok := res.ApplicationConfig(); ok {
+		path := fmt.Sprintf("/%s/%s", ChannelGroupKey, ApplicationGroupKey)
+		check(path, subGroup(ApplicationGroupKey), ac.Capabilities().Supported(), capabilities.ApplicationV2_0)
+	}
+}
+
+// lintACLs reports the ACLs of the application which reference policies
+// not defined in the channel
+func (l *linter) lintACLs(channelGroup *cb.ConfigGroup, res Resources) {
+	application, ok := channelGroup.Groups[ApplicationGroupKey]
+	if !ok {
+		return
+	}
+	value, ok := application.Values[ACLsKey]
+	if !ok {
+		return
+	}
+
+	path := fmt.Sprintf("/%s/%s/%s", ChannelGroupKey, ApplicationGroupKey, ACLsKey)
+	acls := &pb.ACLs{}
+	if err := proto.Unmarshal(value.Value, acls); err != nil {
+		l.report(LintError, LintCheckACLPolicies, path, "cannot unmarshal ACLs: %s", err)
+		return
+	}
+
+	pm := res.PolicyManager()
+	for _, resource := range sortedKeys(acls.Acls) {
+		policyRef := acls.Acls[resource].PolicyRef
+		if _, ok := pm.GetPolicy(policyRef); !ok {
+			l.report(LintError, LintCheckACLPolicies, path, "ACL for resource %s references policy '%s' which does not exist", resource, policyRef)
+		}
+	}
+}
+
+func (l *linter) lintAnchorPeers(res Resources) {
+	ac, ok := res.ApplicationConfig()
+	if !ok {
+		return
+	}
+
+	orgs := ac.Organizations()
+	for _, name := range sortedKeys(orgs) {
+		if len(orgs[name].AnchorPeers()) == 0 {
+			path := fmt.Sprintf("/%s/%s/%s", ChannelGroupKey, ApplicationGroupKey, name)
+			l.report(LintWarning, LintCheckAnchorPeers, path, "organization %s has no anchor peers, its peers cannot be discovered by other organizations through gossip", name)
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map with string keys in order
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*cb.ConfigGroup:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]*pb.APIResource:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]ApplicationOrg:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LintHealthChecker is a healthz.HealthChecker which reports the channels
+// whose config has findings that impair this node: findings of severity
+// LintError and certificates of the local MSP which expire within the expiry
+// window, so that they are renewed before an outage. Findings about other
+// organizations, which this node cannot fix, are left to Lint.
+type LintHealthChecker struct {
+	// Channels returns the resources of the channels to check by channel ID
+	Channels func() map[string]Resources
+	Options  LintOptions
+	// MSPID is the ID of the local MSP, the findings about other MSPs are ignored
+	MSPID string
+	// TLSCert is the PEM encoded TLS certificate of this node, the findings
+	// about consenters with another certificate are ignored
+	TLSCert []byte
+}
+
+// HealthCheck lints the config of every channel and fails if any of them
+// has findings about expired or expiring certificates of the local MSP or
+// of the consenter of this node, or about the capabilities this binary
+// does not support
+func (c *LintHealthChecker) HealthCheck(ctx context.Context) error {
+	channels := c.Channels()
+
+	var channelIDs []string
+	for channelID := range channels {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+
+	var problems []string
+	for _, channelID := range channelIDs {
+		for _, finding := range Lint(channels[channelID], c.Options) {
+			if c.isLocal(finding) && (finding.Severity == LintError || finding.Check == LintCheckMSPCertExpiry) {
+				problems = append(problems, fmt.Sprintf("[channel %s] %s: %s", channelID, finding.Path, finding.Message))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("channel config problems detected: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isLocal returns whether the finding is about a problem
+// this node can and must address
+func (c *LintHealthChecker) isLocal(finding *LintFinding) bool {
+	switch finding.Check {
+	case LintCheckMSPCertExpiry, LintCheckNodeOUs:
+		return finding.MSPID != "" && finding.MSPID == c.MSPID
+	case LintCheckConsenterCertExpiry:
+		tlsCert := derOf(c.TLSCert)
+		return tlsCert != nil && bytes.Equal(finding.consenterCert, tlsCert)
+	case LintCheckCapabilities:
+		return true
+	default:
+		return false
+	}
+}
+
+// derOf returns the DER bytes of a PEM encoded certificate, or nil
+// if it is not PEM encoded
+func derOf(pemBytes []byte) []byte {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil
+	}
+	return block.Bytes
+}
@@ -0,0 +1,258 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
+	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleConfig(t *testing.T) *cb.Config {
+	conf := genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile, configtest.GetDevConfigDir())
+	gb := encoder.New(conf).GenesisBlockForChannel("foo")
+	env := protoutil.ExtractEnvelopeOrPanic(gb, 0)
+	payload := protoutil.UnmarshalPayloadOrPanic(env.Payload)
+	configEnv := &cb.ConfigEnvelope{}
+	require.NoError(t, proto.Unmarshal(payload.Data, configEnv))
+	return configEnv.Config
+}
+
+func newLintBundle(t *testing.T, config *cb.Config) *channelconfig.Bundle {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	bundle, err := channelconfig.NewBundle("foo", config, cryptoProvider)
+	require.NoError(t, err)
+	return bundle
+}
+
+func findingsOf(findings []*channelconfig.LintFinding, check string) []*channelconfig.LintFinding {
+	var res []*channelconfig.LintFinding
+	for _, finding := range findings {
+		if finding.Check == check {
+			res = append(res, finding)
+		}
+	}
+	return res
+}
+
+func TestLintSampleConfig(t *testing.T) {
+	bundle := newLintBundle(t, sampleConfig(t))
+
+	// the sample MSP certificates expire in November 2027
+	findings := channelconfig.Lint(bundle, channelconfig.LintOptions{Now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)})
+	for _, finding := range findings {
+		assert.NotEqual(t, channelconfig.LintError, finding.Severity, finding.String())
+	}
+	assert.Empty(t, findingsOf(findings, channelconfig.LintCheckMSPCertExpiry))
+	assert.Empty(t, findingsOf(findings, channelconfig.LintCheckAnchorPeers))
+	assert.Empty(t, findingsOf(findings, channelconfig.LintCheckCapabilities))
+	assert.Len(t, findingsOf(findings, channelconfig.LintCheckNodeOUs), 3)
+
+	findings = channelconfig.Lint(bundle, channelconfig.LintOptions{Now: time.Date(2027, 11, 1, 0, 0, 0, 0, time.UTC)})
+	expiring := findingsOf(findings, channelconfig.LintCheckMSPCertExpiry)
+	assert.Contains(t, expiring, &channelconfig.LintFinding{
+		Severity: channelconfig.LintWarning,
+		Check:    channelconfig.LintCheckMSPCertExpiry,
+		Path:     "/Channel/Application/SampleOrg",
+		Message:  "MSP SampleOrg root CA certificate 'ca.org1.example.com' expires on 2027-11-10T13:41:11Z",
+		MSPID:    "SampleOrg",
+	})
+	assert.Contains(t, expiring, &channelconfig.LintFinding{
+		Severity: channelconfig.LintError,
+		Check:    channelconfig.LintCheckMSPCertExpiry,
+		Path:     "/Channel/Orderer/SampleOrg",
+		Message:  "MSP SampleOrg TLS root CA certificate 'Org2' expired on 2027-05-06T09:30:34Z",
+		MSPID:    "SampleOrg",
+	})
+
+	findings = channelconfig.Lint(bundle, channelconfig.LintOptions{Now: time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)})
+	require.NotEmpty(t, findings)
+	assert.Equal(t, channelconfig.LintError, findings[0].Severity)
+	assert.Equal(t, "MSP SampleOrg root CA certificate 'ca.org1.example.com' expired on 2027-11-10T13:41:11Z", findings[0].Message)
+}
+
+func TestLintApplication(t *testing.T) {
+	config := sampleConfig(t)
+	application := config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+	application.Values[channelconfig.ACLsKey] = &cb.ConfigValue{
+		ModPolicy: channelconfig.AdminsPolicyKey,
+		Value: protoutil.MarshalOrPanic(&pb.ACLs{
+			Acls: map[string]*pb.APIResource{
+				"peer/Propose":   {PolicyRef: "/Channel/Application/Writers"},
+				"qscc/GetBlock":  {PolicyRef: "/Channel/Application/Auditors"},
+				"cscc/GetConfig": {PolicyRef: "Readers"},
+			},
+		}),
+	}
+	delete(application.Groups["SampleOrg"].Values, channelconfig.AnchorPeersKey)
+	application.Values[channelconfig.CapabilitiesKey].Value = protoutil.MarshalOrPanic(&cb.Capabilities{
+		Capabilities: map[string]*cb.Capability{"V1_4_2": {}},
+	})
+
+	findings := channelconfig.Lint(newLintBundle(t, config), channelconfig.LintOptions{Now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	acls := findingsOf(findings, channelconfig.LintCheckACLPolicies)
+	require.Len(t, acls, 1)
+	assert.Equal(t, &channelconfig.LintFinding{
+		Severity: channelconfig.LintError,
+		Check:    channelconfig.LintCheckACLPolicies,
+		Path:     "/Channel/Application/ACLs",
+		Message:  "ACL for resource qscc/GetBlock references policy '/Channel/Application/Auditors' which does not exist",
+	}, acls[0])
+
+	anchorPeers := findingsOf(findings, channelconfig.LintCheckAnchorPeers)
+	require.Len(t, anchorPeers, 1)
+	assert.Equal(t, channelconfig.LintWarning, anchorPeers[0].Severity)
+	assert.Equal(t, "/Channel/Application/SampleOrg", anchorPeers[0].Path)
+
+	capabilities := findingsOf(findings, channelconfig.LintCheckCapabilities)
+	require.Len(t, capabilities, 1)
+	assert.Equal(t, &channelconfig.LintFinding{
+		Severity: channelconfig.LintInfo,
+		Check:    channelconfig.LintCheckCapabilities,
+		Path:     "/Channel/Application",
+		Message:  "capability V2_0 is supported by this binary but not enabled",
+	}, capabilities[0])
+}
+
+func TestLintUnsupportedCapabilities(t *testing.T) {
+	config := sampleConfig(t)
+	config.ChannelGroup.Values[channelconfig.CapabilitiesKey].Value = protoutil.MarshalOrPanic(&cb.Capabilities{
+		Capabilities: map[string]*cb.Capability{"V2_0": {}, "V99_0": {}},
+	})
+
+	findings := channelconfig.Lint(newLintBundle(t, config), channelconfig.LintOptions{})
+	capabilities := findingsOf(findings, channelconfig.LintCheckCapabilities)
+	require.Len(t, capabilities, 1)
+	assert.Equal(t, channelconfig.LintError, capabilities[0].Severity)
+	assert.Equal(t, "/Channel", capabilities[0].Path)
+	assert.Contains(t, capabilities[0].Message, "V99_0")
+}
+
+func TestLintConsenters(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	// tlsgen issues client certificates valid for a day and server
+	// certificates valid for ten years
+	serverCert, err := ca.NewServerCertKeyPair("raft0.example.com")
+	require.NoError(t, err)
+	clientCert, err := ca.NewClientCertKeyPair()
+	require.NoError(t, err)
+
+	config := sampleConfig(t)
+	orderer := config.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	orderer.Values[channelconfig.ConsensusTypeKey].Value = protoutil.MarshalOrPanic(&ab.ConsensusType{
+		Type: "etcdraft",
+		Metadata: protoutil.MarshalOrPanic(&etcdraft.ConfigMetadata{
+			Consenters: []*etcdraft.Consenter{{
+				Host:          "raft0.example.com",
+				Port:          7050,
+				ClientTlsCert: clientCert.Cert,
+				ServerTlsCert: serverCert.Cert,
+			}},
+			Options: &etcdraft.Options{
+				TickInterval:         "500ms",
+				ElectionTick:         10,
+				HeartbeatTick:        1,
+				MaxInflightBlocks:    5,
+				SnapshotIntervalSize: 16 * 1024 * 1024,
+			},
+		}),
+	})
+	bundle := newLintBundle(t, config)
+
+	findings := findingsOf(channelconfig.Lint(bundle, channelconfig.LintOptions{ExpiryWindow: time.Hour}), channelconfig.LintCheckConsenterCertExpiry)
+	assert.Empty(t, findings)
+
+	findings = findingsOf(channelconfig.Lint(bundle, channelconfig.LintOptions{}), channelconfig.LintCheckConsenterCertExpiry)
+	require.Len(t, findings, 1)
+	assert.Equal(t, channelconfig.LintError, findings[0].Severity)
+	assert.Equal(t, "/Channel/Orderer/ConsensusType", findings[0].Path)
+	assert.Regexp(t, "^consenter raft0.example.com:7050 client TLS certificate '.*' expires on ", findings[0].Message)
+
+	findings = findingsOf(channelconfig.Lint(bundle, channelconfig.LintOptions{Now: time.Now().Add(48 * time.Hour)}), channelconfig.LintCheckConsenterCertExpiry)
+	require.Len(t, findings, 1)
+	assert.Equal(t, channelconfig.LintError, findings[0].Severity)
+	assert.Regexp(t, "^consenter raft0.example.com:7050 client TLS certificate '.*' expired on ", findings[0].Message)
+
+	findings = findingsOf(channelconfig.Lint(bundle, channelconfig.LintOptions{Now: time.Now().Add(11 * 365 * 24 * time.Hour)}), channelconfig.LintCheckConsenterCertExpiry)
+	require.Len(t, findings, 2)
+	assert.Regexp(t, "^consenter raft0.example.com:7050 server TLS certificate '.*' expired on ", findings[1].Message)
+
+	checker := &channelconfig.LintHealthChecker{
+		Channels: func() map[string]channelconfig.Resources {
+			return map[string]channelconfig.Resources{"foo": bundle}
+		},
+		TLSCert: serverCert.Cert,
+	}
+	// The client certificate of the consenter expires soon, but isn't the one of this orderer
+	assert.NoError(t, checker.HealthCheck(context.Background()))
+
+	// The certificate of this orderer expires within the expiry window
+	checker.Options.ExpiryWindow = 11 * 365 * 24 * time.Hour
+	err = checker.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Regexp(t, "consenter raft0.example.com:7050 server TLS certificate '.*' expires on ", err.Error())
+
+	checker.Options.ExpiryWindow = 0
+	checker.Options.Now = time.Now().Add(11 * 365 * 24 * time.Hour)
+	err = checker.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Regexp(t, "consenter raft0.example.com:7050 server TLS certificate '.*' expired on ", err.Error())
+
+	// The certificates of other consenters are not the concern of this orderer
+	otherCert, err := ca.NewServerCertKeyPair("raft1.example.com")
+	require.NoError(t, err)
+	checker.TLSCert = otherCert.Cert
+	assert.NoError(t, checker.HealthCheck(context.Background()))
+}
+
+func TestLintHealthChecker(t *testing.T) {
+	bundle := newLintBundle(t, sampleConfig(t))
+	checker := &channelconfig.LintHealthChecker{
+		Channels: func() map[string]channelconfig.Resources {
+			return map[string]channelconfig.Resources{"foo": bundle}
+		},
+		Options: channelconfig.LintOptions{Now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		MSPID:   "SampleOrg",
+	}
+	assert.NoError(t, checker.HealthCheck(context.Background()))
+
+	// Certificates expiring within the expiry window fail the health check
+	checker.Options.Now = time.Date(2027, 4, 20, 0, 0, 0, 0, time.UTC)
+	err := checker.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel config problems detected: [channel foo] /Channel/Application/SampleOrg: MSP SampleOrg TLS root CA certificate 'Org2' expires on 2027-05-06T09:30:34Z")
+
+	checker.Options.ExpiryWindow = time.Hour
+	assert.NoError(t, checker.HealthCheck(context.Background()))
+
+	checker.Options.Now = time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = checker.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel config problems detected: [channel foo] /Channel/Application/SampleOrg: MSP SampleOrg root CA certificate 'ca.org1.example.com' expired on 2027-11-10T13:41:11Z")
+
+	// The certificates of other organizations are not the concern of this node
+	checker.MSPID = "OtherOrg"
+	assert.NoError(t, checker.HealthCheck(context.Background()))
+}
@@ -48,4 +48,25 @@ func LogSanityChecks(res Resources) {
 			}
 		}
 	}
+
+	logLintFindings(res)
+}
+
+// logLintFindings logs the findings of Lint for the config of the channel
+func logLintFindings(res Resources) {
+	var channelID string
+	if validator := res.ConfigtxValidator(); validator != nil {
+		channelID = validator.ChannelID()
+	}
+
+	for _, finding := range Lint(res, LintOptions{}) {
+		switch finding.Severity {
+		case LintError:
+			logger.Errorf("[channel %s] Config check %s failed for %s: %s", channelID, finding.Check, finding.Path, finding.Message)
+		case LintWarning:
+			logger.Warningf("[channel %s] Config check %s failed for %s: %s", channelID, finding.Check, finding.Path, finding.Message)
+		default:
+			logger.Infof("[channel %s] Config check %s: %s: %s", channelID, finding.Check, finding.Path, finding.Message)
+		}
+	}
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package configtx
 
 import (
+	"sort"
 	"strings"
 
 	cb "github.com/hyperledger/fabric-protos-go/common"
@@ -113,49 +114,150 @@ func verifyFullProposedConfig(writeSet, fullProposedConfig map[string]comparable
 // authorizeUpdate validates that all modified config has the corresponding modification policies satisfied by the signature set
 // it returns a map of the modified config
 func (vi *ValidatorImpl) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelope) (map[string]comparable, error) {
+	writeSet, deltaSet, err := vi.computeUpdateSets(configUpdateEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	signedData, err := protoutil.ConfigUpdateEnvelopeAsSignedData(configUpdateEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = vi.verifyDeltaSet(deltaSet, signedData); err != nil {
+		return nil, errors.Wrapf(err, "error validating DeltaSet")
+	}
+
+	fullProposedConfig := vi.computeUpdateResult(deltaSet)
+	if err := verifyFullProposedConfig(writeSet, fullProposedConfig); err != nil {
+		return nil, errors.Wrapf(err, "full config did not verify")
+	}
+
+	return fullProposedConfig, nil
+}
+
+// computeUpdateSets verifies the read set of the config update and returns
+// its write set along with the delta set of the elements it modifies
+func (vi *ValidatorImpl) computeUpdateSets(configUpdateEnv *cb.ConfigUpdateEnvelope) (writeSet, deltaSet map[string]comparable, err error) {
 	if configUpdateEnv == nil {
-		return nil, errors.Errorf("cannot process nil ConfigUpdateEnvelope")
+		return nil, nil, errors.Errorf("cannot process nil ConfigUpdateEnvelope")
 	}
 
 	configUpdate, err := UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if configUpdate.ChannelId != vi.channelID {
-		return nil, errors.Errorf("ConfigUpdate for channel '%s' but envelope for channel '%s'", configUpdate.ChannelId, vi.channelID)
+		return nil, nil, errors.Errorf("ConfigUpdate for channel '%s' but envelope for channel '%s'", configUpdate.ChannelId, vi.channelID)
 	}
 
 	readSet, err := mapConfig(configUpdate.ReadSet, vi.namespace)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error mapping ReadSet")
+		return nil, nil, errors.Wrapf(err, "error mapping ReadSet")
 	}
 	err = vi.verifyReadSet(readSet)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error validating ReadSet")
+		return nil, nil, errors.Wrapf(err, "error validating ReadSet")
+	}
+
+	writeSet, err = mapConfig(configUpdate.WriteSet, vi.namespace)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error mapping WriteSet")
 	}
 
-	writeSet, err := mapConfig(configUpdate.WriteSet, vi.namespace)
+	return writeSet, computeDeltaSet(readSet, writeSet), nil
+}
+
+// ModPolicyStatus describes whether the mod_policy of a config element
+// modified by a config update is satisfied by the signatures of the update
+type ModPolicyStatus struct {
+	// Key identifies the modified config element, e.g. "[Group]  /Channel/Application"
+	Key string
+	// PolicyPath is the fully qualified path of the mod_policy of the element
+	PolicyPath string
+	// Satisfied is true if the signatures satisfy the mod_policy
+	Satisfied bool
+	// Explanation describes the evaluation of the signatures against the mod_policy
+	Explanation *policies.Explanation
+}
+
+// ModPolicyStatuses returns, for each existing config element modified by the
+// config update, whether its mod_policy is satisfied by the signatures of the
+// update. Unlike ProposeConfigUpdate, unsatisfied policies are not an error,
+// which allows tracking the progress of the collection of signatures.
+func (vi *ValidatorImpl) ModPolicyStatuses(configUpdateEnv *cb.ConfigUpdateEnvelope) ([]*ModPolicyStatus, error) {
+	_, deltaSet, err := vi.computeUpdateSets(configUpdateEnv)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error mapping WriteSet")
+		return nil, err
+	}
+
+	if len(deltaSet) == 0 {
+		return nil, errors.Errorf("delta set was empty -- update would have no effect")
 	}
 
-	deltaSet := computeDeltaSet(readSet, writeSet)
 	signedData, err := protoutil.ConfigUpdateEnvelopeAsSignedData(configUpdateEnv)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = vi.verifyDeltaSet(deltaSet, signedData); err != nil {
-		return nil, errors.Wrapf(err, "error validating DeltaSet")
+	keys := make([]string, 0, len(deltaSet))
+	for key := range deltaSet {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	fullProposedConfig := vi.computeUpdateResult(deltaSet)
-	if err := verifyFullProposedConfig(writeSet, fullProposedConfig); err != nil {
-		return nil, errors.Wrapf(err, "full config did not verify")
+	var statuses []*ModPolicyStatus
+	for _, key := range keys {
+		value := deltaSet[key]
+		if err := validateModPolicy(value.modPolicy()); err != nil {
+			return nil, errors.Wrapf(err, "invalid mod_policy for element %s", key)
+		}
+
+		existing, ok := vi.configMap[key]
+		if !ok {
+			if value.version() != 0 {
+				return nil, errors.Errorf("attempted to set key %s to version %d, but key does not exist", key, value.version())
+			}
+
+			// new elements are authorized by the mod_policy of their parent group
+			continue
+		}
+		if value.version() != existing.version()+1 {
+			return nil, errors.Errorf("attempt to set key %s to version %d, but key is at version %d", key, value.version(), existing.version())
+		}
+
+		policy, ok := vi.policyForItem(existing)
+		if !ok {
+			return nil, errors.Errorf("unexpected missing policy %s for item %s", existing.modPolicy(), key)
+		}
+
+		explanation := policies.ExplainSignedData(policy, signedData)
+		statuses = append(statuses, &ModPolicyStatus{
+			Key:         key,
+			PolicyPath:  modPolicyPath(existing),
+			Satisfied:   explanation.Satisfied,
+			Explanation: explanation,
+		})
 	}
 
-	return fullProposedConfig, nil
+	return statuses, nil
+}
+
+// modPolicyPath returns the fully qualified path of the mod_policy of
+// the item, resolving relative paths as policyForItem does
+func modPolicyPath(item comparable) string {
+	modPolicy := item.modPolicy()
+	if len(modPolicy) > 0 && modPolicy[0] == policies.PathSeparator[0] {
+		return modPolicy
+	}
+
+	path := item.path
+	if item.ConfigGroup != nil {
+		path = append(append([]string{}, item.path...), item.key)
+	}
+
+	return policies.PathSeparator + strings.Join(append(append([]string{}, path...), modPolicy), policies.PathSeparator)
 }
 
 func (vi *ValidatorImpl) policyForItem(item comparable) (policies.Policy, bool) {
@@ -310,6 +310,62 @@ func TestConfigChangeViolatesPolicy(t *testing.T) {
 	assert.EqualError(t, err, "error authorizing update: error validating DeltaSet: policy for [Value]  /foonamespace/foo not satisfied: err")
 }
 
+func TestModPolicyStatuses(t *testing.T) {
+	pm := defaultPolicyManager()
+	vi, err := NewValidatorImpl(
+		defaultChannel,
+		makeConfig(makeConfigPair("foo", "foo", 0, []byte("foo")), makeConfigPair("bar", "/Channel/Admins", 0, []byte("bar"))),
+		"foonamespace",
+		pm)
+	assert.NoError(t, err)
+
+	fakePolicy := &mockpolicies.Policy{}
+	fakePolicy.EvaluateSignedDataReturns(fmt.Errorf("err"))
+	pm.GetPolicyStub = func(path string) (policies.Policy, bool) {
+		if path == "foo" {
+			return fakePolicy, true
+		}
+		return &mockpolicies.Policy{}, true
+	}
+
+	configUpdateEnv := &cb.ConfigUpdateEnvelope{
+		ConfigUpdate: protoutil.MarshalOrPanic(&cb.ConfigUpdate{
+			ChannelId: defaultChannel,
+			ReadSet:   makeConfigSet(),
+			WriteSet: makeConfigSet(
+				makeConfigPair("foo", "foo", 1, []byte("foo")),
+				makeConfigPair("bar", "/Channel/Admins", 1, []byte("bar")),
+				makeConfigPair("baz", "foo", 0, []byte("baz")),
+			),
+		}),
+	}
+
+	statuses, err := vi.ModPolicyStatuses(configUpdateEnv)
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "[Value]  /foonamespace/bar", statuses[0].Key)
+	assert.Equal(t, "/Channel/Admins", statuses[0].PolicyPath)
+	assert.True(t, statuses[0].Satisfied)
+	assert.Equal(t, "[Value]  /foonamespace/foo", statuses[1].Key)
+	assert.Equal(t, "/foonamespace/foo", statuses[1].PolicyPath)
+	assert.False(t, statuses[1].Satisfied)
+	assert.Equal(t, "err", statuses[1].Explanation.Reason)
+
+	t.Run("Wrong channel", func(t *testing.T) {
+		_, err := vi.ModPolicyStatuses(&cb.ConfigUpdateEnvelope{
+			ConfigUpdate: protoutil.MarshalOrPanic(&cb.ConfigUpdate{ChannelId: "wrongChannel"}),
+		})
+		assert.EqualError(t, err, "ConfigUpdate for channel 'wrongChannel' but envelope for channel 'default.channel.id'")
+	})
+
+	t.Run("Empty update", func(t *testing.T) {
+		_, err := vi.ModPolicyStatuses(&cb.ConfigUpdateEnvelope{
+			ConfigUpdate: protoutil.MarshalOrPanic(&cb.ConfigUpdate{ChannelId: defaultChannel}),
+		})
+		assert.EqualError(t, err, "delta set was empty -- update would have no effect")
+	})
+}
+
 // TestUnchangedConfigViolatesPolicy checks to make sure that existing config items are not revalidated against their modification policies
 // as the policy may have changed, certs revoked, etc. since the config was adopted.
 func TestUnchangedConfigViolatesPolicy(t *testing.T) {
@@ -39,6 +39,11 @@ func (e VSCCEndorsementPolicyError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the error which lead to the failure
+func (e VSCCEndorsementPolicyError) Unwrap() error {
+	return e.Err
+}
+
 // VSCCExecutionFailureError error to indicate
 // failure during attempt of executing VSCC
 // endorsement policy check
@@ -51,6 +56,11 @@ func (e VSCCExecutionFailureError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the error which lead to the failure
+func (e VSCCExecutionFailureError) Unwrap() error {
+	return e.Err
+}
+
 func (e *VSCCExecutionFailureError) IsValid() bool {
 	return e.Err == nil
 }
@@ -0,0 +1,190 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policies
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// Explanation is a structured description of the outcome of a policy
+// evaluation. Each node corresponds to a policy, a gate or a principal
+// and records whether it was satisfied and by which identities.
+type Explanation struct {
+	// Policy is a human readable description of the policy element,
+	// e.g. "OutOf(2)", "SignedBy('Org1MSP.peer')" or a policy path
+	Policy string `json:"policy"`
+	// Satisfied is true if the policy element was satisfied
+	Satisfied bool `json:"satisfied"`
+	// Required is the number of sub-elements that need to be satisfied
+	// for gate-like elements (NOutOf and implicit meta policies)
+	Required int `json:"required,omitempty"`
+	// SatisfiedBy lists the identities which satisfied a principal
+	SatisfiedBy []string `json:"satisfied_by,omitempty"`
+	// Reason optionally describes why the element was not satisfied
+	Reason string `json:"reason,omitempty"`
+	// SubExplanations holds the explanations of the sub-elements
+	SubExplanations []*Explanation `json:"sub_explanations,omitempty"`
+}
+
+// String renders the explanation as an indented tree
+func (e *Explanation) String() string {
+	var b bytes.Buffer
+	e.write(&b, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (e *Explanation) write(b *bytes.Buffer, depth int) {
+	if e == nil {
+		return
+	}
+
+	outcome := "NOT SATISFIED"
+	if e.Satisfied {
+		outcome = "SATISFIED"
+	}
+
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(fmt.Sprintf("%s: %s", e.Policy, outcome))
+	if e.Required > 0 {
+		b.WriteString(fmt.Sprintf(" (%d of %d required)", e.satisfiedCount(), e.Required))
+	}
+	if len(e.SatisfiedBy) > 0 {
+		b.WriteString(fmt.Sprintf(" by [%s]", strings.Join(e.SatisfiedBy, ", ")))
+	}
+	if e.Reason != "" {
+		b.WriteString(fmt.Sprintf(" - %s", e.Reason))
+	}
+	b.WriteString("\n")
+
+	for _, sub := range e.SubExplanations {
+		sub.write(b, depth+1)
+	}
+}
+
+func (e *Explanation) satisfiedCount() int {
+	count := 0
+	for _, sub := range e.SubExplanations {
+		if sub.Satisfied {
+			count++
+		}
+	}
+	return count
+}
+
+// Explainer is implemented by policies which are able to describe
+// how a set of signatures or identities was evaluated against them
+type Explainer interface {
+	// ExplainSignedData returns an explanation of the evaluation
+	// of the given signature set against the policy
+	ExplainSignedData(signatureSet []*protoutil.SignedData) *Explanation
+
+	// ExplainIdentities returns an explanation of the evaluation
+	// of the given identities against the policy
+	ExplainIdentities(identities []msp.Identity) *Explanation
+}
+
+// ExplainSignedData returns an explanation of the evaluation of the
+// signature set against the given policy. Policies which do not
+// implement Explainer are described by the outcome of their evaluation.
+func ExplainSignedData(policy Policy, signatureSet []*protoutil.SignedData) *Explanation {
+	if explainer, ok := policy.(Explainer); ok {
+		return explainer.ExplainSignedData(signatureSet)
+	}
+
+	return opaqueExplanation(policy, policy.EvaluateSignedData(signatureSet))
+}
+
+// ExplainIdentities returns an explanation of the evaluation of the
+// identities against the given policy. Policies which do not implement
+// Explainer are described by the outcome of their evaluation.
+func ExplainIdentities(policy Policy, identities []msp.Identity) *Explanation {
+	if explainer, ok := policy.(Explainer); ok {
+		return explainer.ExplainIdentities(identities)
+	}
+
+	return opaqueExplanation(policy, policy.EvaluateIdentities(identities))
+}
+
+func opaqueExplanation(policy Policy, err error) *Explanation {
+	explanation := &Explanation{
+		Policy:    fmt.Sprintf("%T", policy),
+		Satisfied: err == nil,
+	}
+	if err != nil {
+		explanation.Reason = err.Error()
+	}
+	return explanation
+}
+
+// IdentityLabel returns the label used to refer to an identity
+// in explanations
+func IdentityLabel(identity msp.Identity) string {
+	id := identity.GetIdentifier()
+	if id == nil {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%s:%s", id.Mspid, id.Id)
+}
+
+// EvaluationError is returned by policies supporting explanations when
+// an evaluation fails. The explanation is computed lazily, so that
+// callers which are not interested in it do not pay for it.
+type EvaluationError struct {
+	err     error
+	explain func() *Explanation
+
+	once        sync.Once
+	explanation *Explanation
+}
+
+// NewEvaluationError returns an EvaluationError wrapping err, whose
+// explanation is produced by invoking explain
+func NewEvaluationError(err error, explain func() *Explanation) *EvaluationError {
+	return &EvaluationError{
+		err:     err,
+		explain: explain,
+	}
+}
+
+// Error returns the message of the underlying error
+func (e *EvaluationError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *EvaluationError) Unwrap() error {
+	return e.err
+}
+
+// Explanation returns the explanation of the failed evaluation
+func (e *EvaluationError) Explanation() *Explanation {
+	e.once.Do(func() {
+		if e.explain != nil {
+			e.explanation = e.explain()
+		}
+	})
+	return e.explanation
+}
+
+// ExplanationFromError returns the explanation carried by an
+// EvaluationError found in the chain of err, if any
+func ExplanationFromError(err error) (*Explanation, bool) {
+	var evalErr *EvaluationError
+	if !errors.As(err, &evalErr) {
+		return nil, false
+	}
+
+	explanation := evalErr.Explanation()
+	return explanation, explanation != nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policies
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type explainablePolicy struct {
+	acceptPolicy
+	explanation *Explanation
+}
+
+func (ep explainablePolicy) ExplainSignedData(signatureSet []*protoutil.SignedData) *Explanation {
+	return ep.explanation
+}
+
+func (ep explainablePolicy) ExplainIdentities(identities []msp.Identity) *Explanation {
+	return ep.explanation
+}
+
+func TestExplanationString(t *testing.T) {
+	explanation := &Explanation{
+		Policy:   "OutOf(2)",
+		Required: 2,
+		SubExplanations: []*Explanation{
+			{Policy: "SignedBy('Org1MSP.peer')", Satisfied: true, SatisfiedBy: []string{"Org1MSP:peer0"}},
+			{Policy: "SignedBy('Org2MSP.peer')", Reason: "no unused identity satisfies the principal"},
+		},
+	}
+
+	assert.Equal(t, "OutOf(2): NOT SATISFIED (1 of 2 required)\n"+
+		"  SignedBy('Org1MSP.peer'): SATISFIED by [Org1MSP:peer0]\n"+
+		"  SignedBy('Org2MSP.peer'): NOT SATISFIED - no unused identity satisfies the principal",
+		explanation.String())
+}
+
+func TestExplainOpaquePolicy(t *testing.T) {
+	explanation := ExplainSignedData(acceptPolicy{}, nil)
+	assert.Equal(t, &Explanation{Policy: "policies.acceptPolicy", Satisfied: true}, explanation)
+
+	explanation = ExplainIdentities(rejectPolicy("foo"), nil)
+	assert.Equal(t, &Explanation{Policy: "foo", Reason: "no such policy"}, explanation)
+}
+
+func TestExplanationFromError(t *testing.T) {
+	_, ok := ExplanationFromError(errors.New("foo"))
+	assert.False(t, ok)
+
+	calls := 0
+	evalErr := NewEvaluationError(errors.New("foo"), func() *Explanation {
+		calls++
+		return &Explanation{Policy: "bar"}
+	})
+	assert.EqualError(t, evalErr, "foo")
+	assert.Equal(t, 0, calls)
+
+	explanation, ok := ExplanationFromError(errors.WithMessage(evalErr, "wrapped"))
+	assert.True(t, ok)
+	assert.Equal(t, "bar", explanation.Policy)
+
+	explanation, ok = ExplanationFromError(evalErr)
+	assert.True(t, ok)
+	assert.Equal(t, "bar", explanation.Policy)
+	assert.Equal(t, 1, calls)
+}
+
+func TestImplicitMetaPolicyExplanation(t *testing.T) {
+	managers := map[string]*ManagerImpl{
+		"Org1": {
+			path:     "Channel/Application/Org1",
+			Policies: map[string]Policy{TestPolicyName: explainablePolicy{explanation: &Explanation{Policy: "org1", Satisfied: true}}},
+		},
+		"Org2": {
+			path:     "Channel/Application/Org2",
+			Policies: map[string]Policy{TestPolicyName: explainablePolicy{explanation: &Explanation{Policy: "org2"}}},
+		},
+	}
+
+	imp, err := NewImplicitMetaPolicy(protoutil.MarshalOrPanic(&cb.ImplicitMetaPolicy{
+		Rule:      cb.ImplicitMetaPolicy_ALL,
+		SubPolicy: TestPolicyName,
+	}), managers)
+	assert.NoError(t, err)
+
+	explanation := imp.ExplainSignedData(nil)
+	assert.Equal(t, "ImplicitMeta('TestPolicyName')", explanation.Policy)
+	assert.Equal(t, 2, explanation.Required)
+	assert.False(t, explanation.Satisfied)
+	assert.Len(t, explanation.SubExplanations, 2)
+
+	satisfied := map[string]bool{}
+	for _, sub := range explanation.SubExplanations {
+		assert.Len(t, sub.SubExplanations, 1)
+		satisfied[sub.Policy] = sub.Satisfied
+	}
+	assert.Equal(t, map[string]bool{
+		"/Channel/Application/Org1/TestPolicyName": true,
+		"/Channel/Application/Org2/TestPolicyName": false,
+	}, satisfied)
+}
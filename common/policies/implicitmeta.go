@@ -99,7 +99,10 @@ func (imp *ImplicitMetaPolicy) EvaluateSignedData(signatureSet []*protoutil.Sign
 	if remaining == 0 {
 		return nil
 	}
-	return fmt.Errorf("implicit policy evaluation failed - %d sub-policies were satisfied, but this policy requires %d of the '%s' sub-policies to be satisfied", (imp.Threshold - remaining), imp.Threshold, imp.SubPolicyName)
+	return NewEvaluationError(
+		fmt.Errorf("implicit policy evaluation failed - %d sub-policies were satisfied, but this policy requires %d of the '%s' sub-policies to be satisfied", (imp.Threshold-remaining), imp.Threshold, imp.SubPolicyName),
+		func() *Explanation { return imp.ExplainSignedData(signatureSet) },
+	)
 }
 
 // EvaluateIdentities takes an array of identities and evaluates whether
@@ -140,5 +143,44 @@ func (imp *ImplicitMetaPolicy) EvaluateIdentities(identities []msp.Identity) err
 	if remaining == 0 {
 		return nil
 	}
-	return fmt.Errorf("implicit policy evaluation failed - %d sub-policies were satisfied, but this policy requires %d of the '%s' sub-policies to be satisfied", (imp.Threshold - remaining), imp.Threshold, imp.SubPolicyName)
+	return NewEvaluationError(
+		fmt.Errorf("implicit policy evaluation failed - %d sub-policies were satisfied, but this policy requires %d of the '%s' sub-policies to be satisfied", (imp.Threshold-remaining), imp.Threshold, imp.SubPolicyName),
+		func() *Explanation { return imp.ExplainIdentities(identities) },
+	)
+}
+
+// ExplainSignedData returns an explanation of the evaluation of the
+// signature set against each of the sub-policies
+func (imp *ImplicitMetaPolicy) ExplainSignedData(signatureSet []*protoutil.SignedData) *Explanation {
+	subExplanations := make([]*Explanation, len(imp.SubPolicies))
+	for i, policy := range imp.SubPolicies {
+		subExplanations[i] = ExplainSignedData(policy, signatureSet)
+	}
+	return imp.explanation(subExplanations)
+}
+
+// ExplainIdentities returns an explanation of the evaluation of the
+// identities against each of the sub-policies
+func (imp *ImplicitMetaPolicy) ExplainIdentities(identities []msp.Identity) *Explanation {
+	subExplanations := make([]*Explanation, len(imp.SubPolicies))
+	for i, policy := range imp.SubPolicies {
+		subExplanations[i] = ExplainIdentities(policy, identities)
+	}
+	return imp.explanation(subExplanations)
+}
+
+func (imp *ImplicitMetaPolicy) explanation(subExplanations []*Explanation) *Explanation {
+	satisfied := 0
+	for _, sub := range subExplanations {
+		if sub.Satisfied {
+			satisfied++
+		}
+	}
+
+	return &Explanation{
+		Policy:          fmt.Sprintf("ImplicitMeta('%s')", imp.SubPolicyName),
+		Satisfied:       satisfied >= imp.Threshold,
+		Required:        imp.Threshold,
+		SubExplanations: subExplanations,
+	}
 }
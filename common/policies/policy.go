@@ -256,6 +256,21 @@ func (rp rejectPolicy) EvaluateIdentities(identities []mspi.Identity) error {
 	return errors.Errorf("no such policy: '%s'", rp)
 }
 
+func (rp rejectPolicy) ExplainSignedData(signedData []*protoutil.SignedData) *Explanation {
+	return rp.explanation()
+}
+
+func (rp rejectPolicy) ExplainIdentities(identities []mspi.Identity) *Explanation {
+	return rp.explanation()
+}
+
+func (rp rejectPolicy) explanation() *Explanation {
+	return &Explanation{
+		Policy: string(rp),
+		Reason: "no such policy",
+	}
+}
+
 // Manager returns the sub-policy manager for a given path and whether it exists
 func (pm *ManagerImpl) Manager(path []string) (Manager, bool) {
 	logger.Debugf("Manager %s looking up path %v", pm.path, path)
@@ -288,6 +303,7 @@ func (pl *PolicyLogger) EvaluateSignedData(signatureSet []*protoutil.SignedData)
 	err := pl.Policy.EvaluateSignedData(signatureSet)
 	if err != nil {
 		logger.Debugf("Signature set did not satisfy policy %s", pl.policyName)
+		pl.logExplanation(err)
 	} else {
 		logger.Debugf("Signature set satisfies policy %s", pl.policyName)
 	}
@@ -303,12 +319,42 @@ func (pl *PolicyLogger) EvaluateIdentities(identities []mspi.Identity) error {
 	err := pl.Policy.EvaluateIdentities(identities)
 	if err != nil {
 		logger.Debugf("Signature set did not satisfy policy %s", pl.policyName)
+		pl.logExplanation(err)
 	} else {
 		logger.Debugf("Signature set satisfies policy %s", pl.policyName)
 	}
 	return err
 }
 
+// ExplainSignedData returns an explanation of the evaluation of
+// the signature set against the underlying policy
+func (pl *PolicyLogger) ExplainSignedData(signatureSet []*protoutil.SignedData) *Explanation {
+	return pl.explanation(ExplainSignedData(pl.Policy, signatureSet))
+}
+
+// ExplainIdentities returns an explanation of the evaluation of
+// the identities against the underlying policy
+func (pl *PolicyLogger) ExplainIdentities(identities []mspi.Identity) *Explanation {
+	return pl.explanation(ExplainIdentities(pl.Policy, identities))
+}
+
+func (pl *PolicyLogger) explanation(explanation *Explanation) *Explanation {
+	return &Explanation{
+		Policy:          pl.policyName,
+		Satisfied:       explanation.Satisfied,
+		SubExplanations: []*Explanation{explanation},
+	}
+}
+
+func (pl *PolicyLogger) logExplanation(err error) {
+	if !logger.IsEnabledFor(zapcore.DebugLevel) {
+		return
+	}
+	if explanation, ok := ExplanationFromError(err); ok {
+		logger.Debugf("Evaluation of policy %s:\n%s", pl.policyName, explanation)
+	}
+}
+
 func (pl *PolicyLogger) Convert() (*cb.SignaturePolicyEnvelope, error) {
 	logger.Debugf("== Converting %T Policy %s ==", pl.Policy, pl.policyName)
 
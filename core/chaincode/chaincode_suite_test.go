@@ -156,3 +156,18 @@ type policy interface {
 type connectionHandler interface {
 	chaincode.ConnectionHandler
 }
+
+//go:generate counterfeiter -o fake/launch_observer.go --fake-name LaunchObserver . launchObserver
+type launchObserver interface {
+	chaincode.LaunchObserver
+}
+
+//go:generate counterfeiter -o fake/supervised_launcher.go --fake-name SupervisedLauncher . supervisedLauncher
+type supervisedLauncher interface {
+	chaincode.SupervisedLauncher
+}
+
+//go:generate counterfeiter -o fake/private_data_access_log.go --fake-name PrivateDataAccessLog . privateDataAccessLog
+type privateDataAccessLog interface {
+	chaincode.PrivateDataAccessLog
+}
@@ -63,6 +63,7 @@ type ChaincodeSupport struct {
 	ACLProvider            ACLProvider
 	AppConfig              ApplicationConfigRetriever
 	BuiltinSCCs            scc.BuiltinSCCs
+	CrossChannelResolver   *CrossChannelResolver
 	DeployedCCInfoProvider ledger.DeployedChaincodeInfoProvider
 	ExecuteTimeout         time.Duration
 	InstallTimeout         time.Duration
@@ -71,7 +72,10 @@ type ChaincodeSupport struct {
 	Keepalive              time.Duration
 	Launcher               Launcher
 	Lifecycle              Lifecycle
+	MaxBulkQueryBytes      int
 	Peer                   *peer.Peer
+	PvtDataAccessLog       PrivateDataAccessLog
+	Quotas                 *QuotaManager
 	Runtime                Runtime
 	TotalQueryLimit        int
 	UserRunsCC             bool
@@ -165,6 +169,10 @@ func (cs *ChaincodeSupport) HandleChaincodeStream(stream ccintf.ChaincodeStream)
 		AppConfig:              cs.AppConfig,
 		Metrics:                cs.HandlerMetrics,
 		TotalQueryLimit:        cs.TotalQueryLimit,
+		MaxBulkQueryBytes:      cs.MaxBulkQueryBytes,
+		Quotas:                 cs.Quotas,
+		CrossChannelWrites:     cs.CrossChannelResolver != nil,
+		AccessLog:              cs.PvtDataAccessLog,
 	}
 
 	return handler.ProcessStream(stream)
@@ -233,6 +241,12 @@ func (cs *ChaincodeSupport) Invoke(txParams *ccprovider.TransactionParams, chain
 		return nil, errors.WithMessage(err, "invalid invocation")
 	}
 
+	// the locks of cross-channel transactions are resolved by the peer
+	// without involving the chaincode
+	if cs.CrossChannelResolver != nil && isCrossChannelResolution(input) {
+		return cs.CrossChannelResolver.Resolve(txParams, chaincodeName, input)
+	}
+
 	h, err := cs.Launch(ccid)
 	if err != nil {
 		return nil, err
@@ -7,30 +7,74 @@ SPDX-License-Identifier: Apache-2.0
 package chaincode
 
 import (
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/config"
 	viper "github.com/spf13/viper2015"
 )
 
 const (
-	defaultExecutionTimeout = 30 * time.Second
-	minimumStartupTimeout   = 5 * time.Second
+	defaultExecutionTimeout  = 30 * time.Second
+	minimumStartupTimeout    = 5 * time.Second
+	defaultMaxBulkQueryBytes = 4 * 1024 * 1024
+	defaultInitialBackoff    = time.Second
+	defaultMaxBackoff        = time.Minute
 )
 
 type Config struct {
-	TotalQueryLimit int
-	TLSEnabled      bool
-	Keepalive       time.Duration
-	ExecuteTimeout  time.Duration
-	InstallTimeout  time.Duration
-	StartupTimeout  time.Duration
-	LogFormat       string
-	LogLevel        string
-	ShimLogLevel    string
-	SCCAllowlist    map[string]bool
+	TotalQueryLimit   int
+	MaxBulkQueryBytes int
+	TLSEnabled        bool
+	Keepalive         time.Duration
+	ExecuteTimeout    time.Duration
+	InstallTimeout    time.Duration
+	StartupTimeout    time.Duration
+	LogFormat         string
+	LogLevel          string
+	ShimLogLevel      string
+	SCCAllowlist      map[string]bool
+	Supervisor        SupervisorConfig
+	Quotas            QuotaConfig
+	CrossChannel      CrossChannelConfig
+	Recording         RecordingConfig
+
+	// PackageSignaturePolicy is the signature policy the signatures of
+	// chaincode packages must satisfy to be installed. Packages are not
+	// verified when it is empty.
+	PackageSignaturePolicy string
+}
+
+// RecordingConfig configures the recording of the chaincode executions of
+// endorsements.
+type RecordingConfig struct {
+	Enabled bool
+	Path    string
+}
+
+// CrossChannelConfig configures the endorsement of the writes of chaincodes
+// invoked on other channels.
+type CrossChannelConfig struct {
+	Enabled bool
+}
+
+// SupervisorConfig configures the restart of chaincodes which exit
+// unexpectedly.
+type SupervisorConfig struct {
+	Enabled        bool
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxRestarts    int
+	// HealthCheck makes the health check of the peer fail when
+	// a chaincode could not be restarted
+	HealthCheck bool
+	// Prelaunch launches the chaincodes deployed with the legacy lifecycle
+	// when the peer starts or joins a channel, rather than when they are
+	// first invoked
+	Prelaunch bool
 }
 
 func GlobalConfig() *Config {
@@ -53,6 +97,7 @@ func (c *Config) load() {
 		c.ExecuteTimeout = defaultExecutionTimeout
 	}
 	c.InstallTimeout = viper.GetDuration("chaincode.installTimeout")
+	c.PackageSignaturePolicy = viper.GetString("chaincode.packageSignaturePolicy")
 	c.StartupTimeout = viper.GetDuration("chaincode.startuptimeout")
 	if c.StartupTimeout < minimumStartupTimeout {
 		c.StartupTimeout = minimumStartupTimeout
@@ -71,6 +116,98 @@ func (c *Config) load() {
 	if viper.IsSet("ledger.state.totalQueryLimit") {
 		c.TotalQueryLimit = viper.GetInt("ledger.state.totalQueryLimit")
 	}
+
+	c.MaxBulkQueryBytes = viper.GetInt("chaincode.maxBulkQueryBytes")
+	if c.MaxBulkQueryBytes <= 0 {
+		c.MaxBulkQueryBytes = defaultMaxBulkQueryBytes
+	}
+
+	c.Supervisor.Enabled = viper.GetBool("chaincode.supervisor.enabled")
+	c.Supervisor.InitialBackoff = viper.GetDuration("chaincode.supervisor.initialBackoff")
+	if c.Supervisor.InitialBackoff <= 0 {
+		c.Supervisor.InitialBackoff = defaultInitialBackoff
+	}
+	c.Supervisor.MaxBackoff = viper.GetDuration("chaincode.supervisor.maxBackoff")
+	if c.Supervisor.MaxBackoff < c.Supervisor.InitialBackoff {
+		c.Supervisor.MaxBackoff = defaultMaxBackoff
+		if c.Supervisor.MaxBackoff < c.Supervisor.InitialBackoff {
+			c.Supervisor.MaxBackoff = c.Supervisor.InitialBackoff
+		}
+	}
+	c.Supervisor.MaxRestarts = viper.GetInt("chaincode.supervisor.maxRestarts")
+	c.Supervisor.HealthCheck = viper.GetBool("chaincode.supervisor.healthCheck")
+	c.Supervisor.Prelaunch = viper.GetBool("chaincode.supervisor.prelaunch")
+
+	c.Quotas = loadQuotas()
+
+	c.CrossChannel.Enabled = viper.GetBool("chaincode.crossChannel.enabled")
+
+	c.Recording.Enabled = viper.GetBool("chaincode.recording.enabled")
+	c.Recording.Path = config.GetPath("chaincode.recording.path")
+	if c.Recording.Path == "" {
+		c.Recording.Path = filepath.Join(config.GetPath("peer.fileSystemPath"), "recordings")
+	}
+}
+
+// chaincodeQuotaConfig is the configuration of a chaincode quota. The
+// execution time is parsed separately as durations are not decoded.
+type chaincodeQuotaConfig struct {
+	Name             string
+	Channel          string
+	MaxConcurrency   int
+	MaxExecutionTime string
+	MaxKeysRead      int
+	MaxKeysWritten   int
+	MaxWriteSetSize  int
+	MaxQueryResults  int
+}
+
+func loadQuotas() QuotaConfig {
+	quotas := QuotaConfig{
+		Default: QuotaLimits{
+			MaxConcurrency:   viper.GetInt("chaincode.quotas.default.maxConcurrency"),
+			MaxExecutionTime: viper.GetDuration("chaincode.quotas.default.maxExecutionTime"),
+			MaxKeysRead:      viper.GetInt("chaincode.quotas.default.maxKeysRead"),
+			MaxKeysWritten:   viper.GetInt("chaincode.quotas.default.maxKeysWritten"),
+			MaxWriteSetSize:  viper.GetInt("chaincode.quotas.default.maxWriteSetSize"),
+			MaxQueryResults:  viper.GetInt("chaincode.quotas.default.maxQueryResults"),
+		},
+	}
+
+	var chaincodes []chaincodeQuotaConfig
+	if err := viper.UnmarshalKey("chaincode.quotas.chaincodes", &chaincodes); err != nil {
+		chaincodeLogger.Warningf("ignoring invalid chaincode.quotas.chaincodes: %s", err)
+		return quotas
+	}
+	for _, cc := range chaincodes {
+		if cc.Name == "" && cc.Channel == "" {
+			chaincodeLogger.Warning("ignoring chaincode quota without name or channel")
+			continue
+		}
+		var maxExecutionTime time.Duration
+		if cc.MaxExecutionTime != "" {
+			var err error
+			maxExecutionTime, err = time.ParseDuration(cc.MaxExecutionTime)
+			if err != nil {
+				chaincodeLogger.Warningf("ignoring chaincode quota for %s on channel %s with invalid maxExecutionTime: %s", cc.Name, cc.Channel, err)
+				continue
+			}
+		}
+		quotas.Chaincodes = append(quotas.Chaincodes, ChaincodeQuota{
+			Name:    cc.Name,
+			Channel: cc.Channel,
+			QuotaLimits: QuotaLimits{
+				MaxConcurrency:   cc.MaxConcurrency,
+				MaxExecutionTime: maxExecutionTime,
+				MaxKeysRead:      cc.MaxKeysRead,
+				MaxKeysWritten:   cc.MaxKeysWritten,
+				MaxWriteSetSize:  cc.MaxWriteSetSize,
+				MaxQueryResults:  cc.MaxQueryResults,
+			},
+		})
+	}
+
+	return quotas
 }
 
 func parseBool(s string) bool {
@@ -32,20 +32,135 @@ var _ = Describe("Config", func() {
 			viper.Set("chaincode.keepalive", "50")
 			viper.Set("chaincode.executetimeout", "20h")
 			viper.Set("chaincode.installTimeout", "30m")
+			viper.Set("chaincode.packageSignaturePolicy", "OR('Org1MSP.admin')")
 			viper.Set("chaincode.startuptimeout", "30h")
 			viper.Set("chaincode.logging.format", "test-chaincode-logging-format")
 			viper.Set("chaincode.logging.level", "warning")
 			viper.Set("chaincode.logging.shim", "warning")
+			viper.Set("chaincode.maxBulkQueryBytes", 1024)
+			viper.Set("chaincode.supervisor.enabled", false)
+			viper.Set("chaincode.supervisor.initialBackoff", "2s")
+			viper.Set("chaincode.supervisor.maxBackoff", "5m")
+			viper.Set("chaincode.supervisor.maxRestarts", 7)
+			viper.Set("chaincode.supervisor.healthCheck", true)
+			viper.Set("chaincode.supervisor.prelaunch", true)
+			viper.Set("chaincode.quotas.default.maxConcurrency", 100)
+			viper.Set("chaincode.quotas.default.maxExecutionTime", "10s")
+			viper.Set("chaincode.quotas.default.maxKeysRead", 1000)
+			viper.Set("chaincode.quotas.default.maxKeysWritten", 500)
+			viper.Set("chaincode.quotas.default.maxWriteSetSize", 1048576)
+			viper.Set("chaincode.quotas.default.maxQueryResults", 2000)
+			viper.Set("chaincode.quotas.chaincodes", []interface{}{
+				map[string]interface{}{"name": "mycc", "channel": "mychannel", "maxConcurrency": 10, "maxExecutionTime": "2s"},
+				map[string]interface{}{"channel": "mychannel", "maxKeysRead": 10},
+				map[string]interface{}{"maxKeysRead": 10},
+				map[string]interface{}{"name": "othercc", "maxConcurrency": 1, "maxExecutionTime": "forever"},
+			})
+			viper.Set("chaincode.crossChannel.enabled", true)
+			viper.Set("chaincode.recording.enabled", true)
+			viper.Set("chaincode.recording.path", "/var/recordings")
 
 			config := chaincode.GlobalConfig()
 			Expect(config.TLSEnabled).To(BeTrue())
 			Expect(config.Keepalive).To(Equal(50 * time.Second))
 			Expect(config.ExecuteTimeout).To(Equal(20 * time.Hour))
 			Expect(config.InstallTimeout).To(Equal(30 * time.Minute))
+			Expect(config.PackageSignaturePolicy).To(Equal("OR('Org1MSP.admin')"))
 			Expect(config.StartupTimeout).To(Equal(30 * time.Hour))
 			Expect(config.LogFormat).To(Equal("test-chaincode-logging-format"))
 			Expect(config.LogLevel).To(Equal("warn"))
 			Expect(config.ShimLogLevel).To(Equal("warn"))
+			Expect(config.MaxBulkQueryBytes).To(Equal(1024))
+			Expect(config.Supervisor).To(Equal(chaincode.SupervisorConfig{
+				Enabled:        false,
+				InitialBackoff: 2 * time.Second,
+				MaxBackoff:     5 * time.Minute,
+				MaxRestarts:    7,
+				HealthCheck:    true,
+				Prelaunch:      true,
+			}))
+			Expect(config.Quotas).To(Equal(chaincode.QuotaConfig{
+				Default: chaincode.QuotaLimits{
+					MaxConcurrency:   100,
+					MaxExecutionTime: 10 * time.Second,
+					MaxKeysRead:      1000,
+					MaxKeysWritten:   500,
+					MaxWriteSetSize:  1048576,
+					MaxQueryResults:  2000,
+				},
+				Chaincodes: []chaincode.ChaincodeQuota{
+					{Name: "mycc", Channel: "mychannel", QuotaLimits: chaincode.QuotaLimits{MaxConcurrency: 10, MaxExecutionTime: 2 * time.Second}},
+					{Channel: "mychannel", QuotaLimits: chaincode.QuotaLimits{MaxKeysRead: 10}},
+				},
+			}))
+			Expect(config.CrossChannel).To(Equal(chaincode.CrossChannelConfig{
+				Enabled: true,
+			}))
+			Expect(config.Recording).To(Equal(chaincode.RecordingConfig{
+				Enabled: true,
+				Path:    "/var/recordings",
+			}))
+		})
+
+		Context("when the recording path is not set", func() {
+			BeforeEach(func() {
+				viper.Set("chaincode.recording.path", "")
+				viper.Set("peer.fileSystemPath", "/var/hyperledger/production")
+			})
+
+			It("records in the file system path of the peer", func() {
+				config := chaincode.GlobalConfig()
+				Expect(config.Recording.Path).To(Equal("/var/hyperledger/production/recordings"))
+			})
+		})
+
+		Context("when the max bulk query bytes is not set", func() {
+			BeforeEach(func() {
+				viper.Set("chaincode.maxBulkQueryBytes", 0)
+			})
+
+			It("falls back to the default", func() {
+				config := chaincode.GlobalConfig()
+				Expect(config.MaxBulkQueryBytes).To(Equal(4 * 1024 * 1024))
+			})
+		})
+
+		Context("when the supervisor is not configured", func() {
+			BeforeEach(func() {
+				viper.Set("chaincode.supervisor.enabled", nil)
+				viper.Set("chaincode.supervisor.healthCheck", nil)
+			})
+
+			It("leaves the supervisor and its health check disabled", func() {
+				config := chaincode.GlobalConfig()
+				Expect(config.Supervisor.Enabled).To(BeFalse())
+				Expect(config.Supervisor.HealthCheck).To(BeFalse())
+			})
+		})
+
+		Context("when the supervisor backoff is not set", func() {
+			BeforeEach(func() {
+				viper.Set("chaincode.supervisor.initialBackoff", "")
+				viper.Set("chaincode.supervisor.maxBackoff", "")
+			})
+
+			It("falls back to the defaults", func() {
+				config := chaincode.GlobalConfig()
+				Expect(config.Supervisor.InitialBackoff).To(Equal(time.Second))
+				Expect(config.Supervisor.MaxBackoff).To(Equal(time.Minute))
+			})
+		})
+
+		Context("when the supervisor max backoff is less than the initial backoff", func() {
+			BeforeEach(func() {
+				viper.Set("chaincode.supervisor.initialBackoff", "2m")
+				viper.Set("chaincode.supervisor.maxBackoff", "30s")
+			})
+
+			It("uses the initial backoff as the max backoff", func() {
+				config := chaincode.GlobalConfig()
+				Expect(config.Supervisor.MaxBackoff).To(Equal(2 * time.Minute))
+			})
 		})
 
 		Context("when an invalid keepalive is configured", func() {
@@ -121,11 +236,20 @@ func capture() (restore func()) {
 		"chaincode.logging.format": viper.GetString("chaincode.logging.format"),
 		"chaincode.logging.level":  viper.GetString("chaincode.logging.level"),
 		"chaincode.logging.shim":   viper.GetString("chaincode.logging.shim"),
+		"peer.fileSystemPath":      viper.GetString("peer.fileSystemPath"),
 	}
 
 	return func() {
 		for k, val := range config {
 			viper.Set(k, val)
 		}
+		for _, k := range []string{"maxConcurrency", "maxExecutionTime", "maxKeysRead", "maxKeysWritten", "maxWriteSetSize", "maxQueryResults"} {
+			viper.Set("chaincode.quotas.default."+k, 0)
+		}
+		viper.Set("chaincode.quotas.chaincodes", nil)
+		viper.Set("chaincode.crossChannel.enabled", false)
+		viper.Set("chaincode.recording.enabled", false)
+		viper.Set("chaincode.recording.path", "")
+		viper.Set("chaincode.packageSignaturePolicy", "")
 	}
 }
@@ -0,0 +1,525 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CrossChannelLockKey is the state metadata key under which the lock
+	// of a key written by a cross-channel invocation is stored.
+	CrossChannelLockKey = msgs.CrossChannelLockKey
+
+	// CrossChannelCommitFuncName is the function which, when invoked on a
+	// chaincode, applies the writes locked in its namespace by the
+	// cross-channel transaction whose ID is the argument. The primary
+	// transaction must have been committed as valid.
+	CrossChannelCommitFuncName = msgs.CrossChannelCommitFuncName
+
+	// CrossChannelAbortFuncName is the function which, when invoked on a
+	// chaincode, releases the locks held in its namespace by the
+	// cross-channel transaction whose ID is the argument without applying
+	// the writes. The primary transaction must have been committed as
+	// invalid.
+	CrossChannelAbortFuncName = msgs.CrossChannelAbortFuncName
+)
+
+// crossChannelSimulator rejects the reads and writes of keys locked by a
+// cross-channel transaction, including the keys returned by range scans and
+// queries. When lock is set, the simulator belongs to a chaincode invoked
+// from another channel and writes are recorded as locks on the keys instead
+// of being applied.
+//
+// Validation enforces the locks as well: a transaction which reads or
+// writes a locked key is invalid unless it commits or aborts the lock, so a
+// transaction endorsed by peers without cross-channel writes cannot bypass
+// them.
+type crossChannelSimulator struct {
+	ledger.TxSimulator
+	lock *msgs.CrossChannelLock
+}
+
+func newCrossChannelSimulator(sim ledger.TxSimulator, lock *msgs.CrossChannelLock) ledger.TxSimulator {
+	if sim == nil {
+		return nil
+	}
+	if _, ok := sim.(*crossChannelSimulator); ok {
+		return sim
+	}
+	return &crossChannelSimulator{TxSimulator: sim, lock: lock}
+}
+
+func (s *crossChannelSimulator) checkUnlocked(namespace, key string) (map[string][]byte, error) {
+	metadata, err := s.TxSimulator.GetStateMetadata(namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := msgs.GetCrossChannelLock(metadata)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid lock on key %s", key)
+	}
+	if lock != nil {
+		return nil, errors.Errorf("key %s is locked by transaction %s on channel %s", key, lock.TxId, lock.ChannelId)
+	}
+	return metadata, nil
+}
+
+func (s *crossChannelSimulator) GetState(namespace, key string) ([]byte, error) {
+	if _, err := s.checkUnlocked(namespace, key); err != nil {
+		return nil, err
+	}
+	return s.TxSimulator.GetState(namespace, key)
+}
+
+func (s *crossChannelSimulator) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
+	for _, key := range keys {
+		if _, err := s.checkUnlocked(namespace, key); err != nil {
+			return nil, err
+		}
+	}
+	return s.TxSimulator.GetStateMultipleKeys(namespace, keys)
+}
+
+func (s *crossChannelSimulator) GetStateRangeScanIterator(namespace, startKey, endKey string) (commonledger.ResultsIterator, error) {
+	itr, err := s.TxSimulator.GetStateRangeScanIterator(namespace, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	return &unlockedKeysIterator{ResultsIterator: itr, namespace: namespace, sim: s}, nil
+}
+
+func (s *crossChannelSimulator) GetStateRangeScanIteratorWithPagination(namespace, startKey, endKey string, pageSize int32) (ledger.QueryResultsIterator, error) {
+	itr, err := s.TxSimulator.GetStateRangeScanIteratorWithPagination(namespace, startKey, endKey, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &unlockedKeysQueryIterator{QueryResultsIterator: itr, namespace: namespace, sim: s}, nil
+}
+
+func (s *crossChannelSimulator) ExecuteQuery(namespace, query string) (commonledger.ResultsIterator, error) {
+	itr, err := s.TxSimulator.ExecuteQuery(namespace, query)
+	if err != nil {
+		return nil, err
+	}
+	return &unlockedKeysIterator{ResultsIterator: itr, namespace: namespace, sim: s}, nil
+}
+
+func (s *crossChannelSimulator) ExecuteQueryWithPagination(namespace, query, bookmark string, pageSize int32) (ledger.QueryResultsIterator, error) {
+	itr, err := s.TxSimulator.ExecuteQueryWithPagination(namespace, query, bookmark, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &unlockedKeysQueryIterator{QueryResultsIterator: itr, namespace: namespace, sim: s}, nil
+}
+
+func (s *crossChannelSimulator) SetState(namespace, key string, value []byte) error {
+	metadata, err := s.checkUnlocked(namespace, key)
+	if err != nil {
+		return err
+	}
+	if s.lock == nil {
+		return s.TxSimulator.SetState(namespace, key, value)
+	}
+	return s.acquire(namespace, key, value, false, metadata)
+}
+
+func (s *crossChannelSimulator) DeleteState(namespace, key string) error {
+	metadata, err := s.checkUnlocked(namespace, key)
+	if err != nil {
+		return err
+	}
+	if s.lock == nil {
+		return s.TxSimulator.DeleteState(namespace, key)
+	}
+	return s.acquire(namespace, key, nil, true, metadata)
+}
+
+func (s *crossChannelSimulator) SetStateMultipleKeys(namespace string, kvs map[string][]byte) error {
+	for key, value := range kvs {
+		if err := s.SetState(namespace, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *crossChannelSimulator) SetStateMetadata(namespace, key string, metadata map[string][]byte) error {
+	if s.lock != nil {
+		return errors.New("state metadata cannot be written by a chaincode invoked from another channel")
+	}
+	if _, err := s.checkUnlocked(namespace, key); err != nil {
+		return err
+	}
+	return s.TxSimulator.SetStateMetadata(namespace, key, metadata)
+}
+
+func (s *crossChannelSimulator) DeleteStateMetadata(namespace, key string) error {
+	if s.lock != nil {
+		return errors.New("state metadata cannot be written by a chaincode invoked from another channel")
+	}
+	if _, err := s.checkUnlocked(namespace, key); err != nil {
+		return err
+	}
+	return s.TxSimulator.DeleteStateMetadata(namespace, key)
+}
+
+func (s *crossChannelSimulator) SetPrivateData(namespace, collection, key string, value []byte) error {
+	if s.lock != nil {
+		return errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	return s.TxSimulator.SetPrivateData(namespace, collection, key, value)
+}
+
+func (s *crossChannelSimulator) SetPrivateDataMultipleKeys(namespace, collection string, kvs map[string][]byte) error {
+	if s.lock != nil {
+		return errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	return s.TxSimulator.SetPrivateDataMultipleKeys(namespace, collection, kvs)
+}
+
+func (s *crossChannelSimulator) DeletePrivateData(namespace, collection, key string) error {
+	if s.lock != nil {
+		return errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	return s.TxSimulator.DeletePrivateData(namespace, collection, key)
+}
+
+func (s *crossChannelSimulator) PurgePrivateData(namespace, collection, key string) error {
+	if s.lock != nil {
+		return errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	return s.TxSimulator.PurgePrivateData(namespace, collection, key)
+}
+
+func (s *crossChannelSimulator) SetPrivateDataMetadata(namespace, collection, key string, metadata map[string][]byte) error {
+	if s.lock != nil {
+		return errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	return s.TxSimulator.SetPrivateDataMetadata(namespace, collection, key, metadata)
+}
+
+func (s *crossChannelSimulator) DeletePrivateDataMetadata(namespace, collection, key string) error {
+	if s.lock != nil {
+		return errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	return s.TxSimulator.DeletePrivateDataMetadata(namespace, collection, key)
+}
+
+// checkResult returns an error if the result of a range scan or a query is a
+// locked key. A key created by a cross-channel transaction is written along
+// with its lock, so its tentative value is never returned.
+func (s *crossChannelSimulator) checkResult(namespace string, result commonledger.QueryResult) error {
+	kv, ok := result.(*queryresult.KV)
+	if !ok {
+		return nil
+	}
+	_, err := s.checkUnlocked(namespace, kv.Key)
+	return err
+}
+
+// unlockedKeysIterator fails on the first locked key it returns.
+type unlockedKeysIterator struct {
+	commonledger.ResultsIterator
+	namespace string
+	sim       *crossChannelSimulator
+}
+
+func (i *unlockedKeysIterator) Next() (commonledger.QueryResult, error) {
+	result, err := i.ResultsIterator.Next()
+	if err != nil || result == nil {
+		return result, err
+	}
+	if err := i.sim.checkResult(i.namespace, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// unlockedKeysQueryIterator fails on the first locked key it returns.
+type unlockedKeysQueryIterator struct {
+	ledger.QueryResultsIterator
+	namespace string
+	sim       *crossChannelSimulator
+}
+
+func (i *unlockedKeysQueryIterator) Next() (commonledger.QueryResult, error) {
+	result, err := i.QueryResultsIterator.Next()
+	if err != nil || result == nil {
+		return result, err
+	}
+	if err := i.sim.checkResult(i.namespace, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// crossChannelHistoryQueryExecutor rejects the history queries of keys
+// locked by a cross-channel transaction. The history of a key includes the
+// values written along with the locks of the keys created by cross-channel
+// transactions once they are committed.
+type crossChannelHistoryQueryExecutor struct {
+	ledger.HistoryQueryExecutor
+	sim *crossChannelSimulator
+}
+
+func newCrossChannelHistoryQueryExecutor(hqe ledger.HistoryQueryExecutor, sim ledger.TxSimulator) ledger.HistoryQueryExecutor {
+	s, ok := sim.(*crossChannelSimulator)
+	if hqe == nil || !ok {
+		return hqe
+	}
+	if _, ok := hqe.(*crossChannelHistoryQueryExecutor); ok {
+		return hqe
+	}
+	return &crossChannelHistoryQueryExecutor{HistoryQueryExecutor: hqe, sim: s}
+}
+
+func (e *crossChannelHistoryQueryExecutor) GetHistoryForKey(namespace, key string) (commonledger.ResultsIterator, error) {
+	if _, err := e.sim.checkUnlocked(namespace, key); err != nil {
+		return nil, err
+	}
+	return e.HistoryQueryExecutor.GetHistoryForKey(namespace, key)
+}
+
+// acquire records the write as a lock in the metadata of the key. Since
+// the metadata of a key that does not exist cannot be written, a key that
+// is created is written with its value along with the lock; the lock keeps
+// other transactions from reading it.
+func (s *crossChannelSimulator) acquire(namespace, key string, value []byte, delete bool, metadata map[string][]byte) error {
+	current, err := s.TxSimulator.GetState(namespace, key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		if delete {
+			// nothing to lock, but undo a creation earlier in this transaction
+			return s.TxSimulator.DeleteState(namespace, key)
+		}
+		if err := s.TxSimulator.SetState(namespace, key, value); err != nil {
+			return err
+		}
+	}
+
+	lock := proto.Clone(s.lock).(*msgs.CrossChannelLock)
+	lock.Value = value
+	lock.Delete = delete
+	lock.Created = current == nil
+	lockBytes, err := proto.Marshal(lock)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cross-channel lock")
+	}
+
+	locked := map[string][]byte{CrossChannelLockKey: lockBytes}
+	for k, v := range metadata {
+		if k != CrossChannelLockKey {
+			locked[k] = v
+		}
+	}
+	return s.TxSimulator.SetStateMetadata(namespace, key, locked)
+}
+
+// isCrossChannelResolution returns true if the input commits or aborts the
+// locks of a cross-channel transaction.
+func isCrossChannelResolution(input *pb.ChaincodeInput) bool {
+	switch chaincodeOperation(input.Args) {
+	case CrossChannelCommitFuncName, CrossChannelAbortFuncName:
+		return true
+	default:
+		return false
+	}
+}
+
+// CrossChannelResolver commits or aborts the locks acquired by the
+// cross-channel transactions on a channel.
+type CrossChannelResolver struct {
+	// LedgerGetter is used to look up the transaction which acquired the
+	// locks and the primary transaction.
+	LedgerGetter LedgerGetter
+}
+
+// Resolve commits or aborts the locks held in the namespace by the
+// transaction given as argument of the input.
+func (r *CrossChannelResolver) Resolve(txParams *ccprovider.TransactionParams, namespace string, input *pb.ChaincodeInput) (*pb.ChaincodeMessage, error) {
+	operation := chaincodeOperation(input.Args)
+	if len(input.Args) != 2 {
+		return nil, errors.Errorf("%s expects the ID of the cross-channel transaction as its only argument", operation)
+	}
+	txID := string(input.Args[1])
+
+	locks, err := r.heldLocks(txParams, namespace, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	primaryChannelID := locks[0].lock.ChannelId
+	valid, committed, err := r.primaryStatus(primaryChannelID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case CrossChannelCommitFuncName:
+		if !valid {
+			return nil, errors.Errorf("transaction %s has not been committed as valid on channel %s", txID, primaryChannelID)
+		}
+	case CrossChannelAbortFuncName:
+		if valid {
+			return nil, errors.Errorf("transaction %s has been committed as valid on channel %s", txID, primaryChannelID)
+		}
+		// a primary transaction which is not committed yet may still be
+		// committed as valid, so its locks are held until it is committed
+		if !committed {
+			return nil, errors.Errorf("transaction %s has not been committed on channel %s", txID, primaryChannelID)
+		}
+	}
+
+	for _, l := range locks {
+		if err := l.resolve(txParams.TXSimulator, namespace, operation == CrossChannelCommitFuncName); err != nil {
+			return nil, errors.WithMessagef(err, "failed to resolve lock on key %s", l.key)
+		}
+	}
+	chaincodeLogger.Infof("[%s] %s resolved %d locks of transaction %s in namespace %s", shorttxid(txParams.TxID), operation, len(locks), txID, namespace)
+
+	payload, err := proto.Marshal(&pb.Response{Status: shim.OK})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal response")
+	}
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Payload: payload, Txid: txParams.TxID, ChannelId: txParams.ChannelID}, nil
+}
+
+type heldLock struct {
+	key      string
+	lock     *msgs.CrossChannelLock
+	metadata map[string][]byte
+}
+
+// resolve applies the locked write if commit is set and releases the lock.
+func (l *heldLock) resolve(sim ledger.TxSimulator, namespace string, commit bool) error {
+	switch {
+	case commit && l.lock.Delete:
+		return sim.DeleteState(namespace, l.key)
+	case !commit && l.lock.Created:
+		return sim.DeleteState(namespace, l.key)
+	case commit:
+		if err := sim.SetState(namespace, l.key, l.lock.Value); err != nil {
+			return err
+		}
+	}
+
+	metadata := map[string][]byte{}
+	for k, v := range l.metadata {
+		if k != CrossChannelLockKey {
+			metadata[k] = v
+		}
+	}
+	if len(metadata) == 0 {
+		return sim.DeleteStateMetadata(namespace, l.key)
+	}
+	return sim.SetStateMetadata(namespace, l.key, metadata)
+}
+
+// heldLocks returns the locks still held in the namespace by the
+// transaction. The keys are those whose metadata was written with a lock by
+// the transaction, which must have been committed as valid.
+func (r *CrossChannelResolver) heldLocks(txParams *ccprovider.TransactionParams, namespace, txID string) ([]*heldLock, error) {
+	lgr := r.LedgerGetter.GetLedger(txParams.ChannelID)
+	if lgr == nil {
+		return nil, errors.Errorf("failed to find ledger for channel: %s", txParams.ChannelID)
+	}
+	ptx, err := lgr.GetTransactionByID(txID)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get transaction %s", txID)
+	}
+	if ptx.ValidationCode != int32(pb.TxValidationCode_VALID) {
+		return nil, errors.Errorf("transaction %s was committed as invalid with code %s", txID, pb.TxValidationCode(ptx.ValidationCode))
+	}
+
+	keys, err := lockedKeys(ptx.TransactionEnvelope, namespace)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get the keys locked by transaction %s", txID)
+	}
+
+	var locks []*heldLock
+	for _, key := range keys {
+		metadata, err := txParams.TXSimulator.GetStateMetadata(namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		lock, err := msgs.GetCrossChannelLock(metadata)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid lock on key %s", key)
+		}
+		// the lock may have been resolved already
+		if lock == nil || lock.TxId != txID {
+			continue
+		}
+		locks = append(locks, &heldLock{key: key, lock: lock, metadata: metadata})
+	}
+	if len(locks) == 0 {
+		return nil, errors.Errorf("transaction %s holds no locks in namespace %s", txID, namespace)
+	}
+	return locks, nil
+}
+
+// primaryStatus returns whether the primary transaction has been committed
+// on its channel and whether it is valid.
+func (r *CrossChannelResolver) primaryStatus(channelID, txID string) (valid, committed bool, err error) {
+	lgr := r.LedgerGetter.GetLedger(channelID)
+	if lgr == nil {
+		return false, false, errors.Errorf("failed to find ledger for channel: %s", channelID)
+	}
+	ptx, err := lgr.GetTransactionByID(txID)
+	if err != nil {
+		chaincodeLogger.Debugf("transaction %s not found on channel %s: %s", txID, channelID, err)
+		return false, false, nil
+	}
+	return ptx.ValidationCode == int32(pb.TxValidationCode_VALID), true, nil
+}
+
+// lockedKeys returns the keys of the namespace whose metadata is written
+// with a lock by the transaction.
+func lockedKeys(env *cb.Envelope, namespace string) ([]string, error) {
+	action, err := protoutil.GetActionFromEnvelopeMsg(env)
+	if err != nil {
+		return nil, err
+	}
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(action.Results, txRWSet); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal read-write set")
+	}
+
+	var keys []string
+	for _, nsRWSet := range txRWSet.NsRwset {
+		if nsRWSet.Namespace != namespace {
+			continue
+		}
+		kvRWSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(nsRWSet.Rwset, kvRWSet); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal read-write set of namespace %s", namespace)
+		}
+		for _, write := range kvRWSet.MetadataWrites {
+			for _, entry := range write.Entries {
+				if entry.Name == CrossChannelLockKey {
+					keys = append(keys, write.Key)
+				}
+			}
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,507 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/mock"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protoutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("CrossChannel", func() {
+	var (
+		fakeTxSimulator *mock.TxSimulator
+		lock            *msgs.CrossChannelLock
+		lockBytes       []byte
+	)
+
+	BeforeEach(func() {
+		fakeTxSimulator = &mock.TxSimulator{}
+		lock = &msgs.CrossChannelLock{
+			ChannelId: "token",
+			TxId:      "tx-id",
+			Timestamp: ptypes.TimestampNow(),
+		}
+		lockBytes = protoutil.MarshalOrPanic(lock)
+	})
+
+	Describe("crossChannelSimulator", func() {
+		var sim ledger.TxSimulator
+
+		BeforeEach(func() {
+			fakeTxSimulator.GetStateMetadataStub = func(namespace, key string) (map[string][]byte, error) {
+				if key == "locked-key" {
+					return map[string][]byte{chaincode.CrossChannelLockKey: lockBytes}, nil
+				}
+				return map[string][]byte{"VALIDATION_PARAMETER": []byte("policy")}, nil
+			}
+			fakeTxSimulator.GetStateReturns([]byte("current-value"), nil)
+		})
+
+		Context("when checking locks", func() {
+			BeforeEach(func() {
+				sim = chaincode.NewCrossChannelSimulator(fakeTxSimulator, nil)
+			})
+
+			It("does not wrap a simulator twice", func() {
+				Expect(chaincode.NewCrossChannelSimulator(sim, lock)).To(BeIdenticalTo(sim))
+				Expect(chaincode.NewCrossChannelSimulator(nil, lock)).To(BeNil())
+			})
+
+			It("reads and writes unlocked keys", func() {
+				value, err := sim.GetState("namespace", "key")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value).To(Equal([]byte("current-value")))
+
+				Expect(sim.SetState("namespace", "key", []byte("value"))).To(Succeed())
+				Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(1))
+				Expect(sim.DeleteState("namespace", "key")).To(Succeed())
+				Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(1))
+				Expect(sim.SetStateMetadata("namespace", "key", nil)).To(Succeed())
+				Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(1))
+				Expect(sim.SetPrivateData("namespace", "collection", "key", []byte("value"))).To(Succeed())
+				Expect(fakeTxSimulator.SetPrivateDataCallCount()).To(Equal(1))
+			})
+
+			It("rejects the reads and writes of locked keys", func() {
+				_, err := sim.GetState("namespace", "locked-key")
+				Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+				_, err = sim.GetStateMultipleKeys("namespace", []string{"key", "locked-key"})
+				Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+				err = sim.SetState("namespace", "locked-key", []byte("value"))
+				Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+				err = sim.DeleteState("namespace", "locked-key")
+				Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+				err = sim.SetStateMetadata("namespace", "locked-key", nil)
+				Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+
+				Expect(fakeTxSimulator.GetStateCallCount()).To(Equal(0))
+				Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(0))
+				Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(0))
+				Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(0))
+			})
+
+			Context("when range scans and queries return keys", func() {
+				var fakeIterator *mock.QueryResultsIterator
+
+				BeforeEach(func() {
+					fakeIterator = &mock.QueryResultsIterator{}
+					fakeIterator.NextReturnsOnCall(0, &queryresult.KV{Namespace: "namespace", Key: "key"}, nil)
+					fakeIterator.NextReturnsOnCall(1, &queryresult.KV{Namespace: "namespace", Key: "locked-key"}, nil)
+					fakeTxSimulator.GetStateRangeScanIteratorReturns(fakeIterator, nil)
+					fakeTxSimulator.GetStateRangeScanIteratorWithPaginationReturns(fakeIterator, nil)
+					fakeTxSimulator.ExecuteQueryReturns(fakeIterator, nil)
+					fakeTxSimulator.ExecuteQueryWithPaginationReturns(fakeIterator, nil)
+				})
+
+				DescribeTable("fails on the first locked key",
+					func(query func() (commonledger.ResultsIterator, error)) {
+						itr, err := query()
+						Expect(err).NotTo(HaveOccurred())
+
+						result, err := itr.Next()
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(&queryresult.KV{Namespace: "namespace", Key: "key"}))
+						_, err = itr.Next()
+						Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+						result, err = itr.Next()
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(BeNil())
+					},
+					Entry("range scan", func() (commonledger.ResultsIterator, error) {
+						return sim.GetStateRangeScanIterator("namespace", "a", "z")
+					}),
+					Entry("range scan with pagination", func() (commonledger.ResultsIterator, error) {
+						return sim.GetStateRangeScanIteratorWithPagination("namespace", "a", "z", 10)
+					}),
+					Entry("rich query", func() (commonledger.ResultsIterator, error) {
+						return sim.ExecuteQuery("namespace", "{}")
+					}),
+					Entry("rich query with pagination", func() (commonledger.ResultsIterator, error) {
+						return sim.ExecuteQueryWithPagination("namespace", "{}", "", 10)
+					}),
+				)
+
+				It("returns the error of the underlying query", func() {
+					fakeTxSimulator.GetStateRangeScanIteratorReturns(nil, errors.New("pineapple"))
+					_, err := sim.GetStateRangeScanIterator("namespace", "a", "z")
+					Expect(err).To(MatchError("pineapple"))
+				})
+			})
+
+			Context("when querying the history of keys", func() {
+				var (
+					fakeHistoryQueryExecutor *mock.HistoryQueryExecutor
+					hqe                      ledger.HistoryQueryExecutor
+				)
+
+				BeforeEach(func() {
+					fakeHistoryQueryExecutor = &mock.HistoryQueryExecutor{}
+					fakeHistoryQueryExecutor.GetHistoryForKeyReturns(&mock.QueryResultsIterator{}, nil)
+					hqe = chaincode.NewCrossChannelHistoryQueryExecutor(fakeHistoryQueryExecutor, sim)
+				})
+
+				It("does not wrap a history query executor twice", func() {
+					Expect(chaincode.NewCrossChannelHistoryQueryExecutor(hqe, sim)).To(BeIdenticalTo(hqe))
+					Expect(chaincode.NewCrossChannelHistoryQueryExecutor(fakeHistoryQueryExecutor, fakeTxSimulator)).To(BeIdenticalTo(fakeHistoryQueryExecutor))
+					Expect(chaincode.NewCrossChannelHistoryQueryExecutor(nil, sim)).To(BeNil())
+				})
+
+				It("returns the history of unlocked keys", func() {
+					_, err := hqe.GetHistoryForKey("namespace", "key")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeHistoryQueryExecutor.GetHistoryForKeyCallCount()).To(Equal(1))
+				})
+
+				It("rejects the history of locked keys", func() {
+					_, err := hqe.GetHistoryForKey("namespace", "locked-key")
+					Expect(err).To(MatchError("key locked-key is locked by transaction tx-id on channel token"))
+					Expect(fakeHistoryQueryExecutor.GetHistoryForKeyCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the lock cannot be unmarshaled", func() {
+				BeforeEach(func() {
+					lockBytes = []byte("garbage")
+				})
+
+				It("returns an error", func() {
+					_, err := sim.GetState("namespace", "locked-key")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(HavePrefix("invalid lock on key locked-key: failed to unmarshal cross-channel lock"))
+				})
+			})
+		})
+
+		Context("when the simulator belongs to a chaincode invoked from another channel", func() {
+			BeforeEach(func() {
+				sim = chaincode.NewCrossChannelSimulator(fakeTxSimulator, lock)
+			})
+
+			It("locks the keys which are updated", func() {
+				Expect(sim.SetState("namespace", "key", []byte("value"))).To(Succeed())
+				Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(0))
+
+				Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(1))
+				namespace, key, metadata := fakeTxSimulator.SetStateMetadataArgsForCall(0)
+				Expect(namespace).To(Equal("namespace"))
+				Expect(key).To(Equal("key"))
+				Expect(metadata).To(HaveKeyWithValue("VALIDATION_PARAMETER", []byte("policy")))
+
+				written := &msgs.CrossChannelLock{}
+				Expect(proto.Unmarshal(metadata[chaincode.CrossChannelLockKey], written)).To(Succeed())
+				Expect(written.TxId).To(Equal("tx-id"))
+				Expect(written.ChannelId).To(Equal("token"))
+				Expect(written.Value).To(Equal([]byte("value")))
+				Expect(written.Delete).To(BeFalse())
+				Expect(written.Created).To(BeFalse())
+				Expect(proto.Equal(written.Timestamp, lock.Timestamp)).To(BeTrue())
+			})
+
+			It("locks the keys which are deleted", func() {
+				Expect(sim.DeleteState("namespace", "key")).To(Succeed())
+				Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(0))
+
+				Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(1))
+				_, _, metadata := fakeTxSimulator.SetStateMetadataArgsForCall(0)
+				written := &msgs.CrossChannelLock{}
+				Expect(proto.Unmarshal(metadata[chaincode.CrossChannelLockKey], written)).To(Succeed())
+				Expect(written.Delete).To(BeTrue())
+			})
+
+			It("locks the keys of a batch", func() {
+				Expect(sim.SetStateMultipleKeys("namespace", map[string][]byte{"key1": []byte("value1"), "key2": []byte("value2")})).To(Succeed())
+				Expect(fakeTxSimulator.SetStateMultipleKeysCallCount()).To(Equal(0))
+				Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(2))
+			})
+
+			Context("when the key does not exist", func() {
+				BeforeEach(func() {
+					fakeTxSimulator.GetStateReturns(nil, nil)
+					fakeTxSimulator.GetStateMetadataReturns(nil, nil)
+					fakeTxSimulator.GetStateMetadataStub = nil
+				})
+
+				It("creates the key along with the lock", func() {
+					Expect(sim.SetState("namespace", "key", []byte("value"))).To(Succeed())
+					Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(1))
+					namespace, key, value := fakeTxSimulator.SetStateArgsForCall(0)
+					Expect(namespace).To(Equal("namespace"))
+					Expect(key).To(Equal("key"))
+					Expect(value).To(Equal([]byte("value")))
+
+					Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(1))
+					_, _, metadata := fakeTxSimulator.SetStateMetadataArgsForCall(0)
+					written := &msgs.CrossChannelLock{}
+					Expect(proto.Unmarshal(metadata[chaincode.CrossChannelLockKey], written)).To(Succeed())
+					Expect(written.Created).To(BeTrue())
+				})
+
+				It("deletes the key without locking it", func() {
+					Expect(sim.DeleteState("namespace", "key")).To(Succeed())
+					Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(1))
+					Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(0))
+				})
+			})
+
+			It("rejects writes of state metadata and private data", func() {
+				Expect(sim.SetStateMetadata("namespace", "key", nil)).To(MatchError("state metadata cannot be written by a chaincode invoked from another channel"))
+				Expect(sim.DeleteStateMetadata("namespace", "key")).To(MatchError("state metadata cannot be written by a chaincode invoked from another channel"))
+				Expect(sim.SetPrivateData("namespace", "collection", "key", nil)).To(MatchError("private data cannot be written by a chaincode invoked from another channel"))
+				Expect(sim.SetPrivateDataMultipleKeys("namespace", "collection", nil)).To(MatchError("private data cannot be written by a chaincode invoked from another channel"))
+				Expect(sim.DeletePrivateData("namespace", "collection", "key")).To(MatchError("private data cannot be written by a chaincode invoked from another channel"))
+				Expect(sim.SetPrivateDataMetadata("namespace", "collection", "key", nil)).To(MatchError("private data cannot be written by a chaincode invoked from another channel"))
+				Expect(sim.DeletePrivateDataMetadata("namespace", "collection", "key")).To(MatchError("private data cannot be written by a chaincode invoked from another channel"))
+			})
+		})
+	})
+
+	Describe("CrossChannelResolver", func() {
+		var (
+			fakeLedgerGetter  *mock.LedgerGetter
+			settlementLedger  *mock.PeerLedger
+			tokenLedger       *mock.PeerLedger
+			locks             map[string]*msgs.CrossChannelLock
+			txParams          *ccprovider.TransactionParams
+			resolver          *chaincode.CrossChannelResolver
+			commitInput       *pb.ChaincodeInput
+			abortInput        *pb.ChaincodeInput
+			prepareValidation pb.TxValidationCode
+		)
+
+		lockEnvelope := func(namespace string, keys ...string) *cb.Envelope {
+			var writes []*kvrwset.KVMetadataWrite
+			for _, key := range keys {
+				writes = append(writes, &kvrwset.KVMetadataWrite{
+					Key:     key,
+					Entries: []*kvrwset.KVMetadataEntry{{Name: chaincode.CrossChannelLockKey, Value: []byte("lock")}},
+				})
+			}
+			results := protoutil.MarshalOrPanic(&rwset.TxReadWriteSet{
+				NsRwset: []*rwset.NsReadWriteSet{
+					{Namespace: "other-namespace", Rwset: []byte("garbage")},
+					{Namespace: namespace, Rwset: protoutil.MarshalOrPanic(&kvrwset.KVRWSet{MetadataWrites: writes})},
+				},
+			})
+			prp := protoutil.MarshalOrPanic(&pb.ProposalResponsePayload{
+				Extension: protoutil.MarshalOrPanic(&pb.ChaincodeAction{Results: results}),
+			})
+			cap := protoutil.MarshalOrPanic(&pb.ChaincodeActionPayload{
+				Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: prp},
+			})
+			tx := protoutil.MarshalOrPanic(&pb.Transaction{Actions: []*pb.TransactionAction{{Payload: cap}}})
+			return &cb.Envelope{Payload: protoutil.MarshalOrPanic(&cb.Payload{Data: tx})}
+		}
+
+		BeforeEach(func() {
+			prepareValidation = pb.TxValidationCode_VALID
+
+			settlementLedger = &mock.PeerLedger{}
+			settlementLedger.GetTransactionByIDStub = func(txID string) (*pb.ProcessedTransaction, error) {
+				return &pb.ProcessedTransaction{
+					TransactionEnvelope: lockEnvelope("asset", "key1", "key2", "key3"),
+					ValidationCode:      int32(prepareValidation),
+				}, nil
+			}
+			tokenLedger = &mock.PeerLedger{}
+			tokenLedger.GetTransactionByIDReturns(&pb.ProcessedTransaction{ValidationCode: int32(pb.TxValidationCode_VALID)}, nil)
+
+			fakeLedgerGetter = &mock.LedgerGetter{}
+			fakeLedgerGetter.GetLedgerStub = func(channelID string) ledger.PeerLedger {
+				switch channelID {
+				case "settlement":
+					return settlementLedger
+				case "token":
+					return tokenLedger
+				default:
+					return nil
+				}
+			}
+
+			created := proto.Clone(lock).(*msgs.CrossChannelLock)
+			created.Value = []byte("created-value")
+			created.Created = true
+			deleted := proto.Clone(lock).(*msgs.CrossChannelLock)
+			deleted.Delete = true
+			updated := proto.Clone(lock).(*msgs.CrossChannelLock)
+			updated.Value = []byte("updated-value")
+			locks = map[string]*msgs.CrossChannelLock{"key1": created, "key2": deleted, "key3": updated}
+
+			fakeTxSimulator.GetStateMetadataStub = func(namespace, key string) (map[string][]byte, error) {
+				l, ok := locks[key]
+				if !ok {
+					return nil, nil
+				}
+				return map[string][]byte{
+					chaincode.CrossChannelLockKey: protoutil.MarshalOrPanic(l),
+					"VALIDATION_PARAMETER":        []byte("policy-" + key),
+				}, nil
+			}
+
+			txParams = &ccprovider.TransactionParams{
+				TxID:        "resolve-tx-id",
+				ChannelID:   "settlement",
+				TXSimulator: fakeTxSimulator,
+			}
+			resolver = &chaincode.CrossChannelResolver{
+				LedgerGetter: fakeLedgerGetter,
+			}
+			commitInput = &pb.ChaincodeInput{Args: [][]byte{[]byte(chaincode.CrossChannelCommitFuncName), []byte("tx-id")}}
+			abortInput = &pb.ChaincodeInput{Args: [][]byte{[]byte(chaincode.CrossChannelAbortFuncName), []byte("tx-id")}}
+		})
+
+		It("commits the locks of a transaction which is valid", func() {
+			resp, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Type).To(Equal(pb.ChaincodeMessage_COMPLETED))
+			Expect(resp.Txid).To(Equal("resolve-tx-id"))
+			Expect(resp.ChannelId).To(Equal("settlement"))
+			response := &pb.Response{}
+			Expect(proto.Unmarshal(resp.Payload, response)).To(Succeed())
+			Expect(response.Status).To(Equal(int32(200)))
+
+			Expect(settlementLedger.GetTransactionByIDArgsForCall(0)).To(Equal("tx-id"))
+			Expect(tokenLedger.GetTransactionByIDArgsForCall(0)).To(Equal("tx-id"))
+
+			Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(2))
+			namespace, key, value := fakeTxSimulator.SetStateArgsForCall(0)
+			Expect([]interface{}{namespace, key, value}).To(Equal([]interface{}{"asset", "key1", []byte("created-value")}))
+			namespace, key, value = fakeTxSimulator.SetStateArgsForCall(1)
+			Expect([]interface{}{namespace, key, value}).To(Equal([]interface{}{"asset", "key3", []byte("updated-value")}))
+
+			Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(1))
+			namespace, key = fakeTxSimulator.DeleteStateArgsForCall(0)
+			Expect([]interface{}{namespace, key}).To(Equal([]interface{}{"asset", "key2"}))
+
+			Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(2))
+			_, key, metadata := fakeTxSimulator.SetStateMetadataArgsForCall(0)
+			Expect(key).To(Equal("key1"))
+			Expect(metadata).To(Equal(map[string][]byte{"VALIDATION_PARAMETER": []byte("policy-key1")}))
+		})
+
+		It("aborts the locks of a transaction which is invalid", func() {
+			tokenLedger.GetTransactionByIDReturns(&pb.ProcessedTransaction{ValidationCode: int32(pb.TxValidationCode_MVCC_READ_CONFLICT)}, nil)
+
+			_, err := resolver.Resolve(txParams, "asset", abortInput)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(1))
+			_, key := fakeTxSimulator.DeleteStateArgsForCall(0)
+			Expect(key).To(Equal("key1"))
+			Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(0))
+			Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(2))
+			_, key, metadata := fakeTxSimulator.SetStateMetadataArgsForCall(0)
+			Expect(key).To(Equal("key2"))
+			Expect(metadata).To(Equal(map[string][]byte{"VALIDATION_PARAMETER": []byte("policy-key2")}))
+		})
+
+		It("removes the metadata of keys which have no other metadata", func() {
+			fakeTxSimulator.GetStateMetadataStub = func(namespace, key string) (map[string][]byte, error) {
+				return map[string][]byte{chaincode.CrossChannelLockKey: protoutil.MarshalOrPanic(locks[key])}, nil
+			}
+
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeTxSimulator.DeleteStateMetadataCallCount()).To(Equal(2))
+			Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(0))
+		})
+
+		It("skips the locks which were already resolved", func() {
+			delete(locks, "key1")
+			locks["key2"].TxId = "other-tx-id"
+
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(1))
+			_, key, _ := fakeTxSimulator.SetStateArgsForCall(0)
+			Expect(key).To(Equal("key3"))
+			Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(0))
+		})
+
+		It("rejects the commit of a transaction which is not valid", func() {
+			tokenLedger.GetTransactionByIDReturns(&pb.ProcessedTransaction{ValidationCode: int32(pb.TxValidationCode_MVCC_READ_CONFLICT)}, nil)
+
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).To(MatchError("transaction tx-id has not been committed as valid on channel token"))
+			Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(0))
+		})
+
+		It("rejects the abort of a transaction which is valid", func() {
+			_, err := resolver.Resolve(txParams, "asset", abortInput)
+			Expect(err).To(MatchError("transaction tx-id has been committed as valid on channel token"))
+			Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(0))
+		})
+
+		Context("when the transaction has not been committed on its channel", func() {
+			BeforeEach(func() {
+				tokenLedger.GetTransactionByIDReturns(nil, errors.New("not found"))
+			})
+
+			It("rejects the abort", func() {
+				_, err := resolver.Resolve(txParams, "asset", abortInput)
+				Expect(err).To(MatchError("transaction tx-id has not been committed on channel token"))
+				Expect(fakeTxSimulator.SetStateMetadataCallCount()).To(Equal(0))
+				Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(0))
+			})
+
+			It("rejects the commit", func() {
+				_, err := resolver.Resolve(txParams, "asset", commitInput)
+				Expect(err).To(MatchError("transaction tx-id has not been committed as valid on channel token"))
+			})
+		})
+
+		It("requires the transaction ID as argument", func() {
+			_, err := resolver.Resolve(txParams, "asset", &pb.ChaincodeInput{Args: [][]byte{[]byte(chaincode.CrossChannelCommitFuncName)}})
+			Expect(err).To(MatchError("fabric.xcc.commit expects the ID of the cross-channel transaction as its only argument"))
+		})
+
+		It("returns an error when the transaction holds no locks in the namespace", func() {
+			_, err := resolver.Resolve(txParams, "other-asset", commitInput)
+			Expect(err).To(MatchError("transaction tx-id holds no locks in namespace other-asset"))
+		})
+
+		It("returns an error when the transaction was not committed as valid", func() {
+			prepareValidation = pb.TxValidationCode_MVCC_READ_CONFLICT
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).To(MatchError("transaction tx-id was committed as invalid with code MVCC_READ_CONFLICT"))
+		})
+
+		It("returns an error when the transaction is not found", func() {
+			settlementLedger.GetTransactionByIDStub = nil
+			settlementLedger.GetTransactionByIDReturns(nil, errors.New("not found"))
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).To(MatchError("failed to get transaction tx-id: not found"))
+		})
+
+		It("returns an error when the peer is not joined to the channel of the transaction", func() {
+			locks["key1"].ChannelId = "unknown"
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).To(MatchError("failed to find ledger for channel: unknown"))
+		})
+
+		It("returns an error when the ledger is not found", func() {
+			txParams.ChannelID = "unknown"
+			_, err := resolver.Resolve(txParams, "asset", commitInput)
+			Expect(err).To(MatchError("failed to find ledger for channel: unknown"))
+		})
+	})
+})
@@ -8,6 +8,9 @@ package extcc
 
 import (
 	"context"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/container/ccintf"
@@ -26,20 +29,29 @@ type StreamHandler interface {
 	HandleChaincodeStream(stream ccintf.ChaincodeStream) error
 }
 
+// DefaultReconnectInterval is the interval at which the connection to a
+// chaincode server is retried while other servers of the chaincode remain
+// connected.
+const DefaultReconnectInterval = 5 * time.Second
+
 type ExternalChaincodeRuntime struct {
+	// ReconnectInterval is the interval at which the connection to a
+	// disconnected chaincode server is retried when the chaincode is served
+	// by several servers. Defaults to DefaultReconnectInterval.
+	ReconnectInterval time.Duration
 }
 
 // createConnection - standard grpc client creating using ClientConfig info (surprised there isn't
 // a helper method for this)
-func (i *ExternalChaincodeRuntime) createConnection(ccid string, ccinfo *ccintf.ChaincodeServerInfo) (*grpc.ClientConn, error) {
-	grpcClient, err := comm.NewGRPCClient(ccinfo.ClientConfig)
+func (i *ExternalChaincodeRuntime) createConnection(ccid, address string, clientConfig comm.ClientConfig) (*grpc.ClientConn, error) {
+	grpcClient, err := comm.NewGRPCClient(clientConfig)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "error creating grpc client to %s", ccid)
 	}
 
-	conn, err := grpcClient.NewConnection(ccinfo.Address)
+	conn, err := grpcClient.NewConnection(address)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "error creating grpc connection to %s", ccinfo.Address)
+		return nil, errors.WithMessagef(err, "error creating grpc connection to %s", address)
 	}
 
 	extccLogger.Debugf("Created external chaincode connection: %s", ccid)
@@ -47,23 +59,39 @@ func (i *ExternalChaincodeRuntime) createConnection(ccid string, ccinfo *ccintf.
 	return conn, nil
 }
 
-func (i *ExternalChaincodeRuntime) Stream(ccid string, ccinfo *ccintf.ChaincodeServerInfo, sHandler StreamHandler) error {
-	extccLogger.Debugf("Starting external chaincode connection: %s", ccid)
-	conn, err := i.createConnection(ccid, ccinfo)
+// connect creates the connection to the chaincode server at the address and
+// starts the chaincode stream.
+func (i *ExternalChaincodeRuntime) connect(ccid, address string, ccinfo *ccintf.ChaincodeServerInfo) (*grpc.ClientConn, pb.Chaincode_ConnectClient, error) {
+	conn, err := i.createConnection(ccid, address, ccinfo.ClientConfig)
 	if err != nil {
-		return errors.WithMessagef(err, "error cannot create connection for %s", ccid)
+		return nil, nil, errors.WithMessagef(err, "error cannot create connection for %s", ccid)
 	}
 
-	defer conn.Close()
-
 	//create the client and start streaming
 	client := pb.NewChaincodeClient(conn)
 
 	stream, err := client.Connect(context.Background())
 	if err != nil {
-		return errors.WithMessagef(err, "error creating grpc client connection to %s", ccid)
+		conn.Close()
+		return nil, nil, errors.WithMessagef(err, "error creating grpc client connection to %s", ccid)
 	}
 
+	return conn, stream, nil
+}
+
+func (i *ExternalChaincodeRuntime) Stream(ccid string, ccinfo *ccintf.ChaincodeServerInfo, sHandler StreamHandler) error {
+	if addresses := ccinfo.ServerAddresses(); len(addresses) > 1 {
+		return i.streamReplicas(ccid, ccinfo, addresses, sHandler)
+	}
+
+	extccLogger.Debugf("Starting external chaincode connection: %s", ccid)
+	conn, stream, err := i.connect(ccid, ccinfo.Address, ccinfo)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
 	//peer as client has to initiate the stream. Rest of the process is unchanged
 	sHandler.HandleChaincodeStream(stream)
 
@@ -71,3 +99,131 @@ func (i *ExternalChaincodeRuntime) Stream(ccid string, ccinfo *ccintf.ChaincodeS
 
 	return nil
 }
+
+// replicaConnection is the connection to one of several chaincode servers
+// serving a chaincode.
+type replicaConnection struct {
+	conn   *grpc.ClientConn
+	stream pb.Chaincode_ConnectClient
+}
+
+// liveReplicas counts the chaincode servers with an established stream. Done
+// is closed when the last stream terminates.
+type liveReplicas struct {
+	mutex sync.Mutex
+	count int
+	done  chan struct{}
+}
+
+// up records an established stream. It returns false when the chaincode is
+// no longer served and the stream must not be used.
+func (l *liveReplicas) up() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	select {
+	case <-l.done:
+		return false
+	default:
+		l.count++
+		return true
+	}
+}
+
+// down records a terminated stream.
+func (l *liveReplicas) down() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.count--
+	if l.count == 0 {
+		close(l.done)
+	}
+}
+
+// streamReplicas maintains a stream to each of the chaincode servers. The
+// stream to a server which terminates or cannot be established is retried
+// while the stream to at least one other server remains. It returns once no
+// stream remains.
+func (i *ExternalChaincodeRuntime) streamReplicas(ccid string, ccinfo *ccintf.ChaincodeServerInfo, addresses []string, sHandler StreamHandler) error {
+	extccLogger.Debugf("Starting external chaincode connections to %d servers: %s", len(addresses), ccid)
+
+	connections := make([]*replicaConnection, len(addresses))
+	errs := make([]string, len(addresses))
+	var wg sync.WaitGroup
+	for idx, address := range addresses {
+		wg.Add(1)
+		go func(idx int, address string) {
+			defer wg.Done()
+			conn, stream, err := i.connect(ccid, address, ccinfo)
+			if err != nil {
+				errs[idx] = err.Error()
+				return
+			}
+			connections[idx] = &replicaConnection{conn: conn, stream: stream}
+		}(idx, address)
+	}
+	wg.Wait()
+
+	live := &liveReplicas{done: make(chan struct{})}
+	var failed []string
+	for idx, c := range connections {
+		if c == nil {
+			extccLogger.Warningf("Could not connect to chaincode server %s of %s: %s", addresses[idx], ccid, errs[idx])
+			failed = append(failed, errs[idx])
+			continue
+		}
+		live.count++
+	}
+	if live.count == 0 {
+		return errors.Errorf("error cannot connect to any chaincode server for %s: %s", ccid, strings.Join(failed, "; "))
+	}
+
+	for idx, address := range addresses {
+		wg.Add(1)
+		go func(address string, c *replicaConnection) {
+			defer wg.Done()
+			i.serveReplica(ccid, address, ccinfo, c, live, sHandler)
+		}(address, connections[idx])
+	}
+	wg.Wait()
+
+	extccLogger.Debugf("External chaincode %s clients exited", ccid)
+
+	return nil
+}
+
+func (i *ExternalChaincodeRuntime) serveReplica(ccid, address string, ccinfo *ccintf.ChaincodeServerInfo, c *replicaConnection, live *liveReplicas, sHandler StreamHandler) {
+	interval := i.ReconnectInterval
+	if interval <= 0 {
+		interval = DefaultReconnectInterval
+	}
+
+	for {
+		if c != nil {
+			sHandler.HandleChaincodeStream(c.stream)
+			c.conn.Close()
+			extccLogger.Warningf("Connection to chaincode server %s of %s terminated", address, ccid)
+			live.down()
+		}
+
+		select {
+		case <-live.done:
+			return
+		case <-time.After(interval):
+		}
+
+		conn, stream, err := i.connect(ccid, address, ccinfo)
+		if err != nil {
+			extccLogger.Debugf("Could not reconnect to chaincode server %s of %s: %s", address, ccid, err)
+			c = nil
+			continue
+		}
+		if !live.up() {
+			conn.Close()
+			return
+		}
+		extccLogger.Infof("Reconnected to chaincode server %s of %s", address, ccid)
+		c = &replicaConnection{conn: conn, stream: stream}
+	}
+}
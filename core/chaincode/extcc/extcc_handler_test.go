@@ -8,6 +8,7 @@ package extcc_test
 
 import (
 	"net"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/extcc"
@@ -72,6 +73,94 @@ var _ = Describe("Extcc", func() {
 				Expect(streamArg).To(Not(BeNil()))
 			})
 		})
+		When("chaincode is served by several servers", func() {
+			var (
+				listeners []net.Listener
+				servers   []*grpc.Server
+				ccinfo    *ccintf.ChaincodeServerInfo
+			)
+			BeforeEach(func() {
+				listeners, servers = nil, nil
+				for n := 0; n < 2; n++ {
+					lis, err := net.Listen("tcp", "127.0.0.1:0")
+					Expect(err).NotTo(HaveOccurred())
+					serv := grpc.NewServer()
+					go serv.Serve(lis)
+					listeners = append(listeners, lis)
+					servers = append(servers, serv)
+				}
+				ccinfo = &ccintf.ChaincodeServerInfo{
+					Address:   listeners[0].Addr().String(),
+					Addresses: []string{listeners[0].Addr().String(), listeners[1].Addr().String()},
+					ClientConfig: comm.ClientConfig{
+						KaOpts:  comm.DefaultKeepaliveOptions,
+						Timeout: 10 * time.Second,
+					},
+				}
+				i.ReconnectInterval = 10 * time.Millisecond
+			})
+
+			AfterEach(func() {
+				for n := range servers {
+					servers[n].Stop()
+					listeners[n].Close()
+				}
+			})
+
+			It("streams to each server", func() {
+				err := i.Stream("ccid", ccinfo, shandler)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(shandler.HandleChaincodeStreamCallCount()).To(Equal(2))
+			})
+
+			It("reconnects to a server while another server remains connected", func() {
+				release := make(chan struct{})
+				var once sync.Once
+				shandler.HandleChaincodeStreamStub = func(ccintf.ChaincodeStream) error {
+					first := false
+					once.Do(func() { first = true })
+					if !first {
+						<-release
+					}
+					return nil
+				}
+
+				errCh := make(chan error, 1)
+				go func() { errCh <- i.Stream("ccid", ccinfo, shandler) }()
+
+				Eventually(shandler.HandleChaincodeStreamCallCount).Should(Equal(3))
+				Consistently(errCh).ShouldNot(Receive())
+
+				close(release)
+				Eventually(errCh).Should(Receive(BeNil()))
+			})
+
+			When("a server cannot be reached", func() {
+				BeforeEach(func() {
+					ccinfo.ClientConfig.Timeout = 500 * time.Millisecond
+					ccinfo.Addresses[1] = "<badaddress>"
+				})
+
+				It("streams to the other servers", func() {
+					err := i.Stream("ccid", ccinfo, shandler)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(shandler.HandleChaincodeStreamCallCount()).To(Equal(1))
+				})
+			})
+
+			When("no server can be reached", func() {
+				BeforeEach(func() {
+					ccinfo.ClientConfig.Timeout = 500 * time.Millisecond
+					ccinfo.Addresses = []string{"<badaddress>", "<otherbadaddress>"}
+				})
+
+				It("returns an error", func() {
+					err := i.Stream("ccid", ccinfo, shandler)
+					Expect(err).To(MatchError(ContainSubstring("error cannot connect to any chaincode server for ccid")))
+					Expect(err).To(MatchError(ContainSubstring("error creating grpc connection to <otherbadaddress>")))
+				})
+			})
+		})
 		Context("chaincode info incorrect", func() {
 			var (
 				ccinfo *ccintf.ChaincodeServerInfo
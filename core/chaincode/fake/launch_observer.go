@@ -0,0 +1,152 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"sync"
+)
+
+type LaunchObserver struct {
+	ExitedStub        func(string, error)
+	exitedMutex       sync.RWMutex
+	exitedArgsForCall []struct {
+		arg1 string
+		arg2 error
+	}
+	LaunchedStub        func(string)
+	launchedMutex       sync.RWMutex
+	launchedArgsForCall []struct {
+		arg1 string
+	}
+	StoppingStub        func(string)
+	stoppingMutex       sync.RWMutex
+	stoppingArgsForCall []struct {
+		arg1 string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *LaunchObserver) Exited(arg1 string, arg2 error) {
+	fake.exitedMutex.Lock()
+	fake.exitedArgsForCall = append(fake.exitedArgsForCall, struct {
+		arg1 string
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.ExitedStub
+	fake.recordInvocation("Exited", []interface{}{arg1, arg2})
+	fake.exitedMutex.Unlock()
+	if stub != nil {
+		fake.ExitedStub(arg1, arg2)
+	}
+}
+
+func (fake *LaunchObserver) ExitedCallCount() int {
+	fake.exitedMutex.RLock()
+	defer fake.exitedMutex.RUnlock()
+	return len(fake.exitedArgsForCall)
+}
+
+func (fake *LaunchObserver) ExitedCalls(stub func(string, error)) {
+	fake.exitedMutex.Lock()
+	defer fake.exitedMutex.Unlock()
+	fake.ExitedStub = stub
+}
+
+func (fake *LaunchObserver) ExitedArgsForCall(i int) (string, error) {
+	fake.exitedMutex.RLock()
+	defer fake.exitedMutex.RUnlock()
+	argsForCall := fake.exitedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *LaunchObserver) Launched(arg1 string) {
+	fake.launchedMutex.Lock()
+	fake.launchedArgsForCall = append(fake.launchedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LaunchedStub
+	fake.recordInvocation("Launched", []interface{}{arg1})
+	fake.launchedMutex.Unlock()
+	if stub != nil {
+		fake.LaunchedStub(arg1)
+	}
+}
+
+func (fake *LaunchObserver) LaunchedCallCount() int {
+	fake.launchedMutex.RLock()
+	defer fake.launchedMutex.RUnlock()
+	return len(fake.launchedArgsForCall)
+}
+
+func (fake *LaunchObserver) LaunchedCalls(stub func(string)) {
+	fake.launchedMutex.Lock()
+	defer fake.launchedMutex.Unlock()
+	fake.LaunchedStub = stub
+}
+
+func (fake *LaunchObserver) LaunchedArgsForCall(i int) string {
+	fake.launchedMutex.RLock()
+	defer fake.launchedMutex.RUnlock()
+	argsForCall := fake.launchedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *LaunchObserver) Stopping(arg1 string) {
+	fake.stoppingMutex.Lock()
+	fake.stoppingArgsForCall = append(fake.stoppingArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.StoppingStub
+	fake.recordInvocation("Stopping", []interface{}{arg1})
+	fake.stoppingMutex.Unlock()
+	if stub != nil {
+		fake.StoppingStub(arg1)
+	}
+}
+
+func (fake *LaunchObserver) StoppingCallCount() int {
+	fake.stoppingMutex.RLock()
+	defer fake.stoppingMutex.RUnlock()
+	return len(fake.stoppingArgsForCall)
+}
+
+func (fake *LaunchObserver) StoppingCalls(stub func(string)) {
+	fake.stoppingMutex.Lock()
+	defer fake.stoppingMutex.Unlock()
+	fake.StoppingStub = stub
+}
+
+func (fake *LaunchObserver) StoppingArgsForCall(i int) string {
+	fake.stoppingMutex.RLock()
+	defer fake.stoppingMutex.RUnlock()
+	argsForCall := fake.stoppingArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *LaunchObserver) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.exitedMutex.RLock()
+	defer fake.exitedMutex.RUnlock()
+	fake.launchedMutex.RLock()
+	defer fake.launchedMutex.RUnlock()
+	fake.stoppingMutex.RLock()
+	defer fake.stoppingMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *LaunchObserver) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -32,6 +32,12 @@ type LaunchRegistry struct {
 		result1 *chaincode.LaunchState
 		result2 bool
 	}
+	ReplicatedStub        func(string, string)
+	replicatedMutex       sync.RWMutex
+	replicatedArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -42,15 +48,16 @@ func (fake *LaunchRegistry) Deregister(arg1 string) error {
 	fake.deregisterArgsForCall = append(fake.deregisterArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.DeregisterStub
+	fakeReturns := fake.deregisterReturns
 	fake.recordInvocation("Deregister", []interface{}{arg1})
 	fake.deregisterMutex.Unlock()
-	if fake.DeregisterStub != nil {
-		return fake.DeregisterStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.deregisterReturns
 	return fakeReturns.result1
 }
 
@@ -102,15 +109,16 @@ func (fake *LaunchRegistry) Launching(arg1 string) (*chaincode.LaunchState, bool
 	fake.launchingArgsForCall = append(fake.launchingArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.LaunchingStub
+	fakeReturns := fake.launchingReturns
 	fake.recordInvocation("Launching", []interface{}{arg1})
 	fake.launchingMutex.Unlock()
-	if fake.LaunchingStub != nil {
-		return fake.LaunchingStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.launchingReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -159,6 +167,39 @@ func (fake *LaunchRegistry) LaunchingReturnsOnCall(i int, result1 *chaincode.Lau
 	}{result1, result2}
 }
 
+func (fake *LaunchRegistry) Replicated(arg1 string, arg2 string) {
+	fake.replicatedMutex.Lock()
+	fake.replicatedArgsForCall = append(fake.replicatedArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ReplicatedStub
+	fake.recordInvocation("Replicated", []interface{}{arg1, arg2})
+	fake.replicatedMutex.Unlock()
+	if stub != nil {
+		fake.ReplicatedStub(arg1, arg2)
+	}
+}
+
+func (fake *LaunchRegistry) ReplicatedCallCount() int {
+	fake.replicatedMutex.RLock()
+	defer fake.replicatedMutex.RUnlock()
+	return len(fake.replicatedArgsForCall)
+}
+
+func (fake *LaunchRegistry) ReplicatedCalls(stub func(string, string)) {
+	fake.replicatedMutex.Lock()
+	defer fake.replicatedMutex.Unlock()
+	fake.ReplicatedStub = stub
+}
+
+func (fake *LaunchRegistry) ReplicatedArgsForCall(i int) (string, string) {
+	fake.replicatedMutex.RLock()
+	defer fake.replicatedMutex.RUnlock()
+	argsForCall := fake.replicatedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *LaunchRegistry) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -166,6 +207,8 @@ func (fake *LaunchRegistry) Invocations() map[string][][]interface{} {
 	defer fake.deregisterMutex.RUnlock()
 	fake.launchingMutex.RLock()
 	defer fake.launchingMutex.RUnlock()
+	fake.replicatedMutex.RLock()
+	defer fake.replicatedMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,107 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"sync"
+)
+
+type SupervisedLauncher struct {
+	LaunchStub        func(string) error
+	launchMutex       sync.RWMutex
+	launchArgsForCall []struct {
+		arg1 string
+	}
+	launchReturns struct {
+		result1 error
+	}
+	launchReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *SupervisedLauncher) Launch(arg1 string) error {
+	fake.launchMutex.Lock()
+	ret, specificReturn := fake.launchReturnsOnCall[len(fake.launchArgsForCall)]
+	fake.launchArgsForCall = append(fake.launchArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LaunchStub
+	fakeReturns := fake.launchReturns
+	fake.recordInvocation("Launch", []interface{}{arg1})
+	fake.launchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *SupervisedLauncher) LaunchCallCount() int {
+	fake.launchMutex.RLock()
+	defer fake.launchMutex.RUnlock()
+	return len(fake.launchArgsForCall)
+}
+
+func (fake *SupervisedLauncher) LaunchCalls(stub func(string) error) {
+	fake.launchMutex.Lock()
+	defer fake.launchMutex.Unlock()
+	fake.LaunchStub = stub
+}
+
+func (fake *SupervisedLauncher) LaunchArgsForCall(i int) string {
+	fake.launchMutex.RLock()
+	defer fake.launchMutex.RUnlock()
+	argsForCall := fake.launchArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *SupervisedLauncher) LaunchReturns(result1 error) {
+	fake.launchMutex.Lock()
+	defer fake.launchMutex.Unlock()
+	fake.LaunchStub = nil
+	fake.launchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *SupervisedLauncher) LaunchReturnsOnCall(i int, result1 error) {
+	fake.launchMutex.Lock()
+	defer fake.launchMutex.Unlock()
+	fake.LaunchStub = nil
+	if fake.launchReturnsOnCall == nil {
+		fake.launchReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.launchReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *SupervisedLauncher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.launchMutex.RLock()
+	defer fake.launchMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *SupervisedLauncher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
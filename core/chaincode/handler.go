@@ -12,20 +12,25 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/flogging"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/core/aclmgmt/resources"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
 	"github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/protoutil"
 	"github.com/pkg/errors"
 )
 
@@ -42,7 +47,7 @@ type Registry interface {
 	Register(*Handler) error
 	Ready(string)
 	Failed(string, error)
-	Deregister(string) error
+	DeregisterHandler(*Handler) error
 }
 
 // An Invoker invokes chaincode.
@@ -91,6 +96,14 @@ type ApplicationConfigRetriever interface {
 	GetApplicationConfig(cid string) (channelconfig.Application, bool)
 }
 
+// PrivateDataAccessLog records the private data returned to chaincode.
+type PrivateDataAccessLog interface {
+	// Enabled returns true if the accesses to the collection are recorded.
+	Enabled(namespace, collection string) bool
+	// Record records the accesses in the access log of the channel.
+	Record(channelID string, entries ...*pvtdataaccesslog.Entry) error
+}
+
 // Handler implements the peer side of the chaincode stream.
 type Handler struct {
 	// Keepalive specifies the interval at which keep-alive messages are sent.
@@ -98,6 +111,9 @@ type Handler struct {
 	// TotalQueryLimit specifies the maximum number of results to return for
 	// chaincode queries.
 	TotalQueryLimit int
+	// MaxBulkQueryBytes specifies the maximum size of the results returned
+	// in a single response to a bulk range query.
+	MaxBulkQueryBytes int
 	// Invoker is used to invoke chaincode.
 	Invoker Invoker
 	// Registry is used to track active handlers.
@@ -123,12 +139,22 @@ type Handler struct {
 	AppConfig ApplicationConfigRetriever
 	// Metrics holds chaincode handler metrics
 	Metrics *HandlerMetrics
+	// Quotas provides the limits applied to the invocations of chaincodes.
+	Quotas *QuotaManager
+	// CrossChannelWrites enables the endorsement of the writes of chaincodes
+	// invoked on other channels and the locking of the keys they write.
+	CrossChannelWrites bool
+	// AccessLog records the private data returned to chaincode, if set.
+	AccessLog PrivateDataAccessLog
 
 	// state holds the current handler state. It will be created, established, or
 	// ready.
 	state State
 	// chaincodeID holds the ID of the chaincode that registered with the peer.
 	chaincodeID string
+	// outstanding holds the number of transactions sent to the chaincode
+	// which have not completed.
+	outstanding int32
 
 	// serialLock is used to serialize sends across the grpc chat stream.
 	serialLock sync.Mutex
@@ -145,7 +171,7 @@ type Handler struct {
 
 // handleMessage is called by ProcessStream to dispatch messages.
 func (h *Handler) handleMessage(msg *pb.ChaincodeMessage) error {
-	chaincodeLogger.Debugf("[%s] Fabric side handling ChaincodeMessage of type: %s in state %s", shorttxid(msg.Txid), msg.Type, h.state)
+	chaincodeLogger.Debugf("[%s] Fabric side handling ChaincodeMessage of type: %s in state %s", shorttxid(msg.Txid), msgs.TypeName(msg.Type), h.state)
 
 	if msg.Type == pb.ChaincodeMessage_KEEPALIVE {
 		return nil
@@ -166,7 +192,7 @@ func (h *Handler) handleMessageCreatedState(msg *pb.ChaincodeMessage) error {
 	case pb.ChaincodeMessage_REGISTER:
 		h.HandleRegister(msg)
 	default:
-		return fmt.Errorf("[%s] Fabric side handler cannot handle message (%s) while in created state", msg.Txid, msg.Type)
+		return fmt.Errorf("[%s] Fabric side handler cannot handle message (%s) while in created state", msg.Txid, msgs.TypeName(msg.Type))
 	}
 	return nil
 }
@@ -200,8 +226,16 @@ func (h *Handler) handleMessageReadyState(msg *pb.ChaincodeMessage) error {
 		go h.HandleTransaction(msg, h.HandleGetStateMetadata)
 	case pb.ChaincodeMessage_PUT_STATE_METADATA:
 		go h.HandleTransaction(msg, h.HandlePutStateMetadata)
+	case msgs.ChaincodeMessage_GET_STATE_MULTIPLE:
+		go h.HandleTransaction(msg, h.HandleGetStateMultiple)
+	case msgs.ChaincodeMessage_WRITE_BATCH_STATE:
+		go h.HandleTransaction(msg, h.HandleWriteBatchState)
+	case msgs.ChaincodeMessage_PURGE_PRIVATE_DATA:
+		go h.HandleTransaction(msg, h.HandlePurgePrivateData)
+	case msgs.ChaincodeMessage_GET_STATE_BY_RANGE_BULK:
+		go h.HandleTransaction(msg, h.HandleGetStateByRangeBulk)
 	default:
-		return fmt.Errorf("[%s] Fabric side handler cannot handle message (%s) while in ready state", msg.Txid, msg.Type)
+		return fmt.Errorf("[%s] Fabric side handler cannot handle message (%s) while in ready state", msg.Txid, msgs.TypeName(msg.Type))
 	}
 
 	return nil
@@ -218,7 +252,7 @@ type handleFunc func(*pb.ChaincodeMessage, *TransactionContext) (*pb.ChaincodeMe
 // returned by the delegate are sent to the chat stream. Any errors returned by the
 // delegate are packaged as chaincode error messages.
 func (h *Handler) HandleTransaction(msg *pb.ChaincodeMessage, delegate handleFunc) {
-	chaincodeLogger.Debugf("[%s] handling %s from chaincode", shorttxid(msg.Txid), msg.Type.String())
+	chaincodeLogger.Debugf("[%s] handling %s from chaincode", shorttxid(msg.Txid), msgs.TypeName(msg.Type))
 	if !h.registerTxid(msg) {
 		return
 	}
@@ -233,7 +267,7 @@ func (h *Handler) HandleTransaction(msg *pb.ChaincodeMessage, delegate handleFun
 	}
 
 	meterLabels := []string{
-		"type", msg.Type.String(),
+		"type", msgs.TypeName(msg.Type),
 		"channel", msg.ChannelId,
 		"chaincode", h.chaincodeID,
 	}
@@ -245,12 +279,16 @@ func (h *Handler) HandleTransaction(msg *pb.ChaincodeMessage, delegate handleFun
 	}
 
 	if err != nil {
-		err = errors.Wrapf(err, "%s failed: transaction ID: %s", msg.Type, msg.Txid)
-		chaincodeLogger.Errorf("[%s] Failed to handle %s. error: %+v", shorttxid(msg.Txid), msg.Type, err)
+		err = errors.Wrapf(err, "%s failed: transaction ID: %s", msgs.TypeName(msg.Type), msg.Txid)
+		chaincodeLogger.Errorf("[%s] Failed to handle %s. error: %+v", shorttxid(msg.Txid), msgs.TypeName(msg.Type), err)
 		resp = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
 	}
 
-	chaincodeLogger.Debugf("[%s] Completed %s. Sending %s", shorttxid(msg.Txid), msg.Type, resp.Type)
+	chaincodeLogger.Debugf("[%s] Completed %s. Sending %s", shorttxid(msg.Txid), msgs.TypeName(msg.Type), resp.Type)
+	if txContext != nil && txContext.execution != nil {
+		txContext.execution.Exchange(msg, resp)
+	}
+
 	h.ActiveTransactions.Remove(msg.ChannelId, msg.Txid)
 	h.serialSendAsync(resp)
 
@@ -290,7 +328,7 @@ func (h *Handler) serialSend(msg *pb.ChaincodeMessage) error {
 	defer h.serialLock.Unlock()
 
 	if err := h.chatStream.Send(msg); err != nil {
-		err = errors.WithMessagef(err, "[%s] error sending %s", shorttxid(msg.Txid), msg.Type)
+		err = errors.WithMessagef(err, "[%s] error sending %s", shorttxid(msg.Txid), msgs.TypeName(msg.Type))
 		chaincodeLogger.Errorf("%+v", err)
 		return err
 	}
@@ -343,7 +381,7 @@ func (h *Handler) checkACL(signedProp *pb.SignedProposal, proposal *pb.Proposal,
 }
 
 func (h *Handler) deregister() {
-	h.Registry.Deregister(h.chaincodeID)
+	h.Registry.DeregisterHandler(h)
 }
 
 func (h *Handler) streamDone() <-chan struct{} {
@@ -602,6 +640,9 @@ func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *Transactio
 	namespaceID := txContext.NamespaceID
 	collection := getState.Collection
 	chaincodeLogger.Debugf("[%s] getting state for chaincode %s, key %s, channel %s", shorttxid(msg.Txid), namespaceID, getState.Key, txContext.ChannelID)
+	if err := txContext.quota.read(1); err != nil {
+		return nil, err
+	}
 
 	if isCollectionSet(collection) {
 		if txContext.IsInitTransaction {
@@ -611,6 +652,9 @@ func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *Transactio
 			return nil, err
 		}
 		res, err = txContext.TXSimulator.GetPrivateData(namespaceID, collection, getState.Key)
+		if err == nil && res != nil {
+			err = h.recordPrivateDataAccess(txContext, msg.Txid, collection, getState.Key)
+		}
 	} else {
 		res, err = txContext.TXSimulator.GetState(namespaceID, getState.Key)
 	}
@@ -625,6 +669,54 @@ func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *Transactio
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
+// Handles query to ledger to get the state of multiple keys
+func (h *Handler) HandleGetStateMultiple(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
+	getStateMultiple := &msgs.GetStateMultiple{}
+	err := proto.Unmarshal(msg.Payload, getStateMultiple)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	var values [][]byte
+	namespaceID := txContext.NamespaceID
+	collection := getStateMultiple.Collection
+	chaincodeLogger.Debugf("[%s] getting state for chaincode %s, %d keys, channel %s", shorttxid(msg.Txid), namespaceID, len(getStateMultiple.Keys), txContext.ChannelID)
+	if err := txContext.quota.read(len(getStateMultiple.Keys)); err != nil {
+		return nil, err
+	}
+
+	if isCollectionSet(collection) {
+		if txContext.IsInitTransaction {
+			return nil, errors.New("private data APIs are not allowed in chaincode Init()")
+		}
+		if err := errorIfCreatorHasNoReadPermission(namespaceID, collection, txContext); err != nil {
+			return nil, err
+		}
+		values, err = txContext.TXSimulator.GetPrivateDataMultipleKeys(namespaceID, collection, getStateMultiple.Keys)
+		if err == nil {
+			var keys []string
+			for i, value := range values {
+				if value != nil {
+					keys = append(keys, getStateMultiple.Keys[i])
+				}
+			}
+			err = h.recordPrivateDataAccess(txContext, msg.Txid, collection, keys...)
+		}
+	} else {
+		values, err = txContext.TXSimulator.GetStateMultipleKeys(namespaceID, getStateMultiple.Keys)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	payload, err := proto.Marshal(&msgs.GetStateMultipleResult{Values: values})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payload, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
 func (h *Handler) HandleGetPrivateDataHash(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
 	getState := &pb.GetState{}
 	err := proto.Unmarshal(msg.Payload, getState)
@@ -636,6 +728,9 @@ func (h *Handler) HandleGetPrivateDataHash(msg *pb.ChaincodeMessage, txContext *
 	namespaceID := txContext.NamespaceID
 	collection := getState.Collection
 	chaincodeLogger.Debugf("[%s] getting private data hash for chaincode %s, key %s, channel %s", shorttxid(msg.Txid), namespaceID, getState.Key, txContext.ChannelID)
+	if err := txContext.quota.read(1); err != nil {
+		return nil, err
+	}
 	if txContext.IsInitTransaction {
 		return nil, errors.New("private data APIs are not allowed in chaincode Init()")
 	}
@@ -666,6 +761,9 @@ func (h *Handler) HandleGetStateMetadata(msg *pb.ChaincodeMessage, txContext *Tr
 	namespaceID := txContext.NamespaceID
 	collection := getStateMetadata.Collection
 	chaincodeLogger.Debugf("[%s] getting state metadata for chaincode %s, key %s, channel %s", shorttxid(msg.Txid), namespaceID, getStateMetadata.Key, txContext.ChannelID)
+	if err := txContext.quota.read(1); err != nil {
+		return nil, err
+	}
 
 	var metadata map[string][]byte
 	if isCollectionSet(collection) {
@@ -724,6 +822,7 @@ func (h *Handler) HandleGetStateByRange(msg *pb.ChaincodeMessage, txContext *Tra
 		}
 		rangeIter, err = txContext.TXSimulator.GetPrivateDataRangeScanIterator(namespaceID, collection,
 			getStateByRange.StartKey, getStateByRange.EndKey)
+		rangeIter = h.accessLoggingIterator(txContext, msg.Txid, collection, rangeIter)
 	} else if isMetadataSetForPagination(metadata) {
 		isPaginated = true
 		startKey := getStateByRange.StartKey
@@ -747,6 +846,10 @@ func (h *Handler) HandleGetStateByRange(msg *pb.ChaincodeMessage, txContext *Tra
 		txContext.CleanupQueryContext(iterID)
 		return nil, errors.WithStack(err)
 	}
+	if err := txContext.quota.results(len(payload.GetResults())); err != nil {
+		txContext.CleanupQueryContext(iterID)
+		return nil, err
+	}
 
 	payloadBytes, err := proto.Marshal(payload)
 	if err != nil {
@@ -778,6 +881,10 @@ func (h *Handler) HandleQueryStateNext(msg *pb.ChaincodeMessage, txContext *Tran
 		txContext.CleanupQueryContext(queryStateNext.Id)
 		return nil, errors.WithStack(err)
 	}
+	if err := txContext.quota.results(len(payload.GetResults())); err != nil {
+		txContext.CleanupQueryContext(queryStateNext.Id)
+		return nil, err
+	}
 
 	payloadBytes, err := proto.Marshal(payload)
 	if err != nil {
@@ -788,6 +895,105 @@ func (h *Handler) HandleQueryStateNext(msg *pb.ChaincodeMessage, txContext *Tran
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
+// Handles query to ledger to range query state, returning all results in a
+// single response unless they exceed MaxBulkQueryBytes. In that case the
+// remaining results are retrieved with QUERY_STATE_NEXT.
+func (h *Handler) HandleGetStateByRangeBulk(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
+	getStateByRange := &pb.GetStateByRange{}
+	err := proto.Unmarshal(msg.Payload, getStateByRange)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	metadata, err := getQueryMetadataFromBytes(getStateByRange.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if isMetadataSetForPagination(metadata) {
+		return nil, errors.New("pagination is not supported for bulk range queries")
+	}
+
+	iterID := h.UUIDGenerator.New()
+	var rangeIter commonledger.ResultsIterator
+	namespaceID := txContext.NamespaceID
+	collection := getStateByRange.Collection
+	if isCollectionSet(collection) {
+		if txContext.IsInitTransaction {
+			return nil, errors.New("private data APIs are not allowed in chaincode Init()")
+		}
+		if err := errorIfCreatorHasNoReadPermission(namespaceID, collection, txContext); err != nil {
+			return nil, err
+		}
+		rangeIter, err = txContext.TXSimulator.GetPrivateDataRangeScanIterator(namespaceID, collection,
+			getStateByRange.StartKey, getStateByRange.EndKey)
+		rangeIter = h.accessLoggingIterator(txContext, msg.Txid, collection, rangeIter)
+	} else {
+		rangeIter, err = txContext.TXSimulator.GetStateRangeScanIterator(namespaceID, getStateByRange.StartKey, getStateByRange.EndKey)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	txContext.InitializeQueryContext(iterID, rangeIter)
+
+	payload, err := h.buildBulkQueryResponse(txContext, rangeIter, iterID)
+	if err != nil {
+		txContext.CleanupQueryContext(iterID)
+		return nil, errors.WithStack(err)
+	}
+	if err := txContext.quota.results(len(payload.GetResults())); err != nil {
+		txContext.CleanupQueryContext(iterID)
+		return nil, err
+	}
+
+	payloadBytes, err := proto.Marshal(payload)
+	if err != nil {
+		txContext.CleanupQueryContext(iterID)
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	chaincodeLogger.Debugf("Got %d keys and values. Sending %s", len(payload.Results), pb.ChaincodeMessage_RESPONSE)
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
+// buildBulkQueryResponse collects the results of the iterator until it is
+// exhausted, the total query limit is reached or adding the next result would
+// exceed MaxBulkQueryBytes. In the latter case the result is left pending for
+// the next QUERY_STATE_NEXT and the iterator is kept open.
+func (h *Handler) buildBulkQueryResponse(txContext *TransactionContext, iter commonledger.ResultsIterator, iterID string) (*pb.QueryResponse, error) {
+	totalReturnLimit := h.calculateTotalReturnLimit(nil)
+	totalReturnCount := txContext.GetTotalReturnCount(iterID)
+
+	var results []*pb.QueryResultBytes
+	size := 0
+	for *totalReturnCount < totalReturnLimit {
+		queryResult, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if queryResult == nil {
+			break
+		}
+
+		resultBytes, err := proto.Marshal(queryResult.(proto.Message))
+		if err != nil {
+			return nil, err
+		}
+		*totalReturnCount++
+
+		if len(results) > 0 && size+len(resultBytes) > h.MaxBulkQueryBytes {
+			if err := txContext.GetPendingQueryResult(iterID).Add(queryResult); err != nil {
+				return nil, err
+			}
+			return &pb.QueryResponse{Results: results, HasMore: true, Id: iterID}, nil
+		}
+		results = append(results, &pb.QueryResultBytes{ResultBytes: resultBytes})
+		size += len(resultBytes)
+	}
+
+	txContext.CleanupQueryContext(iterID)
+	return &pb.QueryResponse{Results: results, HasMore: false, Id: iterID}, nil
+}
+
 // Handles the closing of a state iterator
 func (h *Handler) HandleQueryStateClose(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
 	queryStateClose := &pb.QueryStateClose{}
@@ -838,6 +1044,7 @@ func (h *Handler) HandleGetQueryResult(msg *pb.ChaincodeMessage, txContext *Tran
 			return nil, err
 		}
 		executeIter, err = txContext.TXSimulator.ExecuteQueryOnPrivateData(namespaceID, collection, getQueryResult.Query)
+		executeIter = h.accessLoggingIterator(txContext, msg.Txid, collection, executeIter)
 	} else if isMetadataSetForPagination(metadata) {
 		isPaginated = true
 		executeIter, err = txContext.TXSimulator.ExecuteQueryWithPagination(namespaceID,
@@ -857,6 +1064,10 @@ func (h *Handler) HandleGetQueryResult(msg *pb.ChaincodeMessage, txContext *Tran
 		txContext.CleanupQueryContext(iterID)
 		return nil, errors.WithStack(err)
 	}
+	if err := txContext.quota.results(len(payload.GetResults())); err != nil {
+		txContext.CleanupQueryContext(iterID)
+		return nil, err
+	}
 
 	payloadBytes, err := proto.Marshal(payload)
 	if err != nil {
@@ -895,6 +1106,10 @@ func (h *Handler) HandleGetHistoryForKey(msg *pb.ChaincodeMessage, txContext *Tr
 		txContext.CleanupQueryContext(iterID)
 		return nil, errors.WithStack(err)
 	}
+	if err := txContext.quota.results(len(payload.GetResults())); err != nil {
+		txContext.CleanupQueryContext(iterID)
+		return nil, err
+	}
 
 	payloadBytes, err := proto.Marshal(payload)
 	if err != nil {
@@ -987,24 +1202,31 @@ func (h *Handler) HandlePutState(msg *pb.ChaincodeMessage, txContext *Transactio
 		return nil, errors.Wrap(err, "unmarshal failed")
 	}
 
+	if err := writeState(txContext, putState.Collection, putState.Key, putState.Value); err != nil {
+		return nil, err
+	}
+
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
+func writeState(txContext *TransactionContext, collection, key string, value []byte) error {
+	if err := txContext.quota.write(valueWrite, collection, key, len(key)+len(value)); err != nil {
+		return err
+	}
 	namespaceID := txContext.NamespaceID
-	collection := putState.Collection
+	var err error
 	if isCollectionSet(collection) {
 		if txContext.IsInitTransaction {
-			return nil, errors.New("private data APIs are not allowed in chaincode Init()")
+			return errors.New("private data APIs are not allowed in chaincode Init()")
 		}
 		if err := errorIfCreatorHasNoWritePermission(namespaceID, collection, txContext); err != nil {
-			return nil, err
+			return err
 		}
-		err = txContext.TXSimulator.SetPrivateData(namespaceID, collection, putState.Key, putState.Value)
+		err = txContext.TXSimulator.SetPrivateData(namespaceID, collection, key, value)
 	} else {
-		err = txContext.TXSimulator.SetState(namespaceID, putState.Key, putState.Value)
-	}
-	if err != nil {
-		return nil, errors.WithStack(err)
+		err = txContext.TXSimulator.SetState(namespaceID, key, value)
 	}
-
-	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+	return errors.WithStack(err)
 }
 
 func (h *Handler) HandlePutStateMetadata(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
@@ -1021,6 +1243,10 @@ func (h *Handler) HandlePutStateMetadata(msg *pb.ChaincodeMessage, txContext *Tr
 
 	metadata := make(map[string][]byte)
 	metadata[putStateMetadata.Metadata.Metakey] = putStateMetadata.Metadata.Value
+	size := len(putStateMetadata.Key) + len(putStateMetadata.Metadata.Metakey) + len(putStateMetadata.Metadata.Value)
+	if err := txContext.quota.write(metadataWrite, putStateMetadata.Collection, putStateMetadata.Key, size); err != nil {
+		return nil, err
+	}
 
 	namespaceID := txContext.NamespaceID
 	collection := putStateMetadata.Collection
@@ -1049,24 +1275,96 @@ func (h *Handler) HandleDelState(msg *pb.ChaincodeMessage, txContext *Transactio
 		return nil, errors.Wrap(err, "unmarshal failed")
 	}
 
+	if err := deleteState(txContext, delState.Collection, delState.Key); err != nil {
+		return nil, err
+	}
+
+	// Send response msg back to chaincode.
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
+func deleteState(txContext *TransactionContext, collection, key string) error {
+	if err := txContext.quota.write(valueWrite, collection, key, len(key)); err != nil {
+		return err
+	}
 	namespaceID := txContext.NamespaceID
-	collection := delState.Collection
+	var err error
 	if isCollectionSet(collection) {
 		if txContext.IsInitTransaction {
-			return nil, errors.New("private data APIs are not allowed in chaincode Init()")
+			return errors.New("private data APIs are not allowed in chaincode Init()")
 		}
 		if err := errorIfCreatorHasNoWritePermission(namespaceID, collection, txContext); err != nil {
-			return nil, err
+			return err
 		}
-		err = txContext.TXSimulator.DeletePrivateData(namespaceID, collection, delState.Key)
+		err = txContext.TXSimulator.DeletePrivateData(namespaceID, collection, key)
 	} else {
-		err = txContext.TXSimulator.DeleteState(namespaceID, delState.Key)
+		err = txContext.TXSimulator.DeleteState(namespaceID, key)
 	}
+	return errors.WithStack(err)
+}
+
+// Handles the purge of a private data key. The current value and all the
+// historical versions of the key are removed from the private data of the
+// peers once the transaction is committed.
+func (h *Handler) HandlePurgePrivateData(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
+	delState := &pb.DelState{}
+	err := proto.Unmarshal(msg.Payload, delState)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	if err := purgePrivateData(txContext, delState.Collection, delState.Key); err != nil {
+		return nil, err
+	}
+
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
+func purgePrivateData(txContext *TransactionContext, collection, key string) error {
+	if !isCollectionSet(collection) {
+		return errors.New("only private data can be purged")
+	}
+	if err := txContext.quota.write(purgeWrite, collection, key, len(key)); err != nil {
+		return err
+	}
+	namespaceID := txContext.NamespaceID
+	if txContext.IsInitTransaction {
+		return errors.New("private data APIs are not allowed in chaincode Init()")
+	}
+	if err := errorIfCreatorHasNoWritePermission(namespaceID, collection, txContext); err != nil {
+		return err
+	}
+	return errors.WithStack(txContext.TXSimulator.PurgePrivateData(namespaceID, collection, key))
+}
+
+// Handles a batch of puts, deletes and purges. The writes are applied in order
+// exactly as if they were sent as separate PUT_STATE, DEL_STATE and
+// PURGE_PRIVATE_DATA messages.
+func (h *Handler) HandleWriteBatchState(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
+	batch := &msgs.WriteBatchState{}
+	err := proto.Unmarshal(msg.Payload, batch)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	chaincodeLogger.Debugf("[%s] writing batch of %d records for chaincode %s, channel %s", shorttxid(msg.Txid), len(batch.Rec), txContext.NamespaceID, txContext.ChannelID)
+
+	for i, rec := range batch.Rec {
+		switch rec.Type {
+		case msgs.WriteRecord_PUT_STATE:
+			err = writeState(txContext, rec.Collection, rec.Key, rec.Value)
+		case msgs.WriteRecord_DEL_STATE:
+			err = deleteState(txContext, rec.Collection, rec.Key)
+		case msgs.WriteRecord_PURGE_PRIVATE_DATA:
+			err = purgePrivateData(txContext, rec.Collection, rec.Key)
+		default:
+			err = errors.Errorf("unsupported write type %s", rec.Type)
+		}
+		if err != nil {
+			return nil, errors.WithMessagef(err, "record %d [%s] failed", i, rec.Key)
+		}
 	}
 
-	// Send response msg back to chaincode.
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
@@ -1107,12 +1405,19 @@ func (h *Handler) HandleInvokeChaincode(msg *pb.ChaincodeMessage, txContext *Tra
 	// Set up a new context for the called chaincode if on a different channel
 	// We grab the called channel's ledger simulator to hold the new state
 	txParams := &ccprovider.TransactionParams{
-		TxID:                 msg.Txid,
-		ChannelID:            targetInstance.ChannelID,
-		SignedProp:           txContext.SignedProp,
-		Proposal:             txContext.Proposal,
-		TXSimulator:          txContext.TXSimulator,
-		HistoryQueryExecutor: txContext.HistoryQueryExecutor,
+		TxID:                    msg.Txid,
+		ChannelID:               targetInstance.ChannelID,
+		SignedProp:              txContext.SignedProp,
+		Proposal:                txContext.Proposal,
+		TXSimulator:             txContext.TXSimulator,
+		HistoryQueryExecutor:    txContext.HistoryQueryExecutor,
+		CrossChannelInvocations: txContext.CrossChannelInvocations,
+		Recording:               txContext.Recording,
+	}
+
+	invocations := txContext.CrossChannelInvocations
+	if targetInstance.ChannelID != txContext.ChannelID && h.CrossChannelWrites && invocations != nil && targetInstance.ChannelID != invocations.ChannelID {
+		return h.invokeCrossChannel(msg, txParams, targetInstance, chaincodeSpec.Input)
 	}
 
 	if targetInstance.ChannelID != txContext.ChannelID {
@@ -1152,6 +1457,82 @@ func (h *Handler) HandleInvokeChaincode(msg *pb.ChaincodeMessage, txContext *Tra
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
+// invokeCrossChannel invokes a chaincode on another channel with a simulator
+// which locks the keys written by the chaincode instead of writing them. The
+// invocation is recorded with its simulator so that the writes are endorsed
+// as a separate transaction on that channel.
+func (h *Handler) invokeCrossChannel(msg *pb.ChaincodeMessage, txParams *ccprovider.TransactionParams, targetInstance *sysccprovider.ChaincodeInstance, input *pb.ChaincodeInput) (*pb.ChaincodeMessage, error) {
+	lgr := h.LedgerGetter.GetLedger(targetInstance.ChannelID)
+	if lgr == nil {
+		return nil, errors.Errorf("failed to find ledger for channel: %s", targetInstance.ChannelID)
+	}
+
+	hdr, err := protoutil.UnmarshalHeader(txParams.Proposal.Header)
+	if err != nil {
+		return nil, err
+	}
+	chdr, err := protoutil.UnmarshalChannelHeader(hdr.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	sim, err := lgr.NewTxSimulator(msg.Txid)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	recorded := false
+	defer func() {
+		if !recorded {
+			sim.Done()
+		}
+	}()
+
+	hqe, err := lgr.NewHistoryQueryExecutor()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	lock := &msgs.CrossChannelLock{
+		ChannelId: txParams.CrossChannelInvocations.ChannelID,
+		TxId:      msg.Txid,
+		Timestamp: chdr.Timestamp,
+	}
+	txParams.TXSimulator = newCrossChannelSimulator(sim, lock)
+	txParams.HistoryQueryExecutor = newCrossChannelHistoryQueryExecutor(hqe, txParams.TXSimulator)
+
+	responseMessage, err := h.Invoker.Invoke(txParams, targetInstance.ChaincodeName, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute failed")
+	}
+
+	if responseMessage.Type == pb.ChaincodeMessage_COMPLETED {
+		response := &pb.Response{}
+		if err := proto.Unmarshal(responseMessage.Payload, response); err != nil {
+			return nil, errors.Wrap(err, "unmarshal failed")
+		}
+		if response.Status < shim.ERRORTHRESHOLD {
+			err := txParams.CrossChannelInvocations.Add(&ccprovider.CrossChannelInvocation{
+				ChannelID:     targetInstance.ChannelID,
+				ChaincodeName: targetInstance.ChaincodeName,
+				Input:         input,
+				Response:      response,
+				TXSimulator:   sim,
+			})
+			if err != nil {
+				return nil, err
+			}
+			recorded = true
+		}
+	}
+
+	res, err := proto.Marshal(responseMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
 func (h *Handler) Execute(txParams *ccprovider.TransactionParams, namespace string, msg *pb.ChaincodeMessage, timeout time.Duration) (*pb.ChaincodeMessage, error) {
 	chaincodeLogger.Debugf("Entry")
 	defer chaincodeLogger.Debugf("Exit")
@@ -1160,16 +1541,42 @@ func (h *Handler) Execute(txParams *ccprovider.TransactionParams, namespace stri
 	txParams.IsInitTransaction = (msg.Type == pb.ChaincodeMessage_INIT)
 	txParams.NamespaceID = namespace
 
+	limits := h.Quotas.Limits(msg.ChannelId, namespace)
+	release, err := h.Quotas.Acquire(msg.ChannelId, namespace, limits)
+	if err != nil {
+		h.Metrics.QuotaRejections.With("channel", msg.ChannelId, "chaincode", namespace, "quota", QuotaConcurrency).Add(1)
+		return nil, err
+	}
+	defer release()
+
+	executionTimeLimited := false
+	if limits.MaxExecutionTime > 0 && limits.MaxExecutionTime < timeout {
+		timeout = limits.MaxExecutionTime
+		executionTimeLimited = true
+	}
+
 	txctx, err := h.TXContexts.Create(txParams)
 	if err != nil {
 		return nil, err
 	}
 	defer h.TXContexts.Delete(msg.ChannelId, msg.Txid)
+	txctx.quota = newTransactionQuota(msg.ChannelId, namespace, limits, h.Metrics.QuotaRejections)
+	if h.CrossChannelWrites {
+		txctx.TXSimulator = newCrossChannelSimulator(txctx.TXSimulator, nil)
+		txctx.HistoryQueryExecutor = newCrossChannelHistoryQueryExecutor(txctx.HistoryQueryExecutor, txctx.TXSimulator)
+	}
+
+	atomic.AddInt32(&h.outstanding, 1)
+	defer atomic.AddInt32(&h.outstanding, -1)
 
 	if err := h.setChaincodeProposal(txParams.SignedProp, txParams.Proposal, msg); err != nil {
 		return nil, err
 	}
 
+	if txParams.Recording != nil {
+		txctx.execution = txParams.Recording.StartExecution(namespace, msg)
+	}
+
 	h.serialSendAsync(msg)
 
 	var ccresp *pb.ChaincodeMessage
@@ -1180,10 +1587,18 @@ func (h *Handler) Execute(txParams *ccprovider.TransactionParams, namespace stri
 	case <-time.After(timeout):
 		err = errors.New("timeout expired while executing transaction")
 		h.Metrics.ExecuteTimeouts.With("chaincode", h.chaincodeID).Add(1)
+		if executionTimeLimited {
+			h.Metrics.QuotaRejections.With("channel", msg.ChannelId, "chaincode", namespace, "quota", QuotaExecutionTime).Add(1)
+			err = errors.WithMessage(QuotaExceededError{ChannelID: msg.ChannelId, ChaincodeName: namespace, Quota: QuotaExecutionTime, Limit: limits.MaxExecutionTime}, "timeout expired while executing transaction")
+		}
 	case <-h.streamDone():
 		err = errors.New("chaincode stream terminated")
 	}
 
+	if ccresp != nil && txctx.execution != nil {
+		txctx.execution.Finish(ccresp)
+	}
+
 	return ccresp, err
 }
 
@@ -1209,6 +1624,9 @@ func (h *Handler) getCollectionStore(channelID string) privdata.CollectionStore
 func (h *Handler) State() State { return h.state }
 func (h *Handler) Close()       { h.TXContexts.Close() }
 
+// Outstanding returns the number of transactions executing in the chaincode.
+func (h *Handler) Outstanding() int { return int(atomic.LoadInt32(&h.outstanding)) }
+
 type State int
 
 const (
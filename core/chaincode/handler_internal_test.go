@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package chaincode
 
 import (
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
 	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/core/ledger"
 )
 
 // Helpers to access unexported state.
@@ -16,6 +18,10 @@ func SetHandlerChaincodeID(h *Handler, chaincodeID string) {
 	h.chaincodeID = chaincodeID
 }
 
+func SetHandlerOutstanding(h *Handler, outstanding int32) {
+	h.outstanding = outstanding
+}
+
 func SetHandlerChatStream(h *Handler, chatStream ccintf.ChaincodeStream) {
 	h.chatStream = chatStream
 }
@@ -27,3 +33,11 @@ func StreamDone(h *Handler) <-chan struct{} {
 func SetStreamDoneChan(h *Handler, ch chan struct{}) {
 	h.streamDoneChan = ch
 }
+
+func NewCrossChannelSimulator(sim ledger.TxSimulator, lock *msgs.CrossChannelLock) ledger.TxSimulator {
+	return newCrossChannelSimulator(sim, lock)
+}
+
+func NewCrossChannelHistoryQueryExecutor(hqe ledger.HistoryQueryExecutor, sim ledger.TxSimulator) ledger.HistoryQueryExecutor {
+	return newCrossChannelHistoryQueryExecutor(hqe, sim)
+}
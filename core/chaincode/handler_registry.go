@@ -17,8 +17,9 @@ import (
 type HandlerRegistry struct {
 	allowUnsolicitedRegistration bool // from cs.userRunsCC
 
-	mutex     sync.Mutex              // lock covering handlers and launching
+	mutex     sync.Mutex              // lock covering handlers, replicas and launching
 	handlers  map[string]*Handler     // chaincode cname to associated handler
+	replicas  map[string]*replicaSet  // chaincode cname to handlers of chaincode served by several servers
 	launching map[string]*LaunchState // launching chaincodes to LaunchState
 }
 
@@ -60,6 +61,7 @@ func (l *LaunchState) Notify(err error) {
 func NewHandlerRegistry(allowUnsolicitedRegistration bool) *HandlerRegistry {
 	return &HandlerRegistry{
 		handlers:                     map[string]*Handler{},
+		replicas:                     map[string]*replicaSet{},
 		launching:                    map[string]*LaunchState{},
 		allowUnsolicitedRegistration: allowUnsolicitedRegistration,
 	}
@@ -114,12 +116,44 @@ func (r *HandlerRegistry) Failed(ccid string, err error) {
 	}
 }
 
-// Handler retrieves the handler for a chaincode instance.
+// Replicated indicates that the chaincode being launched is served by
+// several chaincode servers. A handler is registered for each of them and
+// transactions are distributed across the handlers using the named load
+// balancing strategy.
+func (r *HandlerRegistry) Replicated(ccid, loadBalancing string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.replicas[ccid]; !ok {
+		r.replicas[ccid] = &replicaSet{loadBalancing: loadBalancing, last: -1}
+	}
+}
+
+// Handler retrieves the handler for a chaincode instance. When the chaincode
+// is served by several chaincode servers, the handler of one of them is
+// selected according to the load balancing strategy.
 func (r *HandlerRegistry) Handler(ccid string) *Handler {
 	r.mutex.Lock()
-	h := r.handlers[ccid]
-	r.mutex.Unlock()
-	return h
+	defer r.mutex.Unlock()
+
+	if replicas, ok := r.replicas[ccid]; ok && len(replicas.handlers) > 0 {
+		return replicas.next()
+	}
+	return r.handlers[ccid]
+}
+
+// handlersFor returns all handlers registered for a chaincode instance.
+func (r *HandlerRegistry) handlersFor(ccid string) []*Handler {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if replicas, ok := r.replicas[ccid]; ok && len(replicas.handlers) > 0 {
+		return append([]*Handler(nil), replicas.handlers...)
+	}
+	if h := r.handlers[ccid]; h != nil {
+		return []*Handler{h}
+	}
+	return nil
 }
 
 // Register adds a chaincode handler to the registry.
@@ -130,7 +164,8 @@ func (r *HandlerRegistry) Register(h *Handler) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if r.handlers[h.chaincodeID] != nil {
+	replicas := r.replicas[h.chaincodeID]
+	if r.handlers[h.chaincodeID] != nil && replicas == nil {
 		chaincodeLogger.Debugf("duplicate registered handler(key:%s) return error", h.chaincodeID)
 		return errors.Errorf("duplicate chaincodeID: %s", h.chaincodeID)
 	}
@@ -141,7 +176,12 @@ func (r *HandlerRegistry) Register(h *Handler) error {
 		return errors.Errorf("peer will not accept external chaincode connection %s (except in dev mode)", h.chaincodeID)
 	}
 
-	r.handlers[h.chaincodeID] = h
+	if r.handlers[h.chaincodeID] == nil {
+		r.handlers[h.chaincodeID] = h
+	}
+	if replicas != nil {
+		replicas.add(h)
+	}
 
 	chaincodeLogger.Debugf("registered handler complete for chaincode %s", h.chaincodeID)
 	return nil
@@ -154,34 +194,73 @@ func (r *HandlerRegistry) Deregister(ccid string) error {
 	chaincodeLogger.Debugf("deregister handler: %s", ccid)
 
 	r.mutex.Lock()
-	handler := r.handlers[ccid]
+	handlers := []*Handler{r.handlers[ccid]}
+	if replicas, ok := r.replicas[ccid]; ok && len(replicas.handlers) > 0 {
+		handlers = replicas.handlers
+	}
 	delete(r.handlers, ccid)
+	delete(r.replicas, ccid)
 	delete(r.launching, ccid)
 	r.mutex.Unlock()
 
-	if handler == nil {
+	if len(handlers) == 0 || handlers[0] == nil {
 		return errors.Errorf("could not find handler: %s", ccid)
 	}
 
-	handler.Close()
+	for _, handler := range handlers {
+		handler.Close()
+	}
 
 	chaincodeLogger.Debugf("deregistered handler with key: %s", ccid)
 	return nil
 }
 
+// DeregisterHandler removes the handler of a chaincode instance. When the
+// chaincode is served by several chaincode servers, the remaining handlers
+// continue to serve the chaincode. Otherwise all state associated with the
+// chaincode is cleared as with Deregister.
+func (r *HandlerRegistry) DeregisterHandler(h *Handler) error {
+	ccid := h.chaincodeID
+
+	r.mutex.Lock()
+	if replicas, ok := r.replicas[ccid]; ok {
+		if !replicas.remove(h) {
+			// the other chaincode servers may still register
+			r.mutex.Unlock()
+			return errors.Errorf("handler is not registered: %s", ccid)
+		}
+		if remaining := len(replicas.handlers); remaining > 0 {
+			if r.handlers[ccid] == h {
+				r.handlers[ccid] = replicas.handlers[0]
+			}
+			r.mutex.Unlock()
+
+			chaincodeLogger.Warningf("chaincode server of %s disconnected, %d remaining", ccid, remaining)
+			h.Close()
+			return nil
+		}
+	}
+	if registered := r.handlers[ccid]; registered != nil && registered != h {
+		// a handler which failed to register must not clear the state of
+		// the registered one
+		r.mutex.Unlock()
+		return errors.Errorf("handler is not registered: %s", ccid)
+	}
+	r.mutex.Unlock()
+
+	return r.Deregister(ccid)
+}
+
 type TxQueryExecutorGetter struct {
 	HandlerRegistry *HandlerRegistry
 	CCID            string
 }
 
 func (g *TxQueryExecutorGetter) TxQueryExecutor(chainID, txID string) ledger.SimpleQueryExecutor {
-	handler := g.HandlerRegistry.Handler(g.CCID)
-	if handler == nil {
-		return nil
+	for _, handler := range g.HandlerRegistry.handlersFor(g.CCID) {
+		if txContext := handler.TXContexts.Get(chainID, txID); txContext != nil {
+			return txContext.TXSimulator
+		}
 	}
-	txContext := handler.TXContexts.Get(chainID, txID)
-	if txContext == nil {
-		return nil
-	}
-	return txContext.TXSimulator
+	return nil
 }
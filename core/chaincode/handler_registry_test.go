@@ -10,6 +10,7 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/mock"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/container/ccintf"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -239,6 +240,139 @@ var _ = Describe("HandlerRegistry", func() {
 
 			Expect(fakeResultsIterator.CloseCallCount()).To(Equal(1))
 		})
+
+		It("removes references to the handler when the handler is deregistered", func() {
+			err := hr.DeregisterHandler(handler)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(hr.Handler("chaincode-id")).To(BeNil())
+			_, exists := hr.Launching("chaincode-id")
+			Expect(exists).To(BeFalse())
+			Expect(fakeResultsIterator.CloseCallCount()).To(Equal(1))
+		})
+
+		Context("when another handler is deregistered", func() {
+			It("keeps the registered handler", func() {
+				other := &chaincode.Handler{}
+				chaincode.SetHandlerChaincodeID(other, "chaincode-id")
+
+				err := hr.DeregisterHandler(other)
+				Expect(err).To(MatchError("handler is not registered: chaincode-id"))
+				Expect(hr.Handler("chaincode-id")).To(Equal(handler))
+			})
+		})
+	})
+})
+
+var _ = Describe("HandlerRegistry with replicated chaincode", func() {
+	var (
+		hr       *chaincode.HandlerRegistry
+		handlers []*chaincode.Handler
+	)
+
+	BeforeEach(func() {
+		hr = chaincode.NewHandlerRegistry(false)
+		handlers = nil
+		for n := 0; n < 3; n++ {
+			handler := &chaincode.Handler{TXContexts: chaincode.NewTransactionContexts()}
+			chaincode.SetHandlerChaincodeID(handler, "chaincode-id")
+			handlers = append(handlers, handler)
+		}
+
+		_, started := hr.Launching("chaincode-id")
+		Expect(started).To(BeFalse())
+		hr.Replicated("chaincode-id", ccintf.RoundRobin)
+		for _, handler := range handlers {
+			Expect(hr.Register(handler)).To(Succeed())
+		}
+	})
+
+	It("selects the handlers in turn", func() {
+		Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[0]))
+		Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[1]))
+		Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[2]))
+		Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[0]))
+	})
+
+	Context("when least outstanding load balancing is used", func() {
+		BeforeEach(func() {
+			hr = chaincode.NewHandlerRegistry(false)
+			hr.Launching("chaincode-id")
+			hr.Replicated("chaincode-id", ccintf.LeastOutstanding)
+			for _, handler := range handlers {
+				Expect(hr.Register(handler)).To(Succeed())
+			}
+		})
+
+		It("selects the handler with the fewest transactions in progress", func() {
+			chaincode.SetHandlerOutstanding(handlers[0], 4)
+			chaincode.SetHandlerOutstanding(handlers[1], 1)
+			chaincode.SetHandlerOutstanding(handlers[2], 2)
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[1]))
+
+			chaincode.SetHandlerOutstanding(handlers[2], 0)
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[2]))
+		})
+
+		It("selects idle handlers in turn", func() {
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[0]))
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[1]))
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[2]))
+		})
+	})
+
+	Describe("DeregisterHandler", func() {
+		It("keeps serving the chaincode with the remaining handlers", func() {
+			Expect(hr.DeregisterHandler(handlers[0])).To(Succeed())
+
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[1]))
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[2]))
+			Expect(hr.Handler("chaincode-id")).To(BeIdenticalTo(handlers[1]))
+			_, started := hr.Launching("chaincode-id")
+			Expect(started).To(BeTrue())
+		})
+
+		It("accepts the registration of a reconnected server", func() {
+			Expect(hr.DeregisterHandler(handlers[0])).To(Succeed())
+			Expect(hr.Register(handlers[0])).To(Succeed())
+		})
+
+		It("clears the chaincode when the last handler is removed", func() {
+			for _, handler := range handlers {
+				Expect(hr.DeregisterHandler(handler)).To(Succeed())
+			}
+
+			Expect(hr.Handler("chaincode-id")).To(BeNil())
+			_, started := hr.Launching("chaincode-id")
+			Expect(started).To(BeFalse())
+		})
+
+		It("returns an error for a handler which is not registered", func() {
+			handler := &chaincode.Handler{}
+			chaincode.SetHandlerChaincodeID(handler, "chaincode-id")
+
+			err := hr.DeregisterHandler(handler)
+			Expect(err).To(MatchError("handler is not registered: chaincode-id"))
+			Expect(hr.Handler("chaincode-id")).NotTo(BeNil())
+		})
+	})
+
+	It("removes all handlers on Deregister", func() {
+		Expect(hr.Deregister("chaincode-id")).To(Succeed())
+		Expect(hr.Handler("chaincode-id")).To(BeNil())
+	})
+
+	It("finds the transaction simulator on any handler", func() {
+		fakeTxSimulator := &mock.TxSimulator{}
+		_, err := handlers[2].TXContexts.Create(&ccprovider.TransactionParams{
+			ChannelID:   "channel-ID",
+			TxID:        "tx-ID",
+			TXSimulator: fakeTxSimulator,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		txQEGetter := &chaincode.TxQueryExecutorGetter{HandlerRegistry: hr, CCID: "chaincode-id"}
+		Expect(txQEGetter.TxQueryExecutor("channel-ID", "tx-ID")).To(Equal(fakeTxSimulator))
 	})
 })
 
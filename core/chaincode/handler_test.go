@@ -7,10 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package chaincode_test
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
 	"github.com/hyperledger/fabric/common/util"
@@ -18,9 +25,13 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/fake"
 	"github.com/hyperledger/fabric/core/chaincode/mock"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
 	"github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/protoutil"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -46,6 +57,7 @@ var _ = Describe("Handler", func() {
 		fakeShimRequestsCompleted      *metricsfakes.Counter
 		fakeShimRequestDuration        *metricsfakes.Histogram
 		fakeExecuteTimeouts            *metricsfakes.Counter
+		fakeQuotaRejections            *metricsfakes.Counter
 		fakeCapabilites                *mock.ApplicationCapabilities
 
 		responseNotifier chan *pb.ChaincodeMessage
@@ -100,6 +112,8 @@ var _ = Describe("Handler", func() {
 		fakeShimRequestDuration.WithReturns(fakeShimRequestDuration)
 		fakeExecuteTimeouts = &metricsfakes.Counter{}
 		fakeExecuteTimeouts.WithReturns(fakeExecuteTimeouts)
+		fakeQuotaRejections = &metricsfakes.Counter{}
+		fakeQuotaRejections.WithReturns(fakeQuotaRejections)
 
 		builtinSCCs = map[string]struct{}{}
 
@@ -108,6 +122,7 @@ var _ = Describe("Handler", func() {
 			ShimRequestsCompleted: fakeShimRequestsCompleted,
 			ShimRequestDuration:   fakeShimRequestDuration,
 			ExecuteTimeouts:       fakeExecuteTimeouts,
+			QuotaRejections:       fakeQuotaRejections,
 		}
 
 		handler = &chaincode.Handler{
@@ -897,6 +912,106 @@ var _ = Describe("Handler", func() {
 		})
 	})
 
+	Describe("HandlePurgePrivateData", func() {
+		var incomingMessage *pb.ChaincodeMessage
+		var request *pb.DelState
+
+		BeforeEach(func() {
+			request = &pb.DelState{
+				Key:        "purge-key",
+				Collection: "collection-name",
+			}
+			payload, err := proto.Marshal(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			incomingMessage = &pb.ChaincodeMessage{
+				Type:      msgs.ChaincodeMessage_PURGE_PRIVATE_DATA,
+				Payload:   payload,
+				Txid:      "tx-id",
+				ChannelId: "channel-id",
+			}
+			fakeCollectionStore.RetrieveReadWritePermissionReturns(false, true, nil)
+		})
+
+		It("calls PurgePrivateData on the transaction simulator and returns a response message", func() {
+			resp, err := handler.HandlePurgePrivateData(incomingMessage, txContext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(Equal(&pb.ChaincodeMessage{
+				Type:      pb.ChaincodeMessage_RESPONSE,
+				Txid:      "tx-id",
+				ChannelId: "channel-id",
+			}))
+
+			Expect(fakeTxSimulator.PurgePrivateDataCallCount()).To(Equal(1))
+			ccname, collection, key := fakeTxSimulator.PurgePrivateDataArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(collection).To(Equal("collection-name"))
+			Expect(key).To(Equal("purge-key"))
+		})
+
+		Context("when unmarshalling the request fails", func() {
+			BeforeEach(func() {
+				incomingMessage.Payload = []byte("this-is-a-bogus-payload")
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandlePurgePrivateData(incomingMessage, txContext)
+				Expect(err).To(MatchError("unmarshal failed: proto: can't skip unknown wire type 4"))
+			})
+		})
+
+		Context("when collection is not set", func() {
+			BeforeEach(func() {
+				request.Collection = ""
+				payload, err := proto.Marshal(request)
+				Expect(err).NotTo(HaveOccurred())
+				incomingMessage.Payload = payload
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandlePurgePrivateData(incomingMessage, txContext)
+				Expect(err).To(MatchError("only private data can be purged"))
+				Expect(fakeTxSimulator.PurgePrivateDataCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when PurgePrivateData fails due to ledger error", func() {
+			BeforeEach(func() {
+				fakeTxSimulator.PurgePrivateDataReturns(errors.New("mango"))
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandlePurgePrivateData(incomingMessage, txContext)
+				Expect(err).To(MatchError("mango"))
+			})
+		})
+
+		Context("when the transaction is an Init transaction", func() {
+			BeforeEach(func() {
+				txContext.IsInitTransaction = true
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandlePurgePrivateData(incomingMessage, txContext)
+				Expect(err).To(MatchError("private data APIs are not allowed in chaincode Init()"))
+			})
+		})
+
+		Context("when the creator has no write access permission", func() {
+			BeforeEach(func() {
+				fakeCollectionStore.RetrieveReadWritePermissionReturns(false, false, nil)
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandlePurgePrivateData(incomingMessage, txContext)
+				Expect(err).To(MatchError("tx creator does not have write access" +
+					" permission on privatedata in chaincodeName:cc-instance-name" +
+					" collectionName: collection-name"))
+				Expect(fakeTxSimulator.PurgePrivateDataCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Describe("HandleGetState", func() {
 		var (
 			incomingMessage  *pb.ChaincodeMessage
@@ -1005,6 +1120,84 @@ var _ = Describe("Handler", func() {
 				})
 			})
 
+			Context("and the accesses to the collection are recorded", func() {
+				var fakeAccessLog *fake.PrivateDataAccessLog
+
+				BeforeEach(func() {
+					fakeAccessLog = &fake.PrivateDataAccessLog{}
+					fakeAccessLog.EnabledReturns(true)
+					handler.AccessLog = fakeAccessLog
+
+					txContext.Proposal = &pb.Proposal{
+						Header: protoutil.MarshalOrPanic(&cb.Header{
+							SignatureHeader: protoutil.MarshalOrPanic(&cb.SignatureHeader{
+								Creator: protoutil.MarshalOrPanic(&mspproto.SerializedIdentity{
+									Mspid:   "Org1MSP",
+									IdBytes: []byte("cert"),
+								}),
+							}),
+						}),
+					}
+				})
+
+				It("records the access of the creator to the key", func() {
+					_, err := handler.HandleGetState(incomingMessage, txContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAccessLog.EnabledCallCount()).To(Equal(1))
+					ccname, collection := fakeAccessLog.EnabledArgsForCall(0)
+					Expect(ccname).To(Equal("cc-instance-name"))
+					Expect(collection).To(Equal("collection-name"))
+
+					Expect(fakeAccessLog.RecordCallCount()).To(Equal(1))
+					channelID, entries := fakeAccessLog.RecordArgsForCall(0)
+					Expect(channelID).To(Equal("channel-id"))
+					Expect(entries).To(Equal([]*pvtdataaccesslog.Entry{{
+						TxID:       "tx-id",
+						Chaincode:  "cc-instance-name",
+						Collection: "collection-name",
+						Key:        "get-state-key",
+						MSPID:      "Org1MSP",
+						CertHash:   "06298432e8066b29e2223bcc23aa9504b56ae508fabf3435508869b9c3190e22",
+					}}))
+				})
+
+				Context("when the key does not exist", func() {
+					BeforeEach(func() {
+						fakeTxSimulator.GetPrivateDataReturns(nil, nil)
+					})
+
+					It("does not record the access", func() {
+						_, err := handler.HandleGetState(incomingMessage, txContext)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(fakeAccessLog.RecordCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("when the accesses to the collection are not recorded", func() {
+					BeforeEach(func() {
+						fakeAccessLog.EnabledReturns(false)
+					})
+
+					It("does not record the access", func() {
+						_, err := handler.HandleGetState(incomingMessage, txContext)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(fakeAccessLog.RecordCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("when recording the access fails", func() {
+					BeforeEach(func() {
+						fakeAccessLog.RecordReturns(errors.New("disk full"))
+					})
+
+					It("returns the error", func() {
+						_, err := handler.HandleGetState(incomingMessage, txContext)
+						Expect(err).To(MatchError("disk full"))
+					})
+				})
+			})
+
 			Context("and GetPrivateData returns the response message", func() {
 				BeforeEach(func() {
 					//txContext.CollectionACLCache.put("collection-name", true, false)
@@ -1483,6 +1676,58 @@ var _ = Describe("Handler", func() {
 				Expect(endKey).To(Equal("get-state-end-key"))
 			})
 
+			Context("and the accesses to the collection are recorded", func() {
+				var fakeAccessLog *fake.PrivateDataAccessLog
+
+				BeforeEach(func() {
+					fakeAccessLog = &fake.PrivateDataAccessLog{}
+					fakeAccessLog.EnabledReturns(true)
+					handler.AccessLog = fakeAccessLog
+
+					fakeIterator.NextReturnsOnCall(0, &queryresult.KV{Key: "key1"}, nil)
+					fakeIterator.NextReturnsOnCall(1, nil, nil)
+				})
+
+				It("records the keys returned by the iterator", func() {
+					_, err := handler.HandleGetStateByRange(incomingMessage, txContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, iter, _, _, _ := fakeQueryResponseBuilder.BuildQueryResponseArgsForCall(0)
+					Expect(iter).NotTo(Equal(fakeIterator))
+					result, err := iter.Next()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(&queryresult.KV{Key: "key1"}))
+					result, err = iter.Next()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeNil())
+
+					Expect(fakeAccessLog.RecordCallCount()).To(Equal(1))
+					channelID, entries := fakeAccessLog.RecordArgsForCall(0)
+					Expect(channelID).To(Equal("channel-id"))
+					Expect(entries).To(Equal([]*pvtdataaccesslog.Entry{{
+						TxID:       "tx-id",
+						Chaincode:  "cc-instance-name",
+						Collection: "collection-name",
+						Key:        "key1",
+					}}))
+				})
+
+				Context("when recording the access fails", func() {
+					BeforeEach(func() {
+						fakeAccessLog.RecordReturns(errors.New("disk full"))
+					})
+
+					It("returns the error from the iterator", func() {
+						_, err := handler.HandleGetStateByRange(incomingMessage, txContext)
+						Expect(err).NotTo(HaveOccurred())
+
+						_, iter, _, _, _ := fakeQueryResponseBuilder.BuildQueryResponseArgsForCall(0)
+						_, err = iter.Next()
+						Expect(err).To(MatchError("disk full"))
+					})
+				})
+			})
+
 			Context("and GetPrivateDataRangeScanIterator fails due to ledger error", func() {
 				BeforeEach(func() {
 					fakeTxSimulator.GetPrivateDataRangeScanIteratorReturns(nil, errors.New("french fries"))
@@ -1584,127 +1829,593 @@ var _ = Describe("Handler", func() {
 		})
 	})
 
-	Describe("HandleQueryStateNext", func() {
+	Describe("HandleQueryStateNext", func() {
+		var (
+			fakeIterator          *mock.QueryResultsIterator
+			expectedQueryResponse *pb.QueryResponse
+			request               *pb.QueryStateNext
+			incomingMessage       *pb.ChaincodeMessage
+		)
+
+		BeforeEach(func() {
+			request = &pb.QueryStateNext{
+				Id: "query-state-next-id",
+			}
+			payload, err := proto.Marshal(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeIterator = &mock.QueryResultsIterator{}
+			txContext.InitializeQueryContext("query-state-next-id", fakeIterator)
+
+			incomingMessage = &pb.ChaincodeMessage{
+				Type:      pb.ChaincodeMessage_GET_STATE,
+				Payload:   payload,
+				Txid:      "tx-id",
+				ChannelId: "channel-id",
+			}
+			expectedQueryResponse = &pb.QueryResponse{
+				Results: nil,
+				HasMore: true,
+				Id:      "query-response-id",
+			}
+			fakeQueryResponseBuilder.BuildQueryResponseReturns(expectedQueryResponse, nil)
+		})
+
+		It("builds a query response", func() {
+			_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeQueryResponseBuilder.BuildQueryResponseCallCount()).To(Equal(1))
+			tctx, iter, id, _, _ := fakeQueryResponseBuilder.BuildQueryResponseArgsForCall(0)
+			Expect(tctx).To(Equal(txContext))
+			Expect(iter).To(Equal(fakeIterator))
+			Expect(id).To(Equal("query-state-next-id"))
+		})
+
+		It("returns a chaincode message with the query response", func() {
+			resp, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			expectedPayload, err := proto.Marshal(expectedQueryResponse)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resp).To(Equal(&pb.ChaincodeMessage{
+				Type:      pb.ChaincodeMessage_RESPONSE,
+				Payload:   expectedPayload,
+				ChannelId: "channel-id",
+				Txid:      "tx-id",
+			}))
+		})
+
+		Context("when unmarshalling the request fails", func() {
+			BeforeEach(func() {
+				incomingMessage.Payload = []byte("this-is-a-bogus-payload")
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+				Expect(err).To(MatchError("unmarshal failed: proto: can't skip unknown wire type 4"))
+			})
+		})
+
+		Context("when the query iterator is missing", func() {
+			BeforeEach(func() {
+				txContext.CleanupQueryContext("query-state-next-id")
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+				Expect(err).To(MatchError("query iterator not found"))
+			})
+		})
+
+		Context("when building the query response fails", func() {
+			BeforeEach(func() {
+				fakeQueryResponseBuilder.BuildQueryResponseReturns(nil, errors.New("potato"))
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+				Expect(err).To(MatchError("potato"))
+			})
+
+			It("cleans up the query context", func() {
+				handler.HandleQueryStateNext(incomingMessage, txContext)
+
+				pqr := txContext.GetPendingQueryResult("generated-query-id")
+				Expect(pqr).To(BeNil())
+				iter := txContext.GetQueryIterator("generated-query-id")
+				Expect(iter).To(BeNil())
+				retCount := txContext.GetTotalReturnCount("generated-query-id")
+				Expect(retCount).To(BeNil())
+			})
+		})
+
+		Context("when marshaling the query response fails", func() {
+			BeforeEach(func() {
+				fakeQueryResponseBuilder.BuildQueryResponseReturns(nil, nil)
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+				Expect(err).To(MatchError("marshal failed: proto: Marshal called with nil"))
+			})
+
+			It("cleans up the query context", func() {
+				handler.HandleQueryStateNext(incomingMessage, txContext)
+
+				pqr := txContext.GetPendingQueryResult("generated-query-id")
+				Expect(pqr).To(BeNil())
+				iter := txContext.GetQueryIterator("generated-query-id")
+				Expect(iter).To(BeNil())
+				retCount := txContext.GetTotalReturnCount("generated-query-id")
+				Expect(retCount).To(BeNil())
+			})
+		})
+	})
+
+	Describe("HandleGetStateMultiple", func() {
+		var (
+			incomingMessage *pb.ChaincodeMessage
+			request         *msgs.GetStateMultiple
+		)
+
+		BeforeEach(func() {
+			request = &msgs.GetStateMultiple{
+				Keys: []string{"key-1", "key-2", "key-3"},
+			}
+			payload, err := proto.Marshal(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			incomingMessage = &pb.ChaincodeMessage{
+				Type:      msgs.ChaincodeMessage_GET_STATE_MULTIPLE,
+				Payload:   payload,
+				Txid:      "tx-id",
+				ChannelId: "channel-id",
+			}
+
+			fakeTxSimulator.GetStateMultipleKeysReturns([][]byte{[]byte("value-1"), nil, []byte("value-3")}, nil)
+		})
+
+		It("returns the values in the order of the keys", func() {
+			resp, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Type).To(Equal(pb.ChaincodeMessage_RESPONSE))
+			Expect(resp.Txid).To(Equal("tx-id"))
+			Expect(resp.ChannelId).To(Equal("channel-id"))
+
+			result := &msgs.GetStateMultipleResult{}
+			Expect(proto.Unmarshal(resp.Payload, result)).To(Succeed())
+			Expect(result.Values).To(Equal([][]byte{[]byte("value-1"), {}, []byte("value-3")}))
+		})
+
+		Context("when unmarshalling the request fails", func() {
+			BeforeEach(func() {
+				incomingMessage.Payload = []byte("this-is-a-bogus-payload")
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+				Expect(err).To(MatchError("unmarshal failed: proto: can't skip unknown wire type 4"))
+			})
+		})
+
+		Context("when collection is not set", func() {
+			It("calls GetStateMultipleKeys on the transaction simulator", func() {
+				_, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeTxSimulator.GetStateMultipleKeysCallCount()).To(Equal(1))
+				ccname, keys := fakeTxSimulator.GetStateMultipleKeysArgsForCall(0)
+				Expect(ccname).To(Equal("cc-instance-name"))
+				Expect(keys).To(Equal([]string{"key-1", "key-2", "key-3"}))
+				Expect(fakeTxSimulator.GetStateCallCount()).To(Equal(0))
+			})
+
+			Context("and GetStateMultipleKeys fails", func() {
+				BeforeEach(func() {
+					fakeTxSimulator.GetStateMultipleKeysReturns(nil, errors.New("kiwi"))
+				})
+
+				It("returns the error", func() {
+					_, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+					Expect(err).To(MatchError("kiwi"))
+				})
+			})
+		})
+
+		Context("when collection is set", func() {
+			BeforeEach(func() {
+				request.Collection = "collection-name"
+				payload, err := proto.Marshal(request)
+				Expect(err).NotTo(HaveOccurred())
+				incomingMessage.Payload = payload
+
+				fakeCollectionStore.RetrieveReadWritePermissionReturns(true, false, nil)
+				fakeTxSimulator.GetPrivateDataMultipleKeysReturns([][]byte{[]byte("private-1"), nil, nil}, nil)
+			})
+
+			It("calls GetPrivateDataMultipleKeys on the transaction simulator", func() {
+				resp, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeTxSimulator.GetPrivateDataMultipleKeysCallCount()).To(Equal(1))
+				ccname, collection, keys := fakeTxSimulator.GetPrivateDataMultipleKeysArgsForCall(0)
+				Expect(ccname).To(Equal("cc-instance-name"))
+				Expect(collection).To(Equal("collection-name"))
+				Expect(keys).To(Equal([]string{"key-1", "key-2", "key-3"}))
+
+				result := &msgs.GetStateMultipleResult{}
+				Expect(proto.Unmarshal(resp.Payload, result)).To(Succeed())
+				Expect(result.Values).To(HaveLen(3))
+				Expect(result.Values[0]).To(Equal([]byte("private-1")))
+			})
+
+			Context("and the creator has no read access permission", func() {
+				BeforeEach(func() {
+					fakeCollectionStore.RetrieveReadWritePermissionReturns(false, false, nil)
+				})
+
+				It("returns an error", func() {
+					_, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+					Expect(err).To(MatchError("tx creator does not have read access" +
+						" permission on privatedata in chaincodeName:cc-instance-name" +
+						" collectionName: collection-name"))
+					Expect(fakeTxSimulator.GetPrivateDataMultipleKeysCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("and it is an Init transaction", func() {
+				BeforeEach(func() {
+					txContext.IsInitTransaction = true
+				})
+
+				It("returns an error", func() {
+					_, err := handler.HandleGetStateMultiple(incomingMessage, txContext)
+					Expect(err).To(MatchError("private data APIs are not allowed in chaincode Init()"))
+				})
+			})
+		})
+	})
+
+	Describe("HandleWriteBatchState", func() {
+		var (
+			incomingMessage *pb.ChaincodeMessage
+			request         *msgs.WriteBatchState
+		)
+
+		BeforeEach(func() {
+			request = &msgs.WriteBatchState{
+				Rec: []*msgs.WriteRecord{
+					{Key: "put-key", Value: []byte("put-value"), Type: msgs.WriteRecord_PUT_STATE},
+					{Key: "del-key", Type: msgs.WriteRecord_DEL_STATE},
+					{Key: "private-put-key", Value: []byte("private-value"), Collection: "collection-name", Type: msgs.WriteRecord_PUT_STATE},
+					{Key: "private-del-key", Collection: "collection-name", Type: msgs.WriteRecord_DEL_STATE},
+					{Key: "private-purge-key", Collection: "collection-name", Type: msgs.WriteRecord_PURGE_PRIVATE_DATA},
+				},
+			}
+			payload, err := proto.Marshal(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			incomingMessage = &pb.ChaincodeMessage{
+				Type:      msgs.ChaincodeMessage_WRITE_BATCH_STATE,
+				Payload:   payload,
+				Txid:      "tx-id",
+				ChannelId: "channel-id",
+			}
+
+			fakeCollectionStore.RetrieveReadWritePermissionReturns(false, true, nil)
+		})
+
+		It("returns a response message", func() {
+			resp, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(Equal(&pb.ChaincodeMessage{
+				Type:      pb.ChaincodeMessage_RESPONSE,
+				Txid:      "tx-id",
+				ChannelId: "channel-id",
+			}))
+		})
+
+		It("applies each record to the transaction simulator", func() {
+			_, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(1))
+			ccname, key, value := fakeTxSimulator.SetStateArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(key).To(Equal("put-key"))
+			Expect(value).To(Equal([]byte("put-value")))
+
+			Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(1))
+			ccname, key = fakeTxSimulator.DeleteStateArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(key).To(Equal("del-key"))
+
+			Expect(fakeTxSimulator.SetPrivateDataCallCount()).To(Equal(1))
+			ccname, collection, key, value := fakeTxSimulator.SetPrivateDataArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(collection).To(Equal("collection-name"))
+			Expect(key).To(Equal("private-put-key"))
+			Expect(value).To(Equal([]byte("private-value")))
+
+			Expect(fakeTxSimulator.DeletePrivateDataCallCount()).To(Equal(1))
+			ccname, collection, key = fakeTxSimulator.DeletePrivateDataArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(collection).To(Equal("collection-name"))
+			Expect(key).To(Equal("private-del-key"))
+
+			Expect(fakeTxSimulator.PurgePrivateDataCallCount()).To(Equal(1))
+			ccname, collection, key = fakeTxSimulator.PurgePrivateDataArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(collection).To(Equal("collection-name"))
+			Expect(key).To(Equal("private-purge-key"))
+
+			By("checking the collection permissions once")
+			Expect(fakeCollectionStore.RetrieveReadWritePermissionCallCount()).To(Equal(1))
+		})
+
+		Context("when unmarshalling the request fails", func() {
+			BeforeEach(func() {
+				incomingMessage.Payload = []byte("this-is-a-bogus-payload")
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+				Expect(err).To(MatchError("unmarshal failed: proto: can't skip unknown wire type 4"))
+			})
+		})
+
+		Context("when a record has an unsupported type", func() {
+			BeforeEach(func() {
+				request.Rec[1].Type = msgs.WriteRecord_UNDEFINED
+				payload, err := proto.Marshal(request)
+				Expect(err).NotTo(HaveOccurred())
+				incomingMessage.Payload = payload
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+				Expect(err).To(MatchError("record 1 [del-key] failed: unsupported write type UNDEFINED"))
+			})
+		})
+
+		Context("when SetState fails", func() {
+			BeforeEach(func() {
+				fakeTxSimulator.SetStateReturns(errors.New("papaya"))
+			})
+
+			It("returns an error and stops applying records", func() {
+				_, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+				Expect(err).To(MatchError("record 0 [put-key] failed: papaya"))
+				Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the creator has no write access permission", func() {
+			BeforeEach(func() {
+				fakeCollectionStore.RetrieveReadWritePermissionReturns(true, false, nil)
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+				Expect(err).To(MatchError("record 2 [private-put-key] failed: tx creator does not have write access" +
+					" permission on privatedata in chaincodeName:cc-instance-name" +
+					" collectionName: collection-name"))
+				Expect(fakeTxSimulator.SetPrivateDataCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when it is an Init transaction", func() {
+			BeforeEach(func() {
+				txContext.IsInitTransaction = true
+			})
+
+			It("rejects private data writes", func() {
+				_, err := handler.HandleWriteBatchState(incomingMessage, txContext)
+				Expect(err).To(MatchError("record 2 [private-put-key] failed: private data APIs are not allowed in chaincode Init()"))
+			})
+		})
+	})
+
+	Describe("HandleGetStateByRangeBulk", func() {
 		var (
-			fakeIterator          *mock.QueryResultsIterator
-			expectedQueryResponse *pb.QueryResponse
-			request               *pb.QueryStateNext
-			incomingMessage       *pb.ChaincodeMessage
+			incomingMessage *pb.ChaincodeMessage
+			request         *pb.GetStateByRange
+			fakeIterator    *mock.QueryResultsIterator
+			results         []*queryresult.KV
 		)
 
 		BeforeEach(func() {
-			request = &pb.QueryStateNext{
-				Id: "query-state-next-id",
+			request = &pb.GetStateByRange{
+				StartKey: "start-key",
+				EndKey:   "end-key",
 			}
 			payload, err := proto.Marshal(request)
 			Expect(err).NotTo(HaveOccurred())
 
-			fakeIterator = &mock.QueryResultsIterator{}
-			txContext.InitializeQueryContext("query-state-next-id", fakeIterator)
-
 			incomingMessage = &pb.ChaincodeMessage{
-				Type:      pb.ChaincodeMessage_GET_STATE,
+				Type:      msgs.ChaincodeMessage_GET_STATE_BY_RANGE_BULK,
 				Payload:   payload,
 				Txid:      "tx-id",
 				ChannelId: "channel-id",
 			}
-			expectedQueryResponse = &pb.QueryResponse{
-				Results: nil,
-				HasMore: true,
-				Id:      "query-response-id",
+
+			results = nil
+			for i := 0; i < 5; i++ {
+				results = append(results, &queryresult.KV{
+					Namespace: "cc-instance-name",
+					Key:       fmt.Sprintf("key-%d", i),
+					Value:     []byte("0123456789"),
+				})
 			}
-			fakeQueryResponseBuilder.BuildQueryResponseReturns(expectedQueryResponse, nil)
+			fakeIterator = &mock.QueryResultsIterator{}
+			for i, kv := range results {
+				fakeIterator.NextReturnsOnCall(i, kv, nil)
+			}
+			fakeTxSimulator.GetStateRangeScanIteratorReturns(fakeIterator, nil)
+
+			handler.TotalQueryLimit = 100
+			handler.MaxBulkQueryBytes = 1024
 		})
 
-		It("builds a query response", func() {
-			_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
-			Expect(err).NotTo(HaveOccurred())
+		queryResponse := func(resp *pb.ChaincodeMessage) *pb.QueryResponse {
+			Expect(resp.Type).To(Equal(pb.ChaincodeMessage_RESPONSE))
+			qr := &pb.QueryResponse{}
+			Expect(proto.Unmarshal(resp.Payload, qr)).To(Succeed())
+			return qr
+		}
 
-			Expect(fakeQueryResponseBuilder.BuildQueryResponseCallCount()).To(Equal(1))
-			tctx, iter, id, _, _ := fakeQueryResponseBuilder.BuildQueryResponseArgsForCall(0)
-			Expect(tctx).To(Equal(txContext))
-			Expect(iter).To(Equal(fakeIterator))
-			Expect(id).To(Equal("query-state-next-id"))
-		})
+		keys := func(qr *pb.QueryResponse) []string {
+			var keys []string
+			for _, r := range qr.Results {
+				kv := &queryresult.KV{}
+				Expect(proto.Unmarshal(r.ResultBytes, kv)).To(Succeed())
+				keys = append(keys, kv.Key)
+			}
+			return keys
+		}
 
-		It("returns a chaincode message with the query response", func() {
-			resp, err := handler.HandleQueryStateNext(incomingMessage, txContext)
+		It("returns all results in a single response and closes the iterator", func() {
+			resp, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
 			Expect(err).NotTo(HaveOccurred())
 
-			expectedPayload, err := proto.Marshal(expectedQueryResponse)
-			Expect(err).NotTo(HaveOccurred())
+			qr := queryResponse(resp)
+			Expect(qr.HasMore).To(BeFalse())
+			Expect(qr.Id).To(Equal("generated-query-id"))
+			Expect(keys(qr)).To(Equal([]string{"key-0", "key-1", "key-2", "key-3", "key-4"}))
 
-			Expect(resp).To(Equal(&pb.ChaincodeMessage{
-				Type:      pb.ChaincodeMessage_RESPONSE,
-				Payload:   expectedPayload,
-				ChannelId: "channel-id",
-				Txid:      "tx-id",
-			}))
+			Expect(fakeTxSimulator.GetStateRangeScanIteratorCallCount()).To(Equal(1))
+			ccname, startKey, endKey := fakeTxSimulator.GetStateRangeScanIteratorArgsForCall(0)
+			Expect(ccname).To(Equal("cc-instance-name"))
+			Expect(startKey).To(Equal("start-key"))
+			Expect(endKey).To(Equal("end-key"))
+			Expect(fakeIterator.CloseCallCount()).To(Equal(1))
+			Expect(txContext.GetQueryIterator("generated-query-id")).To(BeNil())
 		})
 
-		Context("when unmarshalling the request fails", func() {
+		Context("when the results exceed the size limit", func() {
 			BeforeEach(func() {
-				incomingMessage.Payload = []byte("this-is-a-bogus-payload")
+				size := proto.Size(results[0])
+				handler.MaxBulkQueryBytes = 2*size + 1
+				handler.QueryResponseBuilder = &chaincode.QueryResponseGenerator{MaxResultLimit: 100}
 			})
 
-			It("returns an error", func() {
-				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
-				Expect(err).To(MatchError("unmarshal failed: proto: can't skip unknown wire type 4"))
+			It("returns the results that fit and the rest with QUERY_STATE_NEXT", func() {
+				resp, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				qr := queryResponse(resp)
+				Expect(qr.HasMore).To(BeTrue())
+				Expect(keys(qr)).To(Equal([]string{"key-0", "key-1"}))
+				Expect(fakeIterator.CloseCallCount()).To(Equal(0))
+
+				payload, err := proto.Marshal(&pb.QueryStateNext{Id: qr.Id})
+				Expect(err).NotTo(HaveOccurred())
+				resp, err = handler.HandleQueryStateNext(&pb.ChaincodeMessage{
+					Type:      pb.ChaincodeMessage_QUERY_STATE_NEXT,
+					Payload:   payload,
+					Txid:      "tx-id",
+					ChannelId: "channel-id",
+				}, txContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				qr = queryResponse(resp)
+				Expect(qr.HasMore).To(BeFalse())
+				Expect(keys(qr)).To(Equal([]string{"key-2", "key-3", "key-4"}))
+				Expect(fakeIterator.CloseCallCount()).To(Equal(1))
 			})
 		})
 
-		Context("when the query iterator is missing", func() {
+		Context("when the total query limit is reached", func() {
 			BeforeEach(func() {
-				txContext.CleanupQueryContext("query-state-next-id")
+				handler.TotalQueryLimit = 3
 			})
 
-			It("returns an error", func() {
-				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
-				Expect(err).To(MatchError("query iterator not found"))
+			It("returns up to the limit and closes the iterator", func() {
+				resp, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				qr := queryResponse(resp)
+				Expect(qr.HasMore).To(BeFalse())
+				Expect(keys(qr)).To(Equal([]string{"key-0", "key-1", "key-2"}))
+				Expect(fakeIterator.CloseCallCount()).To(Equal(1))
 			})
 		})
 
-		Context("when building the query response fails", func() {
+		Context("when collection is set", func() {
 			BeforeEach(func() {
-				fakeQueryResponseBuilder.BuildQueryResponseReturns(nil, errors.New("potato"))
+				request.Collection = "collection-name"
+				payload, err := proto.Marshal(request)
+				Expect(err).NotTo(HaveOccurred())
+				incomingMessage.Payload = payload
+
+				fakeCollectionStore.RetrieveReadWritePermissionReturns(true, false, nil)
+				fakeTxSimulator.GetPrivateDataRangeScanIteratorReturns(fakeIterator, nil)
 			})
 
-			It("returns an error", func() {
-				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
-				Expect(err).To(MatchError("potato"))
+			It("calls GetPrivateDataRangeScanIterator on the transaction simulator", func() {
+				_, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeTxSimulator.GetPrivateDataRangeScanIteratorCallCount()).To(Equal(1))
+				ccname, collection, startKey, endKey := fakeTxSimulator.GetPrivateDataRangeScanIteratorArgsForCall(0)
+				Expect(ccname).To(Equal("cc-instance-name"))
+				Expect(collection).To(Equal("collection-name"))
+				Expect(startKey).To(Equal("start-key"))
+				Expect(endKey).To(Equal("end-key"))
 			})
 
-			It("cleans up the query context", func() {
-				handler.HandleQueryStateNext(incomingMessage, txContext)
+			Context("and the creator has no read access permission", func() {
+				BeforeEach(func() {
+					fakeCollectionStore.RetrieveReadWritePermissionReturns(false, false, nil)
+				})
 
-				pqr := txContext.GetPendingQueryResult("generated-query-id")
-				Expect(pqr).To(BeNil())
-				iter := txContext.GetQueryIterator("generated-query-id")
-				Expect(iter).To(BeNil())
-				retCount := txContext.GetTotalReturnCount("generated-query-id")
-				Expect(retCount).To(BeNil())
+				It("returns an error", func() {
+					_, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+					Expect(err).To(MatchError("tx creator does not have read access" +
+						" permission on privatedata in chaincodeName:cc-instance-name" +
+						" collectionName: collection-name"))
+				})
 			})
 		})
 
-		Context("when marshaling the query response fails", func() {
+		Context("when pagination is requested", func() {
 			BeforeEach(func() {
-				fakeQueryResponseBuilder.BuildQueryResponseReturns(nil, nil)
+				metadata, err := proto.Marshal(&pb.QueryMetadata{PageSize: 10})
+				Expect(err).NotTo(HaveOccurred())
+				request.Metadata = metadata
+				payload, err := proto.Marshal(request)
+				Expect(err).NotTo(HaveOccurred())
+				incomingMessage.Payload = payload
 			})
 
 			It("returns an error", func() {
-				_, err := handler.HandleQueryStateNext(incomingMessage, txContext)
-				Expect(err).To(MatchError("marshal failed: proto: Marshal called with nil"))
+				_, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+				Expect(err).To(MatchError("pagination is not supported for bulk range queries"))
 			})
+		})
 
-			It("cleans up the query context", func() {
-				handler.HandleQueryStateNext(incomingMessage, txContext)
+		Context("when the iterator fails", func() {
+			BeforeEach(func() {
+				fakeIterator.NextReturnsOnCall(2, nil, errors.New("lemon"))
+			})
 
-				pqr := txContext.GetPendingQueryResult("generated-query-id")
-				Expect(pqr).To(BeNil())
-				iter := txContext.GetQueryIterator("generated-query-id")
-				Expect(iter).To(BeNil())
-				retCount := txContext.GetTotalReturnCount("generated-query-id")
-				Expect(retCount).To(BeNil())
+			It("returns the error and closes the iterator", func() {
+				_, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+				Expect(err).To(MatchError("lemon"))
+				Expect(fakeIterator.CloseCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when unmarshalling the request fails", func() {
+			BeforeEach(func() {
+				incomingMessage.Payload = []byte("this-is-a-bogus-payload")
+			})
+
+			It("returns an error", func() {
+				_, err := handler.HandleGetStateByRangeBulk(incomingMessage, txContext)
+				Expect(err).To(MatchError("unmarshal failed: proto: can't skip unknown wire type 4"))
 			})
 		})
 	})
@@ -2291,6 +3002,88 @@ var _ = Describe("Handler", func() {
 					Expect(err).To(MatchError("razzies"))
 				})
 			})
+
+			Context("when cross-channel writes are enabled", func() {
+				var invocations *ccprovider.CrossChannelInvocations
+
+				BeforeEach(func() {
+					handler.CrossChannelWrites = true
+					invocations = &ccprovider.CrossChannelInvocations{ChannelID: "channel-id"}
+					txContext.CrossChannelInvocations = invocations
+					txContext.Proposal = &pb.Proposal{
+						Header: protoutil.MarshalOrPanic(&cb.Header{
+							ChannelHeader: protoutil.MarshalOrPanic(&cb.ChannelHeader{
+								ChannelId: "channel-id",
+								TxId:      "tx-id",
+								Timestamp: &timestamp.Timestamp{Seconds: 1600000000},
+							}),
+						}),
+					}
+
+					responseMessage.Type = pb.ChaincodeMessage_COMPLETED
+					responseMessage.Payload = protoutil.MarshalOrPanic(&pb.Response{Status: 200, Payload: []byte("payload")})
+				})
+
+				It("executes the target with a simulator which locks the written keys", func() {
+					newTxSimulator.GetStateMetadataReturns(map[string][]byte{}, nil)
+					fakeInvoker.InvokeStub = func(txParams *ccprovider.TransactionParams, _ string, _ *pb.ChaincodeInput) (*pb.ChaincodeMessage, error) {
+						Expect(txParams.TXSimulator).NotTo(BeIdenticalTo(newTxSimulator))
+						Expect(txParams.TXSimulator.SetState("target-chaincode-name", "key", []byte("value"))).To(Succeed())
+						return responseMessage, nil
+					}
+
+					_, err := handler.HandleInvokeChaincode(incomingMessage, txContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(newTxSimulator.SetStateMetadataCallCount()).To(Equal(1))
+					_, _, metadata := newTxSimulator.SetStateMetadataArgsForCall(0)
+					lock := &msgs.CrossChannelLock{}
+					Expect(proto.Unmarshal(metadata[chaincode.CrossChannelLockKey], lock)).To(Succeed())
+					Expect(lock.ChannelId).To(Equal("channel-id"))
+					Expect(lock.TxId).To(Equal("tx-id"))
+					Expect(lock.Timestamp.Seconds).To(Equal(int64(1600000000)))
+				})
+
+				It("records the invocation and keeps its simulator open", func() {
+					_, err := handler.HandleInvokeChaincode(incomingMessage, txContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(newTxSimulator.DoneCallCount()).To(Equal(0))
+					recorded := invocations.Invocations()
+					Expect(recorded).To(HaveLen(1))
+					Expect(recorded[0].ChannelID).To(Equal("target-channel-id"))
+					Expect(recorded[0].ChaincodeName).To(Equal("target-chaincode-name"))
+					Expect(recorded[0].Response.Payload).To(Equal([]byte("payload")))
+					Expect(recorded[0].TXSimulator).To(BeIdenticalTo(newTxSimulator))
+				})
+
+				Context("when the target returns an error", func() {
+					BeforeEach(func() {
+						responseMessage.Payload = protoutil.MarshalOrPanic(&pb.Response{Status: 500, Message: "bad"})
+					})
+
+					It("does not record the invocation", func() {
+						_, err := handler.HandleInvokeChaincode(incomingMessage, txContext)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(invocations.Invocations()).To(BeEmpty())
+						Expect(newTxSimulator.DoneCallCount()).To(Equal(1))
+					})
+				})
+
+				Context("when the target was already invoked on its channel", func() {
+					BeforeEach(func() {
+						err := invocations.Add(&ccprovider.CrossChannelInvocation{ChannelID: "target-channel-id", ChaincodeName: "target-chaincode-name"})
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					It("returns an error", func() {
+						_, err := handler.HandleInvokeChaincode(incomingMessage, txContext)
+						Expect(err).To(MatchError("chaincode target-chaincode-name was already invoked on channel target-channel-id"))
+						Expect(newTxSimulator.DoneCallCount()).To(Equal(1))
+					})
+				})
+			})
 		})
 
 		Context("when the target is a system chaincode", func() {
@@ -2441,6 +3234,56 @@ var _ = Describe("Handler", func() {
 			Eventually(doneCh).Should(BeClosed())
 		})
 
+		Context("when the endorsement is recorded", func() {
+			var tempDir string
+
+			BeforeEach(func() {
+				var err error
+				tempDir, err = ioutil.TempDir("", "recording")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(tempDir)
+			})
+
+			It("records the messages exchanged with the chaincode", func() {
+				recorder := &recording.Recorder{Path: tempDir}
+				txParams.Recording = recorder.NewTransaction("channel-id", "tx-id")
+
+				getState := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Txid: "tx-id", ChannelId: "channel-id", Payload: []byte("key")}
+				getStateResponse := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: "tx-id", ChannelId: "channel-id", Payload: []byte("value")}
+				completed := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "tx-id", ChannelId: "channel-id", Payload: []byte("result")}
+				fakeChatStream.SendStub = func(msg *pb.ChaincodeMessage) error {
+					if msg.Type == pb.ChaincodeMessage_TRANSACTION {
+						go func() {
+							handler.HandleTransaction(getState, func(*pb.ChaincodeMessage, *chaincode.TransactionContext) (*pb.ChaincodeMessage, error) {
+								return getStateResponse, nil
+							})
+							responseNotifier <- completed
+						}()
+					}
+					return nil
+				}
+
+				_, err := handler.Execute(txParams, "chaincode-name", incomingMessage, time.Second)
+				Expect(err).NotTo(HaveOccurred())
+
+				path, err := recorder.Write(txParams.Recording, nil)
+				Expect(err).NotTo(HaveOccurred())
+				rec, err := recording.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rec.Executions).To(HaveLen(1))
+				execution := rec.Executions[0]
+				Expect(execution.ChaincodeName).To(Equal("chaincode-name"))
+				Expect(execution.Input).To(Equal(protoutil.MarshalOrPanic(incomingMessage)))
+				Expect(execution.Exchanges).To(HaveLen(1))
+				Expect(execution.Exchanges[0].Request).To(Equal(protoutil.MarshalOrPanic(getState)))
+				Expect(execution.Exchanges[0].Response).To(Equal(protoutil.MarshalOrPanic(getStateResponse)))
+				Expect(execution.Result).To(Equal(protoutil.MarshalOrPanic(completed)))
+			})
+		})
+
 		It("returns the chaincode response", func() {
 			Eventually(responseNotifier).Should(BeSent(&pb.ChaincodeMessage{Txid: "a-transaction-id"}))
 
@@ -2591,6 +3434,173 @@ var _ = Describe("Handler", func() {
 				Expect(txid).To(Equal("tx-id"))
 			})
 		})
+
+		Context("when the chaincode has a concurrency quota", func() {
+			BeforeEach(func() {
+				handler.Quotas = chaincode.NewQuotaManager(chaincode.QuotaConfig{
+					Default: chaincode.QuotaLimits{MaxConcurrency: 1},
+				})
+			})
+
+			It("rejects invocations beyond the limit", func() {
+				doneCh := make(chan struct{})
+				go func() {
+					handler.Execute(txParams, "chaincode-name", incomingMessage, time.Hour)
+					close(doneCh)
+				}()
+				Eventually(fakeChatStream.SendCallCount).Should(Equal(1))
+
+				_, err := handler.Execute(txParams, "chaincode-name", incomingMessage, time.Hour)
+				Expect(err).To(MatchError("chaincode chaincode-name on channel channel-id exceeded its concurrency quota of 1"))
+				Expect(fakeChatStream.SendCallCount()).To(Equal(1))
+				Expect(fakeQuotaRejections.WithCallCount()).To(Equal(1))
+				Expect(fakeQuotaRejections.WithArgsForCall(0)).To(Equal([]string{
+					"channel", "channel-id", "chaincode", "chaincode-name", "quota", "concurrency",
+				}))
+				Expect(fakeQuotaRejections.AddCallCount()).To(Equal(1))
+
+				responseNotifier <- &pb.ChaincodeMessage{}
+				Eventually(doneCh).Should(BeClosed())
+
+				responseNotifier <- &pb.ChaincodeMessage{}
+				_, err = handler.Execute(txParams, "chaincode-name", incomingMessage, time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not limit other chaincodes", func() {
+				go handler.Execute(txParams, "chaincode-name", incomingMessage, time.Hour)
+				Eventually(fakeChatStream.SendCallCount).Should(Equal(1))
+
+				otherResponseNotifier := make(chan *pb.ChaincodeMessage, 1)
+				fakeContextRegistry.CreateReturnsOnCall(1, &chaincode.TransactionContext{ResponseNotifier: otherResponseNotifier}, nil)
+				otherResponseNotifier <- &pb.ChaincodeMessage{}
+				_, err := handler.Execute(txParams, "other-chaincode", incomingMessage, time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+
+				responseNotifier <- &pb.ChaincodeMessage{}
+			})
+		})
+
+		Context("when the chaincode has an execution time quota", func() {
+			BeforeEach(func() {
+				handler.Quotas = chaincode.NewQuotaManager(chaincode.QuotaConfig{
+					Default: chaincode.QuotaLimits{MaxExecutionTime: 10 * time.Millisecond},
+				})
+			})
+
+			It("times out after the execution time", func() {
+				_, err := handler.Execute(txParams, "chaincode-name", incomingMessage, time.Hour)
+				Expect(err).To(MatchError("timeout expired while executing transaction: chaincode chaincode-name on channel channel-id exceeded its execution_time quota of 10ms"))
+				Expect(fakeQuotaRejections.WithCallCount()).To(Equal(1))
+				Expect(fakeQuotaRejections.WithArgsForCall(0)).To(Equal([]string{
+					"channel", "channel-id", "chaincode", "chaincode-name", "quota", "execution_time",
+				}))
+			})
+
+			It("uses the execute timeout when it is lower", func() {
+				_, err := handler.Execute(txParams, "chaincode-name", incomingMessage, time.Millisecond)
+				Expect(err).To(MatchError("timeout expired while executing transaction"))
+				Expect(fakeQuotaRejections.WithCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the chaincode has transaction quotas", func() {
+			BeforeEach(func() {
+				handler.Quotas = chaincode.NewQuotaManager(chaincode.QuotaConfig{
+					Default: chaincode.QuotaLimits{
+						MaxKeysRead:     1,
+						MaxKeysWritten:  2,
+						MaxWriteSetSize: 20,
+						MaxQueryResults: 1,
+					},
+				})
+
+				responseNotifier <- &pb.ChaincodeMessage{}
+				_, err := handler.Execute(txParams, "chaincode-name", incomingMessage, time.Second)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("limits the keys read", func() {
+				payload, err := proto.Marshal(&pb.GetState{Key: "key"})
+				Expect(err).NotTo(HaveOccurred())
+				msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Payload: payload, Txid: "tx-id", ChannelId: "channel-id"}
+
+				_, err = handler.HandleGetState(msg, txContext)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.HandleGetState(msg, txContext)
+				Expect(err).To(MatchError("chaincode chaincode-name on channel channel-id exceeded its keys_read quota of 1"))
+				Expect(fakeTxSimulator.GetStateCallCount()).To(Equal(1))
+				Expect(fakeQuotaRejections.WithArgsForCall(0)).To(Equal([]string{
+					"channel", "channel-id", "chaincode", "chaincode-name", "quota", "keys_read",
+				}))
+			})
+
+			It("limits the distinct keys written", func() {
+				put, err := proto.Marshal(&pb.PutState{Key: "a", Value: []byte("v")})
+				Expect(err).NotTo(HaveOccurred())
+				del, err := proto.Marshal(&pb.DelState{Key: "a"})
+				Expect(err).NotTo(HaveOccurred())
+				delOther, err := proto.Marshal(&pb.DelState{Key: "b"})
+				Expect(err).NotTo(HaveOccurred())
+				delOtherCollection, err := proto.Marshal(&pb.DelState{Key: "a", Collection: "collection"})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = handler.HandlePutState(&pb.ChaincodeMessage{Payload: put}, txContext)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.HandleDelState(&pb.ChaincodeMessage{Payload: del}, txContext)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.HandleDelState(&pb.ChaincodeMessage{Payload: delOther}, txContext)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.HandleDelState(&pb.ChaincodeMessage{Payload: del}, txContext)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.HandleDelState(&pb.ChaincodeMessage{Payload: delOtherCollection}, txContext)
+				Expect(err).To(MatchError("chaincode chaincode-name on channel channel-id exceeded its keys_written quota of 2"))
+				Expect(fakeTxSimulator.DeleteStateCallCount()).To(Equal(3))
+				Expect(fakeTxSimulator.DeletePrivateDataCallCount()).To(Equal(0))
+			})
+
+			It("counts the size of the last write to a key in the size of the write set", func() {
+				put, err := proto.Marshal(&pb.PutState{Key: "a", Value: []byte("v")})
+				Expect(err).NotTo(HaveOccurred())
+				putLarger, err := proto.Marshal(&pb.PutState{Key: "a", Value: []byte("eighteen bytes....")})
+				Expect(err).NotTo(HaveOccurred())
+				putOther, err := proto.Marshal(&pb.PutState{Key: "b", Value: []byte("v")})
+				Expect(err).NotTo(HaveOccurred())
+
+				for i := 0; i < 15; i++ {
+					_, err = handler.HandlePutState(&pb.ChaincodeMessage{Payload: put}, txContext)
+					Expect(err).NotTo(HaveOccurred())
+				}
+				_, err = handler.HandlePutState(&pb.ChaincodeMessage{Payload: putLarger}, txContext)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.HandlePutState(&pb.ChaincodeMessage{Payload: putOther}, txContext)
+				Expect(err).To(MatchError("chaincode chaincode-name on channel channel-id exceeded its write_set_size quota of 20"))
+				Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(16))
+			})
+
+			It("limits the size of the write set", func() {
+				put, err := proto.Marshal(&pb.PutState{Key: "key", Value: []byte("a value of twenty bytes")})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = handler.HandlePutState(&pb.ChaincodeMessage{Payload: put}, txContext)
+				Expect(err).To(MatchError("chaincode chaincode-name on channel channel-id exceeded its write_set_size quota of 20"))
+				Expect(fakeTxSimulator.SetStateCallCount()).To(Equal(0))
+			})
+
+			It("limits the results of queries", func() {
+				fakeQueryResponseBuilder.BuildQueryResponseReturns(&pb.QueryResponse{
+					Results: []*pb.QueryResultBytes{{}, {}},
+				}, nil)
+				fakeIterator := &mock.QueryResultsIterator{}
+				fakeTxSimulator.GetStateRangeScanIteratorReturns(fakeIterator, nil)
+				payload, err := proto.Marshal(&pb.GetStateByRange{StartKey: "a", EndKey: "z"})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = handler.HandleGetStateByRange(&pb.ChaincodeMessage{Payload: payload}, txContext)
+				Expect(err).To(MatchError("chaincode chaincode-name on channel channel-id exceeded its query_results quota of 1"))
+				Expect(fakeIterator.CloseCallCount()).To(Equal(1))
+			})
+		})
 	})
 
 	Describe("HandleRegister", func() {
@@ -9,9 +9,11 @@ package lifecycle
 import (
 	"sync"
 
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/core/container/externalbuilder"
 	"github.com/hyperledger/fabric/core/ledger"
 	extchaincode "github.com/hyperledger/fabric/extensions/chaincode"
+	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
 	"github.com/pkg/errors"
 )
 
@@ -48,7 +50,8 @@ func (b *EventBroker) ProcessInstallEvent(localChaincode *LocalChaincode) {
 
 	var dbArtifacts []byte
 
-	if _, ok := extchaincode.GetUCCByPackageID(localChaincode.Info.PackageID); ok {
+	ucc, isInProcess := extchaincode.GetUCCByPackageID(localChaincode.Info.PackageID)
+	if isInProcess {
 		logger.Debugf("Not loading DB artifacts for in-process user chaincode [%s]", localChaincode.Info.PackageID)
 	} else {
 		var err error
@@ -71,7 +74,7 @@ func (b *EventBroker) ProcessInstallEvent(localChaincode *LocalChaincode) {
 				Hash:              []byte(cachedChaincode.InstallInfo.PackageID),
 				CollectionConfigs: cachedChaincode.Definition.Collections,
 			}
-			b.invokeListeners(channelID, ccdef, dbArtifacts)
+			b.invokeListeners(channelID, ccdef, dbArtifacts, inProcDBArtifacts(ucc, ccdef.CollectionConfigs))
 			listenersInvokedOnChannel = true
 		}
 		if listenersInvokedOnChannel {
@@ -101,7 +104,8 @@ func (b *EventBroker) ProcessApproveOrDefineEvent(channelID string, chaincodeNam
 
 	var dbArtifacts []byte
 
-	if _, ok := extchaincode.GetUCCByPackageID(cachedChaincode.InstallInfo.PackageID); ok {
+	ucc, isInProcess := extchaincode.GetUCCByPackageID(cachedChaincode.InstallInfo.PackageID)
+	if isInProcess {
 		logger.Debugf("[%s] Not loading DB artifacts for in-process user chaincode [%s]", channelID, cachedChaincode.InstallInfo.PackageID)
 	} else {
 		var err error
@@ -118,7 +122,7 @@ func (b *EventBroker) ProcessApproveOrDefineEvent(channelID string, chaincodeNam
 		Hash:              []byte(cachedChaincode.InstallInfo.PackageID),
 		CollectionConfigs: cachedChaincode.Definition.Collections,
 	}
-	b.invokeListeners(channelID, ccdef, dbArtifacts)
+	b.invokeListeners(channelID, ccdef, dbArtifacts, inProcDBArtifacts(ucc, ccdef.CollectionConfigs))
 	b.defineCallbackStatus.Store(channelID, struct{}{})
 }
 
@@ -133,11 +137,20 @@ func (b *EventBroker) ApproveOrDefineCommitted(channelID string) {
 	b.defineCallbackStatus.Delete(channelID)
 }
 
-func (b *EventBroker) invokeListeners(channelID string, legacyDefinition *ledger.ChaincodeDefinition, dbArtifacts []byte) {
+// invokeListeners invokes the listeners registered for the channel. The inProcDBArtifacts are provided,
+// instead of the dbArtifacts tar, for in-process user chaincodes and are passed to the listeners which
+// implement ledger.InProcChaincodeLifecycleEventListener.
+func (b *EventBroker) invokeListeners(channelID string, legacyDefinition *ledger.ChaincodeDefinition, dbArtifacts []byte, inProcDBArtifacts map[string]*ccapi.DBArtifacts) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 	channelListeners := b.listeners[channelID]
 	for _, l := range channelListeners {
+		if inProcListener, ok := l.(ledger.InProcChaincodeLifecycleEventListener); ok && inProcDBArtifacts != nil {
+			if err := inProcListener.HandleInProcChaincodeDeploy(legacyDefinition, inProcDBArtifacts); err != nil {
+				// errors are logged rather than propagated for the same reasons as below
+				logger.Errorf("Error from listener during processing in-process chaincode lifecycle event - %+v", errors.WithStack(err))
+			}
+		}
 		if err := l.HandleChaincodeDeploy(legacyDefinition, dbArtifacts); err != nil {
 			// If a listener return this error and we propagate this error up the stack,
 			// following are the implications:
@@ -163,6 +176,22 @@ func (b *EventBroker) invokeListeners(channelID string, legacyDefinition *ledger
 	}
 }
 
+// inProcDBArtifacts returns the DB artifacts of the in-process user chaincode, if any, for the given collections
+func inProcDBArtifacts(ucc ccapi.UserCC, collections *pb.CollectionConfigPackage) map[string]*ccapi.DBArtifacts {
+	if ucc == nil {
+		return nil
+	}
+
+	var collNames []string
+	for _, collection := range collections.GetConfig() {
+		if staticConfig := collection.GetStaticCollectionConfig(); staticConfig != nil {
+			collNames = append(collNames, staticConfig.Name)
+		}
+	}
+
+	return ucc.GetDBArtifacts(collNames)
+}
+
 func (b *EventBroker) invokeDoneOnListeners(channelID string, succeeded bool) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
@@ -0,0 +1,23 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lifecycle
+
+import (
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/ledger"
+	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
+)
+
+// Helpers to access unexported state.
+
+func InvokeListeners(b *EventBroker, channelID string, def *ledger.ChaincodeDefinition, dbArtifacts []byte, inProcDBArtifacts map[string]*ccapi.DBArtifacts) {
+	b.invokeListeners(channelID, def, dbArtifacts, inProcDBArtifacts)
+}
+
+func InProcDBArtifacts(ucc ccapi.UserCC, collections *pb.CollectionConfigPackage) map[string]*ccapi.DBArtifacts {
+	return inProcDBArtifacts(ucc, collections)
+}
@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"io"
 
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	lb "github.com/hyperledger/fabric-protos-go/peer/lifecycle"
 	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
 	"github.com/hyperledger/fabric/core/chaincode/lifecycle/mock"
@@ -18,6 +19,8 @@ import (
 	"github.com/hyperledger/fabric/core/container/externalbuilder"
 	"github.com/hyperledger/fabric/core/ledger"
 	ledgermock "github.com/hyperledger/fabric/core/ledger/mock"
+	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
+	extmock "github.com/hyperledger/fabric/extensions/chaincode/mock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -245,4 +248,69 @@ var _ = Describe("EventBroker", func() {
 			})
 		})
 	})
+
+	Describe("in-process user chaincodes", func() {
+		var (
+			fakeUCC        *extmock.UserCC
+			inProcListener *inProcEventListener
+			collections    *pb.CollectionConfigPackage
+			dbArtifacts    map[string]*ccapi.DBArtifacts
+		)
+
+		BeforeEach(func() {
+			dbArtifacts = map[string]*ccapi.DBArtifacts{
+				"CouchDB": {Indexes: []string{"index-1"}},
+			}
+			fakeUCC = &extmock.UserCC{}
+			fakeUCC.GetDBArtifactsReturns(dbArtifacts)
+
+			collections = &pb.CollectionConfigPackage{
+				Config: []*pb.CollectionConfig{{
+					Payload: &pb.CollectionConfig_StaticCollectionConfig{
+						StaticCollectionConfig: &pb.StaticCollectionConfig{Name: "collection-1"},
+					},
+				}},
+			}
+
+			inProcListener = &inProcEventListener{ChaincodeLifecycleEventListener: &ledgermock.ChaincodeLifecycleEventListener{}}
+			eventBroker.RegisterListener("channel-1", inProcListener)
+		})
+
+		It("retrieves the DB artifacts of the chaincode for its collections", func() {
+			Expect(lifecycle.InProcDBArtifacts(fakeUCC, collections)).To(Equal(dbArtifacts))
+			Expect(fakeUCC.GetDBArtifactsCallCount()).To(Equal(1))
+			Expect(fakeUCC.GetDBArtifactsArgsForCall(0)).To(Equal([]string{"collection-1"}))
+
+			Expect(lifecycle.InProcDBArtifacts(nil, collections)).To(BeNil())
+		})
+
+		It("provides the DB artifacts to the listeners which handle in-process chaincodes", func() {
+			def := &ledger.ChaincodeDefinition{Name: "chaincode-1", Hash: []byte("ucc-1:v1")}
+			lifecycle.InvokeListeners(eventBroker, "channel-1", def, nil, dbArtifacts)
+
+			Expect(fakeListener.HandleChaincodeDeployCallCount()).To(Equal(1))
+			Expect(inProcListener.definitions).To(Equal([]*ledger.ChaincodeDefinition{def}))
+			Expect(inProcListener.dbArtifacts).To(Equal([]map[string]*ccapi.DBArtifacts{dbArtifacts}))
+		})
+
+		It("does not invoke the in-process handler for other chaincodes", func() {
+			lifecycle.InvokeListeners(eventBroker, "channel-1", &ledger.ChaincodeDefinition{}, []byte("db-artifacts"), nil)
+
+			Expect(fakeListener.HandleChaincodeDeployCallCount()).To(Equal(1))
+			Expect(inProcListener.definitions).To(BeEmpty())
+		})
+	})
 })
+
+// inProcEventListener records the DB artifacts of in-process user chaincodes
+type inProcEventListener struct {
+	ledger.ChaincodeLifecycleEventListener
+	definitions []*ledger.ChaincodeDefinition
+	dbArtifacts []map[string]*ccapi.DBArtifacts
+}
+
+func (l *inProcEventListener) HandleInProcChaincodeDeploy(def *ledger.ChaincodeDefinition, dbArtifacts map[string]*ccapi.DBArtifacts) error {
+	l.definitions = append(l.definitions, def)
+	l.dbArtifacts = append(l.dbArtifacts, dbArtifacts)
+	return nil
+}
@@ -212,6 +212,13 @@ type PackageParser interface {
 	Parse(data []byte) (*persistence.ChaincodePackage, error)
 }
 
+//go:generate counterfeiter -o mock/package_verifier.go --fake-name PackageVerifier . PackageVerifier
+
+// PackageVerifier verifies a chaincode package before it is installed.
+type PackageVerifier interface {
+	VerifyPackage(pkg *persistence.ChaincodePackage) error
+}
+
 //go:generate counterfeiter -o mock/install_listener.go --fake-name InstallListener . InstallListener
 type InstallListener interface {
 	HandleChaincodeInstalled(md *persistence.ChaincodePackageMetadata, packageID string)
@@ -307,6 +314,7 @@ type ExternalFunctions struct {
 	Resources                 *Resources
 	InstallListener           InstallListener
 	InstalledChaincodesLister InstalledChaincodesLister
+	PackageVerifier           PackageVerifier
 	ChaincodeBuilder          ChaincodeBuilder
 	BuildRegistry             *container.BuildRegistry
 	mutex                     sync.Mutex
@@ -672,6 +680,12 @@ func (ef *ExternalFunctions) InstallChaincode(chaincodeInstallPackage []byte) (*
 		return nil, errors.New("empty metadata for supplied chaincode")
 	}
 
+	if ef.PackageVerifier != nil {
+		if err := ef.PackageVerifier.VerifyPackage(pkg); err != nil {
+			return nil, errors.WithMessage(err, "could not verify chaincode package")
+		}
+	}
+
 	packageID, err := ef.Resources.ChaincodeStore.Save(pkg.Metadata.Label, chaincodeInstallPackage)
 	if err != nil {
 		return nil, errors.WithMessage(err, "could not save cc install package")
@@ -425,6 +425,39 @@ var _ = Describe("ExternalFunctions", func() {
 			})
 		})
 
+		Context("when a package verifier is set", func() {
+			var fakeVerifier *mock.PackageVerifier
+
+			BeforeEach(func() {
+				fakeVerifier = &mock.PackageVerifier{}
+				ef.PackageVerifier = fakeVerifier
+			})
+
+			It("verifies the package before saving it", func() {
+				_, err := ef.InstallChaincode([]byte("cc-package"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeVerifier.VerifyPackageCallCount()).To(Equal(1))
+				pkg := fakeVerifier.VerifyPackageArgsForCall(0)
+				Expect(pkg.Metadata.Label).To(Equal("cc-label"))
+				Expect(fakeCCStore.SaveCallCount()).To(Equal(1))
+			})
+
+			Context("when the package cannot be verified", func() {
+				BeforeEach(func() {
+					fakeVerifier.VerifyPackageReturns(fmt.Errorf("chaincode package is not signed"))
+				})
+
+				It("does not save the chaincode and returns the error", func() {
+					cc, err := ef.InstallChaincode([]byte("cc-package"))
+					Expect(cc).To(BeNil())
+					Expect(err).To(MatchError("could not verify chaincode package: chaincode package is not signed"))
+					Expect(fakeCCStore.SaveCallCount()).To(Equal(0))
+					Expect(fakeChaincodeBuilder.BuildCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when saving the chaincode fails", func() {
 			BeforeEach(func() {
 				fakeCCStore.SaveReturns("", fmt.Errorf("fake-error"))
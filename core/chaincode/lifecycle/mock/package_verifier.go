@@ -0,0 +1,112 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mock
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+)
+
+type PackageVerifier struct {
+	VerifyPackageStub        func(*persistence.ChaincodePackage) error
+	verifyPackageMutex       sync.RWMutex
+	verifyPackageArgsForCall []struct {
+		arg1 *persistence.ChaincodePackage
+	}
+	verifyPackageReturns struct {
+		result1 error
+	}
+	verifyPackageReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *PackageVerifier) VerifyPackage(arg1 *persistence.ChaincodePackage) error {
+	fake.verifyPackageMutex.Lock()
+	ret, specificReturn := fake.verifyPackageReturnsOnCall[len(fake.verifyPackageArgsForCall)]
+	fake.verifyPackageArgsForCall = append(fake.verifyPackageArgsForCall, struct {
+		arg1 *persistence.ChaincodePackage
+	}{arg1})
+	stub := fake.VerifyPackageStub
+	fakeReturns := fake.verifyPackageReturns
+	fake.recordInvocation("VerifyPackage", []interface{}{arg1})
+	fake.verifyPackageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *PackageVerifier) VerifyPackageCallCount() int {
+	fake.verifyPackageMutex.RLock()
+	defer fake.verifyPackageMutex.RUnlock()
+	return len(fake.verifyPackageArgsForCall)
+}
+
+func (fake *PackageVerifier) VerifyPackageCalls(stub func(*persistence.ChaincodePackage) error) {
+	fake.verifyPackageMutex.Lock()
+	defer fake.verifyPackageMutex.Unlock()
+	fake.VerifyPackageStub = stub
+}
+
+func (fake *PackageVerifier) VerifyPackageArgsForCall(i int) *persistence.ChaincodePackage {
+	fake.verifyPackageMutex.RLock()
+	defer fake.verifyPackageMutex.RUnlock()
+	argsForCall := fake.verifyPackageArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *PackageVerifier) VerifyPackageReturns(result1 error) {
+	fake.verifyPackageMutex.Lock()
+	defer fake.verifyPackageMutex.Unlock()
+	fake.VerifyPackageStub = nil
+	fake.verifyPackageReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *PackageVerifier) VerifyPackageReturnsOnCall(i int, result1 error) {
+	fake.verifyPackageMutex.Lock()
+	defer fake.verifyPackageMutex.Unlock()
+	fake.VerifyPackageStub = nil
+	if fake.verifyPackageReturnsOnCall == nil {
+		fake.verifyPackageReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.verifyPackageReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *PackageVerifier) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.verifyPackageMutex.RLock()
+	defer fake.verifyPackageMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *PackageVerifier) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ lifecycle.PackageVerifier = new(PackageVerifier)
@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lifecycle
+
+import (
+	"sort"
+
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// PackageSignaturePolicy is a PackageVerifier which requires the signatures
+// embedded in chaincode packages to satisfy a policy.
+type PackageSignaturePolicy struct {
+	Policy policies.Policy
+}
+
+// NewPackageSignaturePolicy returns a PackageSignaturePolicy for a signature
+// policy expression such as "OR('Org1.admin')". The identities signing the
+// packages are deserialized with the deserializer.
+func NewPackageSignaturePolicy(expression string, deserializer msp.IdentityDeserializer) (*PackageSignaturePolicy, error) {
+	spe, err := policydsl.FromString(expression)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid package signature policy '%s'", expression)
+	}
+
+	policy, err := (&cauthdsl.EnvelopeBasedPolicyProvider{Deserializer: deserializer}).NewPolicy(spe)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid package signature policy '%s'", expression)
+	}
+
+	return &PackageSignaturePolicy{Policy: policy}, nil
+}
+
+// VerifyPackage returns an error if the package is not signed or its
+// signatures do not satisfy the policy.
+func (p *PackageSignaturePolicy) VerifyPackage(pkg *persistence.ChaincodePackage) error {
+	if len(pkg.Signatures) == 0 {
+		return errors.New("chaincode package is not signed")
+	}
+
+	content := persistence.SignedContent(pkg.MetadataBytes, pkg.CodePackage)
+	signedData := make([]*protoutil.SignedData, len(pkg.Signatures))
+	for i, signature := range pkg.Signatures {
+		signedData[i] = &protoutil.SignedData{
+			Data:      content,
+			Identity:  signature.Identity,
+			Signature: signature.Signature,
+		}
+	}
+
+	if err := p.Policy.EvaluateSignedData(signedData); err != nil {
+		return errors.WithMessage(err, "chaincode package signatures do not satisfy the package signature policy")
+	}
+
+	return nil
+}
+
+// PackageSignerDeserializer deserializes the identities signing chaincode
+// packages with the local MSP or, for identities of other organizations,
+// with the MSPs of the channels the peer has joined.
+type PackageSignerDeserializer struct {
+	LocalDeserializer    msp.IdentityDeserializer
+	ChannelDeserializers func() map[string]msp.IdentityDeserializer
+}
+
+// DeserializeIdentity deserializes the identity with the first deserializer
+// which is able to.
+func (d *PackageSignerDeserializer) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	identity, err := d.LocalDeserializer.DeserializeIdentity(serializedIdentity)
+	if err == nil {
+		return identity, nil
+	}
+
+	for _, deserializer := range d.channelDeserializers() {
+		if identity, err := deserializer.DeserializeIdentity(serializedIdentity); err == nil {
+			return identity, nil
+		}
+	}
+
+	return nil, err
+}
+
+// IsWellFormed checks that the identity is well formed for one of the
+// deserializers.
+func (d *PackageSignerDeserializer) IsWellFormed(identity *mspprotos.SerializedIdentity) error {
+	err := d.LocalDeserializer.IsWellFormed(identity)
+	if err == nil {
+		return nil
+	}
+
+	for _, deserializer := range d.channelDeserializers() {
+		if deserializer.IsWellFormed(identity) == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// channelDeserializers returns the channel deserializers ordered by channel
+// name.
+func (d *PackageSignerDeserializer) channelDeserializers() []msp.IdentityDeserializer {
+	if d.ChannelDeserializers == nil {
+		return nil
+	}
+
+	deserializers := d.ChannelDeserializers()
+	channelIDs := make([]string, 0, len(deserializers))
+	for channelID := range deserializers {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+
+	result := make([]msp.IdentityDeserializer, len(channelIDs))
+	for i, channelID := range channelIDs {
+		result[i] = deserializers[channelID]
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lifecycle_test
+
+import (
+	"fmt"
+
+	mspprotos "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
+	"github.com/hyperledger/fabric/core/chaincode/lifecycle/mock"
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PackageSignaturePolicy", func() {
+	var (
+		fakePolicy *mock.InconvertiblePolicy
+		psp        *lifecycle.PackageSignaturePolicy
+		pkg        *persistence.ChaincodePackage
+	)
+
+	BeforeEach(func() {
+		fakePolicy = &mock.InconvertiblePolicy{}
+		psp = &lifecycle.PackageSignaturePolicy{Policy: fakePolicy}
+		pkg = &persistence.ChaincodePackage{
+			MetadataBytes: []byte("metadata"),
+			CodePackage:   []byte("code"),
+			Signatures: []*persistence.ChaincodePackageSignature{
+				{Identity: []byte("identity1"), Signature: []byte("signature1")},
+				{Identity: []byte("identity2"), Signature: []byte("signature2")},
+			},
+		}
+	})
+
+	It("evaluates the signatures over the metadata and code package", func() {
+		err := psp.VerifyPackage(pkg)
+		Expect(err).NotTo(HaveOccurred())
+
+		content := persistence.SignedContent([]byte("metadata"), []byte("code"))
+		Expect(fakePolicy.EvaluateSignedDataCallCount()).To(Equal(1))
+		Expect(fakePolicy.EvaluateSignedDataArgsForCall(0)).To(Equal([]*protoutil.SignedData{
+			{Data: content, Identity: []byte("identity1"), Signature: []byte("signature1")},
+			{Data: content, Identity: []byte("identity2"), Signature: []byte("signature2")},
+		}))
+	})
+
+	Context("when the package is not signed", func() {
+		BeforeEach(func() {
+			pkg.Signatures = nil
+		})
+
+		It("returns an error", func() {
+			err := psp.VerifyPackage(pkg)
+			Expect(err).To(MatchError("chaincode package is not signed"))
+			Expect(fakePolicy.EvaluateSignedDataCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the policy is not satisfied", func() {
+		BeforeEach(func() {
+			fakePolicy.EvaluateSignedDataReturns(fmt.Errorf("signature set did not satisfy policy"))
+		})
+
+		It("returns an error", func() {
+			err := psp.VerifyPackage(pkg)
+			Expect(err).To(MatchError("chaincode package signatures do not satisfy the package signature policy: signature set did not satisfy policy"))
+		})
+	})
+
+	Describe("NewPackageSignaturePolicy", func() {
+		It("creates the policy from the expression", func() {
+			psp, err := lifecycle.NewPackageSignaturePolicy("OR('Org1MSP.admin')", &mock.MSP{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(psp.Policy).NotTo(BeNil())
+		})
+
+		Context("when the expression is invalid", func() {
+			It("returns an error", func() {
+				_, err := lifecycle.NewPackageSignaturePolicy("bad-policy", &mock.MSP{})
+				Expect(err).To(MatchError(ContainSubstring("invalid package signature policy 'bad-policy'")))
+			})
+		})
+	})
+})
+
+var _ = Describe("PackageSignerDeserializer", func() {
+	var (
+		fakeLocalMSP    *mock.MSP
+		fakeChannelMSPs map[string]*mock.MSP
+		psd             *lifecycle.PackageSignerDeserializer
+	)
+
+	BeforeEach(func() {
+		fakeLocalMSP = &mock.MSP{}
+		fakeLocalMSP.DeserializeIdentityReturns(nil, fmt.Errorf("local-deserialize-error"))
+		fakeLocalMSP.IsWellFormedReturns(fmt.Errorf("local-well-formed-error"))
+		fakeChannelMSPs = map[string]*mock.MSP{
+			"channel1": {},
+			"channel2": {},
+		}
+		for _, fakeMSP := range fakeChannelMSPs {
+			fakeMSP.DeserializeIdentityReturns(nil, fmt.Errorf("channel-deserialize-error"))
+			fakeMSP.IsWellFormedReturns(fmt.Errorf("channel-well-formed-error"))
+		}
+
+		psd = &lifecycle.PackageSignerDeserializer{
+			LocalDeserializer: fakeLocalMSP,
+			ChannelDeserializers: func() map[string]msp.IdentityDeserializer {
+				deserializers := map[string]msp.IdentityDeserializer{}
+				for channelID, fakeMSP := range fakeChannelMSPs {
+					deserializers[channelID] = fakeMSP
+				}
+				return deserializers
+			},
+		}
+	})
+
+	Describe("DeserializeIdentity", func() {
+		It("deserializes identities of the local MSP", func() {
+			fakeLocalMSP.DeserializeIdentityReturns(nil, nil)
+
+			_, err := psd.DeserializeIdentity([]byte("identity"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLocalMSP.DeserializeIdentityArgsForCall(0)).To(Equal([]byte("identity")))
+			Expect(fakeChannelMSPs["channel1"].DeserializeIdentityCallCount()).To(Equal(0))
+		})
+
+		It("falls back to the channel MSPs", func() {
+			fakeChannelMSPs["channel2"].DeserializeIdentityReturns(nil, nil)
+
+			_, err := psd.DeserializeIdentity([]byte("identity"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeChannelMSPs["channel1"].DeserializeIdentityCallCount()).To(Equal(1))
+			Expect(fakeChannelMSPs["channel2"].DeserializeIdentityCallCount()).To(Equal(1))
+		})
+
+		It("returns the error of the local MSP when no MSP can deserialize the identity", func() {
+			_, err := psd.DeserializeIdentity([]byte("identity"))
+			Expect(err).To(MatchError("local-deserialize-error"))
+		})
+	})
+
+	Describe("IsWellFormed", func() {
+		It("checks the identity with the local MSP", func() {
+			fakeLocalMSP.IsWellFormedReturns(nil)
+
+			err := psd.IsWellFormed(&mspprotos.SerializedIdentity{Mspid: "Org1MSP"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeChannelMSPs["channel1"].IsWellFormedCallCount()).To(Equal(0))
+		})
+
+		It("falls back to the channel MSPs", func() {
+			fakeChannelMSPs["channel1"].IsWellFormedReturns(nil)
+
+			err := psd.IsWellFormed(&mspprotos.SerializedIdentity{Mspid: "Org2MSP"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the error of the local MSP when the identity is not well formed", func() {
+			err := psd.IsWellFormed(&mspprotos.SerializedIdentity{Mspid: "Org3MSP"})
+			Expect(err).To(MatchError("local-well-formed-error"))
+		})
+	})
+})
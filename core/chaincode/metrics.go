@@ -31,6 +31,21 @@ var (
 		StatsdFormat: "%{#fqname}.%{chaincode}",
 	}
 
+	restarts = metrics.CounterOpts{
+		Namespace:    "chaincode",
+		Name:         "restarts",
+		Help:         "The number of times a chaincode has been restarted after exiting unexpectedly.",
+		LabelNames:   []string{"chaincode"},
+		StatsdFormat: "%{#fqname}.%{chaincode}",
+	}
+	status = metrics.GaugeOpts{
+		Namespace:    "chaincode",
+		Name:         "status",
+		Help:         "The status of a supervised chaincode: 0 is running, 1 is restarting, 2 is failed and -1 is stopped by the peer.",
+		LabelNames:   []string{"chaincode"},
+		StatsdFormat: "%{#fqname}.%{chaincode}",
+	}
+
 	shimRequestsReceived = metrics.CounterOpts{
 		Namespace:    "chaincode",
 		Name:         "shim_requests_received",
@@ -59,6 +74,13 @@ var (
 		LabelNames:   []string{"chaincode"},
 		StatsdFormat: "%{#fqname}.%{chaincode}",
 	}
+	quotaRejections = metrics.CounterOpts{
+		Namespace:    "chaincode",
+		Name:         "quota_rejections",
+		Help:         "The number of chaincode invocations and requests rejected because a quota was exceeded.",
+		LabelNames:   []string{"channel", "chaincode", "quota"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{chaincode}.%{quota}",
+	}
 )
 
 type HandlerMetrics struct {
@@ -66,6 +88,7 @@ type HandlerMetrics struct {
 	ShimRequestsCompleted metrics.Counter
 	ShimRequestDuration   metrics.Histogram
 	ExecuteTimeouts       metrics.Counter
+	QuotaRejections       metrics.Counter
 }
 
 func NewHandlerMetrics(p metrics.Provider) *HandlerMetrics {
@@ -74,6 +97,7 @@ func NewHandlerMetrics(p metrics.Provider) *HandlerMetrics {
 		ShimRequestsCompleted: p.NewCounter(shimRequestsCompleted),
 		ShimRequestDuration:   p.NewHistogram(shimRequestDuration),
 		ExecuteTimeouts:       p.NewCounter(executeTimeouts),
+		QuotaRejections:       p.NewCounter(quotaRejections),
 	}
 }
 
@@ -90,3 +114,15 @@ func NewLaunchMetrics(p metrics.Provider) *LaunchMetrics {
 		LaunchTimeouts: p.NewCounter(launchTimeouts),
 	}
 }
+
+type SupervisorMetrics struct {
+	Restarts metrics.Counter
+	Status   metrics.Gauge
+}
+
+func NewSupervisorMetrics(p metrics.Provider) *SupervisorMetrics {
+	return &SupervisorMetrics{
+		Restarts: p.NewCounter(restarts),
+		Status:   p.NewGauge(status),
+	}
+}
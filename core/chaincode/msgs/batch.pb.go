@@ -0,0 +1,286 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: batch.proto
+
+package msgs
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Type is the kind of write, named after the corresponding
+// single-key ChaincodeMessage type
+type WriteRecord_Type int32
+
+const (
+	WriteRecord_UNDEFINED          WriteRecord_Type = 0
+	WriteRecord_PUT_STATE          WriteRecord_Type = 9
+	WriteRecord_DEL_STATE          WriteRecord_Type = 10
+	WriteRecord_PURGE_PRIVATE_DATA WriteRecord_Type = 23
+)
+
+var WriteRecord_Type_name = map[int32]string{
+	0:  "UNDEFINED",
+	9:  "PUT_STATE",
+	10: "DEL_STATE",
+	23: "PURGE_PRIVATE_DATA",
+}
+
+var WriteRecord_Type_value = map[string]int32{
+	"UNDEFINED":          0,
+	"PUT_STATE":          9,
+	"DEL_STATE":          10,
+	"PURGE_PRIVATE_DATA": 23,
+}
+
+func (x WriteRecord_Type) String() string {
+	return proto.EnumName(WriteRecord_Type_name, int32(x))
+}
+
+func (WriteRecord_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_905061dbf2994c5e, []int{2, 0}
+}
+
+// GetStateMultiple is the payload of a GET_STATE_MULTIPLE message. It reads
+// several keys from the public state or, if collection is set, from a private
+// data collection in a single round-trip.
+type GetStateMultiple struct {
+	Keys                 []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	Collection           string   `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStateMultiple) Reset()         { *m = GetStateMultiple{} }
+func (m *GetStateMultiple) String() string { return proto.CompactTextString(m) }
+func (*GetStateMultiple) ProtoMessage()    {}
+func (*GetStateMultiple) Descriptor() ([]byte, []int) {
+	return fileDescriptor_905061dbf2994c5e, []int{0}
+}
+
+func (m *GetStateMultiple) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStateMultiple.Unmarshal(m, b)
+}
+func (m *GetStateMultiple) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStateMultiple.Marshal(b, m, deterministic)
+}
+func (m *GetStateMultiple) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStateMultiple.Merge(m, src)
+}
+func (m *GetStateMultiple) XXX_Size() int {
+	return xxx_messageInfo_GetStateMultiple.Size(m)
+}
+func (m *GetStateMultiple) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStateMultiple.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStateMultiple proto.InternalMessageInfo
+
+func (m *GetStateMultiple) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *GetStateMultiple) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+// GetStateMultipleResult is the payload of the response to a
+// GET_STATE_MULTIPLE message. The values are in the order of the requested
+// keys; the value of a key which does not exist is empty.
+type GetStateMultipleResult struct {
+	Values               [][]byte `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStateMultipleResult) Reset()         { *m = GetStateMultipleResult{} }
+func (m *GetStateMultipleResult) String() string { return proto.CompactTextString(m) }
+func (*GetStateMultipleResult) ProtoMessage()    {}
+func (*GetStateMultipleResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_905061dbf2994c5e, []int{1}
+}
+
+func (m *GetStateMultipleResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStateMultipleResult.Unmarshal(m, b)
+}
+func (m *GetStateMultipleResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStateMultipleResult.Marshal(b, m, deterministic)
+}
+func (m *GetStateMultipleResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStateMultipleResult.Merge(m, src)
+}
+func (m *GetStateMultipleResult) XXX_Size() int {
+	return xxx_messageInfo_GetStateMultipleResult.Size(m)
+}
+func (m *GetStateMultipleResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStateMultipleResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStateMultipleResult proto.InternalMessageInfo
+
+func (m *GetStateMultipleResult) GetValues() [][]byte {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// WriteRecord is a single write of a WriteBatchState
+type WriteRecord struct {
+	Key                  string           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                []byte           `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Collection           string           `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	Type                 WriteRecord_Type `protobuf:"varint,4,opt,name=type,proto3,enum=msgs.WriteRecord_Type" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *WriteRecord) Reset()         { *m = WriteRecord{} }
+func (m *WriteRecord) String() string { return proto.CompactTextString(m) }
+func (*WriteRecord) ProtoMessage()    {}
+func (*WriteRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_905061dbf2994c5e, []int{2}
+}
+
+func (m *WriteRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteRecord.Unmarshal(m, b)
+}
+func (m *WriteRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteRecord.Marshal(b, m, deterministic)
+}
+func (m *WriteRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteRecord.Merge(m, src)
+}
+func (m *WriteRecord) XXX_Size() int {
+	return xxx_messageInfo_WriteRecord.Size(m)
+}
+func (m *WriteRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteRecord proto.InternalMessageInfo
+
+func (m *WriteRecord) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *WriteRecord) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *WriteRecord) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *WriteRecord) GetType() WriteRecord_Type {
+	if m != nil {
+		return m.Type
+	}
+	return WriteRecord_UNDEFINED
+}
+
+// WriteBatchState is the payload of a WRITE_BATCH_STATE message. The
+// records are applied in order, exactly as if they were sent one by one.
+type WriteBatchState struct {
+	Rec                  []*WriteRecord `protobuf:"bytes,1,rep,name=rec,proto3" json:"rec,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *WriteBatchState) Reset()         { *m = WriteBatchState{} }
+func (m *WriteBatchState) String() string { return proto.CompactTextString(m) }
+func (*WriteBatchState) ProtoMessage()    {}
+func (*WriteBatchState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_905061dbf2994c5e, []int{3}
+}
+
+func (m *WriteBatchState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteBatchState.Unmarshal(m, b)
+}
+func (m *WriteBatchState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteBatchState.Marshal(b, m, deterministic)
+}
+func (m *WriteBatchState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteBatchState.Merge(m, src)
+}
+func (m *WriteBatchState) XXX_Size() int {
+	return xxx_messageInfo_WriteBatchState.Size(m)
+}
+func (m *WriteBatchState) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteBatchState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteBatchState proto.InternalMessageInfo
+
+func (m *WriteBatchState) GetRec() []*WriteRecord {
+	if m != nil {
+		return m.Rec
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("msgs.WriteRecord_Type", WriteRecord_Type_name, WriteRecord_Type_value)
+	proto.RegisterType((*GetStateMultiple)(nil), "msgs.GetStateMultiple")
+	proto.RegisterType((*GetStateMultipleResult)(nil), "msgs.GetStateMultipleResult")
+	proto.RegisterType((*WriteRecord)(nil), "msgs.WriteRecord")
+	proto.RegisterType((*WriteBatchState)(nil), "msgs.WriteBatchState")
+}
+
+func init() { proto.RegisterFile("batch.proto", fileDescriptor_905061dbf2994c5e) }
+
+var fileDescriptor_905061dbf2994c5e = []byte{
+	// 335 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0xc1, 0x8b, 0xda, 0x40,
+	0x14, 0xc6, 0x9b, 0x26, 0x15, 0xf2, 0xb4, 0x6d, 0x3a, 0x94, 0x34, 0xa7, 0x22, 0xe9, 0x45, 0x7a,
+	0x48, 0x5a, 0x85, 0xde, 0x23, 0x89, 0x22, 0xdd, 0x15, 0x19, 0xe3, 0x2e, 0xec, 0x45, 0x92, 0xf1,
+	0xad, 0x09, 0x46, 0x27, 0x4c, 0x26, 0x0b, 0xf9, 0x27, 0xf7, 0x6f, 0x5a, 0x66, 0xf4, 0x20, 0xee,
+	0xed, 0xfd, 0x66, 0x1e, 0xdf, 0xf7, 0xbd, 0xf7, 0xa0, 0x9f, 0x67, 0x92, 0x15, 0x41, 0x2d, 0xb8,
+	0xe4, 0xc4, 0x3a, 0x36, 0xfb, 0xc6, 0x9f, 0x81, 0x33, 0x47, 0xb9, 0x96, 0x99, 0xc4, 0xfb, 0xb6,
+	0x92, 0x65, 0x5d, 0x21, 0x21, 0x60, 0x1d, 0xb0, 0x6b, 0x3c, 0x63, 0x68, 0x8e, 0x6c, 0xaa, 0x6b,
+	0xf2, 0x13, 0x80, 0xf1, 0xaa, 0x42, 0x26, 0x4b, 0x7e, 0xf2, 0x3e, 0x0e, 0x8d, 0x91, 0x4d, 0xaf,
+	0x5e, 0xfc, 0x3f, 0xe0, 0xde, 0xea, 0x50, 0x6c, 0xda, 0x4a, 0x12, 0x17, 0x7a, 0x2f, 0x59, 0xd5,
+	0xe2, 0x59, 0x6f, 0x40, 0x2f, 0xe4, 0xbf, 0x1a, 0xd0, 0x7f, 0x14, 0xa5, 0x44, 0x8a, 0x8c, 0x8b,
+	0x1d, 0x71, 0xc0, 0x3c, 0x60, 0xe7, 0x19, 0x5a, 0x5a, 0x95, 0xe4, 0x3b, 0x7c, 0xd2, 0xbd, 0xda,
+	0x6e, 0x40, 0xcf, 0x70, 0x93, 0xc4, 0xbc, 0x4d, 0x42, 0x7e, 0x83, 0x25, 0xbb, 0x1a, 0x3d, 0x6b,
+	0x68, 0x8c, 0xbe, 0x8c, 0xdd, 0x40, 0x8d, 0x19, 0x5c, 0x19, 0x05, 0x69, 0x57, 0x23, 0xd5, 0x3d,
+	0xfe, 0x7f, 0xb0, 0x14, 0x91, 0xcf, 0x60, 0x6f, 0x96, 0x71, 0x32, 0x5b, 0x2c, 0x93, 0xd8, 0xf9,
+	0xa0, 0x70, 0xb5, 0x49, 0xb7, 0xeb, 0x34, 0x4a, 0x13, 0xc7, 0x56, 0x18, 0x27, 0x77, 0x17, 0x04,
+	0xe2, 0x02, 0x59, 0x6d, 0xe8, 0x3c, 0xd9, 0xae, 0xe8, 0xe2, 0x21, 0x4a, 0x93, 0x6d, 0x1c, 0xa5,
+	0x91, 0xf3, 0xc3, 0xff, 0x07, 0x5f, 0xb5, 0xcd, 0x54, 0x2d, 0x59, 0x6f, 0x82, 0xfc, 0x02, 0x53,
+	0x20, 0xd3, 0x83, 0xf7, 0xc7, 0xdf, 0xde, 0x45, 0xa1, 0xea, 0x77, 0x3a, 0x79, 0xfa, 0xbb, 0x2f,
+	0x65, 0xd1, 0xe6, 0x01, 0xe3, 0xc7, 0xb0, 0xe8, 0x6a, 0x14, 0x15, 0xee, 0xf6, 0x28, 0xc2, 0xe7,
+	0x2c, 0x17, 0x25, 0x0b, 0x19, 0x17, 0x18, 0xb2, 0x22, 0x2b, 0x4f, 0x8c, 0xef, 0x30, 0x54, 0x2a,
+	0x79, 0x4f, 0x1f, 0x71, 0xf2, 0x36, 0x00, 0x41, 0xb0, 0x52, 0x44, 0xd3, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgs
+
+import (
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CrossChannelLockKey is the state metadata key under which the lock
+	// of a key written by a cross-channel invocation is stored.
+	CrossChannelLockKey = "CROSS_CHANNEL_LOCK"
+
+	// CrossChannelCommitFuncName is the function which commits the locks
+	// of a cross-channel transaction.
+	CrossChannelCommitFuncName = "fabric.xcc.commit"
+
+	// CrossChannelAbortFuncName is the function which aborts the locks of a
+	// cross-channel transaction.
+	CrossChannelAbortFuncName = "fabric.xcc.abort"
+)
+
+// GetCrossChannelLock returns the cross-channel lock held in the state
+// metadata of a key, or nil if the key is not locked.
+func GetCrossChannelLock(metadata map[string][]byte) (*CrossChannelLock, error) {
+	lockBytes, ok := metadata[CrossChannelLockKey]
+	if !ok {
+		return nil, nil
+	}
+	lock := &CrossChannelLock{}
+	if err := proto.Unmarshal(lockBytes, lock); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal cross-channel lock")
+	}
+	return lock, nil
+}
+
+// GetCrossChannelResolution returns the ID of the cross-channel transaction
+// whose locks are committed or aborted by the chaincode input, or an empty
+// string if the input does not resolve locks.
+func GetCrossChannelResolution(input *pb.ChaincodeInput) string {
+	if len(input.GetArgs()) != 2 {
+		return ""
+	}
+	switch string(input.Args[0]) {
+	case CrossChannelCommitFuncName, CrossChannelAbortFuncName:
+		return string(input.Args[1])
+	default:
+		return ""
+	}
+}
+
+// AddCrossChannelResponses appends the cross-channel responses to the
+// unknown fields of the proposal response so that they are sent along with it.
+func AddCrossChannelResponses(resp *pb.ProposalResponse, responses []*CrossChannelResponse) error {
+	if len(responses) == 0 {
+		return nil
+	}
+	extra, err := proto.Marshal(&CrossChannelResponses{CrossChannelResponses: responses})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cross-channel responses")
+	}
+	resp.XXX_unrecognized = append(resp.XXX_unrecognized, extra...)
+	return nil
+}
+
+// GetCrossChannelResponses returns the cross-channel responses carried by
+// the proposal response, if any.
+func GetCrossChannelResponses(resp *pb.ProposalResponse) ([]*CrossChannelResponse, error) {
+	if len(resp.XXX_unrecognized) == 0 {
+		return nil, nil
+	}
+	responses := &CrossChannelResponses{}
+	if err := proto.Unmarshal(resp.XXX_unrecognized, responses); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal cross-channel responses")
+	}
+	return responses.CrossChannelResponses, nil
+}
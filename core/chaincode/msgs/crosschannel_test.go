@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgs
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossChannelResponses(t *testing.T) {
+	resp := &pb.ProposalResponse{Version: 1, Response: &pb.Response{Status: 200}, Payload: []byte("payload")}
+
+	responses, err := GetCrossChannelResponses(resp)
+	require.NoError(t, err)
+	require.Empty(t, responses)
+
+	expected := []*CrossChannelResponse{
+		{ChannelId: "settlement", Proposal: []byte("proposal"), ProposalResponse: []byte("proposal-response")},
+	}
+	require.NoError(t, AddCrossChannelResponses(resp, expected))
+
+	// the responses survive a round-trip through the wire format
+	respBytes, err := proto.Marshal(resp)
+	require.NoError(t, err)
+	received := &pb.ProposalResponse{}
+	require.NoError(t, proto.Unmarshal(respBytes, received))
+	require.Equal(t, []byte("payload"), received.Payload)
+
+	responses, err = GetCrossChannelResponses(received)
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	require.True(t, proto.Equal(expected[0], responses[0]))
+
+	received.XXX_unrecognized = []byte("garbage")
+	_, err = GetCrossChannelResponses(received)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal cross-channel responses")
+}
+
+func TestGetCrossChannelLock(t *testing.T) {
+	lock, err := GetCrossChannelLock(map[string][]byte{"other": []byte("value")})
+	require.NoError(t, err)
+	require.Nil(t, lock)
+
+	lockBytes, err := proto.Marshal(&CrossChannelLock{ChannelId: "token", TxId: "tx-id"})
+	require.NoError(t, err)
+	lock, err = GetCrossChannelLock(map[string][]byte{CrossChannelLockKey: lockBytes})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&CrossChannelLock{ChannelId: "token", TxId: "tx-id"}, lock))
+
+	_, err = GetCrossChannelLock(map[string][]byte{CrossChannelLockKey: []byte("garbage")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal cross-channel lock")
+}
+
+func TestGetCrossChannelResolution(t *testing.T) {
+	input := func(args ...string) *pb.ChaincodeInput {
+		in := &pb.ChaincodeInput{}
+		for _, arg := range args {
+			in.Args = append(in.Args, []byte(arg))
+		}
+		return in
+	}
+	require.Equal(t, "tx-id", GetCrossChannelResolution(input(CrossChannelCommitFuncName, "tx-id")))
+	require.Equal(t, "tx-id", GetCrossChannelResolution(input(CrossChannelAbortFuncName, "tx-id")))
+	require.Equal(t, "", GetCrossChannelResolution(input("invoke", "tx-id")))
+	require.Equal(t, "", GetCrossChannelResolution(input(CrossChannelCommitFuncName)))
+	require.Equal(t, "", GetCrossChannelResolution(input(CrossChannelCommitFuncName, "tx-id", "extra")))
+	require.Equal(t, "", GetCrossChannelResolution(nil))
+}
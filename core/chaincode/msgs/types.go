@@ -0,0 +1,43 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgs
+
+import (
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// ChaincodeMessage types which are handled by the peer in addition to those
+// defined by fabric-protos-go. They allow a chaincode shim to read and write
+// many keys in a single round-trip; a shim which does not send them keeps
+// working with the single-key messages. The values of WRITE_BATCH_STATE and
+// GET_STATE_MULTIPLE match the upstream chaincode shim protocol.
+// GET_STATE_BY_RANGE_BULK takes a GetStateByRange payload and responds with a
+// QueryResponse, like GET_STATE_BY_RANGE; its value is provisional until the
+// type is allocated in fabric-protos-go. PURGE_PRIVATE_DATA takes a DelState
+// payload, like DEL_STATE, and its value matches the upstream protocol.
+const (
+	ChaincodeMessage_PURGE_PRIVATE_DATA      pb.ChaincodeMessage_Type = 23
+	ChaincodeMessage_WRITE_BATCH_STATE       pb.ChaincodeMessage_Type = 24
+	ChaincodeMessage_GET_STATE_MULTIPLE      pb.ChaincodeMessage_Type = 25
+	ChaincodeMessage_GET_STATE_BY_RANGE_BULK pb.ChaincodeMessage_Type = 100
+)
+
+var typeNames = map[pb.ChaincodeMessage_Type]string{
+	ChaincodeMessage_PURGE_PRIVATE_DATA:      "PURGE_PRIVATE_DATA",
+	ChaincodeMessage_WRITE_BATCH_STATE:       "WRITE_BATCH_STATE",
+	ChaincodeMessage_GET_STATE_MULTIPLE:      "GET_STATE_MULTIPLE",
+	ChaincodeMessage_GET_STATE_BY_RANGE_BULK: "GET_STATE_BY_RANGE_BULK",
+}
+
+// TypeName returns the name of the given ChaincodeMessage type, including
+// the types defined in this package which fabric-protos-go does not know
+func TypeName(t pb.ChaincodeMessage_Type) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return t.String()
+}
@@ -0,0 +1,23 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgs
+
+import (
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeName(t *testing.T) {
+	require.Equal(t, "GET_STATE", TypeName(pb.ChaincodeMessage_GET_STATE))
+	require.Equal(t, "GET_STATE_BY_RANGE_BULK", TypeName(ChaincodeMessage_GET_STATE_BY_RANGE_BULK))
+
+	// the enum of fabric-protos-go is left untouched
+	_, registered := pb.ChaincodeMessage_Type_name[int32(ChaincodeMessage_GET_STATE_BY_RANGE_BULK)]
+	require.False(t, registered)
+}
@@ -219,9 +219,11 @@ func (tfs *TarFileStream) Close() error {
 
 // ChaincodePackage represents the un-tar-ed format of the chaincode package.
 type ChaincodePackage struct {
-	Metadata    *ChaincodePackageMetadata
-	CodePackage []byte
-	DBArtifacts []byte
+	Metadata      *ChaincodePackageMetadata
+	MetadataBytes []byte
+	CodePackage   []byte
+	DBArtifacts   []byte
+	Signatures    []*ChaincodePackageSignature
 }
 
 // ChaincodePackageMetadata contains the information necessary to understand
@@ -267,8 +269,9 @@ func (ccpp ChaincodePackageParser) Parse(source []byte) (*ChaincodePackage, erro
 
 	tarReader := tar.NewReader(gzReader)
 
-	var codePackage []byte
+	var codePackage, metadataBytes []byte
 	var ccPackageMetadata *ChaincodePackageMetadata
+	var signatures []*ChaincodePackageSignature
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
@@ -296,9 +299,15 @@ func (ccpp ChaincodePackageParser) Parse(source []byte) (*ChaincodePackage, erro
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not unmarshal %s as json", MetadataFile)
 			}
+			metadataBytes = fileBytes
 
 		case CodePackageFile:
 			codePackage = fileBytes
+		case SignaturesFile:
+			err := json.Unmarshal(fileBytes, &signatures)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not unmarshal %s as json", SignaturesFile)
+			}
 		default:
 			logger.Warningf("Encountered unexpected file '%s' in top level of chaincode package", header.Name)
 		}
@@ -322,8 +331,10 @@ func (ccpp ChaincodePackageParser) Parse(source []byte) (*ChaincodePackage, erro
 	}
 
 	return &ChaincodePackage{
-		Metadata:    ccPackageMetadata,
-		CodePackage: codePackage,
-		DBArtifacts: dbArtifacts,
+		Metadata:      ccPackageMetadata,
+		MetadataBytes: metadataBytes,
+		CodePackage:   codePackage,
+		DBArtifacts:   dbArtifacts,
+		Signatures:    signatures,
 	}, nil
 }
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package persistence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// SignaturesFile is the location of the detached signatures over the
+// metadata and code package in the top level of the chaincode package.
+const SignaturesFile = "signatures.json"
+
+// ChaincodePackageSignature is the signature of a chaincode package by an
+// identity.
+type ChaincodePackageSignature struct {
+	Identity  []byte `json:"identity"`
+	Signature []byte `json:"signature"`
+}
+
+//go:generate counterfeiter -o mock/package_signer.go --fake-name PackageSigner . PackageSigner
+
+// PackageSigner signs chaincode packages.
+type PackageSigner interface {
+	Sign(msg []byte) ([]byte, error)
+	Serialize() ([]byte, error)
+}
+
+// SignedContent returns the bytes signed by the signatures of a chaincode
+// package: the SHA-256 hash of the metadata file followed by the SHA-256
+// hash of the code package. The signatures cover neither the other files
+// of the package nor each other, so signatures may be added independently.
+func SignedContent(metadata, codePackage []byte) []byte {
+	mdHash := sha256.Sum256(metadata)
+	codeHash := sha256.Sum256(codePackage)
+	return append(mdHash[:], codeHash[:]...)
+}
+
+// SignChaincodePackage adds the signature of the signer to a chaincode
+// package and returns the signed package. A signature of the signer already
+// in the package is replaced. As the package ID is derived from the package
+// bytes, the signed package has a different package ID than the unsigned
+// one.
+func SignChaincodePackage(pkgBytes []byte, signer PackageSigner) ([]byte, error) {
+	gzReader, err := gzip.NewReader(bytes.NewBuffer(pkgBytes))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading as gzip stream")
+	}
+	tarReader := tar.NewReader(gzReader)
+
+	type entry struct {
+		header *tar.Header
+		data   []byte
+	}
+	var entries []entry
+	var metadata, codePackage []byte
+	var signatures []*ChaincodePackageSignature
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error inspecting next tar header")
+		}
+
+		fileBytes, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s from tar", header.Name)
+		}
+
+		switch header.Name {
+		case MetadataFile:
+			metadata = fileBytes
+		case CodePackageFile:
+			codePackage = fileBytes
+		case SignaturesFile:
+			if err := json.Unmarshal(fileBytes, &signatures); err != nil {
+				return nil, errors.Wrapf(err, "could not unmarshal %s as json", SignaturesFile)
+			}
+			continue
+		}
+		entries = append(entries, entry{header: header, data: fileBytes})
+	}
+
+	if metadata == nil {
+		return nil, errors.Errorf("did not find any package metadata (missing %s)", MetadataFile)
+	}
+	if codePackage == nil {
+		return nil, errors.Errorf("did not find a code package inside the package")
+	}
+
+	identity, err := signer.Serialize()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize signer")
+	}
+	signature, err := signer.Sign(SignedContent(metadata, codePackage))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create signature")
+	}
+
+	signed := []*ChaincodePackageSignature{}
+	for _, s := range signatures {
+		if !bytes.Equal(s.Identity, identity) {
+			signed = append(signed, s)
+		}
+	}
+	signed = append(signed, &ChaincodePackageSignature{
+		Identity:  identity,
+		Signature: signature,
+	})
+	signaturesBytes, err := json.Marshal(signed)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal signatures")
+	}
+	entries = append(entries, entry{
+		header: &tar.Header{
+			Name: SignaturesFile,
+			Size: int64(len(signaturesBytes)),
+			Mode: 0100644,
+		},
+		data: signaturesBytes,
+	})
+
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		if err := tw.WriteHeader(e.header); err != nil {
+			return nil, errors.Wrapf(err, "failed to write %s header to tar", e.header.Name)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return nil, errors.Wrapf(err, "failed to write %s to tar", e.header.Name)
+		}
+	}
+	err = tw.Close()
+	if err == nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create tar for chaincode package")
+	}
+
+	return payload.Bytes(), nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package persistence_test
+
+import (
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/core/chaincode/persistence/mock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	tm "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("SignChaincodePackage", func() {
+	var (
+		pkgBytes   []byte
+		fakeSigner *mock.PackageSigner
+		ccpp       persistence.ChaincodePackageParser
+	)
+
+	BeforeEach(func() {
+		var err error
+		pkgBytes, err = ioutil.ReadFile("testdata/good-package.tar.gz")
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeSigner = &mock.PackageSigner{}
+		fakeSigner.SerializeReturns([]byte("identity"), nil)
+		fakeSigner.SignReturns([]byte("signature"), nil)
+
+		mockMetaProvider := &mock.MetadataProvider{}
+		mockMetaProvider.On("GetDBArtifacts", tm.Anything).Return([]byte("DB artefacts"), nil)
+		ccpp = persistence.ChaincodePackageParser{MetadataProvider: mockMetaProvider}
+	})
+
+	It("embeds a signature over the metadata and code package", func() {
+		signed, err := persistence.SignChaincodePackage(pkgBytes, fakeSigner)
+		Expect(err).NotTo(HaveOccurred())
+
+		unsignedPackage, err := ccpp.Parse(pkgBytes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsignedPackage.Signatures).To(BeEmpty())
+
+		signedPackage, err := ccpp.Parse(signed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signedPackage.Metadata).To(Equal(unsignedPackage.Metadata))
+		Expect(signedPackage.MetadataBytes).To(Equal(unsignedPackage.MetadataBytes))
+		Expect(signedPackage.CodePackage).To(Equal(unsignedPackage.CodePackage))
+		Expect(signedPackage.Signatures).To(Equal([]*persistence.ChaincodePackageSignature{
+			{Identity: []byte("identity"), Signature: []byte("signature")},
+		}))
+
+		Expect(fakeSigner.SignCallCount()).To(Equal(1))
+		Expect(fakeSigner.SignArgsForCall(0)).To(Equal(persistence.SignedContent(unsignedPackage.MetadataBytes, unsignedPackage.CodePackage)))
+	})
+
+	Context("when the package is already signed", func() {
+		BeforeEach(func() {
+			otherSigner := &mock.PackageSigner{}
+			otherSigner.SerializeReturns([]byte("other-identity"), nil)
+			otherSigner.SignReturns([]byte("other-signature"), nil)
+
+			var err error
+			pkgBytes, err = persistence.SignChaincodePackage(pkgBytes, otherSigner)
+			Expect(err).NotTo(HaveOccurred())
+			pkgBytes, err = persistence.SignChaincodePackage(pkgBytes, fakeSigner)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("adds the signature and replaces the previous signature of the signer", func() {
+			fakeSigner.SignReturns([]byte("new-signature"), nil)
+			signed, err := persistence.SignChaincodePackage(pkgBytes, fakeSigner)
+			Expect(err).NotTo(HaveOccurred())
+
+			signedPackage, err := ccpp.Parse(signed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signedPackage.Signatures).To(Equal([]*persistence.ChaincodePackageSignature{
+				{Identity: []byte("other-identity"), Signature: []byte("other-signature")},
+				{Identity: []byte("identity"), Signature: []byte("new-signature")},
+			}))
+		})
+	})
+
+	Context("when the data is not gzipped", func() {
+		It("fails", func() {
+			_, err := persistence.SignChaincodePackage([]byte("bad-data"), fakeSigner)
+			Expect(err).To(MatchError("error reading as gzip stream: unexpected EOF"))
+		})
+	})
+
+	Context("when the chaincode package metadata is missing", func() {
+		It("fails", func() {
+			data, err := ioutil.ReadFile("testdata/missing-metadata.tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = persistence.SignChaincodePackage(data, fakeSigner)
+			Expect(err).To(MatchError("did not find any package metadata (missing metadata.json)"))
+		})
+	})
+
+	Context("when the tar is missing a code-package", func() {
+		It("fails", func() {
+			data, err := ioutil.ReadFile("testdata/missing-codepackage.tar.gz")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = persistence.SignChaincodePackage(data, fakeSigner)
+			Expect(err).To(MatchError("did not find a code package inside the package"))
+		})
+	})
+
+	Context("when the signer cannot be serialized", func() {
+		BeforeEach(func() {
+			fakeSigner.SerializeReturns(nil, errors.New("cocoa"))
+		})
+
+		It("fails", func() {
+			_, err := persistence.SignChaincodePackage(pkgBytes, fakeSigner)
+			Expect(err).To(MatchError("failed to serialize signer: cocoa"))
+		})
+	})
+
+	Context("when signing fails", func() {
+		BeforeEach(func() {
+			fakeSigner.SignReturns(nil, errors.New("puffs"))
+		})
+
+		It("fails", func() {
+			_, err := persistence.SignChaincodePackage(pkgBytes, fakeSigner)
+			Expect(err).To(MatchError("failed to create signature: puffs"))
+		})
+	})
+})
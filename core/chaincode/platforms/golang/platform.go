@@ -206,20 +206,20 @@ func getLDFlagsOpts() string {
 
 var buildScript = `
 set -e
-if [ -f "/chaincode/input/src/go.mod" ] && [ -d "/chaincode/input/src/vendor" ]; then
-    cd /chaincode/input/src
-    GO111MODULE=on go build -v -mod=vendor %[1]s -o /chaincode/output/chaincode %[2]s
-elif [ -f "/chaincode/input/src/go.mod" ]; then
-    cd /chaincode/input/src
-    GO111MODULE=on go build -v -mod=readonly %[1]s -o /chaincode/output/chaincode %[2]s
-elif [ -f "/chaincode/input/src/%[2]s/go.mod" ] && [ -d "/chaincode/input/src/%[2]s/vendor" ]; then
-    cd /chaincode/input/src/%[2]s
-    GO111MODULE=on go build -v -mod=vendor %[1]s -o /chaincode/output/chaincode .
-elif [ -f "/chaincode/input/src/%[2]s/go.mod" ]; then
-    cd /chaincode/input/src/%[2]s
-    GO111MODULE=on go build -v -mod=readonly %[1]s -o /chaincode/output/chaincode .
+if [ -f "%[3]s/src/go.mod" ] && [ -d "%[3]s/src/vendor" ]; then
+    cd %[3]s/src
+    GO111MODULE=on go build -v -mod=vendor %[1]s -o %[4]s %[2]s
+elif [ -f "%[3]s/src/go.mod" ]; then
+    cd %[3]s/src
+    GO111MODULE=on go build -v -mod=readonly %[1]s -o %[4]s %[2]s
+elif [ -f "%[3]s/src/%[2]s/go.mod" ] && [ -d "%[3]s/src/%[2]s/vendor" ]; then
+    cd %[3]s/src/%[2]s
+    GO111MODULE=on go build -v -mod=vendor %[1]s -o %[4]s .
+elif [ -f "%[3]s/src/%[2]s/go.mod" ]; then
+    cd %[3]s/src/%[2]s
+    GO111MODULE=on go build -v -mod=readonly %[1]s -o %[4]s .
 else
-    GOPATH=/chaincode/input:$GOPATH go build -v %[1]s -o /chaincode/output/chaincode %[2]s
+    GOPATH=%[3]s:$GOPATH go build -v %[1]s -o %[4]s %[2]s
 fi
 echo Done!
 `
@@ -237,11 +237,19 @@ func (p *Platform) DockerBuildOptions(path string) (util.DockerBuildOptions, err
 	}
 	ldFlagOpts := getLDFlagsOpts()
 	return util.DockerBuildOptions{
-		Cmd: fmt.Sprintf(buildScript, ldFlagOpts, path),
+		Cmd: fmt.Sprintf(buildScript, ldFlagOpts, path, "/chaincode/input", "/chaincode/output/chaincode"),
 		Env: env,
 	}, nil
 }
 
+// LocalBuildScript returns the shell script which builds the chaincode at
+// path with the local Go toolchain. The code package must be extracted to
+// inputDir and the chaincode binary is written to output. The binary is
+// dynamically linked as it runs on the host that built it.
+func (p *Platform) LocalBuildScript(path, inputDir, output string) string {
+	return fmt.Sprintf(buildScript, dynamicLDFlagsOpts, path, inputDir, output)
+}
+
 // CodeDescriptor describes the code we're packaging.
 type CodeDescriptor struct {
 	Source       string // absolute path of the source to package
@@ -302,9 +302,20 @@ func TestModuleDeploymentPayload(t *testing.T) {
 func setupGopath(t *testing.T, path string) func() {
 	initialGopath, gopathSet := os.LookupEnv("GOPATH")
 	initialGo111Module, go111ModuleSet := os.LookupEnv("GO111MODULE")
+	initialGomodcache, gomodcacheSet := os.LookupEnv("GOMODCACHE")
+	initialGoflags, goflagsSet := os.LookupEnv("GOFLAGS")
+
+	// keep the module cache out of the GOPATH so that the tests don't
+	// write it to testdata
+	modcache, err := ioutil.TempDir("", "gomodcache")
+	require.NoError(t, err)
+	err = os.Setenv("GOMODCACHE", modcache)
+	require.NoError(t, err, "failed to set GOMODCACHE")
+	err = os.Setenv("GOFLAGS", "-modcacherw")
+	require.NoError(t, err, "failed to set GOFLAGS")
 
 	if path == "" {
-		err := os.Unsetenv("GOPATH")
+		err = os.Unsetenv("GOPATH")
 		require.NoError(t, err)
 	} else {
 		absPath, err := filepath.Abs(path)
@@ -326,6 +337,17 @@ func setupGopath(t *testing.T, path string) func() {
 		} else {
 			os.Setenv("GO111MODULE", initialGo111Module)
 		}
+		if !gomodcacheSet {
+			os.Unsetenv("GOMODCACHE")
+		} else {
+			os.Setenv("GOMODCACHE", initialGomodcache)
+		}
+		if !goflagsSet {
+			os.Unsetenv("GOFLAGS")
+		} else {
+			os.Setenv("GOFLAGS", initialGoflags)
+		}
+		os.RemoveAll(modcache)
 	}
 }
 
@@ -430,6 +452,31 @@ echo Done!
 	})
 }
 
+func TestLocalBuildScript(t *testing.T) {
+	platform := &Platform{}
+
+	script := platform.LocalBuildScript("the-path", "/var/build/input", "/var/build/output/chaincode")
+	assert.Equal(t, `
+set -e
+if [ -f "/var/build/input/src/go.mod" ] && [ -d "/var/build/input/src/vendor" ]; then
+    cd /var/build/input/src
+    GO111MODULE=on go build -v -mod=vendor  -o /var/build/output/chaincode the-path
+elif [ -f "/var/build/input/src/go.mod" ]; then
+    cd /var/build/input/src
+    GO111MODULE=on go build -v -mod=readonly  -o /var/build/output/chaincode the-path
+elif [ -f "/var/build/input/src/the-path/go.mod" ] && [ -d "/var/build/input/src/the-path/vendor" ]; then
+    cd /var/build/input/src/the-path
+    GO111MODULE=on go build -v -mod=vendor  -o /var/build/output/chaincode .
+elif [ -f "/var/build/input/src/the-path/go.mod" ]; then
+    cd /var/build/input/src/the-path
+    GO111MODULE=on go build -v -mod=readonly  -o /var/build/output/chaincode .
+else
+    GOPATH=/var/build/input:$GOPATH go build -v  -o /var/build/output/chaincode the-path
+fi
+echo Done!
+`, script)
+}
+
 func TestDescribeCode(t *testing.T) {
 	abs, err := filepath.Abs(filepath.FromSlash("testdata/ccmodule"))
 	assert.NoError(t, err)
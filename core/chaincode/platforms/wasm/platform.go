@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/platforms/util"
+	"github.com/hyperledger/fabric/internal/ccmetadata"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
+	"github.com/pkg/errors"
+)
+
+var logger = flogging.MustGetLogger("chaincode.platform.wasm")
+
+// Name is the chaincode type of WebAssembly chaincode.
+const Name = "WASM"
+
+// ModulePath is the path of the WebAssembly module in the code package.
+const ModulePath = "src/chaincode.wasm"
+
+// Platform for chaincodes compiled to WebAssembly modules
+type Platform struct{}
+
+// Name returns the name of this platform
+func (p *Platform) Name() string {
+	return Name
+}
+
+// ValidatePath validates that the path refers to a module
+func (p *Platform) ValidatePath(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return errors.Errorf("path to chaincode does not exist: %s", path)
+	}
+	if fi.IsDir() {
+		return errors.Errorf("path to chaincode must be a wasm module: %s", path)
+	}
+	return nil
+}
+
+// ValidateCodePackage checks that the code package only contains the
+// module, which must be valid, and metadata.
+func (p *Platform) ValidateCodePackage(code []byte) error {
+	gr, err := gzip.NewReader(bytes.NewReader(code))
+	if err != nil {
+		return errors.Errorf("failure opening codepackage gzip stream: %s", err)
+	}
+	tr := tar.NewReader(gr)
+
+	var foundModule bool
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Errorf("failure reading codepackage: %s", err)
+		}
+
+		if header.Name != ModulePath && !strings.HasPrefix(header.Name, "META-INF/") {
+			return errors.Errorf("illegal file detected in payload: \"%s\"", header.Name)
+		}
+		if header.Mode&^0100666 != 0 {
+			return errors.Errorf("illegal file mode detected for file %s: %o", header.Name, header.Mode)
+		}
+
+		if header.Name == ModulePath {
+			module, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return errors.Errorf("failure reading %s: %s", ModulePath, err)
+			}
+			if _, err := wasm.Compile(module); err != nil {
+				return err
+			}
+			foundModule = true
+		}
+	}
+	if !foundModule {
+		return errors.Errorf("no %s found in the chaincode package", ModulePath)
+	}
+
+	return nil
+}
+
+// GetDeploymentPayload writes the module at path to src/chaincode.wasm in
+// .tar.gz format. The META-INF directory next to the module, if any, is
+// included as metadata.
+func (p *Platform) GetDeploymentPayload(path string) ([]byte, error) {
+	module, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Errorf("could not read chaincode module: %s", err)
+	}
+	if _, err := wasm.Compile(module); err != nil {
+		return nil, err
+	}
+
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+
+	logger.Debugf("Packaging wasm module %s", path)
+
+	if err := util.WriteFileToPackage(path, ModulePath, tw); err != nil {
+		return nil, errors.Errorf("Error writing Chaincode package contents: %s", err)
+	}
+	if err := writeMetadata(tw, filepath.Join(filepath.Dir(path), "META-INF")); err != nil {
+		return nil, errors.Errorf("Error writing Chaincode package contents: %s", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, errors.Errorf("Error writing Chaincode package contents: %s", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, errors.Errorf("Error writing Chaincode package contents: %s", err)
+	}
+
+	return payload.Bytes(), nil
+}
+
+// writeMetadata writes the files of the META-INF directory to the package.
+func writeMetadata(tw *tar.Writer, dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(dir, func(localpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// hidden files are not supported as metadata
+		if strings.HasPrefix(info.Name(), ".") {
+			logger.Warningf("Ignoring hidden file in metadata directory: %s", localpath)
+			return nil
+		}
+
+		relpath, err := filepath.Rel(filepath.Dir(dir), localpath)
+		if err != nil {
+			return err
+		}
+		packagepath := filepath.ToSlash(relpath)
+
+		fileBytes, err := ioutil.ReadFile(localpath)
+		if err != nil {
+			return err
+		}
+		if err := ccmetadata.ValidateMetadataFile(packagepath, fileBytes); err != nil {
+			return err
+		}
+
+		return util.WriteFileToPackage(localpath, packagepath, tw)
+	})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var platform = &Platform{}
+
+// emptyModule is the smallest valid module.
+var emptyModule = []byte("\x00asm\x01\x00\x00\x00")
+
+type packageFile struct {
+	name     string
+	mode     int64
+	contents []byte
+}
+
+func makeCodePackage(t *testing.T, files ...packageFile) []byte {
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: f.mode, Size: int64(len(f.contents))})
+		require.NoError(t, err)
+		_, err = tw.Write(f.contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return payload.Bytes()
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "WASM", platform.Name())
+}
+
+func TestValidatePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wasm-platform")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cc.wasm"), emptyModule, 0600))
+
+	assert.NoError(t, platform.ValidatePath(filepath.Join(dir, "cc.wasm")))
+	assert.EqualError(t, platform.ValidatePath(filepath.Join(dir, "missing.wasm")), "path to chaincode does not exist: "+filepath.Join(dir, "missing.wasm"))
+	assert.EqualError(t, platform.ValidatePath(dir), "path to chaincode must be a wasm module: "+dir)
+}
+
+func TestValidateCodePackage(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   []byte
+		errMsg string
+	}{
+		{
+			name: "valid",
+			code: makeCodePackage(t,
+				packageFile{ModulePath, 0100644, emptyModule},
+				packageFile{"META-INF/statedb/couchdb/indexes/index.json", 0100644, []byte("{}")},
+			),
+		},
+		{
+			name:   "not gzipped",
+			code:   []byte("dummy CodePackage content"),
+			errMsg: "failure opening codepackage gzip stream: gzip: invalid header",
+		},
+		{
+			name:   "illegal file",
+			code:   makeCodePackage(t, packageFile{"src/other.wasm", 0100644, emptyModule}),
+			errMsg: "illegal file detected in payload: \"src/other.wasm\"",
+		},
+		{
+			name:   "illegal mode",
+			code:   makeCodePackage(t, packageFile{ModulePath, 0100755, emptyModule}),
+			errMsg: "illegal file mode detected for file src/chaincode.wasm: 100755",
+		},
+		{
+			name:   "invalid module",
+			code:   makeCodePackage(t, packageFile{ModulePath, 0100644, []byte("not a module")}),
+			errMsg: "invalid wasm module: missing magic number",
+		},
+		{
+			name:   "missing module",
+			code:   makeCodePackage(t, packageFile{"META-INF/statedb/couchdb/indexes/index.json", 0100644, []byte("{}")}),
+			errMsg: "no src/chaincode.wasm found in the chaincode package",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := platform.ValidateCodePackage(tt.code)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentPayload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wasm-platform")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	modulePath := filepath.Join(dir, "cc.wasm")
+	require.NoError(t, ioutil.WriteFile(modulePath, emptyModule, 0600))
+	indexDir := filepath.Join(dir, "META-INF", "statedb", "couchdb", "indexes")
+	require.NoError(t, os.MkdirAll(indexDir, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(indexDir, "index.json"), []byte(`{"index":{"fields":["owner"]},"name":"owner","type":"json"}`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(indexDir, ".hidden"), []byte("x"), 0600))
+
+	payload, err := platform.GetDeploymentPayload(modulePath)
+	require.NoError(t, err)
+	assert.NoError(t, platform.ValidateCodePackage(payload))
+
+	gr, err := gzip.NewReader(bytes.NewReader(payload))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"src/chaincode.wasm", "META-INF/statedb/couchdb/indexes/index.json"}, names)
+
+	t.Run("invalid module", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(modulePath, []byte("not a module"), 0600))
+		_, err := platform.GetDeploymentPayload(modulePath)
+		assert.EqualError(t, err, "invalid wasm module: missing magic number")
+	})
+
+	t.Run("missing module", func(t *testing.T) {
+		_, err := platform.GetDeploymentPayload(filepath.Join(dir, "missing.wasm"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "could not read chaincode module")
+	})
+}
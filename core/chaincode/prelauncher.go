@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	ccdef "github.com/hyperledger/fabric/common/chaincode"
+	extucc "github.com/hyperledger/fabric/extensions/chaincode"
+	"github.com/pkg/errors"
+)
+
+// Prelauncher launches the chaincodes deployed on a channel with the legacy
+// lifecycle and installed on the peer ahead of their first invocation. The
+// chaincodes of the current lifecycle are launched by the lifecycle as soon
+// as their definition is committed.
+type Prelauncher struct {
+	Launcher     SupervisedLauncher
+	Lifecycle    Lifecycle
+	LedgerGetter LedgerGetter
+}
+
+// HandleMetadataUpdate launches the given chaincodes of the channel in the
+// background. It is called when the peer starts, when it joins the channel
+// and when a chaincode is deployed on the channel.
+func (p *Prelauncher) HandleMetadataUpdate(channelID string, chaincodes ccdef.MetadataSet) {
+	for _, cc := range chaincodes {
+		go p.launch(channelID, cc.Name)
+	}
+}
+
+func (p *Prelauncher) launch(channelID, chaincodeName string) {
+	ccid, err := p.chaincodeID(channelID, chaincodeName)
+	if err != nil {
+		chaincodeLogger.Warningf("could not pre-launch chaincode %s on channel %s: %s", chaincodeName, channelID, err)
+		return
+	}
+
+	if _, ok := extucc.GetUCCByPackageID(ccid); ok {
+		// in-process chaincodes are launched with the peer
+		return
+	}
+
+	chaincodeLogger.Infof("pre-launching chaincode %s deployed on channel %s", ccid, channelID)
+	if err := p.Launcher.Launch(ccid); err != nil {
+		chaincodeLogger.Warningf("could not pre-launch chaincode %s on channel %s: %s", ccid, channelID, err)
+	}
+}
+
+// chaincodeID returns the ID of the chaincode which is invoked for the chaincode
+// name, so that a chaincode upgraded to the current lifecycle isn't launched in
+// its legacy version
+func (p *Prelauncher) chaincodeID(channelID, chaincodeName string) (string, error) {
+	l := p.LedgerGetter.GetLedger(channelID)
+	if l == nil {
+		return "", errors.Errorf("channel %s not found", channelID)
+	}
+
+	qe, err := l.NewQueryExecutor()
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to create query executor")
+	}
+	defer qe.Done()
+
+	info, err := p.Lifecycle.ChaincodeEndorsementInfo(channelID, chaincodeName, qe)
+	if err != nil {
+		return "", err
+	}
+	return info.ChaincodeID, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	ccdef "github.com/hyperledger/fabric/common/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/fake"
+	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
+	"github.com/hyperledger/fabric/core/chaincode/mock"
+	"github.com/hyperledger/fabric/core/ledger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Prelauncher", func() {
+	var (
+		fakeLauncher      *fake.SupervisedLauncher
+		fakeLifecycle     *mock.Lifecycle
+		fakeLedgerGetter  *mock.LedgerGetter
+		fakePeerLedger    *mock.PeerLedger
+		fakeQueryExecutor *mock.TxSimulator
+
+		prelauncher *chaincode.Prelauncher
+	)
+
+	BeforeEach(func() {
+		fakeLauncher = &fake.SupervisedLauncher{}
+		fakeLifecycle = &mock.Lifecycle{}
+		fakeLifecycle.ChaincodeEndorsementInfoStub = func(channelID, name string, _ ledger.SimpleQueryExecutor) (*lifecycle.ChaincodeEndorsementInfo, error) {
+			return &lifecycle.ChaincodeEndorsementInfo{ChaincodeID: name + ":1.0"}, nil
+		}
+		fakeQueryExecutor = &mock.TxSimulator{}
+		fakePeerLedger = &mock.PeerLedger{}
+		fakePeerLedger.NewQueryExecutorReturns(fakeQueryExecutor, nil)
+		fakeLedgerGetter = &mock.LedgerGetter{}
+		fakeLedgerGetter.GetLedgerReturns(fakePeerLedger)
+
+		prelauncher = &chaincode.Prelauncher{
+			Launcher:     fakeLauncher,
+			Lifecycle:    fakeLifecycle,
+			LedgerGetter: fakeLedgerGetter,
+		}
+	})
+
+	launched := func() []string {
+		var ccids []string
+		for i := 0; i < fakeLauncher.LaunchCallCount(); i++ {
+			ccids = append(ccids, fakeLauncher.LaunchArgsForCall(i))
+		}
+		return ccids
+	}
+
+	It("launches the chaincodes deployed on the channel", func() {
+		prelauncher.HandleMetadataUpdate("channel-id", ccdef.MetadataSet{
+			{Name: "cc1", Version: "1.0"},
+			{Name: "cc2", Version: "1.0"},
+		})
+
+		Eventually(launched).Should(ConsistOf("cc1:1.0", "cc2:1.0"))
+		Expect(fakeLedgerGetter.GetLedgerArgsForCall(0)).To(Equal("channel-id"))
+		Eventually(fakeQueryExecutor.DoneCallCount).Should(Equal(2))
+	})
+
+	It("launches the chaincode which is invoked for the name of the chaincode", func() {
+		fakeLifecycle.ChaincodeEndorsementInfoStub = nil
+		fakeLifecycle.ChaincodeEndorsementInfoReturns(&lifecycle.ChaincodeEndorsementInfo{ChaincodeID: "cc1_2:hash"}, nil)
+
+		prelauncher.HandleMetadataUpdate("channel-id", ccdef.MetadataSet{{Name: "cc1", Version: "1.0"}})
+
+		Eventually(launched).Should(ConsistOf("cc1_2:hash"))
+	})
+
+	It("does not launch chaincodes which cannot be resolved", func() {
+		fakeLifecycle.ChaincodeEndorsementInfoStub = nil
+		fakeLifecycle.ChaincodeEndorsementInfoReturns(nil, errors.New("mango"))
+		prelauncher.HandleMetadataUpdate("channel-id", ccdef.MetadataSet{{Name: "cc1", Version: "1.0"}})
+		Eventually(fakeLifecycle.ChaincodeEndorsementInfoCallCount).Should(Equal(1))
+
+		fakeLedgerGetter.GetLedgerReturns(nil)
+		prelauncher.HandleMetadataUpdate("missing", ccdef.MetadataSet{{Name: "cc1", Version: "1.0"}})
+		Eventually(fakeLedgerGetter.GetLedgerCallCount).Should(Equal(2))
+
+		Consistently(fakeLauncher.LaunchCallCount).Should(Equal(0))
+	})
+})
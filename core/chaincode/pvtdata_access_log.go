@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// recordPrivateDataAccess records in the access log that the keys of the
+// collection were returned to the chaincode on behalf of the creator of
+// the transaction.
+func (h *Handler) recordPrivateDataAccess(txContext *TransactionContext, txID, collection string, keys ...string) error {
+	if h.AccessLog == nil || len(keys) == 0 || !h.AccessLog.Enabled(txContext.NamespaceID, collection) {
+		return nil
+	}
+
+	mspID, certHash, err := creatorOf(txContext)
+	if err != nil {
+		return err
+	}
+	entries := make([]*pvtdataaccesslog.Entry, len(keys))
+	for i, key := range keys {
+		entries[i] = &pvtdataaccesslog.Entry{
+			TxID:       txID,
+			Chaincode:  txContext.NamespaceID,
+			Collection: collection,
+			Key:        key,
+			MSPID:      mspID,
+			CertHash:   certHash,
+		}
+	}
+	return h.AccessLog.Record(txContext.ChannelID, entries...)
+}
+
+// creatorOf returns the MSP ID and the hex encoded hash of the certificate
+// of the creator of the transaction.
+func creatorOf(txContext *TransactionContext) (string, string, error) {
+	if txContext.Proposal == nil {
+		return "", "", nil
+	}
+	header, err := protoutil.UnmarshalHeader(txContext.Proposal.Header)
+	if err != nil {
+		return "", "", errors.WithMessage(err, "failed to get the creator of the transaction")
+	}
+	signatureHeader, err := protoutil.UnmarshalSignatureHeader(header.SignatureHeader)
+	if err != nil {
+		return "", "", errors.WithMessage(err, "failed to get the creator of the transaction")
+	}
+	creator, err := protoutil.UnmarshalSerializedIdentity(signatureHeader.Creator)
+	if err != nil {
+		return "", "", errors.WithMessage(err, "failed to get the creator of the transaction")
+	}
+	certHash := sha256.Sum256(creator.IdBytes)
+	return creator.Mspid, hex.EncodeToString(certHash[:]), nil
+}
+
+// accessLoggingIterator returns an iterator which records in the access log
+// the keys of the collection it returns, if the accesses to the collection
+// are recorded.
+func (h *Handler) accessLoggingIterator(txContext *TransactionContext, txID, collection string, iter commonledger.ResultsIterator) commonledger.ResultsIterator {
+	if iter == nil || h.AccessLog == nil || !h.AccessLog.Enabled(txContext.NamespaceID, collection) {
+		return iter
+	}
+	return &accessLoggingIterator{
+		ResultsIterator: iter,
+		record: func(key string) error {
+			return h.recordPrivateDataAccess(txContext, txID, collection, key)
+		},
+	}
+}
+
+type accessLoggingIterator struct {
+	commonledger.ResultsIterator
+	record func(key string) error
+}
+
+func (i *accessLoggingIterator) Next() (commonledger.QueryResult, error) {
+	result, err := i.ResultsIterator.Next()
+	if err != nil || result == nil {
+		return result, err
+	}
+	if kv, ok := result.(*queryresult.KV); ok {
+		if err := i.record(kv.Key); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,284 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+)
+
+// Quotas which are enforced, as reported in errors and metrics.
+const (
+	QuotaConcurrency   = "concurrency"
+	QuotaExecutionTime = "execution_time"
+	QuotaKeysRead      = "keys_read"
+	QuotaKeysWritten   = "keys_written"
+	QuotaWriteSetSize  = "write_set_size"
+	QuotaQueryResults  = "query_results"
+)
+
+// QuotaLimits are the limits applied to a chaincode. Zero values mean no
+// limit.
+type QuotaLimits struct {
+	// MaxConcurrency limits the number of invocations of the chaincode which
+	// execute concurrently on a channel. Further invocations are rejected.
+	MaxConcurrency int
+	// MaxExecutionTime limits the execution time of an invocation. The
+	// execute timeout of the peer applies if it is lower.
+	MaxExecutionTime time.Duration
+	// MaxKeysRead limits the number of keys read by an invocation, excluding
+	// the results of queries.
+	MaxKeysRead int
+	// MaxKeysWritten limits the number of distinct keys written, deleted or
+	// purged by an invocation. Writing a key again doesn't count.
+	MaxKeysWritten int
+	// MaxWriteSetSize limits the bytes of keys, values and metadata in the
+	// write set of an invocation. Only the last write to a key counts.
+	MaxWriteSetSize int
+	// MaxQueryResults limits the number of results returned by the queries
+	// of an invocation.
+	MaxQueryResults int
+}
+
+// inherit returns the limits with the limits not set taken from parent.
+func (l QuotaLimits) inherit(parent QuotaLimits) QuotaLimits {
+	if l.MaxConcurrency == 0 {
+		l.MaxConcurrency = parent.MaxConcurrency
+	}
+	if l.MaxExecutionTime == 0 {
+		l.MaxExecutionTime = parent.MaxExecutionTime
+	}
+	if l.MaxKeysRead == 0 {
+		l.MaxKeysRead = parent.MaxKeysRead
+	}
+	if l.MaxKeysWritten == 0 {
+		l.MaxKeysWritten = parent.MaxKeysWritten
+	}
+	if l.MaxWriteSetSize == 0 {
+		l.MaxWriteSetSize = parent.MaxWriteSetSize
+	}
+	if l.MaxQueryResults == 0 {
+		l.MaxQueryResults = parent.MaxQueryResults
+	}
+	return l
+}
+
+// ChaincodeQuota overrides limits for a chaincode, for the chaincodes of a
+// channel, or for a chaincode on a channel. An empty name or channel
+// matches all chaincodes or channels.
+type ChaincodeQuota struct {
+	Name    string
+	Channel string
+	QuotaLimits
+}
+
+// specificity orders quotas so that quotas for a chaincode override quotas
+// for a channel.
+func (q ChaincodeQuota) specificity() int {
+	s := 0
+	if q.Name != "" {
+		s += 2
+	}
+	if q.Channel != "" {
+		s++
+	}
+	return s
+}
+
+func (q ChaincodeQuota) matches(channelID, chaincodeName string) bool {
+	return (q.Name == "" || q.Name == chaincodeName) && (q.Channel == "" || q.Channel == channelID)
+}
+
+// QuotaConfig configures the limits of chaincodes.
+type QuotaConfig struct {
+	// Default applies to all chaincodes.
+	Default QuotaLimits
+	// Chaincodes override the default. Limits not set are inherited from
+	// less specific quotas.
+	Chaincodes []ChaincodeQuota
+}
+
+// Limits returns the limits which apply to a chaincode on a channel.
+func (c QuotaConfig) Limits(channelID, chaincodeName string) QuotaLimits {
+	limits := c.Default
+	for s := 1; s <= 3; s++ {
+		for _, q := range c.Chaincodes {
+			if q.specificity() == s && q.matches(channelID, chaincodeName) {
+				limits = q.QuotaLimits.inherit(limits)
+			}
+		}
+	}
+	return limits
+}
+
+// QuotaExceededError is returned when an invocation is rejected because a
+// chaincode exceeded a quota.
+type QuotaExceededError struct {
+	ChannelID     string
+	ChaincodeName string
+	Quota         string
+	Limit         interface{}
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("chaincode %s on channel %s exceeded its %s quota of %v", e.ChaincodeName, e.ChannelID, e.Quota, e.Limit)
+}
+
+type quotaKey struct {
+	channelID     string
+	chaincodeName string
+}
+
+// QuotaManager provides the limits of chaincodes and tracks their
+// concurrent invocations. A nil QuotaManager enforces no limits.
+type QuotaManager struct {
+	config QuotaConfig
+
+	mutex   sync.Mutex
+	running map[quotaKey]int
+}
+
+// NewQuotaManager creates a QuotaManager enforcing the configured limits.
+func NewQuotaManager(config QuotaConfig) *QuotaManager {
+	return &QuotaManager{
+		config:  config,
+		running: map[quotaKey]int{},
+	}
+}
+
+// Limits returns the limits which apply to a chaincode on a channel.
+func (q *QuotaManager) Limits(channelID, chaincodeName string) QuotaLimits {
+	if q == nil {
+		return QuotaLimits{}
+	}
+	return q.config.Limits(channelID, chaincodeName)
+}
+
+// Acquire reserves one of the concurrent invocations allowed for the
+// chaincode. The returned function releases the invocation.
+func (q *QuotaManager) Acquire(channelID, chaincodeName string, limits QuotaLimits) (release func(), err error) {
+	if q == nil || limits.MaxConcurrency <= 0 {
+		return func() {}, nil
+	}
+
+	key := quotaKey{channelID: channelID, chaincodeName: chaincodeName}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.running[key] >= limits.MaxConcurrency {
+		return nil, QuotaExceededError{ChannelID: channelID, ChaincodeName: chaincodeName, Quota: QuotaConcurrency, Limit: limits.MaxConcurrency}
+	}
+	q.running[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+			q.running[key]--
+			if q.running[key] == 0 {
+				delete(q.running, key)
+			}
+		})
+	}, nil
+}
+
+// writeKind is the kind of an entry of the write set of an invocation
+type writeKind string
+
+const (
+	valueWrite    writeKind = "value"
+	metadataWrite writeKind = "metadata"
+	purgeWrite    writeKind = "purge"
+)
+
+// transactionQuota tracks the resources consumed by an invocation. A nil
+// transactionQuota enforces no limits.
+type transactionQuota struct {
+	channelID     string
+	chaincodeName string
+	limits        QuotaLimits
+	rejections    metrics.Counter
+
+	mutex        sync.Mutex
+	keysRead     int
+	keysWritten  map[string]struct{}
+	writeSizes   map[string]int
+	writeSetSize int
+	queryResults int
+}
+
+func newTransactionQuota(channelID, chaincodeName string, limits QuotaLimits, rejections metrics.Counter) *transactionQuota {
+	return &transactionQuota{
+		channelID:     channelID,
+		chaincodeName: chaincodeName,
+		limits:        limits,
+		rejections:    rejections,
+	}
+}
+
+func (t *transactionQuota) exceeded(quota string, limit int) error {
+	t.rejections.With("channel", t.channelID, "chaincode", t.chaincodeName, "quota", quota).Add(1)
+	return QuotaExceededError{ChannelID: t.channelID, ChaincodeName: t.chaincodeName, Quota: quota, Limit: limit}
+}
+
+// read records keys read by the invocation.
+func (t *transactionQuota) read(keys int) error {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.keysRead += keys
+	if t.limits.MaxKeysRead > 0 && t.keysRead > t.limits.MaxKeysRead {
+		return t.exceeded(QuotaKeysRead, t.limits.MaxKeysRead)
+	}
+	return nil
+}
+
+// write records a key of a collection written by the invocation and the
+// bytes written. The public state is the collection "". A write replaces
+// the previous write of the same kind to the key in the write set, so
+// only the size of the last one is counted.
+func (t *transactionQuota) write(kind writeKind, collection, key string, size int) error {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.keysWritten == nil {
+		t.keysWritten = map[string]struct{}{}
+		t.writeSizes = map[string]int{}
+	}
+	t.keysWritten[collection+"\x00"+key] = struct{}{}
+	entry := string(kind) + "\x00" + collection + "\x00" + key
+	t.writeSetSize += size - t.writeSizes[entry]
+	t.writeSizes[entry] = size
+	if t.limits.MaxKeysWritten > 0 && len(t.keysWritten) > t.limits.MaxKeysWritten {
+		return t.exceeded(QuotaKeysWritten, t.limits.MaxKeysWritten)
+	}
+	if t.limits.MaxWriteSetSize > 0 && t.writeSetSize > t.limits.MaxWriteSetSize {
+		return t.exceeded(QuotaWriteSetSize, t.limits.MaxWriteSetSize)
+	}
+	return nil
+}
+
+// results records results returned by the queries of the invocation.
+func (t *transactionQuota) results(count int) error {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.queryResults += count
+	if t.limits.MaxQueryResults > 0 && t.queryResults > t.limits.MaxQueryResults {
+		return t.exceeded(QuotaQueryResults, t.limits.MaxQueryResults)
+	}
+	return nil
+}
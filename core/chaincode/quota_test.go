@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quotas", func() {
+	Describe("QuotaConfig", func() {
+		var config chaincode.QuotaConfig
+
+		BeforeEach(func() {
+			config = chaincode.QuotaConfig{
+				Default: chaincode.QuotaLimits{
+					MaxConcurrency:   100,
+					MaxExecutionTime: time.Minute,
+					MaxKeysRead:      1000,
+				},
+				Chaincodes: []chaincode.ChaincodeQuota{
+					{Name: "mycc", Channel: "mychannel", QuotaLimits: chaincode.QuotaLimits{MaxConcurrency: 1}},
+					{Name: "mycc", QuotaLimits: chaincode.QuotaLimits{MaxConcurrency: 10, MaxKeysWritten: 5}},
+					{Channel: "mychannel", QuotaLimits: chaincode.QuotaLimits{MaxConcurrency: 50, MaxExecutionTime: time.Second}},
+				},
+			}
+		})
+
+		It("applies the default to other chaincodes", func() {
+			Expect(config.Limits("otherchannel", "othercc")).To(Equal(config.Default))
+		})
+
+		It("applies the quotas of a channel", func() {
+			Expect(config.Limits("mychannel", "othercc")).To(Equal(chaincode.QuotaLimits{
+				MaxConcurrency:   50,
+				MaxExecutionTime: time.Second,
+				MaxKeysRead:      1000,
+			}))
+		})
+
+		It("applies the quotas of a chaincode", func() {
+			Expect(config.Limits("otherchannel", "mycc")).To(Equal(chaincode.QuotaLimits{
+				MaxConcurrency:   10,
+				MaxExecutionTime: time.Minute,
+				MaxKeysRead:      1000,
+				MaxKeysWritten:   5,
+			}))
+		})
+
+		It("inherits the limits not set from less specific quotas", func() {
+			Expect(config.Limits("mychannel", "mycc")).To(Equal(chaincode.QuotaLimits{
+				MaxConcurrency:   1,
+				MaxExecutionTime: time.Second,
+				MaxKeysRead:      1000,
+				MaxKeysWritten:   5,
+			}))
+		})
+	})
+
+	Describe("QuotaManager", func() {
+		var quotaManager *chaincode.QuotaManager
+
+		BeforeEach(func() {
+			quotaManager = chaincode.NewQuotaManager(chaincode.QuotaConfig{
+				Default: chaincode.QuotaLimits{MaxConcurrency: 2},
+			})
+		})
+
+		It("limits the concurrent invocations of each chaincode on each channel", func() {
+			limits := quotaManager.Limits("channel", "cc")
+			release1, err := quotaManager.Acquire("channel", "cc", limits)
+			Expect(err).NotTo(HaveOccurred())
+			release2, err := quotaManager.Acquire("channel", "cc", limits)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = quotaManager.Acquire("channel", "cc", limits)
+			Expect(err).To(MatchError("chaincode cc on channel channel exceeded its concurrency quota of 2"))
+			Expect(err).To(BeAssignableToTypeOf(chaincode.QuotaExceededError{}))
+
+			_, err = quotaManager.Acquire("channel", "othercc", limits)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = quotaManager.Acquire("otherchannel", "cc", limits)
+			Expect(err).NotTo(HaveOccurred())
+
+			release1()
+			release1()
+			_, err = quotaManager.Acquire("channel", "cc", limits)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = quotaManager.Acquire("channel", "cc", limits)
+			Expect(err).To(HaveOccurred())
+
+			release2()
+			_, err = quotaManager.Acquire("channel", "cc", limits)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the quota manager is nil", func() {
+			BeforeEach(func() {
+				quotaManager = nil
+			})
+
+			It("enforces no limits", func() {
+				limits := quotaManager.Limits("channel", "cc")
+				Expect(limits).To(Equal(chaincode.QuotaLimits{}))
+
+				release, err := quotaManager.Acquire("channel", "cc", chaincode.QuotaLimits{MaxConcurrency: 1})
+				Expect(err).NotTo(HaveOccurred())
+				release()
+			})
+		})
+	})
+})
@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recording
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/pkg/errors"
+)
+
+// FileExtension is the extension of the files holding recordings.
+const FileExtension = ".rec"
+
+// Recorder writes the recordings of endorsements to a directory, in a
+// sub-directory per channel and a file per transaction.
+type Recorder struct {
+	Path string
+}
+
+// NewTransaction starts the recording of the endorsement of a transaction.
+func (r *Recorder) NewTransaction(channelID, txID string) *Transaction {
+	return &Transaction{
+		recording: &msgs.TransactionRecording{
+			ChannelId:  channelID,
+			TxId:       txID,
+			RecordedAt: ptypes.TimestampNow(),
+		},
+	}
+}
+
+// Write writes the recording of a transaction along with the marshaled
+// rwset.TxReadWriteSet produced by its simulation and returns the path of the
+// file.
+func (r *Recorder) Write(tx *Transaction, results []byte) (string, error) {
+	recording := tx.recordingWithResults(results)
+
+	dir := filepath.Join(r.Path, recording.ChannelId)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "failed to create recording directory %s", dir)
+	}
+
+	b, err := proto.Marshal(recording)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal recording")
+	}
+
+	// the file is written under a temporary name first so that readers
+	// never see a partial recording
+	path := filepath.Join(dir, recording.TxId+FileExtension)
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return "", errors.Wrapf(err, "failed to write recording %s", path)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrapf(err, "failed to write recording %s", path)
+	}
+
+	return path, nil
+}
+
+// ReadFile reads a recording written by a Recorder.
+func ReadFile(path string) (*msgs.TransactionRecording, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read recording")
+	}
+	recording := &msgs.TransactionRecording{}
+	if err := proto.Unmarshal(b, recording); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal recording %s", path)
+	}
+	return recording, nil
+}
+
+// Transaction collects the chaincode executions of the endorsement of a
+// transaction. It is safe for concurrent use.
+type Transaction struct {
+	mutex     sync.Mutex
+	recording *msgs.TransactionRecording
+}
+
+// StartExecution records the start of the execution of a chaincode with the
+// message sent to it.
+func (t *Transaction) StartExecution(chaincodeName string, input *pb.ChaincodeMessage) *Execution {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	execution := &msgs.ExecutionRecording{
+		ChaincodeName: chaincodeName,
+		Input:         marshal(input),
+	}
+	t.recording.Executions = append(t.recording.Executions, execution)
+
+	return &Execution{transaction: t, execution: execution}
+}
+
+func (t *Transaction) recordingWithResults(results []byte) *msgs.TransactionRecording {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	recording := proto.Clone(t.recording).(*msgs.TransactionRecording)
+	recording.Results = results
+	return recording
+}
+
+// Execution collects the messages exchanged with a chaincode during an
+// execution.
+type Execution struct {
+	transaction *Transaction
+	execution   *msgs.ExecutionRecording
+}
+
+// Exchange records a message sent by the chaincode and the response of the
+// peer.
+func (e *Execution) Exchange(request, response *pb.ChaincodeMessage) {
+	e.transaction.mutex.Lock()
+	defer e.transaction.mutex.Unlock()
+
+	e.execution.Exchanges = append(e.execution.Exchanges, &msgs.ChaincodeExchange{
+		Request:  marshal(request),
+		Response: marshal(response),
+	})
+}
+
+// Finish records the message which completed the execution.
+func (e *Execution) Finish(result *pb.ChaincodeMessage) {
+	e.transaction.mutex.Lock()
+	defer e.transaction.mutex.Unlock()
+
+	e.execution.Result = marshal(result)
+}
+
+// marshal marshals a chaincode message, which cannot fail for messages that
+// were sent or received over the chaincode stream.
+func marshal(msg *pb.ChaincodeMessage) []byte {
+	if msg == nil {
+		return nil
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		logger.Warningf("failed to marshal %s message: %s", msgs.TypeName(msg.Type), err)
+	}
+	return b
+}
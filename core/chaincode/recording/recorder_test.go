@@ -0,0 +1,107 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recording
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	recorder := &Recorder{Path: filepath.Join(dir, "recordings")}
+	tx := recorder.NewTransaction("mychannel", "txid")
+
+	input := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "txid", ChannelId: "mychannel", Payload: []byte("input")}
+	execution := tx.StartExecution("mycc", input)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			execution.Exchange(
+				&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Payload: []byte("key")},
+				&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: []byte("value")},
+			)
+		}()
+	}
+	wg.Wait()
+	nested := tx.StartExecution("othercc", &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION})
+	nested.Finish(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte("failed")})
+	execution.Finish(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Payload: []byte("result")})
+
+	path, err := recorder.Write(tx, []byte("results"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "recordings", "mychannel", "txid.rec"), path)
+
+	rec, err := ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "mychannel", rec.ChannelId)
+	require.Equal(t, "txid", rec.TxId)
+	require.NotNil(t, rec.RecordedAt)
+	require.Equal(t, []byte("results"), rec.Results)
+	require.Len(t, rec.Executions, 2)
+
+	require.Equal(t, "mycc", rec.Executions[0].ChaincodeName)
+	recordedInput := &pb.ChaincodeMessage{}
+	require.NoError(t, proto.Unmarshal(rec.Executions[0].Input, recordedInput))
+	require.True(t, proto.Equal(input, recordedInput))
+	require.Len(t, rec.Executions[0].Exchanges, 10)
+	response := &pb.ChaincodeMessage{}
+	require.NoError(t, proto.Unmarshal(rec.Executions[0].Exchanges[0].Response, response))
+	require.Equal(t, []byte("value"), response.Payload)
+	result := &pb.ChaincodeMessage{}
+	require.NoError(t, proto.Unmarshal(rec.Executions[0].Result, result))
+	require.Equal(t, pb.ChaincodeMessage_COMPLETED, result.Type)
+
+	require.Equal(t, "othercc", rec.Executions[1].ChaincodeName)
+	require.Empty(t, rec.Executions[1].Exchanges)
+
+	_, err = os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestRecorderWriteFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+
+	recorder := &Recorder{Path: file}
+	_, err = recorder.Write(recorder.NewTransaction("mychannel", "txid"), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create recording directory")
+}
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = ReadFile(filepath.Join(dir, "missing.rec"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read recording")
+
+	garbage := filepath.Join(dir, "garbage.rec")
+	require.NoError(t, ioutil.WriteFile(garbage, []byte("garbage"), 0600))
+	_, err = ReadFile(garbage)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal recording "+garbage)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recording
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/pkg/errors"
+)
+
+var logger = flogging.MustGetLogger("chaincode.recording")
+
+// Divergence is a message sent by a chaincode during a replay which does not
+// match the recording.
+type Divergence struct {
+	// Index is the index of the exchange in the recording
+	Index int
+	// Expected is the recorded message, nil if the chaincode sent more
+	// messages than recorded
+	Expected *pb.ChaincodeMessage
+	// Actual is the message sent by the chaincode
+	Actual *pb.ChaincodeMessage
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	// Exchanges is the number of messages sent by the chaincode which matched
+	// the recording and were answered with the recorded response
+	Exchanges int
+	// Divergences are the messages sent by the chaincode which did not match
+	// the recording
+	Divergences []Divergence
+	// Missing is the number of recorded messages the chaincode did not send
+	Missing int
+	// Expected is the recorded message which completed the execution
+	Expected *pb.ChaincodeMessage
+	// Result is the message which completed the replayed execution
+	Result *pb.ChaincodeMessage
+}
+
+// Diverged returns true if the chaincode did not behave as recorded.
+func (r *Report) Diverged() bool {
+	return len(r.Divergences) != 0 || r.Missing != 0 || !proto.Equal(r.Expected, r.Result)
+}
+
+// Replay re-executes a recorded execution against the chaincode connected to
+// the stream. The chaincode registers like it does with a peer, receives the
+// recorded input and is answered the recorded responses, as long as it sends
+// the messages which were recorded.
+func Replay(stream ccintf.ChaincodeStream, execution *msgs.ExecutionRecording) (*Report, error) {
+	input, err := unmarshal(execution.Input)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid recorded input")
+	}
+	report := &Report{}
+	if len(execution.Result) != 0 {
+		if report.Expected, err = unmarshal(execution.Result); err != nil {
+			return nil, errors.WithMessage(err, "invalid recorded result")
+		}
+	}
+
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to receive registration")
+	}
+	if msg.Type != pb.ChaincodeMessage_REGISTER {
+		return nil, errors.Errorf("expected %s message, received %s", pb.ChaincodeMessage_REGISTER, msgs.TypeName(msg.Type))
+	}
+	for _, t := range []pb.ChaincodeMessage_Type{pb.ChaincodeMessage_REGISTERED, pb.ChaincodeMessage_READY} {
+		if err := stream.Send(&pb.ChaincodeMessage{Type: t}); err != nil {
+			return nil, errors.Wrapf(err, "failed to send %s", t)
+		}
+	}
+	if err := stream.Send(input); err != nil {
+		return nil, errors.Wrapf(err, "failed to send %s", input.Type)
+	}
+
+	next := 0
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return report, errors.Wrap(err, "failed to receive message from chaincode")
+		}
+
+		switch msg.Type {
+		case pb.ChaincodeMessage_KEEPALIVE:
+			continue
+		case pb.ChaincodeMessage_COMPLETED, pb.ChaincodeMessage_ERROR:
+			if msg.Txid == input.Txid {
+				report.Result = msg
+				if next < len(execution.Exchanges) {
+					report.Missing = len(execution.Exchanges) - next
+				}
+				return report, nil
+			}
+		}
+
+		resp, err := report.answer(msg, execution.Exchanges, next)
+		if err != nil {
+			return report, err
+		}
+		next++
+		if err := stream.Send(resp); err != nil {
+			return report, errors.Wrapf(err, "failed to send %s", resp.Type)
+		}
+	}
+}
+
+// answer returns the recorded response to a message sent by the chaincode
+// if it matches the recorded exchange, and an error response otherwise.
+func (r *Report) answer(msg *pb.ChaincodeMessage, exchanges []*msgs.ChaincodeExchange, index int) (*pb.ChaincodeMessage, error) {
+	divergence := Divergence{Index: index, Actual: msg}
+	if index < len(exchanges) {
+		expected, err := unmarshal(exchanges[index].Request)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid recorded request %d", index)
+		}
+		if proto.Equal(expected, msg) {
+			resp, err := unmarshal(exchanges[index].Response)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "invalid recorded response %d", index)
+			}
+			r.Exchanges++
+			return resp, nil
+		}
+		divergence.Expected = expected
+	}
+
+	r.Divergences = append(r.Divergences, divergence)
+	return &pb.ChaincodeMessage{
+		Type:      pb.ChaincodeMessage_ERROR,
+		Payload:   []byte(fmt.Sprintf("replayed %s message %d does not match the recording", msgs.TypeName(msg.Type), index)),
+		Txid:      msg.Txid,
+		ChannelId: msg.ChannelId,
+	}, nil
+}
+
+func unmarshal(b []byte) (*pb.ChaincodeMessage, error) {
+	msg := &pb.ChaincodeMessage{}
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal chaincode message")
+	}
+	return msg, nil
+}
+
+// Server replays a recorded execution against the first chaincode which
+// registers with it, like chaincodes register with a peer in development
+// mode.
+type Server struct {
+	execution *msgs.ExecutionRecording
+	started   int32
+	done      chan struct{}
+	report    *Report
+	err       error
+}
+
+// NewServer creates a server which replays the execution.
+func NewServer(execution *msgs.ExecutionRecording) *Server {
+	return &Server{
+		execution: execution,
+		done:      make(chan struct{}),
+	}
+}
+
+// Register replays the execution against the chaincode registering on the
+// stream. Only one chaincode is served.
+func (s *Server) Register(stream pb.ChaincodeSupport_RegisterServer) error {
+	if !atomic.CompareAndSwapInt32(&s.started, 0, 1) {
+		return errors.New("the recording has already been replayed")
+	}
+	defer close(s.done)
+	s.report, s.err = Replay(stream, s.execution)
+	return s.err
+}
+
+// Report waits for the replay to complete and returns its report.
+func (s *Server) Report(ctx context.Context) (*Report, error) {
+	select {
+	case <-s.done:
+		return s.report, s.err
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "replay did not complete")
+	}
+}
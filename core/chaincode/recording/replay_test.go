@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recording
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// scriptedChaincode is a chaincode stream which sends a fixed sequence of
+// messages and collects the messages it receives.
+type scriptedChaincode struct {
+	messages []*pb.ChaincodeMessage
+	received []*pb.ChaincodeMessage
+}
+
+func (s *scriptedChaincode) Send(msg *pb.ChaincodeMessage) error {
+	s.received = append(s.received, msg)
+	return nil
+}
+
+func (s *scriptedChaincode) Recv() (*pb.ChaincodeMessage, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
+
+func getState(key string) *pb.ChaincodeMessage {
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Txid: "txid", ChannelId: "mychannel", Payload: []byte(key)}
+}
+
+func response(value string) *pb.ChaincodeMessage {
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: "txid", ChannelId: "mychannel", Payload: []byte(value)}
+}
+
+func completed(result string) *pb.ChaincodeMessage {
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "txid", ChannelId: "mychannel", Payload: []byte(result)}
+}
+
+func newExecution() *msgs.ExecutionRecording {
+	tx := (&Recorder{}).NewTransaction("mychannel", "txid")
+	execution := tx.StartExecution("mycc", &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "txid", ChannelId: "mychannel", Payload: []byte("input")})
+	execution.Exchange(getState("key1"), response("value1"))
+	execution.Exchange(getState("key2"), response("value2"))
+	execution.Finish(completed("result"))
+	return tx.recording.Executions[0]
+}
+
+func TestReplay(t *testing.T) {
+	register := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER}
+
+	t.Run("matching execution", func(t *testing.T) {
+		cc := &scriptedChaincode{messages: []*pb.ChaincodeMessage{
+			register,
+			getState("key1"),
+			{Type: pb.ChaincodeMessage_KEEPALIVE},
+			getState("key2"),
+			completed("result"),
+		}}
+		report, err := Replay(cc, newExecution())
+		require.NoError(t, err)
+		require.False(t, report.Diverged())
+		require.Equal(t, 2, report.Exchanges)
+		require.Empty(t, report.Divergences)
+		require.Zero(t, report.Missing)
+		require.True(t, proto.Equal(completed("result"), report.Result))
+
+		require.Len(t, cc.received, 5)
+		require.Equal(t, pb.ChaincodeMessage_REGISTERED, cc.received[0].Type)
+		require.Equal(t, pb.ChaincodeMessage_READY, cc.received[1].Type)
+		require.Equal(t, pb.ChaincodeMessage_TRANSACTION, cc.received[2].Type)
+		require.Equal(t, []byte("input"), cc.received[2].Payload)
+		require.True(t, proto.Equal(response("value1"), cc.received[3]))
+		require.True(t, proto.Equal(response("value2"), cc.received[4]))
+	})
+
+	t.Run("diverging execution", func(t *testing.T) {
+		cc := &scriptedChaincode{messages: []*pb.ChaincodeMessage{
+			register,
+			getState("key1"),
+			getState("key3"),
+			getState("key4"),
+			completed("other-result"),
+		}}
+		report, err := Replay(cc, newExecution())
+		require.NoError(t, err)
+		require.True(t, report.Diverged())
+		require.Equal(t, 1, report.Exchanges)
+		require.Len(t, report.Divergences, 2)
+		require.Equal(t, 1, report.Divergences[0].Index)
+		require.True(t, proto.Equal(getState("key2"), report.Divergences[0].Expected))
+		require.True(t, proto.Equal(getState("key3"), report.Divergences[0].Actual))
+		require.Equal(t, 2, report.Divergences[1].Index)
+		require.Nil(t, report.Divergences[1].Expected)
+		require.True(t, proto.Equal(completed("result"), report.Expected))
+		require.True(t, proto.Equal(completed("other-result"), report.Result))
+
+		require.Equal(t, pb.ChaincodeMessage_ERROR, cc.received[4].Type)
+		require.Equal(t, "replayed GET_STATE message 1 does not match the recording", string(cc.received[4].Payload))
+		require.Equal(t, "txid", cc.received[4].Txid)
+	})
+
+	t.Run("execution completing early", func(t *testing.T) {
+		cc := &scriptedChaincode{messages: []*pb.ChaincodeMessage{
+			register,
+			getState("key1"),
+			completed("result"),
+		}}
+		report, err := Replay(cc, newExecution())
+		require.NoError(t, err)
+		require.True(t, report.Diverged())
+		require.Equal(t, 1, report.Missing)
+	})
+
+	t.Run("chaincode disconnecting", func(t *testing.T) {
+		cc := &scriptedChaincode{messages: []*pb.ChaincodeMessage{register, getState("key1")}}
+		report, err := Replay(cc, newExecution())
+		require.EqualError(t, err, "failed to receive message from chaincode: EOF")
+		require.Equal(t, 1, report.Exchanges)
+	})
+
+	t.Run("no registration", func(t *testing.T) {
+		cc := &scriptedChaincode{messages: []*pb.ChaincodeMessage{getState("key1")}}
+		_, err := Replay(cc, newExecution())
+		require.EqualError(t, err, "expected REGISTER message, received GET_STATE")
+	})
+
+	t.Run("invalid recording", func(t *testing.T) {
+		execution := newExecution()
+		execution.Exchanges[0].Response = []byte("garbage")
+		cc := &scriptedChaincode{messages: []*pb.ChaincodeMessage{register, getState("key1")}}
+		_, err := Replay(cc, execution)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid recorded response 0")
+
+		execution.Input = []byte("garbage")
+		_, err = Replay(&scriptedChaincode{}, execution)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid recorded input")
+	})
+}
+
+func TestServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	server := NewServer(newExecution())
+	pb.RegisterChaincodeSupportServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	chat := func(messages ...*pb.ChaincodeMessage) error {
+		stream, err := pb.NewChaincodeSupportClient(conn).Register(ctx)
+		if err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	err = chat(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER}, getState("key1"), getState("key2"), completed("result"))
+	require.NoError(t, err)
+
+	report, err := server.Report(ctx)
+	require.NoError(t, err)
+	require.False(t, report.Diverged())
+
+	err = chat(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the recording has already been replayed")
+
+	canceled, cancelReport := context.WithCancel(context.Background())
+	cancelReport()
+	_, err = NewServer(newExecution()).Report(canceled)
+	require.True(t, errors.Cause(err) == context.Canceled)
+}
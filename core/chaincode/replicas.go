@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric/core/container/ccintf"
+)
+
+// replicaSet holds the handlers of a chaincode served by several chaincode
+// servers. It is protected by the mutex of the HandlerRegistry.
+type replicaSet struct {
+	loadBalancing string
+	handlers      []*Handler
+	last          int
+}
+
+func (s *replicaSet) add(h *Handler) {
+	s.handlers = append(s.handlers, h)
+}
+
+// remove removes the handler and reports whether it was found.
+func (s *replicaSet) remove(h *Handler) bool {
+	for i, handler := range s.handlers {
+		if handler == h {
+			s.handlers = append(s.handlers[:i], s.handlers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// next selects the handler which receives the next transaction.
+func (s *replicaSet) next() *Handler {
+	if s.loadBalancing == ccintf.LeastOutstanding {
+		return s.leastOutstanding()
+	}
+	s.last = (s.last + 1) % len(s.handlers)
+	return s.handlers[s.last]
+}
+
+// leastOutstanding returns the handler with the fewest transactions in
+// progress, starting the search after the previously selected handler so
+// that idle handlers are used in turn.
+func (s *replicaSet) leastOutstanding() *Handler {
+	selected := -1
+	for i := 1; i <= len(s.handlers); i++ {
+		candidate := (s.last + i) % len(s.handlers)
+		if selected == -1 || s.handlers[candidate].Outstanding() < s.handlers[selected].Outstanding() {
+			selected = candidate
+		}
+	}
+	s.last = selected
+	return s.handlers[selected]
+}
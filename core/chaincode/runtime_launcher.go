@@ -19,6 +19,7 @@ import (
 // LaunchRegistry tracks launching chaincode instances.
 type LaunchRegistry interface {
 	Launching(ccid string) (launchState *LaunchState, started bool)
+	Replicated(ccid, loadBalancing string)
 	Deregister(ccid string) error
 }
 
@@ -27,6 +28,17 @@ type ConnectionHandler interface {
 	Stream(ccid string, ccinfo *ccintf.ChaincodeServerInfo, sHandler extcc.StreamHandler) error
 }
 
+// LaunchObserver is notified of the life cycle of the chaincode runtimes
+// started by the RuntimeLauncher.
+type LaunchObserver interface {
+	// Launched is called when the chaincode has been launched.
+	Launched(ccid string)
+	// Exited is called when the runtime of the chaincode has terminated.
+	Exited(ccid string, err error)
+	// Stopping is called before the chaincode runtime is stopped.
+	Stopping(ccid string)
+}
+
 // RuntimeLauncher is responsible for launching chaincode runtimes.
 type RuntimeLauncher struct {
 	Runtime           Runtime
@@ -37,6 +49,7 @@ type RuntimeLauncher struct {
 	CACert            []byte
 	CertGenerator     CertGenerator
 	ConnectionHandler ConnectionHandler
+	Observer          LaunchObserver
 }
 
 // CertGenerator generates client certificates for chaincode.
@@ -87,12 +100,17 @@ func (r *RuntimeLauncher) Launch(ccid string, streamHandler extcc.StreamHandler)
 
 			// chaincode server model indicated... proceed to connect to CC
 			if ccservinfo != nil {
+				if len(ccservinfo.ServerAddresses()) > 1 {
+					r.Registry.Replicated(ccid, ccservinfo.LoadBalancing)
+				}
 				if err = r.ConnectionHandler.Stream(ccid, ccservinfo, streamHandler); err != nil {
 					startFailCh <- errors.WithMessagef(err, "connection to %s failed", ccid)
 					return
 				}
 
-				launchState.Notify(errors.Errorf("connection to %s terminated", ccid))
+				err = errors.Errorf("connection to %s terminated", ccid)
+				launchState.Notify(err)
+				r.exited(ccid, err)
 				return
 			}
 
@@ -113,9 +131,12 @@ func (r *RuntimeLauncher) Launch(ccid string, streamHandler extcc.StreamHandler)
 			}
 			exitCode, err := r.Runtime.Wait(ccid)
 			if err != nil {
-				launchState.Notify(errors.Wrap(err, "failed to wait on container exit"))
+				err = errors.Wrap(err, "failed to wait on container exit")
+			} else {
+				err = errors.Errorf("container exited with %d", exitCode)
 			}
-			launchState.Notify(errors.Errorf("container exited with %d", exitCode))
+			launchState.Notify(err)
+			r.exited(ccid, err)
 		}()
 	}
 
@@ -144,11 +165,25 @@ func (r *RuntimeLauncher) Launch(ccid string, streamHandler extcc.StreamHandler)
 		"success", strconv.FormatBool(success),
 	).Observe(time.Since(startTime).Seconds())
 
+	if success && !alreadyStarted && r.Observer != nil {
+		r.Observer.Launched(ccid)
+	}
+
 	chaincodeLogger.Debug("launch complete")
 	return err
 }
 
+func (r *RuntimeLauncher) exited(ccid string, err error) {
+	if r.Observer != nil {
+		r.Observer.Exited(ccid, err)
+	}
+}
+
 func (r *RuntimeLauncher) Stop(ccid string) error {
+	if r.Observer != nil {
+		r.Observer.Stopping(ccid)
+	}
+
 	err := r.Runtime.Stop(ccid)
 	if err != nil {
 		return errors.WithMessagef(err, "failed to stop chaincode %s", ccid)
@@ -209,6 +209,36 @@ var _ = Describe("RuntimeLauncher", func() {
 		BeforeEach(func() {
 			fakeRuntime.BuildReturns(&ccintf.ChaincodeServerInfo{Address: "peer-address"}, nil)
 		})
+		It("does not mark the chaincode as replicated", func() {
+			err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeRegistry.ReplicatedCallCount()).To(Equal(0))
+		})
+
+		Context("when the chaincode is served by several servers", func() {
+			BeforeEach(func() {
+				fakeRuntime.BuildReturns(&ccintf.ChaincodeServerInfo{
+					Address:       "server-1",
+					Addresses:     []string{"server-1", "server-2"},
+					LoadBalancing: ccintf.LeastOutstanding,
+				}, nil)
+			})
+
+			It("marks the chaincode as replicated before connecting", func() {
+				fakeConnHandler.StreamStub = func(string, *ccintf.ChaincodeServerInfo, extcc.StreamHandler) error {
+					Expect(fakeRegistry.ReplicatedCallCount()).To(Equal(1))
+					launchState.Notify(nil)
+					return nil
+				}
+
+				err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+				Expect(err).NotTo(HaveOccurred())
+				ccid, loadBalancing := fakeRegistry.ReplicatedArgsForCall(0)
+				Expect(ccid).To(Equal("chaincode-name:chaincode-version"))
+				Expect(loadBalancing).To(Equal(ccintf.LeastOutstanding))
+			})
+		})
+
 		It("registers the chaincode as launching", func() {
 			err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
 			Expect(err).NotTo(HaveOccurred())
@@ -475,4 +505,84 @@ var _ = Describe("RuntimeLauncher", func() {
 			Expect(err).To(MatchError("failed to stop chaincode chaincode-name:chaincode-version: liver-mush"))
 		})
 	})
+	Context("when an observer is set", func() {
+		var fakeObserver *fake.LaunchObserver
+
+		BeforeEach(func() {
+			fakeObserver = &fake.LaunchObserver{}
+			runtimeLauncher.Observer = fakeObserver
+		})
+
+		It("notifies the observer when the chaincode is launched", func() {
+			err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeObserver.LaunchedCallCount()).To(Equal(1))
+			Expect(fakeObserver.LaunchedArgsForCall(0)).To(Equal("chaincode-name:chaincode-version"))
+		})
+
+		It("notifies the observer when the container exits", func() {
+			err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeObserver.ExitedCallCount()).To(Equal(0))
+
+			exitedCh <- 2
+			Eventually(fakeObserver.ExitedCallCount).Should(Equal(1))
+			ccid, exitErr := fakeObserver.ExitedArgsForCall(0)
+			Expect(ccid).To(Equal("chaincode-name:chaincode-version"))
+			Expect(exitErr).To(MatchError("container exited with 2"))
+		})
+
+		It("notifies the observer before the runtime is stopped", func() {
+			fakeRuntime.StopStub = func(string) error {
+				Expect(fakeObserver.StoppingCallCount()).To(Equal(1))
+				return nil
+			}
+
+			err := runtimeLauncher.Stop("chaincode-name:chaincode-version")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeObserver.StoppingArgsForCall(0)).To(Equal("chaincode-name:chaincode-version"))
+		})
+
+		Context("when the launch fails", func() {
+			BeforeEach(func() {
+				fakeRuntime.StartReturns(errors.New("banana"))
+			})
+
+			It("does not notify the observer of the launch", func() {
+				err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+				Expect(err).To(HaveOccurred())
+				Expect(fakeObserver.LaunchedCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the registry indicates the chaincode has already been started", func() {
+			BeforeEach(func() {
+				fakeRegistry.LaunchingReturns(launchState, true)
+				launchState.Notify(nil)
+			})
+
+			It("does not notify the observer", func() {
+				err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeObserver.LaunchedCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the connection to the external chaincode terminates", func() {
+			BeforeEach(func() {
+				fakeRuntime.BuildReturns(&ccintf.ChaincodeServerInfo{Address: "peer-address"}, nil)
+			})
+
+			It("notifies the observer", func() {
+				err := runtimeLauncher.Launch("chaincode-name:chaincode-version", fakeStreamHandler)
+				Expect(err).NotTo(HaveOccurred())
+
+				extCCConnExited <- struct{}{}
+				Eventually(fakeObserver.ExitedCallCount).Should(Equal(1))
+				_, exitErr := fakeObserver.ExitedArgsForCall(0)
+				Expect(exitErr).To(MatchError("connection to chaincode-name:chaincode-version terminated"))
+			})
+		})
+	})
 })
@@ -0,0 +1,294 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ChaincodeStatus is the status of a chaincode watched by the Supervisor.
+type ChaincodeStatus string
+
+const (
+	// ChaincodeRunning indicates that the chaincode is registered with the peer.
+	ChaincodeRunning ChaincodeStatus = "running"
+	// ChaincodeRestarting indicates that the chaincode has exited or failed to
+	// launch and is waiting to be launched again.
+	ChaincodeRestarting ChaincodeStatus = "restarting"
+	// ChaincodeFailed indicates that the chaincode could not be launched
+	// after the maximum number of restarts and is no longer restarted.
+	ChaincodeFailed ChaincodeStatus = "failed"
+)
+
+// statusValues are the values of the chaincode status gauge
+var statusValues = map[ChaincodeStatus]float64{
+	ChaincodeRunning:    0,
+	ChaincodeRestarting: 1,
+	ChaincodeFailed:     2,
+}
+
+// stoppedStatusValue is the value of the chaincode status gauge
+// once the chaincode is no longer supervised
+const stoppedStatusValue = -1
+
+// SupervisedChaincode describes a chaincode watched by the Supervisor.
+type SupervisedChaincode struct {
+	ChaincodeID  string          `json:"chaincode_id"`
+	Status       ChaincodeStatus `json:"status"`
+	Restarts     int             `json:"restarts"`
+	LastError    string          `json:"last_error,omitempty"`
+	LastExitTime *time.Time      `json:"last_exit_time,omitempty"`
+}
+
+// SupervisedLauncher launches chaincode on behalf of the Supervisor.
+type SupervisedLauncher interface {
+	Launch(ccid string) error
+}
+
+// Supervisor watches the chaincode runtimes started by the RuntimeLauncher
+// and launches them again with exponential backoff when they exit without
+// being stopped by the peer.
+type Supervisor struct {
+	// Launcher is used to restart chaincode.
+	Launcher SupervisedLauncher
+	// InitialBackoff is the delay before the first restart of a chaincode.
+	// The delay doubles with every consecutive failed restart.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between restarts. A chaincode which
+	// has been running for longer than MaxBackoff when it exits is
+	// restarted after InitialBackoff again.
+	MaxBackoff time.Duration
+	// MaxRestarts is the number of consecutive failed restarts after which
+	// a chaincode is marked as failed. Zero means no limit.
+	MaxRestarts int
+	// Metrics holds the supervisor metrics.
+	Metrics *SupervisorMetrics
+
+	mutex      sync.Mutex
+	chaincodes map[string]*supervisedChaincode
+}
+
+type supervisedChaincode struct {
+	SupervisedChaincode
+	failures     int
+	runningSince time.Time
+	timer        *time.Timer
+}
+
+// Launch launches the chaincode and supervises it. If the launch fails,
+// it is retried with backoff and the error is returned.
+func (s *Supervisor) Launch(ccid string) error {
+	err := s.Launcher.Launch(ccid)
+	if err == nil {
+		s.Launched(ccid)
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cc := s.chaincode(ccid)
+	if cc.Status == ChaincodeRestarting || cc.Status == ChaincodeFailed {
+		return err
+	}
+	s.setLastError(cc, err)
+	s.scheduleRestart(cc)
+
+	return err
+}
+
+// Launched is called when the chaincode has been launched.
+func (s *Supervisor) Launched(ccid string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cc := s.chaincode(ccid)
+	if cc.timer != nil {
+		cc.timer.Stop()
+		cc.timer = nil
+	}
+	cc.runningSince = time.Now()
+	s.setStatus(cc, ChaincodeRunning)
+}
+
+// Exited is called when the runtime of the chaincode has terminated. The
+// chaincode is restarted unless it is being stopped.
+func (s *Supervisor) Exited(ccid string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cc, ok := s.chaincodes[ccid]
+	if !ok || cc.Status != ChaincodeRunning {
+		return
+	}
+
+	chaincodeLogger.Warningf("chaincode %s exited unexpectedly: %s", ccid, err)
+	s.setLastError(cc, err)
+	if time.Since(cc.runningSince) >= s.MaxBackoff {
+		cc.failures = 0
+	}
+	s.scheduleRestart(cc)
+}
+
+// Stopping is called before the chaincode is stopped by the peer. The
+// chaincode is no longer supervised.
+func (s *Supervisor) Stopping(ccid string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cc, ok := s.chaincodes[ccid]
+	if !ok {
+		return
+	}
+	if cc.timer != nil {
+		cc.timer.Stop()
+	}
+	delete(s.chaincodes, ccid)
+	s.Metrics.Status.With("chaincode", ccid).Set(stoppedStatusValue)
+}
+
+// Chaincodes returns the supervised chaincodes ordered by chaincode ID.
+func (s *Supervisor) Chaincodes() []SupervisedChaincode {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var chaincodes []SupervisedChaincode
+	for _, cc := range s.chaincodes {
+		chaincodes = append(chaincodes, cc.SupervisedChaincode)
+	}
+	sort.Slice(chaincodes, func(i, j int) bool {
+		return chaincodes[i].ChaincodeID < chaincodes[j].ChaincodeID
+	})
+	return chaincodes
+}
+
+// HealthCheck fails if any chaincode could not be restarted.
+func (s *Supervisor) HealthCheck(context.Context) error {
+	var failed []string
+	for _, cc := range s.Chaincodes() {
+		if cc.Status == ChaincodeFailed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", cc.ChaincodeID, cc.LastError))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("chaincodes failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// ServeHTTP serves the supervised chaincodes as JSON.
+func (s *Supervisor) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chaincodes := s.Chaincodes()
+	if chaincodes == nil {
+		chaincodes = []SupervisedChaincode{}
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(chaincodes); err != nil {
+		chaincodeLogger.Errorf("failed to encode supervised chaincodes: %s", err)
+	}
+}
+
+// chaincode returns the supervised chaincode, adding it if needed. It must
+// be called while holding the mutex.
+func (s *Supervisor) chaincode(ccid string) *supervisedChaincode {
+	if s.chaincodes == nil {
+		s.chaincodes = map[string]*supervisedChaincode{}
+	}
+	cc, ok := s.chaincodes[ccid]
+	if !ok {
+		cc = &supervisedChaincode{SupervisedChaincode: SupervisedChaincode{ChaincodeID: ccid}}
+		s.chaincodes[ccid] = cc
+	}
+	return cc
+}
+
+// scheduleRestart must be called while holding the mutex.
+func (s *Supervisor) scheduleRestart(cc *supervisedChaincode) {
+	if s.MaxRestarts > 0 && cc.failures >= s.MaxRestarts {
+		chaincodeLogger.Errorf("chaincode %s failed %d consecutive restarts and will not be restarted: %s", cc.ChaincodeID, cc.failures, cc.LastError)
+		s.setStatus(cc, ChaincodeFailed)
+		return
+	}
+
+	backoff := s.backoff(cc.failures)
+	cc.failures++
+	s.setStatus(cc, ChaincodeRestarting)
+
+	ccid := cc.ChaincodeID
+	chaincodeLogger.Infof("restarting chaincode %s in %s", ccid, backoff)
+	cc.timer = time.AfterFunc(backoff, func() { s.restart(ccid) })
+}
+
+func (s *Supervisor) restart(ccid string) {
+	s.mutex.Lock()
+	cc, ok := s.chaincodes[ccid]
+	if !ok || cc.Status != ChaincodeRestarting {
+		s.mutex.Unlock()
+		return
+	}
+	cc.timer = nil
+	cc.Restarts++
+	s.mutex.Unlock()
+
+	s.Metrics.Restarts.With("chaincode", ccid).Add(1)
+
+	err := s.Launcher.Launch(ccid)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cc, ok = s.chaincodes[ccid]
+	if !ok || cc.Status != ChaincodeRestarting {
+		return
+	}
+	if err != nil {
+		chaincodeLogger.Warningf("could not restart chaincode %s: %s", ccid, err)
+		s.setLastError(cc, err)
+		s.scheduleRestart(cc)
+		return
+	}
+	cc.runningSince = time.Now()
+	s.setStatus(cc, ChaincodeRunning)
+}
+
+func (s *Supervisor) backoff(failures int) time.Duration {
+	backoff := s.InitialBackoff
+	for i := 0; i < failures && backoff < s.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.MaxBackoff {
+		backoff = s.MaxBackoff
+	}
+	return backoff
+}
+
+func (s *Supervisor) setLastError(cc *supervisedChaincode, err error) {
+	now := time.Now()
+	cc.LastExitTime = &now
+	if err != nil {
+		cc.LastError = err.Error()
+	}
+}
+
+func (s *Supervisor) setStatus(cc *supervisedChaincode, status ChaincodeStatus) {
+	cc.Status = status
+	s.Metrics.Status.With("chaincode", cc.ChaincodeID).Set(statusValues[status])
+}
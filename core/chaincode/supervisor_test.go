@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/fake"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Supervisor", func() {
+	var (
+		fakeLauncher *fake.SupervisedLauncher
+		fakeRestarts *metricsfakes.Counter
+		fakeStatus   *metricsfakes.Gauge
+
+		supervisor *chaincode.Supervisor
+	)
+
+	BeforeEach(func() {
+		fakeLauncher = &fake.SupervisedLauncher{}
+		fakeRestarts = &metricsfakes.Counter{}
+		fakeRestarts.WithReturns(fakeRestarts)
+		fakeStatus = &metricsfakes.Gauge{}
+		fakeStatus.WithReturns(fakeStatus)
+
+		supervisor = &chaincode.Supervisor{
+			Launcher:       fakeLauncher,
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     time.Minute,
+			MaxRestarts:    3,
+			Metrics: &chaincode.SupervisorMetrics{
+				Restarts: fakeRestarts,
+				Status:   fakeStatus,
+			},
+		}
+	})
+
+	statusOf := func(ccid string) chaincode.ChaincodeStatus {
+		for _, cc := range supervisor.Chaincodes() {
+			if cc.ChaincodeID == ccid {
+				return cc.Status
+			}
+		}
+		return ""
+	}
+
+	It("tracks launched chaincode as running", func() {
+		supervisor.Launched("cc:1")
+
+		Expect(supervisor.Chaincodes()).To(Equal([]chaincode.SupervisedChaincode{
+			{ChaincodeID: "cc:1", Status: chaincode.ChaincodeRunning},
+		}))
+		Expect(fakeStatus.WithArgsForCall(0)).To(Equal([]string{"chaincode", "cc:1"}))
+		Expect(fakeStatus.SetArgsForCall(0)).To(Equal(0.0))
+	})
+
+	It("restarts chaincode which exits", func() {
+		supervisor.Launched("cc:1")
+		supervisor.Exited("cc:1", errors.New("container exited with 2"))
+
+		Eventually(fakeLauncher.LaunchCallCount).Should(Equal(1))
+		Expect(fakeLauncher.LaunchArgsForCall(0)).To(Equal("cc:1"))
+		Eventually(func() chaincode.ChaincodeStatus { return statusOf("cc:1") }).Should(Equal(chaincode.ChaincodeRunning))
+
+		chaincodes := supervisor.Chaincodes()
+		Expect(chaincodes).To(HaveLen(1))
+		Expect(chaincodes[0].Restarts).To(Equal(1))
+		Expect(chaincodes[0].LastError).To(Equal("container exited with 2"))
+		Expect(chaincodes[0].LastExitTime).NotTo(BeNil())
+
+		Expect(fakeRestarts.WithArgsForCall(0)).To(Equal([]string{"chaincode", "cc:1"}))
+		Expect(fakeRestarts.AddArgsForCall(0)).To(Equal(1.0))
+	})
+
+	It("does not restart chaincode which is being stopped", func() {
+		supervisor.Launched("cc:1")
+		supervisor.Stopping("cc:1")
+		supervisor.Exited("cc:1", errors.New("container exited with 0"))
+
+		Consistently(fakeLauncher.LaunchCallCount).Should(Equal(0))
+		Expect(supervisor.Chaincodes()).To(BeEmpty())
+		Expect(fakeStatus.WithArgsForCall(fakeStatus.WithCallCount() - 1)).To(Equal([]string{"chaincode", "cc:1"}))
+		Expect(fakeStatus.SetArgsForCall(fakeStatus.SetCallCount() - 1)).To(Equal(-1.0))
+	})
+
+	It("ignores chaincode which it has not seen launched", func() {
+		supervisor.Exited("cc:1", errors.New("container exited with 0"))
+
+		Consistently(fakeLauncher.LaunchCallCount).Should(Equal(0))
+		Expect(supervisor.Chaincodes()).To(BeEmpty())
+	})
+
+	Context("when the launch fails", func() {
+		BeforeEach(func() {
+			fakeLauncher.LaunchReturnsOnCall(0, errors.New("fig"))
+		})
+
+		It("returns the error and retries the launch", func() {
+			err := supervisor.Launch("cc:1")
+			Expect(err).To(MatchError("fig"))
+			Expect(statusOf("cc:1")).To(Equal(chaincode.ChaincodeRestarting))
+
+			Eventually(fakeLauncher.LaunchCallCount).Should(Equal(2))
+			Eventually(func() chaincode.ChaincodeStatus { return statusOf("cc:1") }).Should(Equal(chaincode.ChaincodeRunning))
+		})
+	})
+
+	Context("when the restarts keep failing", func() {
+		BeforeEach(func() {
+			fakeLauncher.LaunchReturns(errors.New("mango"))
+		})
+
+		It("marks the chaincode as failed after the maximum number of restarts", func() {
+			supervisor.Launched("cc:1")
+			supervisor.Exited("cc:1", errors.New("container exited with 1"))
+
+			Eventually(func() chaincode.ChaincodeStatus { return statusOf("cc:1") }).Should(Equal(chaincode.ChaincodeFailed))
+			Expect(fakeLauncher.LaunchCallCount()).To(Equal(3))
+			Consistently(fakeLauncher.LaunchCallCount).Should(Equal(3))
+			Expect(fakeStatus.SetArgsForCall(fakeStatus.SetCallCount() - 1)).To(Equal(2.0))
+
+			err := supervisor.HealthCheck(context.Background())
+			Expect(err).To(MatchError("chaincodes failed: cc:1 (mango)"))
+		})
+	})
+
+	It("reports healthy when no chaincode has failed", func() {
+		supervisor.Launched("cc:1")
+		Expect(supervisor.HealthCheck(context.Background())).To(Succeed())
+	})
+
+	Describe("ServeHTTP", func() {
+		It("serves the supervised chaincodes", func() {
+			supervisor.Launched("cc:2")
+			supervisor.Launched("cc:1")
+
+			resp := httptest.NewRecorder()
+			supervisor.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/chaincodes", nil))
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var chaincodes []chaincode.SupervisedChaincode
+			Expect(json.Unmarshal(resp.Body.Bytes(), &chaincodes)).To(Succeed())
+			Expect(chaincodes).To(Equal([]chaincode.SupervisedChaincode{
+				{ChaincodeID: "cc:1", Status: chaincode.ChaincodeRunning},
+				{ChaincodeID: "cc:2", Status: chaincode.ChaincodeRunning},
+			}))
+		})
+
+		It("serves an empty list when no chaincode is supervised", func() {
+			resp := httptest.NewRecorder()
+			supervisor.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/chaincodes", nil))
+			Expect(resp.Body.String()).To(MatchJSON("[]"))
+		})
+
+		It("rejects other methods", func() {
+			resp := httptest.NewRecorder()
+			supervisor.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/chaincodes", nil))
+			Expect(resp.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+})
@@ -11,6 +11,8 @@ import (
 
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger"
 )
@@ -26,6 +28,20 @@ type TransactionContext struct {
 	CollectionStore      privdata.CollectionStore
 	IsInitTransaction    bool
 
+	// CrossChannelInvocations collects the chaincodes invoked with writes
+	// on other channels
+	CrossChannelInvocations *ccprovider.CrossChannelInvocations
+
+	// Recording collects the chaincode executions of the transaction when
+	// endorsements are recorded
+	Recording *recording.Transaction
+
+	// tracks the resources consumed against the quotas of the chaincode
+	quota *transactionQuota
+
+	// records the messages exchanged with the chaincode
+	execution *recording.Execution
+
 	// tracks open iterators used for range queries
 	queryMutex          sync.Mutex
 	queryIteratorMap    map[string]commonledger.ResultsIterator
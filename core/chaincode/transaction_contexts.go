@@ -56,6 +56,9 @@ func (c *TransactionContexts) Create(txParams *ccprovider.TransactionParams) (*T
 		CollectionStore:      txParams.CollectionStore,
 		IsInitTransaction:    txParams.IsInitTransaction,
 
+		CrossChannelInvocations: txParams.CrossChannelInvocations,
+		Recording:               txParams.Recording,
+
 		queryIteratorMap:    map[string]commonledger.ResultsIterator{},
 		pendingQueryResults: map[string]*PendingQueryResult{},
 	}
@@ -14,12 +14,14 @@ import (
 	"github.com/hyperledger/fabric-protos-go/peer"
 	commonerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	validation "github.com/hyperledger/fabric/core/handlers/validation/api"
 	s "github.com/hyperledger/fabric/core/handlers/validation/api/state"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/protoutil"
 	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
 )
 
 // ChannelResources provides access to channel artefacts or
@@ -209,6 +211,7 @@ func (v *dispatcherImpl) Dispatch(seq int, payload *common.Payload, envBytes []b
 		if err = v.invokeValidationPlugin(ctx); err != nil {
 			switch err.(type) {
 			case *commonerrors.VSCCEndorsementPolicyError:
+				logEndorsementPolicyFailure(chdr.TxId, ns, err)
 				return err, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE
 			default:
 				return err, peer.TxValidationCode_INVALID_OTHER_REASON
@@ -220,6 +223,19 @@ func (v *dispatcherImpl) Dispatch(seq int, payload *common.Payload, envBytes []b
 	return nil, peer.TxValidationCode_VALID
 }
 
+// logEndorsementPolicyFailure logs, at debug level, the explanation of
+// the failed policy evaluation carried by err, if any
+func logEndorsementPolicyFailure(txID, namespace string, err error) {
+	if !logger.IsEnabledFor(zapcore.DebugLevel) {
+		return
+	}
+	explanation, ok := policies.ExplanationFromError(err)
+	if !ok {
+		return
+	}
+	logger.Debugf("Endorsement policy evaluation for namespace %s in txId = %s failed:\n%s", namespace, txID, explanation)
+}
+
 func (v *dispatcherImpl) invokeValidationPlugin(ctx *Context) error {
 	logger.Debug("Validating", ctx, "with plugin")
 	err := v.pluginValidator.ValidateWithPlugin(ctx)
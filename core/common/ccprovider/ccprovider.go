@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/golang/protobuf/proto"
@@ -20,6 +21,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/chaincode"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
 	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/pkg/errors"
@@ -415,4 +417,67 @@ type TransactionParams struct {
 
 	// this is additional data passed to the chaincode
 	ProposalDecorations map[string][]byte
+
+	// CrossChannelInvocations collects the chaincodes invoked on other
+	// channels whose writes are endorsed as separate transactions. When it
+	// is nil, the writes of chaincodes invoked on other channels are
+	// discarded.
+	CrossChannelInvocations *CrossChannelInvocations
+
+	// Recording collects the chaincode executions of the transaction when
+	// endorsements are recorded.
+	Recording *recording.Transaction
+}
+
+// CrossChannelInvocation is a chaincode invoked by a transaction on another
+// channel. Its writes are endorsed as a separate transaction on its channel
+// with the same transaction ID.
+type CrossChannelInvocation struct {
+	ChannelID     string
+	ChaincodeName string
+	Input         *pb.ChaincodeInput
+	Response      *pb.Response
+	TXSimulator   ledger.TxSimulator
+}
+
+// CrossChannelInvocations collects the cross-channel invocations of a
+// transaction. At most one chaincode may be invoked with writes on each
+// channel.
+type CrossChannelInvocations struct {
+	// ChannelID is the channel of the transaction making the invocations.
+	ChannelID string
+
+	mutex       sync.Mutex
+	invocations []*CrossChannelInvocation
+}
+
+// Add records an invocation. An error is returned if a chaincode was
+// already invoked on the channel of the invocation.
+func (c *CrossChannelInvocations) Add(invocation *CrossChannelInvocation) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if invocation.ChannelID == c.ChannelID {
+		return errors.Errorf("channel %s is the channel of the transaction", invocation.ChannelID)
+	}
+	for _, inv := range c.invocations {
+		if inv.ChannelID == invocation.ChannelID {
+			return errors.Errorf("chaincode %s was already invoked on channel %s", inv.ChaincodeName, inv.ChannelID)
+		}
+	}
+	c.invocations = append(c.invocations, invocation)
+	return nil
+}
+
+// Invocations returns the recorded invocations in the order they were made.
+func (c *CrossChannelInvocations) Invocations() []*CrossChannelInvocation {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]*CrossChannelInvocation(nil), c.invocations...)
+}
+
+// Done releases the simulators of the recorded invocations.
+func (c *CrossChannelInvocations) Done() {
+	for _, inv := range c.Invocations() {
+		inv.TXSimulator.Done()
+	}
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/common/chaincode"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger/mock"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -186,3 +187,29 @@ func setupDirectoryStructure(t *testing.T) (string, map[string][]byte) {
 
 	return tmp, hashes
 }
+
+func TestCrossChannelInvocations(t *testing.T) {
+	invocations := &ccprovider.CrossChannelInvocations{ChannelID: "token"}
+	assert.Empty(t, invocations.Invocations())
+
+	sim1 := &mock.TxSimulator{}
+	sim2 := &mock.TxSimulator{}
+	err := invocations.Add(&ccprovider.CrossChannelInvocation{ChannelID: "settlement", ChaincodeName: "cc1", TXSimulator: sim1})
+	assert.NoError(t, err)
+	err = invocations.Add(&ccprovider.CrossChannelInvocation{ChannelID: "audit", ChaincodeName: "cc2", TXSimulator: sim2})
+	assert.NoError(t, err)
+
+	err = invocations.Add(&ccprovider.CrossChannelInvocation{ChannelID: "settlement", ChaincodeName: "cc3"})
+	assert.EqualError(t, err, "chaincode cc1 was already invoked on channel settlement")
+	err = invocations.Add(&ccprovider.CrossChannelInvocation{ChannelID: "token", ChaincodeName: "cc3"})
+	assert.EqualError(t, err, "channel token is the channel of the transaction")
+
+	recorded := invocations.Invocations()
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, "settlement", recorded[0].ChannelID)
+	assert.Equal(t, "audit", recorded[1].ChannelID)
+
+	invocations.Done()
+	assert.Equal(t, 1, sim1.DoneCallCount())
+	assert.Equal(t, 1, sim2.DoneCallCount())
+}
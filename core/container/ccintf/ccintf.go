@@ -33,8 +33,32 @@ type TLSConfig struct {
 	RootCert   []byte
 }
 
+// Strategies used to distribute transactions across the servers of a
+// chaincode served by more than one chaincode server.
+const (
+	// RoundRobin sends transactions to each server in turn.
+	RoundRobin = "round_robin"
+	// LeastOutstanding sends transactions to the server with the fewest
+	// transactions in progress.
+	LeastOutstanding = "least_outstanding"
+)
+
 // ChaincodeServerInfo provides chaincode connection information
 type ChaincodeServerInfo struct {
-	Address      string
-	ClientConfig comm.ClientConfig
+	Address string
+	// Addresses lists the chaincode servers serving the chaincode when it is
+	// served by more than one server. When set, Address is its first entry.
+	Addresses []string
+	// LoadBalancing names the strategy used to distribute transactions
+	// across the servers listed in Addresses.
+	LoadBalancing string
+	ClientConfig  comm.ClientConfig
+}
+
+// ServerAddresses returns the addresses of all the chaincode servers.
+func (c *ChaincodeServerInfo) ServerAddresses() []string {
+	if len(c.Addresses) == 0 {
+		return []string{c.Address}
+	}
+	return c.Addresses
 }
@@ -34,6 +34,20 @@ type ExternalBuilder interface {
 	Build(ccid string, metadata []byte, codePackageStream io.Reader) (Instance, error)
 }
 
+//go:generate counterfeiter -o mock/wasm_builder.go --fake-name WasmBuilder . WasmBuilder
+
+// WasmBuilder is what is exposed by the wasmcontroller
+type WasmBuilder interface {
+	Build(ccid string, metadata *persistence.ChaincodePackageMetadata, codePackageStream io.Reader) (Instance, error)
+}
+
+//go:generate counterfeiter -o mock/process_builder.go --fake-name ProcessBuilder . ProcessBuilder
+
+// ProcessBuilder is what is exposed by the processcontroller
+type ProcessBuilder interface {
+	Build(ccid string, metadata *persistence.ChaincodePackageMetadata, codePackageStream io.Reader) (Instance, error)
+}
+
 //go:generate counterfeiter -o mock/instance.go --fake-name Instance . Instance
 
 // Instance represents a built chaincode instance, because of the docker legacy, calling this a
@@ -73,6 +87,8 @@ type PackageProvider interface {
 
 type Router struct {
 	ExternalBuilder ExternalBuilder
+	WasmBuilder     WasmBuilder
+	ProcessBuilder  ProcessBuilder
 	DockerBuilder   DockerBuilder
 	containers      map[string]Instance
 	PackageProvider PackageProvider
@@ -112,6 +128,32 @@ func (r *Router) Build(ccid string) error {
 		}
 	}
 
+	if instance == nil && r.WasmBuilder != nil {
+		metadata, _, codeStream, err := r.PackageProvider.GetChaincodePackage(ccid)
+		if err != nil {
+			return errors.WithMessage(err, "failed to get chaincode package for wasm build")
+		}
+		defer codeStream.Close()
+
+		instance, err = r.WasmBuilder.Build(ccid, metadata, codeStream)
+		if err != nil {
+			return errors.WithMessage(err, "wasm build failed")
+		}
+	}
+
+	if instance == nil && r.ProcessBuilder != nil {
+		metadata, _, codeStream, err := r.PackageProvider.GetChaincodePackage(ccid)
+		if err != nil {
+			return errors.WithMessage(err, "failed to get chaincode package for process build")
+		}
+		defer codeStream.Close()
+
+		instance, err = r.ProcessBuilder.Build(ccid, metadata, codeStream)
+		if err != nil {
+			return errors.WithMessage(err, "process build failed")
+		}
+	}
+
 	if instance == nil {
 		if r.DockerBuilder == nil {
 			return errors.New("no DockerBuilder, cannot build")
@@ -164,6 +164,138 @@ var _ = Describe("Router", func() {
 				Expect(fakeDockerBuilder.BuildCallCount()).To(Equal(1))
 			})
 		})
+
+		Context("when a wasm builder is provided", func() {
+			var (
+				fakeWasmBuilder    *mock.WasmBuilder
+				fakeProcessBuilder *mock.ProcessBuilder
+			)
+
+			BeforeEach(func() {
+				fakeExternalBuilder.BuildReturns(nil, nil)
+				fakeWasmBuilder = &mock.WasmBuilder{}
+				fakeWasmBuilder.BuildReturns(fakeInstance, nil)
+				router.WasmBuilder = fakeWasmBuilder
+				fakeProcessBuilder = &mock.ProcessBuilder{}
+				router.ProcessBuilder = fakeProcessBuilder
+			})
+
+			It("uses the wasm builder when the external builder returns a nil instance", func() {
+				err := router.Build("package-id")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeWasmBuilder.BuildCallCount()).To(Equal(1))
+				ccid, md, codeStream := fakeWasmBuilder.BuildArgsForCall(0)
+				Expect(ccid).To(Equal("package-id"))
+				Expect(md).To(Equal(&persistence.ChaincodePackageMetadata{
+					Type: "package-type",
+					Path: "package-path",
+				}))
+				codePackage, err := ioutil.ReadAll(codeStream)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(codePackage).To(Equal([]byte("code-bytes")))
+				Expect(fakeProcessBuilder.BuildCallCount()).To(Equal(0))
+				Expect(fakeDockerBuilder.BuildCallCount()).To(Equal(0))
+			})
+
+			Context("when the wasm builder returns a nil instance", func() {
+				BeforeEach(func() {
+					fakeWasmBuilder.BuildReturns(nil, nil)
+					fakeProcessBuilder.BuildReturns(fakeInstance, nil)
+				})
+
+				It("falls back to the process builder", func() {
+					err := router.Build("package-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeProcessBuilder.BuildCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the wasm builder returns an error", func() {
+				BeforeEach(func() {
+					fakeWasmBuilder.BuildReturns(nil, errors.New("fake-wasm-error"))
+				})
+
+				It("wraps and returns the error", func() {
+					err := router.Build("package-id")
+					Expect(err).To(MatchError("wasm build failed: fake-wasm-error"))
+				})
+			})
+
+			Context("when the package provider returns an error before calling the wasm builder", func() {
+				BeforeEach(func() {
+					fakePackageProvider.GetChaincodePackageReturnsOnCall(1, nil, nil, nil, errors.New("fake-package-error"))
+				})
+
+				It("wraps and returns the error", func() {
+					err := router.Build("package-id")
+					Expect(err).To(MatchError("failed to get chaincode package for wasm build: fake-package-error"))
+				})
+			})
+		})
+
+		Context("when a process builder is provided", func() {
+			var fakeProcessBuilder *mock.ProcessBuilder
+
+			BeforeEach(func() {
+				fakeExternalBuilder.BuildReturns(nil, nil)
+				fakeProcessBuilder = &mock.ProcessBuilder{}
+				fakeProcessBuilder.BuildReturns(fakeInstance, nil)
+				router.ProcessBuilder = fakeProcessBuilder
+			})
+
+			It("uses the process builder when the external builder returns a nil instance", func() {
+				err := router.Build("package-id")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeProcessBuilder.BuildCallCount()).To(Equal(1))
+				ccid, md, codeStream := fakeProcessBuilder.BuildArgsForCall(0)
+				Expect(ccid).To(Equal("package-id"))
+				Expect(md).To(Equal(&persistence.ChaincodePackageMetadata{
+					Type: "package-type",
+					Path: "package-path",
+				}))
+				codePackage, err := ioutil.ReadAll(codeStream)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(codePackage).To(Equal([]byte("code-bytes")))
+				Expect(fakeDockerBuilder.BuildCallCount()).To(Equal(0))
+			})
+
+			Context("when the process builder returns a nil instance", func() {
+				BeforeEach(func() {
+					fakeProcessBuilder.BuildReturns(nil, nil)
+					fakeDockerBuilder.BuildReturns(fakeInstance, nil)
+				})
+
+				It("falls back to the docker impl", func() {
+					err := router.Build("package-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeDockerBuilder.BuildCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the process builder returns an error", func() {
+				BeforeEach(func() {
+					fakeProcessBuilder.BuildReturns(nil, errors.New("fake-process-error"))
+				})
+
+				It("wraps and returns the error", func() {
+					err := router.Build("package-id")
+					Expect(err).To(MatchError("process build failed: fake-process-error"))
+				})
+			})
+
+			Context("when the package provider returns an error before calling the process builder", func() {
+				BeforeEach(func() {
+					fakePackageProvider.GetChaincodePackageReturnsOnCall(1, nil, nil, nil, errors.New("fake-package-error"))
+				})
+
+				It("wraps and returns the error", func() {
+					err := router.Build("package-id")
+					Expect(err).To(MatchError("failed to get chaincode package for process build: fake-package-error"))
+				})
+			})
+		})
 	})
 
 	Describe("Post-build operations", func() {
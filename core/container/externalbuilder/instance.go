@@ -67,6 +67,8 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 // ChaincodeServerUserData holds "connection.json" information
 type ChaincodeServerUserData struct {
 	Address            string   `json:"address"`
+	Addresses          []string `json:"addresses"`      // additional chaincode server addresses
+	LoadBalancing      string   `json:"load_balancing"` // round_robin (default) or least_outstanding
 	DialTimeout        Duration `json:"dial_timeout"`
 	TLSRequired        bool     `json:"tls_required"`
 	ClientAuthRequired bool     `json:"client_auth_required"`
@@ -77,10 +79,22 @@ type ChaincodeServerUserData struct {
 }
 
 func (c *ChaincodeServerUserData) ChaincodeServerInfo(cryptoDir string) (*ccintf.ChaincodeServerInfo, error) {
-	if c.Address == "" {
-		return nil, errors.New("chaincode address not provided")
+	addresses, err := c.serverAddresses()
+	if err != nil {
+		return nil, err
+	}
+	connInfo := &ccintf.ChaincodeServerInfo{Address: addresses[0]}
+	if len(addresses) > 1 {
+		connInfo.Addresses = addresses
+		switch c.LoadBalancing {
+		case "", ccintf.RoundRobin:
+			connInfo.LoadBalancing = ccintf.RoundRobin
+		case ccintf.LeastOutstanding:
+			connInfo.LoadBalancing = ccintf.LeastOutstanding
+		default:
+			return nil, errors.Errorf("unknown load balancing strategy '%s'", c.LoadBalancing)
+		}
 	}
-	connInfo := &ccintf.ChaincodeServerInfo{Address: c.Address}
 
 	if c.DialTimeout == (Duration{}) {
 		connInfo.ClientConfig.Timeout = DialTimeout
@@ -117,6 +131,24 @@ func (c *ChaincodeServerUserData) ChaincodeServerInfo(cryptoDir string) (*ccintf
 	return connInfo, nil
 }
 
+// serverAddresses returns the address followed by the additional addresses
+// without duplicates.
+func (c *ChaincodeServerUserData) serverAddresses() ([]string, error) {
+	var addresses []string
+	seen := map[string]bool{}
+	for _, address := range append([]string{c.Address}, c.Addresses...) {
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		return nil, errors.New("chaincode address not provided")
+	}
+	return addresses, nil
+}
+
 func (i *Instance) ChaincodeServerReleaseDir() string {
 	return filepath.Join(i.ReleaseDir, CCServerReleaseDir)
 }
@@ -139,6 +139,70 @@ var _ = Describe("Instance", func() {
 			os.RemoveAll(releaseDir)
 		})
 
+		When("chaincode provides several addresses", func() {
+			BeforeEach(func() {
+				ccuserdata.TLSRequired = false
+				ccuserdata.Addresses = []string{"ccaddress:12346", "ccaddress:12345", "ccaddress:12347"}
+			})
+
+			It("returns all addresses with round robin load balancing", func() {
+				ccinfo, err := ccuserdata.ChaincodeServerInfo(releaseDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ccinfo).To(Equal(&ccintf.ChaincodeServerInfo{
+					Address:       "ccaddress:12345",
+					Addresses:     []string{"ccaddress:12345", "ccaddress:12346", "ccaddress:12347"},
+					LoadBalancing: ccintf.RoundRobin,
+					ClientConfig: comm.ClientConfig{
+						Timeout: 10 * time.Second,
+						KaOpts:  comm.DefaultKeepaliveOptions,
+					},
+				}))
+				Expect(ccinfo.ServerAddresses()).To(Equal(ccinfo.Addresses))
+			})
+
+			Context("when only addresses is provided", func() {
+				It("uses the first address as the address", func() {
+					ccuserdata.Address = ""
+
+					ccinfo, err := ccuserdata.ChaincodeServerInfo(releaseDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ccinfo.Address).To(Equal("ccaddress:12346"))
+					Expect(ccinfo.Addresses).To(Equal([]string{"ccaddress:12346", "ccaddress:12345", "ccaddress:12347"}))
+				})
+			})
+
+			Context("when least outstanding load balancing is requested", func() {
+				It("returns the strategy", func() {
+					ccuserdata.LoadBalancing = "least_outstanding"
+
+					ccinfo, err := ccuserdata.ChaincodeServerInfo(releaseDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ccinfo.LoadBalancing).To(Equal(ccintf.LeastOutstanding))
+				})
+			})
+
+			Context("when an unknown load balancing strategy is requested", func() {
+				It("returns an error", func() {
+					ccuserdata.LoadBalancing = "random"
+
+					_, err := ccuserdata.ChaincodeServerInfo(releaseDir)
+					Expect(err).To(MatchError("unknown load balancing strategy 'random'"))
+				})
+			})
+
+			Context("when the additional addresses repeat the address", func() {
+				It("returns a single address", func() {
+					ccuserdata.Addresses = []string{"ccaddress:12345"}
+
+					ccinfo, err := ccuserdata.ChaincodeServerInfo(releaseDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ccinfo.Addresses).To(BeNil())
+					Expect(ccinfo.LoadBalancing).To(BeEmpty())
+					Expect(ccinfo.ServerAddresses()).To(Equal([]string{"ccaddress:12345"}))
+				})
+			})
+		})
+
 		When("chaincode does not provide all info", func() {
 			Context("tls is not provided", func() {
 				It("returns TLS without client auth information", func() {
@@ -0,0 +1,212 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processcontroller
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/core/container/externalbuilder"
+	"github.com/pkg/errors"
+)
+
+// Limits are the resource limits of a chaincode process. They are set
+// before the chaincode binary is executed. Zero values mean no limit. The execution time of each invocation is bounded by the execute
+// timeout of the chaincode support rather than by a process limit, as the
+// chaincode process outlives the invocations.
+type Limits struct {
+	MaxOpenFiles uint64
+	MaxMemory    uint64 // bytes of virtual memory
+}
+
+// TLS files written to the working directory of the chaincode process.
+const (
+	TLSClientKeyPath      = "tls/client.key"
+	TLSClientCertPath     = "tls/client.crt"
+	TLSClientKeyFile      = "tls/client_pem.key"
+	TLSClientCertFile     = "tls/client_pem.crt"
+	TLSClientRootCertFile = "tls/peer.crt"
+)
+
+// Instance is a chaincode binary run as a process.
+type Instance struct {
+	CCID        string
+	Binary      string
+	WorkDir     string
+	Limits      Limits
+	LoggingEnv  []string
+	MSPID       string
+	TermTimeout time.Duration
+
+	mutex   sync.Mutex
+	session *externalbuilder.Session
+}
+
+// ChaincodeServerInfo returns nil as the chaincode connects to the peer.
+func (i *Instance) ChaincodeServerInfo() (*ccintf.ChaincodeServerInfo, error) {
+	return nil, nil
+}
+
+// Start starts the chaincode process in a clean working directory. The
+// output of the process is written to the peer log.
+func (i *Instance) Start(peerConnection *ccintf.PeerConnection) error {
+	if err := os.RemoveAll(i.WorkDir); err != nil {
+		return errors.WithMessage(err, "could not clean working directory")
+	}
+	if err := os.MkdirAll(i.WorkDir, 0700); err != nil {
+		return errors.WithMessage(err, "could not create working directory")
+	}
+
+	env, err := i.env(peerConnection.TLSConfig)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := i.Limits.Command(i.Binary, "-peer.address="+peerConnection.Address)
+	if err != nil {
+		return errors.WithMessagef(err, "could not limit chaincode %s", i.CCID)
+	}
+	cmd.Dir = i.WorkDir
+	cmd.Env = env
+
+	// the pipe is not closed by Wait so the output is copied until the
+	// process and its children have exited
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return errors.WithMessage(err, "could not capture chaincode output")
+	}
+	cmd.Stdout = stdoutWriter
+
+	logger := flogging.MustGetLogger("peer.chaincode." + externalbuilder.SanitizeCCIDPath(i.CCID))
+	sess, err := externalbuilder.Start(logger, cmd)
+	stdoutWriter.Close()
+	if err != nil {
+		stdout.Close()
+		return errors.WithMessagef(err, "could not start chaincode %s", i.CCID)
+	}
+	go logOutput(logger, stdout)
+
+	i.mutex.Lock()
+	i.session = sess
+	i.mutex.Unlock()
+
+	processLogger.Infof("started chaincode %s with pid %d", i.CCID, cmd.Process.Pid)
+	return nil
+}
+
+// env returns the environment of the chaincode process and writes the TLS
+// files it refers to.
+func (i *Instance) env(tlsConfig *ccintf.TLSConfig) ([]string, error) {
+	env := []string{
+		"CORE_CHAINCODE_ID_NAME=" + i.CCID,
+		"CORE_PEER_LOCALMSPID=" + i.MSPID,
+	}
+	if path, ok := os.LookupEnv("PATH"); ok {
+		env = append(env, "PATH="+path)
+	}
+	env = append(env, i.LoggingEnv...)
+
+	if tlsConfig == nil {
+		return append(env, "CORE_PEER_TLS_ENABLED=false"), nil
+	}
+
+	// the key and certificate are base64 encoded for compatibility with
+	// the chaincode built for Docker
+	files := map[string][]byte{
+		TLSClientKeyPath:      []byte(base64.StdEncoding.EncodeToString(tlsConfig.ClientKey)),
+		TLSClientCertPath:     []byte(base64.StdEncoding.EncodeToString(tlsConfig.ClientCert)),
+		TLSClientKeyFile:      tlsConfig.ClientKey,
+		TLSClientCertFile:     tlsConfig.ClientCert,
+		TLSClientRootCertFile: tlsConfig.RootCert,
+	}
+	for name, contents := range files {
+		path := filepath.Join(i.WorkDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, errors.WithMessage(err, "could not create tls dir")
+		}
+		if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+			return nil, errors.WithMessagef(err, "could not write %s", name)
+		}
+	}
+
+	return append(env,
+		"CORE_PEER_TLS_ENABLED=true",
+		"CORE_TLS_CLIENT_KEY_PATH="+filepath.Join(i.WorkDir, TLSClientKeyPath),
+		"CORE_TLS_CLIENT_CERT_PATH="+filepath.Join(i.WorkDir, TLSClientCertPath),
+		"CORE_TLS_CLIENT_KEY_FILE="+filepath.Join(i.WorkDir, TLSClientKeyFile),
+		"CORE_TLS_CLIENT_CERT_FILE="+filepath.Join(i.WorkDir, TLSClientCertFile),
+		"CORE_PEER_TLS_ROOTCERT_FILE="+filepath.Join(i.WorkDir, TLSClientRootCertFile),
+	), nil
+}
+
+// logOutput copies the standard output of the chaincode to the logger. The
+// standard error is copied by the session.
+func logOutput(logger *flogging.FabricLogger, stdout io.ReadCloser) {
+	defer stdout.Close()
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		logger.Info(scanner.Text())
+	}
+}
+
+func (i *Instance) getSession() *externalbuilder.Session {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.session
+}
+
+// Stop signals the process to terminate with SIGTERM. If the process doesn't
+// terminate within TermTimeout, the process is killed with SIGKILL.
+func (i *Instance) Stop() error {
+	sess := i.getSession()
+	if sess == nil {
+		return errors.Errorf("instance has not been started")
+	}
+
+	done := make(chan struct{})
+	go func() { sess.Wait(); close(done) }()
+
+	sess.Signal(syscall.SIGTERM)
+	select {
+	case <-time.After(i.TermTimeout):
+		sess.Signal(syscall.SIGKILL)
+	case <-done:
+		return nil
+	}
+
+	select {
+	case <-time.After(5 * time.Second):
+		return errors.Errorf("failed to stop chaincode process '%s'", i.CCID)
+	case <-done:
+		return nil
+	}
+}
+
+// Wait waits for the process to exit and returns its exit code.
+func (i *Instance) Wait() (int, error) {
+	sess := i.getSession()
+	if sess == nil {
+		return -1, errors.Errorf("instance was not successfully started")
+	}
+
+	err := sess.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), errors.Wrapf(err, "chaincode process '%s' failed", i.CCID)
+	}
+	return 0, errors.Wrapf(err, "chaincode process '%s' failed", i.CCID)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processcontroller
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// limitsWrapper is the name the peer executes itself with to set the limits
+// of a chaincode process and execute the chaincode binary in its place, as the
+// limits of a child process cannot be set between fork and exec in Go.
+const limitsWrapper = "fabric-chaincode-limits"
+
+func init() {
+	if len(os.Args) > 0 && os.Args[0] == limitsWrapper {
+		os.Exit(execWithLimits(os.Args[1:]))
+	}
+}
+
+// Command returns the command executing the binary with the given arguments.
+// When limits are set the command executes the peer, which sets the limits
+// and executes the binary in its place, so that the limits are in effect
+// before the binary starts.
+func (l Limits) Command(binary string, args ...string) (*exec.Cmd, error) {
+	if l.MaxOpenFiles == 0 && l.MaxMemory == 0 {
+		return exec.Command(binary, args...), nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find the peer executable")
+	}
+
+	wrapperArgs := []string{
+		strconv.FormatUint(l.MaxOpenFiles, 10),
+		strconv.FormatUint(l.MaxMemory, 10),
+		binary,
+	}
+	cmd := exec.Command(self, append(wrapperArgs, args...)...)
+	cmd.Args[0] = limitsWrapper
+	return cmd, nil
+}
+
+// execWithLimits sets the limits given as the first two arguments and
+// executes the binary and arguments which follow. It only returns if
+// the binary could not be executed.
+func execWithLimits(args []string) int {
+	if len(args) < 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s <max open files> <max memory> <binary> [args...]\n", limitsWrapper)
+		return 1
+	}
+
+	maxOpenFiles, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid max open files: %s\n", err)
+		return 1
+	}
+	maxMemory, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid max memory: %s\n", err)
+		return 1
+	}
+
+	l := Limits{MaxOpenFiles: maxOpenFiles, MaxMemory: maxMemory}
+	if err := l.set(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	binary, err := exec.LookPath(args[2])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not find %s: %s\n", args[2], err)
+		return 1
+	}
+	err = syscall.Exec(binary, args[2:], os.Environ())
+	fmt.Fprintf(os.Stderr, "could not execute %s: %s\n", binary, err)
+	return 1
+}
+
+// set sets the limits of the current process, which are inherited
+// across exec
+func (l Limits) set() error {
+	if l.MaxOpenFiles > 0 {
+		rlimit := &syscall.Rlimit{Cur: l.MaxOpenFiles, Max: l.MaxOpenFiles}
+		if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, rlimit); err != nil {
+			return errors.Wrap(err, "could not limit open files")
+		}
+	}
+	if l.MaxMemory > 0 {
+		rlimit := &syscall.Rlimit{Cur: l.MaxMemory, Max: l.MaxMemory}
+		if err := syscall.Setrlimit(syscall.RLIMIT_AS, rlimit); err != nil {
+			return errors.Wrap(err, "could not limit memory")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processcontroller_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/hyperledger/fabric/core/container/processcontroller"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Limits", func() {
+	var cmd *exec.Cmd
+
+	AfterEach(func() {
+		if cmd != nil && cmd.Process != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}
+	})
+
+	limitsOf := func(pid int) string {
+		limits, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+		Expect(err).NotTo(HaveOccurred())
+		return string(limits)
+	}
+
+	executableOf := func(pid int) func() string {
+		return func() string {
+			exe, _ := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+			return exe
+		}
+	}
+
+	It("sets the limits before executing the binary", func() {
+		limits := processcontroller.Limits{MaxOpenFiles: 128, MaxMemory: 1 << 30}
+		var err error
+		cmd, err = limits.Command("sleep", "60")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cmd.Start()).To(Succeed())
+
+		sleep, err := exec.LookPath("sleep")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(executableOf(cmd.Process.Pid)).Should(Equal(sleep))
+
+		Expect(limitsOf(cmd.Process.Pid)).To(MatchRegexp(`Max open files\s+128\s+128\s+files`))
+		Expect(limitsOf(cmd.Process.Pid)).To(MatchRegexp(`Max address space\s+1073741824\s+1073741824\s+bytes`))
+	})
+
+	It("executes the binary directly without limits", func() {
+		var err error
+		cmd, err = processcontroller.Limits{}.Command("sleep", "60")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cmd.Args).To(Equal([]string{"sleep", "60"}))
+	})
+
+	It("fails when the binary cannot be executed", func() {
+		var err error
+		cmd, err = processcontroller.Limits{MaxOpenFiles: 128}.Command("/missing/binary")
+		Expect(err).NotTo(HaveOccurred())
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = stderr
+
+		err = cmd.Run()
+		Expect(err).To(MatchError("exit status 1"))
+		Expect(stderr.String()).To(ContainSubstring("could not find /missing/binary"))
+	})
+})
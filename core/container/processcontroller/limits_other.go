@@ -0,0 +1,24 @@
+// +build !linux
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processcontroller
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// Command returns the command executing the binary with the given
+// arguments. Limits are only supported on Linux.
+func (l Limits) Command(binary string, args ...string) (*exec.Cmd, error) {
+	if l.MaxOpenFiles > 0 || l.MaxMemory > 0 {
+		return nil, errors.New("chaincode process limits are only supported on linux")
+	}
+	return exec.Command(binary, args...), nil
+}
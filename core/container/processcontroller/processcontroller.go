@@ -0,0 +1,133 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processcontroller
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/core/container"
+	"github.com/hyperledger/fabric/core/container/externalbuilder"
+	"github.com/pkg/errors"
+)
+
+var processLogger = flogging.MustGetLogger("processcontroller")
+
+// DefaultTermTimeout is the time a chaincode process is given to exit after
+// SIGTERM before it is killed.
+const DefaultTermTimeout = 5 * time.Second
+
+// LocalBuilder provides the script which builds chaincode with the local
+// toolchain.
+type LocalBuilder interface {
+	LocalBuildScript(path, inputDir, output string) string
+}
+
+// ProcessVM builds Go chaincode with the Go toolchain of the peer host and
+// runs the chaincode as child processes of the peer.
+type ProcessVM struct {
+	// Builder provides the build script for Go chaincode.
+	Builder LocalBuilder
+	// BuildDir holds the chaincode binaries. Binaries are reused across
+	// restarts of the peer.
+	BuildDir string
+	// RunDir holds the working directory of each chaincode process.
+	RunDir string
+	// Limits are applied to each chaincode process.
+	Limits Limits
+	// LoggingEnv is passed to the chaincode to configure its logging.
+	LoggingEnv []string
+	// MSPID is the ID of the local MSP passed to the chaincode.
+	MSPID string
+	// TermTimeout is the time a chaincode process is given to exit after
+	// SIGTERM. Defaults to DefaultTermTimeout.
+	TermTimeout time.Duration
+}
+
+// Build builds the chaincode binary unless it has been built before. Only Go
+// chaincode is supported. For other chaincode nil is returned so that
+// another builder can be used.
+func (vm *ProcessVM) Build(ccid string, metadata *persistence.ChaincodePackageMetadata, codePackage io.Reader) (container.Instance, error) {
+	if strings.ToUpper(metadata.Type) != pb.ChaincodeSpec_GOLANG.String() {
+		processLogger.Debugf("chaincode %s of type %s cannot run as a process", ccid, metadata.Type)
+		return nil, nil
+	}
+
+	binary := filepath.Join(vm.BuildDir, externalbuilder.SanitizeCCIDPath(ccid), "chaincode")
+	if _, err := os.Stat(binary); err == nil {
+		processLogger.Debugf("using existing binary for chaincode %s", ccid)
+		return vm.instance(ccid, binary), nil
+	}
+
+	if err := vm.build(ccid, metadata.Path, codePackage, binary); err != nil {
+		return nil, err
+	}
+
+	return vm.instance(ccid, binary), nil
+}
+
+func (vm *ProcessVM) build(ccid, path string, codePackage io.Reader, binary string) error {
+	tmpDir, err := ioutil.TempDir("", "fabric-"+externalbuilder.SanitizeCCIDPath(ccid))
+	if err != nil {
+		return errors.WithMessage(err, "could not create temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	inputDir := filepath.Join(tmpDir, "input")
+	if err := externalbuilder.Untar(codePackage, inputDir); err != nil {
+		return errors.WithMessage(err, "could not untar source package")
+	}
+
+	output := filepath.Join(tmpDir, "chaincode")
+	cmd := exec.Command("/bin/sh", "-c", vm.Builder.LocalBuildScript(path, inputDir, output))
+	cmd.Dir = tmpDir
+	// GOPATH builds are only supported with module mode disabled
+	cmd.Env = append(os.Environ(), "GO111MODULE=auto")
+
+	processLogger.Infof("building chaincode %s", ccid)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Errorf("could not build chaincode %s: %s: %s", ccid, err, bytes.TrimSpace(out))
+	}
+	processLogger.Debugf("build output for chaincode %s:\n%s", ccid, out)
+
+	if err := os.MkdirAll(filepath.Dir(binary), 0700); err != nil {
+		return errors.WithMessage(err, "could not create build dir")
+	}
+	if err := os.Rename(output, binary); err != nil {
+		return errors.WithMessage(err, "could not move chaincode binary")
+	}
+
+	processLogger.Infof("built chaincode %s", ccid)
+	return nil
+}
+
+func (vm *ProcessVM) instance(ccid, binary string) *Instance {
+	termTimeout := vm.TermTimeout
+	if termTimeout == 0 {
+		termTimeout = DefaultTermTimeout
+	}
+
+	return &Instance{
+		CCID:        ccid,
+		Binary:      binary,
+		WorkDir:     filepath.Join(vm.RunDir, externalbuilder.SanitizeCCIDPath(ccid)),
+		Limits:      vm.Limits,
+		LoggingEnv:  vm.LoggingEnv,
+		MSPID:       vm.MSPID,
+		TermTimeout: termTimeout,
+	}
+}
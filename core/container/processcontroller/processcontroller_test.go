@@ -0,0 +1,227 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processcontroller_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/core/chaincode/platforms/golang"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/core/container/processcontroller"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProcessVM", func() {
+	var (
+		tempDir     string
+		vm          *processcontroller.ProcessVM
+		metadata    *persistence.ChaincodePackageMetadata
+		codePackage []byte
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "processcontroller")
+		Expect(err).NotTo(HaveOccurred())
+
+		vm = &processcontroller.ProcessVM{
+			Builder:     &golang.Platform{},
+			BuildDir:    filepath.Join(tempDir, "builds"),
+			RunDir:      filepath.Join(tempDir, "run"),
+			Limits:      processcontroller.Limits{MaxOpenFiles: 100},
+			LoggingEnv:  []string{"CORE_CHAINCODE_LOGGING_LEVEL=debug"},
+			MSPID:       "Org1MSP",
+			TermTimeout: time.Second,
+		}
+		metadata = &persistence.ChaincodePackageMetadata{Type: "golang", Path: "chaincode"}
+		codePackage = tarChaincode("testdata/chaincode/src")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("Build", func() {
+		It("builds the chaincode binary", func() {
+			instance, err := vm.Build("mycc:0.1", metadata, bytes.NewReader(codePackage))
+			Expect(err).NotTo(HaveOccurred())
+
+			pi, ok := instance.(*processcontroller.Instance)
+			Expect(ok).To(BeTrue())
+			Expect(pi.CCID).To(Equal("mycc:0.1"))
+			Expect(pi.Binary).To(Equal(filepath.Join(tempDir, "builds", "mycc-0.1", "chaincode")))
+			Expect(pi.Binary).To(BeAnExistingFile())
+			Expect(pi.WorkDir).To(Equal(filepath.Join(tempDir, "run", "mycc-0.1")))
+			Expect(pi.Limits).To(Equal(processcontroller.Limits{MaxOpenFiles: 100}))
+			Expect(pi.TermTimeout).To(Equal(time.Second))
+		})
+
+		It("reuses a chaincode binary built before", func() {
+			_, err := vm.Build("mycc:0.1", metadata, bytes.NewReader(codePackage))
+			Expect(err).NotTo(HaveOccurred())
+
+			instance, err := vm.Build("mycc:0.1", metadata, bytes.NewReader([]byte("not a package")))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.(*processcontroller.Instance).Binary).To(BeAnExistingFile())
+		})
+
+		Context("when the chaincode is not written in Go", func() {
+			BeforeEach(func() {
+				metadata.Type = "node"
+			})
+
+			It("returns a nil instance", func() {
+				instance, err := vm.Build("mycc:0.1", metadata, bytes.NewReader(codePackage))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instance).To(BeNil())
+			})
+		})
+
+		Context("when the term timeout is not set", func() {
+			BeforeEach(func() {
+				vm.TermTimeout = 0
+			})
+
+			It("uses the default", func() {
+				instance, err := vm.Build("mycc:0.1", metadata, bytes.NewReader(codePackage))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instance.(*processcontroller.Instance).TermTimeout).To(Equal(processcontroller.DefaultTermTimeout))
+			})
+		})
+
+		Context("when the code package cannot be extracted", func() {
+			It("returns an error", func() {
+				_, err := vm.Build("mycc:0.1", metadata, bytes.NewReader([]byte("not a package")))
+				Expect(err).To(MatchError(ContainSubstring("could not untar source package")))
+			})
+		})
+
+		Context("when the build fails", func() {
+			BeforeEach(func() {
+				metadata.Path = "missing"
+			})
+
+			It("returns the build output", func() {
+				_, err := vm.Build("mycc:0.1", metadata, bytes.NewReader(codePackage))
+				Expect(err).To(MatchError(ContainSubstring("could not build chaincode mycc:0.1")))
+				Expect(err).To(MatchError(ContainSubstring("missing")))
+			})
+		})
+	})
+
+	Describe("Instance", func() {
+		var instance *processcontroller.Instance
+
+		BeforeEach(func() {
+			i, err := vm.Build("mycc:0.1", metadata, bytes.NewReader(codePackage))
+			Expect(err).NotTo(HaveOccurred())
+			instance = i.(*processcontroller.Instance)
+		})
+
+		It("runs the chaincode in its working directory", func() {
+			err := instance.Start(&ccintf.PeerConnection{Address: "peer:7052"})
+			Expect(err).NotTo(HaveOccurred())
+
+			exitCode, err := instance.Wait()
+			Expect(err).To(MatchError("chaincode process 'mycc:0.1' failed: exit status 3"))
+			Expect(exitCode).To(Equal(3))
+
+			report, err := ioutil.ReadFile(filepath.Join(instance.WorkDir, "report.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(report)).To(ContainSubstring("args=-peer.address=peer:7052\n"))
+			Expect(string(report)).To(ContainSubstring("CORE_CHAINCODE_ID_NAME=mycc:0.1"))
+			Expect(string(report)).To(ContainSubstring("CORE_PEER_LOCALMSPID=Org1MSP"))
+			Expect(string(report)).To(ContainSubstring("CORE_CHAINCODE_LOGGING_LEVEL=debug"))
+			Expect(string(report)).To(ContainSubstring("CORE_PEER_TLS_ENABLED=false"))
+			Expect(string(report)).To(ContainSubstring("nofile=100\n"))
+		})
+
+		It("passes the TLS configuration to the chaincode", func() {
+			err := instance.Start(&ccintf.PeerConnection{
+				Address: "peer:7052",
+				TLSConfig: &ccintf.TLSConfig{
+					ClientKey:  []byte("key"),
+					ClientCert: []byte("cert"),
+					RootCert:   []byte("root"),
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			instance.Wait()
+
+			report, err := ioutil.ReadFile(filepath.Join(instance.WorkDir, "report.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(report)).To(ContainSubstring("CORE_PEER_TLS_ENABLED=true"))
+			Expect(string(report)).To(ContainSubstring("CORE_TLS_CLIENT_KEY_FILE=" + filepath.Join(instance.WorkDir, "tls/client_pem.key")))
+
+			Expect(filepath.Join(instance.WorkDir, "tls/client.key")).To(BeARegularFile())
+			key, err := ioutil.ReadFile(filepath.Join(instance.WorkDir, "tls/client_pem.key"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key).To(Equal([]byte("key")))
+			root, err := ioutil.ReadFile(filepath.Join(instance.WorkDir, "tls/peer.crt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(root).To(Equal([]byte("root")))
+		})
+
+		It("stops the chaincode", func() {
+			err := instance.Start(&ccintf.PeerConnection{Address: "sleep"})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(filepath.Join(instance.WorkDir, "report.txt")).Should(BeAnExistingFile())
+
+			err = instance.Stop()
+			Expect(err).NotTo(HaveOccurred())
+
+			exitCode, err := instance.Wait()
+			Expect(err).To(HaveOccurred())
+			Expect(exitCode).To(Equal(-1))
+		})
+
+		Context("when the instance has not been started", func() {
+			It("fails to stop and wait", func() {
+				Expect(instance.Stop()).To(MatchError("instance has not been started"))
+				_, err := instance.Wait()
+				Expect(err).To(MatchError("instance was not successfully started"))
+			})
+		})
+
+		It("has no chaincode server info", func() {
+			info, err := instance.ChaincodeServerInfo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(BeNil())
+		})
+	})
+})
+
+// tarChaincode returns a code package with the files of dir in its src
+// directory.
+func tarChaincode(dir string) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	files, err := ioutil.ReadDir(dir)
+	Expect(err).NotTo(HaveOccurred())
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		Expect(err).NotTo(HaveOccurred())
+		err = tw.WriteHeader(&tar.Header{Name: "src/" + file.Name(), Mode: 0600, Size: int64(len(contents))})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tw.Write(contents)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(tw.Close()).To(Succeed())
+	Expect(gw.Close()).To(Succeed())
+	return buf.Bytes()
+}
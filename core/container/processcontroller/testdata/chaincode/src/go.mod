@@ -0,0 +1,3 @@
+module chaincode
+
+go 1.14
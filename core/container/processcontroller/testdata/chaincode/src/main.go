@@ -0,0 +1,33 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// main records its arguments, environment and open file limit in the
+// working directory. It sleeps when the peer address is "sleep" and exits
+// with status 3 otherwise.
+func main() {
+	var rlimit syscall.Rlimit
+	syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+
+	report := fmt.Sprintf("args=%s\nenv=%s\nnofile=%d\n", strings.Join(os.Args[1:], " "), strings.Join(os.Environ(), " "), rlimit.Cur)
+	ioutil.WriteFile("report.txt", []byte(report), 0600)
+	fmt.Println("chaincode started")
+
+	if len(os.Args) > 1 && os.Args[1] == "-peer.address=sleep" {
+		time.Sleep(time.Hour)
+	}
+	os.Exit(3)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
+)
+
+// Functions exported by the chaincode. Both take no arguments and return a
+// status, where zero indicates success. The response set with set_response
+// is returned as the payload on success and as the message on failure.
+const (
+	// InvokeFunction is called for each transaction.
+	InvokeFunction = "invoke"
+	// InitFunction is optional and called when the chaincode is initialized.
+	InitFunction = "init"
+)
+
+var entryType = signature(0, 1)
+
+// Chaincode executes a compiled module as chaincode. A fresh instance of the
+// module is used for each transaction so that no state is kept outside of
+// the ledger.
+type Chaincode struct {
+	Module *wasm.Module
+	Config wasm.Config
+}
+
+// Init calls the init function of the module, if exported.
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	if _, ok := c.Module.ExportedFunc(InitFunction); !ok {
+		return shim.Success(nil)
+	}
+	return c.run(stub, InitFunction)
+}
+
+// Invoke calls the invoke function of the module.
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.run(stub, InvokeFunction)
+}
+
+func (c *Chaincode) run(stub shim.ChaincodeStubInterface, function string) pb.Response {
+	inv := newInvocation(stub)
+	defer inv.closeIterators()
+
+	inst, err := wasm.Instantiate(c.Module, inv.imports(), c.Config)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("could not instantiate chaincode: %s", err))
+	}
+	results, err := inst.Call(function)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("chaincode execution failed: %s", err))
+	}
+	wasmLogger.Debugf("[%s] %s used %d gas", shorttxid(stub.GetTxID()), function, inst.GasUsed())
+
+	if status := int32(results[0]); status != 0 {
+		return shim.Error(string(inv.response))
+	}
+	return shim.Success(inv.response)
+}
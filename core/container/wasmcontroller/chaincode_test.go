@@ -0,0 +1,318 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller_test
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric/core/container/wasmcontroller"
+	"github.com/hyperledger/fabric/core/container/wasmcontroller/mock"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
+	. "github.com/hyperledger/fabric/internal/pkg/wasm/wasmtest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+// readArg copies argument i to ptr and stores its length in a local.
+func readArg(i, ptr int32, local uint32) []byte {
+	return Code(I32Const(i), Call(arg), LocalSet(local), I32Const(ptr), Call(resultRead))
+}
+
+// respond sets the response to the data at ptr with the length in a local.
+func respond(ptr int32, local uint32) []byte {
+	return Code(I32Const(ptr), LocalGet(local), Call(setResponse))
+}
+
+// rangeModule iterates over a range and responds with the records.
+// Arguments are the start and end key, or the collection, start and end key
+// for private data.
+func rangeModule(private bool) *Module {
+	var open []byte
+	if private {
+		open = Code(
+			readArg(0, 0, 0), readArg(1, 256, 1), readArg(2, 512, 2),
+			I32Const(0), LocalGet(0), I32Const(256), LocalGet(1), I32Const(512), LocalGet(2), Call(getPrivateDataRange),
+		)
+	} else {
+		open = Code(
+			readArg(0, 256, 1), readArg(1, 512, 2),
+			I32Const(256), LocalGet(1), I32Const(512), LocalGet(2), Call(getStateRange),
+		)
+	}
+	return chaincodeModule(6, Code(
+		open, LocalSet(3),
+		I32Const(1024), LocalSet(4),
+		Block(), Loop(),
+		LocalGet(3), Call(iteratorNext), LocalTee(5),
+		I32Const(0), I32LtS, BrIf(1),
+		LocalGet(4), Call(resultRead),
+		LocalGet(4), LocalGet(5), I32Add, LocalSet(4),
+		Br(0),
+		End, End,
+		LocalGet(3), Call(iteratorClose),
+		I32Const(1024), LocalGet(4), I32Const(1024), I32Sub, Call(setResponse),
+		I32Const(0),
+	))
+}
+
+var _ = Describe("Chaincode", func() {
+	var (
+		fakeStub *mock.ChaincodeStub
+		config   wasm.Config
+	)
+
+	BeforeEach(func() {
+		fakeStub = &mock.ChaincodeStub{}
+		fakeStub.GetTxIDReturns("0123456789abcdef")
+		fakeStub.GetChannelIDReturns("channel")
+		config = wasm.Config{}
+	})
+
+	invoke := func(m *Module) (int32, string, []byte) {
+		cc := &wasmcontroller.Chaincode{Module: compile(m), Config: config}
+		resp := cc.Invoke(fakeStub)
+		return resp.Status, resp.Message, resp.Payload
+	}
+
+	Describe("get_state", func() {
+		var module *Module
+
+		BeforeEach(func() {
+			fakeStub.GetArgsReturns([][]byte{[]byte("key")})
+			module = chaincodeModule(2, Code(
+				readArg(0, 0, 0),
+				I32Const(0), LocalGet(0), Call(getState), LocalTee(1),
+				I32Const(0), I32LtS, If(),
+				I32Const(2048), I32Const(9), Call(setResponse),
+				I32Const(1), Return,
+				End,
+				I32Const(256), Call(resultRead),
+				respond(256, 1),
+				I32Const(0),
+			), Data{Offset: 2048, Bytes: []byte("not found")})
+		})
+
+		It("returns the value of the key", func() {
+			fakeStub.GetStateReturns([]byte("value"), nil)
+
+			status, _, payload := invoke(module)
+			Expect(status).To(Equal(int32(shim.OK)))
+			Expect(payload).To(Equal([]byte("value")))
+			Expect(fakeStub.GetStateCallCount()).To(Equal(1))
+			Expect(fakeStub.GetStateArgsForCall(0)).To(Equal("key"))
+		})
+
+		It("returns -1 for missing keys", func() {
+			status, message, _ := invoke(module)
+			Expect(status).To(Equal(int32(shim.ERROR)))
+			Expect(message).To(Equal("not found"))
+		})
+
+		It("aborts the execution when the stub fails", func() {
+			fakeStub.GetStateReturns(nil, errors.New("boom"))
+
+			status, message, _ := invoke(module)
+			Expect(status).To(Equal(int32(shim.ERROR)))
+			Expect(message).To(Equal("chaincode execution failed: get_state failed: boom"))
+		})
+	})
+
+	It("puts and deletes state", func() {
+		fakeStub.GetArgsReturns([][]byte{[]byte("key"), []byte("value")})
+
+		status, _, _ := invoke(chaincodeModule(2, Code(
+			readArg(0, 0, 0), readArg(1, 256, 1),
+			I32Const(0), LocalGet(0), I32Const(256), LocalGet(1), Call(putState),
+			I32Const(0), LocalGet(0), Call(delState),
+			I32Const(0),
+		)))
+		Expect(status).To(Equal(int32(shim.OK)))
+		Expect(fakeStub.PutStateCallCount()).To(Equal(1))
+		key, value := fakeStub.PutStateArgsForCall(0)
+		Expect(key).To(Equal("key"))
+		Expect(value).To(Equal([]byte("value")))
+		Expect(fakeStub.DelStateCallCount()).To(Equal(1))
+		Expect(fakeStub.DelStateArgsForCall(0)).To(Equal("key"))
+	})
+
+	It("reads, writes and deletes private data", func() {
+		fakeStub.GetArgsReturns([][]byte{[]byte("collection"), []byte("key"), []byte("secret")})
+		fakeStub.GetTransientReturns(map[string][]byte{"secret": []byte("transient value")}, nil)
+		fakeStub.GetPrivateDataReturns([]byte("private value"), nil)
+
+		status, _, payload := invoke(chaincodeModule(4, Code(
+			readArg(0, 0, 0), readArg(1, 256, 1), readArg(2, 512, 2),
+			I32Const(512), LocalGet(2), Call(getTransient), LocalSet(3),
+			I32Const(768), Call(resultRead),
+			I32Const(0), LocalGet(0), I32Const(256), LocalGet(1), I32Const(768), LocalGet(3), Call(putPrivateData),
+			I32Const(0), LocalGet(0), I32Const(256), LocalGet(1), Call(getPrivateData), LocalSet(3),
+			I32Const(1024), Call(resultRead),
+			I32Const(0), LocalGet(0), I32Const(256), LocalGet(1), Call(delPrivateData),
+			respond(1024, 3),
+			I32Const(0),
+		)))
+		Expect(status).To(Equal(int32(shim.OK)))
+		Expect(payload).To(Equal([]byte("private value")))
+
+		Expect(fakeStub.PutPrivateDataCallCount()).To(Equal(1))
+		collection, key, value := fakeStub.PutPrivateDataArgsForCall(0)
+		Expect(collection).To(Equal("collection"))
+		Expect(key).To(Equal("key"))
+		Expect(value).To(Equal([]byte("transient value")))
+		collection, key = fakeStub.GetPrivateDataArgsForCall(0)
+		Expect(collection).To(Equal("collection"))
+		Expect(key).To(Equal("key"))
+		collection, key = fakeStub.DelPrivateDataArgsForCall(0)
+		Expect(collection).To(Equal("collection"))
+		Expect(key).To(Equal("key"))
+	})
+
+	Describe("iterators", func() {
+		var fakeIterator *mock.StateQueryIterator
+
+		BeforeEach(func() {
+			fakeIterator = &mock.StateQueryIterator{}
+			fakeIterator.HasNextReturnsOnCall(0, true)
+			fakeIterator.HasNextReturnsOnCall(1, true)
+			fakeIterator.NextReturnsOnCall(0, &queryresult.KV{Key: "a", Value: []byte("1")}, nil)
+			fakeIterator.NextReturnsOnCall(1, &queryresult.KV{Key: "bc", Value: []byte("23")}, nil)
+			fakeStub.GetStateByRangeReturns(fakeIterator, nil)
+			fakeStub.GetPrivateDataByRangeReturns(fakeIterator, nil)
+		})
+
+		records := []byte("\x01\x00\x00\x00a1\x02\x00\x00\x00bc23")
+
+		It("iterates over a range of state", func() {
+			fakeStub.GetArgsReturns([][]byte{[]byte("a"), []byte("z")})
+
+			status, _, payload := invoke(rangeModule(false))
+			Expect(status).To(Equal(int32(shim.OK)))
+			Expect(payload).To(Equal(records))
+			startKey, endKey := fakeStub.GetStateByRangeArgsForCall(0)
+			Expect(startKey).To(Equal("a"))
+			Expect(endKey).To(Equal("z"))
+			Expect(fakeIterator.CloseCallCount()).To(Equal(1))
+		})
+
+		It("iterates over a range of private data", func() {
+			fakeStub.GetArgsReturns([][]byte{[]byte("collection"), []byte("a"), []byte("z")})
+
+			status, _, payload := invoke(rangeModule(true))
+			Expect(status).To(Equal(int32(shim.OK)))
+			Expect(payload).To(Equal(records))
+			collection, startKey, endKey := fakeStub.GetPrivateDataByRangeArgsForCall(0)
+			Expect(collection).To(Equal("collection"))
+			Expect(startKey).To(Equal("a"))
+			Expect(endKey).To(Equal("z"))
+			Expect(fakeIterator.CloseCallCount()).To(Equal(1))
+		})
+
+		It("closes iterators left open", func() {
+			status, _, _ := invoke(chaincodeModule(0, Code(
+				I32Const(0), I32Const(0), I32Const(0), I32Const(0), Call(getStateRange), Drop,
+				I32Const(0),
+			)))
+			Expect(status).To(Equal(int32(shim.OK)))
+			Expect(fakeIterator.CloseCallCount()).To(Equal(1))
+		})
+
+		It("fails for unknown iterators", func() {
+			_, message, _ := invoke(chaincodeModule(0, Code(
+				I32Const(7), Call(iteratorNext),
+			)))
+			Expect(message).To(Equal("chaincode execution failed: unknown iterator 7"))
+		})
+	})
+
+	It("provides the transaction context", func() {
+		status, _, payload := invoke(chaincodeModule(1, Code(
+			Call(txID), LocalSet(0),
+			I32Const(0), Call(resultRead),
+			I32Const(1024), I32Const(3), I32Const(0), LocalGet(0), Call(setEvent),
+			I32Const(1024), I32Const(3), Call(log),
+			Call(channelID), LocalSet(0),
+			I32Const(0), Call(resultRead),
+			respond(0, 0),
+			I32Const(0),
+		), Data{Offset: 1024, Bytes: []byte("evt")}))
+		Expect(status).To(Equal(int32(shim.OK)))
+		Expect(payload).To(Equal([]byte("channel")))
+		Expect(fakeStub.SetEventCallCount()).To(Equal(1))
+		name, eventPayload := fakeStub.SetEventArgsForCall(0)
+		Expect(name).To(Equal("evt"))
+		Expect(eventPayload).To(Equal([]byte("0123456789abcdef")))
+	})
+
+	It("returns the number of arguments and -1 for missing arguments", func() {
+		module := chaincodeModule(0, Code(Call(argCount), I32Const(7), Call(arg), I32Add))
+
+		fakeStub.GetArgsReturns([][]byte{[]byte("a")})
+		status, _, _ := invoke(module)
+		Expect(status).To(Equal(int32(shim.OK)))
+
+		fakeStub.GetArgsReturns([][]byte{[]byte("a"), []byte("b")})
+		status, _, _ = invoke(module)
+		Expect(status).To(Equal(int32(shim.ERROR)))
+	})
+
+	It("returns the response as the error message for a non-zero status", func() {
+		_, message, _ := invoke(chaincodeModule(0, Code(
+			I32Const(0), I32Const(5), Call(setResponse),
+			I32Const(2),
+		), Data{Offset: 0, Bytes: []byte("oops!")}))
+		Expect(message).To(Equal("oops!"))
+	})
+
+	It("fails when the chaincode runs out of gas", func() {
+		config.Gas = 1000
+		_, message, _ := invoke(chaincodeModule(0, Code(Loop(), Br(0), End, I32Const(0))))
+		Expect(message).To(Equal("chaincode execution failed: out of gas"))
+	})
+
+	It("charges gas for host calls", func() {
+		config.Gas = 50
+		_, message, _ := invoke(chaincodeModule(0, Code(Call(argCount))))
+		Expect(message).To(Equal("chaincode execution failed: out of gas"))
+	})
+
+	It("fails when the chaincode passes memory out of bounds", func() {
+		_, message, _ := invoke(chaincodeModule(0, Code(
+			I32Const(65530), I32Const(10), Call(getState),
+		)))
+		Expect(message).To(Equal("chaincode execution failed: out of bounds memory access at 65530, length 10"))
+	})
+
+	It("fails when the module cannot be instantiated", func() {
+		config.MaxMemoryPages = 1
+		module := chaincodeModule(0, I32Const(0))
+		module.Memory = 2
+		_, message, _ := invoke(module)
+		Expect(message).To(Equal("could not instantiate chaincode: module requires 2 pages of memory, limit is 1"))
+	})
+
+	Describe("Init", func() {
+		It("succeeds when the module does not export init", func() {
+			cc := &wasmcontroller.Chaincode{Module: compile(chaincodeModule(0, Code(Unreachable)))}
+			resp := cc.Init(fakeStub)
+			Expect(resp.Status).To(Equal(int32(shim.OK)))
+		})
+
+		It("calls init when exported", func() {
+			module := chaincodeModule(0, Code(Unreachable), Data{Offset: 0, Bytes: []byte("initialized")})
+			module.Funcs = append(module.Funcs, Func{
+				Export:  "init",
+				Results: []byte{I32},
+				Code:    Code(I32Const(0), I32Const(11), Call(setResponse), I32Const(0)),
+			})
+			cc := &wasmcontroller.Chaincode{Module: compile(module)}
+			resp := cc.Init(fakeStub)
+			Expect(resp.Status).To(Equal(int32(shim.OK)))
+			Expect(resp.Payload).To(Equal([]byte("initialized")))
+		})
+	})
+})
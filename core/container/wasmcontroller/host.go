@@ -0,0 +1,384 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller
+
+import (
+	"encoding/binary"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
+	"github.com/pkg/errors"
+)
+
+// HostModule is the module from which chaincode imports host functions.
+//
+// All parameters and results of host functions are of type i32. Data is
+// passed as a pointer and length into the memory of the module. Functions
+// which return data store it in a result buffer and return its length, or
+// -1 if there is no data. The chaincode copies the result buffer into its
+// memory with result_read.
+const HostModule = "fabric"
+
+// hostCallGas is the gas charged for every call of a host function, in
+// addition to one unit of gas per byte passed between chaincode and host.
+const hostCallGas = 100
+
+// noData is returned by host functions which find no data.
+const noData = 0xffffffff
+
+// hostFunctionTypes are the host functions which may be imported, by name.
+var hostFunctionTypes = map[string]wasm.FuncType{
+	"arg_count":              signature(0, 1),
+	"arg":                    signature(1, 1),
+	"result_read":            signature(1, 0),
+	"tx_id":                  signature(0, 1),
+	"channel_id":             signature(0, 1),
+	"get_state":              signature(2, 1),
+	"put_state":              signature(4, 0),
+	"del_state":              signature(2, 0),
+	"get_state_range":        signature(4, 1),
+	"get_private_data":       signature(4, 1),
+	"put_private_data":       signature(6, 0),
+	"del_private_data":       signature(4, 0),
+	"get_private_data_range": signature(6, 1),
+	"get_transient":          signature(2, 1),
+	"iterator_next":          signature(1, 1),
+	"iterator_close":         signature(1, 0),
+	"set_event":              signature(4, 0),
+	"set_response":           signature(2, 0),
+	"log":                    signature(2, 0),
+}
+
+func signature(params, results int) wasm.FuncType {
+	t := wasm.FuncType{}
+	for i := 0; i < params; i++ {
+		t.Params = append(t.Params, wasm.I32)
+	}
+	for i := 0; i < results; i++ {
+		t.Results = append(t.Results, wasm.I32)
+	}
+	return t
+}
+
+// invocation holds the state of a transaction executed by an instance of
+// the chaincode.
+type invocation struct {
+	stub      shim.ChaincodeStubInterface
+	args      [][]byte
+	result    []byte
+	response  []byte
+	iterators map[uint32]shim.StateQueryIteratorInterface
+	next      uint32
+}
+
+func newInvocation(stub shim.ChaincodeStubInterface) *invocation {
+	return &invocation{
+		stub:      stub,
+		args:      stub.GetArgs(),
+		iterators: map[uint32]shim.StateQueryIteratorInterface{},
+	}
+}
+
+type hostFunc func(inst *wasm.Instance, args []uint64) ([]uint64, error)
+
+// imports returns the host functions bound to the invocation.
+func (c *invocation) imports() wasm.Imports {
+	funcs := map[string]hostFunc{
+		"arg_count":              c.argCount,
+		"arg":                    c.arg,
+		"result_read":            c.resultRead,
+		"tx_id":                  c.txID,
+		"channel_id":             c.channelID,
+		"get_state":              c.getState,
+		"put_state":              c.putState,
+		"del_state":              c.delState,
+		"get_state_range":        c.getStateRange,
+		"get_private_data":       c.getPrivateData,
+		"put_private_data":       c.putPrivateData,
+		"del_private_data":       c.delPrivateData,
+		"get_private_data_range": c.getPrivateDataRange,
+		"get_transient":          c.getTransient,
+		"iterator_next":          c.iteratorNext,
+		"iterator_close":         c.iteratorClose,
+		"set_event":              c.setEvent,
+		"set_response":           c.setResponse,
+		"log":                    c.log,
+	}
+
+	imports := map[string]wasm.HostFunction{}
+	for name, f := range funcs {
+		imports[name] = wasm.HostFunction{Type: hostFunctionTypes[name], Func: c.charge(f)}
+	}
+	return wasm.Imports{HostModule: imports}
+}
+
+// charge consumes the gas of a host call before it is executed.
+func (c *invocation) charge(f hostFunc) hostFunc {
+	return func(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+		if err := inst.UseGas(hostCallGas); err != nil {
+			return nil, err
+		}
+		return f(inst, args)
+	}
+}
+
+// read reads data passed by the chaincode.
+func (c *invocation) read(inst *wasm.Instance, ptr, length uint64) ([]byte, error) {
+	if err := inst.UseGas(uint64(uint32(length))); err != nil {
+		return nil, err
+	}
+	return inst.Read(uint32(ptr), uint32(length))
+}
+
+func (c *invocation) readString(inst *wasm.Instance, ptr, length uint64) (string, error) {
+	data, err := c.read(inst, ptr, length)
+	return string(data), err
+}
+
+// setResult stores data in the result buffer and returns its length.
+func (c *invocation) setResult(inst *wasm.Instance, data []byte) ([]uint64, error) {
+	if data == nil {
+		c.result = nil
+		return []uint64{noData}, nil
+	}
+	if err := inst.UseGas(uint64(len(data))); err != nil {
+		return nil, err
+	}
+	c.result = data
+	return []uint64{uint64(len(data))}, nil
+}
+
+func (c *invocation) argCount(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	return []uint64{uint64(len(c.args))}, nil
+}
+
+func (c *invocation) arg(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	i := uint32(args[0])
+	if int(i) >= len(c.args) {
+		return c.setResult(inst, nil)
+	}
+	return c.setResult(inst, append([]byte{}, c.args[i]...))
+}
+
+func (c *invocation) resultRead(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	return nil, inst.Write(uint32(args[0]), c.result)
+}
+
+func (c *invocation) txID(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	return c.setResult(inst, []byte(c.stub.GetTxID()))
+}
+
+func (c *invocation) channelID(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	return c.setResult(inst, []byte(c.stub.GetChannelID()))
+}
+
+func (c *invocation) getState(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	key, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.stub.GetState(key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get_state failed")
+	}
+	return c.setResult(inst, value)
+}
+
+func (c *invocation) putState(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	key, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.read(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.WithMessage(c.stub.PutState(key, value), "put_state failed")
+}
+
+func (c *invocation) delState(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	key, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.WithMessage(c.stub.DelState(key), "del_state failed")
+}
+
+func (c *invocation) getStateRange(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	startKey, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	endKey, err := c.readString(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	iter, err := c.stub.GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get_state_range failed")
+	}
+	return c.addIterator(iter), nil
+}
+
+func (c *invocation) getPrivateData(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	collection, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.readString(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.stub.GetPrivateData(collection, key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get_private_data failed")
+	}
+	return c.setResult(inst, value)
+}
+
+func (c *invocation) putPrivateData(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	collection, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.readString(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.read(inst, args[4], args[5])
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.WithMessage(c.stub.PutPrivateData(collection, key, value), "put_private_data failed")
+}
+
+func (c *invocation) delPrivateData(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	collection, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.readString(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.WithMessage(c.stub.DelPrivateData(collection, key), "del_private_data failed")
+}
+
+func (c *invocation) getPrivateDataRange(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	collection, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	startKey, err := c.readString(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	endKey, err := c.readString(inst, args[4], args[5])
+	if err != nil {
+		return nil, err
+	}
+	iter, err := c.stub.GetPrivateDataByRange(collection, startKey, endKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get_private_data_range failed")
+	}
+	return c.addIterator(iter), nil
+}
+
+func (c *invocation) getTransient(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	key, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	transient, err := c.stub.GetTransient()
+	if err != nil {
+		return nil, errors.WithMessage(err, "get_transient failed")
+	}
+	return c.setResult(inst, transient[key])
+}
+
+func (c *invocation) addIterator(iter shim.StateQueryIteratorInterface) []uint64 {
+	c.next++
+	c.iterators[c.next] = iter
+	return []uint64{uint64(c.next)}
+}
+
+// iteratorNext stores the next record of an iterator in the result buffer.
+// A record is the length of the key as four bytes in little endian order,
+// followed by the key and the value.
+func (c *invocation) iteratorNext(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	iter, ok := c.iterators[uint32(args[0])]
+	if !ok {
+		return nil, errors.Errorf("unknown iterator %d", uint32(args[0]))
+	}
+	if !iter.HasNext() {
+		return c.setResult(inst, nil)
+	}
+	kv, err := iter.Next()
+	if err != nil {
+		return nil, errors.WithMessage(err, "iterator_next failed")
+	}
+	record := make([]byte, 4, 4+len(kv.Key)+len(kv.Value))
+	binary.LittleEndian.PutUint32(record, uint32(len(kv.Key)))
+	record = append(append(record, kv.Key...), kv.Value...)
+	return c.setResult(inst, record)
+}
+
+func (c *invocation) iteratorClose(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	iter, ok := c.iterators[uint32(args[0])]
+	if !ok {
+		return nil, errors.Errorf("unknown iterator %d", uint32(args[0]))
+	}
+	delete(c.iterators, uint32(args[0]))
+	return nil, errors.WithMessage(iter.Close(), "iterator_close failed")
+}
+
+// closeIterators closes the iterators left open by the chaincode.
+func (c *invocation) closeIterators() {
+	for handle, iter := range c.iterators {
+		if err := iter.Close(); err != nil {
+			wasmLogger.Warningf("could not close iterator: %s", err)
+		}
+		delete(c.iterators, handle)
+	}
+}
+
+func (c *invocation) setEvent(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	name, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	payload, err := c.read(inst, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.WithMessage(c.stub.SetEvent(name, payload), "set_event failed")
+}
+
+func (c *invocation) setResponse(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	response, err := c.read(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	c.response = response
+	return nil, nil
+}
+
+func (c *invocation) log(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+	message, err := c.readString(inst, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	wasmLogger.Infof("[%s] %s", shorttxid(c.stub.GetTxID()), message)
+	return nil, nil
+}
+
+func shorttxid(txid string) string {
+	if len(txid) < 8 {
+		return txid
+	}
+	return txid[0:8]
+}
@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/internal/pkg/comm"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// DialTimeout is the time allowed to connect to the peer.
+const DialTimeout = 10 * time.Second
+
+// Instance is WebAssembly chaincode connected to the peer like any other
+// chaincode, but served by a goroutine of the peer.
+type Instance struct {
+	CCID      string
+	Chaincode shim.Chaincode
+
+	mutex  sync.Mutex
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// ChaincodeServerInfo returns nil as the chaincode connects to the peer.
+func (i *Instance) ChaincodeServerInfo() (*ccintf.ChaincodeServerInfo, error) {
+	return nil, nil
+}
+
+// Start connects the chaincode to the peer.
+func (i *Instance) Start(peerConnection *ccintf.PeerConnection) error {
+	clientConfig := comm.ClientConfig{
+		KaOpts:  comm.DefaultKeepaliveOptions,
+		Timeout: DialTimeout,
+	}
+	if tlsConfig := peerConnection.TLSConfig; tlsConfig != nil {
+		clientConfig.SecOpts = comm.SecureOptions{
+			UseTLS:            true,
+			RequireClientCert: true,
+			Certificate:       tlsConfig.ClientCert,
+			Key:               tlsConfig.ClientKey,
+			ServerRootCAs:     [][]byte{tlsConfig.RootCert},
+		}
+	}
+
+	grpcClient, err := comm.NewGRPCClient(clientConfig)
+	if err != nil {
+		return errors.WithMessage(err, "could not create grpc client")
+	}
+	conn, err := grpcClient.NewConnection(peerConnection.Address)
+	if err != nil {
+		return errors.WithMessagef(err, "could not connect to peer %s", peerConnection.Address)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewChaincodeSupportClient(conn).Register(ctx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return errors.WithMessage(err, "could not register with peer")
+	}
+
+	done := make(chan struct{})
+	i.mutex.Lock()
+	i.conn, i.cancel, i.done, i.err = conn, cancel, done, nil
+	i.mutex.Unlock()
+
+	go func() {
+		err := shim.StartInProc(i.CCID, stream, i.Chaincode)
+		i.mutex.Lock()
+		if ctx.Err() == nil {
+			i.err = errors.WithMessagef(err, "chaincode %s disconnected", i.CCID)
+		}
+		i.mutex.Unlock()
+		close(done)
+	}()
+
+	wasmLogger.Infof("started wasm chaincode %s", i.CCID)
+	return nil
+}
+
+// Stop disconnects the chaincode from the peer.
+func (i *Instance) Stop() error {
+	i.mutex.Lock()
+	conn, cancel, done := i.conn, i.cancel, i.done
+	i.mutex.Unlock()
+	if done == nil {
+		return errors.Errorf("instance has not been started")
+	}
+
+	cancel()
+	<-done
+	return conn.Close()
+}
+
+// Wait waits for the chaincode to be stopped or disconnected. An exit code
+// of 1 is returned if the chaincode was disconnected unexpectedly.
+func (i *Instance) Wait() (int, error) {
+	i.mutex.Lock()
+	done := i.done
+	i.mutex.Unlock()
+	if done == nil {
+		return 0, errors.Errorf("instance has not been started")
+	}
+
+	<-done
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.err != nil {
+		return 1, i.err
+	}
+	return 0, nil
+}
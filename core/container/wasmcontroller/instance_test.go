@@ -0,0 +1,134 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller_test
+
+import (
+	"net"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/core/container/wasmcontroller"
+	. "github.com/hyperledger/fabric/internal/pkg/wasm/wasmtest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+)
+
+// chaincodeSupport is a peer which hands the streams of registering
+// chaincode to the test.
+type chaincodeSupport struct {
+	streams chan pb.ChaincodeSupport_RegisterServer
+	done    chan struct{}
+}
+
+func (cs *chaincodeSupport) Register(stream pb.ChaincodeSupport_RegisterServer) error {
+	cs.streams <- stream
+	<-cs.done
+	return nil
+}
+
+var _ = Describe("Instance", func() {
+	var (
+		instance  *wasmcontroller.Instance
+		server    *grpc.Server
+		listener  net.Listener
+		ccSupport *chaincodeSupport
+	)
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		ccSupport = &chaincodeSupport{
+			streams: make(chan pb.ChaincodeSupport_RegisterServer, 1),
+			done:    make(chan struct{}),
+		}
+		server = grpc.NewServer()
+		pb.RegisterChaincodeSupportServer(server, ccSupport)
+		go server.Serve(listener)
+
+		module := chaincodeModule(1, Code(
+			readArg(0, 0, 0),
+			respond(0, 0),
+			I32Const(0),
+		))
+		instance = &wasmcontroller.Instance{
+			CCID:      "cc:1",
+			Chaincode: &wasmcontroller.Chaincode{Module: compile(module)},
+		}
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	It("serves transactions of the peer", func() {
+		err := instance.Start(&ccintf.PeerConnection{Address: listener.Addr().String()})
+		Expect(err).NotTo(HaveOccurred())
+
+		var stream pb.ChaincodeSupport_RegisterServer
+		Eventually(ccSupport.streams).Should(Receive(&stream))
+
+		msg, err := stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Type).To(Equal(pb.ChaincodeMessage_REGISTER))
+		chaincodeID := &pb.ChaincodeID{}
+		Expect(proto.Unmarshal(msg.Payload, chaincodeID)).To(Succeed())
+		Expect(chaincodeID.Name).To(Equal("cc:1"))
+
+		Expect(stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTERED})).To(Succeed())
+		Expect(stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_READY})).To(Succeed())
+		input, err := proto.Marshal(&pb.ChaincodeInput{Args: [][]byte{[]byte("echo")}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stream.Send(&pb.ChaincodeMessage{
+			Type:      pb.ChaincodeMessage_TRANSACTION,
+			Txid:      "txid",
+			ChannelId: "channel",
+			Payload:   input,
+		})).To(Succeed())
+
+		msg, err = stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Type).To(Equal(pb.ChaincodeMessage_COMPLETED))
+		Expect(msg.Txid).To(Equal("txid"))
+		resp := &pb.Response{}
+		Expect(proto.Unmarshal(msg.Payload, resp)).To(Succeed())
+		Expect(resp.Status).To(Equal(int32(200)))
+		Expect(resp.Payload).To(Equal([]byte("echo")))
+
+		Expect(instance.Stop()).To(Succeed())
+		code, err := instance.Wait()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(0))
+	})
+
+	It("exits with an error when the peer disconnects", func() {
+		err := instance.Start(&ccintf.PeerConnection{Address: listener.Addr().String()})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(ccSupport.streams).Should(Receive())
+		close(ccSupport.done)
+
+		code, err := instance.Wait()
+		Expect(code).To(Equal(1))
+		Expect(err).To(MatchError(ContainSubstring("chaincode cc:1 disconnected")))
+	})
+
+	It("returns no chaincode server info", func() {
+		info, err := instance.ChaincodeServerInfo()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info).To(BeNil())
+	})
+
+	Context("when the instance has not been started", func() {
+		It("cannot be stopped or waited for", func() {
+			Expect(instance.Stop()).To(MatchError("instance has not been started"))
+			_, err := instance.Wait()
+			Expect(err).To(MatchError("instance has not been started"))
+		})
+	})
+})
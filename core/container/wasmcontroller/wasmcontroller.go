@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	wasmplatform "github.com/hyperledger/fabric/core/chaincode/platforms/wasm"
+	"github.com/hyperledger/fabric/core/container"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
+	"github.com/pkg/errors"
+)
+
+var wasmLogger = flogging.MustGetLogger("wasmcontroller")
+
+// WasmVM runs chaincode compiled to WebAssembly inside the peer. Each
+// transaction is executed by a fresh instance of the module, bounded by the
+// configured gas, memory and call depth.
+type WasmVM struct {
+	Config wasm.Config
+}
+
+// Build compiles the module of WebAssembly chaincode. For other chaincode
+// nil is returned so that another builder can be used.
+func (vm *WasmVM) Build(ccid string, metadata *persistence.ChaincodePackageMetadata, codePackage io.Reader) (container.Instance, error) {
+	if strings.ToUpper(metadata.Type) != wasmplatform.Name {
+		wasmLogger.Debugf("chaincode %s of type %s is not a wasm module", ccid, metadata.Type)
+		return nil, nil
+	}
+
+	code, err := readModule(codePackage)
+	if err != nil {
+		return nil, err
+	}
+	module, err := wasm.Compile(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(module); err != nil {
+		return nil, errors.WithMessagef(err, "chaincode %s is not supported", ccid)
+	}
+
+	wasmLogger.Debugf("compiled wasm chaincode %s", ccid)
+	return &Instance{
+		CCID:      ccid,
+		Chaincode: &Chaincode{Module: module, Config: vm.Config},
+	}, nil
+}
+
+// readModule reads the module from the code package.
+func readModule(codePackage io.Reader) ([]byte, error) {
+	gr, err := gzip.NewReader(codePackage)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read chaincode package")
+	}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.Errorf("no %s found in the chaincode package", wasmplatform.ModulePath)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read chaincode package")
+		}
+		if header.Name == wasmplatform.ModulePath {
+			code, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read %s", wasmplatform.ModulePath)
+			}
+			return code, nil
+		}
+	}
+}
+
+// validate checks that the module only imports host functions and exports
+// the invoke function.
+func validate(module *wasm.Module) error {
+	for _, imp := range module.Imports() {
+		t, ok := hostFunctionTypes[imp.Name]
+		if imp.Module != HostModule || !ok {
+			return errors.Errorf("unknown import %s.%s", imp.Module, imp.Name)
+		}
+		if !t.Equal(imp.Type) {
+			return errors.Errorf("import %s.%s has wrong signature", imp.Module, imp.Name)
+		}
+	}
+
+	for _, name := range []string{InvokeFunction, InitFunction} {
+		t, ok := module.ExportedFunc(name)
+		if !ok && name == InitFunction {
+			continue
+		}
+		if !ok {
+			return errors.Errorf("function %s is not exported", name)
+		}
+		if !t.Equal(entryType) {
+			return errors.Errorf("function %s must take no arguments and return an i32", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmcontroller_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/core/container/wasmcontroller"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
+	. "github.com/hyperledger/fabric/internal/pkg/wasm/wasmtest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Host functions imported by the test modules, by index.
+const (
+	argCount uint32 = iota
+	arg
+	resultRead
+	txID
+	channelID
+	getState
+	putState
+	delState
+	getStateRange
+	getPrivateData
+	putPrivateData
+	delPrivateData
+	getPrivateDataRange
+	getTransient
+	iteratorNext
+	iteratorClose
+	setEvent
+	setResponse
+	log
+)
+
+var hostImports = []Import{
+	hostImport("arg_count", 0, 1),
+	hostImport("arg", 1, 1),
+	hostImport("result_read", 1, 0),
+	hostImport("tx_id", 0, 1),
+	hostImport("channel_id", 0, 1),
+	hostImport("get_state", 2, 1),
+	hostImport("put_state", 4, 0),
+	hostImport("del_state", 2, 0),
+	hostImport("get_state_range", 4, 1),
+	hostImport("get_private_data", 4, 1),
+	hostImport("put_private_data", 6, 0),
+	hostImport("del_private_data", 4, 0),
+	hostImport("get_private_data_range", 6, 1),
+	hostImport("get_transient", 2, 1),
+	hostImport("iterator_next", 1, 1),
+	hostImport("iterator_close", 1, 0),
+	hostImport("set_event", 4, 0),
+	hostImport("set_response", 2, 0),
+	hostImport("log", 2, 0),
+}
+
+func hostImport(name string, params, results int) Import {
+	return Import{
+		Module:  wasmcontroller.HostModule,
+		Name:    name,
+		Params:  bytes.Repeat([]byte{I32}, params),
+		Results: bytes.Repeat([]byte{I32}, results),
+	}
+}
+
+// chaincodeModule returns a module importing all host functions and
+// exporting invoke with the code and number of locals given.
+func chaincodeModule(locals int, code []byte, data ...Data) *Module {
+	return &Module{
+		Imports: hostImports,
+		Funcs: []Func{
+			{Export: "invoke", Results: []byte{I32}, Locals: locals, Code: code},
+		},
+		Memory: 1,
+		Data:   data,
+	}
+}
+
+func compile(m *Module) *wasm.Module {
+	module, err := wasm.Compile(m.Bytes())
+	Expect(err).NotTo(HaveOccurred())
+	return module
+}
+
+type packageFile struct {
+	name     string
+	contents []byte
+}
+
+func codePackage(files ...packageFile) *bytes.Buffer {
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0100644, Size: int64(len(f.contents))})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tw.Write(f.contents)
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gw.Close()).To(Succeed())
+	return payload
+}
+
+var _ = Describe("WasmVM", func() {
+	var (
+		vm       *wasmcontroller.WasmVM
+		metadata *persistence.ChaincodePackageMetadata
+	)
+
+	BeforeEach(func() {
+		vm = &wasmcontroller.WasmVM{Config: wasm.Config{Gas: 1000}}
+		metadata = &persistence.ChaincodePackageMetadata{Type: "wasm", Path: "cc.wasm"}
+	})
+
+	It("compiles the module of wasm chaincode", func() {
+		module := chaincodeModule(0, I32Const(0)).Bytes()
+		instance, err := vm.Build("cc:1", metadata, codePackage(
+			packageFile{"META-INF/statedb/couchdb/indexes/index.json", []byte("{}")},
+			packageFile{"src/chaincode.wasm", module},
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance).To(BeAssignableToTypeOf(&wasmcontroller.Instance{}))
+
+		wasmInstance := instance.(*wasmcontroller.Instance)
+		Expect(wasmInstance.CCID).To(Equal("cc:1"))
+		Expect(wasmInstance.Chaincode).To(BeAssignableToTypeOf(&wasmcontroller.Chaincode{}))
+		Expect(wasmInstance.Chaincode.(*wasmcontroller.Chaincode).Config).To(Equal(wasm.Config{Gas: 1000}))
+	})
+
+	Context("when the chaincode is not wasm", func() {
+		BeforeEach(func() {
+			metadata.Type = "golang"
+		})
+
+		It("returns no instance", func() {
+			instance, err := vm.Build("cc:1", metadata, bytes.NewBuffer(nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance).To(BeNil())
+		})
+	})
+
+	Context("when the code package is not gzipped", func() {
+		It("returns an error", func() {
+			_, err := vm.Build("cc:1", metadata, bytes.NewBufferString("this is not a code package"))
+			Expect(err).To(MatchError("could not read chaincode package: gzip: invalid header"))
+		})
+	})
+
+	Context("when the code package has no module", func() {
+		It("returns an error", func() {
+			_, err := vm.Build("cc:1", metadata, codePackage())
+			Expect(err).To(MatchError("no src/chaincode.wasm found in the chaincode package"))
+		})
+	})
+
+	Context("when the module is invalid", func() {
+		It("returns an error", func() {
+			_, err := vm.Build("cc:1", metadata, codePackage(packageFile{"src/chaincode.wasm", []byte("garbage")}))
+			Expect(err).To(MatchError("invalid wasm module: missing magic number"))
+		})
+	})
+
+	whenUnsupported := func(description string, module *Module, errMsg string) {
+		Context(description, func() {
+			It("returns an error", func() {
+				_, err := vm.Build("cc:1", metadata, codePackage(packageFile{"src/chaincode.wasm", module.Bytes()}))
+				Expect(err).To(MatchError(errMsg))
+			})
+		})
+	}
+
+	whenUnsupported("when the module imports an unknown function",
+		&Module{
+			Imports: []Import{{Module: "env", Name: "abort"}},
+			Funcs:   []Func{{Export: "invoke", Results: []byte{I32}, Code: I32Const(0)}},
+		},
+		"chaincode cc:1 is not supported: unknown import env.abort",
+	)
+
+	whenUnsupported("when a host function is imported with the wrong signature",
+		&Module{
+			Imports: []Import{{Module: "fabric", Name: "get_state", Params: []byte{I64}}},
+			Funcs:   []Func{{Export: "invoke", Results: []byte{I32}, Code: I32Const(0)}},
+		},
+		"chaincode cc:1 is not supported: import fabric.get_state has wrong signature",
+	)
+
+	whenUnsupported("when the module does not export invoke",
+		&Module{Funcs: []Func{{Export: "run", Results: []byte{I32}, Code: I32Const(0)}}},
+		"chaincode cc:1 is not supported: function invoke is not exported",
+	)
+
+	whenUnsupported("when invoke has the wrong signature",
+		&Module{Funcs: []Func{{Export: "invoke"}}},
+		"chaincode cc:1 is not supported: function invoke must take no arguments and return an i32",
+	)
+
+	whenUnsupported("when init has the wrong signature",
+		&Module{Funcs: []Func{
+			{Export: "invoke", Results: []byte{I32}, Code: I32Const(0)},
+			{Export: "init", Params: []byte{I32}, Results: []byte{I32}, Code: I32Const(0)},
+		}},
+		"chaincode cc:1 is not supported: function init must take no arguments and return an i32",
+	)
+})
@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser
+
+import (
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// endorseCrossChannelInvocations endorses the writes of the chaincodes
+// invoked on other channels as separate transactions on those channels.
+func (e *Endorser) endorseCrossChannelInvocations(up *UnpackedProposal, invocations *ccprovider.CrossChannelInvocations) ([]*msgs.CrossChannelResponse, error) {
+	var responses []*msgs.CrossChannelResponse
+	for _, inv := range invocations.Invocations() {
+		resp, err := e.endorseCrossChannelInvocation(up, inv)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to endorse the invocation of chaincode %s on channel %s", inv.ChaincodeName, inv.ChannelID)
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+func (e *Endorser) endorseCrossChannelInvocation(up *UnpackedProposal, inv *ccprovider.CrossChannelInvocation) (*msgs.CrossChannelResponse, error) {
+	defer inv.TXSimulator.Done()
+
+	cdLedger, err := e.Support.ChaincodeEndorsementInfo(inv.ChannelID, inv.ChaincodeName, inv.TXSimulator)
+	if err != nil {
+		return nil, err
+	}
+
+	simResult, err := inv.TXSimulator.GetTxSimulationResults()
+	if err != nil {
+		return nil, err
+	}
+	if simResult.PvtSimulationResults != nil {
+		return nil, errors.New("private data cannot be written by a chaincode invoked from another channel")
+	}
+	pubSimResBytes, err := proto.Marshal(simResult.PubSimulationResults)
+	if err != nil {
+		return nil, err
+	}
+
+	prop, propHash, err := crossChannelProposal(up, inv)
+	if err != nil {
+		return nil, err
+	}
+
+	prpBytes, err := protoutil.GetBytesProposalResponsePayload(propHash, inv.Response, pubSimResBytes, nil, &pb.ChaincodeID{
+		Name:    inv.ChaincodeName,
+		Version: cdLedger.Version,
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create the proposal response")
+	}
+
+	endorsement, mPrpBytes, err := e.Support.EndorseWithPlugin(cdLedger.EndorsementPlugin, inv.ChannelID, prpBytes, up.SignedProposal)
+	if err != nil {
+		return nil, errors.WithMessage(err, "endorsing with plugin failed")
+	}
+
+	propBytes, err := proto.Marshal(prop)
+	if err != nil {
+		return nil, err
+	}
+	respBytes, err := proto.Marshal(&pb.ProposalResponse{
+		Version:     1,
+		Endorsement: endorsement,
+		Payload:     mPrpBytes,
+		Response:    inv.Response,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &msgs.CrossChannelResponse{
+		ChannelId:        inv.ChannelID,
+		Proposal:         propBytes,
+		ProposalResponse: respBytes,
+	}, nil
+}
+
+// crossChannelProposal creates the proposal of the transaction carrying the
+// writes of a chaincode invoked on another channel, and its hash. It has the
+// transaction ID, timestamp and signature header of the proposal it was
+// invoked from so that every endorser creates the same proposal and the
+// client can submit it as a transaction.
+func crossChannelProposal(up *UnpackedProposal, inv *ccprovider.CrossChannelInvocation) (*pb.Proposal, []byte, error) {
+	hdr, err := protoutil.UnmarshalHeader(up.Proposal.Header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ccHdrExt, err := proto.Marshal(&pb.ChaincodeHeaderExtension{
+		ChaincodeId: &pb.ChaincodeID{Name: inv.ChaincodeName},
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error marshaling ChaincodeHeaderExtension")
+	}
+	chdr := proto.Clone(up.ChannelHeader).(*cb.ChannelHeader)
+	chdr.ChannelId = inv.ChannelID
+	chdr.Extension = ccHdrExt
+	chdrBytes, err := proto.Marshal(chdr)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error marshaling ChannelHeader")
+	}
+	crossChannelHdr := &cb.Header{
+		ChannelHeader:   chdrBytes,
+		SignatureHeader: hdr.SignatureHeader,
+	}
+
+	input := proto.Clone(inv.Input).(*pb.ChaincodeInput)
+	input.Decorations = nil
+	cisBytes, err := proto.Marshal(&pb.ChaincodeInvocationSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			ChaincodeId: &pb.ChaincodeID{Name: inv.ChaincodeName},
+			Input:       input,
+		},
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error marshaling ChaincodeInvocationSpec")
+	}
+	ccPropPayloadBytes, err := proto.Marshal(&pb.ChaincodeProposalPayload{Input: cisBytes})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error marshaling ChaincodeProposalPayload")
+	}
+
+	propHash, err := protoutil.GetProposalHash2(crossChannelHdr, ccPropPayloadBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	hdrBytes, err := proto.Marshal(crossChannelHdr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &pb.Proposal{Header: hdrBytes, Payload: ccPropPayloadBytes}, propHash, nil
+}
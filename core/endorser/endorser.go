@@ -19,6 +19,8 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
 	xendorser "github.com/hyperledger/fabric/extensions/endorser"
@@ -102,6 +104,8 @@ type Endorser struct {
 	PvtRWSetAssembler      PvtRWSetAssembler
 	Metrics                *Metrics
 	SkipCheckForDupTxnID   bool
+	// Recorder records the chaincode executions of endorsements, if set
+	Recorder *recording.Recorder
 }
 
 var rwSetFilter = xendorser.NewCollRWSetFilter()
@@ -374,6 +378,16 @@ func (e *Endorser) ProcessProposalSuccessfullyOrError(up *UnpackedProposal) (*pb
 
 	logger := decorateLogger(endorserLogger, txParams)
 
+	if up.ChannelID() != "" {
+		txParams.CrossChannelInvocations = &ccprovider.CrossChannelInvocations{ChannelID: up.ChannelID()}
+		// releases the simulators of the invocations which are not endorsed
+		defer txParams.CrossChannelInvocations.Done()
+	}
+
+	if e.Recorder != nil && up.ChannelID() != "" {
+		txParams.Recording = e.Recorder.NewTransaction(up.ChannelID(), up.TxID())
+	}
+
 	if acquireTxSimulator(up.ChannelHeader.ChannelId, up.ChaincodeName) {
 		txSim, err := e.Support.GetTxSimulator(up.ChannelID(), up.TxID())
 		if err != nil {
@@ -405,6 +419,9 @@ func (e *Endorser) ProcessProposalSuccessfullyOrError(up *UnpackedProposal) (*pb
 
 	// 1 -- simulate
 	res, simulationResult, ccevent, err := e.SimulateProposal(txParams, up.ChaincodeName, up.Input)
+	if txParams.Recording != nil {
+		e.record(logger, txParams.Recording, simulationResult)
+	}
 	if err != nil {
 		return nil, errors.WithMessage(err, "error in simulation")
 	}
@@ -463,12 +480,37 @@ func (e *Endorser) ProcessProposalSuccessfullyOrError(up *UnpackedProposal) (*pb
 		return nil, errors.WithMessage(err, "endorsing with plugin failed")
 	}
 
-	return &pb.ProposalResponse{
+	pResp := &pb.ProposalResponse{
 		Version:     1,
 		Endorsement: endorsement,
 		Payload:     mPrpBytes,
 		Response:    res,
-	}, nil
+	}
+
+	// the writes of chaincodes invoked on other channels are endorsed as
+	// separate transactions which are returned along with the response
+	crossChannelResponses, err := e.endorseCrossChannelInvocations(up, txParams.CrossChannelInvocations)
+	if err != nil {
+		meterLabels = append(meterLabels, "chaincodeerror", strconv.FormatBool(false))
+		e.Metrics.EndorsementsFailed.With(meterLabels...).Add(1)
+		return nil, err
+	}
+	if err := msgs.AddCrossChannelResponses(pResp, crossChannelResponses); err != nil {
+		return nil, err
+	}
+
+	return pResp, nil
+}
+
+// record writes the recording of the chaincode executions of an endorsement.
+// Failing to record does not fail the endorsement.
+func (e *Endorser) record(logger *flogging.FabricLogger, tx *recording.Transaction, simulationResult []byte) {
+	path, err := e.Recorder.Write(tx, simulationResult)
+	if err != nil {
+		logger.Warningf("failed to record endorsement: %s", err)
+		return
+	}
+	logger.Debugf("recorded endorsement in %s", path)
 }
 
 // determine whether or not a transaction simulator should be
@@ -9,6 +9,9 @@ package endorser_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -20,6 +23,9 @@ import (
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
 	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/endorser"
 	"github.com/hyperledger/fabric/core/endorser/fake"
 	"github.com/hyperledger/fabric/core/ledger"
@@ -705,6 +711,178 @@ var _ = Describe("Endorser", func() {
 		})
 	})
 
+	Context("when endorsements are recorded", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir("", "recording")
+			Expect(err).NotTo(HaveOccurred())
+			e.Recorder = &recording.Recorder{Path: tempDir}
+
+			fakeTxSimulator.GetTxSimulationResultsReturns(
+				&ledger.TxSimulationResults{
+					PubSimulationResults: &rwset.TxReadWriteSet{
+						NsRwset: []*rwset.NsReadWriteSet{{Namespace: "chaincode-name", Rwset: []byte("rwset")}},
+					},
+				},
+				nil,
+			)
+			fakeSupport.ExecuteStub = func(txParams *ccprovider.TransactionParams, name string, input *pb.ChaincodeInput) (*pb.Response, *pb.ChaincodeEvent, error) {
+				Expect(txParams.Recording).NotTo(BeNil())
+				txParams.Recording.StartExecution(name, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION})
+				return chaincodeResponse, chaincodeEvent, nil
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tempDir)
+		})
+
+		It("writes the recording along with the simulation results", func() {
+			_, err := e.ProcessProposal(context.Background(), signedProposal)
+			Expect(err).NotTo(HaveOccurred())
+
+			rec, err := recording.ReadFile(filepath.Join(tempDir, "channel-id", "6f142589e4ef6a1e62c9c816e2074f70baa9f7cf67c2f0c287d4ef907d6d2015.rec"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rec.ChannelId).To(Equal("channel-id"))
+			Expect(rec.Executions).To(HaveLen(1))
+			Expect(rec.Executions[0].ChaincodeName).To(Equal("chaincode-name"))
+			results := &rwset.TxReadWriteSet{}
+			Expect(proto.Unmarshal(rec.Results, results)).To(Succeed())
+			Expect(results.NsRwset).To(HaveLen(1))
+			Expect(results.NsRwset[0].Namespace).To(Equal("chaincode-name"))
+		})
+
+		Context("when the recording cannot be written", func() {
+			BeforeEach(func() {
+				e.Recorder.Path = filepath.Join(tempDir, "file")
+				Expect(ioutil.WriteFile(e.Recorder.Path, nil, 0600)).To(Succeed())
+			})
+
+			It("endorses the proposal", func() {
+				proposalResponse, err := e.ProcessProposal(context.Background(), signedProposal)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(proposalResponse.Endorsement).NotTo(BeNil())
+			})
+		})
+	})
+
+	Context("when a chaincode is invoked with writes on another channel", func() {
+		var fakeCrossChannelSimulator *fake.TxSimulator
+
+		BeforeEach(func() {
+			fakeCrossChannelSimulator = &fake.TxSimulator{}
+			fakeCrossChannelSimulator.GetTxSimulationResultsReturns(
+				&ledger.TxSimulationResults{
+					PubSimulationResults: &rwset.TxReadWriteSet{
+						NsRwset: []*rwset.NsReadWriteSet{{Namespace: "other-chaincode", Rwset: []byte("rwset")}},
+					},
+				},
+				nil,
+			)
+
+			fakeSupport.ExecuteStub = func(txParams *ccprovider.TransactionParams, name string, input *pb.ChaincodeInput) (*pb.Response, *pb.ChaincodeEvent, error) {
+				err := txParams.CrossChannelInvocations.Add(&ccprovider.CrossChannelInvocation{
+					ChannelID:     "other-channel",
+					ChaincodeName: "other-chaincode",
+					Input:         &pb.ChaincodeInput{Args: [][]byte{[]byte("transfer")}},
+					Response:      &pb.Response{Status: 200, Payload: []byte("other-payload")},
+					TXSimulator:   fakeCrossChannelSimulator,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				return chaincodeResponse, chaincodeEvent, nil
+			}
+		})
+
+		It("endorses the writes as a separate transaction on the other channel", func() {
+			proposalResponse, err := e.ProcessProposal(context.Background(), signedProposal)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proposalResponse.Endorsement).NotTo(BeNil())
+
+			responses, err := msgs.GetCrossChannelResponses(proposalResponse)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].ChannelId).To(Equal("other-channel"))
+
+			up, err := endorser.UnpackProposal(&pb.SignedProposal{ProposalBytes: responses[0].Proposal})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(up.ChannelID()).To(Equal("other-channel"))
+			Expect(up.TxID()).To(Equal("6f142589e4ef6a1e62c9c816e2074f70baa9f7cf67c2f0c287d4ef907d6d2015"))
+			Expect(up.ChaincodeName).To(Equal("other-chaincode"))
+			Expect(up.Input.Args).To(Equal([][]byte{[]byte("transfer")}))
+			Expect(up.SignatureHeader.Nonce).To(Equal([]byte("nonce")))
+
+			resp := &pb.ProposalResponse{}
+			Expect(proto.Unmarshal(responses[0].ProposalResponse, resp)).To(Succeed())
+			Expect(resp.Version).To(Equal(int32(1)))
+			Expect(resp.Payload).To(Equal([]byte("endorser-modified-payload")))
+			Expect(proto.Equal(resp.Response, &pb.Response{Status: 200, Payload: []byte("other-payload")})).To(BeTrue())
+
+			Expect(fakeSupport.ChaincodeEndorsementInfoCallCount()).To(Equal(2))
+			channelID, name, qe := fakeSupport.ChaincodeEndorsementInfoArgsForCall(1)
+			Expect(channelID).To(Equal("other-channel"))
+			Expect(name).To(Equal("other-chaincode"))
+			Expect(qe).To(Equal(fakeCrossChannelSimulator))
+
+			Expect(fakeSupport.EndorseWithPluginCallCount()).To(Equal(2))
+			pluginName, cid, propRespPayloadBytes, sp := fakeSupport.EndorseWithPluginArgsForCall(1)
+			Expect(pluginName).To(Equal("plugin-name"))
+			Expect(cid).To(Equal("other-channel"))
+			Expect(sp).To(Equal(signedProposal))
+
+			prp := &pb.ProposalResponsePayload{}
+			Expect(proto.Unmarshal(propRespPayloadBytes, prp)).To(Succeed())
+			Expect(prp.ProposalHash).To(Equal(up.ProposalHash))
+			ccAct := &pb.ChaincodeAction{}
+			Expect(proto.Unmarshal(prp.Extension, ccAct)).To(Succeed())
+			Expect(ccAct.ChaincodeId).To(Equal(&pb.ChaincodeID{Name: "other-chaincode", Version: "chaincode-definition-version"}))
+			Expect(ccAct.Results).To(Equal(protoutil.MarshalOrPanic(&rwset.TxReadWriteSet{
+				NsRwset: []*rwset.NsReadWriteSet{{Namespace: "other-chaincode", Rwset: []byte("rwset")}},
+			})))
+
+			Expect(fakeCrossChannelSimulator.DoneCallCount()).To(BeNumerically(">", 0))
+		})
+
+		Context("when the chaincode writes private data", func() {
+			BeforeEach(func() {
+				fakeCrossChannelSimulator.GetTxSimulationResultsReturns(
+					&ledger.TxSimulationResults{
+						PubSimulationResults: &rwset.TxReadWriteSet{},
+						PvtSimulationResults: &rwset.TxPvtReadWriteSet{},
+					},
+					nil,
+				)
+			})
+
+			It("returns an error", func() {
+				proposalResponse, err := e.ProcessProposal(context.Background(), signedProposal)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(proposalResponse.Response).To(Equal(&pb.Response{
+					Status:  500,
+					Message: "failed to endorse the invocation of chaincode other-chaincode on channel other-channel: private data cannot be written by a chaincode invoked from another channel",
+				}))
+				Expect(fakeCrossChannelSimulator.DoneCallCount()).To(BeNumerically(">", 0))
+			})
+		})
+
+		Context("when the chaincode response is an error", func() {
+			BeforeEach(func() {
+				chaincodeResponse.Status = 500
+			})
+
+			It("releases the simulator without endorsing the writes", func() {
+				proposalResponse, err := e.ProcessProposal(context.Background(), signedProposal)
+				Expect(err).NotTo(HaveOccurred())
+				responses, err := msgs.GetCrossChannelResponses(proposalResponse)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(responses).To(BeEmpty())
+				Expect(fakeSupport.EndorseWithPluginCallCount()).To(Equal(0))
+				Expect(fakeCrossChannelSimulator.DoneCallCount()).To(Equal(1))
+			})
+		})
+	})
+
 	Context("when the proposal is malformed", func() {
 		JustBeforeEach(func() {
 			signedProposal = &pb.SignedProposal{
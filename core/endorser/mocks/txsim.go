@@ -66,6 +66,10 @@ func (m *MockTxSim) DeletePrivateData(namespace, collection, key string) error {
 	return nil
 }
 
+func (m *MockTxSim) PurgePrivateData(namespace, collection, key string) error {
+	return nil
+}
+
 func (m *MockTxSim) ExecuteQueryOnPrivateData(namespace, collection, query string) (commonledger.ResultsIterator, error) {
 	return nil, nil
 }
@@ -12,8 +12,8 @@ import (
 	"github.com/hyperledger/fabric/core/ledger"
 )
 
-// collElgNotifier listens for the chaincode events and determines whether the peer has become eligible for one or more existing
-// private data collections and notifies the registered listener
+// collElgNotifier listens for the chaincode events and determines whether the peer has become eligible, or has lost the
+// eligibility, for one or more existing private data collections and notifies the registered listener
 type collElgNotifier struct {
 	deployedChaincodeInfoProvider ledger.DeployedChaincodeInfoProvider
 	membershipInfoProvider        ledger.MembershipInfoProvider
@@ -41,10 +41,14 @@ func (n *collElgNotifier) InterestedInNamespaces() []string {
 // 1) Retrieves the existing collection configurations and new collection configurations
 // 2) Computes the collections for which the peer is not eligible as per the existing collection configuration
 //    but is eligible as per the new collection configuration
+// 3) Computes the collections for which the peer is eligible as per the existing collection configuration
+//    but is not eligible as per the new collection configuration
 // Finally, it causes an invocation to function 'ProcessCollsEligibilityEnabled' on ledger store with a map {ns:colls}
-// that contains the details of <ns, coll> combination for which the eligibility of the peer is switched on.
+// that contains the details of <ns, coll> combination for which the eligibility of the peer is switched on and
+// to function 'ProcessCollsEligibilityDisabled' with a map {ns:colls} for which the eligibility is switched off.
 func (n *collElgNotifier) HandleStateUpdates(trigger *ledger.StateUpdateTrigger) error {
 	nsCollMap := map[string][]string{}
+	elgDisabledNsCollMap := map[string][]string{}
 	qe := trigger.CommittedStateQueryExecutor
 	postCommitQE := trigger.PostCommitQueryExecutor
 
@@ -80,10 +84,29 @@ func (n *collElgNotifier) HandleStateUpdates(trigger *ledger.StateUpdateTrigger)
 		if len(elgEnabledCollNames) > 0 {
 			nsCollMap[ccName] = elgEnabledCollNames
 		}
+		elgDisabledCollNames, err := n.elgDisabledCollNames(
+			ledgerid,
+			existingCCInfo.ExplicitCollectionConfigPkg,
+			postCommitCCInfo.ExplicitCollectionConfigPkg,
+		)
+		if err != nil {
+			return err
+		}
+		logger.Debugf("[%s] collections of chaincode [%s] for which peer was eligible before and now the eligiblity is disabled - [%s]",
+			ledgerid, ccName, elgDisabledCollNames,
+		)
+		if len(elgDisabledCollNames) > 0 {
+			elgDisabledNsCollMap[ccName] = elgDisabledCollNames
+		}
 	}
 	if len(nsCollMap) > 0 {
 		n.invokeLedgerSpecificNotifier(trigger.LedgerID, trigger.CommittingBlockNum, nsCollMap)
 	}
+	if len(elgDisabledNsCollMap) > 0 {
+		// the private data of the collections is removed by the listener along with the commit of the
+		// block and hence, unlike the above, an error causes the block commit to fail
+		return n.listeners[trigger.LedgerID].ProcessCollsEligibilityDisabled(trigger.CommittingBlockNum, elgDisabledNsCollMap)
+	}
 	return nil
 }
 
@@ -127,6 +150,35 @@ func (n *collElgNotifier) elgEnabledCollNames(ledgerID string,
 	return collectionNames, nil
 }
 
+// elgDisabledCollNames returns the names of the collections for which the peer is eligible as per 'existingPkg' and is not eligible as per 'postCommitPkg'
+func (n *collElgNotifier) elgDisabledCollNames(ledgerID string,
+	existingPkg, postCommitPkg *peer.CollectionConfigPackage) ([]string, error) {
+
+	collectionNames := []string{}
+	postCommitConfMap := map[string]*peer.StaticCollectionConfig{}
+	for _, postCommitConf := range retrieveCollConfs(postCommitPkg) {
+		postCommitConfMap[postCommitConf.Name] = postCommitConf
+	}
+
+	for _, existingConf := range retrieveCollConfs(existingPkg) {
+		collName := existingConf.Name
+		postCommitConf, ok := postCommitConfMap[collName]
+		if !ok { // an existing collection cannot be removed by an upgrade
+			continue
+		}
+		membershipDisabled, err := n.elgEnabled(ledgerID, postCommitConf.MemberOrgsPolicy, existingConf.MemberOrgsPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if !membershipDisabled {
+			continue
+		}
+		// an existing member and removed now
+		collectionNames = append(collectionNames, collName)
+	}
+	return collectionNames, nil
+}
+
 // elgEnabled returns true if the peer is not eligible for a collection as per 'existingPolicy' and is eligible as per 'postCommitPolicy'
 func (n *collElgNotifier) elgEnabled(ledgerID string, existingPolicy, postCommitPolicy *peer.CollectionPolicyConfig) (bool, error) {
 	existingMember, err := n.membershipInfoProvider.AmMemberOf(ledgerID, existingPolicy)
@@ -151,6 +203,7 @@ func (n *collElgNotifier) StateCommitDone(ledgerID string) {
 
 type collElgListener interface {
 	ProcessCollsEligibilityEnabled(commitingBlk uint64, nsCollMap map[string][]string) error
+	ProcessCollsEligibilityDisabled(commitingBlk uint64, nsCollMap map[string][]string) error
 }
 
 func retrieveCollConfs(collConfPkg *peer.CollectionConfigPackage) []*peer.StaticCollectionConfig {
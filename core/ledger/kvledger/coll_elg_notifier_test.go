@@ -73,11 +73,23 @@ func TestCollElgNotifier(t *testing.T) {
 		},
 		mockCollElgListener.receivedNsCollMap,
 	)
+
+	// event triggered should only contain "coll1" as this is the only collection
+	// for which peer became from eligible to ineligible by upgrade tx
+	require.Equal(t, uint64(500), mockCollElgListener.receivedElgDisabledCommittingBlk)
+	require.Equal(t,
+		map[string][]string{
+			"cc1": {"coll1"},
+		},
+		mockCollElgListener.receivedElgDisabledNsCollMap,
+	)
 }
 
 type mockCollElgListener struct {
-	receivedCommittingBlk uint64
-	receivedNsCollMap     map[string][]string
+	receivedCommittingBlk            uint64
+	receivedNsCollMap                map[string][]string
+	receivedElgDisabledCommittingBlk uint64
+	receivedElgDisabledNsCollMap     map[string][]string
 }
 
 func (m *mockCollElgListener) ProcessCollsEligibilityEnabled(commitingBlk uint64, nsCollMap map[string][]string) error {
@@ -86,6 +98,12 @@ func (m *mockCollElgListener) ProcessCollsEligibilityEnabled(commitingBlk uint64
 	return nil
 }
 
+func (m *mockCollElgListener) ProcessCollsEligibilityDisabled(commitingBlk uint64, nsCollMap map[string][]string) error {
+	m.receivedElgDisabledCommittingBlk = commitingBlk
+	m.receivedElgDisabledNsCollMap = nsCollMap
+	return nil
+}
+
 func testutilPrepapreMockCollectionConfigPkg(collEligibilityMap map[string]bool) *peer.CollectionConfigPackage {
 	pkg := &peer.CollectionConfigPackage{}
 	for collName, isEligible := range collEligibilityMap {
@@ -10,16 +10,20 @@ import (
 	"bytes"
 
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	xledgerapi "github.com/hyperledger/fabric/extensions/ledger/api"
+	xstorageapi "github.com/hyperledger/fabric/extensions/storage/api"
 	"github.com/hyperledger/fabric/protoutil"
 )
 
 // constructValidAndInvalidPvtData computes the valid pvt data and hash mismatch list
-// from a received pvt data list of old blocks.
-func constructValidAndInvalidPvtData(reconciledPvtdata []*ledger.ReconciledPvtdata, blockStore xledgerapi.BlockStore) (
+// from a received pvt data list of old blocks. The pvt data from which the keys purged
+// since the commit of the transaction have been removed is considered as valid.
+func constructValidAndInvalidPvtData(reconciledPvtdata []*ledger.ReconciledPvtdata, blockStore xledgerapi.BlockStore,
+	pvtdataStore xstorageapi.PrivateDataStore) (
 	map[uint64][]*ledger.TxPvtData, []*ledger.PvtdataHashMismatch, error,
 ) {
 	// for each block, for each transaction, retrieve the txEnvelope to
@@ -30,7 +34,7 @@ func constructValidAndInvalidPvtData(reconciledPvtdata []*ledger.ReconciledPvtda
 	var invalidPvtData []*ledger.PvtdataHashMismatch
 
 	for _, pvtdata := range reconciledPvtdata {
-		validData, invalidData, err := findValidAndInvalidPvtdata(pvtdata, blockStore)
+		validData, invalidData, err := findValidAndInvalidPvtdata(pvtdata, blockStore, pvtdataStore)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -42,7 +46,8 @@ func constructValidAndInvalidPvtData(reconciledPvtdata []*ledger.ReconciledPvtda
 	return validPvtData, invalidPvtData, nil
 }
 
-func findValidAndInvalidPvtdata(reconciledPvtdata *ledger.ReconciledPvtdata, blockStore xledgerapi.BlockStore) (
+func findValidAndInvalidPvtdata(reconciledPvtdata *ledger.ReconciledPvtdata, blockStore xledgerapi.BlockStore,
+	pvtdataStore xstorageapi.PrivateDataStore) (
 	[]*ledger.TxPvtData, []*ledger.PvtdataHashMismatch, error,
 ) {
 	var validPvtData []*ledger.TxPvtData
@@ -58,7 +63,10 @@ func findValidAndInvalidPvtdata(reconciledPvtdata *ledger.ReconciledPvtdata, blo
 		// (2) validate passed pvtData against the pvtData hash in the tx rwset.
 		logger.Debugf("Constructing valid and invalid pvtData using rwset of blockNum:[%d], txNum:[%d]",
 			reconciledPvtdata.BlockNum, txPvtData.SeqInBlock)
-		validData, invalidData := findValidAndInvalidTxPvtData(txPvtData, txRWSet, reconciledPvtdata.BlockNum)
+		validData, invalidData, err := findValidAndInvalidTxPvtData(txPvtData, txRWSet, reconciledPvtdata.BlockNum, pvtdataStore)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		// (3) append validData to validPvtDataPvt list of this block and
 		// invalidData to invalidPvtData list
@@ -89,8 +97,9 @@ func retrieveRwsetForTx(blkNum uint64, txNum uint64, blockStore xledgerapi.Block
 	return txRWSet, nil
 }
 
-func findValidAndInvalidTxPvtData(txPvtData *ledger.TxPvtData, txRWSet *rwsetutil.TxRwSet, blkNum uint64) (
-	*ledger.TxPvtData, []*ledger.PvtdataHashMismatch,
+func findValidAndInvalidTxPvtData(txPvtData *ledger.TxPvtData, txRWSet *rwsetutil.TxRwSet, blkNum uint64,
+	pvtdataStore xstorageapi.PrivateDataStore) (
+	*ledger.TxPvtData, []*ledger.PvtdataHashMismatch, error,
 ) {
 	var invalidPvtData []*ledger.PvtdataHashMismatch
 	var toDeleteNsColl []*nsColl
@@ -98,7 +107,10 @@ func findValidAndInvalidTxPvtData(txPvtData *ledger.TxPvtData, txRWSet *rwsetuti
 	// find valid and invalid pvt data
 	for _, nsRwset := range txPvtData.WriteSet.NsPvtRwset {
 		txNum := txPvtData.SeqInBlock
-		invalidData, invalidNsColl := findInvalidNsPvtData(nsRwset, txRWSet, blkNum, txNum)
+		invalidData, invalidNsColl, err := findInvalidNsPvtData(nsRwset, txRWSet, blkNum, txNum, pvtdataStore)
+		if err != nil {
+			return nil, nil, err
+		}
 		invalidPvtData = append(invalidPvtData, invalidData...)
 		toDeleteNsColl = append(toDeleteNsColl, invalidNsColl...)
 	}
@@ -108,9 +120,9 @@ func findValidAndInvalidTxPvtData(txPvtData *ledger.TxPvtData, txRWSet *rwsetuti
 	if len(txPvtData.WriteSet.NsPvtRwset) == 0 {
 		// denotes that all namespaces had
 		// invalid pvt data
-		return nil, invalidPvtData
+		return nil, invalidPvtData, nil
 	}
-	return txPvtData, invalidPvtData
+	return txPvtData, invalidPvtData, nil
 }
 
 // Remove removes the rwset for the given <ns, coll> tuple. If after this removal,
@@ -144,8 +156,9 @@ type nsColl struct {
 	ns, coll string
 }
 
-func findInvalidNsPvtData(nsRwset *rwset.NsPvtReadWriteSet, txRWSet *rwsetutil.TxRwSet, blkNum, txNum uint64) (
-	[]*ledger.PvtdataHashMismatch, []*nsColl,
+func findInvalidNsPvtData(nsRwset *rwset.NsPvtReadWriteSet, txRWSet *rwsetutil.TxRwSet, blkNum, txNum uint64,
+	pvtdataStore xstorageapi.PrivateDataStore) (
+	[]*ledger.PvtdataHashMismatch, []*nsColl, error,
 ) {
 	var invalidPvtData []*ledger.PvtdataHashMismatch
 	var invalidNsColl []*nsColl
@@ -162,6 +175,13 @@ func findInvalidNsPvtData(nsRwset *rwset.NsPvtReadWriteSet, txRWSet *rwsetutil.T
 		}
 
 		if !bytes.Equal(util.ComputeSHA256(collPvtRwset.Rwset), rwsetHash) {
+			purged, err := isPurgedPvtData(collPvtRwset.Rwset, txRWSet.GetHashedRwSet(ns, coll), ns, coll, blkNum, txNum, pvtdataStore)
+			if err != nil {
+				return nil, nil, err
+			}
+			if purged {
+				continue
+			}
 			invalidPvtData = append(invalidPvtData, &ledger.PvtdataHashMismatch{
 				BlockNum:     blkNum,
 				TxNum:        txNum,
@@ -171,5 +191,22 @@ func findInvalidNsPvtData(nsRwset *rwset.NsPvtReadWriteSet, txRWSet *rwsetutil.T
 			invalidNsColl = append(invalidNsColl, &nsColl{ns, coll})
 		}
 	}
-	return invalidPvtData, invalidNsColl
+	return invalidPvtData, invalidNsColl, nil
+}
+
+// isPurgedPvtData returns true if the pvt data of the collection matches the hashed write-set of
+// the transaction except for the keys which were purged after the commit of the transaction
+func isPurgedPvtData(pvtRwSetBytes []byte, hashedRwSet *kvrwset.HashedRWSet, ns, coll string, blkNum, txNum uint64,
+	pvtdataStore xstorageapi.PrivateDataStore) (bool, error) {
+	missingKeyHashes, err := rwsetutil.KeyHashesMissingFromPvtRwSet(pvtRwSetBytes, hashedRwSet)
+	if err != nil || len(missingKeyHashes) == 0 {
+		return false, nil
+	}
+	for _, keyHash := range missingKeyHashes {
+		purged, err := pvtdataStore.IsPurged(ns, coll, keyHash, blkNum, txNum)
+		if err != nil || !purged {
+			return false, err
+		}
+	}
+	return true, nil
 }
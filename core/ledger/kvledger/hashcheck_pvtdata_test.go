@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
 	xtestutil "github.com/hyperledger/fabric/extensions/testutil"
 	"github.com/hyperledger/fabric/protoutil"
 	"github.com/stretchr/testify/require"
@@ -117,7 +118,7 @@ func TestConstructValidInvalidBlocksPvtData(t *testing.T) {
 		Block:          blk1,
 		PvtData:        pvtDataBlk1,
 		MissingPvtData: missingData}
-	require.NoError(t, lg.(*kvLedger).commitToPvtAndBlockStore(blockAndPvtData1))
+	require.NoError(t, lg.(*kvLedger).commitToPvtAndBlockStore(blockAndPvtData1, nil))
 
 	// construct pvtData from missing data in tx3, tx6, and tx7
 	pvtdata := []*ledger.ReconciledPvtdata{
@@ -139,7 +140,7 @@ func TestConstructValidInvalidBlocksPvtData(t *testing.T) {
 		},
 	}
 
-	blocksValidPvtData, hashMismatched, err := constructValidAndInvalidPvtData(pvtdata, lg.(*kvLedger).blockStore)
+	blocksValidPvtData, hashMismatched, err := constructValidAndInvalidPvtData(pvtdata, lg.(*kvLedger).blockStore, lg.(*kvLedger).pvtdataStore)
 	require.NoError(t, err)
 	require.Equal(t, len(expectedValidBlocksPvtData), len(blocksValidPvtData))
 	require.ElementsMatch(t, expectedValidBlocksPvtData[1], blocksValidPvtData[1])
@@ -168,13 +169,88 @@ func TestConstructValidInvalidBlocksPvtData(t *testing.T) {
 		},
 	}
 
-	blocksValidPvtData, hashMismatches, err := constructValidAndInvalidPvtData(pvtdata, lg.(*kvLedger).blockStore)
+	blocksValidPvtData, hashMismatches, err := constructValidAndInvalidPvtData(pvtdata, lg.(*kvLedger).blockStore, lg.(*kvLedger).pvtdataStore)
 	require.NoError(t, err)
 	require.Len(t, blocksValidPvtData, 0)
 
 	require.ElementsMatch(t, expectedHashMismatches, hashMismatches)
 }
 
+func TestConstructValidPvtDataOfPurgedKeys(t *testing.T) {
+	//setup extension test environment
+	_, _, destroy := xtestutil.SetupExtTestEnv()
+	defer destroy()
+	conf, cleanup := testConfig(t)
+	defer cleanup()
+	provider := testutilNewProviderWithCollectionConfig(
+		t,
+		[]*nsCollBtlConfig{{namespace: "ns-1", btlConfig: map[string]uint64{"coll-1": 0}}},
+		conf,
+	)
+	defer provider.Close()
+
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	lg, _ := provider.Create(gb)
+	defer lg.Close()
+
+	// tx0 of block1 writes two keys of which the private data is missing
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSet("ns-1", "coll-1", "key-1", []byte("value-1"))
+	builder.AddToPvtAndHashedWriteSet("ns-1", "coll-1", "key-2", []byte("value-2"))
+	simRes, err := builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	pubSimResBytes, err := proto.Marshal(simRes.PubSimulationResults)
+	require.NoError(t, err)
+	blk1 := testutil.ConstructBlock(t, 1, protoutil.BlockHeaderHash(gb.Header), [][]byte{pubSimResBytes}, false)
+	missingData := make(ledger.TxMissingPvtDataMap)
+	missingData.Add(0, "ns-1", "coll-1", true)
+	require.NoError(t, lg.(*kvLedger).commitToPvtAndBlockStore(
+		&ledger.BlockAndPvtData{Block: blk1, MissingPvtData: missingData}, nil))
+
+	// tx0 of block2 purges key-1
+	builder = rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSetForPurge("ns-1", "coll-1", "key-1")
+	simRes, err = builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	pubSimResBytes, err = proto.Marshal(simRes.PubSimulationResults)
+	require.NoError(t, err)
+	blk2 := testutil.ConstructBlock(t, 2, protoutil.BlockHeaderHash(blk1.Header), [][]byte{pubSimResBytes}, false)
+	purges := []*ledger.PvtdataPurge{
+		{Namespace: "ns-1", Collection: "coll-1", KeyHash: util.ComputeStringHash("key-1"), BlockNum: 2, TxNum: 0},
+	}
+	require.NoError(t, lg.(*kvLedger).commitToPvtAndBlockStore(&ledger.BlockAndPvtData{Block: blk2}, purges))
+
+	// the private data of tx0 without the purged key is valid
+	builder = rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSet("ns-1", "coll-1", "key-2", []byte("value-2"))
+	simRes, err = builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	pvtDataWithoutPurgedKey := &ledger.TxPvtData{SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}
+	blocksValidPvtData, hashMismatches, err := constructValidAndInvalidPvtData(
+		[]*ledger.ReconciledPvtdata{{BlockNum: 1, WriteSets: map[uint64]*ledger.TxPvtData{0: pvtDataWithoutPurgedKey}}},
+		lg.(*kvLedger).blockStore,
+		lg.(*kvLedger).pvtdataStore,
+	)
+	require.NoError(t, err)
+	require.Len(t, hashMismatches, 0)
+	require.Equal(t, map[uint64][]*ledger.TxPvtData{1: {pvtDataWithoutPurgedKey}}, blocksValidPvtData)
+
+	// the private data of tx0 without a key which was not purged is invalid
+	builder = rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSet("ns-1", "coll-1", "key-1", []byte("value-1"))
+	simRes, err = builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	pvtDataWithoutKey := &ledger.TxPvtData{SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}
+	blocksValidPvtData, hashMismatches, err = constructValidAndInvalidPvtData(
+		[]*ledger.ReconciledPvtdata{{BlockNum: 1, WriteSets: map[uint64]*ledger.TxPvtData{0: pvtDataWithoutKey}}},
+		lg.(*kvLedger).blockStore,
+		lg.(*kvLedger).pvtdataStore,
+	)
+	require.NoError(t, err)
+	require.Len(t, blocksValidPvtData, 0)
+	require.Len(t, hashMismatches, 1)
+}
+
 func produceSamplePvtdata(t *testing.T, txNum uint64, nsColls []string, values [][]byte) (*ledger.TxPvtData, []byte) {
 	builder := rwsetutil.NewRWSetBuilder()
 	for index, nsColl := range nsColls {
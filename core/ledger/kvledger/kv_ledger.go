@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validation"
 	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
 	storeapi "github.com/hyperledger/fabric/extensions/collections/api/store"
 	"github.com/hyperledger/fabric/extensions/gossip/blockpublisher"
 	xledgerapi "github.com/hyperledger/fabric/extensions/ledger/api"
@@ -64,12 +65,16 @@ type kvLedger struct {
 	// reconciliation and may be updated during a regular block commit.
 	// Hence, we use atomic value to ensure consistent read.
 	isPvtstoreAheadOfBlkstore atomic.Value
+	// collsPvtdataRemovalPending is set when the private data of one or more collections,
+	// for which the peer lost the eligibility, may be pending removal from the pvtdataStore
+	collsPvtdataRemovalPending uint32
 }
 
 type lgrInitializer struct {
 	ledgerID                 string
 	blockStore               xledgerapi.BlockStore
 	pvtdataStore             xstorageapi.PrivateDataStore
+	collElgNotifier          *collElgNotifier
 	stateDB                  *privacyenabledstate.DB
 	historyDB                *history.DB
 	configHistoryMgr         *confighistory.Mgr
@@ -96,7 +101,11 @@ func newKVLedger(initializer *lgrInitializer) (*kvLedger, error) {
 		snapshotsConfig:     initializer.snapshotsConfig,
 		PeerLedgerExtension: initializer.blockStore,
 		blockAPIsRWLock:     &sync.RWMutex{},
+		stats:               initializer.stats,
 	}
+	// the removal of the private data of the collections is not reflected
+	// in the stats till the first block commit
+	l.collsPvtdataRemovalPending = 1
 
 	btlPolicy := pvtdatapolicy.ConstructBTLPolicy(&collectionInfoRetriever{ledgerID, l, initializer.ccInfoProvider})
 
@@ -121,10 +130,12 @@ func newKVLedger(initializer *lgrInitializer) (*kvLedger, error) {
 		CustomTxProcessors:  initializer.customTxProcessors,
 		HashFunc:            rwsetHashFunc,
 		CollDataProvider:    initializer.collDataProvider,
+		PvtdataPurgeChecker: initializer.pvtdataStore,
 	}
 	if err := l.initTxMgr(txmgrInitializer); err != nil {
 		return nil, err
 	}
+	initializer.collElgNotifier.registerListener(ledgerID, l)
 
 	// btlPolicy internally uses queryexecuter and indirectly ends up using txmgr.
 	// Hence, we need to init the pvtdataStore once the txmgr is initiated.
@@ -157,8 +168,6 @@ func newKVLedger(initializer *lgrInitializer) (*kvLedger, error) {
 		return nil, err
 	}
 	l.configHistoryRetriever = initializer.configHistoryMgr.GetRetriever(ledgerID, l)
-
-	l.stats = initializer.stats
 	return l, nil
 }
 
@@ -499,7 +508,7 @@ func (l *kvLedger) CommitLegacy(pvtdataAndBlock *ledger.BlockAndPvtData, commitO
 	logger.Debugf("[%s] Committing pvtdata and block [%d] to storage", l.ledgerID, blockNo)
 	l.blockAPIsRWLock.Lock()
 	defer l.blockAPIsRWLock.Unlock()
-	if err = l.commitToPvtAndBlockStore(pvtdataAndBlock); err != nil {
+	if err = l.commitToPvtAndBlockStore(pvtdataAndBlock, pvtdataPurgesOf(txstatsInfo)); err != nil {
 		return err
 	}
 	elapsedBlockstorageAndPvtdataCommit := time.Since(startBlockstorageAndPvtdataCommit)
@@ -537,10 +546,11 @@ func (l *kvLedger) CommitLegacy(pvtdataAndBlock *ledger.BlockAndPvtData, commitO
 		elapsedCommitState,
 		txstatsInfo,
 	)
+	l.updateCollsPvtdataRemovalStats()
 	return nil
 }
 
-func (l *kvLedger) commitToPvtAndBlockStore(blockAndPvtdata *ledger.BlockAndPvtData) error {
+func (l *kvLedger) commitToPvtAndBlockStore(blockAndPvtdata *ledger.BlockAndPvtData, pvtdataPurges []*ledger.PvtdataPurge) error {
 	pvtdataStoreHt, err := l.pvtdataStore.LastCommittedBlockHeight()
 	if err != nil {
 		return err
@@ -559,7 +569,7 @@ func (l *kvLedger) commitToPvtAndBlockStore(blockAndPvtdata *ledger.BlockAndPvtD
 		// too in the pvtdataStore as we do for the publicdata in the case of blockStore.
 		// Hence, we pass all pvtData present in the block to the pvtdataStore committer.
 		pvtData, missingPvtData := constructPvtDataAndMissingData(blockAndPvtdata)
-		if err := l.pvtdataStore.Commit(blockNum, pvtData, missingPvtData); err != nil {
+		if err := l.pvtdataStore.Commit(blockNum, pvtData, missingPvtData, pvtdataPurges); err != nil {
 			return err
 		}
 	} else {
@@ -618,6 +628,29 @@ func (l *kvLedger) GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledge
 	return l.pvtdataStore.GetMissingPvtDataInfoForMostRecentBlocks(maxBlock)
 }
 
+// GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks returns the missing private data information of the
+// collections selected by the filter for the most recent `maxBlock` blocks which miss at least a private data
+// of such a collection. The missing private data for which an earlier reconciliation attempt failed is not returned
+func (l *kvLedger) GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(maxBlock int, filter ledger.MissingPvtDataFilter) (ledger.MissingPvtDataInfo, error) {
+	// as in GetMissingPvtDataInfoForMostRecentBlocks(), the missing pvtData info is
+	// not returned until the blockStore catches up with the pvtdataStore
+	if l.isPvtstoreAheadOfBlkstore.Load().(bool) {
+		return nil, nil
+	}
+	return l.pvtdataStore.GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(maxBlock, filter)
+}
+
+// GetMissingPvtDataSummary summarizes, per collection, the missing private data of eligible
+// collections that has not expired
+func (l *kvLedger) GetMissingPvtDataSummary() ([]*ledger.CollMissingPvtDataSummary, error) {
+	// as in GetMissingPvtDataInfoForMostRecentBlocks(), the missing pvtData info is
+	// not reported until the blockStore catches up with the pvtdataStore
+	if l.isPvtstoreAheadOfBlkstore.Load().(bool) {
+		return nil, nil
+	}
+	return l.pvtdataStore.GetMissingPvtDataSummary()
+}
+
 func (l *kvLedger) addBlockCommitHash(block *common.Block, updateBatchBytes []byte) {
 	var valueBytes []byte
 
@@ -687,7 +720,7 @@ func (l *kvLedger) CommitPvtDataOfOldBlocks(reconciledPvtdata []*ledger.Reconcil
 	logger.Debugf("[%s:] Comparing pvtData of [%d] old blocks against the hashes in transaction's rwset to find valid and invalid data",
 		l.ledgerID, len(reconciledPvtdata))
 
-	hashVerifiedPvtData, hashMismatches, err := constructValidAndInvalidPvtData(reconciledPvtdata, l.blockStore)
+	hashVerifiedPvtData, hashMismatches, err := constructValidAndInvalidPvtData(reconciledPvtdata, l.blockStore, l.pvtdataStore)
 	if err != nil {
 		return nil, err
 	}
@@ -727,6 +760,57 @@ func (l *kvLedger) applyValidTxPvtDataOfOldBlocks(hashVerifiedPvtData map[uint64
 	return l.txmgr.RemoveStaleAndCommitPvtDataOfOldBlocks(committedPvtData)
 }
 
+// ProcessCollsEligibilityEnabled implements function in interface collElgListener
+func (l *kvLedger) ProcessCollsEligibilityEnabled(committingBlk uint64, nsCollMap map[string][]string) error {
+	return l.pvtdataStore.ProcessCollsEligibilityEnabled(committingBlk, nsCollMap)
+}
+
+// ProcessCollsEligibilityDisabled implements function in interface collElgListener. The private data of the
+// collections is removed from the stateDB along with the commit of the block 'committingBlk' and from the
+// pvtdataStore in the background, once the block is committed
+func (l *kvLedger) ProcessCollsEligibilityDisabled(committingBlk uint64, nsCollMap map[string][]string) error {
+	logger.Infof("[%s] Removing the private data of the collections %v as the peer lost the eligibility with the block [%d]",
+		l.ledgerID, nsCollMap, committingBlk)
+	if err := l.txmgr.RemoveCollsPvtData(nsCollMap); err != nil {
+		return err
+	}
+	if err := l.pvtdataStore.ProcessCollsEligibilityDisabled(committingBlk, nsCollMap); err != nil {
+		return err
+	}
+	l.stats.updateCollsEligibilityDisabled(nsCollMap)
+	atomic.StoreUint32(&l.collsPvtdataRemovalPending, 1)
+	return nil
+}
+
+// GetCollsPvtdataRemovalInfo implements the corresponding method from interface ledger.PeerLedger
+func (l *kvLedger) GetCollsPvtdataRemovalInfo() ([]*ledger.CollPvtdataRemovalInfo, error) {
+	return l.pvtdataStore.GetCollsPvtdataRemovalInfo()
+}
+
+// updateCollsPvtdataRemovalStats updates the number of collections whose private data is yet to be
+// removed from the pvtdataStore. As the removal happens in the background, the number is refreshed
+// with every block commit till no removal is pending
+func (l *kvLedger) updateCollsPvtdataRemovalStats() {
+	if atomic.LoadUint32(&l.collsPvtdataRemovalPending) == 0 {
+		return
+	}
+	removalInfo, err := l.pvtdataStore.GetCollsPvtdataRemovalInfo()
+	if err != nil {
+		logger.Warningf("[%s] Could not retrieve the status of the removal of the private data of the collections: %s", l.ledgerID, err)
+		return
+	}
+	pending := 0
+	for _, info := range removalInfo {
+		if info.Pending {
+			pending++
+		}
+	}
+	if pending == 0 {
+		atomic.StoreUint32(&l.collsPvtdataRemovalPending, 0)
+	}
+	l.stats.updateCollsPvtdataRemovalPending(pending)
+}
+
 func (l *kvLedger) GetMissingPvtDataTracker() (ledger.MissingPvtDataTracker, error) {
 	return l, nil
 }
@@ -786,6 +870,21 @@ func (a *ccEventListenerAdaptor) HandleChaincodeDeploy(chaincodeDefinition *ledg
 	)
 }
 
+func (a *ccEventListenerAdaptor) HandleInProcChaincodeDeploy(chaincodeDefinition *ledger.ChaincodeDefinition, dbArtifacts map[string]*ccapi.DBArtifacts) error {
+	inProcListener, ok := a.legacyEventListener.(cceventmgmt.InProcChaincodeLifecycleListener)
+	if !ok {
+		return nil
+	}
+	return inProcListener.HandleInProcChaincodeDeploy(&cceventmgmt.ChaincodeDefinition{
+		Name:              chaincodeDefinition.Name,
+		Hash:              chaincodeDefinition.Hash,
+		Version:           chaincodeDefinition.Version,
+		CollectionConfigs: chaincodeDefinition.CollectionConfigs,
+	},
+		dbArtifacts,
+	)
+}
+
 func (a *ccEventListenerAdaptor) ChaincodeDeployDone(succeeded bool) {
 	a.legacyEventListener.ChaincodeDeployDone(succeeded)
 }
@@ -847,3 +946,12 @@ func constructPvtDataAndMissingData(blockAndPvtData *ledger.BlockAndPvtData) ([]
 	}
 	return pvtData, missingPvtData
 }
+
+// pvtdataPurgesOf returns the private data keys purged by the valid transactions of a block
+func pvtdataPurgesOf(txstatsInfo []*validation.TxStatInfo) []*ledger.PvtdataPurge {
+	var pvtdataPurges []*ledger.PvtdataPurge
+	for _, txstatInfo := range txstatsInfo {
+		pvtdataPurges = append(pvtdataPurges, txstatInfo.PvtdataPurges...)
+	}
+	return pvtdataPurges
+}
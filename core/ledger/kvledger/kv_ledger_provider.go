@@ -193,6 +193,7 @@ func (p *Provider) initPvtDataStoreProvider() error {
 	privateDataConfig := &pvtdatastorage.PrivateDataConfig{
 		PrivateDataConfig: p.initializer.Config.PrivateDataConfig,
 		StorePath:         PvtDataStorePath(p.initializer.Config.RootFSPath),
+		Encryptor:         p.initializer.PvtdataEncryptor,
 	}
 	pvtdataStoreProvider, err := xpvtdatastorage.NewProvider(privateDataConfig, p.initializer.Config)
 	if err != nil {
@@ -358,8 +359,6 @@ func (p *Provider) open(ledgerID string) (ledger.PeerLedger, error) {
 		return nil, err
 	}
 
-	p.collElgNotifier.registerListener(ledgerID, pvtdataStore)
-
 	// Get the versioned database (state database) for a chain/ledger
 	channelInfoProvider := &channelInfoProvider{ledgerID, blockStore, p.collElgNotifier.deployedChaincodeInfoProvider}
 	db, err := p.dbProvider.GetDBHandle(ledgerID, channelInfoProvider)
@@ -380,6 +379,7 @@ func (p *Provider) open(ledgerID string) (ledger.PeerLedger, error) {
 		ledgerID:                 ledgerID,
 		blockStore:               blockStore,
 		pvtdataStore:             pvtdataStore,
+		collElgNotifier:          p.collElgNotifier,
 		stateDB:                  db,
 		historyDB:                historyDB,
 		configHistoryMgr:         p.configHistoryMgr,
@@ -18,6 +18,8 @@ type stats struct {
 	blockAndPvtdataStoreCommitTime metrics.Histogram
 	statedbCommitTime              metrics.Histogram
 	transactionsCount              metrics.Counter
+	collsEligibilityDisabled       metrics.Counter
+	collsPvtdataRemovalPending     metrics.Gauge
 }
 
 func newStats(metricsProvider metrics.Provider) *stats {
@@ -26,6 +28,8 @@ func newStats(metricsProvider metrics.Provider) *stats {
 	stats.blockAndPvtdataStoreCommitTime = metricsProvider.NewHistogram(blockAndPvtdataStoreCommitTimeOpts)
 	stats.statedbCommitTime = metricsProvider.NewHistogram(statedbCommitTimeOpts)
 	stats.transactionsCount = metricsProvider.NewCounter(transactionCountOpts)
+	stats.collsEligibilityDisabled = metricsProvider.NewCounter(collsEligibilityDisabledOpts)
+	stats.collsPvtdataRemovalPending = metricsProvider.NewGauge(collsPvtdataRemovalPendingOpts)
 	return stats
 }
 
@@ -75,6 +79,22 @@ func (s *ledgerStats) updateTransactionsStats(
 	}
 }
 
+func (s *ledgerStats) updateCollsEligibilityDisabled(nsCollMap map[string][]string) {
+	for ns, colls := range nsCollMap {
+		for _, coll := range colls {
+			s.stats.collsEligibilityDisabled.With(
+				"channel", s.ledgerid,
+				"chaincode", ns,
+				"collection", coll,
+			).Add(1)
+		}
+	}
+}
+
+func (s *ledgerStats) updateCollsPvtdataRemovalPending(pending int) {
+	s.stats.collsPvtdataRemovalPending.With("channel", s.ledgerid).Set(float64(pending))
+}
+
 var (
 	blockProcessingTimeOpts = metrics.HistogramOpts{
 		Namespace:    "ledger",
@@ -114,4 +134,22 @@ var (
 		LabelNames:   []string{"channel", "transaction_type", "chaincode", "validation_code"},
 		StatsdFormat: "%{#fqname}.%{channel}.%{transaction_type}.%{chaincode}.%{validation_code}",
 	}
+
+	collsEligibilityDisabledOpts = metrics.CounterOpts{
+		Namespace:    "ledger",
+		Subsystem:    "",
+		Name:         "pvtdata_colls_eligibility_disabled",
+		Help:         "Number of private data collections for which the peer lost the eligibility.",
+		LabelNames:   []string{"channel", "chaincode", "collection"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{chaincode}.%{collection}",
+	}
+
+	collsPvtdataRemovalPendingOpts = metrics.GaugeOpts{
+		Namespace:    "ledger",
+		Subsystem:    "",
+		Name:         "pvtdata_colls_removal_pending",
+		Help:         "Number of private data collections whose data is yet to be removed from the private data store as the peer lost the eligibility.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
 )
@@ -179,7 +179,7 @@ func testutilConstructMetricProvider() *testMetricProvider {
 		case transactionCountOpts.Name:
 			return fakeTransactionsCount
 		}
-		return nil
+		return testutilConstructCounter()
 	}
 	return &testMetricProvider{
 		fakeProvider,
@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rwsetutil
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/pkg/errors"
+)
+
+// isPurgeFieldTag is the tag of the field 'is_purge' (field number 4, varint) of the
+// message KVWriteHash in the upstream protos. The protos in use do not declare the field
+// yet, so it is carried as an unrecognized field which is preserved when the message is
+// marshaled and, hence, covered by the endorsement signatures.
+const isPurgeFieldTag = 4<<3 | proto.WireVarint
+
+// SetPurge marks the hashed write as the purge of the key
+func SetPurge(w *kvrwset.KVWriteHash) {
+	if IsPurge(w) {
+		return
+	}
+	buf := proto.NewBuffer(nil)
+	buf.EncodeVarint(isPurgeFieldTag)
+	buf.EncodeVarint(1)
+	w.XXX_unrecognized = append(w.XXX_unrecognized, buf.Bytes()...)
+}
+
+// IsPurge returns true if the hashed write is marked as the purge of the key
+func IsPurge(w *kvrwset.KVWriteHash) bool {
+	if w == nil || len(w.XXX_unrecognized) == 0 {
+		return false
+	}
+	buf := proto.NewBuffer(w.XXX_unrecognized)
+	for {
+		tag, err := buf.DecodeVarint()
+		if err != nil {
+			return false
+		}
+		if tag == isPurgeFieldTag {
+			v, err := buf.DecodeVarint()
+			return err == nil && v != 0
+		}
+		if err := skipField(buf, tag); err != nil {
+			return false
+		}
+	}
+}
+
+func skipField(buf *proto.Buffer, tag uint64) error {
+	var err error
+	switch tag & 7 {
+	case proto.WireVarint:
+		_, err = buf.DecodeVarint()
+	case proto.WireFixed64:
+		_, err = buf.DecodeFixed64()
+	case proto.WireBytes:
+		_, err = buf.DecodeRawBytes(false)
+	case proto.WireFixed32:
+		_, err = buf.DecodeFixed32()
+	default:
+		err = errors.Errorf("unsupported wire type %d", tag&7)
+	}
+	return err
+}
+
+// PvtdataPurges returns the purges of private data keys performed by the
+// transaction when committed at the height <blkNum, txNum>
+func (txRwSet *TxRwSet) PvtdataPurges(blkNum, txNum uint64) []*ledger.PvtdataPurge {
+	var purges []*ledger.PvtdataPurge
+	for _, nsRwSet := range txRwSet.NsRwSets {
+		for _, collHashedRwSet := range nsRwSet.CollHashedRwSets {
+			if collHashedRwSet.HashedRwSet == nil {
+				continue
+			}
+			for _, hashedWrite := range collHashedRwSet.HashedRwSet.HashedWrites {
+				if !IsPurge(hashedWrite) {
+					continue
+				}
+				purges = append(purges, &ledger.PvtdataPurge{
+					Namespace:  nsRwSet.NameSpace,
+					Collection: collHashedRwSet.CollectionName,
+					KeyHash:    hashedWrite.KeyHash,
+					BlockNum:   blkNum,
+					TxNum:      txNum,
+				})
+			}
+		}
+	}
+	return purges
+}
+
+// GetHashedRwSet returns the hashed read-write set for a given namespace and collection
+func (txRwSet *TxRwSet) GetHashedRwSet(ns, coll string) *kvrwset.HashedRWSet {
+	for _, nsRwSet := range txRwSet.NsRwSets {
+		if nsRwSet.NameSpace != ns {
+			continue
+		}
+		for _, collHashedRwSet := range nsRwSet.CollHashedRwSets {
+			if collHashedRwSet.CollectionName == coll {
+				return collHashedRwSet.HashedRwSet
+			}
+		}
+	}
+	return nil
+}
+
+// KeyHashesMissingFromPvtRwSet verifies a private write-set (i.e., the serialized
+// 'kvrwset.KVRWSet') from which the keys purged after the commit of the transaction
+// may have been removed. Every write present in the private write-set has to match the
+// corresponding write of the hashed write-set. The function returns the hashes of the
+// keys written in the hashed write-set which are absent from the private write-set.
+func KeyHashesMissingFromPvtRwSet(pvtRwSetBytes []byte, hashedRwSet *kvrwset.HashedRWSet) ([][]byte, error) {
+	if hashedRwSet == nil {
+		return nil, errors.New("hashed write-set is not available")
+	}
+	pvtRwSet := &kvrwset.KVRWSet{}
+	if err := proto.Unmarshal(pvtRwSetBytes, pvtRwSet); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling private write-set")
+	}
+
+	hashedWrites := make(map[string]*kvrwset.KVWriteHash)
+	for _, w := range hashedRwSet.HashedWrites {
+		hashedWrites[string(w.KeyHash)] = w
+	}
+	hashedMetadataWrites := make(map[string]struct{})
+	for _, w := range hashedRwSet.MetadataWrites {
+		hashedMetadataWrites[string(w.KeyHash)] = struct{}{}
+	}
+
+	for _, w := range pvtRwSet.Writes {
+		keyHash := string(util.ComputeStringHash(w.Key))
+		hashedWrite, ok := hashedWrites[keyHash]
+		if !ok || hashedWrite.IsDelete != w.IsDelete ||
+			(!w.IsDelete && !bytes.Equal(hashedWrite.ValueHash, util.ComputeHash(w.Value))) {
+			return nil, errors.New("private write-set does not match the hashed write-set")
+		}
+		delete(hashedWrites, keyHash)
+	}
+	for _, w := range pvtRwSet.MetadataWrites {
+		keyHash := string(util.ComputeStringHash(w.Key))
+		if _, ok := hashedMetadataWrites[keyHash]; !ok {
+			return nil, errors.New("private metadata write-set does not match the hashed write-set")
+		}
+		delete(hashedMetadataWrites, keyHash)
+	}
+
+	var missingKeyHashes [][]byte
+	for _, w := range hashedRwSet.HashedWrites {
+		if _, ok := hashedWrites[string(w.KeyHash)]; ok {
+			missingKeyHashes = append(missingKeyHashes, w.KeyHash)
+			delete(hashedMetadataWrites, string(w.KeyHash))
+		}
+	}
+	for _, w := range hashedRwSet.MetadataWrites {
+		if _, ok := hashedMetadataWrites[string(w.KeyHash)]; ok {
+			missingKeyHashes = append(missingKeyHashes, w.KeyHash)
+		}
+	}
+	return missingKeyHashes, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rwsetutil
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeMarkerSurvivesMarshaling(t *testing.T) {
+	w := &kvrwset.KVWriteHash{KeyHash: []byte("keyHash"), IsDelete: true}
+	require.False(t, IsPurge(w))
+
+	SetPurge(w)
+	SetPurge(w)
+	require.True(t, IsPurge(w))
+
+	b, err := proto.Marshal(w)
+	require.NoError(t, err)
+	unmarshaled := &kvrwset.KVWriteHash{}
+	require.NoError(t, proto.Unmarshal(b, unmarshaled))
+	require.True(t, IsPurge(unmarshaled))
+	require.Equal(t, []byte("keyHash"), unmarshaled.KeyHash)
+	require.True(t, unmarshaled.IsDelete)
+
+	require.False(t, IsPurge(&kvrwset.KVWriteHash{XXX_unrecognized: []byte{0xff}}))
+}
+
+func TestPvtdataPurges(t *testing.T) {
+	builder := NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSet("ns1", "coll1", "key1", []byte("value1"))
+	builder.AddToPvtAndHashedWriteSetForPurge("ns1", "coll1", "key2")
+	builder.AddToPvtAndHashedWriteSetForPurge("ns2", "coll2", "key3")
+	simRes, err := builder.GetTxSimulationResults()
+	require.NoError(t, err)
+
+	pubBytes, err := simRes.GetPubSimulationBytes()
+	require.NoError(t, err)
+	txRwSet := &TxRwSet{}
+	require.NoError(t, txRwSet.FromProtoBytes(pubBytes))
+
+	require.Equal(t,
+		[]*ledger.PvtdataPurge{
+			{Namespace: "ns1", Collection: "coll1", KeyHash: util.ComputeStringHash("key2"), BlockNum: 5, TxNum: 2},
+			{Namespace: "ns2", Collection: "coll2", KeyHash: util.ComputeStringHash("key3"), BlockNum: 5, TxNum: 2},
+		},
+		txRwSet.PvtdataPurges(5, 2),
+	)
+
+	// the private write-set records the purge as a delete of the key
+	pvtRwSet, err := TxPvtRwSetFromProtoMsg(simRes.PvtSimulationResults)
+	require.NoError(t, err)
+	require.Equal(t,
+		[]*kvrwset.KVWrite{{Key: "key2", IsDelete: true}},
+		pvtRwSet.NsPvtRwSet[0].CollPvtRwSets[0].KvRwSet.Writes[1:],
+	)
+}
+
+func TestKeyHashesMissingFromPvtRwSet(t *testing.T) {
+	hashedRwSet := &kvrwset.HashedRWSet{
+		HashedWrites: []*kvrwset.KVWriteHash{
+			{KeyHash: util.ComputeStringHash("key1"), ValueHash: util.ComputeHash([]byte("value1"))},
+			{KeyHash: util.ComputeStringHash("key2"), ValueHash: util.ComputeHash([]byte("value2"))},
+			{KeyHash: util.ComputeStringHash("key3"), IsDelete: true},
+		},
+		MetadataWrites: []*kvrwset.KVMetadataWriteHash{
+			{KeyHash: util.ComputeStringHash("key2")},
+			{KeyHash: util.ComputeStringHash("key4")},
+		},
+	}
+	pvtRwSetBytes := func(kvRWSet *kvrwset.KVRWSet) []byte {
+		b, err := proto.Marshal(kvRWSet)
+		require.NoError(t, err)
+		return b
+	}
+
+	missingKeyHashes, err := KeyHashesMissingFromPvtRwSet(
+		pvtRwSetBytes(&kvrwset.KVRWSet{
+			Writes: []*kvrwset.KVWrite{
+				{Key: "key1", Value: []byte("value1")},
+				{Key: "key3", IsDelete: true},
+			},
+		}),
+		hashedRwSet,
+	)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{util.ComputeStringHash("key2"), util.ComputeStringHash("key4")}, missingKeyHashes)
+
+	_, err = KeyHashesMissingFromPvtRwSet(
+		pvtRwSetBytes(&kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: "key1", Value: []byte("wrong-value")}}}),
+		hashedRwSet,
+	)
+	require.EqualError(t, err, "private write-set does not match the hashed write-set")
+
+	_, err = KeyHashesMissingFromPvtRwSet(
+		pvtRwSetBytes(&kvrwset.KVRWSet{MetadataWrites: []*kvrwset.KVMetadataWrite{{Key: "key1"}}}),
+		hashedRwSet,
+	)
+	require.EqualError(t, err, "private metadata write-set does not match the hashed write-set")
+
+	_, err = KeyHashesMissingFromPvtRwSet([]byte("garbage"), hashedRwSet)
+	require.Contains(t, err.Error(), "error unmarshaling private write-set")
+
+	_, err = KeyHashesMissingFromPvtRwSet(nil, nil)
+	require.EqualError(t, err, "hashed write-set is not available")
+}
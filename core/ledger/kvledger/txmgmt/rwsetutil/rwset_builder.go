@@ -116,6 +116,15 @@ func (b *RWSetBuilder) AddToPvtAndHashedWriteSet(ns string, coll string, key str
 	b.getOrCreateCollHashedRwBuilder(ns, coll).writeMap[key] = kvWriteHash
 }
 
+// AddToPvtAndHashedWriteSetForPurge adds the delete of a key to the private write-set
+// and to the hashed write-set, where the hashed write is marked as the purge of the key
+func (b *RWSetBuilder) AddToPvtAndHashedWriteSetForPurge(ns, coll, key string) {
+	kvWrite, kvWriteHash := newPvtKVWriteAndHash(key, nil)
+	SetPurge(kvWriteHash)
+	b.getOrCreateCollPvtRwBuilder(ns, coll).writeMap[key] = kvWrite
+	b.getOrCreateCollHashedRwBuilder(ns, coll).writeMap[key] = kvWriteHash
+}
+
 // AddToHashedMetadataWriteSet adds a metadata to a key in the hashed write-set
 func (b *RWSetBuilder) AddToHashedMetadataWriteSet(ns, coll, key string, metadata map[string][]byte) {
 	// pvt write set just need the key; not the entire metadata. The metadata is stored only
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/queryutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validation"
 	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
 	"github.com/hyperledger/fabric/core/ledger/util"
@@ -80,6 +81,9 @@ type current struct {
 	block     *common.Block
 	batch     *privacyenabledstate.UpdateBatch
 	listeners []ledger.StateListener
+	// collsForPvtdataRemoval contains the collections {ns:colls}
+	// whose private data is removed with the commit of the block
+	collsForPvtdataRemoval map[string][]string
 }
 
 func (c *current) blockNum() uint64 {
@@ -101,6 +105,9 @@ type Initializer struct {
 	CustomTxProcessors  map[common.HeaderType]ledger.CustomTxProcessor
 	HashFunc            rwsetutil.HashFunc
 	CollDataProvider    storeapi.Provider
+	// PvtdataPurgeChecker is used to verify that the keys missing from the pvt data of a
+	// transaction were purged after its commit. If nil, such pvt data is rejected
+	PvtdataPurgeChecker validation.PvtdataPurgeChecker
 }
 
 // NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr
@@ -135,7 +142,8 @@ func NewLockBasedTxMgr(initializer *Initializer) (*LockBasedTxMgr, error) {
 		txmgr,
 		initializer.DB,
 		initializer.CustomTxProcessors,
-		initializer.HashFunc)
+		initializer.HashFunc,
+		initializer.PvtdataPurgeChecker)
 	return txmgr, nil
 }
 
@@ -560,6 +568,12 @@ func (txmgr *LockBasedTxMgr) Commit() error {
 	}
 
 	commitHeight := version.NewHeight(txmgr.current.blockNum(), txmgr.current.maxTxNumber())
+	// the removal is added to the update batch only after the expiry info is updated
+	// so that the hashed keys committed by the block are still scheduled for expiry
+	if err := txmgr.addCollsPvtdataRemovalToUpdateBatch(commitHeight); err != nil {
+		return err
+	}
+
 	txmgr.commitRWLock.Lock()
 	logger.Debugf("Write lock acquired for committing updates to state database")
 	if err := txmgr.db.ApplyPrivacyAwareUpdates(txmgr.current.batch, commitHeight); err != nil {
@@ -583,6 +597,63 @@ func (txmgr *LockBasedTxMgr) Commit() error {
 	return nil
 }
 
+// RemoveCollsPvtData causes the removal of all the private data of the given collections {ns:colls} from the
+// state database along with the commit of the block that is being validated. The hashed data of the collections
+// is retained. This function is expected to be invoked by a state listener, i.e., during the invocation of the
+// function ValidateAndPrepare, for instance, when the peer is no longer eligible for the collections
+func (txmgr *LockBasedTxMgr) RemoveCollsPvtData(nsCollMap map[string][]string) error {
+	if txmgr.current == nil {
+		return errors.New("private data of collections can be removed only during the commit of a block")
+	}
+	if txmgr.current.collsForPvtdataRemoval == nil {
+		txmgr.current.collsForPvtdataRemoval = map[string][]string{}
+	}
+	for ns, colls := range nsCollMap {
+		txmgr.current.collsForPvtdataRemoval[ns] = append(txmgr.current.collsForPvtdataRemoval[ns], colls...)
+	}
+	return nil
+}
+
+func (txmgr *LockBasedTxMgr) addCollsPvtdataRemovalToUpdateBatch(commitHeight *version.Height) error {
+	pvtUpdates := txmgr.current.batch.PvtUpdates
+	for ns, colls := range txmgr.current.collsForPvtdataRemoval {
+		for _, coll := range colls {
+			keysRemoved := 0
+			// the private data committed by the current block
+			if nsBatch, ok := pvtUpdates.UpdateMap[ns]; ok {
+				for key := range nsBatch.GetCollectionUpdates(coll) {
+					pvtUpdates.Delete(ns, coll, key, commitHeight)
+					keysRemoved++
+				}
+			}
+			// the private data committed by the previous blocks
+			itr, err := txmgr.db.GetPrivateDataRangeScanIterator(ns, coll, "", "")
+			if err != nil {
+				return err
+			}
+			for {
+				queryResult, err := itr.Next()
+				if err != nil {
+					itr.Close()
+					return err
+				}
+				if queryResult == nil {
+					break
+				}
+				key := queryResult.(*statedb.VersionedKV).Key
+				if !pvtUpdates.Contains(ns, coll, key) {
+					pvtUpdates.Delete(ns, coll, key, commitHeight)
+					keysRemoved++
+				}
+			}
+			itr.Close()
+			logger.Infof("[%s] Removing [%d] private data keys of the collection [%s:%s] from the state database",
+				txmgr.ledgerid, keysRemoved, ns, coll)
+		}
+	}
+	return nil
+}
+
 // Rollback implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) Rollback() {
 	txmgr.reset()
@@ -115,7 +115,7 @@ func (q *queryExecutor) GetStateMultipleKeys(ns string, keys []string) ([][]byte
 	}
 	versionedValues, err := q.txmgr.db.GetStateMultipleKeys(ns, keys)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 	values := make([][]byte, len(versionedValues))
 	for i, versionedValue := range versionedValues {
@@ -323,11 +323,20 @@ func (q *queryExecutor) getPrivateDataMultipleKeys(ns, coll string, keys []strin
 	}
 	versionedValues, err := q.txmgr.db.GetPrivateDataMultipleKeys(ns, coll, keys)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 	values := make([][]byte, len(versionedValues))
 	for i, versionedValue := range versionedValues {
 		val, _, ver := decomposeVersionedValue(versionedValue)
+		hashVersion, err := q.txmgr.db.GetKeyHashVersion(ns, coll, util.ComputeStringHash(keys[i]))
+		if err != nil {
+			return nil, err
+		}
+		if !version.AreSame(hashVersion, ver) {
+			return nil, &ErrPvtdataNotAvailable{Msg: fmt.Sprintf(
+				"private data matching public hash version is not available. Public hash version = %s, Private data version = %s",
+				hashVersion, ver)}
+		}
 		if q.collectReadset {
 			q.rwsetBuilder.AddToHashedReadSet(ns, coll, keys[i], ver)
 		}
@@ -1194,6 +1194,48 @@ func TestTxSimulatorMissingPvtdata(t *testing.T) {
 	require.True(t, testPvtValueEqual(t, txMgr, "ns1", "coll4", "key4", nil))
 }
 
+func TestRemoveCollsPvtData(t *testing.T) {
+	testEnv := testEnvsMap[levelDBtestEnvName]
+	testEnv.init(t, "TestRemoveCollsPvtData", nil)
+	defer testEnv.cleanup()
+	txMgr := testEnv.getTxMgr()
+	db := testEnv.getVDB()
+
+	require.EqualError(t, txMgr.RemoveCollsPvtData(map[string][]string{"ns1": {"coll1"}}),
+		"private data of collections can be removed only during the commit of a block")
+
+	updateBatch := privacyenabledstate.NewUpdateBatch()
+	for _, key := range []string{"key1", "key2"} {
+		updateBatch.HashUpdates.Put("ns1", "coll1", util.ComputeStringHash(key), util.ComputeStringHash("value"), version.NewHeight(1, 1))
+		updateBatch.PvtUpdates.Put("ns1", "coll1", key, []byte("value"), version.NewHeight(1, 1))
+	}
+	updateBatch.HashUpdates.Put("ns1", "coll2", util.ComputeStringHash("key3"), util.ComputeStringHash("value"), version.NewHeight(1, 1))
+	updateBatch.PvtUpdates.Put("ns1", "coll2", "key3", []byte("value"), version.NewHeight(1, 1))
+	require.NoError(t, db.ApplyPrivacyAwareUpdates(updateBatch, version.NewHeight(1, 1)))
+
+	// block 2 updates 'key2' and adds 'key4' to the collection 'coll1' along with the removal of its private data
+	updateBatch = privacyenabledstate.NewUpdateBatch()
+	for _, key := range []string{"key2", "key4"} {
+		updateBatch.HashUpdates.Put("ns1", "coll1", util.ComputeStringHash(key), util.ComputeStringHash("value"), version.NewHeight(2, 0))
+		updateBatch.PvtUpdates.Put("ns1", "coll1", key, []byte("value"), version.NewHeight(2, 0))
+	}
+	txMgr.current = &current{block: testutil.ConstructBlock(t, 2, nil, nil, false), batch: updateBatch}
+	require.NoError(t, txMgr.RemoveCollsPvtData(map[string][]string{"ns1": {"coll1"}}))
+	require.NoError(t, txMgr.Commit())
+
+	for _, key := range []string{"key1", "key2", "key4"} {
+		vv, err := db.GetPrivateData("ns1", "coll1", key)
+		require.NoError(t, err)
+		require.Nil(t, vv)
+		vv, err = db.GetValueHash("ns1", "coll1", util.ComputeStringHash(key))
+		require.NoError(t, err)
+		require.NotNil(t, vv)
+	}
+	vv, err := db.GetPrivateData("ns1", "coll2", "key3")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), vv.Value)
+}
+
 func TestRemoveStaleAndCommitPvtDataOfOldBlocksWithExpiry(t *testing.T) {
 	ledgerid := "TestTxSimulatorMissingPvtdataExpiry"
 	btlPolicy := btltestutil.SampleBTLPolicy(
@@ -31,12 +31,21 @@ type PostOrderSimulatorProvider interface {
 	NewTxSimulator(txid string) (ledger.TxSimulator, error)
 }
 
+// PvtdataPurgeChecker checks whether the private data keys missing from the
+// private write-set of a transaction were purged after its commit
+type PvtdataPurgeChecker interface {
+	// IsPurged returns true if the private data key, identified by its hash, was purged by a
+	// transaction committed after the height <blkNum, txNum>
+	IsPurged(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error)
+}
+
 // CommitBatchPreparer performs validation and prepares the final batch that is to be committed to the statedb
 type CommitBatchPreparer struct {
 	postOrderSimulatorProvider PostOrderSimulatorProvider
 	db                         *privacyenabledstate.DB
 	validator                  *validator
 	customTxProcessors         map[common.HeaderType]ledger.CustomTxProcessor
+	purgeChecker               PvtdataPurgeChecker
 }
 
 // TxStatInfo encapsulates information about a transaction
@@ -45,6 +54,9 @@ type TxStatInfo struct {
 	TxType         common.HeaderType
 	ChaincodeID    *peer.ChaincodeID
 	NumCollections int
+	// PvtdataPurges lists the private data keys purged by the transaction. It is
+	// set only if the transaction is valid
+	PvtdataPurges []*ledger.PvtdataPurge
 }
 
 // NewCommitBatchPreparer constructs a validator that internally manages statebased validator and in addition
@@ -54,6 +66,7 @@ func NewCommitBatchPreparer(
 	db *privacyenabledstate.DB,
 	customTxProcessors map[common.HeaderType]ledger.CustomTxProcessor,
 	hashFunc rwsetutil.HashFunc,
+	purgeChecker PvtdataPurgeChecker,
 ) *CommitBatchPreparer {
 	return &CommitBatchPreparer{
 		postOrderSimulatorProvider,
@@ -63,6 +76,7 @@ func NewCommitBatchPreparer(
 			hashFunc: hashFunc,
 		},
 		customTxProcessors,
+		purgeChecker,
 	}
 }
 
@@ -98,6 +112,7 @@ func (p *CommitBatchPreparer) ValidateAndPrepareBatch(blockAndPvtdata *ledger.Bl
 		pubAndHashUpdates,
 		blockAndPvtdata.PvtData,
 		p.customTxProcessors,
+		p.purgeChecker,
 	); err != nil {
 		return nil, nil, err
 	}
@@ -109,6 +124,11 @@ func (p *CommitBatchPreparer) ValidateAndPrepareBatch(blockAndPvtdata *ledger.Bl
 	for i := range txsFilter {
 		txsStatInfo[i].ValidationCode = txsFilter.Flag(i)
 	}
+	for _, tx := range internalBlock.txs {
+		if tx.validationCode == peer.TxValidationCode_VALID {
+			txsStatInfo[tx.indexInBlock].PvtdataPurges = tx.rwset.PvtdataPurges(blk.Header.Number, uint64(tx.indexInBlock))
+		}
+	}
 	return &privacyenabledstate.UpdateBatch{
 		PubUpdates:  pubAndHashUpdates.publicUpdates,
 		HashUpdates: pubAndHashUpdates.hashUpdates,
@@ -125,6 +145,7 @@ func validateAndPreparePvtBatch(
 	pubAndHashUpdates *publicAndHashUpdates,
 	pvtdata map[uint64]*ledger.TxPvtData,
 	customTxProcessors map[common.HeaderType]ledger.CustomTxProcessor,
+	purgeChecker PvtdataPurgeChecker,
 ) (*privacyenabledstate.PvtUpdateBatch, error) {
 
 	pvtUpdates := privacyenabledstate.NewPvtUpdateBatch()
@@ -141,7 +162,7 @@ func validateAndPreparePvtBatch(
 			continue
 		}
 		if requiresPvtdataValidation(txPvtdata) {
-			if err := validatePvtdata(tx, txPvtdata); err != nil {
+			if err := validatePvtdata(blk.num, tx, txPvtdata, purgeChecker); err != nil {
 				return nil, err
 			}
 		}
@@ -169,7 +190,7 @@ func requiresPvtdataValidation(tx *ledger.TxPvtData) bool {
 
 // validPvtdata returns true if hashes of all the collections writeset present in the pvt data
 // match with the corresponding hashes present in the public read-write set
-func validatePvtdata(tx *transaction, pvtdata *ledger.TxPvtData) error {
+func validatePvtdata(blkNum uint64, tx *transaction, pvtdata *ledger.TxPvtData, purgeChecker PvtdataPurgeChecker) error {
 	if pvtdata.WriteSet == nil {
 		return nil
 	}
@@ -179,6 +200,15 @@ func validatePvtdata(tx *transaction, pvtdata *ledger.TxPvtData) error {
 			collPvtdataHash := util.ComputeHash(collPvtdata.Rwset)
 			hashInPubdata := tx.retrieveHash(nsPvtdata.Namespace, collPvtdata.CollectionName)
 			if !bytes.Equal(collPvtdataHash, hashInPubdata) {
+				// the pvt data retrieved from the pvtdata store no longer contains the keys
+				// purged after the commit of the transaction (e.g., while rebuilding the state)
+				purged, err := isPurgedPvtdata(blkNum, tx, nsPvtdata.Namespace, collPvtdata, purgeChecker)
+				if err != nil {
+					return err
+				}
+				if purged {
+					continue
+				}
 				return errors.Errorf(`hash of pvt data for collection [%s:%s] does not match with the corresponding hash in the public data. public hash = [%#v], pvt data hash = [%#v]`,
 					nsPvtdata.Namespace, collPvtdata.CollectionName, hashInPubdata, collPvtdataHash)
 			}
@@ -187,6 +217,27 @@ func validatePvtdata(tx *transaction, pvtdata *ledger.TxPvtData) error {
 	return nil
 }
 
+// isPurgedPvtdata returns true if the pvt data of the collection matches the hashed write-set of
+// the transaction except for the keys which were purged after the commit of the transaction
+func isPurgedPvtdata(blkNum uint64, tx *transaction, ns string, collPvtdata *rwset.CollectionPvtReadWriteSet,
+	purgeChecker PvtdataPurgeChecker) (bool, error) {
+	if tx.rwset == nil || purgeChecker == nil {
+		return false, nil
+	}
+	hashedRwSet := tx.rwset.GetHashedRwSet(ns, collPvtdata.CollectionName)
+	missingKeyHashes, err := rwsetutil.KeyHashesMissingFromPvtRwSet(collPvtdata.Rwset, hashedRwSet)
+	if err != nil || len(missingKeyHashes) == 0 {
+		return false, nil
+	}
+	for _, keyHash := range missingKeyHashes {
+		purged, err := purgeChecker.IsPurged(ns, collPvtdata.CollectionName, keyHash, blkNum, uint64(tx.indexInBlock))
+		if err != nil || !purged {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 // preprocessProtoBlock parses the proto instance of block into 'Block' structure.
 // The returned 'Block' structure contains only transactions that are endorser transactions and are not already marked as invalid
 func preprocessProtoBlock(postOrderSimulatorProvider PostOrderSimulatorProvider,
@@ -224,6 +275,7 @@ func preprocessProtoBlock(postOrderSimulatorProvider PostOrderSimulatorProvider,
 
 		var txRWSet *rwsetutil.TxRwSet
 		var containsPostOrderWrites bool
+		var resolution *crossChannelResolution
 		txType := common.HeaderType(chdr.Type)
 		logger.Debugf("txType=%s", txType)
 		txStatInfo.TxType = txType
@@ -235,6 +287,7 @@ func preprocessProtoBlock(postOrderSimulatorProvider PostOrderSimulatorProvider,
 				continue
 			}
 			txStatInfo.ChaincodeID = respPayload.ChaincodeId
+			resolution = getCrossChannelResolution(payload, respPayload.ChaincodeId.GetName())
 			txRWSet = &rwsetutil.TxRwSet{}
 			if err = txRWSet.FromProtoBytes(respPayload.Results); err != nil {
 				txsFilter.SetFlag(txIndex, peer.TxValidationCode_INVALID_OTHER_REASON)
@@ -277,6 +330,7 @@ func preprocessProtoBlock(postOrderSimulatorProvider PostOrderSimulatorProvider,
 				id:                      chdr.TxId,
 				rwset:                   txRWSet,
 				containsPostOrderWrites: containsPostOrderWrites,
+				crossChannelResolution:  resolution,
 			})
 		}
 	}
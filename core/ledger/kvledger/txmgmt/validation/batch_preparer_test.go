@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package validation
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/flogging/floggingtest"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/testutil/fakes"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/internal/version"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
@@ -28,6 +30,7 @@ import (
 	lutils "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/internal/pkg/txflags"
 	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -123,7 +126,7 @@ func TestValidateAndPreparePvtBatch(t *testing.T) {
 	require.NoError(t, err)
 	addPvtRWSetToPvtUpdateBatch(tx1TxPvtRWSet, expectedPvtUpdates, version.NewHeight(uint64(10), uint64(0)))
 
-	actualPvtUpdates, err := validateAndPreparePvtBatch(mvccValidatedBlock, testDB, nil, pvtDataMap, nil)
+	actualPvtUpdates, err := validateAndPreparePvtBatch(mvccValidatedBlock, testDB, nil, pvtDataMap, nil, nil)
 	require.NoError(t, err)
 	require.Equal(t, expectedPvtUpdates, actualPvtUpdates)
 
@@ -133,6 +136,82 @@ func TestValidateAndPreparePvtBatch(t *testing.T) {
 	require.Equal(t, expectedtxsFilter, blk.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
 }
 
+type purgeCheckerFunc func(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error)
+
+func (f purgeCheckerFunc) IsPurged(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error) {
+	return f(ns, coll, keyHash, blkNum, txNum)
+}
+
+func TestValidatePvtdataWithPurgedKeys(t *testing.T) {
+	rwSetBuilder := rwsetutil.NewRWSetBuilder()
+	rwSetBuilder.AddToPvtAndHashedWriteSet("ns1", "coll1", "key1", []byte("value1"))
+	rwSetBuilder.AddToPvtAndHashedWriteSet("ns1", "coll1", "key2", []byte("value2"))
+	simulationResults, err := rwSetBuilder.GetTxSimulationResults()
+	require.NoError(t, err)
+	txRWSet, err := rwsetutil.TxRwSetFromProtoMsg(simulationResults.PubSimulationResults)
+	require.NoError(t, err)
+	tx := &transaction{indexInBlock: 3, id: "tx1", rwset: txRWSet}
+
+	// the pvt data from which key2 has been removed
+	truncatedBuilder := rwsetutil.NewRWSetBuilder()
+	truncatedBuilder.AddToPvtAndHashedWriteSet("ns1", "coll1", "key1", []byte("value1"))
+	truncatedResults, err := truncatedBuilder.GetTxSimulationResults()
+	require.NoError(t, err)
+	truncatedPvtdata := &ledger.TxPvtData{SeqInBlock: 3, WriteSet: truncatedResults.PvtSimulationResults}
+
+	key2Hash := lutils.ComputeStringHash("key2")
+	purgedKey2 := purgeCheckerFunc(func(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error) {
+		require.Equal(t, "ns1", ns)
+		require.Equal(t, "coll1", coll)
+		require.Equal(t, uint64(10), blkNum)
+		require.Equal(t, uint64(3), txNum)
+		return bytes.Equal(keyHash, key2Hash), nil
+	})
+	notPurged := purgeCheckerFunc(func(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error) {
+		return false, nil
+	})
+	failing := purgeCheckerFunc(func(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error) {
+		return false, errors.New("purge check failed")
+	})
+
+	t.Run("complete pvt data", func(t *testing.T) {
+		pvtdata := &ledger.TxPvtData{SeqInBlock: 3, WriteSet: simulationResults.PvtSimulationResults}
+		require.NoError(t, validatePvtdata(10, tx, pvtdata, nil))
+	})
+
+	t.Run("keys purged after the commit", func(t *testing.T) {
+		require.NoError(t, validatePvtdata(10, tx, truncatedPvtdata, purgedKey2))
+	})
+
+	t.Run("keys not purged", func(t *testing.T) {
+		err := validatePvtdata(10, tx, truncatedPvtdata, notPurged)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "hash of pvt data for collection [ns1:coll1] does not match with the corresponding hash in the public data")
+	})
+
+	t.Run("no purge checker", func(t *testing.T) {
+		err := validatePvtdata(10, tx, truncatedPvtdata, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "hash of pvt data for collection [ns1:coll1] does not match with the corresponding hash in the public data")
+	})
+
+	t.Run("purge check fails", func(t *testing.T) {
+		err := validatePvtdata(10, tx, truncatedPvtdata, failing)
+		require.EqualError(t, err, "purge check failed")
+	})
+
+	t.Run("tampered value", func(t *testing.T) {
+		tamperedBuilder := rwsetutil.NewRWSetBuilder()
+		tamperedBuilder.AddToPvtAndHashedWriteSet("ns1", "coll1", "key1", []byte("tampered"))
+		tamperedResults, err := tamperedBuilder.GetTxSimulationResults()
+		require.NoError(t, err)
+		pvtdata := &ledger.TxPvtData{SeqInBlock: 3, WriteSet: tamperedResults.PvtSimulationResults}
+		err = validatePvtdata(10, tx, pvtdata, purgedKey2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "hash of pvt data for collection [ns1:coll1] does not match with the corresponding hash in the public data")
+	})
+}
+
 func TestPreprocessProtoBlock(t *testing.T) {
 	allwaysValidKVfunc := func(key string, value []byte) error {
 		return nil
@@ -297,7 +376,7 @@ func TestTxStatsInfoWithConfigTx(t *testing.T) {
 	defer testDBEnv.Cleanup()
 	testDB := testDBEnv.GetDBHandle("emptydb")
 
-	v := NewCommitBatchPreparer(nil, testDB, nil, testHashFunc)
+	v := NewCommitBatchPreparer(nil, testDB, nil, testHashFunc, nil)
 
 	gb := testutil.ConstructTestBlocks(t, 1)[0]
 	_, txStatsInfo, err := v.ValidateAndPrepareBatch(&ledger.BlockAndPvtData{Block: gb}, true)
@@ -326,7 +405,7 @@ func TestTXMgrContainsPostOrderWrites(t *testing.T) {
 		common.HeaderType_CONFIG: fakeTxProcessor,
 	}
 
-	v := NewCommitBatchPreparer(mockSimulatorProvider, testDB, customTxProcessors, testHashFunc)
+	v := NewCommitBatchPreparer(mockSimulatorProvider, testDB, customTxProcessors, testHashFunc, nil)
 	blocks := testutil.ConstructTestBlocks(t, 2)
 
 	// block with config tx that produces post order writes
@@ -365,7 +444,7 @@ func TestTxStatsInfo(t *testing.T) {
 	defer testDBEnv.Cleanup()
 	testDB := testDBEnv.GetDBHandle("emptydb")
 
-	v := NewCommitBatchPreparer(nil, testDB, nil, testHashFunc)
+	v := NewCommitBatchPreparer(nil, testDB, nil, testHashFunc, nil)
 
 	// create a block with 4 endorser transactions
 	tx1SimulationResults, _ := testutilGenerateTxSimulationResultsAsBytes(t,
@@ -660,3 +739,52 @@ func Test_preprocessProtoBlock_processNonEndorserTx(t *testing.T) {
 	require.Equal(t, expectedPreprocessedBlock, internalBlock)
 	require.Equal(t, expectedTxStatInfo, txsStatInfo)
 }
+
+func TestPreprocessProtoBlockCrossChannelResolution(t *testing.T) {
+	constructTx := func(txID string, args ...string) *common.Envelope {
+		ccid := &peer.ChaincodeID{Name: "asset"}
+		input := &peer.ChaincodeInput{}
+		for _, arg := range args {
+			input.Args = append(input.Args, []byte(arg))
+		}
+		signer := &fakes.SigningIdentity{}
+		prop, _, err := protoutil.CreateChaincodeProposalWithTxIDNonceAndTransient(
+			txID,
+			common.HeaderType_ENDORSER_TRANSACTION,
+			"testchannelid",
+			&peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{ChaincodeId: ccid, Input: input}},
+			[]byte("nonce"),
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToWriteSet("asset", "key1", []byte("value1"))
+		simRes, err := rwsetBuilder.GetTxSimulationResults()
+		require.NoError(t, err)
+		pubSimBytes, err := simRes.GetPubSimulationBytes()
+		require.NoError(t, err)
+		presp, err := protoutil.CreateProposalResponse(prop.Header, prop.Payload, nil, pubSimBytes, nil, ccid, signer)
+		require.NoError(t, err)
+		env, err := protoutil.CreateSignedTx(prop, signer, presp)
+		require.NoError(t, err)
+		return env
+	}
+
+	blk := testutil.NewBlock([]*common.Envelope{
+		constructTx("tx1", "fabric.xcc.commit", "xcc-txid"),
+		constructTx("tx2", "fabric.xcc.abort", "xcc-txid"),
+		constructTx("tx3", "invoke", "xcc-txid"),
+		constructTx("tx4", "fabric.xcc.commit"),
+	}, 1, []byte("previous-hash"))
+	allwaysValidKVfunc := func(key string, value []byte) error {
+		return nil
+	}
+	b, _, err := preprocessProtoBlock(nil, allwaysValidKVfunc, blk, true, nil)
+	require.NoError(t, err)
+	require.Len(t, b.txs, 4)
+	require.Equal(t, &crossChannelResolution{namespace: "asset", txID: "xcc-txid"}, b.txs[0].crossChannelResolution)
+	require.Equal(t, &crossChannelResolution{namespace: "asset", txID: "xcc-txid"}, b.txs[1].crossChannelResolution)
+	require.Nil(t, b.txs[2].crossChannelResolution)
+	require.Nil(t, b.txs[3].crossChannelResolution)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+// crossChannelResolution identifies the locks that a transaction commits or
+// aborts, i.e., the locks acquired in a namespace by a cross-channel transaction
+type crossChannelResolution struct {
+	namespace string
+	txID      string
+}
+
+// getCrossChannelResolution returns the locks resolved by the endorser transaction
+// carried by the payload, or nil if the transaction does not commit or abort locks
+func getCrossChannelResolution(payload *common.Payload, namespace string) *crossChannelResolution {
+	tx, err := protoutil.UnmarshalTransaction(payload.Data)
+	if err != nil || len(tx.Actions) == 0 {
+		return nil
+	}
+	cap, _, err := protoutil.GetPayloads(tx.Actions[0])
+	if err != nil {
+		return nil
+	}
+	cpp, err := protoutil.UnmarshalChaincodeProposalPayload(cap.ChaincodeProposalPayload)
+	if err != nil {
+		return nil
+	}
+	cis, err := protoutil.UnmarshalChaincodeInvocationSpec(cpp.Input)
+	if err != nil {
+		return nil
+	}
+	txID := msgs.GetCrossChannelResolution(cis.GetChaincodeSpec().GetInput())
+	if txID == "" {
+		return nil
+	}
+	return &crossChannelResolution{namespace: namespace, txID: txID}
+}
+
+// validateCrossChannelLocks checks that the transaction does not access a public key locked
+// by a cross-channel transaction, i.e., that none of the keys it reads or writes, or that are
+// in the range of its range queries, carries a lock in its latest metadata. The keys locked
+// by a cross-channel transaction may only be accessed by the transaction that resolves the locks.
+func (v *validator) validateCrossChannelLocks(tx *transaction, updates *publicAndHashUpdates) (bool, error) {
+	for _, nsRWSet := range tx.rwset.NsRwSets {
+		ns := nsRWSet.NameSpace
+		var keys []string
+		for _, kvRead := range nsRWSet.KvRwSet.Reads {
+			keys = append(keys, kvRead.Key)
+		}
+		for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+			keys = append(keys, kvWrite.Key)
+		}
+		for _, kvMetadataWrite := range nsRWSet.KvRwSet.MetadataWrites {
+			keys = append(keys, kvMetadataWrite.Key)
+		}
+		for _, key := range keys {
+			metadata, err := retrieveLatestMetadata(ns, "", key, updates, v.db)
+			if err != nil {
+				return false, err
+			}
+			if locked, err := tx.isLockedByOther(ns, key, metadata); locked || err != nil {
+				return false, err
+			}
+		}
+		for _, rqi := range nsRWSet.KvRwSet.RangeQueriesInfo {
+			if valid, err := v.validateCrossChannelLocksInRange(tx, ns, rqi.StartKey, rqi.EndKey, !rqi.ItrExhausted, updates); !valid || err != nil {
+				return valid, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func (v *validator) validateCrossChannelLocksInRange(tx *transaction, ns, startKey, endKey string, includeEndKey bool,
+	updates *publicAndHashUpdates) (bool, error) {
+	itr, err := newCombinedIterator(v.db, updates.publicUpdates.UpdateBatch, ns, startKey, endKey, includeEndKey)
+	if err != nil {
+		return false, err
+	}
+	defer itr.Close()
+	for {
+		result, err := itr.Next()
+		if err != nil {
+			return false, err
+		}
+		if result == nil {
+			return true, nil
+		}
+		kv := result.(*statedb.VersionedKV)
+		if locked, err := tx.isLockedByOther(ns, kv.Key, kv.Metadata); locked || err != nil {
+			return false, err
+		}
+	}
+}
+
+// isLockedByOther returns true if the metadata carries a cross-channel lock which
+// is not resolved by the transaction. A lock which cannot be decoded is considered held
+func (tx *transaction) isLockedByOther(ns, key string, metadataBytes []byte) (bool, error) {
+	if metadataBytes == nil {
+		return false, nil
+	}
+	metadata, err := statemetadata.Deserialize(metadataBytes)
+	if err != nil {
+		return false, err
+	}
+	lock, err := msgs.GetCrossChannelLock(metadata)
+	if err != nil {
+		logger.Debugf("Transaction [%s] accesses key [%s:%s] with an invalid cross-channel lock: %s", tx.id, ns, key, err)
+		return true, nil
+	}
+	if lock == nil {
+		return false, nil
+	}
+	if r := tx.crossChannelResolution; r != nil && r.namespace == ns && r.txID == lock.TxId {
+		return false, nil
+	}
+	logger.Debugf("Transaction [%s] accesses key [%s:%s] locked by transaction [%s] of channel [%s]", tx.id, ns, key, lock.TxId, lock.ChannelId)
+	return true, nil
+}
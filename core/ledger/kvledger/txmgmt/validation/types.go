@@ -28,6 +28,7 @@ type transaction struct {
 	rwset                   *rwsetutil.TxRwSet
 	validationCode          peer.TxValidationCode
 	containsPostOrderWrites bool
+	crossChannelResolution  *crossChannelResolution
 }
 
 // publicAndHashUpdates encapsulates public and hash updates. The intended use of this to hold the updates
@@ -94,7 +94,7 @@ func (v *validator) validateAndPrepareBatch(blk *block, doMVCCValidation bool) (
 	for _, tx := range blk.txs {
 		var validationCode peer.TxValidationCode
 		var err error
-		if validationCode, err = v.validateEndorserTX(tx.rwset, doMVCCValidation, updates); err != nil {
+		if validationCode, err = v.validateEndorserTX(tx, doMVCCValidation, updates); err != nil {
 			return nil, err
 		}
 
@@ -115,7 +115,7 @@ func (v *validator) validateAndPrepareBatch(blk *block, doMVCCValidation bool) (
 
 // validateEndorserTX validates endorser transaction
 func (v *validator) validateEndorserTX(
-	txRWSet *rwsetutil.TxRwSet,
+	tx *transaction,
 	doMVCCValidation bool,
 	updates *publicAndHashUpdates) (peer.TxValidationCode, error) {
 
@@ -123,7 +123,18 @@ func (v *validator) validateEndorserTX(
 	var err error
 	//mvcc validation, may invalidate transaction
 	if doMVCCValidation {
-		validationCode, err = v.validateTx(txRWSet, updates)
+		validationCode, err = v.validateTx(tx.rwset, updates)
+		if validationCode != peer.TxValidationCode_VALID || err != nil {
+			return validationCode, err
+		}
+		// the keys locked by a cross-channel transaction conflict with
+		// any access except the one committing or aborting the locks
+		if valid, err := v.validateCrossChannelLocks(tx, updates); !valid || err != nil {
+			if err != nil {
+				return peer.TxValidationCode(-1), err
+			}
+			return peer.TxValidationCode_MVCC_READ_CONFLICT, nil
+		}
 	}
 	return validationCode, err
 }
@@ -12,13 +12,16 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
 	"github.com/hyperledger/fabric/core/ledger/internal/version"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/stretchr/testify/require"
 )
@@ -410,3 +413,122 @@ func getTestPubSimulationRWSet(t *testing.T, builders ...*rwsetutil.RWSetBuilder
 	}
 	return pubRWSets
 }
+
+func TestCrossChannelLockValidation(t *testing.T) {
+	testDBEnv := testEnvs[levelDBtestEnvName]
+	testDBEnv.Init(t)
+	defer testDBEnv.Cleanup()
+	db := testDBEnv.GetDBHandle("TestDB")
+
+	lockBytes := func(txID string) []byte {
+		lockBytes, err := proto.Marshal(&msgs.CrossChannelLock{ChannelId: "token", TxId: txID, Value: []byte("pending")})
+		require.NoError(t, err)
+		return lockBytes
+	}
+	lockMetadata := func(txID string) []byte {
+		metadata, err := statemetadata.Serialize([]*kvrwset.KVMetadataEntry{{Name: msgs.CrossChannelLockKey, Value: lockBytes(txID)}})
+		require.NoError(t, err)
+		return metadata
+	}
+
+	//populate db with initial data, key2 and key4 are locked by the cross-channel transaction xcc-txid
+	batch := privacyenabledstate.NewUpdateBatch()
+	batch.PubUpdates.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 0))
+	batch.PubUpdates.PutValAndMetadata("ns1", "key2", []byte("value2"), lockMetadata("xcc-txid"), version.NewHeight(1, 1))
+	batch.PubUpdates.Put("ns1", "key3", []byte("value3"), version.NewHeight(1, 2))
+	batch.PubUpdates.PutValAndMetadata("ns1", "key4", []byte("value4"), lockMetadata("xcc-txid"), version.NewHeight(1, 3))
+	db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(1, 3))
+
+	testValidator := &validator{db: db, hashFunc: testHashFunc}
+
+	validate := func(resolution *crossChannelResolution, builders ...*rwsetutil.RWSetBuilder) []peer.TxValidationCode {
+		blk := &block{num: 2}
+		for i, txRWSet := range getTestPubSimulationRWSet(t, builders...) {
+			blk.txs = append(blk.txs, &transaction{
+				id:                     fmt.Sprintf("txid-%d", i),
+				indexInBlock:           i,
+				rwset:                  txRWSet,
+				crossChannelResolution: resolution,
+			})
+		}
+		_, err := testValidator.validateAndPrepareBatch(blk, true)
+		require.NoError(t, err)
+		var codes []peer.TxValidationCode
+		for _, tx := range blk.txs {
+			codes = append(codes, tx.validationCode)
+		}
+		return codes
+	}
+
+	t.Run("keys which are not locked", func(t *testing.T) {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToReadSet("ns1", "key1", version.NewHeight(1, 0))
+		rwsetBuilder.AddToWriteSet("ns1", "key3", []byte("value3_new"))
+		rqi := &kvrwset.RangeQueryInfo{StartKey: "key1", EndKey: "key2", ItrExhausted: true}
+		rwsetutil.SetRawReads(rqi, []*kvrwset.KVRead{rwsetutil.NewKVRead("key1", version.NewHeight(1, 0))})
+		rwsetBuilder.AddToRangeQuerySet("ns1", rqi)
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_VALID}, validate(nil, rwsetBuilder))
+	})
+
+	t.Run("read of a locked key", func(t *testing.T) {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToReadSet("ns1", "key2", version.NewHeight(1, 1))
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}, validate(nil, rwsetBuilder))
+	})
+
+	t.Run("write of a locked key", func(t *testing.T) {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToWriteSet("ns1", "key2", []byte("value2_new"))
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}, validate(nil, rwsetBuilder))
+	})
+
+	t.Run("metadata write of a locked key", func(t *testing.T) {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToMetadataWriteSet("ns1", "key4", nil)
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}, validate(nil, rwsetBuilder))
+	})
+
+	t.Run("range query covering a locked key", func(t *testing.T) {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rqi := &kvrwset.RangeQueryInfo{StartKey: "key3", EndKey: "key4", ItrExhausted: false}
+		rwsetutil.SetRawReads(rqi, []*kvrwset.KVRead{
+			rwsetutil.NewKVRead("key3", version.NewHeight(1, 2)),
+			rwsetutil.NewKVRead("key4", version.NewHeight(1, 3))})
+		rwsetBuilder.AddToRangeQuerySet("ns1", rqi)
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}, validate(nil, rwsetBuilder))
+	})
+
+	t.Run("key locked by a preceding transaction in the block", func(t *testing.T) {
+		rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder1.AddToMetadataWriteSet("ns1", "key1", map[string][]byte{msgs.CrossChannelLockKey: lockBytes("other-xcc-txid")})
+		rwsetBuilder2 := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder2.AddToWriteSet("ns1", "key1", []byte("value1_new"))
+		require.Equal(t,
+			[]peer.TxValidationCode{peer.TxValidationCode_VALID, peer.TxValidationCode_MVCC_READ_CONFLICT},
+			validate(nil, rwsetBuilder1, rwsetBuilder2),
+		)
+	})
+
+	resolve := func() *rwsetutil.RWSetBuilder {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToReadSet("ns1", "key2", version.NewHeight(1, 1))
+		rwsetBuilder.AddToWriteSet("ns1", "key2", []byte("pending"))
+		rwsetBuilder.AddToMetadataWriteSet("ns1", "key2", nil)
+		return rwsetBuilder
+	}
+
+	t.Run("resolution of the locks", func(t *testing.T) {
+		resolution := &crossChannelResolution{namespace: "ns1", txID: "xcc-txid"}
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_VALID}, validate(resolution, resolve()))
+	})
+
+	t.Run("resolution of the locks of another transaction", func(t *testing.T) {
+		resolution := &crossChannelResolution{namespace: "ns1", txID: "other-txid"}
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}, validate(resolution, resolve()))
+	})
+
+	t.Run("resolution of the locks in another namespace", func(t *testing.T) {
+		resolution := &crossChannelResolution{namespace: "ns2", txID: "xcc-txid"}
+		require.Equal(t, []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}, validate(resolution, resolve()))
+	})
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/hyperledger/fabric/bccsp"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
+	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
 	storeapi "github.com/hyperledger/fabric/extensions/collections/api/store"
 )
 
@@ -36,6 +38,7 @@ type Initializer struct {
 	CustomTxProcessors              map[common.HeaderType]CustomTxProcessor
 	HashProvider                    HashProvider
 	CollDataProvider                storeapi.Provider
+	PvtdataEncryptor                *pvtdataencryption.Encryptor
 }
 
 // Config is a structure used to configure a ledger provider.
@@ -199,6 +202,9 @@ type PeerLedger interface {
 	//     missing info is recorded in the ledger (or)
 	// (3) the block is committed and does not contain any pvtData.
 	DoesPvtDataInfoExist(blockNum uint64) (bool, error)
+	// GetCollsPvtdataRemovalInfo returns the collections for which the peer lost the eligibility
+	// along with the status of the removal of their private data
+	GetCollsPvtdataRemovalInfo() ([]*CollPvtdataRemovalInfo, error)
 }
 
 // SimpleQueryExecutor encapsulates basic functions
@@ -299,6 +305,9 @@ type TxSimulator interface {
 	SetPrivateDataMultipleKeys(namespace, collection string, kvs map[string][]byte) error
 	// DeletePrivateData deletes the given tuple <namespace, collection, key> from private data
 	DeletePrivateData(namespace, collection, key string) error
+	// PurgePrivateData deletes the given tuple <namespace, collection, key> from private data and, once the
+	// transaction is committed, purges all the historical versions of the key from the private data store
+	PurgePrivateData(namespace, collection, key string) error
 	// SetPrivateDataMetadata sets the metadata associated with an existing key-tuple <namespace, collection, key>
 	SetPrivateDataMetadata(namespace, collection, key string, metadata map[string][]byte) error
 	// DeletePrivateDataMetadata deletes the metadata associated with an existing key-tuple <namespace, collection, key>
@@ -346,6 +355,24 @@ type MissingPvtData struct {
 // missing pvtData
 type TxMissingPvtDataMap map[uint64][]*MissingPvtData
 
+// PvtdataPurge identifies a private data key purged by the transaction at
+// the height <BlockNum, TxNum>. The key is identified by its hash, as the hashed
+// write-set is the only part of a transaction available on every peer
+type PvtdataPurge struct {
+	Namespace, Collection string
+	KeyHash               []byte
+	BlockNum, TxNum       uint64
+}
+
+// CollPvtdataRemovalInfo captures the removal of the private data of a collection for which the peer
+// lost the eligibility with the commit of the block 'BlockNum'. 'Pending' remains true till the private
+// data of the collection committed up to the block is removed from the private data store
+type CollPvtdataRemovalInfo struct {
+	Namespace, Collection string
+	BlockNum              uint64
+	Pending               bool
+}
+
 // BlockAndPvtData encapsulates the block and a map that contains the tuples <seqInBlock, *TxPvtData>
 // The map is expected to contain the entries only for the transactions that has associated pvt data
 type BlockAndPvtData struct {
@@ -517,8 +544,19 @@ type ConfigHistoryRetriever interface {
 // MissingPvtDataTracker allows getting information about the private data that is not missing on the peer
 type MissingPvtDataTracker interface {
 	GetMissingPvtDataInfoForMostRecentBlocks(maxBlocks int) (MissingPvtDataInfo, error)
+	// GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks returns the missing private data information of the
+	// collections selected by the filter for the most recent `maxBlocks` blocks which miss at least a private data
+	// of such a collection. Unlike GetMissingPvtDataInfoForMostRecentBlocks(), it does not return the missing
+	// private data for which an earlier reconciliation attempt failed
+	GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(maxBlocks int, filter MissingPvtDataFilter) (MissingPvtDataInfo, error)
+	// GetMissingPvtDataSummary summarizes, per collection, the private data of eligible collections
+	// that is missing on the peer and has not expired
+	GetMissingPvtDataSummary() ([]*CollMissingPvtDataSummary, error)
 }
 
+// MissingPvtDataFilter selects the chaincodes and collections whose missing private data is returned
+type MissingPvtDataFilter func(ns, coll string) bool
+
 // MissingPvtDataInfo is a map of block number to MissingBlockPvtdataInfo
 type MissingPvtDataInfo map[uint64]MissingBlockPvtdataInfo
 
@@ -530,6 +568,15 @@ type MissingCollectionPvtDataInfo struct {
 	Namespace, Collection string
 }
 
+// CollMissingPvtDataSummary captures the number of transactions whose private data of a collection is missing
+// on the peer. 'Deprioritized' counts the transactions for which an earlier reconciliation attempt failed and
+// 'OldestBlockNum' is the lowest block number among these transactions
+type CollMissingPvtDataSummary struct {
+	Namespace, Collection string
+	Count, Deprioritized  uint64
+	OldestBlockNum        uint64
+}
+
 // CollectionConfigInfo encapsulates a collection config for a chaincode and its committing block number
 type CollectionConfigInfo struct {
 	CollectionConfig   *peer.CollectionConfigPackage
@@ -676,6 +723,15 @@ type ChaincodeLifecycleEventListener interface {
 	ChaincodeDeployDone(succeeded bool)
 }
 
+// InProcChaincodeLifecycleEventListener is implemented by the ChaincodeLifecycleEventListeners which are able
+// to create the db specific artifacts of in-process user chaincodes. These chaincodes are compiled into the peer
+// and hence provide their artifacts directly, mapped by DB type, instead of in a tar.
+type InProcChaincodeLifecycleEventListener interface {
+	// HandleInProcChaincodeDeploy is invoked, in addition to HandleChaincodeDeploy, when an in-process user
+	// chaincode becomes defined
+	HandleInProcChaincodeDeploy(chaincodeDefinition *ChaincodeDefinition, dbArtifacts map[string]*ccapi.DBArtifacts) error
+}
+
 // ChaincodeDefinition captures the info about chaincode
 type ChaincodeDefinition struct {
 	Name              string
@@ -18,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	storeapi "github.com/hyperledger/fabric/extensions/collections/api/store"
 	"github.com/pkg/errors"
 )
@@ -55,6 +56,7 @@ type Initializer struct {
 	HashProvider                    ledger.HashProvider
 	EbMetadataProvider              MetadataProvider
 	CollDataProvider                storeapi.Provider
+	PvtdataEncryptor                *pvtdataencryption.Encryptor
 }
 
 // NewLedgerMgr creates a new LedgerMgr
@@ -76,6 +78,7 @@ func NewLedgerMgr(initializer *Initializer) *LedgerMgr {
 			CustomTxProcessors:              initializer.CustomTxProcessors,
 			HashProvider:                    initializer.HashProvider,
 			CollDataProvider:                initializer.CollDataProvider,
+			PvtdataEncryptor:                initializer.PvtdataEncryptor,
 		},
 	)
 	if err != nil {
@@ -7,11 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package pvtdatastorage
 
 import (
+	"bytes"
 	"math"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/pkg/errors"
 	"github.com/willf/bitset"
 )
 
@@ -26,14 +31,94 @@ func prepareStoreEntries(blockNum uint64, pvtData []*ledger.TxPvtData, btlPolicy
 		return nil, err
 	}
 
+	var hashedIndexEntries []*hashedIndexKey
+	for _, dataEntry := range dataEntries {
+		hashedIndexEntries = append(hashedIndexEntries, deriveHashedIndexKeys(dataEntry.key, dataEntry.value)...)
+	}
+
 	return &storeEntries{
 		dataEntries:             dataEntries,
 		expiryEntries:           expiryEntries,
 		elgMissingDataEntries:   elgMissingDataEntries,
 		inelgMissingDataEntries: inelgMissingDataEntries,
+		hashedIndexEntries:      hashedIndexEntries,
 	}, nil
 }
 
+// deriveHashedIndexKeys constructs the hashed index keys for the keys written (or whose metadata
+// is written) in the private write-set of a data entry. The hashed index allows to locate the
+// historical versions of a private data key when the key gets purged
+func deriveHashedIndexKeys(dataKey *dataKey, collPvtdata *rwset.CollectionPvtReadWriteSet) []*hashedIndexKey {
+	kvRWSet := &kvrwset.KVRWSet{}
+	if err := proto.Unmarshal(collPvtdata.Rwset, kvRWSet); err != nil {
+		logger.Warningf("Private write-set of [%s:%s] at height [%d:%d] could not be decoded, its keys are not indexed: %s",
+			dataKey.ns, dataKey.coll, dataKey.blkNum, dataKey.txNum, err)
+		return nil
+	}
+
+	var hashedIndexKeys []*hashedIndexKey
+	indexed := make(map[string]struct{})
+	addKey := func(key string) {
+		if _, ok := indexed[key]; ok {
+			return
+		}
+		indexed[key] = struct{}{}
+		hashedIndexKeys = append(hashedIndexKeys, &hashedIndexKey{
+			ns:      dataKey.ns,
+			coll:    dataKey.coll,
+			keyHash: util.ComputeStringHash(key),
+			blkNum:  dataKey.blkNum,
+			txNum:   dataKey.txNum,
+		})
+	}
+	for _, w := range kvRWSet.Writes {
+		addKey(w.Key)
+	}
+	for _, w := range kvRWSet.MetadataWrites {
+		addKey(w.Key)
+	}
+	return hashedIndexKeys
+}
+
+// removeKeyFromPvtRwSet removes the writes and metadata writes of the key, identified by its hash,
+// from the private write-set. It returns false if the write-set does not contain the key
+func removeKeyFromPvtRwSet(collPvtdata *rwset.CollectionPvtReadWriteSet, keyHash []byte) (bool, error) {
+	kvRWSet := &kvrwset.KVRWSet{}
+	if err := proto.Unmarshal(collPvtdata.Rwset, kvRWSet); err != nil {
+		return false, errors.Wrap(err, "error unmarshaling private write-set")
+	}
+
+	removed := false
+	var writes []*kvrwset.KVWrite
+	for _, w := range kvRWSet.Writes {
+		if bytes.Equal(util.ComputeStringHash(w.Key), keyHash) {
+			removed = true
+			continue
+		}
+		writes = append(writes, w)
+	}
+	var metadataWrites []*kvrwset.KVMetadataWrite
+	for _, w := range kvRWSet.MetadataWrites {
+		if bytes.Equal(util.ComputeStringHash(w.Key), keyHash) {
+			removed = true
+			continue
+		}
+		metadataWrites = append(metadataWrites, w)
+	}
+	if !removed {
+		return false, nil
+	}
+
+	kvRWSet.Writes = writes
+	kvRWSet.MetadataWrites = metadataWrites
+	rwsetBytes, err := proto.Marshal(kvRWSet)
+	if err != nil {
+		return false, errors.Wrap(err, "error marshaling private write-set")
+	}
+	collPvtdata.Rwset = rwsetBytes
+	return true, nil
+}
+
 func prepareDataEntries(blockNum uint64, pvtData []*ledger.TxPvtData) []*dataEntry {
 	var dataEntries []*dataEntry
 	for _, txPvtdata := range pvtData {
@@ -14,20 +14,28 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
 	"github.com/hyperledger/fabric/core/ledger/internal/version"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/pkg/errors"
 	"github.com/willf/bitset"
 )
 
 var (
-	pendingCommitKey                 = []byte{0}
-	lastCommittedBlkkey              = []byte{1}
-	pvtDataKeyPrefix                 = []byte{2}
-	expiryKeyPrefix                  = []byte{3}
-	elgPrioritizedMissingDataGroup   = []byte{4}
-	inelgMissingDataGroup            = []byte{5}
-	collElgKeyPrefix                 = []byte{6}
-	lastUpdatedOldBlocksKey          = []byte{7}
-	elgDeprioritizedMissingDataGroup = []byte{8}
+	pendingCommitKey                      = []byte{0}
+	lastCommittedBlkkey                   = []byte{1}
+	pvtDataKeyPrefix                      = []byte{2}
+	expiryKeyPrefix                       = []byte{3}
+	elgPrioritizedMissingDataGroup        = []byte{4}
+	inelgMissingDataGroup                 = []byte{5}
+	collElgKeyPrefix                      = []byte{6}
+	lastUpdatedOldBlocksKey               = []byte{7}
+	elgDeprioritizedMissingDataGroup      = []byte{8}
+	hashedIndexKeyPrefix                  = []byte{9}
+	purgeMarkerKeyPrefix                  = []byte{10}
+	purgeMarkerForBackgroundProcKeyPrefix = []byte{11}
+	collInelgKeyPrefix                    = []byte{12}
+	collInelgProcessedKeyPrefix           = []byte{13}
+	hashedIndexBuiltKey                   = []byte{14}
+	v11DataConvertedKey                   = []byte{15}
 
 	nilByte    = byte(0)
 	emptyValue = []byte{}
@@ -61,8 +69,12 @@ func encodeDataKey(key *dataKey) []byte {
 	return append(dataKeyBytes, []byte(key.coll)...)
 }
 
-func encodeDataValue(collData *rwset.CollectionPvtReadWriteSet) ([]byte, error) {
-	return proto.Marshal(collData)
+func encodeDataValue(collData *rwset.CollectionPvtReadWriteSet, encryptor *pvtdataencryption.Encryptor) ([]byte, error) {
+	collDataBytes, err := proto.Marshal(collData)
+	if err != nil {
+		return nil, err
+	}
+	return encryptor.Encrypt(collDataBytes)
 }
 
 func encodeExpiryKey(expiryKey *expiryKey) []byte {
@@ -102,9 +114,99 @@ func decodeDatakey(datakeyBytes []byte) (*dataKey, error) {
 	return &dataKey{nsCollBlk{ns, coll, blkNum}, tranNum}, nil
 }
 
-func decodeDataValue(datavalueBytes []byte) (*rwset.CollectionPvtReadWriteSet, error) {
+func encodeHashedIndexKey(key *hashedIndexKey) []byte {
+	encKey := encodeNsCollKeyHash(hashedIndexKeyPrefix, key.ns, key.coll, key.keyHash)
+	return append(encKey, version.NewHeight(key.blkNum, key.txNum).ToBytes()...)
+}
+
+func decodeHashedIndexKey(keyBytes []byte) (*hashedIndexKey, error) {
+	ns, coll, remainingBytes, err := decodeNsColl(keyBytes[1:])
+	if err != nil {
+		return nil, err
+	}
+	keyHashLen, n := proto.DecodeVarint(remainingBytes)
+	if n == 0 || uint64(len(remainingBytes)-n) < keyHashLen {
+		return nil, errors.Errorf("invalid hashed index key %#v", keyBytes)
+	}
+	keyHash := remainingBytes[n : n+int(keyHashLen)]
+	height, _, err := version.NewHeightFromBytes(remainingBytes[n+int(keyHashLen):])
+	if err != nil {
+		return nil, err
+	}
+	return &hashedIndexKey{
+		ns:      ns,
+		coll:    coll,
+		keyHash: append([]byte(nil), keyHash...),
+		blkNum:  height.BlockNum,
+		txNum:   height.TxNum,
+	}, nil
+}
+
+// encodePurgeMarkerKey encodes the key under which the height of the latest purge of
+// the private data key is kept
+func encodePurgeMarkerKey(key *purgeMarkerKey) []byte {
+	return encodeNsCollKeyHash(purgeMarkerKeyPrefix, key.ns, key.coll, key.keyHash)
+}
+
+func encodePurgeMarkerVal(blkNum, txNum uint64) []byte {
+	return version.NewHeight(blkNum, txNum).ToBytes()
+}
+
+func decodePurgeMarkerVal(b []byte) (*version.Height, error) {
+	height, _, err := version.NewHeightFromBytes(b)
+	return height, err
+}
+
+// encodePurgeMarkerForBackgroundProcKey encodes the key of a purge which is yet to be
+// processed by the background routine that removes the historical versions of the purged
+// key. The purge height comes first so that the purges are processed in commit order
+func encodePurgeMarkerForBackgroundProcKey(key *purgeMarkerKey, blkNum, txNum uint64) []byte {
+	encKey := append(purgeMarkerForBackgroundProcKeyPrefix, version.NewHeight(blkNum, txNum).ToBytes()...)
+	return encodeNsCollKeyHash(encKey, key.ns, key.coll, key.keyHash)
+}
+
+func decodePurgeMarkerForBackgroundProcKey(keyBytes []byte) (*purgeMarkerKey, *version.Height, error) {
+	height, n, err := version.NewHeightFromBytes(keyBytes[1:])
+	if err != nil {
+		return nil, nil, err
+	}
+	ns, coll, remainingBytes, err := decodeNsColl(keyBytes[n+1:])
+	if err != nil {
+		return nil, nil, err
+	}
+	keyHashLen, m := proto.DecodeVarint(remainingBytes)
+	if m == 0 || uint64(len(remainingBytes)-m) != keyHashLen {
+		return nil, nil, errors.Errorf("invalid purge marker key %#v", keyBytes)
+	}
+	keyHash := append([]byte(nil), remainingBytes[m:]...)
+	return &purgeMarkerKey{ns: ns, coll: coll, keyHash: keyHash}, height, nil
+}
+
+func encodeNsCollKeyHash(prefix []byte, ns, coll string, keyHash []byte) []byte {
+	encKey := append([]byte(nil), prefix...)
+	encKey = append(encKey, []byte(ns)...)
+	encKey = append(encKey, nilByte)
+	encKey = append(encKey, []byte(coll)...)
+	encKey = append(encKey, nilByte)
+	encKey = append(encKey, proto.EncodeVarint(uint64(len(keyHash)))...)
+	return append(encKey, keyHash...)
+}
+
+func decodeNsColl(b []byte) (string, string, []byte, error) {
+	splittedKey := bytes.SplitN(b, []byte{nilByte}, 3)
+	if len(splittedKey) != 3 {
+		return "", "", nil, errors.Errorf("invalid encoding of namespace and collection %#v", b)
+	}
+	return string(splittedKey[0]), string(splittedKey[1]), splittedKey[2], nil
+}
+
+func decodeDataValue(datavalueBytes []byte, encryptor *pvtdataencryption.Encryptor) (*rwset.CollectionPvtReadWriteSet, error) {
+	datavalueBytes, err := encryptor.Decrypt(datavalueBytes)
+	if err != nil {
+		return nil, err
+	}
 	collPvtdata := &rwset.CollectionPvtReadWriteSet{}
-	err := proto.Unmarshal(datavalueBytes, collPvtdata)
+	err = proto.Unmarshal(datavalueBytes, collPvtdata)
 	return collPvtdata, err
 }
 
@@ -190,6 +292,15 @@ func decodeCollElgVal(b []byte) (*CollElgInfo, error) {
 	return m, nil
 }
 
+func encodeCollInelgKey(prefix []byte, blkNum uint64) []byte {
+	return append(prefix, encodeReverseOrderVarUint64(blkNum)...)
+}
+
+func decodeCollInelgKey(b []byte) uint64 {
+	blkNum, _ := decodeReverseOrderVarUint64(b[1:])
+	return blkNum
+}
+
 func createRangeScanKeysForElgMissingData(blkNum uint64, group []byte) ([]byte, []byte) {
 	startKey := append(group, encodeReverseOrderVarUint64(blkNum)...)
 	endKey := append(group, encodeReverseOrderVarUint64(0)...)
@@ -225,6 +336,36 @@ func createRangeScanKeysForCollElg() (startKey, endKey []byte) {
 		encodeCollElgKey(0)
 }
 
+func createRangeScanKeysForCollInelg(prefix []byte) (startKey, endKey []byte) {
+	return encodeCollInelgKey(prefix, math.MaxUint64),
+		encodeCollInelgKey(prefix, 0)
+}
+
+// createRangeScanKeysForDataUpToBlock returns the range of the data entries committed up to the given block
+func createRangeScanKeysForDataUpToBlock(maxBlkNum uint64) ([]byte, []byte) {
+	startKey := append(pvtDataKeyPrefix, version.NewHeight(0, 0).ToBytes()...)
+	endKey := append(pvtDataKeyPrefix, version.NewHeight(maxBlkNum+1, 0).ToBytes()...)
+	return startKey, endKey
+}
+
+// createRangeScanKeysForData returns the range of all the data entries
+func createRangeScanKeysForData() ([]byte, []byte) {
+	return pvtDataKeyPrefix, []byte{pvtDataKeyPrefix[0] + 1}
+}
+
+// createRangeScanKeysForHashedIndex returns the range of the hashed index entries of the
+// private data key which were committed below the given height
+func createRangeScanKeysForHashedIndex(ns, coll string, keyHash []byte, blkNum, txNum uint64) ([]byte, []byte) {
+	prefix := encodeNsCollKeyHash(hashedIndexKeyPrefix, ns, coll, keyHash)
+	startKey := append(prefix, version.NewHeight(0, 0).ToBytes()...)
+	endKey := append(append([]byte(nil), prefix...), version.NewHeight(blkNum, txNum).ToBytes()...)
+	return startKey, endKey
+}
+
+func createRangeScanKeysForPurgeMarkersForBackgroundProc() ([]byte, []byte) {
+	return purgeMarkerForBackgroundProcKeyPrefix, []byte{purgeMarkerForBackgroundProcKeyPrefix[0] + 1}
+}
+
 func datakeyRange(blockNum uint64) ([]byte, []byte) {
 	startKey := append(pvtDataKeyPrefix, version.NewHeight(blockNum, 0).ToBytes()...)
 	endKey := append(pvtDataKeyPrefix, version.NewHeight(blockNum, math.MaxUint64).ToBytes()...)
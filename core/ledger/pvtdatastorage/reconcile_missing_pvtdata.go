@@ -10,6 +10,7 @@ import (
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/pkg/errors"
 	"github.com/willf/bitset"
 )
@@ -82,6 +83,10 @@ func (p *oldBlockDataProcessor) prepareDataAndExpiryEntries(blocksPvtData map[ui
 		nsCollBlk := dataEntry.key.nsCollBlk
 		txNum := dataEntry.key.txNum
 
+		if err := p.removePurgedKeys(dataEntry); err != nil {
+			return err
+		}
+
 		expKey, err := p.constructExpiryKey(dataEntry)
 		if err != nil {
 			return err
@@ -89,6 +94,7 @@ func (p *oldBlockDataProcessor) prepareDataAndExpiryEntries(blocksPvtData map[ui
 
 		if neverExpires(expKey.expiringBlk) {
 			p.entries.dataEntries[*dataEntry.key] = dataEntry.value
+			p.entries.hashedIndexEntries = append(p.entries.hashedIndexEntries, deriveHashedIndexKeys(dataEntry.key, dataEntry.value)...)
 			continue
 		}
 
@@ -107,6 +113,26 @@ func (p *oldBlockDataProcessor) prepareDataAndExpiryEntries(blocksPvtData map[ui
 
 		p.entries.dataEntries[*dataEntry.key] = dataEntry.value
 		p.entries.expiryEntries[expKey] = expData
+		p.entries.hashedIndexEntries = append(p.entries.hashedIndexEntries, deriveHashedIndexKeys(dataEntry.key, dataEntry.value)...)
+	}
+	return nil
+}
+
+// removePurgedKeys removes from the reconciled pvtData the keys which were purged after
+// the commit of the transaction so that the purged data is not brought back into the store
+func (p *oldBlockDataProcessor) removePurgedKeys(dataEntry *dataEntry) error {
+	for _, hashedIndexKey := range deriveHashedIndexKeys(dataEntry.key, dataEntry.value) {
+		purged, err := p.IsPurged(hashedIndexKey.ns, hashedIndexKey.coll, hashedIndexKey.keyHash,
+			hashedIndexKey.blkNum, hashedIndexKey.txNum)
+		if err != nil {
+			return err
+		}
+		if !purged {
+			continue
+		}
+		if _, err := removeKeyFromPvtRwSet(dataEntry.value, hashedIndexKey.keyHash); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -256,7 +282,7 @@ func (p *oldBlockDataProcessor) getDeprioMissingDataFromEntriesOrStore(nsCollBlk
 func (p *oldBlockDataProcessor) constructDBUpdateBatch() (*leveldbhelper.UpdateBatch, error) {
 	batch := p.db.NewUpdateBatch()
 
-	if err := p.entries.addDataEntriesTo(batch); err != nil {
+	if err := p.entries.addDataEntriesTo(batch, p.encryptor); err != nil {
 		return nil, errors.WithMessage(err, "error while adding data entries to the update batch")
 	}
 
@@ -272,6 +298,10 @@ func (p *oldBlockDataProcessor) constructDBUpdateBatch() (*leveldbhelper.UpdateB
 		return nil, errors.WithMessage(err, "error while adding eligible deprioritized missing data entries to the update batch")
 	}
 
+	for _, hashedIndexKey := range p.entries.hashedIndexEntries {
+		batch.Put(encodeHashedIndexKey(hashedIndexKey), emptyValue)
+	}
+
 	return batch, nil
 }
 
@@ -280,15 +310,16 @@ type entriesForPvtDataOfOldBlocks struct {
 	expiryEntries                   map[expiryKey]*ExpiryData
 	prioritizedMissingDataEntries   map[nsCollBlk]*bitset.BitSet
 	deprioritizedMissingDataEntries map[nsCollBlk]*bitset.BitSet
+	hashedIndexEntries              []*hashedIndexKey
 }
 
-func (e *entriesForPvtDataOfOldBlocks) addDataEntriesTo(batch *leveldbhelper.UpdateBatch) error {
+func (e *entriesForPvtDataOfOldBlocks) addDataEntriesTo(batch *leveldbhelper.UpdateBatch, encryptor *pvtdataencryption.Encryptor) error {
 	var key, val []byte
 	var err error
 
 	for dataKey, pvtData := range e.dataEntries {
 		key = encodeDataKey(&dataKey)
-		if val, err = encodeDataValue(pvtData); err != nil {
+		if val, err = encodeDataValue(pvtData, encryptor); err != nil {
 			return errors.Wrap(err, "error while encoding data value")
 		}
 		batch.Put(key, val)
@@ -88,9 +88,9 @@ func TestCommitPvtDataOfOldBlocks(t *testing.T) {
 
 	blocksPvtData, missingDataSummary := constructPvtDataForTest(t, blockTxPvtDataInfo)
 
-	require.NoError(t, store.Commit(0, nil, nil))
-	require.NoError(t, store.Commit(1, blocksPvtData[1].pvtData, blocksPvtData[1].missingDataInfo))
-	require.NoError(t, store.Commit(2, blocksPvtData[2].pvtData, blocksPvtData[2].missingDataInfo))
+	require.NoError(t, store.Commit(0, nil, nil, nil))
+	require.NoError(t, store.Commit(1, blocksPvtData[1].pvtData, blocksPvtData[1].missingDataInfo, nil))
+	require.NoError(t, store.Commit(2, blocksPvtData[2].pvtData, blocksPvtData[2].missingDataInfo, nil))
 
 	assertMissingDataInfo(t, store, missingDataSummary, 2)
 
@@ -180,14 +180,14 @@ func TestCommitPvtDataOfOldBlocksWithBTL(t *testing.T) {
 
 		blocksPvtData, missingDataSummary := constructPvtDataForTest(t, blockTxPvtDataInfo)
 
-		require.NoError(t, store.Commit(0, nil, nil))
-		require.NoError(t, store.Commit(1, blocksPvtData[1].pvtData, blocksPvtData[1].missingDataInfo))
+		require.NoError(t, store.Commit(0, nil, nil, nil))
+		require.NoError(t, store.Commit(1, blocksPvtData[1].pvtData, blocksPvtData[1].missingDataInfo, nil))
 
 		assertMissingDataInfo(t, store, missingDataSummary, 1)
 
 		// COMMIT BLOCK 2 & 3 WITH NO PVTDATA
-		require.NoError(t, store.Commit(2, nil, nil))
-		require.NoError(t, store.Commit(3, nil, nil))
+		require.NoError(t, store.Commit(2, nil, nil, nil))
+		require.NoError(t, store.Commit(3, nil, nil, nil))
 	}
 
 	t.Run("expired but not purged", func(t *testing.T) {
@@ -291,7 +291,7 @@ func TestCommitPvtDataOfOldBlocksWithBTL(t *testing.T) {
 		store := env.TestStore
 
 		setup(store)
-		require.NoError(t, store.Commit(4, nil, nil))
+		require.NoError(t, store.Commit(4, nil, nil, nil))
 
 		testWaitForPurgerRoutineToFinish(store)
 
@@ -479,15 +479,15 @@ func TestCommitPvtDataOfOldBlocksWithDeprioritization(t *testing.T) {
 			store := env.TestStore
 
 			// COMMIT BLOCK 0 WITH NO DATA
-			require.NoError(t, store.Commit(0, nil, nil))
-			require.NoError(t, store.Commit(1, blocksPvtData[1].pvtData, blocksPvtData[1].missingDataInfo))
-			require.NoError(t, store.Commit(2, blocksPvtData[2].pvtData, blocksPvtData[2].missingDataInfo))
+			require.NoError(t, store.Commit(0, nil, nil, nil))
+			require.NoError(t, store.Commit(1, blocksPvtData[1].pvtData, blocksPvtData[1].missingDataInfo, nil))
+			require.NoError(t, store.Commit(2, blocksPvtData[2].pvtData, blocksPvtData[2].missingDataInfo, nil))
 
 			assertMissingDataInfo(t, store, missingDataSummary, 2)
 
 			require.NoError(t, store.CommitPvtDataOfOldBlocks(nil, tt.deprioritizedList))
 
-			prioMissingData, err := store.getMissingData(elgPrioritizedMissingDataGroup, 3)
+			prioMissingData, err := store.getMissingData(elgPrioritizedMissingDataGroup, 3, nil)
 			require.NoError(t, err)
 			require.Equal(t, len(tt.expectedPrioMissingDataKeys), len(prioMissingData))
 			for blkNum, txsMissingData := range tt.expectedPrioMissingDataKeys {
@@ -496,7 +496,7 @@ func TestCommitPvtDataOfOldBlocksWithDeprioritization(t *testing.T) {
 				}
 			}
 
-			deprioMissingData, err := store.getMissingData(elgDeprioritizedMissingDataGroup, 3)
+			deprioMissingData, err := store.getMissingData(elgDeprioritizedMissingDataGroup, 3, nil)
 			require.NoError(t, err)
 			require.Equal(t, len(tt.deprioritizedList), len(deprioMissingData))
 			for blkNum, txsMissingData := range tt.deprioritizedList {
@@ -547,11 +547,11 @@ func TestCommitPvtDataOfOldBlocksWithDeprioritization(t *testing.T) {
 			}
 			require.NoError(t, store.CommitPvtDataOfOldBlocks(oldBlocksPvtData, nil))
 
-			prioMissingData, err = store.getMissingData(elgPrioritizedMissingDataGroup, 3)
+			prioMissingData, err = store.getMissingData(elgPrioritizedMissingDataGroup, 3, nil)
 			require.NoError(t, err)
 			require.Equal(t, make(ledger.MissingPvtDataInfo), prioMissingData)
 
-			deprioMissingData, err = store.getMissingData(elgDeprioritizedMissingDataGroup, 3)
+			deprioMissingData, err = store.getMissingData(elgDeprioritizedMissingDataGroup, 3, nil)
 			require.NoError(t, err)
 			require.Equal(t, make(ledger.MissingPvtDataInfo), deprioMissingData)
 		})
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package pvtdatastorage
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,7 +19,9 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/internal/version"
 	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	xstorageapi "github.com/hyperledger/fabric/extensions/storage/api"
 	"github.com/willf/bitset"
 )
@@ -46,6 +50,9 @@ type PrivateDataConfig struct {
 	// It is internally computed by the ledger component,
 	// so it is not in ledger.PrivateDataConfig and not exposed to other components.
 	StorePath string
+	// Encryptor encrypts the private write sets before they are stored.
+	// The private write sets are stored in plaintext if it is nil.
+	Encryptor *pvtdataencryption.Encryptor
 }
 
 // Store manages the permanent storage of private write sets for a ledger
@@ -61,6 +68,16 @@ type Store struct {
 	lastCommittedBlock uint64
 	purgerLock         sync.Mutex
 	collElgProcSync    *collElgProcSync
+	// purgeMarkerProcSync signals the background routine that
+	// removes the historical versions of the purged keys
+	purgeMarkerProcSync *collElgProcSync
+	// collInelgProcSync signals the background routine that removes the
+	// private data of the collections for which the peer lost the eligibility.
+	// An event is processed only once the block that caused the loss of the
+	// eligibility is committed. Hence, `collInelgEventsPending` is set as long
+	// as there are events waiting for the commit of the block
+	collInelgProcSync      *collElgProcSync
+	collInelgEventsPending uint32
 	// After committing the pvtdata of old blocks,
 	// the `isLastUpdatedOldBlocksSet` is set to true.
 	// Once the stateDB is updated with these pvtdata,
@@ -74,6 +91,8 @@ type Store struct {
 
 	deprioritizedDataReconcilerInterval time.Duration
 	accessDeprioMissingDataAfter        time.Time
+
+	encryptor *pvtdataencryption.Encryptor
 }
 
 type blkTranNumKey []byte
@@ -107,11 +126,25 @@ type missingDataKey struct {
 	nsCollBlk
 }
 
+// hashedIndexKey locates, by the hash of a private data key,
+// the data entry in which a version of the key was committed
+type hashedIndexKey struct {
+	ns, coll      string
+	keyHash       []byte
+	blkNum, txNum uint64
+}
+
+type purgeMarkerKey struct {
+	ns, coll string
+	keyHash  []byte
+}
+
 type storeEntries struct {
 	dataEntries             []*dataEntry
 	expiryEntries           []*expiryEntry
 	elgMissingDataEntries   map[missingDataKey]*bitset.BitSet
 	inelgMissingDataEntries map[missingDataKey]*bitset.BitSet
+	hashedIndexEntries      []*hashedIndexKey
 }
 
 // lastUpdatedOldBlocksList keeps the list of last updated blocks
@@ -144,15 +177,33 @@ func (p *Provider) OpenStore(ledgerid string) (xstorageapi.PrivateDataStore, err
 		purgeInterval:                       uint64(p.pvtData.PurgeInterval),
 		deprioritizedDataReconcilerInterval: p.pvtData.DeprioritizedDataReconcilerInterval,
 		accessDeprioMissingDataAfter:        time.Now().Add(p.pvtData.DeprioritizedDataReconcilerInterval),
+		encryptor:                           p.pvtData.Encryptor,
 		collElgProcSync: &collElgProcSync{
 			notification: make(chan bool, 1),
 			procComplete: make(chan bool, 1),
 		},
+		purgeMarkerProcSync: &collElgProcSync{
+			notification: make(chan bool, 1),
+			procComplete: make(chan bool, 1),
+		},
+		collInelgProcSync: &collElgProcSync{
+			notification: make(chan bool, 1),
+			procComplete: make(chan bool, 1),
+		},
 	}
 	if err := s.initState(); err != nil {
 		return nil, err
 	}
+	if err := s.convertV11DataEntriesIfAny(); err != nil {
+		return nil, err
+	}
+	if err := s.buildHashedIndexIfAbsent(); err != nil {
+		return nil, err
+	}
 	s.launchCollElgProc()
+	s.launchPurgeMarkerProc()
+	s.launchCollInelgProc()
+	s.launchReencryption()
 	logger.Debugf("Pvtdata store opened. Initial state: isEmpty [%t], lastCommittedBlock [%d]",
 		s.isEmpty, s.lastCommittedBlock)
 	return s, nil
@@ -206,13 +257,19 @@ func (s *Store) initState() error {
 // Init initializes the store. This function is expected to be invoked before using the store
 func (s *Store) Init(btlPolicy pvtdatapolicy.BTLPolicy) {
 	s.btlPolicy = btlPolicy
+	// the removal of the private data of the collections requires the btl policy,
+	// hence, the events left unprocessed by the previous run are processed now
+	s.collInelgProcSync.notify()
 }
 
 // Commit commits the pvt data as well as both the eligible and ineligible
 // missing private data --- `eligible` denotes that the missing private data belongs to a collection
 // for which this peer is a member; `ineligible` denotes that the missing private data belong to a
 // collection for which this peer is not a member.
-func (s *Store) Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData ledger.TxMissingPvtDataMap) error {
+// Parameter 'pvtdataPurges' lists the private data keys purged by the valid transactions of the block.
+// The historical versions of these keys are removed from the store in the background.
+func (s *Store) Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData ledger.TxMissingPvtDataMap,
+	pvtdataPurges []*ledger.PvtdataPurge) error {
 	expectedBlockNum := s.nextBlockNum()
 	if expectedBlockNum != blockNum {
 		return &ErrIllegalArgs{fmt.Sprintf("Expected block number=%d, received block number=%d", expectedBlockNum, blockNum)}
@@ -229,7 +286,7 @@ func (s *Store) Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtD
 
 	for _, dataEntry := range storeEntries.dataEntries {
 		key = encodeDataKey(dataEntry.key)
-		if val, err = encodeDataValue(dataEntry.value); err != nil {
+		if val, err = encodeDataValue(dataEntry.value, s.encryptor); err != nil {
 			return err
 		}
 		batch.Put(key, val)
@@ -261,6 +318,18 @@ func (s *Store) Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtD
 		batch.Put(key, val)
 	}
 
+	for _, hashedIndexKey := range storeEntries.hashedIndexEntries {
+		batch.Put(encodeHashedIndexKey(hashedIndexKey), emptyValue)
+	}
+
+	for _, purge := range pvtdataPurges {
+		markerKey := &purgeMarkerKey{ns: purge.Namespace, coll: purge.Collection, keyHash: purge.KeyHash}
+		// the purges are listed in the order of the transactions, hence the marker
+		// ends up holding the height of the latest purge of the key
+		batch.Put(encodePurgeMarkerKey(markerKey), encodePurgeMarkerVal(purge.BlockNum, purge.TxNum))
+		batch.Put(encodePurgeMarkerForBackgroundProcKey(markerKey, purge.BlockNum, purge.TxNum), emptyValue)
+	}
+
 	committingBlockNum := s.nextBlockNum()
 	logger.Debugf("Committing private data for block [%d]", committingBlockNum)
 	batch.Put(lastCommittedBlkkey, encodeLastCommittedBlockVal(committingBlockNum))
@@ -271,10 +340,35 @@ func (s *Store) Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtD
 	s.isEmpty = false
 	atomic.StoreUint64(&s.lastCommittedBlock, committingBlockNum)
 	logger.Debugf("Committed private data for block [%d]", committingBlockNum)
+	if len(pvtdataPurges) > 0 {
+		s.purgeMarkerProcSync.notify()
+	}
+	if atomic.LoadUint32(&s.collInelgEventsPending) == 1 {
+		s.collInelgProcSync.notify()
+	}
 	s.performPurgeIfScheduled(committingBlockNum)
 	return nil
 }
 
+// IsPurged returns true if the private data key, identified by its hash, was purged by a
+// transaction committed after the height <blkNum, txNum>. Private data written at that
+// height is not expected to be present anymore and is not accepted by the reconciliation
+func (s *Store) IsPurged(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error) {
+	purgeHeight, err := s.getPurgeHeight(&purgeMarkerKey{ns: ns, coll: coll, keyHash: keyHash})
+	if err != nil || purgeHeight == nil {
+		return false, err
+	}
+	return purgeHeight.Compare(version.NewHeight(blkNum, txNum)) > 0, nil
+}
+
+func (s *Store) getPurgeHeight(key *purgeMarkerKey) (*version.Height, error) {
+	v, err := s.db.Get(encodePurgeMarkerKey(key))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return decodePurgeMarkerVal(v)
+}
+
 // GetLastUpdatedOldBlocksPvtData returns the pvtdata of blocks listed in `lastUpdatedOldBlocksList`
 // If we decide to rebuild stateDB in v2.0, by default, the rebuild logic would take
 // care of synching stateDB with pvtdataStore without calling GetLastUpdatedOldBlocksPvtData().
@@ -387,7 +481,7 @@ func (s *Store) GetPvtDataByBlockNum(blockNum uint64, filter ledger.PvtNsCollFil
 		if expired || !passesFilter(dataKey, filter) {
 			continue
 		}
-		dataValue, err := decodeDataValue(dataValueBytes)
+		dataValue, err := decodeDataValue(dataValueBytes, s.encryptor)
 		if err != nil {
 			return nil, err
 		}
@@ -423,14 +517,27 @@ func (s *Store) GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.M
 	if time.Now().After(s.accessDeprioMissingDataAfter) {
 		s.accessDeprioMissingDataAfter = time.Now().Add(s.deprioritizedDataReconcilerInterval)
 		logger.Debug("fetching missing pvtdata entries from the deprioritized list")
-		return s.getMissingData(elgDeprioritizedMissingDataGroup, maxBlock)
+		return s.getMissingData(elgDeprioritizedMissingDataGroup, maxBlock, nil)
 	}
 
 	logger.Debug("fetching missing pvtdata entries from the prioritized list")
-	return s.getMissingData(elgPrioritizedMissingDataGroup, maxBlock)
+	return s.getMissingData(elgPrioritizedMissingDataGroup, maxBlock, nil)
 }
 
-func (s *Store) getMissingData(group []byte, maxBlock int) (ledger.MissingPvtDataInfo, error) {
+// GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks returns the missing private data information of the
+// collections selected by the filter for the most recent `maxBlock` blocks which miss at least a private data
+// of such a collection. Only the prioritized missing data entries are considered, the deprioritized ones are
+// left to GetMissingPvtDataInfoForMostRecentBlocks()
+func (s *Store) GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(maxBlock int, filter ledger.MissingPvtDataFilter) (ledger.MissingPvtDataInfo, error) {
+	if maxBlock < 1 {
+		return nil, nil
+	}
+	return s.getMissingData(elgPrioritizedMissingDataGroup, maxBlock, filter)
+}
+
+// getMissingData returns the missing data entries of the given group for the most recent `maxBlock` blocks.
+// When a filter is given, the entries of the collections it does not select are skipped
+func (s *Store) getMissingData(group []byte, maxBlock int, filter ledger.MissingPvtDataFilter) (ledger.MissingPvtDataInfo, error) {
 	missingPvtDataInfo := make(ledger.MissingPvtDataInfo)
 	numberOfBlockProcessed := 0
 	lastProcessedBlock := uint64(0)
@@ -451,6 +558,9 @@ func (s *Store) getMissingData(group []byte, maxBlock int) (ledger.MissingPvtDat
 	for dbItr.Next() {
 		missingDataKeyBytes := dbItr.Key()
 		missingDataKey := decodeElgMissingDataKey(missingDataKeyBytes)
+		if filter != nil && !filter(missingDataKey.ns, missingDataKey.coll) {
+			continue
+		}
 
 		if isMaxBlockLimitReached && (missingDataKey.blkNum != lastProcessedBlock) {
 			// ensures that exactly maxBlock number
@@ -504,6 +614,76 @@ func (s *Store) getMissingData(group []byte, maxBlock int) (ledger.MissingPvtDat
 	return missingPvtDataInfo, nil
 }
 
+// GetMissingPvtDataSummary summarizes, per collection, the missing private data of eligible collections
+// that has not expired. Unlike GetMissingPvtDataInfoForMostRecentBlocks(), it covers both the prioritized
+// and the deprioritized missing data entries
+func (s *Store) GetMissingPvtDataSummary() ([]*ledger.CollMissingPvtDataSummary, error) {
+	lastCommittedBlock := atomic.LoadUint64(&s.lastCommittedBlock)
+	summaries := make(map[[2]string]*ledger.CollMissingPvtDataSummary)
+
+	for _, group := range [][]byte{elgPrioritizedMissingDataGroup, elgDeprioritizedMissingDataGroup} {
+		deprioritized := bytes.Equal(group, elgDeprioritizedMissingDataGroup)
+		startKey, endKey := createRangeScanKeysForElgMissingData(lastCommittedBlock, group)
+		if err := s.scanElgMissingData(startKey, endKey, lastCommittedBlock, func(key *missingDataKey, numTxs uint64) {
+			summary, ok := summaries[[2]string{key.ns, key.coll}]
+			if !ok {
+				summary = &ledger.CollMissingPvtDataSummary{
+					Namespace:      key.ns,
+					Collection:     key.coll,
+					OldestBlockNum: key.blkNum,
+				}
+				summaries[[2]string{key.ns, key.coll}] = summary
+			}
+			summary.Count += numTxs
+			if deprioritized {
+				summary.Deprioritized += numTxs
+			}
+			if key.blkNum < summary.OldestBlockNum {
+				summary.OldestBlockNum = key.blkNum
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []*ledger.CollMissingPvtDataSummary
+	for _, summary := range summaries {
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Collection < result[j].Collection
+	})
+	return result, nil
+}
+
+func (s *Store) scanElgMissingData(startKey, endKey []byte, lastCommittedBlock uint64, process func(key *missingDataKey, numTxs uint64)) error {
+	dbItr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return err
+	}
+	defer dbItr.Release()
+
+	for dbItr.Next() {
+		missingDataKey := decodeElgMissingDataKey(dbItr.Key())
+		expired, err := isExpired(missingDataKey.nsCollBlk, s.btlPolicy, lastCommittedBlock)
+		if err != nil {
+			return err
+		}
+		if expired {
+			continue
+		}
+		bitmap, err := decodeMissingDataValue(dbItr.Value())
+		if err != nil {
+			return err
+		}
+		process(missingDataKey, uint64(bitmap.Count()))
+	}
+	return nil
+}
+
 // ProcessCollsEligibilityEnabled notifies the store when the peer becomes eligible to receive data for an
 // existing collection. Parameter 'committingBlk' refers to the block number that contains the corresponding
 // collection upgrade transaction and the parameter 'nsCollMap' contains the collections for which the peer
@@ -524,6 +704,61 @@ func (s *Store) ProcessCollsEligibilityEnabled(committingBlk uint64, nsCollMap m
 	return nil
 }
 
+// ProcessCollsEligibilityDisabled notifies the store when the peer is no longer eligible to receive data for an
+// existing collection. Parameter 'committingBlk' refers to the block number that contains the corresponding
+// collection upgrade transaction and the parameter 'nsCollMap' contains the collections for which the peer
+// is not eligible anymore. Once the block is committed, the private data of these collections committed up to
+// the block is removed from the store in the background and recorded as ineligible missing data instead
+func (s *Store) ProcessCollsEligibilityDisabled(committingBlk uint64, nsCollMap map[string][]string) error {
+	key := encodeCollInelgKey(collInelgKeyPrefix, committingBlk)
+	m := newCollElgInfo(nsCollMap)
+	val, err := encodeCollElgVal(m)
+	if err != nil {
+		return err
+	}
+	batch := s.db.NewUpdateBatch()
+	batch.Put(key, val)
+	if err = s.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	atomic.StoreUint32(&s.collInelgEventsPending, 1)
+	s.collInelgProcSync.notify()
+	return nil
+}
+
+// GetCollsPvtdataRemovalInfo returns the collections for which the peer lost the eligibility along with
+// the status of the removal of their private data from the store. The pending removals are listed first
+func (s *Store) GetCollsPvtdataRemovalInfo() ([]*ledger.CollPvtdataRemovalInfo, error) {
+	var removalInfo []*ledger.CollPvtdataRemovalInfo
+	for _, prefix := range [][]byte{collInelgKeyPrefix, collInelgProcessedKeyPrefix} {
+		startKey, endKey := createRangeScanKeysForCollInelg(prefix)
+		itr, err := s.db.GetIterator(startKey, endKey)
+		if err != nil {
+			return nil, err
+		}
+		for itr.Next() {
+			blkNum := decodeCollInelgKey(itr.Key())
+			collElgInfo, err := decodeCollElgVal(itr.Value())
+			if err != nil {
+				itr.Release()
+				return nil, err
+			}
+			for _, ns := range sortedNamespaces(collElgInfo) {
+				for _, coll := range collElgInfo.NsCollMap[ns].Entries {
+					removalInfo = append(removalInfo, &ledger.CollPvtdataRemovalInfo{
+						Namespace:  ns,
+						Collection: coll,
+						BlockNum:   blkNum,
+						Pending:    bytes.Equal(prefix, collInelgKeyPrefix),
+					})
+				}
+			}
+		}
+		itr.Release()
+	}
+	return removalInfo, nil
+}
+
 func (s *Store) performPurgeIfScheduled(latestCommittedBlk uint64) {
 	if latestCommittedBlk%s.purgeInterval != 0 {
 		return
@@ -552,6 +787,9 @@ func (s *Store) purgeExpiredData(minBlkNum, maxBlkNum uint64) error {
 		dataKeys, missingDataKeys := deriveKeys(expiryEntry)
 
 		for _, dataKey := range dataKeys {
+			if err := s.deleteHashedIndexEntries(batch, dataKey); err != nil {
+				return err
+			}
 			batch.Delete(encodeDataKey(dataKey))
 		}
 
@@ -577,6 +815,21 @@ func (s *Store) purgeExpiredData(minBlkNum, maxBlkNum uint64) error {
 	return nil
 }
 
+func (s *Store) deleteHashedIndexEntries(batch *leveldbhelper.UpdateBatch, dataKey *dataKey) error {
+	dataValueBytes, err := s.db.Get(encodeDataKey(dataKey))
+	if err != nil || dataValueBytes == nil {
+		return err
+	}
+	dataValue, err := decodeDataValue(dataValueBytes, s.encryptor)
+	if err != nil {
+		return err
+	}
+	for _, hashedIndexKey := range deriveHashedIndexKeys(dataKey, dataValue) {
+		batch.Delete(encodeHashedIndexKey(hashedIndexKey))
+	}
+	return nil
+}
+
 func (s *Store) retrieveExpiryEntries(minBlkNum, maxBlkNum uint64) ([]*expiryEntry, error) {
 	startKey, endKey := getExpiryKeysForRangeScan(minBlkNum, maxBlkNum)
 	logger.Debugf("retrieveExpiryEntries(): startKey=%#v, endKey=%#v", startKey, endKey)
@@ -690,6 +943,407 @@ func (s *Store) processCollElgEvents() error {
 	return nil
 }
 
+// buildHashedIndexIfAbsent indexes, by the hashes of the keys, the data entries committed before
+// the store maintained the hashed index, so that the purges locate their historical versions.
+// The data entries in v11 format have been converted, and indexed, by convertV11DataEntriesIfAny
+func (s *Store) buildHashedIndexIfAbsent() error {
+	built, err := s.db.Get(hashedIndexBuiltKey)
+	if err != nil || built != nil {
+		return err
+	}
+
+	startKey, endKey := createRangeScanKeysForData()
+	itr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return err
+	}
+	defer itr.Release()
+
+	batch := s.db.NewUpdateBatch()
+	entriesIndexed := 0
+	for itr.Next() {
+		dataKey, err := decodeDatakey(itr.Key())
+		if err != nil {
+			return err
+		}
+		dataValue, err := decodeDataValue(itr.Value(), s.encryptor)
+		if err != nil {
+			return err
+		}
+		for _, hashedIndexKey := range deriveHashedIndexKeys(dataKey, dataValue) {
+			batch.Put(encodeHashedIndexKey(hashedIndexKey), emptyValue)
+		}
+		entriesIndexed++
+		if batch.Len() > s.maxBatchSize {
+			if err := s.db.WriteBatch(batch, true); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	batch.Put(hashedIndexBuiltKey, emptyValue)
+	if err := s.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	if entriesIndexed > 0 {
+		logger.Infof("[%s] - [%d] Entries of private data storage added to the hashed index", s.ledgerid, entriesIndexed)
+	}
+	return nil
+}
+
+func (s *Store) launchPurgeMarkerProc() {
+	go func() {
+		// process the purges left unprocessed by the previous run
+		if err := s.processPurgeMarkers(); err != nil {
+			logger.Errorw("failed to process private data purges", "err", err)
+		}
+		for {
+			logger.Debugf("Waiting for private data purges")
+			s.purgeMarkerProcSync.waitForNotification()
+			if err := s.processPurgeMarkers(); err != nil {
+				logger.Errorw("failed to process private data purges", "err", err)
+			}
+			s.purgeMarkerProcSync.done()
+		}
+	}()
+}
+
+// processPurgeMarkers removes from the store the historical versions of the purged keys, i.e., the versions
+// committed below the height of the purge. The private write-set of the purging transaction is retained
+func (s *Store) processPurgeMarkers() error {
+	logger.Debugf("Starting to process private data purges")
+	s.purgerLock.Lock()
+	defer s.purgerLock.Unlock()
+	startKey, endKey := createRangeScanKeysForPurgeMarkersForBackgroundProc()
+	markerItr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return err
+	}
+	defer markerItr.Release()
+
+	totalVersionsRemoved := 0
+	for markerItr.Next() {
+		markerKeyBytes := append([]byte(nil), markerItr.Key()...)
+		markerKey, purgeHeight, err := decodePurgeMarkerForBackgroundProcKey(markerKeyBytes)
+		if err != nil {
+			return err
+		}
+		// each purge is written in a batch of its own as the purges may remove
+		// different keys from the same data entry
+		batch := s.db.NewUpdateBatch()
+		versionsRemoved, err := s.removeHistoricalVersions(batch, markerKey, purgeHeight)
+		if err != nil {
+			return err
+		}
+		batch.Delete(markerKeyBytes)
+		if err := s.db.WriteBatch(batch, true); err != nil {
+			return err
+		}
+		logger.Debugf("Removed [%d] historical versions of a key of [ns=%s, coll=%s] purged at height [%d:%d]",
+			versionsRemoved, markerKey.ns, markerKey.coll, purgeHeight.BlockNum, purgeHeight.TxNum)
+		totalVersionsRemoved += versionsRemoved
+	}
+	logger.Debugf("Removed [%d] historical versions of purged private data keys", totalVersionsRemoved)
+	return nil
+}
+
+func (s *Store) removeHistoricalVersions(batch *leveldbhelper.UpdateBatch, key *purgeMarkerKey, purgeHeight *version.Height) (int, error) {
+	startKey, endKey := createRangeScanKeysForHashedIndex(key.ns, key.coll, key.keyHash, purgeHeight.BlockNum, purgeHeight.TxNum)
+	indexItr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return 0, err
+	}
+	defer indexItr.Release()
+
+	versionsRemoved := 0
+	for indexItr.Next() {
+		indexKeyBytes := append([]byte(nil), indexItr.Key()...)
+		indexKey, err := decodeHashedIndexKey(indexKeyBytes)
+		if err != nil {
+			return 0, err
+		}
+		batch.Delete(indexKeyBytes)
+
+		encDataKey := encodeDataKey(&dataKey{nsCollBlk{indexKey.ns, indexKey.coll, indexKey.blkNum}, indexKey.txNum})
+		dataValueBytes, err := s.db.Get(encDataKey)
+		if err != nil {
+			return 0, err
+		}
+		if dataValueBytes == nil {
+			// the data entry has expired
+			continue
+		}
+		dataValue, err := decodeDataValue(dataValueBytes, s.encryptor)
+		if err != nil {
+			return 0, err
+		}
+		removed, err := removeKeyFromPvtRwSet(dataValue, key.keyHash)
+		if err != nil {
+			return 0, err
+		}
+		if !removed {
+			continue
+		}
+		// the (possibly empty) write-set is retained so that the data entry is
+		// neither considered as missing nor fetched again by the reconciler
+		if dataValueBytes, err = encodeDataValue(dataValue, s.encryptor); err != nil {
+			return 0, err
+		}
+		batch.Put(encDataKey, dataValueBytes)
+		versionsRemoved++
+	}
+	return versionsRemoved, nil
+}
+
+func (s *Store) launchCollInelgProc() {
+	go func() {
+		for {
+			logger.Debugf("Waiting for collection ineligibility event")
+			s.collInelgProcSync.waitForNotification()
+			if err := s.processCollInelgEvents(); err != nil {
+				logger.Errorw("failed to process collection ineligibility events", "err", err)
+			}
+			s.collInelgProcSync.done()
+		}
+	}()
+}
+
+// processCollInelgEvents removes the private data of the collections for which the peer lost the eligibility.
+// The processed events are retained to report the status of the removals
+func (s *Store) processCollInelgEvents() error {
+	logger.Debugf("Starting to process collection ineligibility events")
+	s.purgerLock.Lock()
+	defer s.purgerLock.Unlock()
+	atomic.StoreUint32(&s.collInelgEventsPending, 0)
+	startKey, endKey := createRangeScanKeysForCollInelg(collInelgKeyPrefix)
+	eventItr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return err
+	}
+	defer eventItr.Release()
+
+	totalEntriesRemoved := 0
+	for eventItr.Next() {
+		collInelgKey, collInelgVal := eventItr.Key(), eventItr.Value()
+		blkNum := decodeCollInelgKey(collInelgKey)
+		if s.isEmpty || blkNum > atomic.LoadUint64(&s.lastCommittedBlock) {
+			// the private data of the block is yet to be committed
+			atomic.StoreUint32(&s.collInelgEventsPending, 1)
+			continue
+		}
+		collInelgInfo, err := decodeCollElgVal(collInelgVal)
+		if err != nil {
+			return err
+		}
+		logger.Debugf("Processing collection ineligibility event [blkNum=%d], CollElgInfo=%s", blkNum, collInelgInfo)
+		for _, ns := range sortedNamespaces(collInelgInfo) {
+			for _, coll := range collInelgInfo.NsCollMap[ns].Entries {
+				logger.Infof("Removing private data of [ns=%s, coll=%s] committed up to block [%d] as the peer is no longer eligible",
+					ns, coll, blkNum)
+				entriesRemoved, err := s.removeCollPvtdata(ns, coll, blkNum)
+				if err != nil {
+					return err
+				}
+				logger.Infof("Removed all [%d] private data entries for [ns=%s, coll=%s]", entriesRemoved, ns, coll)
+				totalEntriesRemoved += entriesRemoved
+			}
+		}
+		batch := s.db.NewUpdateBatch()
+		batch.Delete(collInelgKey)
+		batch.Put(encodeCollInelgKey(collInelgProcessedKeyPrefix, blkNum), append([]byte(nil), collInelgVal...))
+		if err := s.db.WriteBatch(batch, true); err != nil {
+			return err
+		}
+	}
+	logger.Debugf("Removed [%d] private data entries of the collections for which the peer lost the eligibility", totalEntriesRemoved)
+	return nil
+}
+
+// removeCollPvtdata removes the private data of the collection committed up to the block 'maxBlkNum'. The data entries
+// as well as the eligible missing data entries of a block are turned into an ineligible missing data entry so that
+// the data is fetched by the reconciler again, if the peer regains the eligibility for the collection
+func (s *Store) removeCollPvtdata(ns, coll string, maxBlkNum uint64) (int, error) {
+	removals := map[uint64]*collPvtdataRemoval{}
+	getOrCreateRemoval := func(blkNum uint64) *collPvtdataRemoval {
+		removal, ok := removals[blkNum]
+		if !ok {
+			removal = &collPvtdataRemoval{missingData: &bitset.BitSet{}}
+			removals[blkNum] = removal
+		}
+		return removal
+	}
+
+	for _, group := range [][]byte{elgPrioritizedMissingDataGroup, elgDeprioritizedMissingDataGroup} {
+		startKey, endKey := createRangeScanKeysForElgMissingData(maxBlkNum, group)
+		itr, err := s.db.GetIterator(startKey, endKey)
+		if err != nil {
+			return 0, err
+		}
+		for itr.Next() {
+			key := decodeElgMissingDataKey(itr.Key())
+			if key.ns != ns || key.coll != coll {
+				continue
+			}
+			missingData, err := decodeMissingDataValue(itr.Value())
+			if err != nil {
+				itr.Release()
+				return 0, err
+			}
+			removal := getOrCreateRemoval(key.blkNum)
+			removal.missingData.InPlaceUnion(missingData)
+			removal.deletedKeys = append(removal.deletedKeys, append([]byte(nil), itr.Key()...))
+		}
+		itr.Release()
+	}
+
+	batch := s.db.NewUpdateBatch()
+	// the blocks are finalized in the ascending order, along with the scan of the data entries, so that
+	// a batch never contains the removal of a data entry without the corresponding missing data entry
+	finalizeUpTo := func(blkNum uint64) error {
+		var blkNums []uint64
+		for b := range removals {
+			if b <= blkNum {
+				blkNums = append(blkNums, b)
+			}
+		}
+		sort.Slice(blkNums, func(i, j int) bool { return blkNums[i] < blkNums[j] })
+		for _, b := range blkNums {
+			if err := s.finalizeCollPvtdataRemoval(batch, &missingDataKey{nsCollBlk{ns, coll, b}}, removals[b]); err != nil {
+				return err
+			}
+			delete(removals, b)
+		}
+		if batch.Len() > s.maxBatchSize {
+			if err := s.db.WriteBatch(batch, true); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	}
+
+	entriesRemoved := 0
+	startKey, endKey := createRangeScanKeysForDataUpToBlock(maxBlkNum)
+	dataItr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return 0, err
+	}
+	defer dataItr.Release()
+	for dataItr.Next() {
+		dataKeyBytes := append([]byte(nil), dataItr.Key()...)
+		dataKey, err := decodeDatakey(dataKeyBytes)
+		if err != nil {
+			return 0, err
+		}
+		if dataKey.ns != ns || dataKey.coll != coll {
+			continue
+		}
+		if dataKey.blkNum > 0 {
+			if err := finalizeUpTo(dataKey.blkNum - 1); err != nil {
+				return 0, err
+			}
+		}
+		if err := s.deleteHashedIndexEntries(batch, dataKey); err != nil {
+			return 0, err
+		}
+		removal := getOrCreateRemoval(dataKey.blkNum)
+		removal.missingData.Set(uint(dataKey.txNum))
+		removal.deletedKeys = append(removal.deletedKeys, dataKeyBytes)
+		entriesRemoved++
+	}
+	if err := finalizeUpTo(maxBlkNum); err != nil {
+		return 0, err
+	}
+	return entriesRemoved, s.db.WriteBatch(batch, true)
+}
+
+// collPvtdataRemoval accumulates the removal of the private data of a collection from a block
+type collPvtdataRemoval struct {
+	missingData *bitset.BitSet
+	deletedKeys [][]byte
+}
+
+func (s *Store) finalizeCollPvtdataRemoval(batch *leveldbhelper.UpdateBatch, key *missingDataKey, removal *collPvtdataRemoval) error {
+	for _, k := range removal.deletedKeys {
+		batch.Delete(k)
+	}
+	expired, err := isExpired(key.nsCollBlk, s.btlPolicy, atomic.LoadUint64(&s.lastCommittedBlock))
+	if err != nil || expired {
+		// the expired data is not to be recorded as missing as it is going to be purged
+		return err
+	}
+
+	inelgKey := encodeInelgMissingDataKey(key)
+	existing, err := s.db.Get(inelgKey)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		existingMissingData, err := decodeMissingDataValue(existing)
+		if err != nil {
+			return err
+		}
+		removal.missingData.InPlaceUnion(existingMissingData)
+	}
+	val, err := encodeMissingDataValue(removal.missingData)
+	if err != nil {
+		return err
+	}
+	batch.Put(inelgKey, val)
+
+	// the expiry entry of the block lists the collection as missing data
+	// instead of present data so that the missing data entry gets purged
+	expiringBlk, err := s.btlPolicy.GetExpiringBlock(key.ns, key.coll, key.blkNum)
+	if err != nil || neverExpires(expiringBlk) {
+		return err
+	}
+	expKey := encodeExpiryKey(&expiryKey{expiringBlk: expiringBlk, committingBlk: key.blkNum})
+	expValBytes, err := s.db.Get(expKey)
+	if err != nil {
+		return err
+	}
+	expVal := newExpiryData()
+	if expValBytes != nil {
+		if expVal, err = decodeExpiryValue(expValBytes); err != nil {
+			return err
+		}
+	}
+	delete(expVal.getOrCreateCollections(key.ns).Map, key.coll)
+	expVal.addMissingData(key.ns, key.coll)
+	if expValBytes, err = encodeExpiryValue(expVal); err != nil {
+		return err
+	}
+	batch.Put(expKey, expValBytes)
+	return nil
+}
+
+// launchReencryption re-encrypts, with the current key of the encryptor, the data entries
+// stored in plaintext or encrypted with a previous key. The data entries in v11 format have
+// been converted, and encrypted, by convertV11DataEntriesIfAny
+func (s *Store) launchReencryption() {
+	if s.encryptor == nil {
+		return
+	}
+	go func() {
+		startKey, endKey := createRangeScanKeysForData()
+		reencrypted, err := s.encryptor.Reencrypt(s.db, startKey, endKey, &s.purgerLock)
+		if err != nil {
+			logger.Errorw("failed to re-encrypt private data", "err", err)
+			return
+		}
+		logger.Infof("[%s] - [%d] Entries re-encrypted in private data storage", s.ledgerid, reencrypted)
+	}()
+}
+
+func sortedNamespaces(collElgInfo *CollElgInfo) []string {
+	var namespaces []string
+	for ns := range collElgInfo.NsCollMap {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
 // LastCommittedBlockHeight returns the height of the last committed block
 func (s *Store) LastCommittedBlockHeight() (uint64, error) {
 	if s.isEmpty {
@@ -733,7 +1387,7 @@ type collElgProcSync struct {
 func (c *collElgProcSync) notify() {
 	select {
 	case c.notification <- true:
-		logger.Debugf("Signaled to collection eligibility processing routine")
+		logger.Debugf("Signaled to background processing routine")
 	default: //noop
 		logger.Debugf("Previous signal still pending. Skipping new signal")
 	}
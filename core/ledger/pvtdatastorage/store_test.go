@@ -7,19 +7,27 @@ SPDX-License-Identifier: Apache-2.0
 package pvtdatastorage
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/internal/version"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	btltestutil "github.com/hyperledger/fabric/core/ledger/pvtdatapolicy/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/stretchr/testify/require"
 )
 
@@ -78,10 +86,10 @@ func TestStoreBasicCommitAndRetrieval(t *testing.T) {
 	blk2MissingData.Add(3, "ns-1", "coll-1", true)
 
 	// no pvt data with block 0
-	require.NoError(t, store.Commit(0, nil, nil))
+	require.NoError(t, store.Commit(0, nil, nil, nil))
 
 	// pvt data with block 1 - commit
-	require.NoError(t, store.Commit(1, testData, blk1MissingData))
+	require.NoError(t, store.Commit(1, testData, blk1MissingData, nil))
 
 	// pvt data retrieval for block 0 should return nil
 	var nilFilter ledger.PvtNsCollFilter
@@ -119,7 +127,7 @@ func TestStoreBasicCommitAndRetrieval(t *testing.T) {
 	require.Nil(t, retrievedData)
 
 	// pvt data with block 2 - commit
-	require.NoError(t, store.Commit(2, testData, blk2MissingData))
+	require.NoError(t, store.Commit(2, testData, blk2MissingData, nil))
 
 	// retrieve the stored missing entries using GetMissingPvtDataInfoForMostRecentBlocks
 	// Only the code path of eligible entries would be covered in this unit-test. For
@@ -157,7 +165,7 @@ func TestStoreIteratorError(t *testing.T) {
 	env := NewTestStoreEnv(t, "TestStoreIteratorError", nil, pvtDataConf())
 	defer env.Cleanup()
 	store := env.TestStore
-	require.NoError(t, store.Commit(0, nil, nil))
+	require.NoError(t, store.Commit(0, nil, nil, nil))
 	env.TestStoreProvider.Close()
 	errStr := "internal leveldb error while obtaining db iterator: leveldb: closed"
 
@@ -213,8 +221,8 @@ func TestGetMissingDataInfo(t *testing.T) {
 		blk1MissingData.Add(1, "ns-1", "coll-1", true)
 		blk1MissingData.Add(1, "ns-1", "coll-2", true)
 
-		require.NoError(t, store.Commit(0, nil, nil))
-		require.NoError(t, store.Commit(1, nil, blk1MissingData))
+		require.NoError(t, store.Commit(0, nil, nil, nil))
+		require.NoError(t, store.Commit(1, nil, blk1MissingData, nil))
 
 		deprioritizedList := ledger.MissingPvtDataInfo{
 			1: ledger.MissingBlockPvtdataInfo{
@@ -295,6 +303,57 @@ func TestGetMissingDataInfo(t *testing.T) {
 		}
 	})
 
+	t.Run("summary of prioritized and deprioritized missing data", func(t *testing.T) {
+		store := setup("testGetMissingDataSummary", pvtDataConf())
+
+		summary, err := store.GetMissingPvtDataSummary()
+		require.NoError(t, err)
+		require.Equal(t, []*ledger.CollMissingPvtDataSummary{
+			{Namespace: "ns-1", Collection: "coll-1", Count: 1, OldestBlockNum: 1},
+			{Namespace: "ns-1", Collection: "coll-2", Count: 1, Deprioritized: 1, OldestBlockNum: 1},
+		}, summary)
+	})
+
+	t.Run("prioritized missing data of the selected collections", func(t *testing.T) {
+		store := setup("testGetMissingDataInfoOfCollections", pvtDataConf())
+
+		// construct missing data of coll-2 for blocks 2 and 3
+		for blkNum := uint64(2); blkNum <= 3; blkNum++ {
+			missingData := make(ledger.TxMissingPvtDataMap)
+			missingData.Add(4, "ns-1", "coll-2", true)
+			require.NoError(t, store.Commit(blkNum, nil, missingData, nil))
+		}
+
+		selectColl := func(coll string) ledger.MissingPvtDataFilter {
+			return func(ns, c string) bool {
+				return c == coll
+			}
+		}
+
+		// the most recent blocks with missing data of coll-1 are returned
+		// even though more recent blocks miss data of coll-2 only
+		missingDataInfo, err := store.GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(1, selectColl("coll-1"))
+		require.NoError(t, err)
+		require.Equal(t, ledger.MissingPvtDataInfo{
+			1: ledger.MissingBlockPvtdataInfo{1: {{Namespace: "ns-1", Collection: "coll-1"}}},
+		}, missingDataInfo)
+
+		// the deprioritized missing data of coll-2 in block 1 is not returned
+		missingDataInfo, err = store.GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(5, selectColl("coll-2"))
+		require.NoError(t, err)
+		require.Equal(t, ledger.MissingPvtDataInfo{
+			3: ledger.MissingBlockPvtdataInfo{4: {{Namespace: "ns-1", Collection: "coll-2"}}},
+			2: ledger.MissingBlockPvtdataInfo{4: {{Namespace: "ns-1", Collection: "coll-2"}}},
+		}, missingDataInfo)
+
+		missingDataInfo, err = store.GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(5, selectColl("coll-3"))
+		require.NoError(t, err)
+		require.Empty(t, missingDataInfo)
+
+		missingDataInfo, err = store.GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(0, selectColl("coll-1"))
+		require.NoError(t, err)
+		require.Nil(t, missingDataInfo)
+	})
 }
 
 func TestExpiryDataNotIncluded(t *testing.T) {
@@ -329,21 +388,21 @@ func TestExpiryDataNotIncluded(t *testing.T) {
 	blk2MissingData.Add(1, "ns-1", "coll-2", true)
 
 	// no pvt data with block 0
-	require.NoError(t, store.Commit(0, nil, nil))
+	require.NoError(t, store.Commit(0, nil, nil, nil))
 
 	// write pvt data for block 1
 	testDataForBlk1 := []*ledger.TxPvtData{
 		produceSamplePvtdata(t, 2, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1", "ns-2:coll-2"}),
 		produceSamplePvtdata(t, 4, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1", "ns-2:coll-2"}),
 	}
-	require.NoError(t, store.Commit(1, testDataForBlk1, blk1MissingData))
+	require.NoError(t, store.Commit(1, testDataForBlk1, blk1MissingData, nil))
 
 	// write pvt data for block 2
 	testDataForBlk2 := []*ledger.TxPvtData{
 		produceSamplePvtdata(t, 3, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1", "ns-2:coll-2"}),
 		produceSamplePvtdata(t, 5, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1", "ns-2:coll-2"}),
 	}
-	require.NoError(t, store.Commit(2, testDataForBlk2, blk2MissingData))
+	require.NoError(t, store.Commit(2, testDataForBlk2, blk2MissingData, nil))
 
 	retrievedData, _ := store.GetPvtDataByBlockNum(1, nil)
 	// block 1 data should still be not expired
@@ -367,7 +426,7 @@ func TestExpiryDataNotIncluded(t *testing.T) {
 	require.Equal(t, expectedMissingPvtDataInfo, missingPvtDataInfo)
 
 	// Commit block 3 with no pvtdata
-	require.NoError(t, store.Commit(3, nil, nil))
+	require.NoError(t, store.Commit(3, nil, nil, nil))
 
 	// After committing block 3, the data for "ns-1:coll1" of block 1 should have expired and should not be returned by the store
 	expectedPvtdataFromBlock1 := []*ledger.TxPvtData{
@@ -390,7 +449,7 @@ func TestExpiryDataNotIncluded(t *testing.T) {
 	require.Equal(t, expectedMissingPvtDataInfo, missingPvtDataInfo)
 
 	// Commit block 4 with no pvtdata
-	require.NoError(t, store.Commit(4, nil, nil))
+	require.NoError(t, store.Commit(4, nil, nil, nil))
 
 	// After committing block 4, the data for "ns-2:coll2" of block 1 should also have expired and should not be returned by the store
 	expectedPvtdataFromBlock1 = []*ledger.TxPvtData{
@@ -438,7 +497,7 @@ func TestStorePurge(t *testing.T) {
 	s := env.TestStore
 
 	// no pvt data with block 0
-	require.NoError(t, s.Commit(0, nil, nil))
+	require.NoError(t, s.Commit(0, nil, nil, nil))
 
 	// construct missing data for block 1
 	blk1MissingData := make(ledger.TxMissingPvtDataMap)
@@ -457,10 +516,10 @@ func TestStorePurge(t *testing.T) {
 		produceSamplePvtdata(t, 2, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1", "ns-2:coll-2"}),
 		produceSamplePvtdata(t, 4, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1", "ns-2:coll-2"}),
 	}
-	require.NoError(t, s.Commit(1, testDataForBlk1, blk1MissingData))
+	require.NoError(t, s.Commit(1, testDataForBlk1, blk1MissingData, nil))
 
 	// write pvt data for block 2
-	require.NoError(t, s.Commit(2, nil, nil))
+	require.NoError(t, s.Commit(2, nil, nil, nil))
 	// data for ns-1:coll-1 and ns-2:coll-2 should exist in store
 	ns1Coll1 := &dataKey{nsCollBlk: nsCollBlk{ns: "ns-1", coll: "coll-1", blkNum: 1}, txNum: 2}
 	ns2Coll2 := &dataKey{nsCollBlk: nsCollBlk{ns: "ns-2", coll: "coll-2", blkNum: 1}, txNum: 2}
@@ -500,7 +559,7 @@ func TestStorePurge(t *testing.T) {
 	require.NoError(t, s.CommitPvtDataOfOldBlocks(nil, deprioritizedList))
 
 	// write pvt data for block 3
-	require.NoError(t, s.Commit(3, nil, nil))
+	require.NoError(t, s.Commit(3, nil, nil, nil))
 	// data for ns-1:coll-1 and ns-2:coll-2 should exist in store (because purger should not be launched at block 3)
 	testWaitForPurgerRoutineToFinish(s)
 	require.True(t, testDataKeyExists(t, s, ns1Coll1))
@@ -516,7 +575,7 @@ func TestStorePurge(t *testing.T) {
 	require.True(t, testInelgMissingDataKeyExists(t, s, ns3Coll2inelgMD))
 
 	// write pvt data for block 4
-	require.NoError(t, s.Commit(4, nil, nil))
+	require.NoError(t, s.Commit(4, nil, nil, nil))
 	// data for ns-1:coll-1 should not exist in store (because purger should be launched at block 4)
 	// but ns-2:coll-2 should exist because it expires at block 5
 	testWaitForPurgerRoutineToFinish(s)
@@ -532,14 +591,14 @@ func TestStorePurge(t *testing.T) {
 	require.True(t, testInelgMissingDataKeyExists(t, s, ns3Coll2inelgMD))
 
 	// write pvt data for block 5
-	require.NoError(t, s.Commit(5, nil, nil))
+	require.NoError(t, s.Commit(5, nil, nil, nil))
 	// ns-2:coll-2 should exist because though the data expires at block 5 but purger is launched every second block
 	testWaitForPurgerRoutineToFinish(s)
 	require.False(t, testDataKeyExists(t, s, ns1Coll1))
 	require.True(t, testDataKeyExists(t, s, ns2Coll2))
 
 	// write pvt data for block 6
-	require.NoError(t, s.Commit(6, nil, nil))
+	require.NoError(t, s.Commit(6, nil, nil, nil))
 	// ns-2:coll-2 should not exists now (because purger should be launched at block 6)
 	testWaitForPurgerRoutineToFinish(s)
 	require.False(t, testDataKeyExists(t, s, ns1Coll1))
@@ -562,7 +621,7 @@ func TestStoreState(t *testing.T) {
 	testData := []*ledger.TxPvtData{
 		produceSamplePvtdata(t, 0, []string{"ns-1:coll-1", "ns-1:coll-2"}),
 	}
-	_, ok := store.Commit(1, testData, nil).(*ErrIllegalArgs)
+	_, ok := store.Commit(1, testData, nil, nil).(*ErrIllegalArgs)
 	require.True(t, ok)
 }
 
@@ -593,7 +652,7 @@ func TestPendingBatch(t *testing.T) {
 	dataKey := &dataKey{nsCollBlk{"ns-1", "coll-1", 26}, 1}
 	dataValue := &rwset.CollectionPvtReadWriteSet{CollectionName: "coll-1", Rwset: []byte("pvtdata")}
 	keyBytes := encodeDataKey(dataKey)
-	valueBytes, err := encodeDataValue(dataValue)
+	valueBytes, err := encodeDataValue(dataValue, nil)
 	require.NoError(t, err)
 	batch.Put(keyBytes, valueBytes)
 
@@ -665,7 +724,7 @@ func testCollElgEnabled(t *testing.T, conf *PrivateDataConfig) {
 	// Initial state: eligible for {ns-1:coll-1 and ns-2:coll-1 }
 
 	// no pvt data with block 0
-	require.NoError(t, testStore.Commit(0, nil, nil))
+	require.NoError(t, testStore.Commit(0, nil, nil, nil))
 
 	// construct and commit block 1
 	blk1MissingData := make(ledger.TxMissingPvtDataMap)
@@ -676,7 +735,7 @@ func testCollElgEnabled(t *testing.T, conf *PrivateDataConfig) {
 	testDataForBlk1 := []*ledger.TxPvtData{
 		produceSamplePvtdata(t, 2, []string{"ns-1:coll-1"}),
 	}
-	require.NoError(t, testStore.Commit(1, testDataForBlk1, blk1MissingData))
+	require.NoError(t, testStore.Commit(1, testDataForBlk1, blk1MissingData, nil))
 
 	// construct and commit block 2
 	blk2MissingData := make(ledger.TxMissingPvtDataMap)
@@ -686,7 +745,7 @@ func testCollElgEnabled(t *testing.T, conf *PrivateDataConfig) {
 	testDataForBlk2 := []*ledger.TxPvtData{
 		produceSamplePvtdata(t, 3, []string{"ns-1:coll-1"}),
 	}
-	require.NoError(t, testStore.Commit(2, testDataForBlk2, blk2MissingData))
+	require.NoError(t, testStore.Commit(2, testDataForBlk2, blk2MissingData, nil))
 
 	// Retrieve and verify missing data reported
 	// Expected missing data should be only blk1-tx1 (because, the other missing data is marked as ineliigible)
@@ -733,6 +792,108 @@ func testCollElgEnabled(t *testing.T, conf *PrivateDataConfig) {
 	require.Equal(t, expectedMissingPvtDataInfo, missingPvtDataInfo)
 }
 
+func TestCollElgDisabled(t *testing.T) {
+	conf := pvtDataConf()
+	testCollElgDisabled(t, conf)
+	conf.MaxBatchSize = 1
+	testCollElgDisabled(t, conf)
+}
+
+func testCollElgDisabled(t *testing.T, conf *PrivateDataConfig) {
+	btlPolicy := btltestutil.SampleBTLPolicy(
+		map[[2]string]uint64{
+			{"ns-1", "coll-1"}: 0,
+			{"ns-1", "coll-2"}: 0,
+			{"ns-2", "coll-1"}: 2,
+		},
+	)
+	env := NewTestStoreEnv(t, "TestCollElgDisabled", btlPolicy, conf)
+	defer env.Cleanup()
+	testStore := env.TestStore
+
+	require.NoError(t, testStore.Commit(0, nil, nil, nil))
+	blk1MissingData := make(ledger.TxMissingPvtDataMap)
+	blk1MissingData.Add(1, "ns-1", "coll-1", true)
+	require.NoError(t, testStore.Commit(1,
+		[]*ledger.TxPvtData{produceSamplePvtdata(t, 2, []string{"ns-1:coll-1", "ns-1:coll-2", "ns-2:coll-1"})},
+		blk1MissingData, nil))
+	require.NoError(t, testStore.Commit(2,
+		[]*ledger.TxPvtData{produceSamplePvtdata(t, 3, []string{"ns-1:coll-1"})}, nil, nil))
+
+	// the peer loses the eligibility for {ns-1:coll-1, ns-2:coll-1} with block 3
+	require.NoError(t, testStore.ProcessCollsEligibilityDisabled(3,
+		map[string][]string{
+			"ns-1": {"coll-1"},
+			"ns-2": {"coll-1"},
+		},
+	))
+	removalInfo := func(pending bool) []*ledger.CollPvtdataRemovalInfo {
+		return []*ledger.CollPvtdataRemovalInfo{
+			{Namespace: "ns-1", Collection: "coll-1", BlockNum: 3, Pending: pending},
+			{Namespace: "ns-2", Collection: "coll-1", BlockNum: 3, Pending: pending},
+		}
+	}
+	info, err := testStore.GetCollsPvtdataRemovalInfo()
+	require.NoError(t, err)
+	require.Equal(t, removalInfo(true), info)
+
+	// the removal waits for the commit of block 3
+	testutilWaitForCollInelgProcToFinish(testStore)
+	require.True(t, testDataKeyExists(t, testStore, &dataKey{nsCollBlk{"ns-1", "coll-1", 2}, 3}))
+
+	require.NoError(t, testStore.Commit(3,
+		[]*ledger.TxPvtData{produceSamplePvtdata(t, 1, []string{"ns-1:coll-1"})}, nil, nil))
+	require.Eventually(t, func() bool {
+		info, err := testStore.GetCollsPvtdataRemovalInfo()
+		require.NoError(t, err)
+		return reflect.DeepEqual(removalInfo(false), info)
+	}, time.Minute, 10*time.Millisecond)
+
+	// only the pvtdata of the collections for which the peer is still eligible remains
+	retrievedData, err := testStore.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(produceSamplePvtdata(t, 2, []string{"ns-1:coll-2"}).WriteSet, retrievedData[0].WriteSet))
+	for _, blkNum := range []uint64{2, 3} {
+		retrievedData, err = testStore.GetPvtDataByBlockNum(blkNum, nil)
+		require.NoError(t, err)
+		require.Empty(t, retrievedData)
+	}
+	startKey, endKey := createRangeScanKeysForHashedIndex("ns-1", "coll-1", util.ComputeStringHash("key-ns-1-coll-1"), 4, 0)
+	itr, err := testStore.db.GetIterator(startKey, endKey)
+	require.NoError(t, err)
+	require.False(t, itr.Next())
+	itr.Release()
+
+	// the removed pvtdata is recorded as ineligible missing data
+	missingPvtDataInfo, err := testStore.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	require.NoError(t, err)
+	require.Empty(t, missingPvtDataInfo)
+	require.True(t, testInelgMissingDataKeyExists(t, testStore, &missingDataKey{nsCollBlk{"ns-1", "coll-1", 1}}))
+	require.True(t, testInelgMissingDataKeyExists(t, testStore, &missingDataKey{nsCollBlk{"ns-2", "coll-1", 1}}))
+
+	// and gets reconciled again when the peer regains the eligibility
+	require.NoError(t, testStore.ProcessCollsEligibilityEnabled(4, map[string][]string{"ns-1": {"coll-1"}}))
+	testutilWaitForCollElgProcToFinish(testStore)
+	expectedMissingPvtDataInfo := make(ledger.MissingPvtDataInfo)
+	expectedMissingPvtDataInfo.Add(3, 1, "ns-1", "coll-1")
+	expectedMissingPvtDataInfo.Add(2, 3, "ns-1", "coll-1")
+	expectedMissingPvtDataInfo.Add(1, 1, "ns-1", "coll-1")
+	expectedMissingPvtDataInfo.Add(1, 2, "ns-1", "coll-1")
+	missingPvtDataInfo, err = testStore.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	require.NoError(t, err)
+	require.Equal(t, expectedMissingPvtDataInfo, missingPvtDataInfo)
+
+	// the ineligible missing data of the collection with a btl expires
+	require.NoError(t, testStore.Commit(4, nil, nil, nil))
+	testWaitForPurgerRoutineToFinish(testStore)
+	require.False(t, testInelgMissingDataKeyExists(t, testStore, &missingDataKey{nsCollBlk{"ns-2", "coll-1", 1}}))
+}
+
+func testutilWaitForCollInelgProcToFinish(s *Store) {
+	s.collInelgProcSync.waitForDone()
+}
+
 func testLastCommittedBlockHeight(t *testing.T, expectedBlockHt uint64, store *Store) {
 	blkHt, err := store.LastCommittedBlockHeight()
 	require.NoError(t, err)
@@ -769,6 +930,306 @@ func testInelgMissingDataKeyExists(t *testing.T, s *Store, missingDataKey *missi
 	return len(val) != 0
 }
 
+func TestPvtdataKeyPurge(t *testing.T) {
+	btlPolicy := btltestutil.SampleBTLPolicy(
+		map[[2]string]uint64{
+			{"ns-1", "coll-1"}: 0,
+		},
+	)
+	env := NewTestStoreEnv(t, "TestPvtdataKeyPurge", btlPolicy, pvtDataConf())
+	defer env.Cleanup()
+	store := env.TestStore
+	keyHash1 := util.ComputeStringHash("key-1")
+
+	require.NoError(t, store.Commit(0, nil, nil, nil))
+	// block 1 writes key-1 and key-2 and misses the pvtdata of tx2
+	blk1MissingData := make(ledger.TxMissingPvtDataMap)
+	blk1MissingData.Add(2, "ns-1", "coll-1", true)
+	require.NoError(t, store.Commit(1,
+		[]*ledger.TxPvtData{producePvtdataWithKeys(t, 1, "key-1", "key-2")},
+		blk1MissingData, nil))
+	// block 2 writes key-1 again
+	require.NoError(t, store.Commit(2, []*ledger.TxPvtData{producePvtdataWithKeys(t, 0, "key-1")}, nil, nil))
+	// block 3 purges key-1
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSetForPurge("ns-1", "coll-1", "key-1")
+	simRes, err := builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	purgeTxPvtdata := &ledger.TxPvtData{SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}
+	purges := []*ledger.PvtdataPurge{
+		{Namespace: "ns-1", Collection: "coll-1", KeyHash: keyHash1, BlockNum: 3, TxNum: 0},
+	}
+	require.NoError(t, store.Commit(3, []*ledger.TxPvtData{purgeTxPvtdata}, nil, purges))
+	testutilWaitForPurgeMarkerProcToFinish(store)
+
+	// the historical versions of key-1 are removed, the purge itself is retained
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(producePvtdataWithKeys(t, 1, "key-2").WriteSet, retrievedData[0].WriteSet))
+	retrievedData, err = store.GetPvtDataByBlockNum(2, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(producePvtdataWithKeys(t, 0).WriteSet, retrievedData[0].WriteSet))
+	retrievedData, err = store.GetPvtDataByBlockNum(3, nil)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(purgeTxPvtdata.WriteSet, retrievedData[0].WriteSet))
+
+	// the hashed index entries of the removed versions are deleted
+	startKey, endKey := createRangeScanKeysForHashedIndex("ns-1", "coll-1", keyHash1, 3, 0)
+	itr, err := store.db.GetIterator(startKey, endKey)
+	require.NoError(t, err)
+	require.False(t, itr.Next())
+	itr.Release()
+
+	purged, err := store.IsPurged("ns-1", "coll-1", keyHash1, 2, 0)
+	require.NoError(t, err)
+	require.True(t, purged)
+	purged, err = store.IsPurged("ns-1", "coll-1", keyHash1, 3, 0)
+	require.NoError(t, err)
+	require.False(t, purged)
+	purged, err = store.IsPurged("ns-1", "coll-1", util.ComputeStringHash("key-2"), 1, 1)
+	require.NoError(t, err)
+	require.False(t, purged)
+
+	// the reconciled pvtdata of block 1 does not bring back the purged key
+	require.NoError(t, store.CommitPvtDataOfOldBlocks(
+		map[uint64][]*ledger.TxPvtData{1: {producePvtdataWithKeys(t, 2, "key-1", "key-3")}}, nil))
+	retrievedData, err = store.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 2)
+	require.True(t, proto.Equal(producePvtdataWithKeys(t, 2, "key-3").WriteSet, retrievedData[1].WriteSet))
+	missingDataInfo, err := store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	require.NoError(t, err)
+	require.Empty(t, missingDataInfo)
+}
+
+func TestPvtdataKeyPurgeOfDataCommittedBeforeHashedIndex(t *testing.T) {
+	btlPolicy := btltestutil.SampleBTLPolicy(
+		map[[2]string]uint64{
+			{"ns-1", "coll-1"}: 0,
+		},
+	)
+	env := NewTestStoreEnv(t, "TestPvtdataKeyPurgeOfDataCommittedBeforeHashedIndex", btlPolicy, pvtDataConf())
+	defer env.Cleanup()
+	keyHash1 := util.ComputeStringHash("key-1")
+
+	require.NoError(t, env.TestStore.Commit(0, nil, nil, nil))
+	require.NoError(t, env.TestStore.Commit(1, []*ledger.TxPvtData{producePvtdataWithKeys(t, 1, "key-1", "key-2")}, nil, nil))
+
+	// remove the hashed index as if the data was committed before the store maintained it
+	startKey, endKey := hashedIndexKeyPrefix, []byte{hashedIndexKeyPrefix[0] + 1}
+	itr, err := env.TestStore.db.GetIterator(startKey, endKey)
+	require.NoError(t, err)
+	batch := env.TestStore.db.NewUpdateBatch()
+	for itr.Next() {
+		batch.Delete(append([]byte(nil), itr.Key()...))
+	}
+	itr.Release()
+	batch.Delete(hashedIndexBuiltKey)
+	require.NoError(t, env.TestStore.db.WriteBatch(batch, true))
+
+	// the index is built when the store is opened
+	env.CloseAndReopen()
+	store := env.TestStore
+	built, err := store.db.Get(hashedIndexBuiltKey)
+	require.NoError(t, err)
+	require.NotNil(t, built)
+
+	// block 2 purges key-1
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSetForPurge("ns-1", "coll-1", "key-1")
+	simRes, err := builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	purges := []*ledger.PvtdataPurge{
+		{Namespace: "ns-1", Collection: "coll-1", KeyHash: keyHash1, BlockNum: 2, TxNum: 0},
+	}
+	require.NoError(t, store.Commit(2, []*ledger.TxPvtData{{SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}}, nil, purges))
+	testutilWaitForPurgeMarkerProcToFinish(store)
+
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(producePvtdataWithKeys(t, 1, "key-2").WriteSet, retrievedData[0].WriteSet))
+}
+
+func TestPvtdataKeyPurgeOfDataCommittedInV11Format(t *testing.T) {
+	btlPolicy := btltestutil.SampleBTLPolicy(
+		map[[2]string]uint64{
+			{"ns-1", "coll-1"}: 0,
+		},
+	)
+	env := NewTestStoreEnv(t, "TestPvtdataKeyPurgeOfDataCommittedInV11Format", btlPolicy, pvtDataConf())
+	defer env.Cleanup()
+	keyHash1 := util.ComputeStringHash("key-1")
+
+	require.NoError(t, env.TestStore.Commit(0, nil, nil, nil))
+	require.NoError(t, env.TestStore.Commit(1, []*ledger.TxPvtData{producePvtdataWithKeys(t, 1, "key-1", "key-2")}, nil, nil))
+	rewriteInV11Format(t, env.TestStore, 1)
+
+	// the data entries in v11 format are converted when the store is opened
+	env.CloseAndReopen()
+	store := env.TestStore
+	itr, err := store.db.GetIterator(createRangeScanKeysForData())
+	require.NoError(t, err)
+	for itr.Next() {
+		v11Fmt, err := v11Format(itr.Key())
+		require.NoError(t, err)
+		require.False(t, v11Fmt)
+	}
+	itr.Release()
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(producePvtdataWithKeys(t, 1, "key-1", "key-2").WriteSet, retrievedData[0].WriteSet))
+
+	// block 2 purges key-1
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToPvtAndHashedWriteSetForPurge("ns-1", "coll-1", "key-1")
+	simRes, err := builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	purges := []*ledger.PvtdataPurge{
+		{Namespace: "ns-1", Collection: "coll-1", KeyHash: keyHash1, BlockNum: 2, TxNum: 0},
+	}
+	require.NoError(t, store.Commit(2, []*ledger.TxPvtData{{SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}}, nil, purges))
+	testutilWaitForPurgeMarkerProcToFinish(store)
+
+	retrievedData, err = store.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(producePvtdataWithKeys(t, 1, "key-2").WriteSet, retrievedData[0].WriteSet))
+}
+
+func TestStoreEncryption(t *testing.T) {
+	btlPolicy := btltestutil.SampleBTLPolicy(
+		map[[2]string]uint64{
+			{"ns-1", "coll-1"}: 0,
+			{"ns-1", "coll-2"}: 0,
+		},
+	)
+	csp, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewInMemoryKeyStore())
+	require.NoError(t, err)
+	newEncryptor := func() *pvtdataencryption.Encryptor {
+		key, err := csp.KeyGen(&bccsp.AES256KeyGenOpts{})
+		require.NoError(t, err)
+		encryptor, err := pvtdataencryption.New(csp, &pvtdataencryption.Config{
+			Enabled:               true,
+			KeyID:                 hex.EncodeToString(key.SKI()),
+			ReencryptionBatchSize: 1,
+		})
+		require.NoError(t, err)
+		return encryptor
+	}
+	requireEncryptedWith := func(s *Store, encryptor *pvtdataencryption.Encryptor) {
+		require.Eventually(t, func() bool {
+			itr, err := s.db.GetIterator(createRangeScanKeysForData())
+			require.NoError(t, err)
+			defer itr.Release()
+			for itr.Next() {
+				if !pvtdataencryption.IsEncrypted(itr.Value()) || encryptor.NeedsReencryption(itr.Value()) {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	requirePvtData := func(s *Store, blkNum uint64, expectedData []*ledger.TxPvtData) {
+		retrievedData, err := s.GetPvtDataByBlockNum(blkNum, nil)
+		require.NoError(t, err)
+		require.Len(t, retrievedData, len(expectedData))
+		for i, data := range retrievedData {
+			require.Equal(t, expectedData[i].SeqInBlock, data.SeqInBlock)
+			require.True(t, proto.Equal(expectedData[i].WriteSet, data.WriteSet))
+		}
+	}
+
+	// the private data committed before the encryption is enabled is stored in plaintext
+	conf := pvtDataConf()
+	env := NewTestStoreEnv(t, "TestStoreEncryption", btlPolicy, conf)
+	defer env.Cleanup()
+	blk1MissingData := make(ledger.TxMissingPvtDataMap)
+	blk1MissingData.Add(3, "ns-1", "coll-1", true)
+	blk1Data := []*ledger.TxPvtData{produceSamplePvtdata(t, 2, []string{"ns-1:coll-1", "ns-1:coll-2"})}
+	require.NoError(t, env.TestStore.Commit(0, nil, nil, nil))
+	require.NoError(t, env.TestStore.Commit(1, blk1Data, blk1MissingData, nil))
+
+	// once the encryption is enabled, the private data committed, as well as the reconciled
+	// private data, is encrypted and the private data stored in plaintext is re-encrypted
+	conf.Encryptor = newEncryptor()
+	env.CloseAndReopen()
+	store := env.TestStore
+	blk2Data := []*ledger.TxPvtData{produceSamplePvtdata(t, 1, []string{"ns-1:coll-1"})}
+	require.NoError(t, store.Commit(2, blk2Data, nil, nil))
+	reconciledData := produceSamplePvtdata(t, 3, []string{"ns-1:coll-1"})
+	require.NoError(t, store.CommitPvtDataOfOldBlocks(map[uint64][]*ledger.TxPvtData{1: {reconciledData}}, nil))
+	blk1Data = append(blk1Data, reconciledData)
+
+	requireEncryptedWith(store, conf.Encryptor)
+	requirePvtData(store, 1, blk1Data)
+	requirePvtData(store, 2, blk2Data)
+
+	// the private data is re-encrypted once the key is rotated
+	conf.Encryptor = newEncryptor()
+	env.CloseAndReopen()
+	store = env.TestStore
+	requireEncryptedWith(store, conf.Encryptor)
+	requirePvtData(store, 1, blk1Data)
+	requirePvtData(store, 2, blk2Data)
+
+	// the encrypted private data cannot be read once the encryption is disabled
+	conf.Encryptor = nil
+	env.CloseAndReopen()
+	_, err = env.TestStore.GetPvtDataByBlockNum(1, nil)
+	require.EqualError(t, err, "the private data is encrypted but the encryption of private data is not enabled")
+}
+
+func TestStoreEncryptionOfDataCommittedInV11Format(t *testing.T) {
+	btlPolicy := btltestutil.SampleBTLPolicy(
+		map[[2]string]uint64{
+			{"ns-1", "coll-1"}: 0,
+		},
+	)
+	conf := pvtDataConf()
+	env := NewTestStoreEnv(t, "TestStoreEncryptionOfDataCommittedInV11Format", btlPolicy, conf)
+	defer env.Cleanup()
+	blk1Data := []*ledger.TxPvtData{producePvtdataWithKeys(t, 1, "key-1", "key-2")}
+	require.NoError(t, env.TestStore.Commit(0, nil, nil, nil))
+	require.NoError(t, env.TestStore.Commit(1, blk1Data, nil, nil))
+	rewriteInV11Format(t, env.TestStore, 1)
+
+	// the data entries in v11 format are encrypted once the encryption is enabled
+	csp, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewInMemoryKeyStore())
+	require.NoError(t, err)
+	key, err := csp.KeyGen(&bccsp.AES256KeyGenOpts{})
+	require.NoError(t, err)
+	conf.Encryptor, err = pvtdataencryption.New(csp, &pvtdataencryption.Config{
+		Enabled: true,
+		KeyID:   hex.EncodeToString(key.SKI()),
+	})
+	require.NoError(t, err)
+	env.CloseAndReopen()
+	store := env.TestStore
+
+	itr, err := store.db.GetIterator(createRangeScanKeysForData())
+	require.NoError(t, err)
+	dataEntries := 0
+	for itr.Next() {
+		v11Fmt, err := v11Format(itr.Key())
+		require.NoError(t, err)
+		require.False(t, v11Fmt)
+		require.True(t, pvtdataencryption.IsEncrypted(itr.Value()))
+		require.False(t, conf.Encryptor.NeedsReencryption(itr.Value()))
+		dataEntries++
+	}
+	itr.Release()
+	require.Equal(t, 1, dataEntries)
+
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	require.NoError(t, err)
+	require.Len(t, retrievedData, 1)
+	require.True(t, proto.Equal(blk1Data[0].WriteSet, retrievedData[0].WriteSet))
+}
+
 func testWaitForPurgerRoutineToFinish(s *Store) {
 	time.Sleep(1 * time.Second)
 	s.purgerLock.Lock()
@@ -779,6 +1240,10 @@ func testutilWaitForCollElgProcToFinish(s *Store) {
 	s.collElgProcSync.waitForDone()
 }
 
+func testutilWaitForPurgeMarkerProcToFinish(s *Store) {
+	s.purgeMarkerProcSync.waitForDone()
+}
+
 func produceSamplePvtdata(t *testing.T, txNum uint64, nsColls []string) *ledger.TxPvtData {
 	builder := rwsetutil.NewRWSetBuilder()
 	for _, nsColl := range nsColls {
@@ -791,3 +1256,50 @@ func produceSamplePvtdata(t *testing.T, txNum uint64, nsColls []string) *ledger.
 	require.NoError(t, err)
 	return &ledger.TxPvtData{SeqInBlock: txNum, WriteSet: simRes.PvtSimulationResults}
 }
+
+func producePvtdataWithKeys(t *testing.T, txNum uint64, keys ...string) *ledger.TxPvtData {
+	builder := rwsetutil.NewRWSetBuilder()
+	for _, key := range keys {
+		builder.AddToPvtAndHashedWriteSet("ns-1", "coll-1", key, []byte("value-"+key))
+	}
+	simRes, err := builder.GetTxSimulationResults()
+	require.NoError(t, err)
+	if simRes.PvtSimulationResults == nil {
+		// keep a write-set for the collection with no write, as left by a purge
+		emptyRwset, err := proto.Marshal(&kvrwset.KVRWSet{})
+		require.NoError(t, err)
+		simRes.PvtSimulationResults = &rwset.TxPvtReadWriteSet{
+			DataModel: rwset.TxReadWriteSet_KV,
+			NsPvtRwset: []*rwset.NsPvtReadWriteSet{{
+				Namespace:          "ns-1",
+				CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{{CollectionName: "coll-1", Rwset: emptyRwset}},
+			}},
+		}
+	}
+	return &ledger.TxPvtData{SeqInBlock: txNum, WriteSet: simRes.PvtSimulationResults}
+}
+
+// rewriteInV11Format replaces the data entries of the store, which holds the private data of
+// the given block only, and their hashed index entries with data entries in v11 format, as
+// committed by a v1.1 peer
+func rewriteInV11Format(t *testing.T, s *Store, blkNum uint64) {
+	pvtdata, err := s.GetPvtDataByBlockNum(blkNum, nil)
+	require.NoError(t, err)
+	batch := s.db.NewUpdateBatch()
+	for _, prefix := range [][]byte{pvtDataKeyPrefix, hashedIndexKeyPrefix} {
+		itr, err := s.db.GetIterator(prefix, []byte{prefix[0] + 1})
+		require.NoError(t, err)
+		for itr.Next() {
+			batch.Delete(append([]byte(nil), itr.Key()...))
+		}
+		itr.Release()
+	}
+	for _, txPvtdata := range pvtdata {
+		v11Key := append(append([]byte(nil), pvtDataKeyPrefix...), version.NewHeight(blkNum, txPvtdata.SeqInBlock).ToBytes()...)
+		v11Value, err := proto.Marshal(txPvtdata.WriteSet)
+		require.NoError(t, err)
+		batch.Put(v11Key, v11Value)
+	}
+	batch.Delete(v11DataConvertedKey)
+	require.NoError(t, s.db.WriteBatch(batch, true))
+}
@@ -99,3 +99,76 @@ func v11TrimPvtWSet(pvtWSet *rwset.TxPvtReadWriteSet, filter ledger.PvtNsCollFil
 	}
 	return filteredTxPvtRwSet
 }
+
+// convertV11DataEntriesIfAny rewrites the data entries in v11 format, each of which holds the
+// private write-set of a transaction, into a data entry per collection as committed by the
+// later versions, and adds them to the hashed index. This makes the purges, the removal of
+// the private data of ineligible collections and the encryption apply to these entries as well.
+// As the converted entries are subject to the BTL of their collections, the entries that have
+// expired are no longer returned
+func (s *Store) convertV11DataEntriesIfAny() error {
+	converted, err := s.db.Get(v11DataConvertedKey)
+	if err != nil || converted != nil {
+		return err
+	}
+
+	startKey, endKey := createRangeScanKeysForData()
+	itr, err := s.db.GetIterator(startKey, endKey)
+	if err != nil {
+		return err
+	}
+	defer itr.Release()
+
+	batch := s.db.NewUpdateBatch()
+	entriesConverted := 0
+	for itr.Next() {
+		dataKeyBytes := itr.Key()
+		v11Fmt, err := v11Format(dataKeyBytes)
+		if err != nil {
+			return err
+		}
+		if !v11Fmt {
+			continue
+		}
+		blkNum, txNum, err := v11DecodePK(dataKeyBytes)
+		if err != nil {
+			return err
+		}
+		pvtWSet, err := v11DecodePvtRwSet(itr.Value())
+		if err != nil {
+			return err
+		}
+		for _, nsPvtRwset := range pvtWSet.NsPvtRwset {
+			for _, collPvtRwset := range nsPvtRwset.CollectionPvtRwset {
+				dataKey := &dataKey{
+					nsCollBlk: nsCollBlk{ns: nsPvtRwset.Namespace, coll: collPvtRwset.CollectionName, blkNum: blkNum},
+					txNum:     txNum,
+				}
+				dataValue, err := encodeDataValue(collPvtRwset, s.encryptor)
+				if err != nil {
+					return err
+				}
+				batch.Put(encodeDataKey(dataKey), dataValue)
+				for _, hashedIndexKey := range deriveHashedIndexKeys(dataKey, collPvtRwset) {
+					batch.Put(encodeHashedIndexKey(hashedIndexKey), emptyValue)
+				}
+			}
+		}
+		batch.Delete(dataKeyBytes)
+		entriesConverted++
+		if batch.Len() > s.maxBatchSize {
+			if err := s.db.WriteBatch(batch, true); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	batch.Put(v11DataConvertedKey, emptyValue)
+	if err := s.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	if entriesConverted > 0 {
+		logger.Infof("[%s] - [%d] Entries of private data storage in v11 format converted", s.ledgerid, entriesConverted)
+	}
+	return nil
+}
@@ -46,6 +46,31 @@ type ExternalBuilder struct {
 	Path                 string   `yaml:"path"`
 }
 
+// ChaincodeProcess configures the runtime which builds Go chaincode with the
+// local Go toolchain and runs it as child processes of the peer.
+type ChaincodeProcess struct {
+	// Enabled enables the process runtime.
+	Enabled bool
+	// MaxOpenFiles limits the number of files a chaincode process may open.
+	// Zero means no limit.
+	MaxOpenFiles uint64
+	// MaxMemory limits the virtual memory of a chaincode process in bytes.
+	// Zero means no limit.
+	MaxMemory uint64
+}
+
+// ChaincodeWasm bounds the execution of WebAssembly chaincode, which is run
+// inside the peer. Zero values select the defaults of the runtime.
+type ChaincodeWasm struct {
+	// Gas is the number of instructions a transaction may execute. Calls to
+	// the peer consume additional gas.
+	Gas uint64
+	// MaxMemory limits the memory of the chaincode in bytes.
+	MaxMemory uint64
+	// MaxCallDepth limits the depth of nested function calls.
+	MaxCallDepth int
+}
+
 // Config is the struct that defines the Peer configurations.
 type Config struct {
 	// LocalMSPID is the identifier of the local MSP.
@@ -160,6 +185,11 @@ type Config struct {
 	// chaincode. The external builder detection processing will iterate over the
 	// builders in the order specified below.
 	ExternalBuilders []ExternalBuilder
+	// ChaincodeProcess configures running Go chaincode as processes of the
+	// peer instead of in Docker containers.
+	ChaincodeProcess ChaincodeProcess
+	// ChaincodeWasm bounds the execution of WebAssembly chaincode.
+	ChaincodeWasm ChaincodeWasm
 
 	// ----- Operations config -----
 	// TODO: create separate sub-struct for Operations config.
@@ -293,6 +323,22 @@ func (c *Config) load() error {
 		}
 	}
 
+	c.ChaincodeProcess = ChaincodeProcess{
+		Enabled:   viper.GetBool("chaincode.process.enabled"),
+		MaxMemory: uint64(viper.GetSizeInBytes("chaincode.process.limits.maxMemory")),
+	}
+	if maxOpenFiles := viper.GetInt("chaincode.process.limits.maxOpenFiles"); maxOpenFiles > 0 {
+		c.ChaincodeProcess.MaxOpenFiles = uint64(maxOpenFiles)
+	}
+
+	c.ChaincodeWasm = ChaincodeWasm{
+		MaxMemory:    uint64(viper.GetSizeInBytes("chaincode.wasm.maxMemory")),
+		MaxCallDepth: viper.GetInt("chaincode.wasm.maxCallDepth"),
+	}
+	if gas := viper.GetInt("chaincode.wasm.gas"); gas > 0 {
+		c.ChaincodeWasm.Gas = uint64(gas)
+	}
+
 	c.OperationsListenAddress = viper.GetString("operations.listenAddress")
 	c.OperationsTLSEnabled = viper.GetBool("operations.tls.enabled")
 	c.OperationsTLSCertFile = config.GetPath("operations.tls.cert.file")
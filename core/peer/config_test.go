@@ -270,6 +270,12 @@ func TestGlobalConfig(t *testing.T) {
 			Name: "absolute",
 		},
 	})
+	viper.Set("chaincode.process.enabled", true)
+	viper.Set("chaincode.process.limits.maxOpenFiles", 1024)
+	viper.Set("chaincode.process.limits.maxMemory", "512 MB")
+	viper.Set("chaincode.wasm.gas", 5000000)
+	viper.Set("chaincode.wasm.maxMemory", "32 MB")
+	viper.Set("chaincode.wasm.maxCallDepth", 500)
 
 	coreConfig, err := GlobalConfig()
 	assert.NoError(t, err)
@@ -312,6 +318,16 @@ func TestGlobalConfig(t *testing.T) {
 				Name: "absolute",
 			},
 		},
+		ChaincodeProcess: ChaincodeProcess{
+			Enabled:      true,
+			MaxOpenFiles: 1024,
+			MaxMemory:    512 * 1024 * 1024,
+		},
+		ChaincodeWasm: ChaincodeWasm{
+			Gas:          5000000,
+			MaxMemory:    32 * 1024 * 1024,
+			MaxCallDepth: 500,
+		},
 		OperationsListenAddress:         "127.0.0.1:9443",
 		OperationsTLSEnabled:            false,
 		OperationsTLSCertFile:           filepath.Join(cwd, "test/tls/cert/file"),
@@ -239,6 +239,17 @@ type PeerLedger struct {
 		result1 bool
 		result2 error
 	}
+	GetCollsPvtdataRemovalInfoStub        func() ([]*ledger.CollPvtdataRemovalInfo, error)
+	getCollsPvtdataRemovalInfoMutex       sync.RWMutex
+	getCollsPvtdataRemovalInfoArgsForCall []struct{}
+	getCollsPvtdataRemovalInfoReturns     struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}
+	getCollsPvtdataRemovalInfoReturnsOnCall map[int]struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -1146,6 +1157,49 @@ func (fake *PeerLedger) DoesPvtDataInfoExistReturnsOnCall(i int, result1 bool, r
 	}{result1, result2}
 }
 
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfo() ([]*ledger.CollPvtdataRemovalInfo, error) {
+	fake.getCollsPvtdataRemovalInfoMutex.Lock()
+	ret, specificReturn := fake.getCollsPvtdataRemovalInfoReturnsOnCall[len(fake.getCollsPvtdataRemovalInfoArgsForCall)]
+	fake.getCollsPvtdataRemovalInfoArgsForCall = append(fake.getCollsPvtdataRemovalInfoArgsForCall, struct{}{})
+	fake.recordInvocation("GetCollsPvtdataRemovalInfo", []interface{}{})
+	fake.getCollsPvtdataRemovalInfoMutex.Unlock()
+	if fake.GetCollsPvtdataRemovalInfoStub != nil {
+		return fake.GetCollsPvtdataRemovalInfoStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getCollsPvtdataRemovalInfoReturns.result1, fake.getCollsPvtdataRemovalInfoReturns.result2
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoCallCount() int {
+	fake.getCollsPvtdataRemovalInfoMutex.RLock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.RUnlock()
+	return len(fake.getCollsPvtdataRemovalInfoArgsForCall)
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoReturns(result1 []*ledger.CollPvtdataRemovalInfo, result2 error) {
+	fake.GetCollsPvtdataRemovalInfoStub = nil
+	fake.getCollsPvtdataRemovalInfoReturns = struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoReturnsOnCall(i int, result1 []*ledger.CollPvtdataRemovalInfo, result2 error) {
+	fake.GetCollsPvtdataRemovalInfoStub = nil
+	if fake.getCollsPvtdataRemovalInfoReturnsOnCall == nil {
+		fake.getCollsPvtdataRemovalInfoReturnsOnCall = make(map[int]struct {
+			result1 []*ledger.CollPvtdataRemovalInfo
+			result2 error
+		})
+	}
+	fake.getCollsPvtdataRemovalInfoReturnsOnCall[i] = struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *PeerLedger) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -1187,6 +1241,8 @@ func (fake *PeerLedger) Invocations() map[string][][]interface{} {
 	defer fake.getMissingPvtDataTrackerMutex.RUnlock()
 	fake.doesPvtDataInfoExistMutex.RLock()
 	defer fake.doesPvtDataInfoExistMutex.RUnlock()
+	fake.getCollsPvtdataRemovalInfoMutex.RLock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
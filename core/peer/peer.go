@@ -29,6 +29,7 @@ import (
 	validation "github.com/hyperledger/fabric/core/handlers/validation/api/state"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
 	storeapi "github.com/hyperledger/fabric/extensions/collections/api/store"
 	"github.com/hyperledger/fabric/extensions/collections/storeprovider"
 	"github.com/hyperledger/fabric/extensions/gossip/blockpublisher"
@@ -191,6 +192,7 @@ type Peer struct {
 	LedgerMgr                *ledgermgmt.LedgerMgr
 	OrdererEndpointOverrides map[string]*orderers.Endpoint
 	CryptoProvider           bccsp.BCCSP
+	PvtDataAccessLog         *pvtdataaccesslog.AccessLog
 
 	// validationWorkersSemaphore is used to limit the number of concurrent validation
 	// go routines.
@@ -377,6 +379,7 @@ func (p *Peer) createChannel(
 		CollDataStore:      collDataStore,
 		Ledger:             l,
 		BlockPublisher:     blockpublisher.ForChannel(cid),
+		PvtDataAccessLog:   p.PvtDataAccessLog,
 	})
 
 	p.mutex.Lock()
@@ -421,6 +424,19 @@ func (p *Peer) GetChannelsInfo() []*pb.ChannelInfo {
 	return channelInfos
 }
 
+// ChannelConfigs returns the channel configuration of every channel of
+// this peer by channel ID.
+func (p *Peer) ChannelConfigs() map[string]channelconfig.Resources {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	configs := make(map[string]channelconfig.Resources, len(p.channels))
+	for cid, c := range p.channels {
+		configs[cid] = c.Resources()
+	}
+	return configs
+}
+
 // GetChannelConfig returns the channel configuration of the channel with channel ID. Note that this
 // call returns nil if channel cid has not been created.
 func (p *Peer) GetChannelConfig(cid string) channelconfig.Resources {
@@ -116,6 +116,7 @@ func NewTestPeer(t *testing.T) (*Peer, func()) {
 	assert.NoError(t, err)
 	transientStoreProvider, err := transientstoreext.NewStoreProvider(
 		filepath.Join(tempdir, "transientstore"),
+		nil,
 	)
 	assert.NoError(t, err)
 	peerInstance := &Peer{
@@ -240,6 +241,11 @@ func TestCreateChannel(t *testing.T) {
 	if len(channels) != 1 {
 		t.Fatalf("incorrect number of channels")
 	}
+
+	configs := peerInstance.ChannelConfigs()
+	if len(configs) != 1 || configs[testChannelID] == nil {
+		t.Fatalf("incorrect channel configs")
+	}
 }
 
 func TestDeliverSupportManager(t *testing.T) {
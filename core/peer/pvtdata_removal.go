@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ErrChannelNotFound is returned when the status of a channel
+// the peer has not joined is requested
+var ErrChannelNotFound = errors.New("channel not found")
+
+// PvtdataRemovalStatus is the status of the removal of the private data of
+// the collections for which the peer lost the eligibility on a channel
+type PvtdataRemovalStatus struct {
+	Channel     string                      `json:"channel"`
+	Collections []*CollPvtdataRemovalStatus `json:"collections"`
+}
+
+// CollPvtdataRemovalStatus is the status of the removal of the private data of a collection.
+// BlockNum is the block in which the peer lost the eligibility for the collection and Pending
+// is true as long as the private data of the collection has not been removed
+type CollPvtdataRemovalStatus struct {
+	Chaincode  string `json:"chaincode"`
+	Collection string `json:"collection"`
+	BlockNum   uint64 `json:"block_num"`
+	Pending    bool   `json:"pending"`
+}
+
+// PvtdataRemovalStatus returns the status of the removal of the private data of the collections for
+// which the peer lost the eligibility on the given channel, or on all the channels if channelID is empty
+func (p *Peer) PvtdataRemovalStatus(channelID string) ([]*PvtdataRemovalStatus, error) {
+	p.mutex.RLock()
+	channels := make(map[string]*Channel, len(p.channels))
+	for cid, c := range p.channels {
+		if channelID == "" || cid == channelID {
+			channels[cid] = c
+		}
+	}
+	p.mutex.RUnlock()
+
+	if channelID != "" && len(channels) == 0 {
+		return nil, ErrChannelNotFound
+	}
+
+	statuses := []*PvtdataRemovalStatus{}
+	for cid, c := range channels {
+		removalInfo, err := c.Ledger().GetCollsPvtdataRemovalInfo()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get the private data removal status of channel %s", cid)
+		}
+		status := &PvtdataRemovalStatus{
+			Channel:     cid,
+			Collections: []*CollPvtdataRemovalStatus{},
+		}
+		for _, info := range removalInfo {
+			status.Collections = append(status.Collections, &CollPvtdataRemovalStatus{
+				Chaincode:  info.Namespace,
+				Collection: info.Collection,
+				BlockNum:   info.BlockNum,
+				Pending:    info.Pending,
+			})
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Channel < statuses[j].Channel
+	})
+	return statuses, nil
+}
+
+// PvtdataRemovalHandler serves the status of the removal of the private data of
+// the collections for which the peer lost the eligibility as JSON. The optional
+// query parameter 'channel' restricts the status to a single channel.
+type PvtdataRemovalHandler struct {
+	Peer *Peer
+}
+
+func (h *PvtdataRemovalHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses, err := h.Peer.PvtdataRemovalStatus(req.URL.Query().Get("channel"))
+	switch {
+	case err == ErrChannelNotFound:
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		peerLogger.Errorf("failed to get the private data removal status: %s", err)
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(statuses); err != nil {
+		peerLogger.Errorf("failed to encode the private data removal status: %s", err)
+	}
+}
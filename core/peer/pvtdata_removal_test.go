@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type removalInfoLedger struct {
+	ledger.PeerLedger
+	removalInfo []*ledger.CollPvtdataRemovalInfo
+	err         error
+}
+
+func (l *removalInfoLedger) GetCollsPvtdataRemovalInfo() ([]*ledger.CollPvtdataRemovalInfo, error) {
+	return l.removalInfo, l.err
+}
+
+func TestPvtdataRemovalHandler(t *testing.T) {
+	p := &Peer{
+		channels: map[string]*Channel{
+			"ch2": {ledger: &removalInfoLedger{
+				removalInfo: []*ledger.CollPvtdataRemovalInfo{
+					{Namespace: "marbles", Collection: "coll2", BlockNum: 12, Pending: true},
+					{Namespace: "marbles", Collection: "coll1", BlockNum: 7},
+				},
+			}},
+			"ch1": {ledger: &removalInfoLedger{}},
+		},
+	}
+	handler := &PvtdataRemovalHandler{Peer: p}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, target, nil))
+		return resp
+	}
+
+	resp := get("/pvtdata/removal")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	var statuses []*PvtdataRemovalStatus
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statuses))
+	require.Equal(t, []*PvtdataRemovalStatus{
+		{Channel: "ch1", Collections: []*CollPvtdataRemovalStatus{}},
+		{
+			Channel: "ch2",
+			Collections: []*CollPvtdataRemovalStatus{
+				{Chaincode: "marbles", Collection: "coll2", BlockNum: 12, Pending: true},
+				{Chaincode: "marbles", Collection: "coll1", BlockNum: 7},
+			},
+		},
+	}, statuses)
+
+	resp = get("/pvtdata/removal?channel=ch1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, `[{"channel":"ch1","collections":[]}]`+"\n", resp.Body.String())
+
+	resp = get("/pvtdata/removal?channel=ch3")
+	require.Equal(t, http.StatusNotFound, resp.Code)
+	require.Equal(t, "channel not found\n", resp.Body.String())
+
+	p.channels["ch2"].ledger = &removalInfoLedger{err: errors.New("leveldb: closed")}
+	resp = get("/pvtdata/removal")
+	require.Equal(t, http.StatusInternalServerError, resp.Code)
+	require.Equal(t, "failed to get the private data removal status of channel ch2: leveldb: closed\n", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/pvtdata/removal", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
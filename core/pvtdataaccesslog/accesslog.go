@@ -0,0 +1,304 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataaccesslog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var logger = flogging.MustGetLogger("pvtdataaccesslog")
+
+// ErrNotEnabled is returned when the access log is queried while it is not enabled
+var ErrNotEnabled = errors.New("the private data access log is not enabled")
+
+var (
+	entryKeyPrefix = []byte{'e'}
+	timeKeyPrefix  = []byte{'t'}
+)
+
+const maxPurgeBatchSize = 1000
+
+// Entry records the access to a private data key by a client, through
+// chaincode, or by another peer, which pulled the private data
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Channel    string    `json:"channel"`
+	TxID       string    `json:"tx_id,omitempty"`
+	Chaincode  string    `json:"chaincode"`
+	Collection string    `json:"collection"`
+	Key        string    `json:"key"`
+	// MSPID and CertHash identify the client on behalf of which chaincode read the private data.
+	// CertHash is the hex encoded SHA256 hash of the certificate of the client.
+	MSPID    string `json:"msp_id,omitempty"`
+	CertHash string `json:"cert_hash,omitempty"`
+	// PKIID and Endpoint identify the peer which pulled the private data.
+	// PKIID is hex encoded.
+	PKIID    string `json:"pki_id,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// Query selects the entries of the access log of a channel. The entries are
+// selected by chaincode, by collection of a chaincode or by key of a collection.
+type Query struct {
+	Chaincode  string
+	Collection string
+	Key        string
+	// Since excludes the entries recorded before it, if set
+	Since time.Time
+	// Limit is the maximum number of entries returned, if set
+	Limit int
+}
+
+func (q *Query) validate() error {
+	if q.Key != "" && q.Collection == "" {
+		return errors.New("the collection must be specified to query the accesses to a key")
+	}
+	if q.Collection != "" && q.Chaincode == "" {
+		return errors.New("the chaincode must be specified to query the accesses to a collection")
+	}
+	return nil
+}
+
+// AccessLog records the accesses to the private data of the collections
+// listed in its configuration and purges them after their retention period.
+// A nil AccessLog records nothing.
+type AccessLog struct {
+	conf        *Config
+	collections map[string]map[string]struct{}
+	dbInst      *leveldbhelper.DB
+	seq         uint64
+	now         func() time.Time
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+	purgeWG  sync.WaitGroup
+}
+
+// New opens the access log stored at the given path. If the access log is
+// not enabled nothing is opened and nothing is recorded.
+func New(path string, conf *Config) (*AccessLog, error) {
+	l := &AccessLog{
+		conf:     conf,
+		seq:      uint64(time.Now().UnixNano()),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+	if !conf.Enabled {
+		return l, nil
+	}
+
+	if len(conf.Collections) > 0 {
+		l.collections = map[string]map[string]struct{}{}
+		for _, c := range conf.Collections {
+			if c.Chaincode == "" {
+				return nil, errors.New("the chaincode of the collections of the private data access log must be specified")
+			}
+			if _, ok := l.collections[c.Chaincode]; !ok {
+				l.collections[c.Chaincode] = map[string]struct{}{}
+			}
+			l.collections[c.Chaincode][c.Collection] = struct{}{}
+		}
+	}
+
+	l.dbInst = leveldbhelper.CreateDB(&leveldbhelper.Conf{DBPath: path})
+	l.dbInst.Open()
+	if conf.Retention > 0 {
+		l.purgeWG.Add(1)
+		go l.purgePeriodically()
+	}
+	return l, nil
+}
+
+// Enabled returns true if the accesses to the private data of the collection
+// are recorded
+func (l *AccessLog) Enabled(namespace, collection string) bool {
+	if l == nil || !l.conf.Enabled {
+		return false
+	}
+	if l.collections == nil {
+		return true
+	}
+	colls, ok := l.collections[namespace]
+	if !ok {
+		return false
+	}
+	if _, ok := colls[collection]; ok {
+		return true
+	}
+	_, ok = colls[""]
+	return ok
+}
+
+// Record records the given entries in the access log of the channel. The
+// time of the entries which have none is set to the current time.
+func (l *AccessLog) Record(channelID string, entries ...*Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if l == nil || !l.conf.Enabled {
+		return ErrNotEnabled
+	}
+
+	now := l.now()
+	batch := &leveldb.Batch{}
+	for _, entry := range entries {
+		entry.Channel = channelID
+		if entry.Time.IsZero() {
+			entry.Time = now
+		}
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal private data access log entry")
+		}
+		suffix := make([]byte, 16)
+		binary.BigEndian.PutUint64(suffix, uint64(entry.Time.UnixNano()))
+		binary.BigEndian.PutUint64(suffix[8:], atomic.AddUint64(&l.seq, 1))
+
+		entryKey := append(entryKeyPrefixFor(channelID, entry.Chaincode, entry.Collection, entry.Key), suffix...)
+		batch.Put(entryKey, value)
+		batch.Put(append(append([]byte{}, timeKeyPrefix...), suffix...), entryKey)
+	}
+	if err := l.dbInst.WriteBatch(batch, false); err != nil {
+		return errors.WithMessage(err, "failed to record private data access")
+	}
+	return nil
+}
+
+// Query returns the entries of the access log of the channel selected by the
+// query, ordered by chaincode, collection, key and time
+func (l *AccessLog) Query(channelID string, q *Query) ([]*Entry, error) {
+	if l == nil || !l.conf.Enabled {
+		return nil, ErrNotEnabled
+	}
+
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	var components []string
+	switch {
+	case q.Key != "":
+		components = []string{q.Chaincode, q.Collection, q.Key}
+	case q.Collection != "":
+		components = []string{q.Chaincode, q.Collection}
+	case q.Chaincode != "":
+		components = []string{q.Chaincode}
+	}
+
+	prefix := entryKeyPrefixFor(channelID, components...)
+	keyRange := util.BytesPrefix(prefix)
+	itr := l.dbInst.GetIterator(keyRange.Start, keyRange.Limit)
+	defer itr.Release()
+
+	var entries []*Entry
+	for itr.Next() {
+		if q.Limit > 0 && len(entries) >= q.Limit {
+			break
+		}
+		entry := &Entry{}
+		if err := json.Unmarshal(itr.Value(), entry); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal private data access log entry")
+		}
+		if entry.Time.Before(q.Since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := itr.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to query private data access log")
+	}
+	return entries, nil
+}
+
+// PurgeBefore removes the entries recorded before the given time from the
+// access logs of all the channels and returns the number of entries removed
+func (l *AccessLog) PurgeBefore(t time.Time) (int, error) {
+	if l == nil || !l.conf.Enabled {
+		return 0, nil
+	}
+
+	endKey := make([]byte, len(timeKeyPrefix)+8)
+	copy(endKey, timeKeyPrefix)
+	binary.BigEndian.PutUint64(endKey[len(timeKeyPrefix):], uint64(t.UnixNano()))
+
+	purged := 0
+	for {
+		itr := l.dbInst.GetIterator(timeKeyPrefix, endKey)
+		batch := &leveldb.Batch{}
+		for batch.Len() < 2*maxPurgeBatchSize && itr.Next() {
+			batch.Delete(append([]byte{}, itr.Key()...))
+			batch.Delete(append([]byte{}, itr.Value()...))
+		}
+		err := itr.Error()
+		itr.Release()
+		if err != nil {
+			return purged, errors.Wrap(err, "failed to purge private data access log")
+		}
+		if batch.Len() == 0 {
+			return purged, nil
+		}
+		if err := l.dbInst.WriteBatch(batch, true); err != nil {
+			return purged, errors.WithMessage(err, "failed to purge private data access log")
+		}
+		purged += batch.Len() / 2
+	}
+}
+
+// Close stops the purge of the access log and closes it
+func (l *AccessLog) Close() {
+	if l == nil || !l.conf.Enabled {
+		return
+	}
+	l.stopOnce.Do(func() {
+		close(l.stopChan)
+		l.purgeWG.Wait()
+		l.dbInst.Close()
+	})
+}
+
+func (l *AccessLog) purgePeriodically() {
+	defer l.purgeWG.Done()
+	ticker := time.NewTicker(l.conf.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopChan:
+			return
+		case <-ticker.C:
+			purged, err := l.PurgeBefore(l.now().Add(-l.conf.Retention))
+			if err != nil {
+				logger.Errorf("Failed to purge the private data access log: %s", err)
+				continue
+			}
+			if purged > 0 {
+				logger.Infof("Purged %d entries older than %s from the private data access log", purged, l.conf.Retention)
+			}
+		}
+	}
+}
+
+// entryKeyPrefixFor encodes the channel and the given components, each
+// prefixed with its length so that a prefix of the components only
+// matches the keys which start with the same complete components
+func entryKeyPrefixFor(channelID string, components ...string) []byte {
+	key := append([]byte{}, entryKeyPrefix...)
+	for _, c := range append([]string{channelID}, components...) {
+		key = append(key, proto.EncodeVarint(uint64(len(c)))...)
+		key = append(key, c...)
+	}
+	return key
+}
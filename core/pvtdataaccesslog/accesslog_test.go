@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataaccesslog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccessLog(t *testing.T, conf *Config) *AccessLog {
+	path, err := ioutil.TempDir("", "pvtdataaccesslog")
+	require.NoError(t, err)
+	l, err := New(path, conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		l.Close()
+		os.RemoveAll(path)
+	})
+	return l
+}
+
+func TestEnabled(t *testing.T) {
+	var nilLog *AccessLog
+	require.False(t, nilLog.Enabled("cc1", "coll1"))
+
+	l := newTestAccessLog(t, &Config{})
+	require.False(t, l.Enabled("cc1", "coll1"))
+	require.Equal(t, ErrNotEnabled, l.Record("ch1", &Entry{}))
+	_, err := l.Query("ch1", &Query{})
+	require.Equal(t, ErrNotEnabled, err)
+
+	l = newTestAccessLog(t, &Config{Enabled: true})
+	require.True(t, l.Enabled("cc1", "coll1"))
+
+	l = newTestAccessLog(t, &Config{
+		Enabled: true,
+		Collections: []Collection{
+			{Chaincode: "cc1"},
+			{Chaincode: "cc2", Collection: "coll1"},
+		},
+	})
+	require.True(t, l.Enabled("cc1", "coll1"))
+	require.True(t, l.Enabled("cc1", "coll2"))
+	require.True(t, l.Enabled("cc2", "coll1"))
+	require.False(t, l.Enabled("cc2", "coll2"))
+	require.False(t, l.Enabled("cc3", "coll1"))
+
+	_, err = New("", &Config{Enabled: true, Collections: []Collection{{Collection: "coll1"}}})
+	require.EqualError(t, err, "the chaincode of the collections of the private data access log must be specified")
+}
+
+func TestRecordAndQuery(t *testing.T) {
+	l := newTestAccessLog(t, &Config{Enabled: true})
+	now := time.Unix(1000, 0).UTC()
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.Record("ch1",
+		&Entry{TxID: "tx1", Chaincode: "cc1", Collection: "coll1", Key: "key1", MSPID: "Org1MSP", CertHash: "ab01"},
+		&Entry{TxID: "tx1", Chaincode: "cc1", Collection: "coll1", Key: "key2", MSPID: "Org1MSP", CertHash: "ab01"},
+		// a key which is a prefix of another key does not select the accesses to the other key
+		&Entry{Chaincode: "cc1", Collection: "coll1", Key: "key", PKIID: "0a0b", Endpoint: "peer1:7051"},
+	))
+	require.NoError(t, l.Record("ch1", &Entry{Time: now.Add(time.Minute), Chaincode: "cc1", Collection: "coll2", Key: "key1"}))
+	require.NoError(t, l.Record("ch2", &Entry{Chaincode: "cc1", Collection: "coll1", Key: "key1"}))
+	require.NoError(t, l.Record("ch1"))
+
+	entries, err := l.Query("ch1", &Query{Chaincode: "cc1", Collection: "coll1", Key: "key1"})
+	require.NoError(t, err)
+	require.Equal(t, []*Entry{
+		{Time: now, Channel: "ch1", TxID: "tx1", Chaincode: "cc1", Collection: "coll1", Key: "key1", MSPID: "Org1MSP", CertHash: "ab01"},
+	}, entries)
+
+	entries, err = l.Query("ch1", &Query{Chaincode: "cc1", Collection: "coll1"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "key", entries[0].Key)
+	require.Equal(t, "peer1:7051", entries[0].Endpoint)
+
+	entries, err = l.Query("ch1", &Query{Chaincode: "cc1"})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	entries, err = l.Query("ch1", &Query{Chaincode: "cc1", Since: now.Add(time.Second)})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "coll2", entries[0].Collection)
+
+	entries, err = l.Query("ch1", &Query{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entries, err = l.Query("ch2", &Query{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entries, err = l.Query("ch3", &Query{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	_, err = l.Query("ch1", &Query{Chaincode: "cc1", Key: "key1"})
+	require.EqualError(t, err, "the collection must be specified to query the accesses to a key")
+	_, err = l.Query("ch1", &Query{Collection: "coll1"})
+	require.EqualError(t, err, "the chaincode must be specified to query the accesses to a collection")
+}
+
+func TestPurgeBefore(t *testing.T) {
+	l := newTestAccessLog(t, &Config{Enabled: true})
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 2*maxPurgeBatchSize+1; i++ {
+		require.NoError(t, l.Record("ch1", &Entry{Time: now, Chaincode: "cc1", Collection: "coll1", Key: "key1"}))
+	}
+	require.NoError(t, l.Record("ch2", &Entry{Time: now.Add(time.Hour), Chaincode: "cc1", Collection: "coll1", Key: "key1"}))
+
+	purged, err := l.PurgeBefore(now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2*maxPurgeBatchSize+1, purged)
+
+	entries, err := l.Query("ch1", &Query{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	entries, err = l.Query("ch2", &Query{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	purged, err = l.PurgeBefore(now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Zero(t, purged)
+}
+
+func TestPurgePeriodically(t *testing.T) {
+	l := newTestAccessLog(t, &Config{Enabled: true, Retention: time.Hour, PurgeInterval: 10 * time.Millisecond})
+
+	require.NoError(t, l.Record("ch1",
+		&Entry{Time: time.Now().Add(-2 * time.Hour), Chaincode: "cc1", Collection: "coll1", Key: "key1"},
+		&Entry{Chaincode: "cc1", Collection: "coll1", Key: "key2"},
+	))
+	require.Eventually(t, func() bool {
+		entries, err := l.Query("ch1", &Query{})
+		return err == nil && len(entries) == 1 && entries[0].Key == "key2"
+	}, 5*time.Second, 10*time.Millisecond)
+}
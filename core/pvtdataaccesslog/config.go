@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataaccesslog
+
+import (
+	"time"
+
+	viper "github.com/spf13/viper2015"
+)
+
+const (
+	retentionDefault     = 90 * 24 * time.Hour
+	purgeIntervalDefault = time.Hour
+)
+
+// Config is the configuration of the private data access log
+type Config struct {
+	// Enabled is a flag that indicates whether the accesses to private data are recorded.
+	Enabled bool
+	// Collections lists the collections whose accesses are recorded. The accesses to
+	// all the collections are recorded if it is empty.
+	Collections []Collection
+	// Retention is the time during which an access is kept in the log. The accesses are
+	// never removed if it is negative.
+	Retention time.Duration
+	// PurgeInterval is the interval at which the accesses older than Retention are removed.
+	PurgeInterval time.Duration
+}
+
+// Collection identifies a collection of a chaincode. If Collection is empty,
+// it identifies all the collections of the chaincode.
+type Collection struct {
+	Chaincode  string
+	Collection string
+}
+
+// GlobalConfig obtains the configuration of the private data access log from viper
+func GlobalConfig() *Config {
+	c := &Config{
+		Enabled:       viper.GetBool("ledger.pvtdataAccessLog.enabled"),
+		Retention:     viper.GetDuration("ledger.pvtdataAccessLog.retention"),
+		PurgeInterval: viper.GetDuration("ledger.pvtdataAccessLog.purgeInterval"),
+	}
+	if err := viper.UnmarshalKey("ledger.pvtdataAccessLog.collections", &c.Collections); err != nil {
+		logger.Warningf("Ignoring invalid configuration key ledger.pvtdataAccessLog.collections: %s", err)
+		c.Collections = nil
+	}
+	if c.Retention == 0 {
+		c.Retention = retentionDefault
+	}
+	if c.PurgeInterval <= 0 {
+		c.PurgeInterval = purgeIntervalDefault
+	}
+	return c
+}
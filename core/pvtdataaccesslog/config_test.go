@@ -0,0 +1,47 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataaccesslog
+
+import (
+	"testing"
+	"time"
+
+	viper "github.com/spf13/viper2015"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalConfig(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("ledger.pvtdataAccessLog.enabled", true)
+	viper.Set("ledger.pvtdataAccessLog.collections", []map[string]interface{}{
+		{"chaincode": "cc1"},
+		{"chaincode": "cc2", "collection": "coll1"},
+	})
+	viper.Set("ledger.pvtdataAccessLog.retention", "24h")
+	viper.Set("ledger.pvtdataAccessLog.purgeInterval", "10m")
+
+	require.Equal(t, &Config{
+		Enabled: true,
+		Collections: []Collection{
+			{Chaincode: "cc1"},
+			{Chaincode: "cc2", Collection: "coll1"},
+		},
+		Retention:     24 * time.Hour,
+		PurgeInterval: 10 * time.Minute,
+	}, GlobalConfig())
+}
+
+func TestGlobalConfigDefaults(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	require.Equal(t, &Config{
+		Retention:     90 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}, GlobalConfig())
+}
@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataaccesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Handler serves the entries of the access log selected by the channel,
+// chaincode, collection, key, since and limit query parameters
+type Handler struct {
+	AccessLog *AccessLog
+}
+
+func (h *Handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := req.URL.Query()
+	channelID := params.Get("channel")
+	if channelID == "" {
+		http.Error(resp, "the channel must be specified", http.StatusBadRequest)
+		return
+	}
+	q := &Query{
+		Chaincode:  params.Get("chaincode"),
+		Collection: params.Get("collection"),
+		Key:        params.Get("key"),
+	}
+	if since := params.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			http.Error(resp, fmt.Sprintf("invalid since: %s", err), http.StatusBadRequest)
+			return
+		}
+		q.Since = t
+	}
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			http.Error(resp, fmt.Sprintf("invalid limit: %s", limit), http.StatusBadRequest)
+			return
+		}
+		q.Limit = n
+	}
+	if err := q.validate(); err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.AccessLog.Query(channelID, q)
+	switch {
+	case err == ErrNotEnabled:
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []*Entry{}
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(entries); err != nil {
+		logger.Errorf("Failed to encode the private data access log: %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataaccesslog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	l := newTestAccessLog(t, &Config{Enabled: true})
+	now := time.Unix(1000, 0).UTC()
+	require.NoError(t, l.Record("ch1",
+		&Entry{Time: now, Chaincode: "cc1", Collection: "coll1", Key: "key1", MSPID: "Org1MSP"},
+		&Entry{Time: now.Add(time.Hour), Chaincode: "cc1", Collection: "coll1", Key: "key2", MSPID: "Org2MSP"},
+	))
+	handler := &Handler{AccessLog: l}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, target, nil))
+		return resp
+	}
+
+	resp := get("/pvtdata/accesslog?channel=ch1&chaincode=cc1&collection=coll1&key=key1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	var entries []*Entry
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	require.Equal(t, []*Entry{{Time: now, Channel: "ch1", Chaincode: "cc1", Collection: "coll1", Key: "key1", MSPID: "Org1MSP"}}, entries)
+
+	resp = get("/pvtdata/accesslog?channel=ch1&since=1970-01-01T01:00:00Z")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	require.Equal(t, "key2", entries[0].Key)
+
+	resp = get("/pvtdata/accesslog?channel=ch1&limit=1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+
+	resp = get("/pvtdata/accesslog?channel=ch2")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "[]\n", resp.Body.String())
+
+	for target, expectedErr := range map[string]string{
+		"/pvtdata/accesslog":                              "the channel must be specified\n",
+		"/pvtdata/accesslog?channel=ch1&since=yesterday":  "invalid since: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"\n",
+		"/pvtdata/accesslog?channel=ch1&limit=-1":         "invalid limit: -1\n",
+		"/pvtdata/accesslog?channel=ch1&collection=coll1": "the chaincode must be specified to query the accesses to a collection\n",
+	} {
+		resp = get(target)
+		require.Equal(t, http.StatusBadRequest, resp.Code, target)
+		require.Equal(t, expectedErr, resp.Body.String(), target)
+	}
+
+	handler.AccessLog = nil
+	resp = get("/pvtdata/accesslog?channel=ch1")
+	require.Equal(t, http.StatusNotFound, resp.Code)
+	require.Equal(t, "the private data access log is not enabled\n", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/pvtdata/accesslog", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
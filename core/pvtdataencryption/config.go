@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataencryption
+
+import (
+	"time"
+
+	viper "github.com/spf13/viper2015"
+)
+
+const (
+	reencryptionBatchSizeDefault       = 1000
+	reencryptionBatchesIntervalDefault = time.Second
+)
+
+// Config is the configuration of the encryption of the private data stored by the peer
+type Config struct {
+	// Enabled is a flag that indicates whether the private data is encrypted before being stored.
+	Enabled bool
+	// KeyID is the hex encoded subject key identifier (SKI) of the BCCSP key which encrypts
+	// the private data. The private data encrypted with another key, or stored in plaintext,
+	// is re-encrypted with this key in the background.
+	KeyID string
+	// ReencryptionBatchSize is the maximum number of values re-encrypted in a single batch.
+	ReencryptionBatchSize int
+	// ReencryptionBatchesInterval is the minimum duration between the re-encryption batches.
+	ReencryptionBatchesInterval time.Duration
+}
+
+// GlobalConfig obtains the configuration of the encryption of private data from viper
+func GlobalConfig() *Config {
+	c := &Config{
+		Enabled:                     viper.GetBool("ledger.pvtdataEncryption.enabled"),
+		KeyID:                       viper.GetString("ledger.pvtdataEncryption.keyID"),
+		ReencryptionBatchSize:       viper.GetInt("ledger.pvtdataEncryption.reencryptionBatchSize"),
+		ReencryptionBatchesInterval: viper.GetDuration("ledger.pvtdataEncryption.reencryptionBatchesInterval"),
+	}
+	if c.ReencryptionBatchSize <= 0 {
+		c.ReencryptionBatchSize = reencryptionBatchSizeDefault
+	}
+	if !viper.IsSet("ledger.pvtdataEncryption.reencryptionBatchesInterval") {
+		c.ReencryptionBatchesInterval = reencryptionBatchesIntervalDefault
+	}
+	return c
+}
@@ -0,0 +1,40 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataencryption
+
+import (
+	"testing"
+	"time"
+
+	viper "github.com/spf13/viper2015"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalConfig(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("ledger.pvtdataEncryption.enabled", true)
+	viper.Set("ledger.pvtdataEncryption.keyID", "0a0b")
+	viper.Set("ledger.pvtdataEncryption.reencryptionBatchSize", 10)
+	viper.Set("ledger.pvtdataEncryption.reencryptionBatchesInterval", "0s")
+
+	require.Equal(t, &Config{
+		Enabled:               true,
+		KeyID:                 "0a0b",
+		ReencryptionBatchSize: 10,
+	}, GlobalConfig())
+}
+
+func TestGlobalConfigDefaults(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	require.Equal(t, &Config{
+		ReencryptionBatchSize:       1000,
+		ReencryptionBatchesInterval: time.Second,
+	}, GlobalConfig())
+}
@@ -0,0 +1,250 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataencryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+)
+
+var logger = flogging.MustGetLogger("pvtdataencryption")
+
+const (
+	// encryptedValueMarker is the first byte of an encrypted value. A marshaled
+	// message never starts with this byte, which encodes the tag of field number 0,
+	// hence an encrypted value is told apart from a value stored in plaintext.
+	encryptedValueMarker = byte(0x07)
+	formatVersion        = byte(1)
+	dataKeySize          = 32
+)
+
+// Encryptor encrypts the private data with envelope encryption: the values are encrypted with AES-GCM
+// under a data key which is generated by each instance of the encryptor. The data key is encrypted
+// (wrapped) with the key of the BCCSP identified by the configuration, and stored in each encrypted
+// value along with the identifier of the wrapping key. Hence, a value can be decrypted as long as the
+// BCCSP holds the key that wrapped its data key, which allows the rotation of the wrapping key.
+//
+// The methods of a nil Encryptor store and return the values in plaintext.
+type Encryptor struct {
+	csp  bccsp.BCCSP
+	conf *Config
+	// keyID is the SKI of the current wrapping key
+	keyID []byte
+	// header precedes the values encrypted under the current data key. It holds the
+	// format version, the wrapping key ID and the wrapped data key, and is authenticated
+	// along with the encrypted value
+	header []byte
+	aead   cipher.AEAD
+
+	mutex sync.RWMutex
+	// aeads caches the unwrapped data keys by the header of the values they encrypt
+	aeads map[string]cipher.AEAD
+}
+
+// New returns an Encryptor which wraps its data key with the BCCSP key identified by the
+// configuration. It returns nil if the encryption of private data is not enabled.
+func New(csp bccsp.BCCSP, conf *Config) (*Encryptor, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+	if conf.KeyID == "" {
+		return nil, errors.New("the key ID of the encryption of private data must be specified")
+	}
+	keyID, err := hex.DecodeString(conf.KeyID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid key ID of the encryption of private data [%s]", conf.KeyID)
+	}
+	key, err := getWrappingKey(csp, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, errors.Wrap(err, "failed to generate the data key of the encryption of private data")
+	}
+	wrappedDataKey, err := csp.Encrypt(key, dataKey, &bccsp.AESCBCPKCS7ModeOpts{})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to wrap the data key with key [%s]", conf.KeyID)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	header := encodeHeader(keyID, wrappedDataKey)
+
+	logger.Infof("Private data is encrypted with key [%s]", conf.KeyID)
+	return &Encryptor{
+		csp:    csp,
+		conf:   conf,
+		keyID:  keyID,
+		header: header,
+		aead:   aead,
+		aeads:  map[string]cipher.AEAD{string(header): aead},
+	}, nil
+}
+
+// Encrypt encrypts the value with the current data key
+func (e *Encryptor) Encrypt(value []byte) ([]byte, error) {
+	if e == nil {
+		return value, nil
+	}
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate the nonce of the encryption of private data")
+	}
+	encrypted := make([]byte, 0, len(e.header)+len(nonce)+len(value)+e.aead.Overhead())
+	encrypted = append(encrypted, e.header...)
+	encrypted = append(encrypted, nonce...)
+	return e.aead.Seal(encrypted, nonce, value, e.header), nil
+}
+
+// Decrypt returns the plaintext of the value. A value stored in plaintext is returned as is.
+func (e *Encryptor) Decrypt(value []byte) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if e == nil {
+		return nil, errors.New("the private data is encrypted but the encryption of private data is not enabled")
+	}
+	header, _, _, err := decodeHeader(value)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := e.aeadFor(header)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := value[len(header):]
+	if len(encrypted) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted private data: missing nonce")
+	}
+	nonce := encrypted[:aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, encrypted[aead.NonceSize():], header)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt private data")
+	}
+	return plaintext, nil
+}
+
+// NeedsReencryption returns true if the value is stored in plaintext,
+// or encrypted under a key other than the current wrapping key
+func (e *Encryptor) NeedsReencryption(value []byte) bool {
+	if e == nil {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	_, keyID, _, err := decodeHeader(value)
+	return err != nil || !bytes.Equal(keyID, e.keyID)
+}
+
+// IsEncrypted returns true if the value was encrypted by an Encryptor
+func IsEncrypted(value []byte) bool {
+	return len(value) > 0 && value[0] == encryptedValueMarker
+}
+
+// aeadFor returns the cipher of the data key wrapped in the header,
+// which is unwrapped with the BCCSP if it is not cached
+func (e *Encryptor) aeadFor(header []byte) (cipher.AEAD, error) {
+	e.mutex.RLock()
+	aead, ok := e.aeads[string(header)]
+	e.mutex.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	_, keyID, wrappedDataKey, err := decodeHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	key, err := getWrappingKey(e.csp, keyID)
+	if err != nil {
+		return nil, err
+	}
+	// the wrapped data key is copied as the BCCSP may decrypt in place
+	dataKey, err := e.csp.Decrypt(key, append([]byte(nil), wrappedDataKey...), &bccsp.AESCBCPKCS7ModeOpts{})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to unwrap the data key with key [%x]", keyID)
+	}
+	if aead, err = newAEAD(dataKey); err != nil {
+		return nil, err
+	}
+
+	e.mutex.Lock()
+	e.aeads[string(header)] = aead
+	e.mutex.Unlock()
+	return aead, nil
+}
+
+func getWrappingKey(csp bccsp.BCCSP, keyID []byte) (bccsp.Key, error) {
+	key, err := csp.GetKey(keyID)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get key [%x] of the encryption of private data", keyID)
+	}
+	if !key.Symmetric() || !key.Private() {
+		return nil, errors.Errorf("key [%x] of the encryption of private data is not a symmetric key", keyID)
+	}
+	return key, nil
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid data key of the encryption of private data")
+	}
+	return cipher.NewGCM(block)
+}
+
+// encodeHeader encodes the header of an encrypted value as
+// <marker><version><len(keyID)><keyID><len(wrappedDataKey)><wrappedDataKey>
+func encodeHeader(keyID, wrappedDataKey []byte) []byte {
+	header := []byte{encryptedValueMarker, formatVersion}
+	header = append(header, proto.EncodeVarint(uint64(len(keyID)))...)
+	header = append(header, keyID...)
+	header = append(header, proto.EncodeVarint(uint64(len(wrappedDataKey)))...)
+	return append(header, wrappedDataKey...)
+}
+
+// decodeHeader returns the header of the encrypted value, along with the wrapping key ID and the wrapped data key
+func decodeHeader(value []byte) ([]byte, []byte, []byte, error) {
+	if len(value) < 2 || value[0] != encryptedValueMarker {
+		return nil, nil, nil, errors.New("invalid encrypted private data: missing header")
+	}
+	if value[1] != formatVersion {
+		return nil, nil, nil, errors.Errorf("unsupported format version [%d] of encrypted private data", value[1])
+	}
+	keyID, rest, err := decodeLengthPrefixed(value[2:])
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "invalid encrypted private data: invalid key ID")
+	}
+	wrappedDataKey, rest, err := decodeLengthPrefixed(rest)
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "invalid encrypted private data: invalid data key")
+	}
+	headerLen := len(value) - len(rest)
+	return value[:headerLen], keyID, wrappedDataKey, nil
+}
+
+func decodeLengthPrefixed(b []byte) ([]byte, []byte, error) {
+	l, n := proto.DecodeVarint(b)
+	if n == 0 || uint64(len(b)-n) < l {
+		return nil, nil, errors.New("unexpected end of data")
+	}
+	return b[n : n+int(l)], b[n+int(l):], nil
+}
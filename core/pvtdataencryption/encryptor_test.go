@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataencryption
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCSP(t *testing.T) bccsp.BCCSP {
+	csp, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewInMemoryKeyStore())
+	require.NoError(t, err)
+	return csp
+}
+
+func newTestKey(t *testing.T, csp bccsp.BCCSP) string {
+	key, err := csp.KeyGen(&bccsp.AES256KeyGenOpts{})
+	require.NoError(t, err)
+	return hex.EncodeToString(key.SKI())
+}
+
+func newTestEncryptor(t *testing.T, csp bccsp.BCCSP, keyID string) *Encryptor {
+	e, err := New(csp, &Config{Enabled: true, KeyID: keyID, ReencryptionBatchSize: 2})
+	require.NoError(t, err)
+	return e
+}
+
+func TestNew(t *testing.T) {
+	csp := newTestCSP(t)
+
+	e, err := New(csp, &Config{KeyID: newTestKey(t, csp)})
+	require.NoError(t, err)
+	require.Nil(t, e)
+
+	_, err = New(csp, &Config{Enabled: true})
+	require.EqualError(t, err, "the key ID of the encryption of private data must be specified")
+
+	_, err = New(csp, &Config{Enabled: true, KeyID: "xyz"})
+	require.EqualError(t, err, "invalid key ID of the encryption of private data [xyz]: encoding/hex: invalid byte: U+0078 'x'")
+
+	_, err = New(csp, &Config{Enabled: true, KeyID: "0102"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get key [0102] of the encryption of private data")
+
+	ecdsaKey, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	_, err = New(csp, &Config{Enabled: true, KeyID: hex.EncodeToString(ecdsaKey.SKI())})
+	require.EqualError(t, err, "key ["+hex.EncodeToString(ecdsaKey.SKI())+"] of the encryption of private data is not a symmetric key")
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	csp := newTestCSP(t)
+	e := newTestEncryptor(t, csp, newTestKey(t, csp))
+	plaintext := []byte("private value")
+
+	encrypted, err := e.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(encrypted))
+	require.False(t, bytes.Contains(encrypted, plaintext))
+	require.False(t, e.NeedsReencryption(encrypted))
+	decrypted, err := e.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	encryptedAgain, err := e.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, encrypted, encryptedAgain)
+
+	empty, err := e.Encrypt(nil)
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(empty))
+	decrypted, err = e.Decrypt(empty)
+	require.NoError(t, err)
+	require.Empty(t, decrypted)
+
+	// a value stored in plaintext is returned as is
+	require.False(t, IsEncrypted(plaintext))
+	require.True(t, e.NeedsReencryption(plaintext))
+	decrypted, err = e.Decrypt(plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = e.Decrypt(tampered)
+	require.EqualError(t, err, "failed to decrypt private data: cipher: message authentication failed")
+
+	_, err = e.Decrypt(encrypted[:len(e.header)+1])
+	require.EqualError(t, err, "invalid encrypted private data: missing nonce")
+
+	_, err = e.Decrypt([]byte{encryptedValueMarker, 2})
+	require.EqualError(t, err, "unsupported format version [2] of encrypted private data")
+
+	_, err = e.Decrypt([]byte{encryptedValueMarker, formatVersion, 10, 1})
+	require.EqualError(t, err, "invalid encrypted private data: invalid key ID: unexpected end of data")
+}
+
+func TestNilEncryptor(t *testing.T) {
+	var e *Encryptor
+	plaintext := []byte("private value")
+
+	value, err := e.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, value)
+	value, err = e.Decrypt(plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, value)
+	require.False(t, e.NeedsReencryption(plaintext))
+
+	csp := newTestCSP(t)
+	encrypted, err := newTestEncryptor(t, csp, newTestKey(t, csp)).Encrypt(plaintext)
+	require.NoError(t, err)
+	_, err = e.Decrypt(encrypted)
+	require.EqualError(t, err, "the private data is encrypted but the encryption of private data is not enabled")
+}
+
+func TestKeyRotation(t *testing.T) {
+	csp := newTestCSP(t)
+	keyID1, keyID2 := newTestKey(t, csp), newTestKey(t, csp)
+	plaintext := []byte("private value")
+
+	encrypted, err := newTestEncryptor(t, csp, keyID1).Encrypt(plaintext)
+	require.NoError(t, err)
+
+	// an encryptor with the same key generates its own data key,
+	// the values encrypted by a previous instance are not re-encrypted
+	e := newTestEncryptor(t, csp, keyID1)
+	require.False(t, e.NeedsReencryption(encrypted))
+	decrypted, err := e.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	e = newTestEncryptor(t, csp, keyID2)
+	require.True(t, e.NeedsReencryption(encrypted))
+	decrypted, err = e.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// the previous key is required to decrypt the values which are not re-encrypted yet
+	otherCSP := newTestCSP(t)
+	e = newTestEncryptor(t, otherCSP, newTestKey(t, otherCSP))
+	_, err = e.Decrypt(encrypted)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get key ["+keyID1+"] of the encryption of private data")
+}
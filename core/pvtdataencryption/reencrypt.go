@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataencryption
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+)
+
+// Reencrypt re-encrypts, with the current key, the values of the range [startKey, endKey) of the db
+// which are stored in plaintext or encrypted under another key. The values are re-encrypted in batches,
+// each of which is processed while holding the lock that guards the modifications of the values, so
+// that a value removed or modified concurrently is not overwritten. Reencrypt returns the number of
+// re-encrypted values.
+func (e *Encryptor) Reencrypt(db *leveldbhelper.DBHandle, startKey, endKey []byte, lock sync.Locker) (int, error) {
+	if e == nil {
+		return 0, nil
+	}
+	total := 0
+	for {
+		lock.Lock()
+		reencrypted, nextKey, err := e.reencryptBatch(db, startKey, endKey)
+		lock.Unlock()
+		total += reencrypted
+		if err != nil || nextKey == nil {
+			return total, err
+		}
+		logger.Debugf("Re-encrypted [%d] values so far, going to sleep for %s between batches", total, e.conf.ReencryptionBatchesInterval)
+		time.Sleep(e.conf.ReencryptionBatchesInterval)
+		startKey = nextKey
+	}
+}
+
+// reencryptBatch re-encrypts a batch of values starting from startKey. It returns
+// the key from which the next batch starts, which is nil if the range is exhausted
+func (e *Encryptor) reencryptBatch(db *leveldbhelper.DBHandle, startKey, endKey []byte) (int, []byte, error) {
+	itr, err := db.GetIterator(startKey, endKey)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer itr.Release()
+
+	batch := db.NewUpdateBatch()
+	reencrypted := 0
+	var nextKey []byte
+	for itr.Next() {
+		key, value := itr.Key(), itr.Value()
+		if !e.NeedsReencryption(value) {
+			continue
+		}
+		plaintext, err := e.Decrypt(value)
+		if err != nil {
+			return 0, nil, err
+		}
+		encrypted, err := e.Encrypt(plaintext)
+		if err != nil {
+			return 0, nil, err
+		}
+		batch.Put(append([]byte(nil), key...), encrypted)
+		reencrypted++
+		if reencrypted >= e.conf.ReencryptionBatchSize {
+			// the smallest key greater than the current key
+			nextKey = append(append([]byte(nil), key...), 0)
+			break
+		}
+	}
+	if err := itr.Error(); err != nil {
+		return 0, nil, err
+	}
+	if err := db.WriteBatch(batch, true); err != nil {
+		return 0, nil, err
+	}
+	return reencrypted, nextKey, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdataencryption
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencrypt(t *testing.T) {
+	path, err := ioutil.TempDir("", "pvtdataencryption")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	dbProvider, err := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: path})
+	require.NoError(t, err)
+	defer dbProvider.Close()
+	db := dbProvider.GetDBHandle("ledger1")
+
+	csp := newTestCSP(t)
+	keyID1, keyID2 := newTestKey(t, csp), newTestKey(t, csp)
+	e1 := newTestEncryptor(t, csp, keyID1)
+	encrypted, err := e1.Encrypt([]byte("value3"))
+	require.NoError(t, err)
+
+	batch := db.NewUpdateBatch()
+	batch.Put([]byte("a1"), []byte("value1"))
+	batch.Put([]byte("a2"), []byte("value2"))
+	batch.Put([]byte("a3"), encrypted)
+	batch.Put([]byte("a5"), []byte("value5"))
+	batch.Put([]byte("b1"), []byte("out of range"))
+	require.NoError(t, db.WriteBatch(batch, true))
+
+	e2 := newTestEncryptor(t, csp, keyID2)
+	reencrypted, err := e2.Reencrypt(db, []byte("a"), []byte("b"), &sync.Mutex{})
+	require.NoError(t, err)
+	require.Equal(t, 4, reencrypted)
+
+	for key, expectedValue := range map[string]string{"a1": "value1", "a2": "value2", "a3": "value3", "a5": "value5"} {
+		value, err := db.Get([]byte(key))
+		require.NoError(t, err)
+		require.False(t, e2.NeedsReencryption(value), key)
+		value, err = e2.Decrypt(value)
+		require.NoError(t, err)
+		require.Equal(t, expectedValue, string(value))
+	}
+	value, err := db.Get([]byte("b1"))
+	require.NoError(t, err)
+	require.Equal(t, "out of range", string(value))
+
+	reencrypted, err = e2.Reencrypt(db, []byte("a"), []byte("b"), &sync.Mutex{})
+	require.NoError(t, err)
+	require.Zero(t, reencrypted)
+
+	var nilEncryptor *Encryptor
+	reencrypted, err = nilEncryptor.Reencrypt(db, []byte("a"), []byte("c"), &sync.Mutex{})
+	require.NoError(t, err)
+	require.Zero(t, reencrypted)
+}
@@ -8,6 +8,7 @@ package transientstore
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
@@ -16,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
@@ -63,34 +65,43 @@ type EndorserPvtSimulationResults struct {
 // interface.
 type storeProvider struct {
 	dbProvider *leveldbhelper.Provider
+	encryptor  *pvtdataencryption.Encryptor
 }
 
 // store holds an instance of a levelDB.
 type Store struct {
-	db       *leveldbhelper.DBHandle
-	ledgerID string
+	db        *leveldbhelper.DBHandle
+	ledgerID  string
+	encryptor *pvtdataencryption.Encryptor
+	// purgeLock guards the modifications of the stored private write sets
+	// against their re-encryption in the background
+	purgeLock sync.Mutex
 }
 
 // RwsetScanner helps iterating over results
 type RwsetScanner struct {
-	txid   string
-	dbItr  iterator.Iterator
-	filter ledger.PvtNsCollFilter
+	txid      string
+	dbItr     iterator.Iterator
+	filter    ledger.PvtNsCollFilter
+	encryptor *pvtdataencryption.Encryptor
 }
 
-// NewStoreProvider instantiates TransientStoreProvider
-func NewStoreProvider(path string) (StoreProvider, error) {
+// NewStoreProvider instantiates TransientStoreProvider. The private write sets
+// are encrypted with the given encryptor, or stored in plaintext if it is nil.
+func NewStoreProvider(path string, encryptor *pvtdataencryption.Encryptor) (StoreProvider, error) {
 	dbProvider, err := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: path})
 	if err != nil {
 		return nil, err
 	}
-	return &storeProvider{dbProvider: dbProvider}, nil
+	return &storeProvider{dbProvider: dbProvider, encryptor: encryptor}, nil
 }
 
 // OpenStore returns a handle to a ledgerId in Store
 func (provider *storeProvider) OpenStore(ledgerID string) (*Store, error) {
 	dbHandle := provider.dbProvider.GetDBHandle(ledgerID)
-	return &Store{db: dbHandle, ledgerID: ledgerID}, nil
+	s := &Store{db: dbHandle, ledgerID: ledgerID, encryptor: provider.encryptor}
+	s.launchReencryption()
+	return s, nil
 }
 
 // Close closes the TransientStoreProvider
@@ -122,7 +133,10 @@ func (s *Store) Persist(txid string, blockHeight uint64,
 	// retrieving, a nil byte is prepended to the new proto, i.e., privateSimulationResultsWithConfigBytes,
 	// as a marshaled message can never start with a nil byte. In v1.3, we can avoid prepending the
 	// nil byte.
-	value := append([]byte{nilByte}, privateSimulationResultsWithConfigBytes...)
+	value, err := s.encryptor.Encrypt(append([]byte{nilByte}, privateSimulationResultsWithConfigBytes...))
+	if err != nil {
+		return err
+	}
 	dbBatch.Put(compositeKeyPvtRWSet, value)
 
 	// Create two index: (i) by txid, and (ii) by height
@@ -165,7 +179,7 @@ func (s *Store) GetTxPvtRWSetByTxid(txid string, filter ledger.PvtNsCollFilter)
 	if err != nil {
 		return nil, err
 	}
-	return &RwsetScanner{txid, iter, filter}, nil
+	return &RwsetScanner{txid, iter, filter, s.encryptor}, nil
 }
 
 // PurgeByTxids removes private write sets of a given set of transactions from the
@@ -175,6 +189,9 @@ func (s *Store) PurgeByTxids(txids []string) error {
 
 	logger.Debug("Purging private data from transient store for committed txids")
 
+	s.purgeLock.Lock()
+	defer s.purgeLock.Unlock()
+
 	dbBatch := s.db.NewUpdateBatch()
 
 	for _, txid := range txids {
@@ -227,6 +244,9 @@ func (s *Store) PurgeBelowHeight(maxBlockNumToRetain uint64) error {
 
 	logger.Debugf("Purging orphaned private data from transient store received prior to block [%d]", maxBlockNumToRetain)
 
+	s.purgeLock.Lock()
+	defer s.purgeLock.Unlock()
+
 	// Do a range query with 0 as startKey and maxBlockNumToRetain-1 as endKey
 	startKey := createPurgeIndexByHeightRangeStartKey(0)
 	endKey := createPurgeIndexByHeightRangeEndKey(maxBlockNumToRetain - 1)
@@ -289,6 +309,68 @@ func (s *Store) GetMinTransientBlkHt() (uint64, error) {
 	return 0, ErrStoreEmpty
 }
 
+// PurgePvtdataKeys removes the given purged private data keys from the private write sets
+// which were persisted prior to the commit of the purge, i.e., at a block height not greater
+// than the number of the block containing the purging transaction. This removes the values
+// held for transactions that were endorsed but never committed. Note that a transaction endorsed
+// before the purge which writes a purged key cannot use its private data from the transient store.
+func (s *Store) PurgePvtdataKeys(purges []*ledger.PvtdataPurge) error {
+	if len(purges) == 0 {
+		return nil
+	}
+
+	logger.Debugf("Purging [%d] private data keys from transient store", len(purges))
+
+	s.purgeLock.Lock()
+	defer s.purgeLock.Unlock()
+
+	iter, err := s.db.GetIterator([]byte{prwsetPrefix}, []byte{prwsetPrefix + 1})
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+
+	dbBatch := s.db.NewUpdateBatch()
+	for iter.Next() {
+		_, blockHeight, err := splitCompositeKeyOfPvtRWSet(iter.Key())
+		if err != nil {
+			return err
+		}
+		value, err := s.encryptor.Decrypt(iter.Value())
+		if err != nil {
+			return err
+		}
+		value, modified, err := removePurgedKeys(value, blockHeight, purges)
+		if err != nil {
+			return err
+		}
+		if !modified {
+			continue
+		}
+		if value, err = s.encryptor.Encrypt(value); err != nil {
+			return err
+		}
+		dbBatch.Put(append([]byte(nil), iter.Key()...), value)
+	}
+	return s.db.WriteBatch(dbBatch, true)
+}
+
+// launchReencryption re-encrypts, with the current key of the encryptor, the private
+// write sets stored in plaintext or encrypted with a previous key
+func (s *Store) launchReencryption() {
+	if s.encryptor == nil {
+		return
+	}
+	go func() {
+		reencrypted, err := s.encryptor.Reencrypt(s.db, []byte{prwsetPrefix}, []byte{prwsetPrefix + 1}, &s.purgeLock)
+		if err != nil {
+			logger.Errorf("Failed to re-encrypt private data in transient store: %s", err)
+			return
+		}
+		logger.Infof("Re-encrypted [%d] private write sets in transient store of channel [%s]", reencrypted, s.ledgerID)
+	}()
+}
+
 func (s *Store) Shutdown() {
 	// do nothing because shared db is used
 }
@@ -300,11 +382,14 @@ func (scanner *RwsetScanner) Next() (*EndorserPvtSimulationResults, error) {
 		return nil, nil
 	}
 	dbKey := scanner.dbItr.Key()
-	dbVal := scanner.dbItr.Value()
 	_, blockHeight, err := splitCompositeKeyOfPvtRWSet(dbKey)
 	if err != nil {
 		return nil, err
 	}
+	dbVal, err := scanner.encryptor.Decrypt(scanner.dbItr.Value())
+	if err != nil {
+		return nil, err
+	}
 
 	txPvtRWSet := &rwset.TxPvtReadWriteSet{}
 	txPvtRWSetWithConfig := &transientstore.TxPvtReadWriteSetWithConfigInfo{}
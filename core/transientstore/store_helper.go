@@ -10,10 +10,14 @@ import (
 	"bytes"
 	"errors"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-protos-go/transientstore"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/core/ledger"
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util"
 )
 
 var (
@@ -235,3 +239,110 @@ func trimPvtCollectionConfigs(configs map[string]*peer.CollectionConfigPackage,
 	}
 	return result, nil
 }
+
+// removePurgedKeys removes the purged keys from the private write set stored in the given value
+// (in either the new or the old proto format) if the private write set was persisted at a block
+// height not greater than the block number of the purge. It returns the modified value, if any.
+func removePurgedKeys(value []byte, blockHeight uint64, purges []*ledger.PvtdataPurge) ([]byte, bool, error) {
+	type nsColl struct {
+		ns, coll string
+	}
+	purgedKeys := make(map[nsColl][][]byte)
+	for _, purge := range purges {
+		if blockHeight <= purge.BlockNum {
+			k := nsColl{purge.Namespace, purge.Collection}
+			purgedKeys[k] = append(purgedKeys[k], purge.KeyHash)
+		}
+	}
+	if len(purgedKeys) == 0 {
+		return nil, false, nil
+	}
+
+	newProto := len(value) > 0 && value[0] == nilByte
+	txPvtRWSetWithConfig := &transientstore.TxPvtReadWriteSetWithConfigInfo{}
+	txPvtRWSet := &rwset.TxPvtReadWriteSet{}
+	if newProto {
+		if err := proto.Unmarshal(value[1:], txPvtRWSetWithConfig); err != nil {
+			return nil, false, err
+		}
+		txPvtRWSet = txPvtRWSetWithConfig.PvtRwset
+	} else if err := proto.Unmarshal(value, txPvtRWSet); err != nil {
+		return nil, false, err
+	}
+	if txPvtRWSet == nil {
+		return nil, false, nil
+	}
+
+	modified := false
+	for _, ns := range txPvtRWSet.NsPvtRwset {
+		for _, coll := range ns.CollectionPvtRwset {
+			keyHashes, ok := purgedKeys[nsColl{ns.Namespace, coll.CollectionName}]
+			if !ok {
+				continue
+			}
+			collModified, err := removeKeysFromCollPvtRwSet(coll, keyHashes)
+			if err != nil {
+				return nil, false, err
+			}
+			modified = modified || collModified
+		}
+	}
+	if !modified {
+		return nil, false, nil
+	}
+
+	if !newProto {
+		value, err := proto.Marshal(txPvtRWSet)
+		return value, true, err
+	}
+	valueBytes, err := proto.Marshal(txPvtRWSetWithConfig)
+	if err != nil {
+		return nil, false, err
+	}
+	return append([]byte{nilByte}, valueBytes...), true, nil
+}
+
+func removeKeysFromCollPvtRwSet(coll *rwset.CollectionPvtReadWriteSet, keyHashes [][]byte) (bool, error) {
+	isPurged := func(key string) bool {
+		keyHash := ledgerutil.ComputeStringHash(key)
+		for _, h := range keyHashes {
+			if bytes.Equal(h, keyHash) {
+				return true
+			}
+		}
+		return false
+	}
+
+	kvRWSet := &kvrwset.KVRWSet{}
+	if err := proto.Unmarshal(coll.Rwset, kvRWSet); err != nil {
+		return false, err
+	}
+	modified := false
+	var writes []*kvrwset.KVWrite
+	for _, w := range kvRWSet.Writes {
+		if isPurged(w.Key) {
+			modified = true
+			continue
+		}
+		writes = append(writes, w)
+	}
+	var metadataWrites []*kvrwset.KVMetadataWrite
+	for _, w := range kvRWSet.MetadataWrites {
+		if isPurged(w.Key) {
+			modified = true
+			continue
+		}
+		metadataWrites = append(metadataWrites, w)
+	}
+	if !modified {
+		return false, nil
+	}
+	kvRWSet.Writes = writes
+	kvRWSet.MetadataWrites = metadataWrites
+	rwsetBytes, err := proto.Marshal(kvRWSet)
+	if err != nil {
+		return false, err
+	}
+	coll.Rwset = rwsetBytes
+	return true, nil
+}
@@ -7,21 +7,27 @@ SPDX-License-Identifier: Apache-2.0
 package transientstore
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-protos-go/transientstore"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/common/policydsl"
 	commonutil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,7 +60,7 @@ func (env *testEnv) initTestEnv(t *testing.T) {
 		os.RemoveAll(tempdir)
 	}
 
-	env.storeProvider, err = NewStoreProvider(tempdir)
+	env.storeProvider, err = NewStoreProvider(tempdir, nil)
 	if err != nil {
 		t.Fatalf("Failed to open test store: %s", err)
 	}
@@ -498,6 +504,131 @@ func TestTransientStorePurgeBelowHeight(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestTransientStorePurgePvtdataKeys(t *testing.T) {
+	env.initTestEnv(t)
+	defer env.cleanup()
+	testStore := env.store
+
+	samplePvtRWSetWithKeys := func(keys ...string) *rwset.TxPvtReadWriteSet {
+		kvRWSet := &kvrwset.KVRWSet{}
+		for _, key := range keys {
+			kvRWSet.Writes = append(kvRWSet.Writes, &kvrwset.KVWrite{Key: key, Value: []byte("value-" + key)})
+		}
+		kvRWSetBytes, err := proto.Marshal(kvRWSet)
+		require.NoError(t, err)
+		return &rwset.TxPvtReadWriteSet{
+			DataModel: rwset.TxReadWriteSet_KV,
+			NsPvtRwset: []*rwset.NsPvtReadWriteSet{{
+				Namespace:          "ns-1",
+				CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{{CollectionName: "coll-1", Rwset: kvRWSetBytes}},
+			}},
+		}
+	}
+	retrievePvtRWSet := func(txid string) *rwset.TxPvtReadWriteSet {
+		itr, err := testStore.GetTxPvtRWSetByTxid(txid, nil)
+		require.NoError(t, err)
+		defer itr.Close()
+		res, err := itr.Next()
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		return res.PvtSimulationResultsWithConfig.PvtRwset
+	}
+
+	// entries persisted prior to the purge, in both the new and the old proto, and after the purge
+	require.NoError(t, testStore.Persist("txid-1", 2,
+		&transientstore.TxPvtReadWriteSetWithConfigInfo{PvtRwset: samplePvtRWSetWithKeys("key-1", "key-2")}))
+	require.NoError(t, testStore.persistOldProto("txid-2", 3, samplePvtRWSetWithKeys("key-1")))
+	require.NoError(t, testStore.Persist("txid-3", 4,
+		&transientstore.TxPvtReadWriteSetWithConfigInfo{PvtRwset: samplePvtRWSetWithKeys("key-1", "key-2")}))
+
+	require.NoError(t, testStore.PurgePvtdataKeys([]*ledger.PvtdataPurge{
+		{Namespace: "ns-1", Collection: "coll-1", KeyHash: util.ComputeStringHash("key-1"), BlockNum: 3, TxNum: 0},
+	}))
+
+	require.True(t, proto.Equal(samplePvtRWSetWithKeys("key-2"), retrievePvtRWSet("txid-1")))
+	require.True(t, proto.Equal(samplePvtRWSetWithKeys(), retrievePvtRWSet("txid-2")))
+	require.True(t, proto.Equal(samplePvtRWSetWithKeys("key-1", "key-2"), retrievePvtRWSet("txid-3")))
+}
+
+func TestTransientStoreEncryption(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "ts")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	csp, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewInMemoryKeyStore())
+	require.NoError(t, err)
+	key, err := csp.KeyGen(&bccsp.AES256KeyGenOpts{})
+	require.NoError(t, err)
+	encryptor, err := pvtdataencryption.New(csp, &pvtdataencryption.Config{
+		Enabled:               true,
+		KeyID:                 hex.EncodeToString(key.SKI()),
+		ReencryptionBatchSize: 1,
+	})
+	require.NoError(t, err)
+
+	retrievePvtRWSet := func(s *Store, txid string) *rwset.TxPvtReadWriteSet {
+		itr, err := s.GetTxPvtRWSetByTxid(txid, nil)
+		require.NoError(t, err)
+		defer itr.Close()
+		res, err := itr.Next()
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		return res.PvtSimulationResultsWithConfig.PvtRwset
+	}
+
+	// private write sets persisted in plaintext, in both the new and the old proto
+	storeProvider, err := NewStoreProvider(tempdir, nil)
+	require.NoError(t, err)
+	testStore, err := storeProvider.OpenStore("TestStore")
+	require.NoError(t, err)
+	require.NoError(t, testStore.Persist("txid-1", 1, samplePvtDataWithConfigInfo(t)))
+	require.NoError(t, testStore.persistOldProto("txid-2", 1, samplePvtData(t)))
+	storeProvider.Close()
+
+	// are re-encrypted once the encryption is enabled
+	storeProvider, err = NewStoreProvider(tempdir, encryptor)
+	require.NoError(t, err)
+	defer storeProvider.Close()
+	testStore, err = storeProvider.OpenStore("TestStore")
+	require.NoError(t, err)
+	require.NoError(t, testStore.Persist("txid-3", 2, samplePvtDataWithConfigInfo(t)))
+	require.Eventually(t, func() bool {
+		itr, err := testStore.db.GetIterator([]byte{prwsetPrefix}, []byte{prwsetPrefix + 1})
+		require.NoError(t, err)
+		defer itr.Release()
+		for itr.Next() {
+			if !pvtdataencryption.IsEncrypted(itr.Value()) || encryptor.NeedsReencryption(itr.Value()) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.True(t, proto.Equal(samplePvtData(t), retrievePvtRWSet(testStore, "txid-1")))
+	require.True(t, proto.Equal(samplePvtData(t), retrievePvtRWSet(testStore, "txid-2")))
+	require.True(t, proto.Equal(samplePvtData(t), retrievePvtRWSet(testStore, "txid-3")))
+
+	// the purged keys are removed from the encrypted private write sets
+	purgedRWSet := &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: "key-1", Value: []byte("value-1")}}}
+	purgedRWSetBytes, err := proto.Marshal(purgedRWSet)
+	require.NoError(t, err)
+	require.NoError(t, testStore.Persist("txid-4", 2, &transientstore.TxPvtReadWriteSetWithConfigInfo{
+		PvtRwset: &rwset.TxPvtReadWriteSet{
+			DataModel: rwset.TxReadWriteSet_KV,
+			NsPvtRwset: []*rwset.NsPvtReadWriteSet{{
+				Namespace:          "ns-3",
+				CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{{CollectionName: "coll-1", Rwset: purgedRWSetBytes}},
+			}},
+		},
+	}))
+	require.NoError(t, testStore.PurgePvtdataKeys([]*ledger.PvtdataPurge{
+		{Namespace: "ns-3", Collection: "coll-1", KeyHash: util.ComputeStringHash("key-1"), BlockNum: 2, TxNum: 0},
+	}))
+	kvRWSet := &kvrwset.KVRWSet{}
+	require.NoError(t, proto.Unmarshal(retrievePvtRWSet(testStore, "txid-4").NsPvtRwset[0].CollectionPvtRwset[0].Rwset, kvRWSet))
+	require.Empty(t, kvRWSet.Writes)
+}
+
 func TestTransientStoreRetrievalWithFilter(t *testing.T) {
 	env.initTestEnv(t)
 	defer env.cleanup()
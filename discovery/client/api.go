@@ -51,6 +51,12 @@ type ChannelResponse interface {
 	// The given InvocationChain specifies the chaincode calls (along with collections)
 	// that the client passed during the construction of the request
 	Endorsers(invocationChain InvocationChain, f Filter) (Endorsers, error)
+
+	// PvtPeers returns the response for a private data peers query for the given
+	// collection of the given chaincode, or error if something went wrong.
+	// The method returns the peers eligible for the private data of the collection,
+	// along with their ledger height and the private data of the collection they miss.
+	PvtPeers(chaincode, collection string) (PvtPeers, error)
 }
 
 // LocalResponse aggregates responses for a channel-less scope
@@ -63,6 +69,39 @@ type LocalResponse interface {
 // for satisfying some chaincode's endorsement policy
 type Endorsers []*Peer
 
+// PvtPeer is a peer eligible for the private data of a collection
+type PvtPeer struct {
+	*Peer
+	LedgerHeight uint64
+	// MissingPvtData indicates whether the peer published that
+	// private data of the collection is missing on it
+	MissingPvtData bool
+	// OldestMissingBlock is the lowest block number of the
+	// private data of the collection missing on the peer
+	OldestMissingBlock uint64
+}
+
+// HoldsPvtDataUpTo returns whether the peer holds all the private data
+// of the collection committed in the blocks below the given height
+func (p *PvtPeer) HoldsPvtDataUpTo(height uint64) bool {
+	return p.LedgerHeight >= height && (!p.MissingPvtData || p.OldestMissingBlock >= height)
+}
+
+// PvtPeers defines a set of peers eligible for the private data of a collection
+type PvtPeers []*PvtPeer
+
+// HoldingPvtDataUpTo returns the peers which hold all the private
+// data of the collection committed in the blocks below the given height
+func (pp PvtPeers) HoldingPvtDataUpTo(height uint64) PvtPeers {
+	var res PvtPeers
+	for _, p := range pp {
+		if p.HoldsPvtDataUpTo(height) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
 // Peer aggregates identity, membership and channel-scoped information
 // of a certain peer.
 type Peer struct {
@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric-protos-go/discovery"
 	"github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric/discovery/protoext"
+	privdatacommon "github.com/hyperledger/fabric/gossip/privdata/common"
 	gprotoext "github.com/hyperledger/fabric/gossip/protoext"
 	"github.com/pkg/errors"
 )
@@ -135,6 +136,30 @@ func (req *Request) AddPeersQuery(invocationChain ...*discovery.ChaincodeCall) *
 	return req
 }
 
+// AddPvtPeersQuery adds to the request a query for the peers eligible for the private data
+// of each of the given collections of the chaincode. The peers are queried from the peer
+// membership of the channel filtered by the collections, and the private data they miss is
+// derived from the chaincode metadata of the StateInfo messages they publish.
+func (req *Request) AddPvtPeersQuery(chaincode string, collections ...string) (*Request, error) {
+	if chaincode == "" {
+		return nil, errors.New("chaincode name cannot be empty")
+	}
+	if len(collections) == 0 {
+		return nil, errors.New("at least one collection must be specified")
+	}
+	for _, collection := range collections {
+		if collection == "" {
+			return nil, errors.New("collection name cannot be empty")
+		}
+		req.AddPeersQuery(pvtPeersInvocationChain(chaincode, collection)...)
+	}
+	return req, nil
+}
+
+func pvtPeersInvocationChain(chaincode, collection string) InvocationChain {
+	return InvocationChain{{Name: chaincode, CollectionNames: []string{collection}}}
+}
+
 func channnelAndInvocationChain(ch string, ic InvocationChain) string {
 	return fmt.Sprintf("%s %s", ch, ic.String())
 }
@@ -244,6 +269,42 @@ func (cr *channelResponse) Peers(invocationChain ...*discovery.ChaincodeCall) ([
 	return parsePeers(protoext.PeerMembershipQueryType, cr.response, cr.channel, invocationChain...)
 }
 
+func (cr *channelResponse) PvtPeers(chaincode, collection string) (PvtPeers, error) {
+	peers, err := cr.Peers(pvtPeersInvocationChain(chaincode, collection)...)
+	if err != nil {
+		return nil, err
+	}
+	var pvtPeers PvtPeers
+	for _, p := range peers {
+		pvtPeer, err := pvtPeerOf(p, chaincode, collection)
+		if err != nil {
+			return nil, err
+		}
+		pvtPeers = append(pvtPeers, pvtPeer)
+	}
+	return pvtPeers, nil
+}
+
+func pvtPeerOf(p *Peer, chaincode, collection string) (*PvtPeer, error) {
+	pvtPeer := &PvtPeer{Peer: p}
+	if p.StateInfoMessage == nil || p.StateInfoMessage.GetStateInfo().GetProperties() == nil {
+		return pvtPeer, nil
+	}
+	properties := p.StateInfoMessage.GetStateInfo().GetProperties()
+	pvtPeer.LedgerHeight = properties.LedgerHeight
+	for _, cc := range properties.Chaincodes {
+		if cc.GetName() != chaincode {
+			continue
+		}
+		md, err := privdatacommon.ChaincodeMetadataOf(cc)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed parsing stateInfo message")
+		}
+		pvtPeer.OldestMissingBlock, pvtPeer.MissingPvtData = md.MissingPvtData[collection]
+	}
+	return pvtPeer, nil
+}
+
 func (cr *channelResponse) Endorsers(invocationChain InvocationChain, f Filter) (Endorsers, error) {
 	// If we have a key that has no chaincode field,
 	// it means it's an error returned from the service
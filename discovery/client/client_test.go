@@ -499,6 +499,48 @@ func TestClient(t *testing.T) {
 		assert.Len(t, peers, 6)
 	})
 
+	t.Run("Private data peers query", func(t *testing.T) {
+		cc2WithMissingPvtData := &gossip.Chaincode{
+			Name:     "mycc2",
+			Version:  "1.0",
+			Metadata: []byte(`{"missing_pvtdata":{"col":7}}`),
+		}
+		var channelPeers gdisc.Members
+		for i := 0; i < 8; i++ {
+			stateInfo := stateInfoMessageWithHeight(10, cc, cc2)
+			if i%2 == 1 {
+				stateInfo = stateInfoMessageWithHeight(10, cc, cc2WithMissingPvtData)
+			}
+			channelPeers = append(channelPeers, newPeer(i, stateInfo, propertiesWithChaincodes).NetworkMember)
+		}
+		sup.On("PeersOfChannel").Return(channelPeers).Once()
+		req, err := NewRequest().OfChannel("mychannel").AddPvtPeersQuery("mycc2", "col")
+		assert.NoError(t, err)
+		r, err = cl.Send(ctx, req, authInfo)
+		assert.NoError(t, err)
+		mychannel := r.ForChannel("mychannel")
+		peers, err := mychannel.PvtPeers("mycc2", "col")
+		assert.NoError(t, err)
+		// We should see all peers that aren't in ORG A since it's not part of the collection
+		assert.Len(t, peers, 6)
+		var missing int
+		for _, p := range peers {
+			assert.NotEqual(t, "A", p.MSPID)
+			assert.Equal(t, uint64(10), p.LedgerHeight)
+			if p.MissingPvtData {
+				missing++
+				assert.Equal(t, uint64(7), p.OldestMissingBlock)
+			}
+		}
+		assert.Equal(t, 3, missing)
+		assert.Len(t, peers.HoldingPvtDataUpTo(7), 6)
+		assert.Len(t, peers.HoldingPvtDataUpTo(8), 3)
+		assert.Empty(t, peers.HoldingPvtDataUpTo(11))
+
+		_, err = mychannel.PvtPeers("mycc2", "col2")
+		assert.Equal(t, ErrNotFound, err)
+	})
+
 	t.Run("Endorser query with PrioritiesByHeight selector", func(t *testing.T) {
 		sup.On("PeersOfChannel").Return(channelPeersWithDifferentLedgerHeights).Twice()
 		req = NewRequest()
@@ -712,6 +754,48 @@ func TestAddEndorsersQueryInvalidInput(t *testing.T) {
 	assert.Contains(t, err.Error(), "chaincode name should not be empty")
 }
 
+func TestAddPvtPeersQueryInvalidInput(t *testing.T) {
+	_, err := NewRequest().AddPvtPeersQuery("", "col")
+	assert.EqualError(t, err, "chaincode name cannot be empty")
+
+	_, err = NewRequest().AddPvtPeersQuery("mycc")
+	assert.EqualError(t, err, "at least one collection must be specified")
+
+	_, err = NewRequest().AddPvtPeersQuery("mycc", "col", "")
+	assert.EqualError(t, err, "collection name cannot be empty")
+}
+
+func TestPvtPeerOf(t *testing.T) {
+	stateInfo := func(metadata []byte) *protoext.SignedGossipMessage {
+		sMsg, err := protoext.EnvelopeToGossipMessage(stateInfoMessageWithHeight(5, &gossip.Chaincode{Name: "mycc", Metadata: metadata}))
+		assert.NoError(t, err)
+		return sMsg
+	}
+
+	p, err := pvtPeerOf(&Peer{StateInfoMessage: stateInfo(nil)}, "mycc", "col")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), p.LedgerHeight)
+	assert.False(t, p.MissingPvtData)
+
+	p, err = pvtPeerOf(&Peer{StateInfoMessage: stateInfo([]byte(`{"missing_pvtdata":{"col":3}}`))}, "mycc", "col")
+	assert.NoError(t, err)
+	assert.True(t, p.MissingPvtData)
+	assert.Equal(t, uint64(3), p.OldestMissingBlock)
+	assert.False(t, p.HoldsPvtDataUpTo(4))
+	assert.True(t, p.HoldsPvtDataUpTo(3))
+
+	p, err = pvtPeerOf(&Peer{StateInfoMessage: stateInfo([]byte(`{"missing_pvtdata":{"col":3}}`))}, "mycc", "col2")
+	assert.NoError(t, err)
+	assert.False(t, p.MissingPvtData)
+
+	_, err = pvtPeerOf(&Peer{StateInfoMessage: stateInfo([]byte("{"))}, "mycc", "col")
+	assert.EqualError(t, err, "failed parsing stateInfo message: failed unmarshaling metadata of chaincode mycc: unexpected end of JSON input")
+
+	p, err = pvtPeerOf(&Peer{}, "mycc", "col")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), p.LedgerHeight)
+}
+
 func TestValidateAliveMessage(t *testing.T) {
 	am := aliveMessage(1)
 	msg, _ := protoext.EnvelopeToGossipMessage(am)
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"sync"
+	"time"
+)
+
+// healthSmoothingFactor is the weight of the most recent outcome in the
+// exponentially weighted moving averages of the latency and the error rate
+const healthSmoothingFactor = 0.3
+
+// HealthTracker tracks the round-trip latency and the recent error rate
+// of the requests, such as endorsements, that a client sends to peers.
+// The peers are identified by their endpoint. It is safe for concurrent use.
+type HealthTracker struct {
+	lock  sync.RWMutex
+	peers map[string]*peerHealth
+}
+
+type peerHealth struct {
+	// latency is the moving average of the latency of the successful requests
+	latency time.Duration
+	// errorRate is the moving average of the outcomes of the requests,
+	// each outcome being 1 for a failure and 0 for a success
+	errorRate float64
+}
+
+// NewHealthTracker creates a new HealthTracker
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		peers: make(map[string]*peerHealth),
+	}
+}
+
+// RecordSuccess records that a request to the peer at the given
+// endpoint succeeded with the given round-trip latency
+func (ht *HealthTracker) RecordSuccess(endpoint string, latency time.Duration) {
+	ht.lock.Lock()
+	defer ht.lock.Unlock()
+	ph, exists := ht.peers[endpoint]
+	if !exists {
+		ht.peers[endpoint] = &peerHealth{latency: latency}
+		return
+	}
+	if ph.latency == 0 {
+		ph.latency = latency
+	} else {
+		ph.latency = time.Duration(healthSmoothingFactor*float64(latency) + (1-healthSmoothingFactor)*float64(ph.latency))
+	}
+	ph.errorRate = (1 - healthSmoothingFactor) * ph.errorRate
+}
+
+// RecordFailure records that a request to the peer at the given endpoint failed
+func (ht *HealthTracker) RecordFailure(endpoint string) {
+	ht.lock.Lock()
+	defer ht.lock.Unlock()
+	ph, exists := ht.peers[endpoint]
+	if !exists {
+		ht.peers[endpoint] = &peerHealth{errorRate: 1}
+		return
+	}
+	ph.errorRate = healthSmoothingFactor + (1-healthSmoothingFactor)*ph.errorRate
+}
+
+// Latency returns the average round-trip latency of the recent requests to the peer
+// at the given endpoint, and false if no request to the peer has succeeded yet
+func (ht *HealthTracker) Latency(endpoint string) (time.Duration, bool) {
+	ht.lock.RLock()
+	defer ht.lock.RUnlock()
+	ph, exists := ht.peers[endpoint]
+	if !exists || ph.latency == 0 {
+		return 0, false
+	}
+	return ph.latency, true
+}
+
+// ErrorRate returns the rate, between 0 and 1, of the recent requests to the peer at
+// the given endpoint that failed. It is 0 if no request was sent to the peer yet.
+func (ht *HealthTracker) ErrorRate(endpoint string) float64 {
+	ht.lock.RLock()
+	defer ht.lock.RUnlock()
+	if ph, exists := ht.peers[endpoint]; exists {
+		return ph.errorRate
+	}
+	return 0
+}
@@ -77,6 +77,124 @@ func (*byHeight) Compare(left Peer, right Peer) Priority {
 	return 0
 }
 
+// Scorer scores peers for their selection. The scores range from 0 to 1,
+// and a peer with a higher score is more likely to be selected
+type Scorer interface {
+	// Score returns the score of the given peer
+	Score(Peer) float64
+}
+
+// ScorerFunc is a function that implements Scorer
+type ScorerFunc func(Peer) float64
+
+// Score returns the score of the given peer
+func (sf ScorerFunc) Score(p Peer) float64 {
+	return sf(p)
+}
+
+// WeightedScorer is a Scorer whose scores are weighted
+// when combined with the scores of other Scorers
+type WeightedScorer struct {
+	Scorer
+	Weight float64
+}
+
+// latencyScoreReference is the latency of the peers
+// whose latency is unknown when scoring them by latency
+const latencyScoreReference = 100 * time.Millisecond
+
+// ScoreByLatency returns a Scorer that favors the peers with the lowest round-trip latency
+// recorded by the given HealthTracker. The peers whose latency is unknown are scored as if
+// their latency was 100ms, so that they are preferred over slow peers.
+func ScoreByLatency(ht *HealthTracker) Scorer {
+	return ScorerFunc(func(p Peer) float64 {
+		latency, known := ht.Latency(endpointOf(p))
+		if !known {
+			latency = latencyScoreReference
+		}
+		return float64(latencyScoreReference) / float64(latencyScoreReference+latency)
+	})
+}
+
+// ScoreByErrorRate returns a Scorer that favors the peers with
+// the lowest recent error rate recorded by the given HealthTracker
+func ScoreByErrorRate(ht *HealthTracker) Scorer {
+	return ScorerFunc(func(p Peer) float64 {
+		return 1 - ht.ErrorRate(endpointOf(p))
+	})
+}
+
+// ScoreByOrgPreference returns a Scorer that favors the peers of the given
+// organizations, by descending order of preference, over the peers of
+// the organizations that are not given
+func ScoreByOrgPreference(mspIDs ...string) Scorer {
+	scores := make(map[string]float64, len(mspIDs))
+	for i := len(mspIDs) - 1; i >= 0; i-- {
+		scores[mspIDs[i]] = float64(len(mspIDs)-i) / float64(len(mspIDs))
+	}
+	return ScorerFunc(func(p Peer) float64 {
+		return scores[p.MSPID]
+	})
+}
+
+type byScore []WeightedScorer
+
+func (scorers byScore) score(p Peer) float64 {
+	var score float64
+	for _, scorer := range scorers {
+		score += scorer.Weight * scorer.Score(p)
+	}
+	return score
+}
+
+func (scorers byScore) Compare(left Peer, right Peer) Priority {
+	leftScore, rightScore := scorers.score(left), scorers.score(right)
+	if leftScore > rightScore {
+		return 1
+	}
+	if rightScore > leftScore {
+		return -1
+	}
+	return 0
+}
+
+// PrioritiesByScore returns a PrioritySelector that selects
+// peers by descending sum of their weighted scores
+func PrioritiesByScore(scorers ...WeightedScorer) PrioritySelector {
+	return byScore(scorers)
+}
+
+// PrioritiesByLatency returns a PrioritySelector that selects peers by ascending
+// round-trip latency, as recorded by the given HealthTracker
+func PrioritiesByLatency(ht *HealthTracker) PrioritySelector {
+	return PrioritiesByScore(WeightedScorer{Scorer: ScoreByLatency(ht), Weight: 1})
+}
+
+// PrioritiesByErrorRate returns a PrioritySelector that selects peers by ascending
+// recent error rate, as recorded by the given HealthTracker
+func PrioritiesByErrorRate(ht *HealthTracker) PrioritySelector {
+	return PrioritiesByScore(WeightedScorer{Scorer: ScoreByErrorRate(ht), Weight: 1})
+}
+
+// PrioritiesByOrgPreference returns a PrioritySelector that selects the peers of the
+// given organizations, by descending order of preference, before the other peers
+func PrioritiesByOrgPreference(mspIDs ...string) PrioritySelector {
+	return PrioritiesByScore(WeightedScorer{Scorer: ScoreByOrgPreference(mspIDs...), Weight: 1})
+}
+
+// PrioritiesByLocalOrg returns a PrioritySelector that selects
+// the peers of the given organization before the other peers
+func PrioritiesByLocalOrg(mspID string) PrioritySelector {
+	return PrioritiesByOrgPreference(mspID)
+}
+
+func endpointOf(p Peer) string {
+	if p.AliveMessage == nil {
+		return ""
+	}
+	return p.AliveMessage.GetAliveMsg().GetMembership().GetEndpoint()
+}
+
 func noExclusion(_ Peer) bool {
 	return false
 }
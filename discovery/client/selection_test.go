@@ -8,6 +8,7 @@ package discovery
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/gossip"
@@ -150,6 +151,95 @@ func TestPrioritiesByHeight(t *testing.T) {
 
 }
 
+func TestPrioritiesByScore(t *testing.T) {
+	newPeer := func(i int, mspID string) *Peer {
+		am, _ := protoext.EnvelopeToGossipMessage(aliveMessage(i))
+		return &Peer{
+			MSPID:        mspID,
+			AliveMessage: am,
+		}
+	}
+	endpoints := func(endorsers Endorsers) []string {
+		var res []string
+		for _, e := range endorsers {
+			res = append(res, endpointOf(*e))
+		}
+		return res
+	}
+	p0, p1, p2, p3 := newPeer(0, "Org1MSP"), newPeer(1, "Org2MSP"), newPeer(2, "Org2MSP"), newPeer(3, "Org3MSP")
+
+	ht := NewHealthTracker()
+	ht.RecordSuccess("p0", 300*time.Millisecond)
+	ht.RecordSuccess("p1", 20*time.Millisecond)
+	ht.RecordSuccess("p3", 50*time.Millisecond)
+	ht.RecordFailure("p3")
+
+	t.Run("By latency", func(t *testing.T) {
+		// the latency of p2 is unknown, hence it is preferred over the slow peer p0
+		ordered := Endorsers{p0, p1, p2, p3}.Sort(PrioritiesByLatency(ht))
+		assert.Equal(t, []string{"p1", "p3", "p2", "p0"}, endpoints(ordered))
+	})
+
+	t.Run("By error rate", func(t *testing.T) {
+		ordered := Endorsers{p3, p0}.Sort(PrioritiesByErrorRate(ht))
+		assert.Equal(t, []string{"p0", "p3"}, endpoints(ordered))
+		assert.Equal(t, Priority(0), PrioritiesByErrorRate(ht).Compare(*p0, *p2))
+	})
+
+	t.Run("By org preference", func(t *testing.T) {
+		ordered := Endorsers{p0, p3, p1}.Sort(PrioritiesByOrgPreference("Org3MSP", "Org2MSP"))
+		assert.Equal(t, []string{"p3", "p1", "p0"}, endpoints(ordered))
+
+		ordered = Endorsers{p1, p3, p0}.Sort(PrioritiesByLocalOrg("Org1MSP"))
+		assert.Equal(t, "p0", endpointOf(*ordered[0]))
+	})
+
+	t.Run("Weighted", func(t *testing.T) {
+		// the error rate of p3 outweighs its latency
+		ps := PrioritiesByScore(
+			WeightedScorer{Scorer: ScoreByLatency(ht), Weight: 1},
+			WeightedScorer{Scorer: ScoreByErrorRate(ht), Weight: 2},
+		)
+		ordered := Endorsers{p3, p0, p1}.Sort(ps)
+		assert.Equal(t, []string{"p1", "p0", "p3"}, endpoints(ordered))
+
+		// the local org outweighs the latency and the error rate
+		ps = PrioritiesByScore(
+			WeightedScorer{Scorer: ScoreByLatency(ht), Weight: 1},
+			WeightedScorer{Scorer: ScoreByErrorRate(ht), Weight: 2},
+			WeightedScorer{Scorer: ScoreByOrgPreference("Org3MSP"), Weight: 5},
+		)
+		ordered = Endorsers{p0, p1, p3}.Sort(ps)
+		assert.Equal(t, []string{"p3", "p1", "p0"}, endpoints(ordered))
+	})
+}
+
+func TestHealthTracker(t *testing.T) {
+	ht := NewHealthTracker()
+	_, known := ht.Latency("p0")
+	assert.False(t, known)
+	assert.Equal(t, 0.0, ht.ErrorRate("p0"))
+
+	ht.RecordFailure("p0")
+	_, known = ht.Latency("p0")
+	assert.False(t, known)
+	assert.Equal(t, 1.0, ht.ErrorRate("p0"))
+
+	ht.RecordSuccess("p0", 100*time.Millisecond)
+	latency, known := ht.Latency("p0")
+	assert.True(t, known)
+	assert.Equal(t, 100*time.Millisecond, latency)
+	assert.InDelta(t, 0.7, ht.ErrorRate("p0"), 0.0001)
+
+	ht.RecordSuccess("p0", 200*time.Millisecond)
+	latency, _ = ht.Latency("p0")
+	assert.Equal(t, 130*time.Millisecond, latency)
+	assert.InDelta(t, 0.49, ht.ErrorRate("p0"), 0.0001)
+
+	ht.RecordFailure("p0")
+	assert.InDelta(t, 0.643, ht.ErrorRate("p0"), 0.0001)
+}
+
 func stateInfoWithHeight(h uint64) *protoext.SignedGossipMessage {
 	g := &gossip.GossipMessage{
 		Content: &gossip.GossipMessage_StateInfo{
@@ -19,6 +19,7 @@ const (
 	PeersCommand     = "peers"
 	ConfigCommand    = "config"
 	EndorsersCommand = "endorsers"
+	PvtPeersCommand  = "pvtpeers"
 )
 
 var (
@@ -75,6 +76,10 @@ func AddCommands(cli CommandRegistrar) {
 	chaincodes := endorsers.Flag("chaincode", "Specifies the chaincode name(s)").Strings()
 	collections := endorsers.Flag("collection", "Specifies the collection name(s) as a mapping from chaincode to a comma separated list of collections").PlaceHolder("CC:C1,C2").StringMap()
 	noPrivReads := endorsers.Flag("noPrivateReads", "Specifies chaincodes that are not expected to be have private data read").PlaceHolder("CHAINCODE").Strings()
+	preferredOrgs := endorsers.Flag("preferOrg", "Lists the endorsers of the given organization(s) first, by descending order of preference").PlaceHolder("MSPID").Strings()
+	preferLocalOrg := endorsers.Flag("preferLocalOrg", "Lists the endorsers of the organization of the user first").Bool()
+	measureLatency := endorsers.Flag("measureLatency", "Measures the round-trip latency to the endorsers and lists them by ascending latency and error rate").Bool()
+	weights := endorsers.Flag("weight", "Specifies the weight of a criterion the endorsers are listed by: orgs, latency or errors. Defaults to 1").PlaceHolder("CRITERION:WEIGHT").StringMap()
 
 	server = endorsers.Flag("server", "Sets the endpoint of the server to connect").String()
 	channel = endorsers.Flag("channel", "Sets the channel the query is intended to").String()
@@ -83,4 +88,22 @@ func AddCommands(cli CommandRegistrar) {
 	endorserCmd.SetChaincodes(chaincodes)
 	endorserCmd.SetCollections(collections)
 	endorserCmd.SetNoPrivateReads(noPrivReads)
+	endorserCmd.SetPreferredOrgs(preferredOrgs)
+	endorserCmd.SetPreferLocalOrg(preferLocalOrg)
+	endorserCmd.SetMeasureLatency(measureLatency)
+	endorserCmd.SetWeights(weights)
+
+	pvtPeersParser := &PvtPeersResponseParser{Writer: responseParserWriter}
+	pvtPeersCmd := NewPvtPeersCmd(&ClientStub{}, pvtPeersParser)
+	pvtPeers := cli.Command(PvtPeersCommand, "Discover peers holding private data of collections", pvtPeersCmd.Execute)
+	chaincode := pvtPeers.Flag("chaincode", "Specifies the chaincode name").String()
+	pvtCollections := pvtPeers.Flag("collection", "Specifies the collection name(s)").Strings()
+	height := pvtPeers.Flag("height", "Only lists the peers holding the private data of the blocks below the given height").Uint64()
+	server = pvtPeers.Flag("server", "Sets the endpoint of the server to connect").String()
+	channel = pvtPeers.Flag("channel", "Sets the channel the query is intended to").String()
+	pvtPeersCmd.SetServer(server)
+	pvtPeersCmd.SetChannel(channel)
+	pvtPeersCmd.SetChaincode(chaincode)
+	pvtPeersCmd.SetCollections(pvtCollections)
+	pvtPeersParser.SetQuery(chaincode, pvtCollections, height)
 }
@@ -23,13 +23,22 @@ func TestAddCommands(t *testing.T) {
 	cli.On("Command", discovery.PeersCommand, mock.Anything, configFunc).Return(app.Command(discovery.PeersCommand, ""))
 	cli.On("Command", discovery.ConfigCommand, mock.Anything, configFunc).Return(app.Command(discovery.ConfigCommand, ""))
 	cli.On("Command", discovery.EndorsersCommand, mock.Anything, configFunc).Return(app.Command(discovery.EndorsersCommand, ""))
+	cli.On("Command", discovery.PvtPeersCommand, mock.Anything, configFunc).Return(app.Command(discovery.PvtPeersCommand, ""))
 	discovery.AddCommands(cli)
 	// Ensure that serve and channel flags are were configured for the sub-commands
-	for _, cmd := range []string{discovery.PeersCommand, discovery.ConfigCommand, discovery.EndorsersCommand} {
+	for _, cmd := range []string{discovery.PeersCommand, discovery.ConfigCommand, discovery.EndorsersCommand, discovery.PvtPeersCommand} {
 		assert.NotNil(t, app.GetCommand(cmd).GetFlag("server"))
 		assert.NotNil(t, app.GetCommand(cmd).GetFlag("channel"))
 	}
 	// Ensure that chaincode and collection flags were called for the endorsers
 	assert.NotNil(t, app.GetCommand(discovery.EndorsersCommand).GetFlag("chaincode"))
 	assert.NotNil(t, app.GetCommand(discovery.EndorsersCommand).GetFlag("collection"))
+	// Ensure that the flags ordering the endorsers were called for the endorsers
+	for _, flag := range []string{"preferOrg", "preferLocalOrg", "measureLatency", "weight"} {
+		assert.NotNil(t, app.GetCommand(discovery.EndorsersCommand).GetFlag(flag))
+	}
+	// Ensure that chaincode, collection and height flags were called for the pvtpeers
+	assert.NotNil(t, app.GetCommand(discovery.PvtPeersCommand).GetFlag("chaincode"))
+	assert.NotNil(t, app.GetCommand(discovery.PvtPeersCommand).GetFlag("collection"))
+	assert.NotNil(t, app.GetCommand(discovery.PvtPeersCommand).GetFlag("height"))
 }
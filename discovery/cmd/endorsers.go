@@ -10,8 +10,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	. "github.com/hyperledger/fabric-protos-go/discovery"
@@ -33,13 +37,37 @@ func NewEndorsersCmd(stub Stub, parser ResponseParser) *EndorsersCmd {
 
 // EndorsersCmd executes a command that retrieves endorsers for a chaincode invocation chain
 type EndorsersCmd struct {
-	stub        Stub
-	server      *string
-	channel     *string
-	chaincodes  *[]string
-	collections *map[string]string
-	noPrivReads *[]string
-	parser      ResponseParser
+	stub           Stub
+	server         *string
+	channel        *string
+	chaincodes     *[]string
+	collections    *map[string]string
+	noPrivReads    *[]string
+	preferredOrgs  *[]string
+	preferLocalOrg *bool
+	measureLatency *bool
+	weights        *map[string]string
+	parser         ResponseParser
+}
+
+// SetPreferredOrgs sets the organizations whose peers are listed first, by descending order of preference
+func (pc *EndorsersCmd) SetPreferredOrgs(preferredOrgs *[]string) {
+	pc.preferredOrgs = preferredOrgs
+}
+
+// SetPreferLocalOrg sets whether the peers of the organization of the user are listed first
+func (pc *EndorsersCmd) SetPreferLocalOrg(preferLocalOrg *bool) {
+	pc.preferLocalOrg = preferLocalOrg
+}
+
+// SetMeasureLatency sets whether the endorsers are listed by ascending round-trip latency
+func (pc *EndorsersCmd) SetMeasureLatency(measureLatency *bool) {
+	pc.measureLatency = measureLatency
+}
+
+// SetWeights sets the weights of the criteria the endorsers are listed by
+func (pc *EndorsersCmd) SetWeights(weights *map[string]string) {
+	pc.weights = weights
 }
 
 // SetCollections sets the collections to be the given collections
@@ -100,6 +128,11 @@ func (pc *EndorsersCmd) Execute(conf common.Config) error {
 		})
 	}
 
+	weights, err := pc.parseWeights()
+	if err != nil {
+		return err
+	}
+
 	req, err := discovery.NewRequest().OfChannel(channel).AddEndorsersQuery(&ChaincodeInterest{Chaincodes: ccCalls})
 	if err != nil {
 		return errors.Wrap(err, "failed creating request")
@@ -110,9 +143,167 @@ func (pc *EndorsersCmd) Execute(conf common.Config) error {
 		return err
 	}
 
+	if ps := pc.prioritySelector(conf, weights, res); ps != nil {
+		sortEndorsers(res, ps)
+	}
+
 	return pc.parser.ParseResponse(channel, res)
 }
 
+const (
+	orgsCriterion    = "orgs"
+	latencyCriterion = "latency"
+	errorsCriterion  = "errors"
+
+	latencyProbeTimeout = 2 * time.Second
+)
+
+func (pc *EndorsersCmd) parseWeights() (map[string]float64, error) {
+	weights := map[string]float64{
+		orgsCriterion:    1,
+		latencyCriterion: 1,
+		errorsCriterion:  1,
+	}
+	if pc.weights == nil {
+		return weights, nil
+	}
+	for criterion, weight := range *pc.weights {
+		if _, exists := weights[criterion]; !exists {
+			return nil, errors.Errorf("unknown selection criterion %s, expected one of %s, %s, %s", criterion, orgsCriterion, latencyCriterion, errorsCriterion)
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil || w < 0 {
+			return nil, errors.Errorf("invalid weight %s of selection criterion %s", weight, criterion)
+		}
+		weights[criterion] = w
+	}
+	return weights, nil
+}
+
+// prioritySelector returns the PrioritySelector which orders the endorsers according to the
+// selection flags, or nil if no ordering is requested. When the latency is to be measured,
+// each endorser of the response is probed and the outcome is fed to a health tracker.
+func (pc *EndorsersCmd) prioritySelector(conf common.Config, weights map[string]float64, res ServiceResponse) discovery.PrioritySelector {
+	var orgs []string
+	if pc.preferLocalOrg != nil && *pc.preferLocalOrg {
+		orgs = append(orgs, conf.SignerConfig.MSPID)
+	}
+	if pc.preferredOrgs != nil {
+		orgs = append(orgs, *pc.preferredOrgs...)
+	}
+	measureLatency := pc.measureLatency != nil && *pc.measureLatency
+
+	var scorers []discovery.WeightedScorer
+	if len(orgs) > 0 {
+		scorers = append(scorers, discovery.WeightedScorer{Scorer: discovery.ScoreByOrgPreference(orgs...), Weight: weights[orgsCriterion]})
+	}
+	if measureLatency {
+		ht := discovery.NewHealthTracker()
+		for endpoint := range endpointsOf(res) {
+			latency, err := probeLatency(endpoint)
+			if err != nil {
+				ht.RecordFailure(endpoint)
+				continue
+			}
+			ht.RecordSuccess(endpoint, latency)
+		}
+		scorers = append(scorers,
+			discovery.WeightedScorer{Scorer: discovery.ScoreByLatency(ht), Weight: weights[latencyCriterion]},
+			discovery.WeightedScorer{Scorer: discovery.ScoreByErrorRate(ht), Weight: weights[errorsCriterion]},
+		)
+	}
+	if len(scorers) == 0 {
+		return nil
+	}
+	return discovery.PrioritiesByScore(scorers...)
+}
+
+// probeLatency measures the round-trip latency to the given
+// endpoint as the time to establish a TCP connection to it
+func probeLatency(endpoint string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", endpoint, latencyProbeTimeout)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
+
+func endorsementDescriptorsOf(res ServiceResponse) []*EndorsementDescriptor {
+	if res == nil || res.Raw() == nil || len(res.Raw().Results) == 0 {
+		return nil
+	}
+	return res.Raw().Results[0].GetCcQueryRes().GetContent()
+}
+
+func endpointsOf(res ServiceResponse) map[string]struct{} {
+	endpoints := make(map[string]struct{})
+	for _, desc := range endorsementDescriptorsOf(res) {
+		for _, endorsers := range desc.EndorsersByGroups {
+			for _, p := range endorsers.Peers {
+				if endpoint := endpointFromEnvelope(p.MembershipInfo); endpoint != "" {
+					endpoints[endpoint] = struct{}{}
+				}
+			}
+		}
+	}
+	return endpoints
+}
+
+// sortEndorsers sorts the endorsers of each group of the response
+// according to the given PrioritySelector, in place
+func sortEndorsers(res ServiceResponse, ps discovery.PrioritySelector) {
+	for _, desc := range endorsementDescriptorsOf(res) {
+		for _, endorsers := range desc.EndorsersByGroups {
+			peers := make([]discovery.Peer, len(endorsers.Peers))
+			for i, p := range endorsers.Peers {
+				peers[i] = peerFromRaw(p)
+			}
+			sort.Stable(&rawEndorserSort{raw: endorsers.Peers, peers: peers, ps: ps})
+		}
+	}
+}
+
+type rawEndorserSort struct {
+	raw   []*Peer
+	peers []discovery.Peer
+	ps    discovery.PrioritySelector
+}
+
+func (s *rawEndorserSort) Len() int {
+	return len(s.raw)
+}
+
+func (s *rawEndorserSort) Less(i, j int) bool {
+	return s.ps.Compare(s.peers[i], s.peers[j]) > discovery.Priority(0)
+}
+
+func (s *rawEndorserSort) Swap(i, j int) {
+	s.raw[i], s.raw[j] = s.raw[j], s.raw[i]
+	s.peers[i], s.peers[j] = s.peers[j], s.peers[i]
+}
+
+func peerFromRaw(p *Peer) discovery.Peer {
+	sID := &msp.SerializedIdentity{}
+	proto.Unmarshal(p.Identity, sID)
+	var aliveMsg *protoext.SignedGossipMessage
+	if p.MembershipInfo != nil {
+		aliveMsg, _ = protoext.EnvelopeToGossipMessage(p.MembershipInfo)
+	}
+	var stateInfoMsg *protoext.SignedGossipMessage
+	if p.StateInfo != nil {
+		stateInfoMsg, _ = protoext.EnvelopeToGossipMessage(p.StateInfo)
+	}
+	return discovery.Peer{
+		MSPID:            sID.Mspid,
+		Identity:         p.Identity,
+		AliveMessage:     aliveMsg,
+		StateInfoMessage: stateInfoMsg,
+	}
+}
+
 // EndorserResponseParser parses endorsement responses from the peer
 type EndorserResponseParser struct {
 	io.Writer
@@ -8,14 +8,18 @@ package discovery_test
 
 import (
 	"bytes"
+	"net"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	discprotos "github.com/hyperledger/fabric-protos-go/discovery"
 	"github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric/cmd/common"
+	"github.com/hyperledger/fabric/cmd/common/signer"
 	. "github.com/hyperledger/fabric/discovery/client"
 	discovery "github.com/hyperledger/fabric/discovery/cmd"
 	"github.com/hyperledger/fabric/discovery/cmd/mocks"
+	"github.com/hyperledger/fabric/gossip/protoext"
 	"github.com/hyperledger/fabric/protoutil"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -160,6 +164,133 @@ func TestEndorserCmd(t *testing.T) {
 	})
 }
 
+func TestEndorserCmdOrdering(t *testing.T) {
+	server := "peer0"
+	channel := "mychannel"
+	chaincodes := []string{"mycc"}
+
+	reachable, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer reachable.Close()
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	unreachable.Close()
+
+	endorser := func(mspID, endpoint string) *discprotos.Peer {
+		aliveMsg := aliveMessage(0)
+		aliveMsg.GetAliveMsg().Membership.Endpoint = endpoint
+		sMsg, _ := protoext.NoopSign(aliveMsg.GossipMessage)
+		return &discprotos.Peer{
+			Identity:       protoutil.MarshalOrPanic(&msp.SerializedIdentity{Mspid: mspID}),
+			StateInfo:      stateInfoMessage(100).Envelope,
+			MembershipInfo: sMsg.Envelope,
+		}
+	}
+	// execute runs the command and returns the MSP IDs of the endorsers in the order they are parsed
+	execute := func(conf common.Config, configure func(cmd *discovery.EndorsersCmd)) ([]string, error) {
+		stub := &mocks.Stub{}
+		parser := &mocks.ResponseParser{}
+		res := &mocks.ServiceResponse{}
+		res.On("Raw").Return(&discprotos.Response{
+			Results: []*discprotos.QueryResult{
+				{
+					Result: &discprotos.QueryResult_CcQueryRes{
+						CcQueryRes: &discprotos.ChaincodeQueryResult{
+							Content: []*discprotos.EndorsementDescriptor{
+								{
+									Chaincode: "mycc",
+									EndorsersByGroups: map[string]*discprotos.Peers{
+										"G0": {
+											Peers: []*discprotos.Peer{
+												endorser("Org1MSP", unreachable.Addr().String()),
+												endorser("Org2MSP", reachable.Addr().String()),
+												endorser("Org3MSP", unreachable.Addr().String()),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		var mspIDs []string
+		parser.On("ParseResponse", channel, res).Return(nil).Run(func(arg mock.Arguments) {
+			res := arg.Get(1).(discovery.ServiceResponse)
+			for _, p := range res.Raw().Results[0].GetCcQueryRes().Content[0].EndorsersByGroups["G0"].Peers {
+				sID := &msp.SerializedIdentity{}
+				assert.NoError(t, proto.Unmarshal(p.Identity, sID))
+				mspIDs = append(mspIDs, sID.Mspid)
+			}
+		})
+		stub.On("Send", server, mock.Anything, mock.Anything).Return(res, nil)
+
+		cmd := discovery.NewEndorsersCmd(stub, parser)
+		configure(cmd)
+		cmd.SetChannel(&channel)
+		cmd.SetServer(&server)
+		cmd.SetChaincodes(&chaincodes)
+		err := cmd.Execute(conf)
+		return mspIDs, err
+	}
+
+	t.Run("No ordering", func(t *testing.T) {
+		mspIDs, err := execute(common.Config{}, func(*discovery.EndorsersCmd) {})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Org1MSP", "Org2MSP", "Org3MSP"}, mspIDs)
+	})
+
+	t.Run("Preferred organizations", func(t *testing.T) {
+		preferredOrgs := []string{"Org3MSP"}
+		preferLocalOrg := true
+		mspIDs, err := execute(common.Config{SignerConfig: signer.Config{MSPID: "Org2MSP"}}, func(cmd *discovery.EndorsersCmd) {
+			cmd.SetPreferredOrgs(&preferredOrgs)
+			cmd.SetPreferLocalOrg(&preferLocalOrg)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Org2MSP", "Org3MSP", "Org1MSP"}, mspIDs)
+	})
+
+	t.Run("Measured latency", func(t *testing.T) {
+		measureLatency := true
+		mspIDs, err := execute(common.Config{}, func(cmd *discovery.EndorsersCmd) {
+			cmd.SetMeasureLatency(&measureLatency)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Org2MSP", "Org1MSP", "Org3MSP"}, mspIDs)
+	})
+
+	t.Run("Weighted criteria", func(t *testing.T) {
+		preferredOrgs := []string{"Org3MSP", "Org1MSP"}
+		measureLatency := true
+		weights := map[string]string{"orgs": "0.1", "latency": "2"}
+		mspIDs, err := execute(common.Config{}, func(cmd *discovery.EndorsersCmd) {
+			cmd.SetPreferredOrgs(&preferredOrgs)
+			cmd.SetMeasureLatency(&measureLatency)
+			cmd.SetWeights(&weights)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Org2MSP", "Org3MSP", "Org1MSP"}, mspIDs)
+	})
+
+	t.Run("Unknown criterion", func(t *testing.T) {
+		weights := map[string]string{"load": "1"}
+		_, err := execute(common.Config{}, func(cmd *discovery.EndorsersCmd) {
+			cmd.SetWeights(&weights)
+		})
+		assert.EqualError(t, err, "unknown selection criterion load, expected one of orgs, latency, errors")
+	})
+
+	t.Run("Invalid weight", func(t *testing.T) {
+		weights := map[string]string{"latency": "-1"}
+		_, err := execute(common.Config{}, func(cmd *discovery.EndorsersCmd) {
+			cmd.SetWeights(&weights)
+		})
+		assert.EqualError(t, err, "invalid weight -1 of selection criterion latency")
+	})
+}
+
 func TestParseEndorsementResponse(t *testing.T) {
 	buff := &bytes.Buffer{}
 	parser := &discovery.EndorserResponseParser{Writer: buff}
@@ -86,3 +86,26 @@ func (_m *ChannelResponse) Peers(invocationChain ...*discovery.ChaincodeCall) ([
 
 	return r0, r1
 }
+
+// PvtPeers provides a mock function with given fields: chaincode, collection
+func (_m *ChannelResponse) PvtPeers(chaincode string, collection string) (client.PvtPeers, error) {
+	ret := _m.Called(chaincode, collection)
+
+	var r0 client.PvtPeers
+	if rf, ok := ret.Get(0).(func(string, string) client.PvtPeers); ok {
+		r0 = rf(chaincode, collection)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.PvtPeers)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chaincode, collection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
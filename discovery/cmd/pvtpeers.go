@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric/cmd/common"
+	discovery "github.com/hyperledger/fabric/discovery/client"
+	"github.com/pkg/errors"
+)
+
+// NewPvtPeersCmd creates a new PvtPeersCmd with the given Stub and ResponseParser
+func NewPvtPeersCmd(stub Stub, parser ResponseParser) *PvtPeersCmd {
+	return &PvtPeersCmd{
+		stub:   stub,
+		parser: parser,
+	}
+}
+
+// PvtPeersCmd executes a command that retrieves the peers eligible for the private data of collections
+type PvtPeersCmd struct {
+	stub        Stub
+	server      *string
+	channel     *string
+	chaincode   *string
+	collections *[]string
+	parser      ResponseParser
+}
+
+// SetServer sets the server
+func (pc *PvtPeersCmd) SetServer(server *string) {
+	pc.server = server
+}
+
+// SetChannel sets the channel
+func (pc *PvtPeersCmd) SetChannel(channel *string) {
+	pc.channel = channel
+}
+
+// SetChaincode sets the chaincode of the collections
+func (pc *PvtPeersCmd) SetChaincode(chaincode *string) {
+	pc.chaincode = chaincode
+}
+
+// SetCollections sets the collections to be the given collections
+func (pc *PvtPeersCmd) SetCollections(collections *[]string) {
+	pc.collections = collections
+}
+
+// Execute executes the command
+func (pc *PvtPeersCmd) Execute(conf common.Config) error {
+	if pc.channel == nil || *pc.channel == "" {
+		return errors.New("no channel specified")
+	}
+
+	if pc.server == nil || *pc.server == "" {
+		return errors.New("no server specified")
+	}
+
+	if pc.chaincode == nil || *pc.chaincode == "" {
+		return errors.New("no chaincode specified")
+	}
+
+	var collections []string
+	if pc.collections != nil {
+		collections = *pc.collections
+	}
+
+	server := *pc.server
+	channel := *pc.channel
+
+	req, err := discovery.NewRequest().OfChannel(channel).AddPvtPeersQuery(*pc.chaincode, collections...)
+	if err != nil {
+		return errors.Wrap(err, "failed creating request")
+	}
+
+	res, err := pc.stub.Send(server, conf, req)
+	if err != nil {
+		return err
+	}
+
+	return pc.parser.ParseResponse(channel, res)
+}
+
+// PvtPeersResponseParser parses a response to a query for the peers eligible
+// for the private data of the collections of a chaincode
+type PvtPeersResponseParser struct {
+	io.Writer
+	chaincode   *string
+	collections *[]string
+	height      *uint64
+}
+
+// SetQuery sets the chaincode and the collections the response is parsed for, and the height
+// up to which the peers are required to hold the private data of the collections
+func (parser *PvtPeersResponseParser) SetQuery(chaincode *string, collections *[]string, height *uint64) {
+	parser.chaincode = chaincode
+	parser.collections = collections
+	parser.height = height
+}
+
+// ParseResponse parses the given response about the given channel
+func (parser *PvtPeersResponseParser) ParseResponse(channel string, res ServiceResponse) error {
+	var height uint64
+	if parser.height != nil {
+		height = *parser.height
+	}
+
+	var collectionsPeers []collectionPeers
+	for _, collection := range *parser.collections {
+		peers, err := res.ForChannel(channel).PvtPeers(*parser.chaincode, collection)
+		if err != nil {
+			return errors.WithMessagef(err, "failed retrieving the peers of collection %s", collection)
+		}
+		if height > 0 {
+			peers = peers.HoldingPvtDataUpTo(height)
+		}
+
+		cp := collectionPeers{
+			Collection: collection,
+			Peers:      []pvtPeer{},
+		}
+		for _, p := range peers {
+			cp.Peers = append(cp.Peers, rawPeerToPvtPeer(p))
+		}
+		collectionsPeers = append(collectionsPeers, cp)
+	}
+
+	b, _ := json.MarshalIndent(collectionsPeers, "", "\t")
+	fmt.Fprintln(parser.Writer, string(b))
+	return nil
+}
+
+type collectionPeers struct {
+	Collection string
+	Peers      []pvtPeer
+}
+
+type pvtPeer struct {
+	MSPID              string
+	LedgerHeight       uint64
+	Endpoint           string
+	Identity           string
+	MissingPvtData     bool
+	OldestMissingBlock uint64 `json:",omitempty"`
+}
+
+func rawPeerToPvtPeer(p *discovery.PvtPeer) pvtPeer {
+	cp := rawPeerToChannelPeer(p.Peer)
+	return pvtPeer{
+		MSPID:              cp.MSPID,
+		LedgerHeight:       p.LedgerHeight,
+		Endpoint:           cp.Endpoint,
+		Identity:           cp.Identity,
+		MissingPvtData:     p.MissingPvtData,
+		OldestMissingBlock: p.OldestMissingBlock,
+	}
+}
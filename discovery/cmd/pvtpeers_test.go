@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/cmd/common"
+	. "github.com/hyperledger/fabric/discovery/client"
+	discovery "github.com/hyperledger/fabric/discovery/cmd"
+	"github.com/hyperledger/fabric/discovery/cmd/mocks"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPvtPeersCmd(t *testing.T) {
+	server := "peer0"
+	channel := "mychannel"
+	chaincode := "mycc"
+	collections := []string{"col1", "col2"}
+	stub := &mocks.Stub{}
+	parser := &mocks.ResponseParser{}
+	cmd := discovery.NewPvtPeersCmd(stub, parser)
+
+	t.Run("no channel supplied", func(t *testing.T) {
+		err := cmd.Execute(common.Config{})
+		assert.EqualError(t, err, "no channel specified")
+	})
+
+	t.Run("no server supplied", func(t *testing.T) {
+		cmd.SetChannel(&channel)
+		err := cmd.Execute(common.Config{})
+		assert.EqualError(t, err, "no server specified")
+	})
+
+	t.Run("no chaincode supplied", func(t *testing.T) {
+		cmd.SetServer(&server)
+		err := cmd.Execute(common.Config{})
+		assert.EqualError(t, err, "no chaincode specified")
+	})
+
+	t.Run("no collection supplied", func(t *testing.T) {
+		cmd.SetChaincode(&chaincode)
+		err := cmd.Execute(common.Config{})
+		assert.EqualError(t, err, "failed creating request: at least one collection must be specified")
+	})
+
+	t.Run("Server return error", func(t *testing.T) {
+		cmd.SetCollections(&collections)
+		stub.On("Send", server, mock.Anything, mock.Anything).Return(nil, errors.New("deadline exceeded")).Once()
+		err := cmd.Execute(common.Config{})
+		assert.Contains(t, err.Error(), "deadline exceeded")
+	})
+
+	t.Run("Private data peers query", func(t *testing.T) {
+		stub.On("Send", server, mock.Anything, mock.MatchedBy(func(req *Request) bool {
+			return len(req.Queries) == 2 && req.Queries[0].Channel == channel &&
+				req.Queries[1].GetPeerQuery().Filter.Chaincodes[0].CollectionNames[0] == "col2"
+		})).Return(nil, nil).Once()
+		parser.On("ParseResponse", channel, mock.Anything).Return(nil).Once()
+		err := cmd.Execute(common.Config{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestParsePvtPeers(t *testing.T) {
+	buff := &bytes.Buffer{}
+	chaincode := "mycc"
+	collections := []string{"col1", "col2"}
+	var height uint64
+	parser := &discovery.PvtPeersResponseParser{Writer: buff}
+	parser.SetQuery(&chaincode, &collections, &height)
+
+	idBytes := protoutil.MarshalOrPanic(&msp.SerializedIdentity{
+		Mspid:   "Org1MSP",
+		IdBytes: []byte("identity"),
+	})
+	peer := &Peer{
+		MSPID:            "Org1MSP",
+		Identity:         idBytes,
+		AliveMessage:     aliveMessage(0),
+		StateInfoMessage: stateInfoMessage(100),
+	}
+
+	chanRes := &mocks.ChannelResponse{}
+	chanRes.On("PvtPeers", chaincode, "col1").Return(PvtPeers{
+		{Peer: peer, LedgerHeight: 100},
+		{Peer: peer, LedgerHeight: 100, MissingPvtData: true, OldestMissingBlock: 42},
+	}, nil)
+	chanRes.On("PvtPeers", chaincode, "col2").Return(PvtPeers{}, nil)
+	res := &mocks.ServiceResponse{}
+	res.On("ForChannel", "mychannel").Return(chanRes)
+
+	err := parser.ParseResponse("mychannel", res)
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n\t{\n\t\t\"Collection\": \"col1\",\n\t\t\"Peers\": [\n\t\t\t{\n\t\t\t\t\"MSPID\": \"Org1MSP\",\n\t\t\t\t\"LedgerHeight\": 100,\n\t\t\t\t\"Endpoint\": \"p0\",\n\t\t\t\t\"Identity\": \"identity\",\n\t\t\t\t\"MissingPvtData\": false\n\t\t\t},\n\t\t\t{\n\t\t\t\t\"MSPID\": \"Org1MSP\",\n\t\t\t\t\"LedgerHeight\": 100,\n\t\t\t\t\"Endpoint\": \"p0\",\n\t\t\t\t\"Identity\": \"identity\",\n\t\t\t\t\"MissingPvtData\": true,\n\t\t\t\t\"OldestMissingBlock\": 42\n\t\t\t}\n\t\t]\n\t},\n\t{\n\t\t\"Collection\": \"col2\",\n\t\t\"Peers\": []\n\t}\n]\n", buff.String())
+
+	// only the peers which hold the private data of the blocks below the height are listed
+	buff.Reset()
+	height = 50
+	err = parser.ParseResponse("mychannel", res)
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n\t{\n\t\t\"Collection\": \"col1\",\n\t\t\"Peers\": [\n\t\t\t{\n\t\t\t\t\"MSPID\": \"Org1MSP\",\n\t\t\t\t\"LedgerHeight\": 100,\n\t\t\t\t\"Endpoint\": \"p0\",\n\t\t\t\t\"Identity\": \"identity\",\n\t\t\t\t\"MissingPvtData\": false\n\t\t\t}\n\t\t]\n\t},\n\t{\n\t\t\"Collection\": \"col2\",\n\t\t\"Peers\": []\n\t}\n]\n", buff.String())
+
+	chanRes = &mocks.ChannelResponse{}
+	chanRes.On("PvtPeers", chaincode, "col1").Return(nil, errors.New("not found"))
+	res = &mocks.ServiceResponse{}
+	res.On("ForChannel", "mychannel").Return(chanRes)
+	err = parser.ParseResponse("mychannel", res)
+	assert.EqualError(t, err, "failed retrieving the peers of collection col1: not found")
+}
@@ -7,27 +7,91 @@ SPDX-License-Identifier: Apache-2.0
 package chaincode
 
 import (
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/hyperledger/fabric/extensions/chaincode/api"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
 )
 
+var registry = newUCCRegistry()
+
+// uccRegistry holds the in-process user chaincodes by package ID
+type uccRegistry struct {
+	mutex      sync.RWMutex
+	chaincodes map[string]api.UserCC
+	pending    sync.WaitGroup
+}
+
+func newUCCRegistry() *uccRegistry {
+	return &uccRegistry{chaincodes: make(map[string]api.UserCC)}
+}
+
+// Register registers Go chaincodes compiled into the peer binary so that they
+// may be approved and committed with _lifecycle using the package ID
+// 'name:version', without being installed, and executed in-process. Register
+// must be called before the peer starts, typically from the init function of
+// a package imported by the peer's main package.
+func Register(ccs ...api.UserCC) error {
+	return registry.register(ccs...)
+}
+
+// MustRegister registers the given in-process user chaincodes and panics on error
+func MustRegister(ccs ...api.UserCC) {
+	if err := Register(ccs...); err != nil {
+		panic(err)
+	}
+}
+
+// DeferRegistration is called by components which register their chaincodes
+// asynchronously, e.g. once their own configuration is loaded. The peer waits
+// until the returned function is invoked before it initializes _lifecycle.
+func DeferRegistration() (done func()) {
+	registry.pending.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(registry.pending.Done)
+	}
+}
+
 // GetUCC returns the in-process user chaincode for the given name and version
 func GetUCC(name, version string) (api.UserCC, bool) {
-	return nil, false
+	return GetUCCByPackageID(name + ":" + version)
 }
 
 // GetUCCByPackageID returns the in-process user chaincode for the given package ID
-func GetUCCByPackageID(string) (api.UserCC, bool) {
-	return nil, false
+func GetUCCByPackageID(packageID string) (api.UserCC, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	cc, ok := registry.chaincodes[packageID]
+	return cc, ok
 }
 
-// Chaincodes returns all registered in-process chaincodes
+// Chaincodes returns all registered in-process chaincodes ordered by package ID
 func Chaincodes() []api.UserCC {
-	return nil
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	var packageIDs []string
+	for packageID := range registry.chaincodes {
+		packageIDs = append(packageIDs, packageID)
+	}
+	sort.Strings(packageIDs)
+
+	var ccs []api.UserCC
+	for _, packageID := range packageIDs {
+		ccs = append(ccs, registry.chaincodes[packageID])
+	}
+	return ccs
 }
 
 // WaitForReady blocks until the chaincodes are all registered
 func WaitForReady() {
+	registry.pending.Wait()
 }
 
 // GetPackageID returns the package ID of the chaincode
@@ -40,3 +104,49 @@ func IsValidMSP(mspID string, msps map[string]msp.MSP) bool {
 	_, ok := msps[mspID]
 	return ok
 }
+
+func (r *uccRegistry) register(ccs ...api.UserCC) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// validate all chaincodes before registering any of them
+	packageIDs := make(map[string]struct{})
+	for _, cc := range ccs {
+		if err := validateUCC(cc); err != nil {
+			return err
+		}
+
+		packageID := GetPackageID(cc)
+		if _, exists := r.chaincodes[packageID]; exists {
+			return errors.Errorf("in-process user chaincode [%s] is already registered", packageID)
+		}
+		if _, exists := packageIDs[packageID]; exists {
+			return errors.Errorf("in-process user chaincode [%s] is registered more than once", packageID)
+		}
+		packageIDs[packageID] = struct{}{}
+	}
+
+	for _, cc := range ccs {
+		r.chaincodes[GetPackageID(cc)] = cc
+	}
+	return nil
+}
+
+func validateUCC(cc api.UserCC) error {
+	if cc == nil {
+		return errors.New("in-process user chaincode is nil")
+	}
+	if cc.Name() == "" {
+		return errors.New("in-process user chaincode name is empty")
+	}
+	if cc.Version() == "" {
+		return errors.Errorf("in-process user chaincode [%s] version is empty", cc.Name())
+	}
+	if strings.Contains(cc.Name(), ":") || strings.Contains(cc.Version(), ":") {
+		return errors.Errorf("in-process user chaincode [%s] name and version must not contain ':'", GetPackageID(cc))
+	}
+	if cc.Chaincode() == nil {
+		return errors.Errorf("in-process user chaincode [%s] has no implementation", GetPackageID(cc))
+	}
+	return nil
+}
@@ -8,7 +8,11 @@ package chaincode
 
 import (
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/extensions/chaincode/api"
 	"github.com/hyperledger/fabric/extensions/chaincode/mock"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/stretchr/testify/require"
@@ -16,6 +20,27 @@ import (
 
 //go:generate counterfeiter -o ./mock/usercc.gen.go -fake-name UserCC ./api UserCC
 
+type testChaincode struct{}
+
+func (testChaincode) Init(shim.ChaincodeStubInterface) pb.Response   { return shim.Success(nil) }
+func (testChaincode) Invoke(shim.ChaincodeStubInterface) pb.Response { return shim.Success(nil) }
+
+func newUserCC(name, version string) *mock.UserCC {
+	cc := &mock.UserCC{}
+	cc.NameReturns(name)
+	cc.VersionReturns(version)
+	cc.ChaincodeReturns(testChaincode{})
+	return cc
+}
+
+// resetRegistry replaces the registry with an empty one and returns a
+// function which restores it
+func resetRegistry() func() {
+	r := registry
+	registry = newUCCRegistry()
+	return func() { registry = r }
+}
+
 func TestGetUCC(t *testing.T) {
 	cc, ok := GetUCC("", "")
 	require.False(t, ok)
@@ -26,8 +51,60 @@ func TestChaincodes(t *testing.T) {
 	require.Empty(t, Chaincodes())
 }
 
+func TestRegister(t *testing.T) {
+	defer resetRegistry()()
+
+	cc1 := newUserCC("cc1", "v1")
+	cc2 := newUserCC("cc2", "v1")
+	require.NoError(t, Register(cc2, cc1))
+
+	cc, ok := GetUCC("cc1", "v1")
+	require.True(t, ok)
+	require.Equal(t, cc1, cc)
+
+	cc, ok = GetUCCByPackageID("cc2:v1")
+	require.True(t, ok)
+	require.Equal(t, cc2, cc)
+
+	_, ok = GetUCC("cc1", "v2")
+	require.False(t, ok)
+
+	require.Equal(t, []api.UserCC{cc1, cc2}, Chaincodes())
+
+	require.EqualError(t, Register(newUserCC("cc3", "v1"), cc1), "in-process user chaincode [cc1:v1] is already registered")
+	_, ok = GetUCC("cc3", "v1")
+	require.False(t, ok, "no chaincode should be registered when one of them is invalid")
+
+	require.EqualError(t, Register(newUserCC("cc3", "v1"), newUserCC("cc3", "v1")), "in-process user chaincode [cc3:v1] is registered more than once")
+	require.EqualError(t, Register(newUserCC("", "v1")), "in-process user chaincode name is empty")
+	require.EqualError(t, Register(newUserCC("cc3", "")), "in-process user chaincode [cc3] version is empty")
+	require.EqualError(t, Register(newUserCC("cc3", "v:1")), "in-process user chaincode [cc3:v:1] name and version must not contain ':'")
+	require.EqualError(t, Register(nil), "in-process user chaincode is nil")
+
+	noImpl := &mock.UserCC{}
+	noImpl.NameReturns("cc3")
+	noImpl.VersionReturns("v1")
+	require.EqualError(t, Register(noImpl), "in-process user chaincode [cc3:v1] has no implementation")
+
+	require.Panics(t, func() { MustRegister(cc1) })
+}
+
 func TestWaitForReady(t *testing.T) {
+	defer resetRegistry()()
+
 	require.NotPanics(t, WaitForReady)
+
+	done := DeferRegistration()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		MustRegister(newUserCC("cc1", "v1"))
+		done()
+		done()
+	}()
+
+	WaitForReady()
+	_, ok := GetUCC("cc1", "v1")
+	require.True(t, ok)
 }
 
 func TestGetPackageID(t *testing.T) {
@@ -57,6 +57,10 @@ type TransientStore interface {
 
 	// GetMinTransientBlkHt returns the lowest block height remaining in transient store
 	GetMinTransientBlkHt() (uint64, error)
+
+	// PurgePvtdataKeys removes the given purged private data keys from the private
+	// write sets held in the transient store.
+	PurgePvtdataKeys(purges []*ledger.PvtdataPurge) error
 }
 
 // TransientStoreProvider is a transient store provider
@@ -92,16 +96,35 @@ type PrivateDataStore interface {
 	// GetMissingPvtDataInfoForMostRecentBlocks returns the missing private data information for the
 	// most recent `maxBlock` blocks which miss at least a private data of a eligible collection.
 	GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.MissingPvtDataInfo, error)
+	// GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks returns the missing private data information of the
+	// collections selected by the filter for the most recent `maxBlock` blocks which miss at least a private data
+	// of such a collection. The missing private data for which an earlier reconciliation attempt failed is not returned
+	GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(maxBlock int, filter ledger.MissingPvtDataFilter) (ledger.MissingPvtDataInfo, error)
+	// GetMissingPvtDataSummary summarizes, per collection, the missing private data of eligible
+	// collections that has not expired
+	GetMissingPvtDataSummary() ([]*ledger.CollMissingPvtDataSummary, error)
 	// Commit commits the pvt data as well as both the eligible and ineligible
 	// missing private data --- `eligible` denotes that the missing private data belongs to a collection
 	// for which this peer is a member; `ineligible` denotes that the missing private data belong to a
-	// collection for which this peer is not a member.
-	Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData ledger.TxMissingPvtDataMap) error
+	// collection for which this peer is not a member. The historical versions of the keys listed in
+	// `pvtdataPurges` are removed from the store.
+	Commit(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData ledger.TxMissingPvtDataMap, pvtdataPurges []*ledger.PvtdataPurge) error
+	// IsPurged returns true if the private data key, identified by its hash, was purged by a
+	// transaction committed after the height <blkNum, txNum>
+	IsPurged(ns, coll string, keyHash []byte, blkNum, txNum uint64) (bool, error)
 	// ProcessCollsEligibilityEnabled notifies the store when the peer becomes eligible to receive data for an
 	// existing collection. Parameter 'committingBlk' refers to the block number that contains the corresponding
 	// collection upgrade transaction and the parameter 'nsCollMap' contains the collections for which the peer
 	// is now eligible to receive pvt data
 	ProcessCollsEligibilityEnabled(committingBlk uint64, nsCollMap map[string][]string) error
+	// ProcessCollsEligibilityDisabled notifies the store when the peer is no longer eligible to receive data for an
+	// existing collection. Parameter 'committingBlk' refers to the block number that contains the corresponding
+	// collection upgrade transaction and the parameter 'nsCollMap' contains the collections for which the peer
+	// is not eligible anymore. The private data of these collections is removed from the store
+	ProcessCollsEligibilityDisabled(committingBlk uint64, nsCollMap map[string][]string) error
+	// GetCollsPvtdataRemovalInfo returns the collections for which the peer lost the eligibility
+	// along with the status of the removal of their private data from the store
+	GetCollsPvtdataRemovalInfo() ([]*ledger.CollPvtdataRemovalInfo, error)
 	// CommitPvtDataOfOldBlocks commits the pvtData (i.e., previously missing data) of old blocks.
 	// The parameter `blocksPvtData` refers a list of old block's pvtdata which are missing in the pvtstore.
 	// This call stores an additional entry called `lastUpdatedOldBlocksList` which keeps the exact list
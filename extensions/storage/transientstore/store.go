@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package transientstore
 
 import (
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/hyperledger/fabric/core/transientstore"
 	storageapi "github.com/hyperledger/fabric/extensions/storage/api"
 )
@@ -27,8 +28,8 @@ func (p *ProviderImpl) Close() {
 }
 
 // NewStoreProvider returns a new store provider
-func NewStoreProvider(path string) (*ProviderImpl, error) {
-	provider, err := transientstore.NewStoreProvider(path)
+func NewStoreProvider(path string, encryptor *pvtdataencryption.Encryptor) (*ProviderImpl, error) {
+	provider, err := transientstore.NewStoreProvider(path, encryptor)
 	if err != nil {
 		return nil, err
 	}
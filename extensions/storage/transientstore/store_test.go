@@ -20,7 +20,7 @@ func TestNewStoreProvider(t *testing.T) {
 	path, cleanup := setupPath(t)
 	defer cleanup()
 
-	p, err := NewStoreProvider(path)
+	p, err := NewStoreProvider(path, nil)
 	require.NoError(t, err)
 	require.NotEmpty(t, p)
 }
@@ -50,6 +50,9 @@ type Comm interface {
 	// CloseConn closes a connection to a certain endpoint
 	CloseConn(peer *RemotePeer)
 
+	// Connections returns the information of the connections to remote peers
+	Connections() []*protoext.ConnectionInfo
+
 	// Stop stops the module
 	Stop()
 }
@@ -35,6 +35,9 @@ const (
 	DefConnTimeout   = time.Second * 2
 	DefRecvBuffSize  = 20
 	DefSendBuffSize  = 20
+
+	DefCompressionThreshold = 1024
+	DefMaxBatchSize         = 10
 )
 
 var (
@@ -83,11 +86,18 @@ func NewCommInstance(s *grpc.Server, certs *common.TLSCertificates, idStore iden
 		connTimeout:     config.ConnTimeout,
 		recvBuffSize:    config.RecvBuffSize,
 		sendBuffSize:    config.SendBuffSize,
+		compression:     config.CompressionEnabled,
+		threshold:       config.CompressionThreshold,
+		maxBatchSize:    config.MaxBatchSize,
+		capabilities:    supportedCapabilities,
 	}
 
 	connConfig := ConnConfig{
-		RecvBuffSize: config.RecvBuffSize,
-		SendBuffSize: config.SendBuffSize,
+		RecvBuffSize:         config.RecvBuffSize,
+		SendBuffSize:         config.SendBuffSize,
+		CompressionEnabled:   config.CompressionEnabled,
+		CompressionThreshold: config.CompressionThreshold,
+		MaxBatchSize:         config.MaxBatchSize,
 	}
 
 	commInst.connStore = newConnStore(commInst, commInst.logger, connConfig)
@@ -103,6 +113,14 @@ type CommConfig struct {
 	ConnTimeout  time.Duration // Connection timeout
 	RecvBuffSize int           // Buffer size of received messages
 	SendBuffSize int           // Buffer size of sending messages
+
+	// CompressionEnabled determines whether the messages sent to peers that support it are compressed
+	CompressionEnabled bool
+	// CompressionThreshold is the minimum size in bytes of the messages that are compressed
+	CompressionThreshold int
+	// MaxBatchSize is the maximum number of alive and state info messages that are sent in a
+	// single frame to peers that support it. Messages aren't batched if it is 1 or less.
+	MaxBatchSize int
 }
 
 type commImpl struct {
@@ -129,6 +147,10 @@ type commImpl struct {
 	connTimeout     time.Duration
 	recvBuffSize    int
 	sendBuffSize    int
+	compression     bool
+	threshold       int
+	maxBatchSize    int
+	capabilities    capabilities // capabilities advertised to remote peers
 }
 
 func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidType) (*connection, error) {
@@ -167,7 +189,8 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 
 	ctx, cancel = context.WithCancel(context.Background())
 	if stream, err = cl.GossipStream(ctx); err == nil {
-		connInfo, err = c.authenticateRemotePeer(stream, true, false)
+		var sharedCapabilities capabilities
+		connInfo, sharedCapabilities, err = c.authenticateRemotePeer(stream, true, false)
 		if err == nil {
 			pkiID = connInfo.ID
 			// PKIID is nil when we don't know the remote PKI id's
@@ -188,12 +211,16 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 				}
 			}
 			connConfig := ConnConfig{
-				RecvBuffSize: c.recvBuffSize,
-				SendBuffSize: c.sendBuffSize,
+				RecvBuffSize:         c.recvBuffSize,
+				SendBuffSize:         c.sendBuffSize,
+				CompressionEnabled:   c.compression,
+				CompressionThreshold: c.threshold,
+				MaxBatchSize:         c.maxBatchSize,
 			}
 			conn := newConnection(cl, cc, stream, c.metrics, connConfig)
 			conn.pkiID = pkiID
 			conn.info = connInfo
+			conn.capabilities = sharedCapabilities
 			conn.logger = c.logger
 			conn.cancel = cancel
 
@@ -309,7 +336,7 @@ func (c *commImpl) Handshake(remotePeer *RemotePeer) (api.PeerIdentityType, erro
 	if err != nil {
 		return nil, err
 	}
-	connInfo, err := c.authenticateRemotePeer(stream, true, true)
+	connInfo, _, err := c.authenticateRemotePeer(stream, true, true)
 	if err != nil {
 		c.logger.Warningf("Authentication failed: %v", err)
 		return nil, err
@@ -371,6 +398,10 @@ func (c *commImpl) CloseConn(peer *RemotePeer) {
 	c.connStore.closeConnByPKIid(peer.PKIID)
 }
 
+func (c *commImpl) Connections() []*protoext.ConnectionInfo {
+	return c.connStore.connections()
+}
+
 func (c *commImpl) closeSubscriptions() {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -408,7 +439,9 @@ func extractRemoteAddress(stream stream) string {
 	return remoteAddress
 }
 
-func (c *commImpl) authenticateRemotePeer(stream stream, initiator, isProbe bool) (*protoext.ConnectionInfo, error) {
+// authenticateRemotePeer exchanges connection messages with the remote peer, and returns
+// the connection info and the capabilities supported by both peers
+func (c *commImpl) authenticateRemotePeer(stream stream, initiator, isProbe bool) (*protoext.ConnectionInfo, capabilities, error) {
 	ctx := stream.Context()
 	remoteAddress := extractRemoteAddress(stream)
 	remoteCertHash := extractCertificateHashFromContext(ctx)
@@ -432,12 +465,12 @@ func (c *commImpl) authenticateRemotePeer(stream stream, initiator, isProbe bool
 	// TLS enabled but not detected on other side
 	if useTLS && len(remoteCertHash) == 0 {
 		c.logger.Warningf("%s didn't send TLS certificate", remoteAddress)
-		return nil, fmt.Errorf("No TLS certificate")
+		return nil, 0, fmt.Errorf("No TLS certificate")
 	}
 
 	cMsg, err = c.createConnectionMsg(c.PKIID, selfCertHash, c.peerIdentity, signer, isProbe)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	c.logger.Debug("Sending", cMsg, "to", remoteAddress)
@@ -445,24 +478,24 @@ func (c *commImpl) authenticateRemotePeer(stream stream, initiator, isProbe bool
 	m, err := readWithTimeout(stream, c.connTimeout, remoteAddress)
 	if err != nil {
 		c.logger.Warningf("Failed reading messge from %s, reason: %v", remoteAddress, err)
-		return nil, err
+		return nil, 0, err
 	}
 	receivedMsg := m.GetConn()
 	if receivedMsg == nil {
 		c.logger.Warning("Expected connection message from", remoteAddress, "but got", receivedMsg)
-		return nil, fmt.Errorf("Wrong type")
+		return nil, 0, fmt.Errorf("Wrong type")
 	}
 
 	if receivedMsg.PkiId == nil {
 		c.logger.Warningf("%s didn't send a pkiID", remoteAddress)
-		return nil, fmt.Errorf("No PKI-ID")
+		return nil, 0, fmt.Errorf("No PKI-ID")
 	}
 
 	c.logger.Debug("Received", receivedMsg, "from", remoteAddress)
 	err = c.idMapper.Put(receivedMsg.PkiId, receivedMsg.Identity)
 	if err != nil {
 		c.logger.Warningf("Identity store rejected %s : %v", remoteAddress, err)
-		return nil, err
+		return nil, 0, err
 	}
 
 	connInfo := &protoext.ConnectionInfo{
@@ -480,7 +513,7 @@ func (c *commImpl) authenticateRemotePeer(stream stream, initiator, isProbe bool
 		// If the remote peer sent its TLS certificate, make sure it actually matches the TLS cert
 		// that the peer used.
 		if !bytes.Equal(remoteCertHash, receivedMsg.TlsCertHash) {
-			return nil, errors.Errorf("Expected %v in remote hash of TLS cert, but got %v", remoteCertHash, receivedMsg.TlsCertHash)
+			return nil, 0, errors.Errorf("Expected %v in remote hash of TLS cert, but got %v", remoteCertHash, receivedMsg.TlsCertHash)
 		}
 	}
 	// Final step - verify the signature on the connection message itself
@@ -491,16 +524,18 @@ func (c *commImpl) authenticateRemotePeer(stream stream, initiator, isProbe bool
 	err = m.Verify(receivedMsg.Identity, verifier)
 	if err != nil {
 		c.logger.Errorf("Failed verifying signature from %s : %v", remoteAddress, err)
-		return nil, err
+		return nil, 0, err
 	}
 
 	c.logger.Debug("Authenticated", remoteAddress)
 
+	sharedCapabilities := capabilities(m.Nonce) & c.capabilities
+
 	if receivedMsg.Probe {
-		return connInfo, errProbe
+		return connInfo, sharedCapabilities, errProbe
 	}
 
-	return connInfo, nil
+	return connInfo, sharedCapabilities, nil
 }
 
 // SendWithAck sends a message to remote peers, waiting for acknowledgement from minAck of them, or until a certain timeout expires
@@ -564,7 +599,7 @@ func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	if c.isStopping() {
 		return fmt.Errorf("Shutting down")
 	}
-	connInfo, err := c.authenticateRemotePeer(stream, false, false)
+	connInfo, sharedCapabilities, err := c.authenticateRemotePeer(stream, false, false)
 
 	if err == errProbe {
 		c.logger.Infof("Peer %s (%s) probed us", connInfo.ID, connInfo.Endpoint)
@@ -577,7 +612,7 @@ func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	}
 	c.logger.Debug("Servicing", extractRemoteAddress(stream))
 
-	conn := c.connStore.onConnected(stream, connInfo, c.metrics)
+	conn := c.connStore.onConnected(stream, connInfo, sharedCapabilities, c.metrics)
 
 	h := func(m *protoext.SignedGossipMessage) {
 		c.msgPublisher.DeMultiplex(&ReceivedMessageImpl{
@@ -636,8 +671,10 @@ func readWithTimeout(stream stream, timeout time.Duration, address string) (*pro
 
 func (c *commImpl) createConnectionMsg(pkiID common.PKIidType, certHash []byte, cert api.PeerIdentityType, signer protoext.Signer, isProbe bool) (*protoext.SignedGossipMessage, error) {
 	m := &proto.GossipMessage{
-		Tag:   proto.GossipMessage_EMPTY,
-		Nonce: 0,
+		Tag: proto.GossipMessage_EMPTY,
+		// The Nonce of connection messages is otherwise unused and is
+		// set to 0 by older peers, which therefore advertise no capabilities
+		Nonce: uint64(c.capabilities),
 		Content: &proto.GossipMessage_Conn{
 			Conn: &proto.ConnEstablish{
 				TlsCertHash: certHash,
@@ -128,6 +128,12 @@ func (*naiveSecProvider) VerifyByChannel(_ common.ChannelID, _ api.PeerIdentityT
 func newCommInstanceOnlyWithMetrics(t *testing.T, commMetrics *metrics.CommMetrics, sec *naiveSecProvider,
 	gRPCServer *comm.GRPCServer, certs *common.TLSCertificates,
 	secureDialOpts api.PeerSecureDialOpts, dialOpts ...grpc.DialOption) Comm {
+	return newCommInstanceOnlyWithConfig(t, testCommConfig, commMetrics, sec, gRPCServer, certs, secureDialOpts, dialOpts...)
+}
+
+func newCommInstanceOnlyWithConfig(t *testing.T, config CommConfig, commMetrics *metrics.CommMetrics, sec *naiveSecProvider,
+	gRPCServer *comm.GRPCServer, certs *common.TLSCertificates,
+	secureDialOpts api.PeerSecureDialOpts, dialOpts ...grpc.DialOption) Comm {
 
 	_, portString, err := net.SplitHostPort(gRPCServer.Address())
 	assert.NoError(t, err)
@@ -137,7 +143,7 @@ func newCommInstanceOnlyWithMetrics(t *testing.T, commMetrics *metrics.CommMetri
 	identityMapper := identity.NewIdentityMapper(sec, id, noopPurgeIdentity, sec)
 
 	commInst, err := NewCommInstance(gRPCServer.Server(), certs, identityMapper, id, secureDialOpts,
-		sec, commMetrics, testCommConfig, dialOpts...)
+		sec, commMetrics, config, dialOpts...)
 	assert.NoError(t, err)
 
 	go func() {
@@ -842,6 +848,34 @@ func TestReConnections(t *testing.T) {
 	comm2.Stop()
 }
 
+func TestConnections(t *testing.T) {
+	comm1, port1 := newCommInstance(t, naiveSec)
+	defer comm1.Stop()
+	comm2, port2 := newCommInstance(t, naiveSec)
+	defer comm2.Stop()
+	assert.Empty(t, comm1.Connections())
+
+	inc := comm2.Accept(acceptAll)
+	comm1.Send(createGossipMsg(), remotePeer(port2))
+	select {
+	case <-inc:
+	case <-time.After(10 * time.Second):
+		assert.FailNow(t, "didn't receive the message in a timely manner")
+	}
+
+	conns := comm1.Connections()
+	assert.Len(t, conns, 1)
+	assert.Equal(t, remotePeer(port2).PKIID, conns[0].ID)
+	assert.Equal(t, remotePeer(port2).Endpoint, conns[0].Endpoint)
+	assert.Eventually(t, func() bool {
+		return len(comm2.Connections()) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, remotePeer(port1).PKIID, comm2.Connections()[0].ID)
+
+	comm1.CloseConn(remotePeer(port2))
+	assert.Empty(t, comm1.Connections())
+}
+
 func TestProbe(t *testing.T) {
 	comm1, port1 := newCommInstance(t, naiveSec)
 	defer comm1.Stop()
@@ -931,7 +965,7 @@ func TestReadFromStream(t *testing.T) {
 	stream.On("Recv").Return(&proto.Envelope{Payload: []byte{1}}, nil).Once()
 	stream.On("Recv").Return(nil, errors.New("stream closed")).Once()
 
-	conn := newConnection(nil, nil, stream, disabledMetrics, ConnConfig{1, 1})
+	conn := newConnection(nil, nil, stream, disabledMetrics, ConnConfig{RecvBuffSize: 1, SendBuffSize: 1})
 	conn.logger = flogging.MustGetLogger("test")
 
 	errChan := make(chan error, 2)
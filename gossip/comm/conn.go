@@ -144,6 +144,19 @@ func (cs *connectionStore) connNum() int {
 	return len(cs.pki2Conn)
 }
 
+// connections returns the information of the established connections
+func (cs *connectionStore) connections() []*protoext.ConnectionInfo {
+	cs.RLock()
+	defer cs.RUnlock()
+	res := make([]*protoext.ConnectionInfo, 0, len(cs.pki2Conn))
+	for _, conn := range cs.pki2Conn {
+		if conn.info != nil {
+			res = append(res, conn.info)
+		}
+	}
+	return res
+}
+
 func (cs *connectionStore) shutdown() {
 	cs.shutdownOnce.Do(func() {
 		cs.Lock()
@@ -161,7 +174,7 @@ func (cs *connectionStore) shutdown() {
 // onConnected closes any connection to the remote peer and creates a new connection object to it in order to have only
 // one single bi-directional connection between a pair of peers
 func (cs *connectionStore) onConnected(serverStream proto.Gossip_GossipStreamServer,
-	connInfo *protoext.ConnectionInfo, metrics *metrics.CommMetrics) *connection {
+	connInfo *protoext.ConnectionInfo, sharedCapabilities capabilities, metrics *metrics.CommMetrics) *connection {
 	cs.Lock()
 	defer cs.Unlock()
 
@@ -172,6 +185,7 @@ func (cs *connectionStore) onConnected(serverStream proto.Gossip_GossipStreamSer
 	conn := newConnection(nil, nil, serverStream, metrics, cs.config)
 	conn.pkiID = connInfo.ID
 	conn.info = connInfo
+	conn.capabilities = sharedCapabilities
 	conn.logger = cs.logger
 	cs.pki2Conn[string(connInfo.ID)] = conn
 	return conn
@@ -195,18 +209,28 @@ func newConnection(cl proto.GossipClient, c *grpc.ClientConn, s stream, metrics
 		gossipStream: s,
 		stopChan:     make(chan struct{}, 1),
 		recvBuffSize: config.RecvBuffSize,
+		compress:     config.CompressionEnabled,
+		threshold:    config.CompressionThreshold,
+		maxBatchSize: config.MaxBatchSize,
 	}
 	return connection
 }
 
 // ConnConfig is the configuration required to initialize a new conn
 type ConnConfig struct {
-	RecvBuffSize int
-	SendBuffSize int
+	RecvBuffSize         int
+	SendBuffSize         int
+	CompressionEnabled   bool
+	CompressionThreshold int
+	MaxBatchSize         int
 }
 
 type connection struct {
 	recvBuffSize int
+	compress     bool         // whether messages are compressed if the remote peer supports it
+	threshold    int          // minimum size of the compressed messages
+	maxBatchSize int          // maximum number of messages batched if the remote peer supports it
+	capabilities capabilities // capabilities supported by both peers
 	metrics      *metrics.CommMetrics
 	cancel       context.CancelFunc
 	info         *protoext.ConnectionInfo
@@ -237,8 +261,9 @@ func (conn *connection) close() {
 
 func (conn *connection) send(msg *protoext.SignedGossipMessage, onErr func(error), shouldBlock blockingBehavior) {
 	m := &msgSending{
-		envelope: msg.Envelope,
-		onErr:    onErr,
+		envelope:  msg.Envelope,
+		onErr:     onErr,
+		batchable: msg.GetAliveMsg() != nil || msg.GetStateInfo() != nil,
 	}
 
 	select {
@@ -286,22 +311,85 @@ func (conn *connection) serviceConnection() error {
 
 func (conn *connection) writeToStream() {
 	stream := conn.gossipStream
+	var next *msgSending
 	for {
-		select {
-		case m := <-conn.outBuff:
-			err := stream.Send(m.envelope)
-			if err != nil {
-				go m.onErr(err)
+		m := next
+		next = nil
+		if m == nil {
+			select {
+			case m = <-conn.outBuff:
+			case <-conn.stopChan:
+				conn.logger.Debug("Closing writing to stream")
 				return
 			}
-			conn.metrics.SentMessages.Add(1)
-		case <-conn.stopChan:
-			conn.logger.Debug("Closing writing to stream")
+		}
+		batch := []*msgSending{m}
+		if m.batchable && conn.batches() {
+			batch, next = conn.fillBatch(batch)
+		}
+		if err := conn.sendFrame(stream, batch); err != nil {
+			// none of the messages of the batch was sent, nor the one
+			// taken from the buffer after them
+			if next != nil {
+				batch = append(batch, next)
+			}
+			for _, m := range batch {
+				go m.onErr(err)
+			}
 			return
 		}
 	}
 }
 
+// fillBatch appends to the given batch the batchable messages that are already waiting to be
+// sent, and returns the batch along with the message that follows them if it isn't batchable
+func (conn *connection) fillBatch(batch []*msgSending) ([]*msgSending, *msgSending) {
+	for len(batch) < conn.maxBatchSize {
+		select {
+		case m := <-conn.outBuff:
+			if !m.batchable {
+				return batch, m
+			}
+			batch = append(batch, m)
+		default:
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+func (conn *connection) sendFrame(stream stream, batch []*msgSending) error {
+	envelopes := make([]*proto.Envelope, len(batch))
+	for i, m := range batch {
+		envelopes[i] = m.envelope
+	}
+	frame, bytesSaved, err := encodeFrame(envelopes, conn.compresses(), conn.threshold)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(frame); err != nil {
+		return err
+	}
+	conn.metrics.SentMessages.Add(float64(len(batch)))
+	if len(batch) > 1 {
+		conn.metrics.BatchedMessages.Add(float64(len(batch)))
+	}
+	if bytesSaved > 0 {
+		conn.metrics.CompressionBytesSaved.Add(float64(bytesSaved))
+	}
+	return nil
+}
+
+// compresses returns whether the messages sent to the remote peer are compressed
+func (conn *connection) compresses() bool {
+	return conn.compress && conn.capabilities&compressionCapability != 0
+}
+
+// batches returns whether the alive and state info messages sent to the remote peer are batched
+func (conn *connection) batches() bool {
+	return conn.maxBatchSize > 1 && conn.capabilities&batchingCapability != 0
+}
+
 func (conn *connection) readFromStream(errChan chan error, msgChan chan *protoext.SignedGossipMessage) {
 	stream := conn.gossipStream
 	for {
@@ -315,24 +403,34 @@ func (conn *connection) readFromStream(errChan chan error, msgChan chan *protoex
 				conn.logger.Debugf("Got error, aborting: %v", err)
 				return
 			}
-			conn.metrics.ReceivedMessages.Add(1)
-			msg, err := protoext.EnvelopeToGossipMessage(envelope)
+			envelopes, err := decodeFrame(envelope)
 			if err != nil {
 				errChan <- err
 				conn.logger.Warningf("Got error, aborting: %v", err)
 				return
 			}
-			select {
-			case <-conn.stopChan:
-			case msgChan <- msg:
+			for _, envelope := range envelopes {
+				conn.metrics.ReceivedMessages.Add(1)
+				msg, err := protoext.EnvelopeToGossipMessage(envelope)
+				if err != nil {
+					errChan <- err
+					conn.logger.Warningf("Got error, aborting: %v", err)
+					return
+				}
+				select {
+				case <-conn.stopChan:
+					return
+				case msgChan <- msg:
+				}
 			}
 		}
 	}
 }
 
 type msgSending struct {
-	envelope *proto.Envelope
-	onErr    func(error)
+	envelope  *proto.Envelope
+	onErr     func(error)
+	batchable bool
 }
 
 //go:generate mockery -dir . -name MockStream -case underscore -output mocks/
@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+
+	pb "github.com/golang/protobuf/proto"
+	proto "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/pkg/errors"
+)
+
+// capabilities are the optional features of the communication layer which a peer
+// advertises in the Nonce of its connection message. Peers that predate these features
+// advertise none, so a feature is only used towards peers that advertised it.
+type capabilities uint64
+
+const (
+	// compressionCapability is the capability of receiving compressed frames
+	compressionCapability capabilities = 1 << iota
+	// batchingCapability is the capability of receiving several messages in a single frame
+	batchingCapability
+
+	supportedCapabilities = compressionCapability | batchingCapability
+)
+
+// The payload of the envelope of a frame starts with a header byte whose 3 lowest bits,
+// the protobuf wire type of the first field of a marshaled message, are an invalid wire
+// type. Therefore the envelopes of messages are never mistaken for frames.
+const (
+	frameMarker     byte = 0x07
+	frameMarkerMask byte = 0x07
+	compressedFrame byte = 1 << 3
+	batchFrame      byte = 1 << 4
+
+	// maxFramePayloadSize is the maximum size of the decompressed payload of a frame
+	maxFramePayloadSize = 100 * 1024 * 1024
+)
+
+// encodeFrame encodes the given envelopes into the envelope sent to a remote peer, along with the
+// number of bytes saved by compression. Several envelopes are batched into a single frame, and the
+// frame is compressed if compress is true and its payload is at least compressionThreshold bytes.
+// A single envelope which isn't compressed is sent as is.
+func encodeFrame(envelopes []*proto.Envelope, compress bool, compressionThreshold int) (*proto.Envelope, int, error) {
+	var header byte
+	frame := &proto.Envelope{}
+	payload := envelopes[0].Payload
+	if len(envelopes) == 1 {
+		frame.Signature = envelopes[0].Signature
+		frame.SecretEnvelope = envelopes[0].SecretEnvelope
+	} else {
+		var err error
+		payload, err = pb.Marshal(&proto.StateInfoSnapshot{Elements: envelopes})
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed marshaling batch")
+		}
+		header |= batchFrame
+	}
+
+	var bytesSaved int
+	if compress && len(payload) >= compressionThreshold {
+		compressed, err := compressPayload(payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		// the header byte is only worth it if the compression saves more than it
+		if len(compressed)+1 < len(payload) {
+			bytesSaved = len(payload) - len(compressed) - 1
+			payload = compressed
+			header |= compressedFrame
+		}
+	}
+
+	if header == 0 {
+		return envelopes[0], 0, nil
+	}
+	frame.Payload = append([]byte{frameMarker | header}, payload...)
+	return frame, bytesSaved, nil
+}
+
+// decodeFrame decodes the envelopes of the messages sent in the given envelope
+func decodeFrame(frame *proto.Envelope) ([]*proto.Envelope, error) {
+	if len(frame.Payload) == 0 || frame.Payload[0]&frameMarkerMask != frameMarker {
+		return []*proto.Envelope{frame}, nil
+	}
+
+	header, payload := frame.Payload[0], frame.Payload[1:]
+	if header&^(frameMarkerMask|compressedFrame|batchFrame) != 0 {
+		return nil, errors.Errorf("unsupported frame header %#x", header)
+	}
+	if header&compressedFrame != 0 {
+		var err error
+		payload, err = decompressPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if header&batchFrame == 0 {
+		return []*proto.Envelope{{
+			Payload:        payload,
+			Signature:      frame.Signature,
+			SecretEnvelope: frame.SecretEnvelope,
+		}}, nil
+	}
+
+	batch := &proto.StateInfoSnapshot{}
+	if err := pb.Unmarshal(payload, batch); err != nil {
+		return nil, errors.Wrap(err, "failed unmarshaling batch")
+	}
+	return batch.Elements, nil
+}
+
+func compressPayload(payload []byte) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	w, err := flate.NewWriter(buff, flate.BestSpeed)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return nil, errors.Wrap(err, "failed compressing payload")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed compressing payload")
+	}
+	return buff.Bytes(), nil
+}
+
+func decompressPayload(compressed []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(compressed))
+	defer r.Close()
+	payload, err := ioutil.ReadAll(io.LimitReader(r, maxFramePayloadSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decompressing payload")
+	}
+	if len(payload) > maxFramePayloadSize {
+		return nil, errors.Errorf("decompressed payload exceeds %d bytes", maxFramePayloadSize)
+	}
+	return payload, nil
+}
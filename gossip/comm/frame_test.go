@@ -0,0 +1,306 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	proto "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric/common/flogging"
+	gmocks "github.com/hyperledger/fabric/gossip/comm/mocks"
+	"github.com/hyperledger/fabric/gossip/metrics"
+	"github.com/hyperledger/fabric/gossip/metrics/mocks"
+	"github.com/hyperledger/fabric/gossip/protoext"
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func aliveMsg(seqNum uint64) *protoext.SignedGossipMessage {
+	msg, _ := protoext.NoopSign(&proto.GossipMessage{
+		Tag: proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_AliveMsg{
+			AliveMsg: &proto.AliveMessage{
+				Membership: &proto.Member{Endpoint: "p0"},
+				Timestamp:  &proto.PeerTime{SeqNum: seqNum},
+			},
+		},
+	})
+	msg.Envelope.Signature = []byte{1, 2, 3}
+	return msg
+}
+
+func dataMsg(data []byte) *protoext.SignedGossipMessage {
+	msg, _ := protoext.NoopSign(&proto.GossipMessage{
+		Tag: proto.GossipMessage_CHAN_AND_ORG,
+		Content: &proto.GossipMessage_DataMsg{
+			DataMsg: &proto.DataMessage{
+				Payload: &proto.Payload{SeqNum: 1, Data: data},
+			},
+		},
+	})
+	msg.Envelope.SecretEnvelope = &proto.SecretEnvelope{Payload: []byte("secret")}
+	return msg
+}
+
+func TestEncodeDecodeFrame(t *testing.T) {
+	block := bytes.Repeat([]byte("transaction "), 1000)
+	random := make([]byte, 2000)
+	_, err := rand.Read(random)
+	assert.NoError(t, err)
+	var aliveEnvelopes []*proto.Envelope
+	for i := 1; i <= DefMaxBatchSize; i++ {
+		aliveEnvelopes = append(aliveEnvelopes, aliveMsg(uint64(i)).Envelope)
+	}
+
+	for _, testCase := range []struct {
+		name         string
+		envelopes    []*proto.Envelope
+		compress     bool
+		threshold    int
+		expectedType byte
+	}{
+		{
+			name:      "message below the compression threshold",
+			envelopes: []*proto.Envelope{aliveMsg(1).Envelope},
+			compress:  true,
+			threshold: 1024,
+		},
+		{
+			name:      "compression disabled",
+			envelopes: []*proto.Envelope{dataMsg(block).Envelope},
+			threshold: 1024,
+		},
+		{
+			name:      "incompressible message",
+			envelopes: []*proto.Envelope{dataMsg(random).Envelope},
+			compress:  true,
+			threshold: 1024,
+		},
+		{
+			name:         "compressed message",
+			envelopes:    []*proto.Envelope{dataMsg(block).Envelope},
+			compress:     true,
+			threshold:    1024,
+			expectedType: compressedFrame,
+		},
+		{
+			name:         "batch",
+			envelopes:    []*proto.Envelope{aliveMsg(1).Envelope, aliveMsg(2).Envelope, aliveMsg(3).Envelope},
+			compress:     true,
+			threshold:    1024,
+			expectedType: batchFrame,
+		},
+		{
+			name:         "compressed batch",
+			envelopes:    aliveEnvelopes,
+			compress:     true,
+			expectedType: batchFrame | compressedFrame,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			frame, bytesSaved, err := encodeFrame(testCase.envelopes, testCase.compress, testCase.threshold)
+			assert.NoError(t, err)
+			if testCase.expectedType == 0 {
+				assert.Equal(t, testCase.envelopes[0], frame)
+				assert.Zero(t, bytesSaved)
+			} else {
+				assert.Equal(t, frameMarker|testCase.expectedType, frame.Payload[0])
+			}
+			if testCase.expectedType&compressedFrame != 0 {
+				assert.True(t, bytesSaved > 0)
+			} else {
+				assert.Zero(t, bytesSaved)
+			}
+
+			envelopes, err := decodeFrame(frame)
+			assert.NoError(t, err)
+			assert.Len(t, envelopes, len(testCase.envelopes))
+			for i := range envelopes {
+				assert.True(t, pb.Equal(testCase.envelopes[i], envelopes[i]))
+			}
+		})
+	}
+}
+
+func TestDecodeFrameErrors(t *testing.T) {
+	_, err := decodeFrame(&proto.Envelope{Payload: []byte{frameMarker | 1<<5, 1, 2}})
+	assert.EqualError(t, err, "unsupported frame header 0x27")
+
+	_, err = decodeFrame(&proto.Envelope{Payload: []byte{frameMarker | compressedFrame, 0xff, 0xff}})
+	assert.Contains(t, err.Error(), "failed decompressing payload")
+
+	_, err = decodeFrame(&proto.Envelope{Payload: []byte{frameMarker | batchFrame, 0xff, 0xff}})
+	assert.Contains(t, err.Error(), "failed unmarshaling batch")
+}
+
+func TestWriteToStreamBatches(t *testing.T) {
+	var frames []*proto.Envelope
+	sent := make(chan struct{}, 10)
+	stream := &gmocks.MockStream{}
+	stream.On("Send", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		frames = append(frames, args.Get(0).(*proto.Envelope))
+		sent <- struct{}{}
+	})
+
+	testMetricProvider := mocks.TestUtilConstructMetricProvider()
+	commMetrics := metrics.NewGossipMetrics(testMetricProvider.FakeProvider).CommMetrics
+	conn := newConnection(nil, nil, stream, commMetrics, ConnConfig{SendBuffSize: 10, MaxBatchSize: 3})
+	conn.logger = flogging.MustGetLogger("test")
+	conn.capabilities = supportedCapabilities
+	defer conn.close()
+
+	msgs := []*protoext.SignedGossipMessage{aliveMsg(1), aliveMsg(2), dataMsg([]byte{1}), aliveMsg(3), aliveMsg(4), aliveMsg(5), aliveMsg(6)}
+	for _, msg := range msgs {
+		conn.send(msg, func(error) {}, nonBlockingSend)
+	}
+	go conn.writeToStream()
+	for i := 0; i < 4; i++ {
+		select {
+		case <-sent:
+		case <-time.After(10 * time.Second):
+			assert.FailNow(t, "frames weren't sent in a timely manner")
+		}
+	}
+
+	var received []*protoext.SignedGossipMessage
+	for _, frame := range frames {
+		envelopes, err := decodeFrame(frame)
+		assert.NoError(t, err)
+		for _, envelope := range envelopes {
+			msg, err := protoext.EnvelopeToGossipMessage(envelope)
+			assert.NoError(t, err)
+			received = append(received, msg)
+		}
+	}
+	assert.Len(t, received, len(msgs))
+	for i := range msgs {
+		assert.True(t, pb.Equal(msgs[i].Envelope, received[i].Envelope))
+	}
+	assert.Equal(t, frameMarker|batchFrame, frames[0].Payload[0])
+	assert.Equal(t, msgs[2].Envelope, frames[1])
+	assert.Equal(t, frameMarker|batchFrame, frames[2].Payload[0])
+	assert.Equal(t, msgs[6].Envelope, frames[3])
+
+	assert.Equal(t, 2, testMetricProvider.FakeBatchedMessages.AddCallCount())
+	assert.Equal(t, float64(2), testMetricProvider.FakeBatchedMessages.AddArgsForCall(0))
+	assert.Equal(t, float64(3), testMetricProvider.FakeBatchedMessages.AddArgsForCall(1))
+	assert.Zero(t, testMetricProvider.FakeBytesSaved.AddCallCount())
+}
+
+func TestWriteToStreamBatchFailure(t *testing.T) {
+	stream := &gmocks.MockStream{}
+	stream.On("Send", mock.Anything).Return(errors.New("stream closed"))
+
+	testMetricProvider := mocks.TestUtilConstructMetricProvider()
+	commMetrics := metrics.NewGossipMetrics(testMetricProvider.FakeProvider).CommMetrics
+	conn := newConnection(nil, nil, stream, commMetrics, ConnConfig{SendBuffSize: 10, MaxBatchSize: 3})
+	conn.logger = flogging.MustGetLogger("test")
+	conn.capabilities = supportedCapabilities
+	defer conn.close()
+
+	failed := make(chan error, 10)
+	onErr := func(err error) {
+		failed <- err
+	}
+	for _, msg := range []*protoext.SignedGossipMessage{aliveMsg(1), aliveMsg(2), dataMsg([]byte{1}), aliveMsg(3)} {
+		conn.send(msg, onErr, nonBlockingSend)
+	}
+	go conn.writeToStream()
+
+	// the two alive messages of the batch and the data message that follows them fail
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-failed:
+			assert.EqualError(t, err, "stream closed")
+		case <-time.After(10 * time.Second):
+			assert.FailNow(t, "the failure of the messages wasn't reported in a timely manner")
+		}
+	}
+	select {
+	case <-failed:
+		assert.Fail(t, "the failure of a message which wasn't taken from the buffer was reported")
+	case <-time.After(100 * time.Millisecond):
+	}
+	stream.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestCompression(t *testing.T) {
+	testMetricProvider := mocks.TestUtilConstructMetricProvider()
+	commMetrics := metrics.NewGossipMetrics(testMetricProvider.FakeProvider).CommMetrics
+	config := testCommConfig
+	config.CompressionEnabled = true
+	config.CompressionThreshold = DefCompressionThreshold
+	config.MaxBatchSize = DefMaxBatchSize
+
+	port1, gRPCServer1, certs1, secureDialOpts1, dialOpts1 := util.CreateGRPCLayer()
+	comm1 := newCommInstanceOnlyWithConfig(t, config, commMetrics, naiveSec, gRPCServer1, certs1, secureDialOpts1, dialOpts1...)
+	defer comm1.Stop()
+	// comm2 doesn't compress the messages it sends, but receives compressed messages
+	comm2, port2 := newCommInstance(t, naiveSec)
+	defer comm2.Stop()
+
+	block := bytes.Repeat([]byte("transaction "), 1000)
+	acceptData := func(o interface{}) bool {
+		return o.(protoext.ReceivedMessage).GetGossipMessage().GetDataMsg() != nil
+	}
+
+	fromComm1 := comm2.Accept(acceptData)
+	msg := dataMsg(block)
+	comm1.Send(msg, remotePeer(port2))
+	select {
+	case received := <-fromComm1:
+		assert.True(t, pb.Equal(msg.Envelope, received.GetSourceEnvelope()))
+	case <-time.After(10 * time.Second):
+		assert.FailNow(t, "didn't receive the message in a timely manner")
+	}
+	assert.Equal(t, 1, testMetricProvider.FakeBytesSaved.AddCallCount())
+	assert.True(t, testMetricProvider.FakeBytesSaved.AddArgsForCall(0) > float64(len(block)/2))
+
+	fromComm2 := comm1.Accept(acceptData)
+	msg = dataMsg(block)
+	comm2.Send(msg, remotePeer(port1))
+	select {
+	case received := <-fromComm2:
+		assert.True(t, pb.Equal(msg.Envelope, received.GetSourceEnvelope()))
+	case <-time.After(10 * time.Second):
+		assert.FailNow(t, "didn't receive the message in a timely manner")
+	}
+}
+
+func TestCompressionWithLegacyPeer(t *testing.T) {
+	config := testCommConfig
+	config.CompressionEnabled = true
+	config.MaxBatchSize = DefMaxBatchSize
+	port, gRPCServer, certs, secureDialOpts, dialOpts := util.CreateGRPCLayer()
+	comm1 := newCommInstanceOnlyWithConfig(t, config, disabledMetrics, naiveSec, gRPCServer, certs, secureDialOpts, dialOpts...)
+	defer comm1.Stop()
+
+	// the session advertises no capabilities, like the peers that predate them
+	stream, err := establishSession(t, port)
+	assert.NoError(t, err)
+
+	inc := comm1.Accept(acceptAll)
+	assert.NoError(t, stream.Send(createGossipMsg().Envelope))
+	var received protoext.ReceivedMessage
+	select {
+	case received = <-inc:
+	case <-time.After(10 * time.Second):
+		assert.FailNow(t, "didn't receive the message in a timely manner")
+	}
+
+	msg := dataMsg(bytes.Repeat([]byte("transaction "), 1000))
+	received.Respond(msg.GossipMessage)
+	envelope, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Envelope.Payload, envelope.Payload)
+}
@@ -190,6 +190,11 @@ func (mock *commMock) CloseConn(peer *comm.RemotePeer) {
 	// NOOP
 }
 
+// Connections returns the information of the connections to remote peers
+func (mock *commMock) Connections() []*protoext.ConnectionInfo {
+	return nil
+}
+
 // Stop stops the module
 func (mock *commMock) Stop() {
 	logger.Debug("Stopping communication module, closing all accepting channels.")
@@ -8,6 +8,7 @@ package discovery
 
 import (
 	"fmt"
+	"time"
 
 	protolib "github.com/golang/protobuf/proto"
 	proto "github.com/hyperledger/fabric-protos-go/gossip"
@@ -125,6 +126,16 @@ func (n NetworkMember) PreferredEndpoint() string {
 	return n.Endpoint
 }
 
+// MemberState is a member in the view of the discovery
+// module, along with whether it is considered alive
+type MemberState struct {
+	NetworkMember
+	// Alive is whether the member is considered alive
+	Alive bool
+	// LastSeen is the time the last alive message of the member was received
+	LastSeen time.Time
+}
+
 // PeerIdentification encompasses a remote peer's
 // PKI-ID and whether its in the same org as the current
 // peer or not
@@ -155,6 +166,10 @@ type Discovery interface {
 	// GetMembership returns the alive members in the view
 	GetMembership() []NetworkMember
 
+	// KnownMembers returns the alive and the dead members in the view,
+	// along with the time they were last seen alive
+	KnownMembers() []MemberState
+
 	// InitiateSync makes the instance ask a given number of peers
 	// for their membership information
 	InitiateSync(peerNum int)
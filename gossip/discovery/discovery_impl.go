@@ -967,6 +967,30 @@ func (d *gossipDiscoveryImpl) GetMembership() []NetworkMember {
 
 }
 
+func (d *gossipDiscoveryImpl) KnownMembers() []MemberState {
+	if d.toDie() {
+		return []MemberState{}
+	}
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	response := make([]MemberState, 0, len(d.aliveLastTS)+len(d.deadLastTS))
+	for alive, lastTS := range map[bool]map[string]*timestamp{true: d.aliveLastTS, false: d.deadLastTS} {
+		for pkiIDStr, ts := range lastTS {
+			member := d.id2Member[pkiIDStr]
+			if member == nil {
+				continue
+			}
+			response = append(response, MemberState{
+				NetworkMember: *member,
+				Alive:         alive,
+				LastSeen:      ts.lastSeen,
+			})
+		}
+	}
+	return response
+}
+
 func tsToTime(ts uint64) time.Time {
 	return time.Unix(int64(0), int64(ts))
 }
@@ -913,6 +913,37 @@ func TestExpiration(t *testing.T) {
 	waitUntilOrFailBlocking(t, stopAction.Wait)
 }
 
+func TestKnownMembers(t *testing.T) {
+	bootPeers := []string{bootPeer(14611)}
+	instances := []*gossipInstance{
+		createDiscoveryInstance(14611, "d1", bootPeers),
+		createDiscoveryInstance(14612, "d2", bootPeers),
+		createDiscoveryInstance(14613, "d3", bootPeers),
+	}
+	assertMembership(t, instances, 2)
+
+	start := time.Now()
+	members := instances[0].KnownMembers()
+	assert.Len(t, members, 2)
+	for _, m := range members {
+		assert.True(t, m.Alive)
+		assert.Contains(t, []string{"localhost:14612", "localhost:14613"}, m.Endpoint)
+		assert.True(t, m.LastSeen.Before(start))
+	}
+
+	waitUntilOrFailBlocking(t, instances[2].Stop)
+	assertMembership(t, instances[:2], 1)
+
+	members = instances[0].KnownMembers()
+	assert.Len(t, members, 2)
+	for _, m := range members {
+		assert.Equal(t, m.Endpoint == "localhost:14612", m.Alive)
+	}
+
+	stopInstances(t, instances[:2])
+	assert.Empty(t, instances[0].KnownMembers())
+}
+
 func TestGetFullMembership(t *testing.T) {
 	nodeNum := 15
 	bootPeers := []string{bootPeer(5511), bootPeer(5512)}
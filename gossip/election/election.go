@@ -13,12 +13,14 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/util"
 )
 
 // Gossip leader election module
 // Algorithm properties:
-// - Peers break symmetry by comparing IDs
+// - Peers break symmetry by ranking their IDs with a Strategy,
+//   by default the peer with the lowest ID is the best candidate
 // - Each peer is either a leader or a follower,
 //   and the aim is to have exactly 1 leader if the membership view
 //   is the same for all peers
@@ -50,7 +52,7 @@ import (
 //		If you are the leader:
 //			Broadcast leadership declaration
 //			If a leadership declaration was received from
-// 			a peer with a lower ID,
+// 			a better candidate than yourself,
 //			become a follower
 //		Else, you're a follower:
 //			If haven't received a leadership declaration within
@@ -63,7 +65,7 @@ import (
 //	If received a leadership declaration:
 //		return
 //	Iterate over all proposal messages collected.
-// 	If a proposal message from a peer with an ID lower
+// 	If a proposal message from a better candidate
 // 	than yourself was received, return.
 //	Else, declare yourself a leader
 
@@ -141,6 +143,9 @@ type ElectionConfig struct {
 	MembershipSampleInterval time.Duration
 	LeaderAliveThreshold     time.Duration
 	LeaderElectionDuration   time.Duration
+	// Strategy ranks the peers as candidates to the leadership,
+	// the peer with the lowest PKI-ID is preferred if nil
+	Strategy Strategy
 }
 
 // NewLeaderElectionService returns a new LeaderElectionService
@@ -162,6 +167,9 @@ func NewLeaderElectionService(adapter LeaderElectionAdapter, id string, callback
 	if callback != nil {
 		le.callback = callback
 	}
+	if le.config.Strategy == nil {
+		le.config.Strategy = &lowestIDStrategy{}
+	}
 
 	go le.start()
 	return le
@@ -228,7 +236,7 @@ func (le *leaderElectionSvcImpl) handleMessage(msg Msg) {
 		if le.sleeping && len(le.interruptChan) == 0 {
 			le.interruptChan <- struct{}{}
 		}
-		if bytes.Compare(msg.SenderID(), le.id) < 0 && le.IsLeader() {
+		if le.prefers(msg.SenderID()) && le.IsLeader() {
 			le.stopBeingLeader()
 		}
 	} else {
@@ -310,7 +318,7 @@ func (le *leaderElectionSvcImpl) leaderElection() {
 	// for being a leader
 	for _, o := range le.proposals.ToArray() {
 		id := o.(string)
-		if bytes.Compare(peerID(id), le.id) < 0 {
+		if le.prefers(peerID(id)) {
 			return
 		}
 	}
@@ -373,6 +381,11 @@ func (le *leaderElectionSvcImpl) drainInterruptChannel() {
 	}
 }
 
+// prefers returns whether the peer of given id is a better leader than this peer
+func (le *leaderElectionSvcImpl) prefers(id peerID) bool {
+	return le.config.Strategy.Prefers(common.PKIidType(id), common.PKIidType(le.id))
+}
+
 // isAlive returns whether peer of given id is considered alive
 func (le *leaderElectionSvcImpl) isAlive(id peerID) bool {
 	for _, p := range le.adapter.Peers() {
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -159,6 +160,10 @@ func createPeers(spawnInterval time.Duration, ids ...int) []*peer {
 }
 
 func createPeerWithCostumeMetrics(id int, peerMap map[string]*peer, l *sync.RWMutex, f func(mock.Arguments)) *peer {
+	return createPeerWithStrategy(id, peerMap, l, f, nil)
+}
+
+func createPeerWithStrategy(id int, peerMap map[string]*peer, l *sync.RWMutex, f func(mock.Arguments), strategy Strategy) *peer {
 	idStr := fmt.Sprintf("p%d", id)
 	c := make(chan Msg, 100)
 	p := &peer{id: idStr, peers: peerMap, sharedLock: l, msgChan: c, mockedMethods: make(map[string]struct{}), leaderFromCallback: false, callbackInvoked: false}
@@ -168,6 +173,7 @@ func createPeerWithCostumeMetrics(id int, peerMap map[string]*peer, l *sync.RWMu
 		MembershipSampleInterval: testMembershipSampleInterval,
 		LeaderAliveThreshold:     testLeaderAliveThreshold,
 		LeaderElectionDuration:   testLeaderElectionDuration,
+		Strategy:                 strategy,
 	}
 	p.LeaderElectionService = NewLeaderElectionService(p, idStr, p.leaderCallback, config)
 	l.Lock()
@@ -253,6 +259,47 @@ func TestInitPeersAtSameTime(t *testing.T) {
 	waitForBoolFunc(t, peers[len(peers)-1].isLeaderFromCallback, true, "Leadership callback result is wrong for ", peers[len(peers)-1].id)
 }
 
+func TestInitPeersAtSameTimeWithStrategy(t *testing.T) {
+	// Scenario: Peers are spawned at the same time, p5 having the highest priority
+	// expected outcome: p5 is the leader
+	resolve := func(pkiID common.PKIidType) []string {
+		return []string{string(pkiID)}
+	}
+	strategy := NewPriorityStrategy(map[string]int{"p5": 10, "p7": 5}, resolve)
+	peerMap := make(map[string]*peer)
+	l := &sync.RWMutex{}
+	var peers []*peer
+	for _, id := range []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0} {
+		peers = append(peers, createPeerWithStrategy(id, peerMap, l, func(mock.Arguments) {}, strategy))
+	}
+	time.Sleep(testStartupGracePeriod + testLeaderElectionDuration)
+	leaders := waitForLeaderElection(t, peers)
+	assert.Equal(t, []string{"p5"}, leaders)
+}
+
+func TestPreferredLeader(t *testing.T) {
+	// Scenario: Peers are spawned at the same time and p0 is elected,
+	// then p2 is set as the preferred leader and p0 yields
+	// expected outcome: p2 is the leader
+	resolve := func(pkiID common.PKIidType) []string {
+		return []string{string(pkiID)}
+	}
+	strategy := NewPreferredLeaderStrategy(&lowestIDStrategy{}, resolve)
+	peerMap := make(map[string]*peer)
+	l := &sync.RWMutex{}
+	var peers []*peer
+	for _, id := range []int{3, 2, 1, 0} {
+		peers = append(peers, createPeerWithStrategy(id, peerMap, l, func(mock.Arguments) {}, strategy))
+	}
+	leaders := waitForLeaderElection(t, peers)
+	assert.Equal(t, []string{"p0"}, leaders)
+
+	strategy.SetPreferredLeader("p2")
+	peers[3].Yield()
+	waitForBoolFunc(t, peers[1].IsLeader, true, "p2 isn't the leader")
+	assert.Equal(t, []string{"p2"}, waitForLeaderElection(t, peers))
+}
+
 func TestInitPeersStartAtIntervals(t *testing.T) {
 	// Scenario: Peers are spawned one by one in a slow rate
 	// expected outcome: the first peer is the leader although its ID is highest
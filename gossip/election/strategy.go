@@ -0,0 +1,189 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package election
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/pkg/errors"
+)
+
+// Names of the leader election strategies
+const (
+	LowestIDStrategy    = "lowestID"
+	PriorityStrategy    = "priority"
+	ChannelHashStrategy = "channelHash"
+)
+
+// Strategy ranks the peers of an organization as candidates to the leadership of a channel.
+// A leader steps down when it learns about a leader it prefers over itself, and a peer only
+// becomes the leader if it didn't hear of a candidate it prefers over itself. Therefore all
+// the peers of an organization must rank the candidates the same way, otherwise they may
+// not agree on a single leader.
+type Strategy interface {
+	// Prefers returns whether the peer with the given PKI-ID is a
+	// better leader than the peer with the other PKI-ID
+	Prefers(pkiID, other common.PKIidType) bool
+}
+
+// EndpointResolver returns the endpoints of the peer with the given
+// PKI-ID, or nil if the peer isn't known
+type EndpointResolver func(pkiID common.PKIidType) []string
+
+// StrategyConfig is the configuration of the leader election strategy
+type StrategyConfig struct {
+	// Name is the name of the strategy, the lowest PKI-ID strategy if empty
+	Name string
+	// Priorities are the priorities of the peers for the priority strategy, by endpoint.
+	// The peers which aren't listed have a priority of 0.
+	Priorities map[string]int
+}
+
+// NewStrategy returns the strategy of the given configuration for the leader election of the given channel.
+// The strategy lets a leader be set at runtime with SetPreferredLeader.
+func NewStrategy(config StrategyConfig, channel common.ChannelID, resolve EndpointResolver) (*PreferredLeaderStrategy, error) {
+	var strategy Strategy
+	switch config.Name {
+	case "", LowestIDStrategy:
+		strategy = &lowestIDStrategy{}
+	case PriorityStrategy:
+		strategy = NewPriorityStrategy(config.Priorities, resolve)
+	case ChannelHashStrategy:
+		strategy = NewChannelHashStrategy(channel)
+	default:
+		return nil, errors.Errorf("unknown leader election strategy %s, expected one of %s, %s, %s",
+			config.Name, LowestIDStrategy, PriorityStrategy, ChannelHashStrategy)
+	}
+	return NewPreferredLeaderStrategy(strategy, resolve), nil
+}
+
+// lowestIDStrategy prefers the peers with the lowest PKI-ID
+type lowestIDStrategy struct{}
+
+func (*lowestIDStrategy) Prefers(pkiID, other common.PKIidType) bool {
+	return bytes.Compare(pkiID, other) < 0
+}
+
+type priorityStrategy struct {
+	priorities map[string]int
+	resolve    EndpointResolver
+}
+
+// NewPriorityStrategy returns a strategy which prefers the peers with the highest priority, the
+// priorities being given by endpoint. The peer with the lowest PKI-ID is preferred among the
+// peers with the same priority.
+func NewPriorityStrategy(priorities map[string]int, resolve EndpointResolver) Strategy {
+	return &priorityStrategy{
+		priorities: priorities,
+		resolve:    resolve,
+	}
+}
+
+func (s *priorityStrategy) Prefers(pkiID, other common.PKIidType) bool {
+	priority, otherPriority := s.priorityOf(pkiID), s.priorityOf(other)
+	if priority != otherPriority {
+		return priority > otherPriority
+	}
+	return bytes.Compare(pkiID, other) < 0
+}
+
+// priorityOf returns the highest priority of the endpoints of the given peer
+func (s *priorityStrategy) priorityOf(pkiID common.PKIidType) int {
+	var priority int
+	found := false
+	for _, endpoint := range s.resolve(pkiID) {
+		if p, exists := s.priorities[endpoint]; exists && (!found || p > priority) {
+			priority = p
+			found = true
+		}
+	}
+	return priority
+}
+
+type channelHashStrategy struct {
+	channel common.ChannelID
+}
+
+// NewChannelHashStrategy returns a strategy which prefers the peers with the lowest hash of their
+// PKI-ID along with the given channel, so that the leadership of the channels of an organization
+// is spread among its peers.
+func NewChannelHashStrategy(channel common.ChannelID) Strategy {
+	return &channelHashStrategy{channel: channel}
+}
+
+func (s *channelHashStrategy) Prefers(pkiID, other common.PKIidType) bool {
+	if c := bytes.Compare(s.hash(pkiID), s.hash(other)); c != 0 {
+		return c < 0
+	}
+	return bytes.Compare(pkiID, other) < 0
+}
+
+func (s *channelHashStrategy) hash(pkiID common.PKIidType) []byte {
+	h := sha256.New()
+	h.Write(s.channel)
+	h.Write(pkiID)
+	return h.Sum(nil)
+}
+
+// PreferredLeaderStrategy prefers the peer set as the preferred leader, if any,
+// and otherwise ranks the peers with the strategy it wraps
+type PreferredLeaderStrategy struct {
+	Strategy
+	resolve EndpointResolver
+
+	lock      sync.RWMutex
+	preferred string
+}
+
+// NewPreferredLeaderStrategy returns a PreferredLeaderStrategy which
+// ranks the peers with the given strategy until a leader is preferred
+func NewPreferredLeaderStrategy(strategy Strategy, resolve EndpointResolver) *PreferredLeaderStrategy {
+	return &PreferredLeaderStrategy{
+		Strategy: strategy,
+		resolve:  resolve,
+	}
+}
+
+// SetPreferredLeader makes the peer with the given endpoint the preferred leader,
+// or restores the ranking of the wrapped strategy if endpoint is empty
+func (s *PreferredLeaderStrategy) SetPreferredLeader(endpoint string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.preferred = endpoint
+}
+
+// PreferredLeader returns the endpoint of the preferred leader, or an empty string if there is none
+func (s *PreferredLeaderStrategy) PreferredLeader() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.preferred
+}
+
+// IsPreferredLeader returns whether the peer with the given PKI-ID is the preferred leader
+func (s *PreferredLeaderStrategy) IsPreferredLeader(pkiID common.PKIidType) bool {
+	preferred := s.PreferredLeader()
+	if preferred == "" {
+		return false
+	}
+	for _, endpoint := range s.resolve(pkiID) {
+		if endpoint == preferred {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PreferredLeaderStrategy) Prefers(pkiID, other common.PKIidType) bool {
+	preferred, otherPreferred := s.IsPreferredLeader(pkiID), s.IsPreferredLeader(other)
+	if preferred != otherPreferred {
+		return preferred
+	}
+	return s.Strategy.Prefers(pkiID, other)
+}
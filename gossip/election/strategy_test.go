@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package election
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func endpointOf(pkiID common.PKIidType) []string {
+	return []string{string(pkiID) + ":7051"}
+}
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range []string{"", LowestIDStrategy, PriorityStrategy, ChannelHashStrategy} {
+		strategy, err := NewStrategy(StrategyConfig{Name: name}, common.ChannelID("ch"), endpointOf)
+		assert.NoError(t, err)
+		assert.NotNil(t, strategy)
+	}
+
+	_, err := NewStrategy(StrategyConfig{Name: "highestID"}, common.ChannelID("ch"), endpointOf)
+	assert.EqualError(t, err, "unknown leader election strategy highestID, expected one of lowestID, priority, channelHash")
+}
+
+func TestLowestIDStrategy(t *testing.T) {
+	strategy, err := NewStrategy(StrategyConfig{Name: LowestIDStrategy}, common.ChannelID("ch"), endpointOf)
+	assert.NoError(t, err)
+	assert.True(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p1")))
+	assert.False(t, strategy.Prefers(common.PKIidType("p1"), common.PKIidType("p0")))
+	assert.False(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p0")))
+}
+
+func TestPriorityStrategy(t *testing.T) {
+	strategy := NewPriorityStrategy(map[string]int{
+		"p2:7051": 10,
+		"p3:7051": 10,
+		"p4:7051": -1,
+	}, endpointOf)
+
+	// The highest priority wins
+	assert.True(t, strategy.Prefers(common.PKIidType("p2"), common.PKIidType("p0")))
+	assert.False(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p2")))
+	// The lowest PKI-ID wins among the peers with the same priority
+	assert.True(t, strategy.Prefers(common.PKIidType("p2"), common.PKIidType("p3")))
+	assert.True(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p1")))
+	// Unlisted peers have a priority of 0
+	assert.True(t, strategy.Prefers(common.PKIidType("p1"), common.PKIidType("p4")))
+	// Unknown peers have a priority of 0
+	assert.True(t, strategy.Prefers(common.PKIidType("p2"), common.PKIidType("unknown")))
+}
+
+func TestPriorityStrategyInternalEndpoint(t *testing.T) {
+	resolve := func(pkiID common.PKIidType) []string {
+		return []string{string(pkiID) + ":7051", string(pkiID) + ".internal:7051"}
+	}
+	strategy := NewPriorityStrategy(map[string]int{"p1.internal:7051": 1}, resolve)
+	assert.True(t, strategy.Prefers(common.PKIidType("p1"), common.PKIidType("p0")))
+}
+
+func TestChannelHashStrategy(t *testing.T) {
+	peers := []common.PKIidType{
+		common.PKIidType("p0"),
+		common.PKIidType("p1"),
+		common.PKIidType("p2"),
+		common.PKIidType("p3"),
+	}
+
+	leaders := map[string]int{}
+	for i := 0; i < 100; i++ {
+		strategy := NewChannelHashStrategy(common.ChannelID(fmt.Sprintf("ch%d", i)))
+		leader := peers[0]
+		for _, p := range peers[1:] {
+			if strategy.Prefers(p, leader) {
+				leader = p
+			}
+		}
+		for _, p := range peers {
+			if !p.IsNotSameFilter(leader) {
+				continue
+			}
+			assert.False(t, strategy.Prefers(p, leader))
+			assert.True(t, strategy.Prefers(leader, p))
+		}
+		leaders[string(leader)]++
+	}
+
+	// Each peer leads some of the channels
+	assert.Len(t, leaders, len(peers))
+}
+
+func TestPreferredLeaderStrategy(t *testing.T) {
+	strategy, err := NewStrategy(StrategyConfig{Name: LowestIDStrategy}, common.ChannelID("ch"), endpointOf)
+	assert.NoError(t, err)
+	assert.Empty(t, strategy.PreferredLeader())
+	assert.False(t, strategy.IsPreferredLeader(common.PKIidType("p2")))
+
+	strategy.SetPreferredLeader("p2:7051")
+	assert.Equal(t, "p2:7051", strategy.PreferredLeader())
+	assert.True(t, strategy.IsPreferredLeader(common.PKIidType("p2")))
+	assert.True(t, strategy.Prefers(common.PKIidType("p2"), common.PKIidType("p0")))
+	assert.False(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p2")))
+	// The wrapped strategy ranks the peers which aren't preferred
+	assert.True(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p1")))
+
+	// Clearing the preferred leader restores the wrapped strategy
+	strategy.SetPreferredLeader("")
+	assert.Empty(t, strategy.PreferredLeader())
+	assert.True(t, strategy.Prefers(common.PKIidType("p0"), common.PKIidType("p2")))
+}
@@ -81,6 +81,12 @@ type Config struct {
 	RecvBuffSize int
 	// SendBuffSize is the buffer size of sending message.
 	SendBuffSize int
+	// CompressionEnabled determines whether messages are compressed when sent to peers that support it.
+	CompressionEnabled bool
+	// CompressionThreshold is the minimum size in bytes of the messages that are compressed.
+	CompressionThreshold int
+	// MaxBatchSize is the maximum number of alive and state info messages sent in a single frame.
+	MaxBatchSize int
 
 	// MsgExpirationTimeout indicate leadership message expiration timeout.
 	MsgExpirationTimeout time.Duration
@@ -141,6 +147,9 @@ func (c *Config) loadConfig(endpoint string, certs *common.TLSCertificates, boot
 	c.ConnTimeout = util.GetDurationOrDefault("peer.gossip.connTimeout", comm.DefConnTimeout)
 	c.RecvBuffSize = util.GetIntOrDefault("peer.gossip.recvBuffSize", comm.DefRecvBuffSize)
 	c.SendBuffSize = util.GetIntOrDefault("peer.gossip.sendBuffSize", comm.DefSendBuffSize)
+	c.CompressionEnabled = viper.GetBool("peer.gossip.compression.enabled")
+	c.CompressionThreshold = util.GetIntOrDefault("peer.gossip.compression.threshold", comm.DefCompressionThreshold)
+	c.MaxBatchSize = util.GetIntOrDefault("peer.gossip.maxBatchSize", comm.DefMaxBatchSize)
 	c.MsgExpirationTimeout = util.GetDurationOrDefault("peer.gossip.election.leaderAliveThreshold", election.DefLeaderAliveThreshold) * 10
 	c.AliveTimeInterval = util.GetDurationOrDefault("peer.gossip.aliveTimeInterval", discovery.DefAliveTimeInterval)
 	c.AliveExpirationTimeout = util.GetDurationOrDefault("peer.gossip.aliveExpirationTimeout", 5*c.AliveTimeInterval)
@@ -101,10 +101,13 @@ func New(conf *Config, s *grpc.Server, sa api.SecurityAdvisor,
 	}, sa)
 
 	commConfig := comm.CommConfig{
-		DialTimeout:  conf.DialTimeout,
-		ConnTimeout:  conf.ConnTimeout,
-		RecvBuffSize: conf.RecvBuffSize,
-		SendBuffSize: conf.SendBuffSize,
+		DialTimeout:          conf.DialTimeout,
+		ConnTimeout:          conf.ConnTimeout,
+		RecvBuffSize:         conf.RecvBuffSize,
+		SendBuffSize:         conf.SendBuffSize,
+		CompressionEnabled:   conf.CompressionEnabled,
+		CompressionThreshold: conf.CompressionThreshold,
+		MaxBatchSize:         conf.MaxBatchSize,
 	}
 	g.comm, err = comm.NewCommInstance(s, conf.TLSCerts, g.idMapper, selfIdentity, secureDialOpts, sa,
 		gossipMetrics.CommMetrics, commConfig)
@@ -711,6 +714,22 @@ func (g *Node) Peers() []discovery.NetworkMember {
 	return g.disc.GetMembership()
 }
 
+// KnownMembers returns the alive and the dead members known to the peer,
+// along with the time they were last seen alive
+func (g *Node) KnownMembers() []discovery.MemberState {
+	return g.disc.KnownMembers()
+}
+
+// Connections returns the information of the connections to remote peers
+func (g *Node) Connections() []*protoext.ConnectionInfo {
+	return g.comm.Connections()
+}
+
+// Probe returns nil if the peer at the given endpoint is responsive, and an error if it's not
+func (g *Node) Probe(endpoint string) error {
+	return g.comm.Probe(&comm.RemotePeer{Endpoint: endpoint})
+}
+
 // PeersOfChannel returns the NetworkMembers considered alive
 // and also subscribed to the channel given
 func (g *Node) PeersOfChannel(channel common.ChannelID) []discovery.NetworkMember {
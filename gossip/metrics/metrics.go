@@ -95,16 +95,20 @@ var (
 
 // CommMetrics encapsulates gossip communication related metrics
 type CommMetrics struct {
-	SentMessages     metrics.Counter
-	BufferOverflow   metrics.Counter
-	ReceivedMessages metrics.Counter
+	SentMessages          metrics.Counter
+	BufferOverflow        metrics.Counter
+	ReceivedMessages      metrics.Counter
+	BatchedMessages       metrics.Counter
+	CompressionBytesSaved metrics.Counter
 }
 
 func newCommMetrics(p metrics.Provider) *CommMetrics {
 	return &CommMetrics{
-		SentMessages:     p.NewCounter(SentMessagesOpts),
-		BufferOverflow:   p.NewCounter(BufferOverflowOpts),
-		ReceivedMessages: p.NewCounter(ReceivedMessagesOpts),
+		SentMessages:          p.NewCounter(SentMessagesOpts),
+		BufferOverflow:        p.NewCounter(BufferOverflowOpts),
+		ReceivedMessages:      p.NewCounter(ReceivedMessagesOpts),
+		BatchedMessages:       p.NewCounter(BatchedMessagesOpts),
+		CompressionBytesSaved: p.NewCounter(CompressionBytesSavedOpts),
 	}
 }
 
@@ -132,6 +136,22 @@ var (
 		Help:         "Number of messages received",
 		StatsdFormat: "%{#fqname}",
 	}
+
+	BatchedMessagesOpts = metrics.CounterOpts{
+		Namespace:    "gossip",
+		Subsystem:    "comm",
+		Name:         "messages_batched",
+		Help:         "Number of messages sent in batches",
+		StatsdFormat: "%{#fqname}",
+	}
+
+	CompressionBytesSavedOpts = metrics.CounterOpts{
+		Namespace:    "gossip",
+		Subsystem:    "comm",
+		Name:         "compression_bytes_saved",
+		Help:         "Number of bytes saved by compressing the messages sent",
+		StatsdFormat: "%{#fqname}",
+	}
 )
 
 // MembershipMetrics encapsulates gossip channel membership related metrics
@@ -24,6 +24,8 @@ type TestMetricProvider struct {
 	FakeSentMessages     *metricsfakes.Counter
 	FakeBufferOverflow   *metricsfakes.Counter
 	FakeReceivedMessages *metricsfakes.Counter
+	FakeBatchedMessages  *metricsfakes.Counter
+	FakeBytesSaved       *metricsfakes.Counter
 
 	FakeTotalGauge *metricsfakes.Gauge
 
@@ -50,6 +52,8 @@ func TestUtilConstructMetricProvider() *TestMetricProvider {
 	fakeSentMessages := testUtilConstructCounter()
 	fakeBufferOverflow := testUtilConstructCounter()
 	fakeReceivedMessages := testUtilConstructCounter()
+	fakeBatchedMessages := testUtilConstructCounter()
+	fakeBytesSaved := testUtilConstructCounter()
 
 	fakeTotalGauge := testUtilConstructGauge()
 
@@ -71,6 +75,10 @@ func TestUtilConstructMetricProvider() *TestMetricProvider {
 			return fakeSentMessages
 		case gmetrics.ReceivedMessagesOpts.Name:
 			return fakeReceivedMessages
+		case gmetrics.BatchedMessagesOpts.Name:
+			return fakeBatchedMessages
+		case gmetrics.CompressionBytesSavedOpts.Name:
+			return fakeBytesSaved
 		}
 		return nil
 	}
@@ -122,6 +130,8 @@ func TestUtilConstructMetricProvider() *TestMetricProvider {
 		fakeSentMessages,
 		fakeBufferOverflow,
 		fakeReceivedMessages,
+		fakeBatchedMessages,
+		fakeBytesSaved,
 		fakeTotalGauge,
 		fakeValidationDuration,
 		fakeListMissingPrivateDataDuration,
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/discovery"
+	privdatacommon "github.com/hyperledger/fabric/gossip/privdata/common"
+)
+
+// peerBackoff keeps track of the peers which did not respond to the requests
+// for missing private data sent by the reconciler. Such a peer is not asked
+// again until its backoff elapses; the backoff doubles with each consecutive
+// request the peer does not respond to, up to a maximum.
+type peerBackoff struct {
+	initial time.Duration
+	max     time.Duration
+	now     func() time.Time
+
+	lock  sync.Mutex
+	peers map[string]*backedOffPeer
+}
+
+type backedOffPeer struct {
+	endpoint string
+	failures int
+	until    time.Time
+}
+
+func newPeerBackoff(initial, max time.Duration) *peerBackoff {
+	return &peerBackoff{
+		initial: initial,
+		max:     max,
+		now:     time.Now,
+		peers:   map[string]*backedOffPeer{},
+	}
+}
+
+// available returns the members which are not backed off
+func (b *peerBackoff) available(members []discovery.NetworkMember) []discovery.NetworkMember {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	var res []discovery.NetworkMember
+	for _, member := range members {
+		if p, exists := b.peers[string(member.PKIid)]; exists && now.Before(p.until) {
+			continue
+		}
+		res = append(res, member)
+	}
+	return res
+}
+
+// failed backs the peer off as it did not respond to a request
+func (b *peerBackoff) failed(peer remotePeer) {
+	if b.initial <= 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	p, exists := b.peers[peer.pkiID]
+	if !exists {
+		p = &backedOffPeer{}
+		b.peers[peer.pkiID] = p
+	}
+	p.endpoint = peer.endpoint
+	backoff := b.initial
+	for i := 0; i < p.failures && backoff < b.max; i++ {
+		backoff *= 2
+	}
+	if backoff > b.max {
+		backoff = b.max
+	}
+	p.failures++
+	p.until = b.now().Add(backoff)
+	logger.Warningf("Peer %s did not respond to a request for missing private data, not requesting it for %s", peer.endpoint, backoff)
+}
+
+// succeeded resets the backoff of the peer as it responded to a request
+func (b *peerBackoff) succeeded(peer remotePeer) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.peers, peer.pkiID)
+}
+
+// backedOffPeers returns the peers which are currently backed off, ordered by endpoint
+func (b *peerBackoff) backedOffPeers() []privdatacommon.BackedOffPeer {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	var res []privdatacommon.BackedOffPeer
+	for _, p := range b.peers {
+		if !now.Before(p.until) {
+			continue
+		}
+		res = append(res, privdatacommon.BackedOffPeer{
+			Endpoint: p.endpoint,
+			Failures: p.failures,
+			Until:    p.until,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Endpoint < res[j].Endpoint
+	})
+	return res
+}
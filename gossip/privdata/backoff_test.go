@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"testing"
+	"time"
+
+	proto "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	privdatacommon "github.com/hyperledger/fabric/gossip/privdata/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerBackoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newPeerBackoff(time.Minute, 3*time.Minute)
+	b.now = func() time.Time { return now }
+
+	p1 := remotePeer{pkiID: "p1", endpoint: "p1:7051"}
+	p2 := remotePeer{pkiID: "p2", endpoint: "p2:7051"}
+	members := []discovery.NetworkMember{
+		{PKIid: common.PKIidType("p1"), Endpoint: "p1:7051"},
+		{PKIid: common.PKIidType("p2"), Endpoint: "p2:7051"},
+	}
+
+	b.failed(p1)
+	require.Equal(t, members[1:], b.available(members))
+	require.Equal(t, []privdatacommon.BackedOffPeer{
+		{Endpoint: "p1:7051", Failures: 1, Until: now.Add(time.Minute)},
+	}, b.backedOffPeers())
+
+	// the backoff doubles with each consecutive failure up to the maximum
+	now = now.Add(time.Minute)
+	require.Equal(t, members, b.available(members))
+	require.Empty(t, b.backedOffPeers())
+	b.failed(p1)
+	require.Equal(t, now.Add(2*time.Minute), b.backedOffPeers()[0].Until)
+	b.failed(p1)
+	require.Equal(t, now.Add(3*time.Minute), b.backedOffPeers()[0].Until)
+
+	b.failed(p2)
+	require.Empty(t, b.available(members))
+	require.Len(t, b.backedOffPeers(), 2)
+
+	// a response resets the backoff
+	b.succeeded(p1)
+	require.Equal(t, members[:1], b.available(members))
+	b.failed(p1)
+	require.Equal(t, 1, b.backedOffPeers()[0].Failures)
+
+	// a zero backoff disables it
+	b = newPeerBackoff(0, 0)
+	b.failed(p1)
+	require.Equal(t, members, b.available(members))
+}
+
+func TestUpdateBackoff(t *testing.T) {
+	b := newPeerBackoff(time.Minute, time.Hour)
+	p1 := remotePeer{pkiID: "p1", endpoint: "p1"}
+	p2 := remotePeer{pkiID: "p2", endpoint: "p2"}
+	b.failed(p1)
+
+	dig1 := proto.PvtDataDigest{TxId: "tx1", Namespace: "ns1", Collection: "col1", BlockSeq: 1}
+	dig2 := proto.PvtDataDigest{TxId: "tx2", Namespace: "ns1", Collection: "col1", BlockSeq: 2}
+	dig3 := proto.PvtDataDigest{TxId: "tx3", Namespace: "ns1", Collection: "col1", BlockSeq: 3}
+	updateBackoff(b,
+		peer2Digests{
+			p1: {dig1, dig2},
+			p2: {dig3},
+		},
+		// an empty response still shows that the peer is available
+		[]*proto.PvtDataElement{{Digest: &dig2}},
+	)
+
+	backedOffPeers := b.backedOffPeers()
+	require.Len(t, backedOffPeers, 1)
+	require.Equal(t, "p2", backedOffPeers[0].Endpoint)
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package common
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-protos-go/gossip"
 	"github.com/hyperledger/fabric-protos-go/peer"
 )
@@ -32,3 +34,12 @@ type FetchedPvtDataContainer struct {
 	AvailableElements []*gossip.PvtDataElement
 	PurgedElements    []*gossip.PvtDataDigest
 }
+
+// BackedOffPeer is a peer from which missing private data
+// is not requested until the given time as it did not
+// respond to the last requests
+type BackedOffPeer struct {
+	Endpoint string    `json:"endpoint"`
+	Failures int       `json:"failures"`
+	Until    time.Time `json:"until"`
+}
@@ -0,0 +1,36 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/pkg/errors"
+)
+
+// ChaincodeMetadata is published by a peer, JSON encoded, in the metadata of the chaincodes
+// of its StateInfo message. It lets the other peers and the clients of the discovery service
+// know which collections of the chaincode the peer is missing private data of.
+type ChaincodeMetadata struct {
+	// MissingPvtData maps the collections of the chaincode for which private data is
+	// missing on the peer to the lowest block number of the missing private data
+	MissingPvtData map[string]uint64 `json:"missing_pvtdata,omitempty"`
+}
+
+// ChaincodeMetadataOf returns the metadata published by a peer for the chaincode,
+// which is empty if the peer did not publish metadata
+func ChaincodeMetadataOf(cc *gossip.Chaincode) (*ChaincodeMetadata, error) {
+	md := &ChaincodeMetadata{}
+	if len(cc.Metadata) == 0 {
+		return md, nil
+	}
+	if err := json.Unmarshal(cc.Metadata, md); err != nil {
+		return nil, errors.Wrapf(err, "failed unmarshaling metadata of chaincode %s", cc.Name)
+	}
+	return md, nil
+}
@@ -16,6 +16,8 @@ import (
 const (
 	reconcileSleepIntervalDefault         = time.Minute
 	reconcileBatchSizeDefault             = 10
+	reconcilePeerBackoffDefault           = time.Minute
+	reconcileMaxPeerBackoffDefault        = 30 * time.Minute
 	implicitCollectionMaxPeerCountDefault = 1
 )
 
@@ -28,10 +30,27 @@ type PrivdataConfig struct {
 	ReconcileBatchSize int
 	// ReconciliationEnabled is a flag that indicates whether private data reconciliation is enabled or not.
 	ReconciliationEnabled bool
+	// ReconciliationPriorities determines the order in which the missing private data of the chaincodes and
+	// collections is reconciled. The batches of the missing private data of a higher priority are reconciled first.
+	ReconciliationPriorities []ReconciliationPriority
+	// ReconcilePeerBackoff determines the time during which the reconciler stops requesting missing private
+	// data from a peer that did not respond. It doubles with each consecutive failure up to ReconcileMaxPeerBackoff.
+	ReconcilePeerBackoff time.Duration
+	// ReconcileMaxPeerBackoff determines the maximum time during which the reconciler stops requesting missing
+	// private data from a peer that did not respond.
+	ReconcileMaxPeerBackoff time.Duration
 	// ImplicitCollectionDisseminationPolicy specifies the dissemination  policy for the peer's own implicit collection.
 	ImplicitCollDisseminationPolicy ImplicitCollectionDisseminationPolicy
 }
 
+// ReconciliationPriority assigns a priority to the reconciliation of the missing private data of a chaincode.
+// If Collection is empty, the priority applies to the collections of the chaincode which have no priority of their own.
+type ReconciliationPriority struct {
+	Chaincode  string
+	Collection string
+	Priority   int
+}
+
 // ImplicitCollectionDisseminationPolicy specifies the dissemination  policy for the peer's own implicit collection.
 // It is not applicable to private data for other organizations' implicit collections.
 type ImplicitCollectionDisseminationPolicy struct {
@@ -65,6 +84,23 @@ func (c *PrivdataConfig) loadPrivDataConfig() {
 
 	c.ReconciliationEnabled = viper.GetBool("peer.gossip.pvtData.reconciliationEnabled")
 
+	if err := viper.UnmarshalKey("peer.gossip.pvtData.reconciliationPriorities", &c.ReconciliationPriorities); err != nil {
+		logger.Warningf("Ignoring invalid configuration key peer.gossip.pvtData.reconciliationPriorities: %s", err)
+		c.ReconciliationPriorities = nil
+	}
+
+	c.ReconcilePeerBackoff = viper.GetDuration("peer.gossip.pvtData.reconcilePeerBackoff")
+	if c.ReconcilePeerBackoff == 0 {
+		c.ReconcilePeerBackoff = reconcilePeerBackoffDefault
+	}
+	c.ReconcileMaxPeerBackoff = viper.GetDuration("peer.gossip.pvtData.reconcileMaxPeerBackoff")
+	if c.ReconcileMaxPeerBackoff == 0 {
+		c.ReconcileMaxPeerBackoff = reconcileMaxPeerBackoffDefault
+	}
+	if c.ReconcileMaxPeerBackoff < c.ReconcilePeerBackoff {
+		c.ReconcileMaxPeerBackoff = c.ReconcilePeerBackoff
+	}
+
 	requiredPeerCount := viper.GetInt("peer.gossip.pvtData.implicitCollectionDisseminationPolicy.requiredPeerCount")
 
 	maxPeerCount := implicitCollectionMaxPeerCountDefault
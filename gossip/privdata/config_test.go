@@ -21,6 +21,12 @@ func TestGlobalConfig(t *testing.T) {
 	viper.Set("peer.gossip.pvtData.reconcileSleepInterval", "10s")
 	viper.Set("peer.gossip.pvtData.reconcileBatchSize", 10)
 	viper.Set("peer.gossip.pvtData.reconciliationEnabled", true)
+	viper.Set("peer.gossip.pvtData.reconciliationPriorities", []map[string]interface{}{
+		{"chaincode": "cc1", "priority": 10},
+		{"chaincode": "cc1", "collection": "coll1", "priority": -1},
+	})
+	viper.Set("peer.gossip.pvtData.reconcilePeerBackoff", "10s")
+	viper.Set("peer.gossip.pvtData.reconcileMaxPeerBackoff", "5s")
 	viper.Set("peer.gossip.pvtData.implicitCollectionDisseminationPolicy.requiredPeerCount", 2)
 	viper.Set("peer.gossip.pvtData.implicitCollectionDisseminationPolicy.maxPeerCount", 3)
 
@@ -30,6 +36,12 @@ func TestGlobalConfig(t *testing.T) {
 		ReconcileSleepInterval: 10 * time.Second,
 		ReconcileBatchSize:     10,
 		ReconciliationEnabled:  true,
+		ReconciliationPriorities: []privdata.ReconciliationPriority{
+			{Chaincode: "cc1", Priority: 10},
+			{Chaincode: "cc1", Collection: "coll1", Priority: -1},
+		},
+		ReconcilePeerBackoff:    10 * time.Second,
+		ReconcileMaxPeerBackoff: 10 * time.Second,
 		ImplicitCollDisseminationPolicy: privdata.ImplicitCollectionDisseminationPolicy{
 			RequiredPeerCount: 2,
 			MaxPeerCount:      3,
@@ -45,9 +57,11 @@ func TestGlobalConfigDefaults(t *testing.T) {
 	coreConfig := privdata.GlobalConfig()
 
 	expectedConfig := &privdata.PrivdataConfig{
-		ReconcileSleepInterval: time.Minute,
-		ReconcileBatchSize:     10,
-		ReconciliationEnabled:  false,
+		ReconcileSleepInterval:  time.Minute,
+		ReconcileBatchSize:      10,
+		ReconciliationEnabled:   false,
+		ReconcilePeerBackoff:    time.Minute,
+		ReconcileMaxPeerBackoff: 30 * time.Minute,
 		ImplicitCollDisseminationPolicy: privdata.ImplicitCollectionDisseminationPolicy{
 			RequiredPeerCount: 0,
 			MaxPeerCount:      1,
@@ -216,7 +216,7 @@ func (c *coordinator) StoreBlock(block *common.Block, privateDataSets util.PvtDa
 		fetcher:                                 c.Fetcher,
 		idDeserializerFactory:                   c.idDeserializerFactory,
 	}
-	pvtdataToRetrieve, err := c.getTxPvtdataInfoFromBlock(block)
+	pvtdataToRetrieve, pvtdataPurges, err := c.getTxPvtdataInfoFromBlock(block)
 	if err != nil {
 		logger.Warningf("Failed to get private data info from block: %s", err)
 		return nil, err
@@ -243,6 +243,7 @@ func (c *coordinator) StoreBlock(block *common.Block, privateDataSets util.PvtDa
 
 	// Purge transactions
 	go retrievedPvtdata.Purge()
+	go c.purgePvtdataKeysFromTransientStore(block, pvtdataPurges)
 
 	return blockAndPvtData, nil
 }
@@ -300,18 +301,20 @@ func (c *coordinator) GetPvtDataAndBlockByNum(seqNum uint64, peerAuthInfo protou
 	return blockAndPvtData.Block, seqs2Namespaces.asPrivateData(), nil
 }
 
-// getTxPvtdataInfoFromBlock parses the block transactions and returns the list of private data items in the block.
+// getTxPvtdataInfoFromBlock parses the block transactions and returns the list of private data items in the block
+// along with the private data keys purged by the transactions marked as valid.
 // Note that this peer's eligibility for the private data is not checked here.
-func (c *coordinator) getTxPvtdataInfoFromBlock(block *common.Block) ([]*ledger.TxPvtdataInfo, error) {
+func (c *coordinator) getTxPvtdataInfoFromBlock(block *common.Block) ([]*ledger.TxPvtdataInfo, []*ledger.PvtdataPurge, error) {
 	txPvtdataItemsFromBlock := []*ledger.TxPvtdataInfo{}
+	var pvtdataPurges []*ledger.PvtdataPurge
 
 	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
-		return nil, errors.New("Block.Metadata is nil or Block.Metadata lacks a Tx filter bitmap")
+		return nil, nil, errors.New("Block.Metadata is nil or Block.Metadata lacks a Tx filter bitmap")
 	}
 	txsFilter := txValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
 	data := block.Data.Data
 	if len(txsFilter) != len(block.Data.Data) {
-		return nil, errors.Errorf("block data size(%d) is different from Tx filter size(%d)", len(block.Data.Data), len(txsFilter))
+		return nil, nil, errors.Errorf("block data size(%d) is different from Tx filter size(%d)", len(block.Data.Data), len(txsFilter))
 	}
 
 	for seqInBlock, txEnvBytes := range data {
@@ -320,6 +323,9 @@ func (c *coordinator) getTxPvtdataInfoFromBlock(block *common.Block) ([]*ledger.
 		if err != nil {
 			continue
 		}
+		if !invalid {
+			pvtdataPurges = append(pvtdataPurges, txInfo.txRWSet.PvtdataPurges(block.Header.Number, uint64(seqInBlock))...)
+		}
 
 		colPvtdataInfo := []*ledger.CollectionPvtdataInfo{}
 		for _, ns := range txInfo.txRWSet.NsRwSets {
@@ -337,7 +343,7 @@ func (c *coordinator) getTxPvtdataInfoFromBlock(block *common.Block) ([]*ledger.
 				colConfig, err := c.CollectionStore.RetrieveCollectionConfig(cc)
 				if err != nil {
 					logger.Warningf("Failed to retrieve collection config for collection criteria [%#v]: %s", cc, err)
-					return nil, err
+					return nil, nil, err
 				}
 				col := &ledger.CollectionPvtdataInfo{
 					Namespace:        ns.NameSpace,
@@ -358,7 +364,25 @@ func (c *coordinator) getTxPvtdataInfoFromBlock(block *common.Block) ([]*ledger.
 		txPvtdataItemsFromBlock = append(txPvtdataItemsFromBlock, txPvtdataToRetrieve)
 	}
 
-	return txPvtdataItemsFromBlock, nil
+	return txPvtdataItemsFromBlock, pvtdataPurges, nil
+}
+
+// purgePvtdataKeysFromTransientStore removes from the transient store the private data keys
+// purged by the transactions which remained valid once the block got committed
+func (c *coordinator) purgePvtdataKeysFromTransientStore(block *common.Block, pvtdataPurges []*ledger.PvtdataPurge) {
+	if len(pvtdataPurges) == 0 {
+		return
+	}
+	txsFilter := txValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	var validPurges []*ledger.PvtdataPurge
+	for _, purge := range pvtdataPurges {
+		if txsFilter[purge.TxNum] == uint8(peer.TxValidationCode_VALID) {
+			validPurges = append(validPurges, purge)
+		}
+	}
+	if err := c.store.PurgePvtdataKeys(validPurges); err != nil {
+		logger.Errorf("Failed purging private data keys from the transient store for block [%d]: %s", block.Header.Number, err)
+	}
 }
 
 func (c *coordinator) reportValidationDuration(time time.Duration) {
@@ -32,3 +32,49 @@ func (_m *MissingPvtDataTracker) GetMissingPvtDataInfoForMostRecentBlocks(maxBlo
 
 	return r0, r1
 }
+
+// GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks provides a mock function with given fields: maxBlocks, filter
+func (_m *MissingPvtDataTracker) GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(maxBlocks int, filter ledger.MissingPvtDataFilter) (ledger.MissingPvtDataInfo, error) {
+	ret := _m.Called(maxBlocks, filter)
+
+	var r0 ledger.MissingPvtDataInfo
+	if rf, ok := ret.Get(0).(func(int, ledger.MissingPvtDataFilter) ledger.MissingPvtDataInfo); ok {
+		r0 = rf(maxBlocks, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ledger.MissingPvtDataInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, ledger.MissingPvtDataFilter) error); ok {
+		r1 = rf(maxBlocks, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMissingPvtDataSummary provides a mock function with given fields:
+func (_m *MissingPvtDataTracker) GetMissingPvtDataSummary() ([]*ledger.CollMissingPvtDataSummary, error) {
+	ret := _m.Called()
+
+	var r0 []*ledger.CollMissingPvtDataSummary
+	if rf, ok := ret.Get(0).(func() []*ledger.CollMissingPvtDataSummary); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ledger.CollMissingPvtDataSummary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
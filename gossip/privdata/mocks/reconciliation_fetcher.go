@@ -10,6 +10,22 @@ type ReconciliationFetcher struct {
 	mock.Mock
 }
 
+// BackedOffPeers provides a mock function with given fields:
+func (_m *ReconciliationFetcher) BackedOffPeers() []common.BackedOffPeer {
+	ret := _m.Called()
+
+	var r0 []common.BackedOffPeer
+	if rf, ok := ret.Get(0).(func() []common.BackedOffPeer); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]common.BackedOffPeer)
+		}
+	}
+
+	return r0
+}
+
 // FetchReconciledItems provides a mock function with given fields: dig2collectionConfig
 func (_m *ReconciliationFetcher) FetchReconciledItems(dig2collectionConfig common.Dig2CollectionConfig) (*common.FetchedPvtDataContainer, error) {
 	ret := _m.Called(dig2collectionConfig)
@@ -15,9 +15,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	protosgossip "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	commonutil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
@@ -68,6 +72,14 @@ type gossip interface {
 	Accept(acceptor common.MessageAcceptor, passThrough bool) (<-chan *protosgossip.GossipMessage, <-chan protoext.ReceivedMessage)
 }
 
+// AccessLog records the private data sent to other peers
+type AccessLog interface {
+	// Enabled returns true if the accesses to the collection are recorded
+	Enabled(namespace, collection string) bool
+	// Record records the accesses in the access log of the channel
+	Record(channelID string, entries ...*pvtdataaccesslog.Entry) error
+}
+
 type puller struct {
 	metrics       *metrics.PrivdataMetrics
 	pubSub        *util.PubSub
@@ -76,6 +88,8 @@ type puller struct {
 	channel       string
 	cs            privdata.CollectionStore
 	btlPullMargin uint64
+	backoff       *peerBackoff
+	accessLog     AccessLog
 	gossip
 	PrivateDataRetriever
 	CollectionAccessFactory
@@ -83,7 +97,8 @@ type puller struct {
 
 // NewPuller creates new private data puller
 func NewPuller(metrics *metrics.PrivdataMetrics, cs privdata.CollectionStore, g gossip,
-	dataRetriever PrivateDataRetriever, factory CollectionAccessFactory, channel string, btlPullMargin uint64) *puller {
+	dataRetriever PrivateDataRetriever, factory CollectionAccessFactory, channel string, btlPullMargin uint64,
+	config *PrivdataConfig, accessLog AccessLog) *puller {
 	p := &puller{
 		metrics:                 metrics,
 		pubSub:                  util.NewPubSub(),
@@ -91,6 +106,8 @@ func NewPuller(metrics *metrics.PrivdataMetrics, cs privdata.CollectionStore, g
 		channel:                 channel,
 		cs:                      cs,
 		btlPullMargin:           btlPullMargin,
+		backoff:                 newPeerBackoff(config.ReconcilePeerBackoff, config.ReconcileMaxPeerBackoff),
+		accessLog:               accessLog,
 		gossip:                  g,
 		PrivateDataRetriever:    dataRetriever,
 		CollectionAccessFactory: factory,
@@ -145,6 +162,7 @@ func (p *puller) createResponse(message protoext.ReceivedMessage) []*protosgossi
 	authInfo := message.GetConnectionInfo().Auth
 	var returned []*protosgossip.PvtDataElement
 	connectionEndpoint := message.GetConnectionInfo().Endpoint
+	requester := remotePeer{pkiID: string(message.GetConnectionInfo().ID), endpoint: connectionEndpoint}
 
 	defer func() {
 		logger.Debug("Returning", connectionEndpoint, len(returned), "elements")
@@ -166,7 +184,7 @@ func (p *puller) createResponse(message protoext.ReceivedMessage) []*protosgossi
 			Identity:  message.GetConnectionInfo().Identity,
 			Data:      authInfo.SignedData,
 			Signature: authInfo.Signature,
-		}, connectionEndpoint)...)
+		}, requester)...)
 	}
 	return returned
 }
@@ -227,7 +245,7 @@ func (p *puller) fetch(dig2src dig2sources) (*privdatacommon.FetchedPvtDataConta
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return p.fetchPrivateData(dig2Filter)
+	return p.fetchPrivateData(dig2Filter, nil)
 }
 
 func (p *puller) FetchReconciledItems(dig2collectionConfig privdatacommon.Dig2CollectionConfig) (*privdatacommon.FetchedPvtDataContainer, error) {
@@ -236,10 +254,16 @@ func (p *puller) FetchReconciledItems(dig2collectionConfig privdatacommon.Dig2Co
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return p.fetchPrivateData(dig2Filter)
+	return p.fetchPrivateData(dig2Filter, p.backoff)
+}
+
+// BackedOffPeers returns the peers from which missing private data
+// is currently not reconciled as they did not respond to requests
+func (p *puller) BackedOffPeers() []privdatacommon.BackedOffPeer {
+	return p.backoff.backedOffPeers()
 }
 
-func (p *puller) fetchPrivateData(dig2Filter digestToFilterMapping) (*privdatacommon.FetchedPvtDataContainer, error) {
+func (p *puller) fetchPrivateData(dig2Filter digestToFilterMapping, backoff *peerBackoff) (*privdatacommon.FetchedPvtDataContainer, error) {
 	// Get a list of peers per channel
 	allFilters := dig2Filter.flattenFilterValues()
 	members := p.waitForMembership()
@@ -250,6 +274,13 @@ func (p *puller) fetchPrivateData(dig2Filter digestToFilterMapping) (*privdataco
 		logger.Warning("Do not know any peer in the channel(", p.channel, ") that matches the policies , aborting")
 		return nil, errors.New("Empty membership")
 	}
+	if backoff != nil {
+		members = backoff.available(members)
+		if len(members) == 0 {
+			logger.Warning("All the peers in the channel(", p.channel, ") that match the policies did not respond to recent requests, aborting")
+			return &privdatacommon.FetchedPvtDataContainer{}, nil
+		}
+	}
 	members = randomizeMemberList(members)
 	res := &privdatacommon.FetchedPvtDataContainer{}
 	// Distribute requests to peers, and obtain subscriptions for all their messages
@@ -290,6 +321,9 @@ func (p *puller) fetchPrivateData(dig2Filter digestToFilterMapping) (*privdataco
 		logger.Debug("Matched", len(dig2Filter), "digests to", len(peer2digests), "peer(s)")
 		subscriptions := p.scatterRequests(peer2digests)
 		responses := p.gatherResponses(subscriptions)
+		if backoff != nil {
+			updateBackoff(backoff, peer2digests, responses)
+		}
 		for _, resp := range responses {
 			if len(resp.Payload) == 0 {
 				logger.Debug("Got empty response for", resp.Digest)
@@ -309,6 +343,39 @@ func (p *puller) fetchPrivateData(dig2Filter digestToFilterMapping) (*privdataco
 	return res, nil
 }
 
+// updateBackoff backs off the peers which did not respond
+// for any of the digests they were requested
+func updateBackoff(backoff *peerBackoff, peer2digests peer2Digests, responses []*protosgossip.PvtDataElement) {
+	responded := make(map[privdatacommon.DigKey]struct{})
+	for _, resp := range responses {
+		responded[digKey(resp.Digest)] = struct{}{}
+	}
+	for peer, digests := range peer2digests {
+		failed := true
+		for i := range digests {
+			if _, exists := responded[digKey(&digests[i])]; exists {
+				failed = false
+				break
+			}
+		}
+		if failed {
+			backoff.failed(peer)
+		} else {
+			backoff.succeeded(peer)
+		}
+	}
+}
+
+func digKey(dig *protosgossip.PvtDataDigest) privdatacommon.DigKey {
+	return privdatacommon.DigKey{
+		TxId:       dig.TxId,
+		BlockSeq:   dig.BlockSeq,
+		SeqInBlock: dig.SeqInBlock,
+		Namespace:  dig.Namespace,
+		Collection: dig.Collection,
+	}
+}
+
 func (p *puller) gatherResponses(subscriptions []util.Subscription) []*protosgossip.PvtDataElement {
 	var res []*protosgossip.PvtDataElement
 	privateElements := make(chan *protosgossip.PvtDataElement, len(subscriptions))
@@ -611,8 +678,9 @@ func (p *puller) purgedFilter(dig privdatacommon.DigKey) (filter.RoutingFilter,
 	}, nil
 }
 
-func (p *puller) filterNotEligible(dig2rwSets Dig2PvtRWSetWithConfig, shouldCheckLatestConfig bool, signedData protoutil.SignedData, endpoint string) []*protosgossip.PvtDataElement {
+func (p *puller) filterNotEligible(dig2rwSets Dig2PvtRWSetWithConfig, shouldCheckLatestConfig bool, signedData protoutil.SignedData, requester remotePeer) []*protosgossip.PvtDataElement {
 	var returned []*protosgossip.PvtDataElement
+	endpoint := requester.endpoint
 	for d, rwSets := range dig2rwSets {
 		digest := &protosgossip.PvtDataDigest{
 			TxId:       d.TxId,
@@ -626,6 +694,9 @@ func (p *puller) filterNotEligible(dig2rwSets Dig2PvtRWSetWithConfig, shouldChec
 		if err := p.validatePvtRWSetsForEndpoint(digest, rwSets, shouldCheckLatestConfig, signedData); err != nil {
 			logger.Debugf("Skipping R/W set for channel [%s], chaincode [%s], collection [%s], txID = [%s], endpoint [%s]. Reason: %s",
 				p.channel, d.Namespace, d.Collection, d.TxId, endpoint, err)
+		} else if err := p.recordAccess(digest, rwSets.RWSet, requester); err != nil {
+			logger.Warningf("Skipping R/W set for channel [%s], chaincode [%s], collection [%s], txID = [%s], endpoint [%s]: failed recording the access: %s",
+				p.channel, d.Namespace, d.Collection, d.TxId, endpoint, err)
 		} else {
 			logger.Debug("Found", len(rwSets.RWSet), "for TxID", d.TxId, ", collection", d.Collection, "for", endpoint)
 			payload = util.PrivateRWSets(rwSets.RWSet...)
@@ -641,6 +712,40 @@ func (p *puller) filterNotEligible(dig2rwSets Dig2PvtRWSetWithConfig, shouldChec
 	return returned
 }
 
+// recordAccess records in the access log that the keys written by the
+// private R/W sets are sent to the requesting peer
+func (p *puller) recordAccess(d *protosgossip.PvtDataDigest, rwSets []util.PrivateRWSet, requester remotePeer) error {
+	if p.accessLog == nil || !p.accessLog.Enabled(d.Namespace, d.Collection) {
+		return nil
+	}
+
+	var entries []*pvtdataaccesslog.Entry
+	for _, rwSet := range rwSets {
+		collRWSet := &rwset.CollectionPvtReadWriteSet{}
+		if err := proto.Unmarshal(rwSet, collRWSet); err != nil {
+			return errors.Wrap(err, "failed unmarshaling private R/W set")
+		}
+		kvRWSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(collRWSet.Rwset, kvRWSet); err != nil {
+			return errors.Wrap(err, "failed unmarshaling private R/W set")
+		}
+		for _, write := range kvRWSet.Writes {
+			if write.IsDelete {
+				continue
+			}
+			entries = append(entries, &pvtdataaccesslog.Entry{
+				TxID:       d.TxId,
+				Chaincode:  d.Namespace,
+				Collection: d.Collection,
+				Key:        write.Key,
+				PKIID:      hex.EncodeToString([]byte(requester.pkiID)),
+				Endpoint:   requester.endpoint,
+			})
+		}
+	}
+	return p.accessLog.Record(p.channel, entries...)
+}
+
 func (p *puller) validatePvtRWSetsForEndpoint(d *protosgossip.PvtDataDigest, rwSets *util.PrivateRWSetWithConfig, shouldCheckLatestConfig bool, signedData protoutil.SignedData) error {
 	if rwSets == nil {
 		return errors.New("RW sets is nil")
@@ -17,10 +17,12 @@ import (
 	pb "github.com/golang/protobuf/proto"
 	proto "github.com/hyperledger/fabric-protos-go/gossip"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/metrics/disabled"
 	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
 	"github.com/hyperledger/fabric/core/transientstore"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
@@ -201,6 +203,8 @@ func (msg *receivedMsg) GetSourceEnvelope() *proto.Envelope {
 
 func (msg *receivedMsg) GetConnectionInfo() *protoext.ConnectionInfo {
 	return &protoext.ConnectionInfo{
+		ID:       msg.RemotePeer.PKIID,
+		Endpoint: msg.RemotePeer.Endpoint,
 		Identity: api.PeerIdentityType(msg.RemotePeer.PKIID),
 		Auth: &protoext.AuthInfo{
 			SignedData: []byte{},
@@ -291,7 +295,7 @@ func (gn *gossipNetwork) newPullerWithMetrics(metrics *metrics.PrivdataMetrics,
 	g.network = gn
 	g.On("PeersOfChannel", mock.Anything).Return(knownMembers)
 
-	p := NewPuller(metrics, ps, g, &dataRetrieverMock{}, factory, "A", 10)
+	p := NewPuller(metrics, ps, g, &dataRetrieverMock{}, factory, "A", 10, &PrivdataConfig{}, nil)
 	gn.peers = append(gn.peers, g)
 	return p
 }
@@ -381,6 +385,101 @@ func TestPullerFromOnly1Peer(t *testing.T) {
 	assert.Equal(t, p2TransientStore.RWSet, fetched)
 }
 
+type accessLogRecorder struct {
+	lock    sync.Mutex
+	err     error
+	entries []*pvtdataaccesslog.Entry
+}
+
+func (r *accessLogRecorder) Enabled(namespace, collection string) bool {
+	return collection == "col1"
+}
+
+func (r *accessLogRecorder) Record(channelID string, entries ...*pvtdataaccesslog.Entry) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, entry := range entries {
+		entry.Channel = channelID
+	}
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func TestPullerRecordsAccess(t *testing.T) {
+	// Scenario: p1 pulls from p2, which records the keys of the private data it sends to p1
+	// in its access log
+	gn := &gossipNetwork{}
+	factoryMock := &mocks.CollectionAccessFactory{}
+	policyMock := &mocks.CollectionAccessPolicy{}
+	Setup(policyMock, 1, 2, func(data protoutil.SignedData) bool {
+		return true
+	}, map[string]struct{}{"org1": {}, "org2": {}}, false)
+	factoryMock.On("AccessPolicy", mock.Anything, mock.Anything).Return(policyMock, nil)
+
+	p1 := gn.newPuller("p1", newCollectionStore().withPolicy("col1", uint64(100)).thatMapsTo("p2"), factoryMock, membership(peerData{"p2", uint64(1)})...)
+	p2 := gn.newPuller("p2", newCollectionStore().withPolicy("col1", uint64(100)).thatMapsTo("p1"), factoryMock)
+	accessLog := &accessLogRecorder{}
+	p2.accessLog = accessLog
+
+	kvRWSet := &kvrwset.KVRWSet{
+		Writes: []*kvrwset.KVWrite{
+			{Key: "key1", Value: []byte("value1")},
+			{Key: "key2", IsDelete: true},
+		},
+	}
+	collRWSet := &rwset.CollectionPvtReadWriteSet{
+		CollectionName: "col1",
+		Rwset:          protoutil.MarshalOrPanic(kvRWSet),
+	}
+	dig := &proto.PvtDataDigest{
+		TxId:       "txID1",
+		Collection: "col1",
+		Namespace:  "ns1",
+	}
+	store := Dig2PvtRWSetWithConfig{
+		privdatacommon.DigKey{
+			TxId:       "txID1",
+			Collection: "col1",
+			Namespace:  "ns1",
+		}: &util.PrivateRWSetWithConfig{
+			RWSet: []util.PrivateRWSet{protoutil.MarshalOrPanic(collRWSet)},
+			CollectionConfig: &peer.CollectionConfig{
+				Payload: &peer.CollectionConfig_StaticCollectionConfig{
+					StaticCollectionConfig: &peer.StaticCollectionConfig{
+						Name: "col1",
+					},
+				},
+			},
+		},
+	}
+	p2.PrivateDataRetriever.(*dataRetrieverMock).On("CollectionRWSet", mock.MatchedBy(protoMatcher(dig)), uint64(0)).Return(store, true, nil)
+
+	dasf := &digestsAndSourceFactory{}
+	fetchedMessages, err := p1.fetch(dasf.mapDigest(toDigKey(dig)).toSources().create())
+	assert.NoError(t, err)
+	assert.Len(t, fetchedMessages.AvailableElements, 1)
+	assert.Equal(t, []*pvtdataaccesslog.Entry{
+		{
+			Channel:    "A",
+			TxID:       "txID1",
+			Chaincode:  "ns1",
+			Collection: "col1",
+			Key:        "key1",
+			PKIID:      "7031",
+			Endpoint:   "p1",
+		},
+	}, accessLog.entries)
+
+	// the private data is not sent if its access cannot be recorded
+	accessLog.err = errors.New("disk full")
+	fetchedMessages, err = p1.fetch(dasf.mapDigest(toDigKey(dig)).toSources().create())
+	assert.NoError(t, err)
+	assert.Empty(t, fetchedMessages.AvailableElements)
+}
+
 func TestPullerDataNotAvailable(t *testing.T) {
 	// Scenario: p1 pulls from p2 and not from p3
 	// but the data in p2 doesn't exist
@@ -1074,7 +1173,7 @@ func TestPullerIntegratedWithDataRetreiver(t *testing.T) {
 		t.Fatalf("Failed to create test directory, got err %s", err)
 		return
 	}
-	storeProvider, err := transientstore.NewStoreProvider(tempdir)
+	storeProvider, err := transientstore.NewStoreProvider(tempdir, nil)
 	if err != nil {
 		t.Fatalf("Failed to open store, got err %s", err)
 		return
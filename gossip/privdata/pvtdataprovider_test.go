@@ -925,7 +925,7 @@ func TestRetryFetchFromPeer(t *testing.T) {
 
 	tempdir, err := ioutil.TempDir("", "ts")
 	require.NoError(t, err, fmt.Sprintf("Failed to create test directory, got err %s", err))
-	storeProvider, err := transientstoreext.NewStoreProvider(tempdir)
+	storeProvider, err := transientstoreext.NewStoreProvider(tempdir, nil)
 	require.NoError(t, err, fmt.Sprintf("Failed to create store provider, got err %s", err))
 	store, err := storeProvider.OpenStore(ts.channelID)
 	require.NoError(t, err, fmt.Sprintf("Failed to open store, got err %s", err))
@@ -1019,7 +1019,7 @@ func TestSkipPullingAllInvalidTransactions(t *testing.T) {
 
 	tempdir, err := ioutil.TempDir("", "ts")
 	require.NoError(t, err, fmt.Sprintf("Failed to create test directory, got err %s", err))
-	storeProvider, err := transientstore.NewStoreProvider(tempdir)
+	storeProvider, err := transientstore.NewStoreProvider(tempdir, nil)
 	require.NoError(t, err, fmt.Sprintf("Failed to create store provider, got err %s", err))
 	store, err := storeProvider.OpenStore(ts.channelID)
 	require.NoError(t, err, fmt.Sprintf("Failed to open store, got err %s", err))
@@ -1119,7 +1119,7 @@ func TestRetrievedPvtdataPurgeBelowHeight(t *testing.T) {
 
 	tempdir, err := ioutil.TempDir("", "ts")
 	require.NoError(t, err, fmt.Sprintf("Failed to create test directory, got err %s", err))
-	storeProvider, err := transientstoreext.NewStoreProvider(tempdir)
+	storeProvider, err := transientstoreext.NewStoreProvider(tempdir, nil)
 	require.NoError(t, err, fmt.Sprintf("Failed to create store provider, got err %s", err))
 	store, err := storeProvider.OpenStore(ts.channelID)
 	require.NoError(t, err, fmt.Sprintf("Failed to open store, got err %s", err))
@@ -1261,7 +1261,7 @@ func testRetrievePvtdataSuccess(t *testing.T,
 
 	tempdir, err := ioutil.TempDir("", "ts")
 	require.NoError(t, err, fmt.Sprintf("Failed to create test directory, got err %s", err))
-	storeProvider, err := transientstoreext.NewStoreProvider(tempdir)
+	storeProvider, err := transientstoreext.NewStoreProvider(tempdir, nil)
 	require.NoError(t, err, fmt.Sprintf("Failed to create store provider, got err %s", err))
 	store, err := storeProvider.OpenStore(ts.channelID)
 	require.NoError(t, err, fmt.Sprintf("Failed to open store, got err %s", err))
@@ -1300,7 +1300,7 @@ func testRetrievePvtdataFailure(t *testing.T,
 
 	tempdir, err := ioutil.TempDir("", "ts")
 	require.NoError(t, err, fmt.Sprintf("Failed to create test directory, got err %s", err))
-	storeProvider, err := transientstoreext.NewStoreProvider(tempdir)
+	storeProvider, err := transientstoreext.NewStoreProvider(tempdir, nil)
 	require.NoError(t, err, fmt.Sprintf("Failed to create store provider, got err %s", err))
 	store, err := storeProvider.OpenStore(ts.channelID)
 	require.NoError(t, err, fmt.Sprintf("Failed to open store, got err %s", err))
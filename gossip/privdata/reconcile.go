@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -43,6 +44,9 @@ type ConfigHistoryRetriever interface {
 // private data elements that have to be reconciled.
 type ReconciliationFetcher interface {
 	FetchReconciledItems(dig2collectionConfig privdatacommon.Dig2CollectionConfig) (*privdatacommon.FetchedPvtDataContainer, error)
+	// BackedOffPeers returns the peers from which missing private data is
+	// currently not fetched as they did not respond to recent requests
+	BackedOffPeers() []privdatacommon.BackedOffPeer
 }
 
 // PvtDataReconciler completes missing parts of private data that weren't available during commit time.
@@ -52,6 +56,37 @@ type PvtDataReconciler interface {
 	Start()
 	// Stop function stops reconciler
 	Stop()
+	// Status returns the status of the reconciliation of the missing private data
+	Status() (*ReconciliationStatus, error)
+}
+
+// ReconciliationStatus is the status of the reconciliation of the missing private data of a channel
+type ReconciliationStatus struct {
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+	// LastAttempt is the time at which the last reconciliation iteration started
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	// LastSuccess is the time at which the last successful reconciliation iteration started
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	// LastReconciled is the number of private data elements reconciled by the last iteration
+	LastReconciled int `json:"last_reconciled"`
+	// OldestMissingBlock is the lowest block number among the missing private data, if any
+	OldestMissingBlock *uint64                        `json:"oldest_missing_block,omitempty"`
+	Missing            []*CollMissingPvtDataStatus    `json:"missing"`
+	BackedOffPeers     []privdatacommon.BackedOffPeer `json:"backed_off_peers,omitempty"`
+}
+
+// CollMissingPvtDataStatus reports the number of transactions for which the private data
+// of a collection is missing. Deprioritized counts the transactions whose private data could
+// not be fetched in an earlier reconciliation iteration; these are retried less frequently.
+type CollMissingPvtDataStatus struct {
+	Chaincode     string `json:"chaincode"`
+	Collection    string `json:"collection"`
+	Priority      int    `json:"priority"`
+	Count         uint64 `json:"count"`
+	Deprioritized uint64 `json:"deprioritized"`
+	OldestBlock   uint64 `json:"oldest_block"`
 }
 
 type Reconciler struct {
@@ -59,11 +94,18 @@ type Reconciler struct {
 	metrics                *metrics.PrivdataMetrics
 	ReconcileSleepInterval time.Duration
 	ReconcileBatchSize     int
+	priorities             reconciliationPriorities
 	stopChan               chan struct{}
 	startOnce              sync.Once
 	stopOnce               sync.Once
 	ReconciliationFetcher
 	committer.Committer
+
+	statusLock     sync.RWMutex
+	lastAttempt    *time.Time
+	lastSuccess    *time.Time
+	lastError      string
+	lastReconciled int
 }
 
 // NoOpReconciler non functional reconciler to be used
@@ -80,6 +122,10 @@ func (*NoOpReconciler) Stop() {
 	// do nothing
 }
 
+func (*NoOpReconciler) Status() (*ReconciliationStatus, error) {
+	return &ReconciliationStatus{}, nil
+}
+
 // NewReconciler creates a new instance of reconciler
 func NewReconciler(channel string, metrics *metrics.PrivdataMetrics, c committer.Committer,
 	fetcher ReconciliationFetcher, config *PrivdataConfig) *Reconciler {
@@ -89,6 +135,7 @@ func NewReconciler(channel string, metrics *metrics.PrivdataMetrics, c committer
 		metrics:                metrics,
 		ReconcileSleepInterval: config.ReconcileSleepInterval,
 		ReconcileBatchSize:     config.ReconcileBatchSize,
+		priorities:             newReconciliationPriorities(config.ReconciliationPriorities),
 		Committer:              c,
 		ReconciliationFetcher:  fetcher,
 		stopChan:               make(chan struct{}),
@@ -122,8 +169,71 @@ func (r *Reconciler) run() {
 	}
 }
 
+// Status returns the status of the reconciliation of the missing private data of the channel,
+// which includes the missing private data of the eligible collections recorded by the ledger
+func (r *Reconciler) Status() (*ReconciliationStatus, error) {
+	status := &ReconciliationStatus{
+		Channel:        r.channel,
+		Enabled:        true,
+		Missing:        []*CollMissingPvtDataStatus{},
+		BackedOffPeers: r.BackedOffPeers(),
+	}
+	r.statusLock.RLock()
+	status.LastAttempt = r.lastAttempt
+	status.LastSuccess = r.lastSuccess
+	status.LastError = r.lastError
+	status.LastReconciled = r.lastReconciled
+	r.statusLock.RUnlock()
+
+	missingPvtDataTracker, err := r.GetMissingPvtDataTracker()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get the missing private data tracker")
+	}
+	if missingPvtDataTracker == nil {
+		return nil, errors.New("got nil as MissingPvtDataTracker")
+	}
+	summaries, err := missingPvtDataTracker.GetMissingPvtDataSummary()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get the missing private data")
+	}
+	for _, summary := range summaries {
+		status.Missing = append(status.Missing, &CollMissingPvtDataStatus{
+			Chaincode:     summary.Namespace,
+			Collection:    summary.Collection,
+			Priority:      r.priorities.priority(summary.Namespace, summary.Collection),
+			Count:         summary.Count,
+			Deprioritized: summary.Deprioritized,
+			OldestBlock:   summary.OldestBlockNum,
+		})
+		if status.OldestMissingBlock == nil || summary.OldestBlockNum < *status.OldestMissingBlock {
+			oldestBlock := summary.OldestBlockNum
+			status.OldestMissingBlock = &oldestBlock
+		}
+	}
+	return status, nil
+}
+
+func (r *Reconciler) recordAttempt(startTime time.Time, reconciled int, err error) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+	r.lastAttempt = &startTime
+	r.lastReconciled = reconciled
+	if err != nil {
+		r.lastError = err.Error()
+		return
+	}
+	r.lastSuccess = &startTime
+	r.lastError = ""
+}
+
 // returns the number of items that were reconciled , minBlock, maxBlock (blocks range) and an error
-func (r *Reconciler) reconcile() error {
+func (r *Reconciler) reconcile() (err error) {
+	totalReconciled, minBlock, maxBlock := 0, uint64(math.MaxUint64), uint64(0)
+
+	defer func(startTime time.Time) {
+		r.recordAttempt(startTime, totalReconciled, err)
+	}(time.Now())
+
 	missingPvtDataTracker, err := r.GetMissingPvtDataTracker()
 	if err != nil {
 		logger.Error("reconciliation error when trying to get missingPvtDataTracker:", err)
@@ -133,12 +243,11 @@ func (r *Reconciler) reconcile() error {
 		logger.Error("got nil as MissingPvtDataTracker, exiting...")
 		return errors.New("got nil as MissingPvtDataTracker, exiting...")
 	}
-	totalReconciled, minBlock, maxBlock := 0, uint64(math.MaxUint64), uint64(0)
 
 	defer r.reportReconciliationDuration(time.Now())
 
 	for {
-		missingPvtDataInfo, err := missingPvtDataTracker.GetMissingPvtDataInfoForMostRecentBlocks(r.ReconcileBatchSize)
+		missingPvtDataInfo, err := r.getMissingPvtDataInfo(missingPvtDataTracker)
 		if err != nil {
 			logger.Error("reconciliation error when trying to get missing pvt data info recent blocks:", err)
 			return err
@@ -156,29 +265,122 @@ func (r *Reconciler) reconcile() error {
 		logger.Debug("got from ledger", len(missingPvtDataInfo), "blocks with missing private data, trying to reconcile...")
 
 		dig2collectionCfg, minB, maxB := r.getDig2CollectionConfig(missingPvtDataInfo)
-		fetchedData, err := r.FetchReconciledItems(dig2collectionCfg)
-		if err != nil {
-			logger.Error("reconciliation error when trying to fetch missing items from different peers:", err)
-			return err
-		}
+		// a batch may hold the missing private data of collections with different priorities
+		// once the missing private data of all the priorities has been reconciled; the missing
+		// private data of the collections with a higher priority is fetched and committed first
+		for _, prioritizedDig2collectionCfg := range r.priorities.split(dig2collectionCfg) {
+			fetchedData, err := r.FetchReconciledItems(prioritizedDig2collectionCfg)
+			if err != nil {
+				logger.Error("reconciliation error when trying to fetch missing items from different peers:", err)
+				return err
+			}
 
-		pvtDataToCommit := r.preparePvtDataToCommit(fetchedData.AvailableElements)
-		unreconciled := constructUnreconciledMissingData(dig2collectionCfg, fetchedData.AvailableElements)
-		pvtdataHashMismatch, err := r.CommitPvtDataOfOldBlocks(pvtDataToCommit, unreconciled)
-		if err != nil {
-			return errors.Wrap(err, "failed to commit private data")
+			pvtDataToCommit := r.preparePvtDataToCommit(fetchedData.AvailableElements)
+			unreconciled := constructUnreconciledMissingData(prioritizedDig2collectionCfg, fetchedData.AvailableElements)
+			pvtdataHashMismatch, err := r.CommitPvtDataOfOldBlocks(pvtDataToCommit, unreconciled)
+			if err != nil {
+				return errors.Wrap(err, "failed to commit private data")
+			}
+			r.logMismatched(pvtdataHashMismatch)
+			totalReconciled += len(fetchedData.AvailableElements)
 		}
-		r.logMismatched(pvtdataHashMismatch)
 		if minB < minBlock {
 			minBlock = minB
 		}
 		if maxB > maxBlock {
 			maxBlock = maxB
 		}
-		totalReconciled += len(fetchedData.AvailableElements)
 	}
 }
 
+// getMissingPvtDataInfo returns the next batch of missing private data to reconcile. When priorities are
+// configured, the batch is made of the most recent blocks with missing private data of the collections of
+// the highest priority which still miss private data, so that it does not wait for the missing private data
+// of less important collections in more recent blocks. The missing private data which could not be fetched
+// in earlier attempts, and which is retried periodically, is only considered once the missing private data
+// of all the priorities has been reconciled
+func (r *Reconciler) getMissingPvtDataInfo(missingPvtDataTracker ledger.MissingPvtDataTracker) (ledger.MissingPvtDataInfo, error) {
+	for _, priority := range r.priorities.levels() {
+		priority := priority
+		missingPvtDataInfo, err := missingPvtDataTracker.GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks(r.ReconcileBatchSize, func(ns, coll string) bool {
+			return r.priorities.priority(ns, coll) == priority
+		})
+		if err != nil || len(missingPvtDataInfo) > 0 {
+			return missingPvtDataInfo, err
+		}
+	}
+	return missingPvtDataTracker.GetMissingPvtDataInfoForMostRecentBlocks(r.ReconcileBatchSize)
+}
+
+// reconciliationPriorities maps the chaincodes and their collections to the
+// priority of the reconciliation of their missing private data. The priority
+// of a chaincode is recorded under the empty collection name.
+type reconciliationPriorities map[string]map[string]int
+
+func newReconciliationPriorities(priorities []ReconciliationPriority) reconciliationPriorities {
+	res := make(reconciliationPriorities)
+	for _, p := range priorities {
+		if _, exists := res[p.Chaincode]; !exists {
+			res[p.Chaincode] = make(map[string]int)
+		}
+		res[p.Chaincode][p.Collection] = p.Priority
+	}
+	return res
+}
+
+func (p reconciliationPriorities) priority(chaincode, collection string) int {
+	if priority, exists := p[chaincode][collection]; exists {
+		return priority
+	}
+	return p[chaincode][""]
+}
+
+// levels returns the configured priorities along with the priority 0 of the chaincodes
+// and collections not listed, in decreasing order, or nil if no priority is configured
+func (p reconciliationPriorities) levels() []int {
+	if len(p) == 0 {
+		return nil
+	}
+
+	exists := map[int]bool{0: true}
+	levels := []int{0}
+	for _, collPriorities := range p {
+		for _, priority := range collPriorities {
+			if !exists[priority] {
+				exists[priority] = true
+				levels = append(levels, priority)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(levels)))
+	return levels
+}
+
+// split splits the missing private data by priority, in decreasing order of priority
+func (p reconciliationPriorities) split(dig2collectionCfg privdatacommon.Dig2CollectionConfig) []privdatacommon.Dig2CollectionConfig {
+	if len(p) == 0 || len(dig2collectionCfg) == 0 {
+		return []privdatacommon.Dig2CollectionConfig{dig2collectionCfg}
+	}
+
+	byPriority := make(map[int]privdatacommon.Dig2CollectionConfig)
+	var priorities []int
+	for dig, collectionCfg := range dig2collectionCfg {
+		priority := p.priority(dig.Namespace, dig.Collection)
+		if _, exists := byPriority[priority]; !exists {
+			byPriority[priority] = make(privdatacommon.Dig2CollectionConfig)
+			priorities = append(priorities, priority)
+		}
+		byPriority[priority][dig] = collectionCfg
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	res := make([]privdatacommon.Dig2CollectionConfig, 0, len(priorities))
+	for _, priority := range priorities {
+		res = append(res, byPriority[priority])
+	}
+	return res
+}
+
 func (r *Reconciler) reportReconciliationDuration(startTime time.Time) {
 	r.metrics.ReconciliationDuration.With("channel", r.channel).Observe(time.Since(startTime).Seconds())
 }
@@ -705,3 +705,166 @@ func TestConstructUnreconciledMissingData(t *testing.T) {
 		})
 	}
 }
+
+func TestReconciliationPriorities(t *testing.T) {
+	// Scenario: the batches of missing private data are made of the most recent blocks with missing
+	// private data of the collections with the highest priority, whatever the missing private data
+	// of less important collections in more recent blocks. A batch returned once the missing private
+	// data of all the priorities has been reconciled is fetched and committed in decreasing order of
+	// the priority of its chaincodes or collections
+	committer := &mocks.Committer{}
+	fetcher := &mocks.ReconciliationFetcher{}
+	configHistoryRetriever := &mocks.ConfigHistoryRetriever{}
+	missingPvtDataTracker := &mocks.MissingPvtDataTracker{}
+
+	missing := map[privdatacommon.DigKey]bool{
+		{Namespace: "ns1", Collection: "col1", BlockSeq: 1, SeqInBlock: 1}: true,
+		{Namespace: "ns1", Collection: "col2", BlockSeq: 2, SeqInBlock: 1}: true,
+		{Namespace: "ns2", Collection: "col1", BlockSeq: 3, SeqInBlock: 1}: true,
+		{Namespace: "ns1", Collection: "col1", BlockSeq: 4, SeqInBlock: 1}: true,
+	}
+	var filteredQueries int
+	missingPvtDataTracker.On("GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks", 1, mock.Anything).Return(
+		func(maxBlocks int, filter ledger.MissingPvtDataFilter) ledger.MissingPvtDataInfo {
+			filteredQueries++
+			var mostRecent *privdatacommon.DigKey
+			for dig := range missing {
+				dig := dig
+				if filter(dig.Namespace, dig.Collection) && (mostRecent == nil || dig.BlockSeq > mostRecent.BlockSeq) {
+					mostRecent = &dig
+				}
+			}
+			if mostRecent == nil {
+				return nil
+			}
+			missingInfo := ledger.MissingPvtDataInfo{}
+			missingInfo.Add(mostRecent.BlockSeq, mostRecent.SeqInBlock, mostRecent.Namespace, mostRecent.Collection)
+			return missingInfo
+		}, nil)
+	missingPvtDataTracker.On("GetMissingPvtDataInfoForMostRecentBlocks", 1).Return(ledger.MissingPvtDataInfo{
+		5: map[uint64][]*ledger.MissingCollectionPvtDataInfo{
+			1: {{Namespace: "ns1", Collection: "col2"}},
+			2: {{Namespace: "ns2", Collection: "col1"}},
+			3: {{Namespace: "ns1", Collection: "col1"}},
+		},
+	}, nil).Once()
+	missingPvtDataTracker.On("GetMissingPvtDataInfoForMostRecentBlocks", 1).Return(nil, nil)
+
+	collectionConfigInfo := &ledger.CollectionConfigInfo{
+		CollectionConfig: &peer.CollectionConfigPackage{
+			Config: []*peer.CollectionConfig{
+				{Payload: &peer.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: &peer.StaticCollectionConfig{Name: "col1"}}},
+				{Payload: &peer.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: &peer.StaticCollectionConfig{Name: "col2"}}},
+			},
+		},
+	}
+	configHistoryRetriever.On("MostRecentCollectionConfigBelow", mock.Anything, mock.Anything).Return(collectionConfigInfo, nil)
+	committer.On("GetMissingPvtDataTracker").Return(missingPvtDataTracker, nil)
+	committer.On("GetConfigHistoryRetriever").Return(configHistoryRetriever, nil)
+
+	var fetched [][]privdatacommon.DigKey
+	fetcher.On("FetchReconciledItems", mock.Anything).Run(func(args mock.Arguments) {
+		var digs []privdatacommon.DigKey
+		for dig := range args.Get(0).(privdatacommon.Dig2CollectionConfig) {
+			digs = append(digs, dig)
+			delete(missing, dig)
+		}
+		fetched = append(fetched, digs)
+	}).Return(&privdatacommon.FetchedPvtDataContainer{}, nil)
+	committer.On("CommitPvtDataOfOldBlocks", mock.Anything, mock.Anything).Return(nil, nil)
+
+	r := NewReconciler("", metrics.NewGossipMetrics(&disabled.Provider{}).PrivdataMetrics, committer, fetcher,
+		&PrivdataConfig{
+			ReconcileSleepInterval: time.Minute,
+			ReconcileBatchSize:     1,
+			ReconciliationPriorities: []ReconciliationPriority{
+				{Chaincode: "ns1", Priority: 10},
+				{Chaincode: "ns1", Collection: "col2", Priority: -1},
+			},
+		})
+	require.NoError(t, r.reconcile())
+
+	require.Equal(t, [][]privdatacommon.DigKey{
+		{{Namespace: "ns1", Collection: "col1", BlockSeq: 4, SeqInBlock: 1}},
+		{{Namespace: "ns1", Collection: "col1", BlockSeq: 1, SeqInBlock: 1}},
+		{{Namespace: "ns2", Collection: "col1", BlockSeq: 3, SeqInBlock: 1}},
+		{{Namespace: "ns1", Collection: "col2", BlockSeq: 2, SeqInBlock: 1}},
+		{{Namespace: "ns1", Collection: "col1", BlockSeq: 5, SeqInBlock: 3}},
+		{{Namespace: "ns2", Collection: "col1", BlockSeq: 5, SeqInBlock: 2}},
+		{{Namespace: "ns1", Collection: "col2", BlockSeq: 5, SeqInBlock: 1}},
+	}, fetched)
+	committer.AssertNumberOfCalls(t, "CommitPvtDataOfOldBlocks", 7)
+	missingPvtDataTracker.AssertNumberOfCalls(t, "GetMissingPvtDataInfoForMostRecentBlocks", 2)
+	// the batches of the priority 10 take a query each, the batch of the priority 0 two and the batch of
+	// the priority -1 three, as do both calls of GetMissingPvtDataInfoForMostRecentBlocks
+	require.Equal(t, 2*1+2+3+2*3, filteredQueries)
+}
+
+func TestReconciliationPriorityLevels(t *testing.T) {
+	require.Nil(t, newReconciliationPriorities(nil).levels())
+	require.Equal(t, []int{10, 5, 0, -1}, newReconciliationPriorities([]ReconciliationPriority{
+		{Chaincode: "ns1", Priority: 5},
+		{Chaincode: "ns1", Collection: "col1", Priority: 10},
+		{Chaincode: "ns2", Priority: -1},
+		{Chaincode: "ns3", Priority: 5},
+	}).levels())
+}
+
+func TestReconciliationStatus(t *testing.T) {
+	committer := &mocks.Committer{}
+	fetcher := &mocks.ReconciliationFetcher{}
+	missingPvtDataTracker := &mocks.MissingPvtDataTracker{}
+
+	backedOffPeers := []privdatacommon.BackedOffPeer{{Endpoint: "p1", Failures: 2, Until: time.Now().Add(time.Minute)}}
+	fetcher.On("BackedOffPeers").Return(backedOffPeers)
+	committer.On("GetMissingPvtDataTracker").Return(missingPvtDataTracker, nil)
+	missingPvtDataTracker.On("GetMissingPvtDataInfoOfCollectionsForMostRecentBlocks", mock.Anything, mock.Anything).Return(nil, nil)
+	missingPvtDataTracker.On("GetMissingPvtDataInfoForMostRecentBlocks", mock.Anything).Return(nil, errors.New("ledger unavailable")).Once()
+	missingPvtDataTracker.On("GetMissingPvtDataInfoForMostRecentBlocks", mock.Anything).Return(nil, nil)
+	missingPvtDataTracker.On("GetMissingPvtDataSummary").Return([]*ledger.CollMissingPvtDataSummary{
+		{Namespace: "ns1", Collection: "col1", Count: 5, Deprioritized: 2, OldestBlockNum: 7},
+		{Namespace: "ns1", Collection: "col2", Count: 1, OldestBlockNum: 3},
+	}, nil)
+
+	r := NewReconciler("mychannel", metrics.NewGossipMetrics(&disabled.Provider{}).PrivdataMetrics, committer, fetcher,
+		&PrivdataConfig{
+			ReconcileSleepInterval:   time.Minute,
+			ReconcileBatchSize:       1,
+			ReconciliationPriorities: []ReconciliationPriority{{Chaincode: "ns1", Collection: "col2", Priority: 5}},
+		})
+
+	status, err := r.Status()
+	require.NoError(t, err)
+	require.Nil(t, status.LastAttempt)
+	require.Nil(t, status.LastSuccess)
+
+	require.Error(t, r.reconcile())
+	status, err = r.Status()
+	require.NoError(t, err)
+	require.NotNil(t, status.LastAttempt)
+	require.Nil(t, status.LastSuccess)
+	require.Equal(t, "ledger unavailable", status.LastError)
+
+	require.NoError(t, r.reconcile())
+	status, err = r.Status()
+	require.NoError(t, err)
+	require.Equal(t, status.LastAttempt, status.LastSuccess)
+	oldestMissingBlock := uint64(3)
+	require.Equal(t, &ReconciliationStatus{
+		Channel:            "mychannel",
+		Enabled:            true,
+		LastAttempt:        status.LastAttempt,
+		LastSuccess:        status.LastSuccess,
+		OldestMissingBlock: &oldestMissingBlock,
+		Missing: []*CollMissingPvtDataStatus{
+			{Chaincode: "ns1", Collection: "col1", Count: 5, Deprioritized: 2, OldestBlock: 7},
+			{Chaincode: "ns1", Collection: "col2", Priority: 5, Count: 1, OldestBlock: 3},
+		},
+		BackedOffPeers: backedOffPeers,
+	}, status)
+
+	committer.Mock = mock.Mock{}
+	committer.On("GetMissingPvtDataTracker").Return(nil, errors.New("no tracker"))
+	_, err = r.Status()
+	require.EqualError(t, err, "failed to get the missing private data tracker: no tracker")
+}
@@ -18,6 +18,12 @@ const (
 	transientBlockRetentionDefault = 1000
 )
 
+// ElectionPriority is the priority of a peer as a candidate to the leadership of the channels
+type ElectionPriority struct {
+	Endpoint string `mapstructure:"endpoint"`
+	Priority int    `mapstructure:"priority"`
+}
+
 // ServiceConfig is the config struct for gossip services
 type ServiceConfig struct {
 	// PeerTLSEnabled enables/disables Peer TLS.
@@ -40,6 +46,12 @@ type ServiceConfig struct {
 	// ElectionLeaderElectionDuration is the time passes since last declaration message before peer decides to perform
 	// leader election (unit: second).
 	ElectionLeaderElectionDuration time.Duration
+	// ElectionStrategy is the name of the strategy which ranks the peers of the organization
+	// as candidates to the leadership of the channels: lowestID, priority or channelHash.
+	ElectionStrategy string
+	// ElectionPriorities are the priorities of the peers of the organization, by endpoint,
+	// when the priority strategy is used. The peers with the highest priority are preferred.
+	ElectionPriorities map[string]int
 	// PvtDataPullRetryThreshold determines the maximum duration of time private data corresponding for
 	// a given block.
 	PvtDataPullRetryThreshold time.Duration
@@ -76,6 +88,25 @@ func (c *ServiceConfig) loadGossipConfig() {
 	c.ElectionMembershipSampleInterval = util.GetDurationOrDefault("peer.gossip.election.membershipSampleInterval", election.DefMembershipSampleInterval)
 	c.ElectionLeaderAliveThreshold = util.GetDurationOrDefault("peer.gossip.election.leaderAliveThreshold", election.DefLeaderAliveThreshold)
 	c.ElectionLeaderElectionDuration = util.GetDurationOrDefault("peer.gossip.election.leaderElectionDuration", election.DefLeaderElectionDuration)
+	c.ElectionStrategy = viper.GetString("peer.gossip.election.strategy")
+	switch c.ElectionStrategy {
+	case election.LowestIDStrategy, election.PriorityStrategy, election.ChannelHashStrategy:
+	case "":
+		c.ElectionStrategy = election.LowestIDStrategy
+	default:
+		logger.Warningf("Configuration key peer.gossip.election.strategy has unknown value %s, defaulting to %s", c.ElectionStrategy, election.LowestIDStrategy)
+		c.ElectionStrategy = election.LowestIDStrategy
+	}
+	var priorities []ElectionPriority
+	if err := viper.UnmarshalKey("peer.gossip.election.priorities", &priorities); err != nil {
+		logger.Warningf("Configuration key peer.gossip.election.priorities is invalid, ignoring it: %s", err)
+	}
+	for _, p := range priorities {
+		if c.ElectionPriorities == nil {
+			c.ElectionPriorities = map[string]int{}
+		}
+		c.ElectionPriorities[p.Endpoint] = p.Priority
+	}
 
 	c.PvtDataPushAckTimeout = viper.GetDuration("peer.gossip.pvtData.pushAckTimeout")
 	c.PvtDataPullRetryThreshold = viper.GetDuration("peer.gossip.pvtData.pullRetryThreshold")
@@ -28,6 +28,11 @@ func TestGlobalConfig(t *testing.T) {
 	viper.Set("peer.gossip.orgLeader", true)
 	viper.Set("peer.gossip.election.leaderAliveThreshold", "10m")
 	viper.Set("peer.gossip.election.leaderElectionDuration", "5s")
+	viper.Set("peer.gossip.election.strategy", "priority")
+	viper.Set("peer.gossip.election.priorities", []map[string]interface{}{
+		{"endpoint": "peer0:7051", "priority": 10},
+		{"endpoint": "peer1:7051", "priority": 5},
+	})
 	viper.Set("peer.gossip.pvtData.btlPullMargin", 15)
 	viper.Set("peer.gossip.pvtData.transientstoreMaxBlockRetention", 1000)
 	viper.Set("peer.gossip.pvtData.skipPullingInvalidTransactionsDuringCommit", false)
@@ -46,6 +51,8 @@ func TestGlobalConfig(t *testing.T) {
 		ElectionLeaderElectionDuration:             5 * time.Second,
 		ElectionStartupGracePeriod:                 election.DefStartupGracePeriod,
 		ElectionMembershipSampleInterval:           election.DefMembershipSampleInterval,
+		ElectionStrategy:                           election.PriorityStrategy,
+		ElectionPriorities:                         map[string]int{"peer0:7051": 10, "peer1:7051": 5},
 		BtlPullMargin:                              15,
 		TransientstoreMaxBlockRetention:            uint64(1000),
 		SkipPullingInvalidTransactionsDuringCommit: false,
@@ -53,3 +60,20 @@ func TestGlobalConfig(t *testing.T) {
 
 	assert.Equal(t, coreConfig, expectedConfig)
 }
+
+func TestGlobalConfigElectionStrategy(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	coreConfig := service.GlobalConfig()
+	assert.Equal(t, election.LowestIDStrategy, coreConfig.ElectionStrategy)
+	assert.Nil(t, coreConfig.ElectionPriorities)
+
+	viper.Set("peer.gossip.election.strategy", "highestID")
+	coreConfig = service.GlobalConfig()
+	assert.Equal(t, election.LowestIDStrategy, coreConfig.ElectionStrategy)
+
+	viper.Set("peer.gossip.election.strategy", "channelHash")
+	coreConfig = service.GlobalConfig()
+	assert.Equal(t, election.ChannelHashStrategy, coreConfig.ElectionStrategy)
+}
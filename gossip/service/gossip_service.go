@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package service
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 
 	gproto "github.com/hyperledger/fabric-protos-go/gossip"
@@ -112,6 +114,16 @@ type gossipSvc interface {
 	// IsInMyOrg checks whether a network member is in this peer's org
 	IsInMyOrg(member discovery.NetworkMember) bool
 
+	// KnownMembers returns the alive and the dead members known to the peer,
+	// along with the time they were last seen alive
+	KnownMembers() []discovery.MemberState
+
+	// Connections returns the information of the connections to remote peers
+	Connections() []*protoext.ConnectionInfo
+
+	// Probe returns nil if the peer at the given endpoint is responsive, and an error if it's not
+	Probe(endpoint string) error
+
 	// Stop stops the gossip component
 	Stop()
 }
@@ -183,6 +195,17 @@ type GossipService struct {
 	serviceConfig     *ServiceConfig
 	privdataConfig    *gossipprivdata.PrivdataConfig
 	anchorPeerTracker *anchorPeerTracker
+	// pvtDataAdvertisers publish the chaincodes of the channels
+	// along with the private data missing on the peer
+	pvtDataAdvertisers map[string]*missingPvtDataAdvertiser
+	// electionStrategies rank the candidates to the leadership of the channels
+	// which use dynamic leader election
+	electionStrategies map[string]*election.PreferredLeaderStrategy
+	// internalEndpoint is the endpoint the peer publishes to peers in its organization
+	internalEndpoint string
+	// preferredLeaders shares the preferred leaders of the
+	// channels with the other peers of the organization
+	preferredLeaders preferredLeaders
 }
 
 // This is an implementation of api.JoinChannelMessage.
@@ -224,6 +247,18 @@ func (t *anchorPeerTracker) update(channelName string, endpoints map[string]stru
 	t.allEndpoints[channelName] = endpoints
 }
 
+// endpoints returns the sorted anchor peer endpoints of the given channel
+func (t *anchorPeerTracker) endpoints(channelName string) []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	endpoints := make([]string, 0, len(t.allEndpoints[channelName]))
+	for endpoint := range t.allEndpoints[channelName] {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
 // IsAnchorPeer checks if an endpoint is an anchor peer in any channel
 func (t *anchorPeerTracker) IsAnchorPeer(endpoint string) bool {
 	t.mutex.RLock()
@@ -286,12 +321,14 @@ func New(
 			deliverGRPCClient:    deliverGRPCClient,
 			deliverServiceConfig: deliverServiceConfig,
 		},
-		peerIdentity:      serializedIdentity,
-		secAdv:            secAdv,
-		metrics:           gossipMetrics,
-		serviceConfig:     serviceConfig,
-		privdataConfig:    privdataConfig,
-		anchorPeerTracker: anchorPeerTracker,
+		peerIdentity:       serializedIdentity,
+		secAdv:             secAdv,
+		metrics:            gossipMetrics,
+		serviceConfig:      serviceConfig,
+		privdataConfig:     privdataConfig,
+		anchorPeerTracker:  anchorPeerTracker,
+		electionStrategies: make(map[string]*election.PreferredLeaderStrategy),
+		internalEndpoint:   gossipConfig.InternalEndpoint,
 	}, nil
 }
 
@@ -334,6 +371,7 @@ type Support struct {
 	CollDataStore        storeapi.Store
 	Ledger               ledger.PeerLedger
 	BlockPublisher       extgossipapi.BlockPublisher
+	PvtDataAccessLog     gossipprivdata.AccessLog
 }
 
 // InitializeChannel allocates the state provider and should be invoked once per channel per execution
@@ -348,7 +386,7 @@ func (g *GossipService) InitializeChannel(channelID string, ordererSource *order
 	dataRetriever := gossipprivdata.NewDataRetriever(store, support.Committer)
 	collectionAccessFactory := gossipprivdata.NewCollectionAccessFactory(support.IdDeserializeFactory)
 	fetcher := gossipprivdata.NewPuller(g.metrics.PrivdataMetrics, support.CollectionStore, g.gossipSvc, dataRetriever,
-		collectionAccessFactory, channelID, g.serviceConfig.BtlPullMargin)
+		collectionAccessFactory, channelID, g.serviceConfig.BtlPullMargin, g.privdataConfig, support.PvtDataAccessLog)
 
 	coordinatorConfig := gossipprivdata.CoordinatorConfig{
 		TransientBlockRetention:        g.serviceConfig.TransientstoreMaxBlockRetention,
@@ -385,6 +423,7 @@ func (g *GossipService) InitializeChannel(channelID string, ordererSource *order
 		reconciler:  reconciler,
 	}
 	g.privateHandlers[channelID].reconciler.Start()
+	g.pvtDataAdvertiserWhileLocked(channelID).start(support.Committer.GetMissingPvtDataTracker, g.privdataConfig.ReconcileSleepInterval)
 
 	blockingMode := !g.serviceConfig.NonBlockingCommitMode
 	stateConfig := state.GlobalConfig()
@@ -419,8 +458,11 @@ func (g *GossipService) InitializeChannel(channelID string, ordererSource *order
 
 		if leaderElection {
 			logger.Debug("Delivery uses dynamic leader election mechanism, channel", channelID)
-			g.leaderElection[channelID] = g.newLeaderElectionComponent(channelID, g.onStatusChangeFactory(channelID,
+			strategy := g.newElectionStrategy(channelID)
+			g.electionStrategies[channelID] = strategy
+			g.leaderElection[channelID] = g.newLeaderElectionComponent(channelID, strategy, g.onStatusChangeFactory(channelID,
 				support.Committer), g.metrics.ElectionMetrics)
+			g.startPreferredLeadersSync(g.serviceConfig.ElectionMembershipSampleInterval)
 		} else if isStaticOrgLeader {
 			logger.Debug("This peer is configured to connect to ordering service for blocks delivery, channel", channelID)
 			g.deliveryService[channelID].StartDeliverForChannel(channelID, support.Committer, func() {})
@@ -482,6 +524,27 @@ func (g *GossipService) AddPayload(channelID string, payload *gproto.Payload) er
 	return g.chains[channelID].AddPayload(payload)
 }
 
+// UpdateChaincodes updates the chaincodes the peer publishes to other peers in the channel,
+// along with the collections of the chaincodes for which private data is missing on the peer
+func (g *GossipService) UpdateChaincodes(chaincodes []*gproto.Chaincode, channelID gossipcommon.ChannelID) {
+	g.lock.Lock()
+	advertiser := g.pvtDataAdvertiserWhileLocked(string(channelID))
+	g.lock.Unlock()
+	advertiser.UpdateChaincodes(chaincodes)
+}
+
+func (g *GossipService) pvtDataAdvertiserWhileLocked(channelID string) *missingPvtDataAdvertiser {
+	if g.pvtDataAdvertisers == nil {
+		g.pvtDataAdvertisers = make(map[string]*missingPvtDataAdvertiser)
+	}
+	advertiser, exists := g.pvtDataAdvertisers[channelID]
+	if !exists {
+		advertiser = newMissingPvtDataAdvertiser(gossipcommon.ChannelID(channelID), g.gossipSvc.UpdateChaincodes)
+		g.pvtDataAdvertisers[channelID] = advertiser
+	}
+	return advertiser
+}
+
 // Stop stops the gossip component
 func (g *GossipService) Stop() {
 	g.lock.Lock()
@@ -495,15 +558,17 @@ func (g *GossipService) Stop() {
 		}
 		g.chains[chainID].Stop()
 		g.privateHandlers[chainID].close()
+		g.pvtDataAdvertisers[chainID].stop()
 
 		if g.deliveryService[chainID] != nil {
 			g.deliveryService[chainID].Stop()
 		}
 	}
+	g.stopPreferredLeadersSync()
 	g.gossipSvc.Stop()
 }
 
-func (g *GossipService) newLeaderElectionComponent(channelID string, callback func(bool),
+func (g *GossipService) newLeaderElectionComponent(channelID string, strategy election.Strategy, callback func(bool),
 	electionMetrics *gossipmetrics.ElectionMetrics) election.LeaderElectionService {
 	PKIid := g.mcs.GetPKIidOfCert(g.peerIdentity)
 	adapter := election.NewAdapter(g, PKIid, gossipcommon.ChannelID(channelID), electionMetrics)
@@ -512,10 +577,44 @@ func (g *GossipService) newLeaderElectionComponent(channelID string, callback fu
 		MembershipSampleInterval: g.serviceConfig.ElectionMembershipSampleInterval,
 		LeaderAliveThreshold:     g.serviceConfig.ElectionLeaderAliveThreshold,
 		LeaderElectionDuration:   g.serviceConfig.ElectionLeaderElectionDuration,
+		Strategy:                 strategy,
 	}
 	return election.NewLeaderElectionService(adapter, string(PKIid), callback, config)
 }
 
+// newElectionStrategy returns the configured strategy which ranks the candidates to the leadership of the channel
+func (g *GossipService) newElectionStrategy(channelID string) *election.PreferredLeaderStrategy {
+	config := election.StrategyConfig{
+		Name:       g.serviceConfig.ElectionStrategy,
+		Priorities: g.serviceConfig.ElectionPriorities,
+	}
+	strategy, err := election.NewStrategy(config, gossipcommon.ChannelID(channelID), g.endpointResolver())
+	if err != nil {
+		logger.Warningf("Using the %s leader election strategy for channel %s: %s", election.LowestIDStrategy, channelID, err)
+		strategy, _ = election.NewStrategy(election.StrategyConfig{}, gossipcommon.ChannelID(channelID), g.endpointResolver())
+	}
+	return strategy
+}
+
+// endpointResolver returns an EndpointResolver which resolves the endpoints of this peer and
+// of the alive members of the membership. The membership is used rather than the peers of the
+// channel, since a peer only learns about the channels of the other peers some time after
+// they are alive, and the candidates would be ranked differently in the meantime.
+func (g *GossipService) endpointResolver() election.EndpointResolver {
+	return func(pkiID gossipcommon.PKIidType) []string {
+		self := g.SelfMembershipInfo()
+		if bytes.Equal(pkiID, self.PKIid) {
+			return []string{self.Endpoint, g.internalEndpoint}
+		}
+		for _, member := range g.Peers() {
+			if bytes.Equal(pkiID, member.PKIid) {
+				return []string{member.Endpoint, member.InternalEndpoint}
+			}
+		}
+		return nil
+	}
+}
+
 func (g *GossipService) amIinChannel(myOrg string, config Config) bool {
 	for _, orgName := range orgListFromConfig(config) {
 		if orgName == myOrg {
@@ -80,7 +80,7 @@ func newTransientStore(t *testing.T) *testTransientStore {
 		t.Fatalf("Failed to create test directory, got err %s", err)
 		return s
 	}
-	s.storeProvider, err = transientstoreext.NewStoreProvider(s.tempdir)
+	s.storeProvider, err = transientstoreext.NewStoreProvider(s.tempdir, nil)
 	if err != nil {
 		t.Fatalf("Failed to open store, got err %s", err)
 		return s
@@ -226,6 +226,86 @@ func TestLeaderElectionWithDeliverClient(t *testing.T) {
 	stopPeers(gossips)
 }
 
+func TestPreferredLeaderWithRealGossip(t *testing.T) {
+	clearResources := xtestutil.SetupResources()
+	defer clearResources()
+
+	// Scenario: 3 peers of an org elect the leader of a channel dynamically. The preferred
+	// leader is set on a single peer which isn't the leader, the other peers adopt it and the
+	// preferred peer becomes the only leader. The configured strategy is then restored on
+	// another peer, and all the peers adopt it too.
+
+	n := 3
+	serviceConfig := &ServiceConfig{
+		UseLeaderElection:                true,
+		OrgLeader:                        false,
+		ElectionStartupGracePeriod:       election.DefStartupGracePeriod,
+		ElectionMembershipSampleInterval: election.DefMembershipSampleInterval,
+		ElectionLeaderAliveThreshold:     election.DefLeaderAliveThreshold,
+		ElectionLeaderElectionDuration:   election.DefLeaderElectionDuration,
+	}
+	gossips := startPeers(serviceConfig, n, 0, 1, 2)
+	defer stopPeers(gossips)
+
+	channelName := "chanA"
+	addPeersToChannel(channelName, gossips, []int{0, 1, 2})
+	waitForFullMembershipOrFailNow(t, channelName, gossips, n, TIMEOUT, time.Second*2)
+
+	store := newTransientStore(t)
+	defer store.tearDown()
+
+	services := make([]*electionService, n)
+	for i := 0; i < n; i++ {
+		deliverServiceFactory := &mockDeliverServiceFactory{
+			service: &mockDeliverService{
+				running: make(map[string]bool),
+			},
+		}
+		gossips[i].deliveryFactory = deliverServiceFactory
+		gossips[i].InitializeChannel(channelName, orderers.NewConnectionSource(flogging.MustGetLogger("peer.orderers"), nil), store.Store, Support{
+			Committer:      &mockLedgerInfo{1},
+			BlockPublisher: extmocks.NewBlockPublisher(),
+		})
+		services[i] = &electionService{LeaderElectionService: gossips[i].leaderElection[channelName]}
+	}
+	require.True(t, waitForLeaderElection(services, time.Second*30, time.Second), "One leader should be selected")
+
+	preferred := 0
+	for services[preferred].IsLeader() {
+		preferred++
+	}
+	preferredPKIID := gossips[preferred].SelfMembershipInfo().PKIid
+	require.NoError(t, gossips[preferred].SetPreferredLeader(channelName, gossips[preferred].SelfMembershipInfo().Endpoint))
+
+	require.Eventually(t, func() bool {
+		for _, g := range gossips {
+			if !g.electionStrategies[channelName].IsPreferredLeader(preferredPKIID) {
+				return false
+			}
+		}
+		return true
+	}, time.Second*30, time.Millisecond*500, "The preferred leader should be adopted by all the peers")
+	require.Eventually(t, func() bool {
+		for i, s := range services {
+			if s.IsLeader() != (i == preferred) {
+				return false
+			}
+		}
+		return true
+	}, time.Second*30, time.Millisecond*500, "The preferred peer should be the only leader")
+
+	require.NoError(t, gossips[(preferred+1)%n].SetPreferredLeader(channelName, ""))
+	require.Eventually(t, func() bool {
+		for _, g := range gossips {
+			if len(g.PreferredLeaders()) != 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second*30, time.Millisecond*500, "The configured strategy should be restored on all the peers")
+	require.True(t, waitForLeaderElection(services, time.Second*30, time.Second), "One leader should be selected")
+}
+
 func TestWithStaticDeliverClientLeader(t *testing.T) {
 	// Tests check if static leader flag works ok.
 	// Leader election flag set to false, and static leader flag set to true
@@ -508,7 +588,7 @@ func TestLeaderElectionWithRealGossip(t *testing.T) {
 
 	for i := 0; i < n; i++ {
 		services[i] = &electionService{nil, false, 0}
-		services[i].LeaderElectionService = gossips[i].newLeaderElectionComponent(channelName, services[i].callback, electionMetrics)
+		services[i].LeaderElectionService = gossips[i].newLeaderElectionComponent(channelName, nil, services[i].callback, electionMetrics)
 	}
 
 	logger.Warning("Waiting for leader election")
@@ -541,7 +621,7 @@ func TestLeaderElectionWithRealGossip(t *testing.T) {
 	for idx, i := range secondChannelPeerIndexes {
 		secondChannelServices[idx] = &electionService{nil, false, 0}
 		secondChannelServices[idx].LeaderElectionService =
-			gossips[i].newLeaderElectionComponent(secondChannelName, secondChannelServices[idx].callback, electionMetrics)
+			gossips[i].newLeaderElectionComponent(secondChannelName, nil, secondChannelServices[idx].callback, electionMetrics)
 	}
 
 	assert.True(t, waitForLeaderElection(secondChannelServices, time.Second*30, time.Second*2), "One leader should be selected for chanB")
@@ -792,6 +872,9 @@ func newGossipInstance(serviceConfig *ServiceConfig, port int, id int, gRPCServe
 		metrics:        metrics,
 		serviceConfig:  serviceConfig,
 		privdataConfig: privdata.GlobalConfig(),
+
+		electionStrategies: make(map[string]*election.PreferredLeaderStrategy),
+		internalEndpoint:   conf.InternalEndpoint,
 	}
 
 	return &gossipGRPC{GossipService: gossipService, grpc: gRPCServer}
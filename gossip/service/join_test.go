@@ -110,6 +110,18 @@ func (*gossipMock) IsInMyOrg(member discovery.NetworkMember) bool {
 	panic("implement me")
 }
 
+func (*gossipMock) KnownMembers() []discovery.MemberState {
+	panic("implement me")
+}
+
+func (*gossipMock) Connections() []*protoext.ConnectionInfo {
+	panic("implement me")
+}
+
+func (*gossipMock) Probe(endpoint string) error {
+	panic("implement me")
+}
+
 func (*gossipMock) Stop() {
 	panic("implement me")
 }
@@ -0,0 +1,279 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	gossipcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/election"
+	"github.com/pkg/errors"
+)
+
+// ErrStaticLeaderElection is returned when a preferred leader is set for
+// a channel whose leader isn't elected dynamically
+var ErrStaticLeaderElection = errors.New("the leader of the channel isn't elected dynamically")
+
+// PreferredLeader is the peer, identified by its endpoint,
+// which is preferred as the leader of the org for a channel
+type PreferredLeader struct {
+	Channel  string `json:"channel"`
+	Endpoint string `json:"endpoint"`
+}
+
+// SetPreferredLeader makes the peer of the org with the given endpoint the preferred leader of the org for
+// the given channel, or restores the configured leader election strategy if endpoint is empty. If this peer
+// is the leader and another peer is preferred, it yields the leadership so that the preferred peer gets elected.
+// The preferred leader is published to the other peers of the org, which adopt it.
+func (g *GossipService) SetPreferredLeader(channelID, endpoint string) error {
+	g.lock.RLock()
+	_, joined := g.chains[channelID]
+	strategy, dynamic := g.electionStrategies[channelID]
+	le := g.leaderElection[channelID]
+	g.lock.RUnlock()
+
+	if !joined {
+		return ErrChannelNotFound
+	}
+	if !dynamic || le == nil {
+		return ErrStaticLeaderElection
+	}
+
+	record := &preferredLeaderRecord{Time: time.Now()}
+	if endpoint != "" {
+		record.Leader = g.orgPeerWithEndpoint(endpoint)
+		if record.Leader == nil {
+			return errors.Errorf("no alive peer of the org has the endpoint %s", endpoint)
+		}
+	}
+
+	g.preferredLeaders.lock.Lock()
+	defer g.preferredLeaders.lock.Unlock()
+	logger.Infof("[%s] Setting the preferred leader to '%s'", channelID, endpoint)
+	g.applyPreferredLeaderWhileLocked(channelID, endpoint, record, strategy, le)
+	return nil
+}
+
+// preferredLeaderRecord is the preferred leader of the org for a channel as set on a peer of the org
+type preferredLeaderRecord struct {
+	// Leader is the PKI-ID of the preferred leader, empty if the configured strategy was restored
+	Leader gossipcommon.PKIidType `json:"leader,omitempty"`
+	// Time is the time at which the preferred leader was set
+	Time time.Time `json:"time"`
+}
+
+// newerThan returns whether the record was set after the other one. The records set
+// at the same time are ordered by PKI-ID so that all the peers pick the same one
+func (r *preferredLeaderRecord) newerThan(other *preferredLeaderRecord) bool {
+	if other == nil {
+		return true
+	}
+	if !r.Time.Equal(other.Time) {
+		return r.Time.After(other.Time)
+	}
+	return bytes.Compare(r.Leader, other.Leader) > 0
+}
+
+// membershipMetadata is the metadata the peer publishes along with its membership information
+type membershipMetadata struct {
+	// PreferredLeaders are the preferred leaders of the channels, by channel
+	PreferredLeaders map[string]*preferredLeaderRecord `json:"preferred_leaders,omitempty"`
+}
+
+// preferredLeaders shares the preferred leaders of the channels with the other peers of the org.
+// The peer publishes in its membership metadata the preferred leader of each channel which was
+// last set on it or adopted from another peer of the org, along with the time it was set, and
+// periodically adopts the most recent preferred leaders published by the peers of the org, so
+// that all the peers of the org agree on the preferred leader of a channel.
+type preferredLeaders struct {
+	lock     sync.Mutex
+	records  map[string]*preferredLeaderRecord
+	syncOnce sync.Once
+	stopChan chan struct{}
+}
+
+// startPreferredLeadersSync periodically adopts the preferred leaders published by the peers of the org
+func (g *GossipService) startPreferredLeadersSync(interval time.Duration) {
+	g.preferredLeaders.syncOnce.Do(func() {
+		stopChan := make(chan struct{})
+		g.preferredLeaders.stopChan = stopChan
+		go func() {
+			for {
+				select {
+				case <-stopChan:
+					return
+				case <-time.After(interval):
+					g.syncPreferredLeaders()
+				}
+			}
+		}()
+	})
+}
+
+func (g *GossipService) stopPreferredLeadersSync() {
+	if g.preferredLeaders.stopChan != nil {
+		close(g.preferredLeaders.stopChan)
+		g.preferredLeaders.stopChan = nil
+	}
+}
+
+// syncPreferredLeaders adopts, for each channel whose leader is elected dynamically, the most
+// recent preferred leader published by the peers of the org if it was set after the one of this peer
+func (g *GossipService) syncPreferredLeaders() {
+	latest := make(map[string]*preferredLeaderRecord)
+	for _, member := range g.Peers() {
+		if len(member.Metadata) == 0 || !g.IsInMyOrg(member) {
+			continue
+		}
+		metadata := &membershipMetadata{}
+		if err := json.Unmarshal(member.Metadata, metadata); err != nil {
+			logger.Debugf("Ignoring the membership metadata of %s: %s", member.PKIid, err)
+			continue
+		}
+		for ch, record := range metadata.PreferredLeaders {
+			if record != nil && record.newerThan(latest[ch]) {
+				latest[ch] = record
+			}
+		}
+	}
+
+	g.preferredLeaders.lock.Lock()
+	defer g.preferredLeaders.lock.Unlock()
+	for ch, record := range latest {
+		if !record.newerThan(g.preferredLeaders.records[ch]) {
+			continue
+		}
+		g.lock.RLock()
+		strategy, dynamic := g.electionStrategies[ch]
+		le := g.leaderElection[ch]
+		g.lock.RUnlock()
+		if !dynamic || le == nil {
+			continue
+		}
+
+		var endpoint string
+		if len(record.Leader) > 0 {
+			if endpoint = g.endpointOf(record.Leader); endpoint == "" {
+				logger.Debugf("[%s] The preferred leader %s published by the org isn't known yet", ch, record.Leader)
+				continue
+			}
+		}
+		logger.Infof("[%s] Adopting the preferred leader '%s' published by the org", ch, endpoint)
+		g.applyPreferredLeaderWhileLocked(ch, endpoint, record, strategy, le)
+	}
+}
+
+// applyPreferredLeaderWhileLocked makes the peer with the given endpoint the preferred leader of the
+// channel, yields the leadership if this peer is the leader and another peer is preferred, and
+// publishes the preferred leader to the org. It must be called with the preferredLeaders lock held.
+func (g *GossipService) applyPreferredLeaderWhileLocked(channelID, endpoint string, record *preferredLeaderRecord,
+	strategy *election.PreferredLeaderStrategy, le election.LeaderElectionService) {
+	strategy.SetPreferredLeader(endpoint)
+	if endpoint != "" && le.IsLeader() && !strategy.IsPreferredLeader(g.SelfMembershipInfo().PKIid) {
+		logger.Infof("[%s] Yielding the leadership to the preferred leader %s", channelID, endpoint)
+		le.Yield()
+	}
+
+	if g.preferredLeaders.records == nil {
+		g.preferredLeaders.records = make(map[string]*preferredLeaderRecord)
+	}
+	g.preferredLeaders.records[channelID] = record
+	metadata, err := json.Marshal(&membershipMetadata{PreferredLeaders: g.preferredLeaders.records})
+	if err != nil {
+		logger.Panicf("failed marshaling the membership metadata: %s", err)
+	}
+	g.UpdateMetadata(metadata)
+}
+
+// orgPeerWithEndpoint returns the PKI-ID of this peer or of the alive
+// peer of its org with the given endpoint, or nil if there is none
+func (g *GossipService) orgPeerWithEndpoint(endpoint string) gossipcommon.PKIidType {
+	self := g.SelfMembershipInfo()
+	if endpoint == self.Endpoint || endpoint == g.internalEndpoint {
+		return self.PKIid
+	}
+	for _, member := range g.Peers() {
+		if (endpoint == member.Endpoint || endpoint == member.InternalEndpoint) && g.IsInMyOrg(member) {
+			return member.PKIid
+		}
+	}
+	return nil
+}
+
+// endpointOf returns an endpoint of this peer or of the alive
+// member with the given PKI-ID, or "" if the peer isn't known
+func (g *GossipService) endpointOf(pkiID gossipcommon.PKIidType) string {
+	for _, endpoint := range g.endpointResolver()(pkiID) {
+		if endpoint != "" {
+			return endpoint
+		}
+	}
+	return ""
+}
+
+// PreferredLeaders returns the preferred leaders of the channels
+// whose leader is elected dynamically and a leader is preferred
+func (g *GossipService) PreferredLeaders() []*PreferredLeader {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	leaders := []*PreferredLeader{}
+	for ch, strategy := range g.electionStrategies {
+		if endpoint := strategy.PreferredLeader(); endpoint != "" {
+			leaders = append(leaders, &PreferredLeader{Channel: ch, Endpoint: endpoint})
+		}
+	}
+	sort.Slice(leaders, func(i, j int) bool {
+		return leaders[i].Channel < leaders[j].Channel
+	})
+	return leaders
+}
+
+// PreferredLeaderHandler serves the preferred leaders of the channels as JSON, and sets the
+// preferred leader of a channel from a PreferredLeader sent as JSON in a PUT request. An
+// empty endpoint restores the configured leader election strategy of the channel.
+type PreferredLeaderHandler struct {
+	GossipService *GossipService
+}
+
+func (h *PreferredLeaderHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		resp.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(resp).Encode(h.GossipService.PreferredLeaders()); err != nil {
+			logger.Errorf("failed to encode the preferred leaders: %s", err)
+		}
+
+	case http.MethodPut:
+		var leader PreferredLeader
+		if err := json.NewDecoder(req.Body).Decode(&leader); err != nil {
+			http.Error(resp, errors.Wrap(err, "failed to decode the preferred leader").Error(), http.StatusBadRequest)
+			return
+		}
+		if leader.Channel == "" {
+			http.Error(resp, "the channel of the preferred leader must be specified", http.StatusBadRequest)
+			return
+		}
+
+		switch err := h.GossipService.SetPreferredLeader(leader.Channel, leader.Endpoint); err {
+		case nil:
+			resp.WriteHeader(http.StatusNoContent)
+		case ErrChannelNotFound:
+			http.Error(resp, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+		}
+
+	default:
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
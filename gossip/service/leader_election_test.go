@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/election"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/stretchr/testify/require"
+)
+
+type yieldingLeaderElection struct {
+	leader  bool
+	yielded bool
+}
+
+func (le *yieldingLeaderElection) IsLeader() bool {
+	return le.leader
+}
+
+func (le *yieldingLeaderElection) Stop() {}
+
+func (le *yieldingLeaderElection) Yield() {
+	le.yielded = true
+	le.leader = false
+}
+
+func TestPreferredLeaderHandler(t *testing.T) {
+	resolve := func(pkiID common.PKIidType) []string {
+		return []string{string(pkiID) + ":7051"}
+	}
+	le := &yieldingLeaderElection{leader: true}
+	gossip := &topologyGossip{}
+	g := &GossipService{
+		gossipSvc: gossip,
+		chains: map[string]state.GossipStateProvider{
+			"ch1": nil,
+			"ch2": nil,
+		},
+		leaderElection: map[string]election.LeaderElectionService{
+			"ch1": le,
+		},
+		electionStrategies: map[string]*election.PreferredLeaderStrategy{
+			"ch1": election.NewPreferredLeaderStrategy(election.NewChannelHashStrategy(common.ChannelID("ch1")), resolve),
+		},
+		serviceConfig:     &ServiceConfig{ElectionStrategy: election.ChannelHashStrategy},
+		anchorPeerTracker: &anchorPeerTracker{allEndpoints: map[string]map[string]struct{}{}},
+	}
+	handler := &PreferredLeaderHandler{GossipService: g}
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(method, "/gossip/election/preferredleader", strings.NewReader(body)))
+		return resp
+	}
+	preferredLeaders := func() []*PreferredLeader {
+		resp := serve(http.MethodGet, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+		var leaders []*PreferredLeader
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &leaders))
+		return leaders
+	}
+
+	require.Empty(t, preferredLeaders())
+
+	// The peer itself is preferred, it keeps the leadership
+	resp := serve(http.MethodPut, `{"channel": "ch1", "endpoint": "p0:7051"}`)
+	require.Equal(t, http.StatusNoContent, resp.Code)
+	require.False(t, le.yielded)
+	require.Equal(t, []*PreferredLeader{{Channel: "ch1", Endpoint: "p0:7051"}}, preferredLeaders())
+
+	// Another peer is preferred, the peer yields the leadership
+	resp = serve(http.MethodPut, `{"channel": "ch1", "endpoint": "p1:7051"}`)
+	require.Equal(t, http.StatusNoContent, resp.Code)
+	require.True(t, le.yielded)
+	require.Equal(t, []*PreferredLeader{{Channel: "ch1", Endpoint: "p1:7051"}}, preferredLeaders())
+
+	// The preferred leader is published to the org
+	metadata := &membershipMetadata{}
+	require.NoError(t, json.Unmarshal(gossip.metadata, metadata))
+	require.Len(t, metadata.PreferredLeaders, 1)
+	require.Equal(t, common.PKIidType("p1"), metadata.PreferredLeaders["ch1"].Leader)
+
+	// A peer which isn't known can't be preferred
+	resp = serve(http.MethodPut, `{"channel": "ch1", "endpoint": "p9:7051"}`)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Equal(t, "no alive peer of the org has the endpoint p9:7051\n", resp.Body.String())
+	require.Equal(t, []*PreferredLeader{{Channel: "ch1", Endpoint: "p1:7051"}}, preferredLeaders())
+
+	topology, err := g.Topology("ch1")
+	require.NoError(t, err)
+	require.Equal(t, &LeaderElection{
+		Mode:            DynamicLeaderElection,
+		Strategy:        election.ChannelHashStrategy,
+		PreferredLeader: "p1:7051",
+	}, topology.Channels[0].LeaderElection)
+
+	// An empty endpoint clears the preferred leader
+	resp = serve(http.MethodPut, `{"channel": "ch1"}`)
+	require.Equal(t, http.StatusNoContent, resp.Code)
+	require.Empty(t, preferredLeaders())
+	require.NoError(t, json.Unmarshal(gossip.metadata, metadata))
+	require.Empty(t, metadata.PreferredLeaders["ch1"].Leader)
+
+	resp = serve(http.MethodPut, `{"channel": "ch2", "endpoint": "p1:7051"}`)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Equal(t, "the leader of the channel isn't elected dynamically\n", resp.Body.String())
+
+	resp = serve(http.MethodPut, `{"channel": "ch3", "endpoint": "p1:7051"}`)
+	require.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodPut, `{"endpoint": "p1:7051"}`)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Equal(t, "the channel of the preferred leader must be specified\n", resp.Body.String())
+
+	resp = serve(http.MethodPut, `{"channel":`)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Contains(t, resp.Body.String(), "failed to decode the preferred leader")
+
+	resp = serve(http.MethodPost, "")
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
+
+func TestEndpointResolver(t *testing.T) {
+	g := &GossipService{
+		gossipSvc:        &topologyGossip{},
+		internalEndpoint: "p0.internal:7051",
+	}
+	resolve := g.endpointResolver()
+
+	require.Equal(t, []string{"p0:7051", "p0.internal:7051"}, resolve(common.PKIidType("p0")))
+	require.Equal(t, []string{"p1:7051", "p1.internal:7051"}, resolve(common.PKIidType("p1")))
+	require.Equal(t, []string{"p2:7051", ""}, resolve(common.PKIidType("p2")))
+	require.Nil(t, resolve(common.PKIidType("p3")))
+
+	// The priorities are resolved through the membership, whatever the channels of the peers
+	strategy := election.NewPriorityStrategy(map[string]int{"p1.internal:7051": 10, "p2:7051": 5}, resolve)
+	require.True(t, strategy.Prefers(common.PKIidType("p1"), common.PKIidType("p2")))
+	require.True(t, strategy.Prefers(common.PKIidType("p2"), common.PKIidType("p0")))
+	require.False(t, strategy.Prefers(common.PKIidType("p3"), common.PKIidType("p2")))
+}
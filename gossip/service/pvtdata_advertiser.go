@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	gproto "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/gossip/common"
+	privdatacommon "github.com/hyperledger/fabric/gossip/privdata/common"
+)
+
+// missingPvtDataAdvertiser publishes, in the metadata of the chaincodes of the StateInfo message
+// of the peer in a channel, the collections for which private data is missing on the peer, so that
+// the clients of the discovery service can tell which peers hold the private data of a collection
+type missingPvtDataAdvertiser struct {
+	channelID common.ChannelID
+	publish   func(chaincodes []*gproto.Chaincode, channelID common.ChannelID)
+
+	lock       sync.Mutex
+	chaincodes []*gproto.Chaincode
+	// missing maps the chaincodes to the collections with missing
+	// private data and the lowest block number of the missing private data
+	missing map[string]map[string]uint64
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopChan  chan struct{}
+}
+
+func newMissingPvtDataAdvertiser(channelID common.ChannelID, publish func([]*gproto.Chaincode, common.ChannelID)) *missingPvtDataAdvertiser {
+	return &missingPvtDataAdvertiser{
+		channelID: channelID,
+		publish:   publish,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// UpdateChaincodes publishes the given chaincodes along with the private data missing on the peer
+func (a *missingPvtDataAdvertiser) UpdateChaincodes(chaincodes []*gproto.Chaincode) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.chaincodes = chaincodes
+	a.publishWhileLocked()
+}
+
+// start periodically retrieves the private data missing on the peer from the
+// tracker, and publishes the chaincodes again when the missing private data changes
+func (a *missingPvtDataAdvertiser) start(getTracker func() (ledger.MissingPvtDataTracker, error), interval time.Duration) {
+	a.startOnce.Do(func() {
+		go func() {
+			for {
+				select {
+				case <-a.stopChan:
+					return
+				case <-time.After(interval):
+					a.refresh(getTracker)
+				}
+			}
+		}()
+	})
+}
+
+func (a *missingPvtDataAdvertiser) stop() {
+	if a == nil {
+		return
+	}
+	a.stopOnce.Do(func() {
+		close(a.stopChan)
+	})
+}
+
+func (a *missingPvtDataAdvertiser) refresh(getTracker func() (ledger.MissingPvtDataTracker, error)) {
+	tracker, err := getTracker()
+	if err != nil || tracker == nil {
+		logger.Warningf("[%s] Failed to get the missing private data tracker: %v", a.channelID, err)
+		return
+	}
+	summaries, err := tracker.GetMissingPvtDataSummary()
+	if err != nil {
+		logger.Warningf("[%s] Failed to get the missing private data: %s", a.channelID, err)
+		return
+	}
+	a.updateMissingPvtData(summaries)
+}
+
+func (a *missingPvtDataAdvertiser) updateMissingPvtData(summaries []*ledger.CollMissingPvtDataSummary) {
+	missing := map[string]map[string]uint64{}
+	for _, summary := range summaries {
+		if missing[summary.Namespace] == nil {
+			missing[summary.Namespace] = map[string]uint64{}
+		}
+		missing[summary.Namespace][summary.Collection] = summary.OldestBlockNum
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if reflect.DeepEqual(missing, a.missing) {
+		return
+	}
+	a.missing = missing
+	if a.chaincodes != nil {
+		logger.Debugf("[%s] Publishing the collections with missing private data: %v", a.channelID, missing)
+		a.publishWhileLocked()
+	}
+}
+
+func (a *missingPvtDataAdvertiser) publishWhileLocked() {
+	chaincodes := make([]*gproto.Chaincode, 0, len(a.chaincodes))
+	for _, cc := range a.chaincodes {
+		if cc == nil {
+			continue
+		}
+		published := &gproto.Chaincode{
+			Name:     cc.Name,
+			Version:  cc.Version,
+			Metadata: cc.Metadata,
+		}
+		if collections := a.missing[cc.Name]; len(collections) > 0 {
+			metadata, err := json.Marshal(&privdatacommon.ChaincodeMetadata{MissingPvtData: collections})
+			if err != nil {
+				logger.Panicf("failed marshaling the metadata of chaincode %s: %s", cc.Name, err)
+			}
+			published.Metadata = metadata
+		}
+		chaincodes = append(chaincodes, published)
+	}
+	a.publish(chaincodes, a.channelID)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	gproto "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/gossip/common"
+	privdatacommon "github.com/hyperledger/fabric/gossip/privdata/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type summaryTracker struct {
+	ledger.MissingPvtDataTracker
+	lock      sync.Mutex
+	summaries []*ledger.CollMissingPvtDataSummary
+	err       error
+}
+
+func (t *summaryTracker) GetMissingPvtDataSummary() ([]*ledger.CollMissingPvtDataSummary, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.summaries, t.err
+}
+
+func (t *summaryTracker) set(summaries []*ledger.CollMissingPvtDataSummary, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.summaries, t.err = summaries, err
+}
+
+func TestMissingPvtDataAdvertiser(t *testing.T) {
+	published := make(chan []*gproto.Chaincode, 10)
+	advertiser := newMissingPvtDataAdvertiser(common.ChannelID("ch1"), func(chaincodes []*gproto.Chaincode, channelID common.ChannelID) {
+		require.Equal(t, common.ChannelID("ch1"), channelID)
+		published <- chaincodes
+	})
+	missingPvtData := func(cc *gproto.Chaincode) map[string]uint64 {
+		md, err := privdatacommon.ChaincodeMetadataOf(cc)
+		require.NoError(t, err)
+		return md.MissingPvtData
+	}
+
+	tracker := &summaryTracker{summaries: []*ledger.CollMissingPvtDataSummary{
+		{Namespace: "cc1", Collection: "coll1", Count: 2, OldestBlockNum: 5},
+		{Namespace: "cc1", Collection: "coll2", Count: 1, OldestBlockNum: 7},
+		{Namespace: "cc3", Collection: "coll1", Count: 1, OldestBlockNum: 3},
+	}}
+	getTracker := func() (ledger.MissingPvtDataTracker, error) { return tracker, nil }
+
+	// the chaincodes are not published before they are known
+	advertiser.refresh(getTracker)
+	require.Empty(t, published)
+
+	advertiser.UpdateChaincodes([]*gproto.Chaincode{{Name: "cc1", Version: "1"}, {Name: "cc2", Version: "2"}})
+	chaincodes := <-published
+	require.Len(t, chaincodes, 2)
+	require.Equal(t, "cc1", chaincodes[0].Name)
+	require.Equal(t, "1", chaincodes[0].Version)
+	require.Equal(t, map[string]uint64{"coll1": 5, "coll2": 7}, missingPvtData(chaincodes[0]))
+	require.Equal(t, "cc2", chaincodes[1].Name)
+	require.Empty(t, chaincodes[1].Metadata)
+
+	// the chaincodes are published again only if the missing private data changes
+	advertiser.refresh(getTracker)
+	require.Empty(t, published)
+
+	tracker.set(nil, errors.New("ledger closed"))
+	advertiser.refresh(getTracker)
+	require.Empty(t, published)
+
+	tracker.set([]*ledger.CollMissingPvtDataSummary{{Namespace: "cc1", Collection: "coll2", Count: 1, OldestBlockNum: 7}}, nil)
+	advertiser.start(getTracker, time.Millisecond)
+	defer advertiser.stop()
+	chaincodes = <-published
+	require.Len(t, chaincodes, 2)
+	require.Equal(t, map[string]uint64{"coll2": 7}, missingPvtData(chaincodes[0]))
+
+	tracker.set(nil, nil)
+	chaincodes = <-published
+	require.Len(t, chaincodes, 2)
+	require.Empty(t, chaincodes[0].Metadata)
+}
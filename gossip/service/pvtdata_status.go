@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	gossipprivdata "github.com/hyperledger/fabric/gossip/privdata"
+	"github.com/pkg/errors"
+)
+
+// ErrChannelNotFound is returned when the status of a channel
+// the peer has not joined is requested
+var ErrChannelNotFound = errors.New("channel not found")
+
+// PvtDataReconciliationStatus returns the status of the reconciliation of the missing private
+// data of the given channel, or of all the channels of the peer if channelID is empty
+func (g *GossipService) PvtDataReconciliationStatus(channelID string) ([]*gossipprivdata.ReconciliationStatus, error) {
+	g.lock.RLock()
+	reconcilers := make(map[string]gossipprivdata.PvtDataReconciler, len(g.privateHandlers))
+	for ch, handler := range g.privateHandlers {
+		if channelID == "" || ch == channelID {
+			reconcilers[ch] = handler.reconciler
+		}
+	}
+	g.lock.RUnlock()
+
+	if channelID != "" && len(reconcilers) == 0 {
+		return nil, ErrChannelNotFound
+	}
+
+	statuses := []*gossipprivdata.ReconciliationStatus{}
+	for ch, reconciler := range reconcilers {
+		status, err := reconciler.Status()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get the private data reconciliation status of channel %s", ch)
+		}
+		status.Channel = ch
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Channel < statuses[j].Channel
+	})
+	return statuses, nil
+}
+
+// PvtDataStatusHandler serves the status of the reconciliation of the missing
+// private data as JSON. The optional query parameter 'channel' restricts the
+// status to a single channel.
+type PvtDataStatusHandler struct {
+	GossipService *GossipService
+}
+
+func (h *PvtDataStatusHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses, err := h.GossipService.PvtDataReconciliationStatus(req.URL.Query().Get("channel"))
+	switch {
+	case err == ErrChannelNotFound:
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Errorf("failed to get the private data reconciliation status: %s", err)
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(statuses); err != nil {
+		logger.Errorf("failed to encode the private data reconciliation status: %s", err)
+	}
+}
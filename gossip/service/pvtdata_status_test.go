@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gossipprivdata "github.com/hyperledger/fabric/gossip/privdata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type statusReconciler struct {
+	gossipprivdata.NoOpReconciler
+	status *gossipprivdata.ReconciliationStatus
+	err    error
+}
+
+func (r *statusReconciler) Status() (*gossipprivdata.ReconciliationStatus, error) {
+	return r.status, r.err
+}
+
+func TestPvtDataStatusHandler(t *testing.T) {
+	g := &GossipService{
+		privateHandlers: map[string]privateHandler{
+			"ch2": {reconciler: &statusReconciler{status: &gossipprivdata.ReconciliationStatus{Enabled: true, LastReconciled: 3}}},
+			"ch1": {reconciler: &gossipprivdata.NoOpReconciler{}},
+		},
+	}
+	handler := &PvtDataStatusHandler{GossipService: g}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, target, nil))
+		return resp
+	}
+
+	resp := get("/pvtdata/status")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	var statuses []*gossipprivdata.ReconciliationStatus
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statuses))
+	require.Equal(t, []*gossipprivdata.ReconciliationStatus{
+		{Channel: "ch1"},
+		{Channel: "ch2", Enabled: true, LastReconciled: 3},
+	}, statuses)
+
+	resp = get("/pvtdata/status?channel=ch2")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 1)
+	require.Equal(t, "ch2", statuses[0].Channel)
+
+	resp = get("/pvtdata/status?channel=ch3")
+	require.Equal(t, http.StatusNotFound, resp.Code)
+	require.Equal(t, "channel not found\n", resp.Body.String())
+
+	g.privateHandlers["ch2"] = privateHandler{reconciler: &statusReconciler{err: errors.New("ledger closed")}}
+	resp = get("/pvtdata/status")
+	require.Equal(t, http.StatusInternalServerError, resp.Code)
+	require.Equal(t, "failed to get the private data reconciliation status of channel ch2: ledger closed\n", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/pvtdata/status", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
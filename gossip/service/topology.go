@@ -0,0 +1,234 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+)
+
+// Leader election modes of the channels of the peer
+const (
+	// DynamicLeaderElection is the mode in which the leader of the org is elected
+	DynamicLeaderElection = "dynamic"
+	// StaticLeaderElection is the mode in which the leader of the org is set in the configuration
+	StaticLeaderElection = "static"
+)
+
+// Topology is the gossip network as seen by the peer
+type Topology struct {
+	Self *Member `json:"self"`
+	// Channels are the channels the peer has joined
+	Channels []*ChannelTopology `json:"channels"`
+	// DeadMembers are the members of the network which are considered dead
+	DeadMembers []*Member `json:"dead_members"`
+}
+
+// Member is a member of the gossip network known to the peer
+type Member struct {
+	PKIID            string `json:"pki_id"`
+	Endpoint         string `json:"endpoint"`
+	InternalEndpoint string `json:"internal_endpoint,omitempty"`
+	// LedgerHeight is the ledger height the member published in the channel
+	LedgerHeight uint64 `json:"ledger_height,omitempty"`
+	// LastSeen is the time the last alive message of the member was received
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+	// Connected is whether the peer has a connection to the member
+	Connected bool `json:"connected"`
+}
+
+// ChannelTopology is the gossip network of a channel as seen by the peer
+type ChannelTopology struct {
+	Channel string `json:"channel"`
+	// LedgerHeight is the ledger height of the peer in the channel
+	LedgerHeight   uint64          `json:"ledger_height"`
+	LeaderElection *LeaderElection `json:"leader_election"`
+	// Members are the alive members of the channel, excluding the peer itself
+	Members     []*Member     `json:"members"`
+	AnchorPeers []*AnchorPeer `json:"anchor_peers"`
+}
+
+// LeaderElection is the state of the election of the peer which pulls
+// the blocks of a channel from the ordering service on behalf of the org
+type LeaderElection struct {
+	// Mode is either DynamicLeaderElection or StaticLeaderElection
+	Mode string `json:"mode"`
+	// Leader is whether the peer is the leader
+	Leader bool `json:"leader"`
+	// Strategy is the strategy which ranks the candidates to the leadership when it is dynamic
+	Strategy string `json:"strategy,omitempty"`
+	// PreferredLeader is the endpoint of the peer set as the preferred leader, if any
+	PreferredLeader string `json:"preferred_leader,omitempty"`
+}
+
+// AnchorPeer is an anchor peer of a channel
+type AnchorPeer struct {
+	Endpoint string `json:"endpoint"`
+	// Alive is whether a member with the endpoint of the anchor peer is considered alive
+	Alive bool `json:"alive"`
+	// Reachable is whether the anchor peer responded to a probe
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Topology returns the gossip network as seen by the peer, restricted to the
+// given channel, or including all the channels of the peer if channelID is empty.
+// The anchor peers of the channels are probed in order to check their reachability.
+func (g *GossipService) Topology(channelID string) (*Topology, error) {
+	g.lock.RLock()
+	var channels []*ChannelTopology
+	for ch := range g.chains {
+		if channelID == "" || ch == channelID {
+			channels = append(channels, &ChannelTopology{
+				Channel:        ch,
+				LeaderElection: g.leaderElectionState(ch),
+			})
+		}
+	}
+	g.lock.RUnlock()
+
+	if channelID != "" && len(channels) == 0 {
+		return nil, ErrChannelNotFound
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].Channel < channels[j].Channel
+	})
+
+	connected := map[string]struct{}{}
+	for _, conn := range g.Connections() {
+		connected[string(conn.ID)] = struct{}{}
+	}
+	lastSeen := map[string]time.Time{}
+	aliveEndpoints := map[string]struct{}{}
+	topology := &Topology{
+		Channels:    channels,
+		DeadMembers: []*Member{},
+	}
+	for _, m := range g.KnownMembers() {
+		lastSeen[string(m.PKIid)] = m.LastSeen
+		if m.Alive {
+			aliveEndpoints[m.Endpoint] = struct{}{}
+			aliveEndpoints[m.InternalEndpoint] = struct{}{}
+			continue
+		}
+		topology.DeadMembers = append(topology.DeadMembers, newMember(m.NetworkMember, lastSeen, connected))
+	}
+	sortMembers(topology.DeadMembers)
+
+	self := g.SelfMembershipInfo()
+	topology.Self = newMember(self, lastSeen, connected)
+
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		if stateInfo := g.SelfChannelInfo(common.ChannelID(ch.Channel)); stateInfo != nil {
+			ch.LedgerHeight = stateInfo.GetStateInfo().GetProperties().GetLedgerHeight()
+		}
+
+		ch.Members = []*Member{}
+		for _, m := range g.PeersOfChannel(common.ChannelID(ch.Channel)) {
+			ch.Members = append(ch.Members, newMember(m, lastSeen, connected))
+		}
+		sortMembers(ch.Members)
+
+		ch.AnchorPeers = []*AnchorPeer{}
+		for _, endpoint := range g.anchorPeerTracker.endpoints(ch.Channel) {
+			_, alive := aliveEndpoints[endpoint]
+			if endpoint == self.Endpoint || endpoint == self.InternalEndpoint {
+				alive = true
+			}
+			anchorPeer := &AnchorPeer{Endpoint: endpoint, Alive: alive}
+			ch.AnchorPeers = append(ch.AnchorPeers, anchorPeer)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := g.Probe(anchorPeer.Endpoint); err != nil {
+					anchorPeer.Error = err.Error()
+					return
+				}
+				anchorPeer.Reachable = true
+			}()
+		}
+	}
+	wg.Wait()
+
+	return topology, nil
+}
+
+// leaderElectionState returns the state of the leader election of the given channel.
+// It must be called while holding the lock of the gossip service.
+func (g *GossipService) leaderElectionState(channelID string) *LeaderElection {
+	if le, exists := g.leaderElection[channelID]; exists {
+		state := &LeaderElection{
+			Mode:     DynamicLeaderElection,
+			Leader:   le.IsLeader(),
+			Strategy: g.serviceConfig.ElectionStrategy,
+		}
+		if strategy, exists := g.electionStrategies[channelID]; exists {
+			state.PreferredLeader = strategy.PreferredLeader()
+		}
+		return state
+	}
+	return &LeaderElection{Mode: StaticLeaderElection, Leader: g.serviceConfig.OrgLeader}
+}
+
+func newMember(m discovery.NetworkMember, lastSeen map[string]time.Time, connected map[string]struct{}) *Member {
+	member := &Member{
+		PKIID:            m.PKIid.String(),
+		Endpoint:         m.Endpoint,
+		InternalEndpoint: m.InternalEndpoint,
+	}
+	if m.Properties != nil {
+		member.LedgerHeight = m.Properties.LedgerHeight
+	}
+	if ts, exists := lastSeen[string(m.PKIid)]; exists {
+		member.LastSeen = &ts
+	}
+	_, member.Connected = connected[string(m.PKIid)]
+	return member
+}
+
+func sortMembers(members []*Member) {
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Endpoint < members[j].Endpoint
+	})
+}
+
+// TopologyHandler serves the gossip network as seen by the peer as JSON.
+// The optional query parameter 'channel' restricts the topology to a
+// single channel.
+type TopologyHandler struct {
+	GossipService *GossipService
+}
+
+func (h *TopologyHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	topology, err := h.GossipService.Topology(req.URL.Query().Get("channel"))
+	switch {
+	case err == ErrChannelNotFound:
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Errorf("failed to get the gossip topology: %s", err)
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(topology); err != nil {
+		logger.Errorf("failed to encode the gossip topology: %s", err)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gproto "github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/election"
+	"github.com/hyperledger/fabric/gossip/protoext"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type topologyGossip struct {
+	gossipSvc
+	members  []discovery.MemberState
+	metadata []byte
+}
+
+func (g *topologyGossip) SelfMembershipInfo() discovery.NetworkMember {
+	return discovery.NetworkMember{PKIid: common.PKIidType("p0"), Endpoint: "p0:7051"}
+}
+
+func (g *topologyGossip) SelfChannelInfo(channelID common.ChannelID) *protoext.SignedGossipMessage {
+	if string(channelID) != "ch1" {
+		return nil
+	}
+	msg, _ := protoext.NoopSign(&gproto.GossipMessage{
+		Content: &gproto.GossipMessage_StateInfo{
+			StateInfo: &gproto.StateInfo{Properties: &gproto.Properties{LedgerHeight: 10}},
+		},
+	})
+	return msg
+}
+
+func (g *topologyGossip) PeersOfChannel(channelID common.ChannelID) []discovery.NetworkMember {
+	if string(channelID) != "ch1" {
+		return nil
+	}
+	return []discovery.NetworkMember{
+		{PKIid: common.PKIidType("p2"), Endpoint: "p2:7051", Properties: &gproto.Properties{LedgerHeight: 9}},
+		{PKIid: common.PKIidType("p1"), Endpoint: "p1:7051", Properties: &gproto.Properties{LedgerHeight: 10}},
+	}
+}
+
+func (g *topologyGossip) Peers() []discovery.NetworkMember {
+	return []discovery.NetworkMember{
+		{PKIid: common.PKIidType("p1"), Endpoint: "p1:7051", InternalEndpoint: "p1.internal:7051"},
+		{PKIid: common.PKIidType("p2"), Endpoint: "p2:7051"},
+	}
+}
+
+func (g *topologyGossip) IsInMyOrg(member discovery.NetworkMember) bool {
+	return true
+}
+
+func (g *topologyGossip) UpdateMetadata(metadata []byte) {
+	g.metadata = metadata
+}
+
+func (g *topologyGossip) KnownMembers() []discovery.MemberState {
+	return g.members
+}
+
+func (g *topologyGossip) Connections() []*protoext.ConnectionInfo {
+	return []*protoext.ConnectionInfo{{ID: common.PKIidType("p1"), Endpoint: "p1:7051"}}
+}
+
+func (g *topologyGossip) Probe(endpoint string) error {
+	if endpoint == "p3:7051" {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+type staticLeaderElection bool
+
+func (le staticLeaderElection) IsLeader() bool {
+	return bool(le)
+}
+
+func (staticLeaderElection) Stop() {}
+
+func (staticLeaderElection) Yield() {}
+
+func TestTopologyHandler(t *testing.T) {
+	lastSeen := time.Now().Add(-time.Second).UTC().Round(0)
+	g := &GossipService{
+		gossipSvc: &topologyGossip{
+			members: []discovery.MemberState{
+				{NetworkMember: discovery.NetworkMember{PKIid: common.PKIidType("p1"), Endpoint: "p1:7051"}, Alive: true, LastSeen: lastSeen},
+				{NetworkMember: discovery.NetworkMember{PKIid: common.PKIidType("p2"), Endpoint: "p2:7051"}, Alive: true, LastSeen: lastSeen},
+				{NetworkMember: discovery.NetworkMember{PKIid: common.PKIidType("p3"), Endpoint: "p3:7051"}, LastSeen: lastSeen},
+			},
+		},
+		chains: map[string]state.GossipStateProvider{
+			"ch1": nil,
+			"ch2": nil,
+		},
+		leaderElection: map[string]election.LeaderElectionService{
+			"ch1": staticLeaderElection(true),
+		},
+		serviceConfig: &ServiceConfig{},
+		anchorPeerTracker: &anchorPeerTracker{allEndpoints: map[string]map[string]struct{}{
+			"ch1": {"p3:7051": {}, "p1:7051": {}},
+		}},
+	}
+	handler := &TopologyHandler{GossipService: g}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, target, nil))
+		return resp
+	}
+
+	resp := get("/gossip/topology")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	topology := &Topology{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), topology))
+	require.Equal(t, &Topology{
+		Self: &Member{PKIID: "7030", Endpoint: "p0:7051"},
+		Channels: []*ChannelTopology{
+			{
+				Channel:        "ch1",
+				LedgerHeight:   10,
+				LeaderElection: &LeaderElection{Mode: DynamicLeaderElection, Leader: true},
+				Members: []*Member{
+					{PKIID: "7031", Endpoint: "p1:7051", LedgerHeight: 10, LastSeen: &lastSeen, Connected: true},
+					{PKIID: "7032", Endpoint: "p2:7051", LedgerHeight: 9, LastSeen: &lastSeen},
+				},
+				AnchorPeers: []*AnchorPeer{
+					{Endpoint: "p1:7051", Alive: true, Reachable: true},
+					{Endpoint: "p3:7051", Error: "connection refused"},
+				},
+			},
+			{
+				Channel:        "ch2",
+				LeaderElection: &LeaderElection{Mode: StaticLeaderElection},
+				Members:        []*Member{},
+				AnchorPeers:    []*AnchorPeer{},
+			},
+		},
+		DeadMembers: []*Member{
+			{PKIID: "7033", Endpoint: "p3:7051", LastSeen: &lastSeen},
+		},
+	}, topology)
+
+	resp = get("/gossip/topology?channel=ch2")
+	require.Equal(t, http.StatusOK, resp.Code)
+	topology = &Topology{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), topology))
+	require.Len(t, topology.Channels, 1)
+	require.Equal(t, "ch2", topology.Channels[0].Channel)
+
+	resp = get("/gossip/topology?channel=ch3")
+	require.Equal(t, http.StatusNotFound, resp.Code)
+	require.Equal(t, "channel not found\n", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/gossip/topology", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
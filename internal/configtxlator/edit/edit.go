@@ -0,0 +1,368 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/internal/configtxlator/update"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// Editor applies high level modifications to the config of a channel and
+// produces the config update transitioning to the modified config
+type Editor struct {
+	channelID string
+	original  *cb.Config
+	updated   *cb.Config
+}
+
+// NewEditor creates an Editor for the config contained in a config block
+func NewEditor(block *cb.Block) (*Editor, error) {
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to extract config envelope from block")
+	}
+
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config envelope payload")
+	}
+
+	if payload.Header == nil {
+		return nil, errors.New("config envelope has no header")
+	}
+
+	channelHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal channel header")
+	}
+
+	if channelHeader.Type != int32(cb.HeaderType_CONFIG) {
+		return nil, errors.Errorf("block does not contain a config transaction, but a transaction of type %s", cb.HeaderType(channelHeader.Type))
+	}
+
+	configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config envelope")
+	}
+
+	if configEnvelope.Config == nil || configEnvelope.Config.ChannelGroup == nil {
+		return nil, errors.New("config envelope has no channel group")
+	}
+
+	return &Editor{
+		channelID: channelHeader.ChannelId,
+		original:  configEnvelope.Config,
+		updated:   proto.Clone(configEnvelope.Config).(*cb.Config),
+	}, nil
+}
+
+// ChannelID returns the ID of the channel of the config
+func (e *Editor) ChannelID() string {
+	return e.channelID
+}
+
+// Config returns the modified config
+func (e *Editor) Config() *cb.Config {
+	return e.updated
+}
+
+// ConfigUpdate returns the config update transitioning from the original
+// config to the modified config
+func (e *Editor) ConfigUpdate() (*cb.ConfigUpdate, error) {
+	configUpdate, err := update.Compute(e.original, e.updated)
+	if err != nil {
+		return nil, err
+	}
+	configUpdate.ChannelId = e.channelID
+	return configUpdate, nil
+}
+
+// Envelope returns an unsigned CONFIG_UPDATE envelope carrying the config
+// update, ready to be signed with 'peer channel signconfigtx'
+func (e *Editor) Envelope() (*cb.Envelope, error) {
+	configUpdate, err := e.ConfigUpdate()
+	if err != nil {
+		return nil, err
+	}
+
+	configUpdateBytes, err := proto.Marshal(configUpdate)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal config update")
+	}
+
+	return protoutil.CreateSignedEnvelope(
+		cb.HeaderType_CONFIG_UPDATE,
+		e.channelID,
+		nil,
+		&cb.ConfigUpdateEnvelope{ConfigUpdate: configUpdateBytes},
+		0,
+		0,
+	)
+}
+
+// AddOrg adds an organization, e.g. as printed by 'configtxgen -printOrg',
+// to the group at the given path relative to the channel group, such as
+// [Application], [Orderer] or [Consortiums, SampleConsortium]
+func (e *Editor) AddOrg(path []string, name string, org *cb.ConfigGroup) error {
+	group, err := e.group(path...)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := group.Groups[name]; ok {
+		return errors.Errorf("organization %s already exists", name)
+	}
+
+	org = proto.Clone(org).(*cb.ConfigGroup)
+	if len(path) == 0 || path[0] != channelconfig.OrdererGroupKey {
+		// orderer endpoints are only valid for orderer organizations
+		delete(org.Values, channelconfig.EndpointsKey)
+	}
+	if org.ModPolicy == "" {
+		org.ModPolicy = channelconfig.AdminsPolicyKey
+	}
+
+	group.Groups[name] = org
+	return nil
+}
+
+// RemoveOrg removes an organization from the group at the given path
+// relative to the channel group
+func (e *Editor) RemoveOrg(path []string, name string) error {
+	group, err := e.group(path...)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := group.Groups[name]; !ok {
+		return errors.Errorf("organization %s not found", name)
+	}
+
+	delete(group.Groups, name)
+	return nil
+}
+
+// SetAnchorPeers replaces the anchor peers of an application organization
+func (e *Editor) SetAnchorPeers(org string, anchorPeers []*pb.AnchorPeer) error {
+	group, err := e.group(channelconfig.ApplicationGroupKey, org)
+	if err != nil {
+		return err
+	}
+
+	return setStandardValue(group, channelconfig.AnchorPeersValue(anchorPeers))
+}
+
+// SetBatchSize changes the batch size of the orderer. Zero values leave
+// the corresponding settings unchanged.
+func (e *Editor) SetBatchSize(maxMessageCount, absoluteMaxBytes, preferredMaxBytes uint32) error {
+	group, err := e.group(channelconfig.OrdererGroupKey)
+	if err != nil {
+		return err
+	}
+
+	batchSize := &ab.BatchSize{}
+	if err := unmarshalValue(group, channelconfig.BatchSizeKey, batchSize); err != nil {
+		return err
+	}
+
+	if maxMessageCount != 0 {
+		batchSize.MaxMessageCount = maxMessageCount
+	}
+	if absoluteMaxBytes != 0 {
+		batchSize.AbsoluteMaxBytes = absoluteMaxBytes
+	}
+	if preferredMaxBytes != 0 {
+		batchSize.PreferredMaxBytes = preferredMaxBytes
+	}
+
+	if batchSize.MaxMessageCount == 0 {
+		return errors.New("max message count must be greater than 0")
+	}
+	if batchSize.PreferredMaxBytes > batchSize.AbsoluteMaxBytes {
+		return errors.Errorf("preferred max bytes (%d) cannot exceed absolute max bytes (%d)", batchSize.PreferredMaxBytes, batchSize.AbsoluteMaxBytes)
+	}
+
+	return setStandardValue(group, channelconfig.BatchSizeValue(batchSize.MaxMessageCount, batchSize.AbsoluteMaxBytes, batchSize.PreferredMaxBytes))
+}
+
+// SetBatchTimeout changes the batch timeout of the orderer
+func (e *Editor) SetBatchTimeout(timeout string) error {
+	group, err := e.group(channelconfig.OrdererGroupKey)
+	if err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return errors.Wrapf(err, "invalid batch timeout %s", timeout)
+	}
+	if duration <= 0 {
+		return errors.Errorf("batch timeout %s must be positive", timeout)
+	}
+
+	return setStandardValue(group, channelconfig.BatchTimeoutValue(timeout))
+}
+
+// AddConsenter adds a consenter to the etcdraft consensus metadata
+func (e *Editor) AddConsenter(consenter *etcdraft.Consenter) error {
+	return e.updateConsenters(func(consenters []*etcdraft.Consenter) ([]*etcdraft.Consenter, error) {
+		for _, c := range consenters {
+			if c.Host == consenter.Host && c.Port == consenter.Port {
+				return nil, errors.Errorf("consenter %s:%d already exists", consenter.Host, consenter.Port)
+			}
+		}
+		return append(consenters, consenter), nil
+	})
+}
+
+// RemoveConsenter removes a consenter from the etcdraft consensus metadata
+func (e *Editor) RemoveConsenter(host string, port uint32) error {
+	return e.updateConsenters(func(consenters []*etcdraft.Consenter) ([]*etcdraft.Consenter, error) {
+		var res []*etcdraft.Consenter
+		for _, c := range consenters {
+			if c.Host == host && c.Port == port {
+				continue
+			}
+			res = append(res, c)
+		}
+
+		if len(res) == len(consenters) {
+			return nil, errors.Errorf("consenter %s:%d not found", host, port)
+		}
+		if len(res) == 0 {
+			return nil, errors.New("cannot remove the last consenter")
+		}
+		return res, nil
+	})
+}
+
+func (e *Editor) updateConsenters(update func([]*etcdraft.Consenter) ([]*etcdraft.Consenter, error)) error {
+	group, err := e.group(channelconfig.OrdererGroupKey)
+	if err != nil {
+		return err
+	}
+
+	consensusType := &ab.ConsensusType{}
+	if err := unmarshalValue(group, channelconfig.ConsensusTypeKey, consensusType); err != nil {
+		return err
+	}
+
+	if consensusType.Type != "etcdraft" {
+		return errors.Errorf("consensus type is %s, not etcdraft", consensusType.Type)
+	}
+
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+		return errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+
+	metadata.Consenters, err = update(metadata.Consenters)
+	if err != nil {
+		return err
+	}
+
+	consensusType.Metadata, err = proto.Marshal(metadata)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal etcdraft metadata")
+	}
+
+	return setValue(group, channelconfig.ConsensusTypeKey, consensusType)
+}
+
+// SetCapabilities adds and removes capabilities of the group at the given
+// path relative to the channel group, such as [] for the channel
+// capabilities or [Application] for the application capabilities
+func (e *Editor) SetCapabilities(path []string, add, remove []string) error {
+	group, err := e.group(path...)
+	if err != nil {
+		return err
+	}
+
+	capabilities := &cb.Capabilities{}
+	if _, ok := group.Values[channelconfig.CapabilitiesKey]; ok {
+		if err := unmarshalValue(group, channelconfig.CapabilitiesKey, capabilities); err != nil {
+			return err
+		}
+	}
+
+	required := map[string]bool{}
+	for name := range capabilities.Capabilities {
+		required[name] = true
+	}
+	for _, name := range remove {
+		if !required[name] {
+			return errors.Errorf("capability %s is not enabled", name)
+		}
+		delete(required, name)
+	}
+	for _, name := range add {
+		required[name] = true
+	}
+
+	return setStandardValue(group, channelconfig.CapabilitiesValue(required))
+}
+
+// group returns the group of the modified config at the given path
+// relative to the channel group
+func (e *Editor) group(path ...string) (*cb.ConfigGroup, error) {
+	group := e.updated.ChannelGroup
+	for i, name := range path {
+		subGroup, ok := group.Groups[name]
+		if !ok {
+			return nil, errors.Errorf("group /%s/%s not found", channelconfig.ChannelGroupKey, strings.Join(path[:i+1], "/"))
+		}
+		group = subGroup
+	}
+	return group, nil
+}
+
+func setStandardValue(group *cb.ConfigGroup, value *channelconfig.StandardConfigValue) error {
+	return setValue(group, value.Key(), value.Value())
+}
+
+// setValue sets the value in the group, preserving the mod_policy of the
+// existing value, if any
+func setValue(group *cb.ConfigGroup, key string, value proto.Message) error {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal value %s", key)
+	}
+
+	modPolicy := channelconfig.AdminsPolicyKey
+	if existing, ok := group.Values[key]; ok {
+		modPolicy = existing.ModPolicy
+	}
+
+	group.Values[key] = &cb.ConfigValue{
+		Value:     valueBytes,
+		ModPolicy: modPolicy,
+	}
+	return nil
+}
+
+func unmarshalValue(group *cb.ConfigGroup, key string, msg proto.Message) error {
+	value, ok := group.Values[key]
+	if !ok {
+		return errors.Errorf("value %s not found", key)
+	}
+
+	if err := proto.Unmarshal(value.Value, msg); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal value %s", key)
+	}
+	return nil
+}
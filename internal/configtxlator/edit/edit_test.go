@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edit
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	ab "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/configtx"
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEditor(t *testing.T) *Editor {
+	block, err := configtxtest.MakeGenesisBlock("testchannel")
+	require.NoError(t, err)
+
+	e, err := NewEditor(block)
+	require.NoError(t, err)
+	return e
+}
+
+// newEtcdRaftEditor returns an editor for a config whose consensus type
+// is etcdraft with the given consenters
+func newEtcdRaftEditor(t *testing.T, consenters ...*etcdraft.Consenter) *Editor {
+	config := newTestEditor(t).Config()
+	config.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.ConsensusTypeKey].Value = protoutil.MarshalOrPanic(&ab.ConsensusType{
+		Type:     "etcdraft",
+		Metadata: protoutil.MarshalOrPanic(&etcdraft.ConfigMetadata{Consenters: consenters}),
+	})
+
+	envelope, err := protoutil.CreateSignedEnvelope(cb.HeaderType_CONFIG, "testchannel", nil, &cb.ConfigEnvelope{Config: config}, 0, 0)
+	require.NoError(t, err)
+	block := protoutil.NewBlock(0, nil)
+	block.Data.Data = [][]byte{protoutil.MarshalOrPanic(envelope)}
+
+	e, err := NewEditor(block)
+	require.NoError(t, err)
+	return e
+}
+
+func TestNewEditor(t *testing.T) {
+	e := newTestEditor(t)
+	assert.Equal(t, "testchannel", e.ChannelID())
+	assert.True(t, proto.Equal(e.original, e.Config()))
+
+	envelope, err := protoutil.CreateSignedEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, "testchannel", nil, &cb.ConfigEnvelope{}, 0, 0)
+	require.NoError(t, err)
+	block := protoutil.NewBlock(0, nil)
+	block.Data.Data = [][]byte{protoutil.MarshalOrPanic(envelope)}
+
+	_, err = NewEditor(block)
+	assert.EqualError(t, err, "block does not contain a config transaction, but a transaction of type ENDORSER_TRANSACTION")
+}
+
+func TestEnvelope(t *testing.T) {
+	e := newTestEditor(t)
+
+	_, err := e.Envelope()
+	assert.EqualError(t, err, "no differences detected between original and updated config")
+
+	require.NoError(t, e.SetBatchTimeout("5s"))
+	envelope, err := e.Envelope()
+	require.NoError(t, err)
+
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	require.NoError(t, err)
+	channelHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(cb.HeaderType_CONFIG_UPDATE), channelHeader.Type)
+	assert.Equal(t, "testchannel", channelHeader.ChannelId)
+
+	configUpdateEnv, err := configtx.UnmarshalConfigUpdateEnvelope(payload.Data)
+	require.NoError(t, err)
+	assert.Empty(t, configUpdateEnv.Signatures)
+	configUpdate, err := configtx.UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate)
+	require.NoError(t, err)
+	assert.Equal(t, "testchannel", configUpdate.ChannelId)
+
+	batchTimeout := &ab.BatchTimeout{}
+	orderer := configUpdate.WriteSet.Groups[channelconfig.OrdererGroupKey]
+	require.NoError(t, proto.Unmarshal(orderer.Values[channelconfig.BatchTimeoutKey].Value, batchTimeout))
+	assert.Equal(t, "5s", batchTimeout.Timeout)
+	assert.Equal(t, uint64(1), orderer.Values[channelconfig.BatchTimeoutKey].Version)
+}
+
+func TestAddRemoveOrg(t *testing.T) {
+	e := newTestEditor(t)
+	application := []string{channelconfig.ApplicationGroupKey}
+
+	org := proto.Clone(e.Config().ChannelGroup.Groups[channelconfig.OrdererGroupKey].Groups["SampleOrg"]).(*cb.ConfigGroup)
+	org.Values[channelconfig.EndpointsKey] = &cb.ConfigValue{ModPolicy: channelconfig.AdminsPolicyKey}
+
+	require.NoError(t, e.AddOrg(application, "Org2", org))
+	added := e.Config().ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Groups["Org2"]
+	require.NotNil(t, added)
+	assert.NotContains(t, added.Values, channelconfig.EndpointsKey)
+	assert.Contains(t, added.Values, channelconfig.MSPKey)
+
+	assert.EqualError(t, e.AddOrg(application, "Org2", org), "organization Org2 already exists")
+	assert.EqualError(t, e.AddOrg([]string{channelconfig.ConsortiumsGroupKey, "Foo"}, "Org2", org), "group /Channel/Consortiums/Foo not found")
+
+	configUpdate, err := e.ConfigUpdate()
+	require.NoError(t, err)
+	assert.Contains(t, configUpdate.WriteSet.Groups[channelconfig.ApplicationGroupKey].Groups, "Org2")
+
+	require.NoError(t, e.RemoveOrg(application, "Org2"))
+	require.NoError(t, e.RemoveOrg(application, "SampleOrg"))
+	assert.Empty(t, e.Config().ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Groups)
+	assert.EqualError(t, e.RemoveOrg(application, "SampleOrg"), "organization SampleOrg not found")
+}
+
+func TestSetAnchorPeers(t *testing.T) {
+	e := newTestEditor(t)
+
+	anchorPeers := []*pb.AnchorPeer{{Host: "peer0.example.com", Port: 7051}}
+	require.NoError(t, e.SetAnchorPeers("SampleOrg", anchorPeers))
+
+	value := e.Config().ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Groups["SampleOrg"].Values[channelconfig.AnchorPeersKey]
+	require.NotNil(t, value)
+	assert.Equal(t, channelconfig.AdminsPolicyKey, value.ModPolicy)
+	actual := &pb.AnchorPeers{}
+	require.NoError(t, proto.Unmarshal(value.Value, actual))
+	assert.True(t, proto.Equal(&pb.AnchorPeers{AnchorPeers: anchorPeers}, actual))
+
+	assert.EqualError(t, e.SetAnchorPeers("Org2", anchorPeers), "group /Channel/Application/Org2 not found")
+}
+
+func TestSetBatchSize(t *testing.T) {
+	e := newTestEditor(t)
+
+	original := &ab.BatchSize{}
+	orderer := e.Config().ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	require.NoError(t, proto.Unmarshal(orderer.Values[channelconfig.BatchSizeKey].Value, original))
+
+	require.NoError(t, e.SetBatchSize(42, 0, 0))
+	batchSize := &ab.BatchSize{}
+	require.NoError(t, proto.Unmarshal(orderer.Values[channelconfig.BatchSizeKey].Value, batchSize))
+	assert.Equal(t, uint32(42), batchSize.MaxMessageCount)
+	assert.Equal(t, original.AbsoluteMaxBytes, batchSize.AbsoluteMaxBytes)
+	assert.Equal(t, original.PreferredMaxBytes, batchSize.PreferredMaxBytes)
+
+	err := e.SetBatchSize(0, 1024, 2048)
+	assert.EqualError(t, err, "preferred max bytes (2048) cannot exceed absolute max bytes (1024)")
+}
+
+func TestSetBatchTimeout(t *testing.T) {
+	e := newTestEditor(t)
+
+	assert.EqualError(t, e.SetBatchTimeout("-1s"), "batch timeout -1s must be positive")
+	assert.Error(t, e.SetBatchTimeout("forever"))
+	assert.NoError(t, e.SetBatchTimeout("250ms"))
+}
+
+func TestConsenters(t *testing.T) {
+	assert.EqualError(t, newTestEditor(t).AddConsenter(&etcdraft.Consenter{}), "consensus type is solo, not etcdraft")
+
+	e := newEtcdRaftEditor(t, &etcdraft.Consenter{Host: "raft0.example.com", Port: 7050})
+
+	consenter := &etcdraft.Consenter{
+		Host:          "raft1.example.com",
+		Port:          7050,
+		ClientTlsCert: []byte("client-cert"),
+		ServerTlsCert: []byte("server-cert"),
+	}
+	require.NoError(t, e.AddConsenter(consenter))
+	assert.EqualError(t, e.AddConsenter(consenter), "consenter raft1.example.com:7050 already exists")
+
+	require.NoError(t, e.RemoveConsenter("raft0.example.com", 7050))
+	assert.EqualError(t, e.RemoveConsenter("raft0.example.com", 7050), "consenter raft0.example.com:7050 not found")
+	assert.EqualError(t, e.RemoveConsenter("raft1.example.com", 7050), "cannot remove the last consenter")
+
+	consensusType := &ab.ConsensusType{}
+	orderer := e.Config().ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	require.NoError(t, proto.Unmarshal(orderer.Values[channelconfig.ConsensusTypeKey].Value, consensusType))
+	metadata := &etcdraft.ConfigMetadata{}
+	require.NoError(t, proto.Unmarshal(consensusType.Metadata, metadata))
+	require.Len(t, metadata.Consenters, 1)
+	assert.True(t, proto.Equal(consenter, metadata.Consenters[0]))
+}
+
+func TestSetCapabilities(t *testing.T) {
+	e := newTestEditor(t)
+
+	require.NoError(t, e.SetCapabilities(nil, []string{"V3_0"}, nil))
+	capabilities := &cb.Capabilities{}
+	require.NoError(t, proto.Unmarshal(e.Config().ChannelGroup.Values[channelconfig.CapabilitiesKey].Value, capabilities))
+	assert.Contains(t, capabilities.Capabilities, "V3_0")
+	assert.Contains(t, capabilities.Capabilities, "V2_0")
+
+	require.NoError(t, e.SetCapabilities(nil, nil, []string{"V2_0"}))
+	capabilities = &cb.Capabilities{}
+	require.NoError(t, proto.Unmarshal(e.Config().ChannelGroup.Values[channelconfig.CapabilitiesKey].Value, capabilities))
+	assert.NotContains(t, capabilities.Capabilities, "V2_0")
+
+	assert.EqualError(t, e.SetCapabilities([]string{channelconfig.ApplicationGroupKey}, nil, []string{"V1_0"}), "capability V1_0 is not enabled")
+}
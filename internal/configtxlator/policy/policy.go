@@ -0,0 +1,318 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/policies/inquire"
+	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// Info describes a policy defined in a channel config
+type Info struct {
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	Rule      string `json:"rule"`
+	ModPolicy string `json:"mod_policy"`
+}
+
+// Simulator evaluates, offline, the policies of a channel config and
+// arbitrary signature policies against a set of identities
+type Simulator struct {
+	bundle *channelconfig.Bundle
+}
+
+// NewSimulator creates a Simulator for the channel config contained
+// in the given config block
+func NewSimulator(block *cb.Block, cryptoProvider bccsp.BCCSP) (*Simulator, error) {
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to extract config envelope from block")
+	}
+
+	bundle, err := channelconfig.NewBundleFromEnvelope(envelope, cryptoProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load channel config")
+	}
+
+	return &Simulator{bundle: bundle}, nil
+}
+
+// Policies returns every policy defined in the channel config,
+// sorted by path
+func (s *Simulator) Policies() ([]*Info, error) {
+	config := s.bundle.ConfigtxValidator().ConfigProto()
+
+	var infos []*Info
+	err := walk(config.ChannelGroup, policies.PathSeparator+channelconfig.ChannelGroupKey, &infos)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos, nil
+}
+
+func walk(group *cb.ConfigGroup, path string, infos *[]*Info) error {
+	for name, configPolicy := range group.Policies {
+		info, err := newInfo(path+policies.PathSeparator+name, configPolicy)
+		if err != nil {
+			return err
+		}
+		*infos = append(*infos, info)
+	}
+
+	for name, subGroup := range group.Groups {
+		if err := walk(subGroup, path+policies.PathSeparator+name, infos); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newInfo(path string, configPolicy *cb.ConfigPolicy) (*Info, error) {
+	info := &Info{
+		Path:      path,
+		ModPolicy: configPolicy.ModPolicy,
+	}
+
+	if configPolicy.Policy == nil {
+		return nil, errors.Errorf("policy %s is nil", path)
+	}
+
+	switch cb.Policy_PolicyType(configPolicy.Policy.Type) {
+	case cb.Policy_SIGNATURE:
+		spe := &cb.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, spe); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal signature policy %s", path)
+		}
+		info.Type = "Signature"
+		info.Rule = SignaturePolicyString(spe)
+	case cb.Policy_IMPLICIT_META:
+		imp := &cb.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, imp); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal implicit meta policy %s", path)
+		}
+		info.Type = "ImplicitMeta"
+		info.Rule = fmt.Sprintf("%s %s", imp.Rule, imp.SubPolicy)
+	default:
+		info.Type = cb.Policy_PolicyType(configPolicy.Policy.Type).String()
+	}
+
+	return info, nil
+}
+
+// ChannelPolicy returns the channel config policy at the given path
+func (s *Simulator) ChannelPolicy(path string) (policies.Policy, error) {
+	policy, ok := s.bundle.PolicyManager().GetPolicy(path)
+	if !ok {
+		return nil, errors.Errorf("policy %s not found", path)
+	}
+	return policy, nil
+}
+
+// SignaturePolicy returns a policy, backed by the MSPs of the channel,
+// for the given signature policy expression
+func (s *Simulator) SignaturePolicy(expression string) (policies.Policy, error) {
+	spe, err := policydsl.FromString(expression)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid signature policy '%s'", expression)
+	}
+
+	provider := &cauthdsl.EnvelopeBasedPolicyProvider{Deserializer: s.bundle.MSPManager()}
+	return provider.NewPolicy(spe)
+}
+
+// CertIdentity returns the identity of the given MSP for the
+// PEM encoded certificate
+func (s *Simulator) CertIdentity(mspID string, pemBytes []byte) (msp.Identity, error) {
+	serializedIdentity, err := proto.Marshal(&mb.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pemBytes,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal identity")
+	}
+
+	identity, err := s.bundle.MSPManager().DeserializeIdentity(serializedIdentity)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to deserialize identity of MSP %s", mspID)
+	}
+
+	return identity, nil
+}
+
+// Evaluate returns the explanation of the evaluation of the
+// identities against the policy
+func (s *Simulator) Evaluate(policy policies.Policy, identities []msp.Identity) *policies.Explanation {
+	return policies.ExplainIdentities(policy, identities)
+}
+
+// OrgCombinations returns the minimal combinations of MSP IDs
+// whose signatures may satisfy the policy
+func OrgCombinations(policy policies.Policy) ([][]string, error) {
+	converter, ok := policy.(policies.Converter)
+	if !ok {
+		return nil, errors.Errorf("policy of type %T cannot be inquired", policy)
+	}
+
+	spe, err := converter.Convert()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to convert policy to a signature policy")
+	}
+
+	var combinations [][]string
+	for _, principalSet := range inquire.NewInquireableSignaturePolicy(spe).SatisfiedBy() {
+		orgs := map[string]struct{}{}
+		for _, principal := range principalSet {
+			mspID, err := principalMSPID(principal)
+			if err != nil {
+				return nil, err
+			}
+			orgs[mspID] = struct{}{}
+		}
+
+		combination := make([]string, 0, len(orgs))
+		for mspID := range orgs {
+			combination = append(combination, mspID)
+		}
+		sort.Strings(combination)
+		combinations = append(combinations, combination)
+	}
+
+	return minimal(combinations), nil
+}
+
+// minimal removes the duplicate combinations and those which are
+// a superset of another combination
+func minimal(combinations [][]string) [][]string {
+	sort.Slice(combinations, func(i, j int) bool {
+		if len(combinations[i]) != len(combinations[j]) {
+			return len(combinations[i]) < len(combinations[j])
+		}
+		return strings.Join(combinations[i], ",") < strings.Join(combinations[j], ",")
+	})
+
+	var res [][]string
+	for _, combination := range combinations {
+		redundant := false
+		for _, kept := range res {
+			if containsAll(combination, kept) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			res = append(res, combination)
+		}
+	}
+	return res
+}
+
+func containsAll(set, subset []string) bool {
+	members := map[string]struct{}{}
+	for _, member := range set {
+		members[member] = struct{}{}
+	}
+	for _, member := range subset {
+		if _, ok := members[member]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func principalMSPID(principal *mb.MSPPrincipal) (string, error) {
+	switch principal.PrincipalClassification {
+	case mb.MSPPrincipal_ROLE:
+		role := &mb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal role principal")
+		}
+		return role.MspIdentifier, nil
+	case mb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal organizational unit principal")
+		}
+		return ou.MspIdentifier, nil
+	case mb.MSPPrincipal_IDENTITY:
+		id := &mb.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, id); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal identity principal")
+		}
+		return id.Mspid, nil
+	default:
+		return "", errors.Errorf("unsupported principal classification %s", principal.PrincipalClassification)
+	}
+}
+
+// SignaturePolicyString renders a signature policy using the
+// syntax of the policy language, where possible
+func SignaturePolicyString(spe *cb.SignaturePolicyEnvelope) string {
+	return signaturePolicyString(spe.Rule, spe.Identities)
+}
+
+func signaturePolicyString(policy *cb.SignaturePolicy, principals []*mb.MSPPrincipal) string {
+	switch t := policy.GetType().(type) {
+	case *cb.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(principals) {
+			return fmt.Sprintf("<invalid principal index %d>", t.SignedBy)
+		}
+		return principalString(principals[t.SignedBy])
+	case *cb.SignaturePolicy_NOutOf_:
+		rules := make([]string, len(t.NOutOf.Rules))
+		for i, rule := range t.NOutOf.Rules {
+			rules[i] = signaturePolicyString(rule, principals)
+		}
+		switch {
+		case t.NOutOf.N == 1:
+			return fmt.Sprintf("OR(%s)", strings.Join(rules, ", "))
+		case int(t.NOutOf.N) == len(rules):
+			return fmt.Sprintf("AND(%s)", strings.Join(rules, ", "))
+		default:
+			return fmt.Sprintf("OutOf(%d, %s)", t.NOutOf.N, strings.Join(rules, ", "))
+		}
+	default:
+		return "<unknown>"
+	}
+}
+
+func principalString(principal *mb.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case mb.MSPPrincipal_ROLE:
+		role := &mb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return fmt.Sprintf("'%s.%s'", role.MspIdentifier, strings.ToLower(role.Role.String()))
+		}
+	case mb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err == nil {
+			return fmt.Sprintf("<%s OU %s>", ou.MspIdentifier, ou.OrganizationalUnitIdentifier)
+		}
+	case mb.MSPPrincipal_IDENTITY:
+		id := &mb.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, id); err == nil {
+			return fmt.Sprintf("<%s identity>", id.Mspid)
+		}
+	}
+	return fmt.Sprintf("<%s principal>", principal.PrincipalClassification)
+}
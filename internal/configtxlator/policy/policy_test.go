@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policy
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSimulator(t *testing.T) *Simulator {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	block, err := configtxtest.MakeGenesisBlock("testchannel")
+	require.NoError(t, err)
+
+	s, err := NewSimulator(block, cryptoProvider)
+	require.NoError(t, err)
+	return s
+}
+
+func TestPolicies(t *testing.T) {
+	s := newTestSimulator(t)
+
+	infos, err := s.Policies()
+	assert.NoError(t, err)
+
+	byPath := map[string]*Info{}
+	for _, info := range infos {
+		byPath[info.Path] = info
+	}
+
+	assert.Equal(t, &Info{
+		Path:      "/Channel/Application/Admins",
+		Type:      "ImplicitMeta",
+		Rule:      "MAJORITY Admins",
+		ModPolicy: "Admins",
+	}, byPath["/Channel/Application/Admins"])
+	assert.Equal(t, &Info{
+		Path:      "/Channel/Application/SampleOrg/Admins",
+		Type:      "Signature",
+		Rule:      "OR('SampleOrg.member')",
+		ModPolicy: "Admins",
+	}, byPath["/Channel/Application/SampleOrg/Admins"])
+
+	for i := 1; i < len(infos); i++ {
+		assert.True(t, infos[i-1].Path < infos[i].Path)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	s := newTestSimulator(t)
+
+	admin, err := RoleIdentity("SampleOrg.admin")
+	require.NoError(t, err)
+	other, err := RoleIdentity("OtherOrg.admin")
+	require.NoError(t, err)
+
+	policy, err := s.ChannelPolicy("/Channel/Application/Admins")
+	require.NoError(t, err)
+
+	explanation := s.Evaluate(policy, []msp.Identity{admin})
+	assert.True(t, explanation.Satisfied)
+
+	explanation = s.Evaluate(policy, []msp.Identity{other})
+	assert.False(t, explanation.Satisfied)
+
+	_, err = s.ChannelPolicy("/Channel/Application/Missing")
+	assert.EqualError(t, err, "policy /Channel/Application/Missing not found")
+
+	policy, err = s.SignaturePolicy("AND('SampleOrg.admin', 'OtherOrg.peer')")
+	require.NoError(t, err)
+	explanation = s.Evaluate(policy, []msp.Identity{admin, other})
+	assert.False(t, explanation.Satisfied)
+	assert.True(t, explanation.SubExplanations[0].Satisfied)
+	assert.False(t, explanation.SubExplanations[1].Satisfied)
+
+	_, err = s.SignaturePolicy("garbage")
+	assert.Error(t, err)
+}
+
+func TestCertIdentity(t *testing.T) {
+	s := newTestSimulator(t)
+
+	pemBytes, err := ioutil.ReadFile(filepath.Join(configtest.GetDevMspDir(), "signcerts", "peer.pem"))
+	require.NoError(t, err)
+
+	identity, err := s.CertIdentity("SampleOrg", pemBytes)
+	require.NoError(t, err)
+
+	policy, err := s.SignaturePolicy("OR('SampleOrg.member')")
+	require.NoError(t, err)
+	assert.True(t, s.Evaluate(policy, []msp.Identity{identity}).Satisfied)
+
+	_, err = s.CertIdentity("UnknownOrg", pemBytes)
+	assert.Error(t, err)
+}
+
+func TestOrgCombinations(t *testing.T) {
+	s := newTestSimulator(t)
+
+	policy, err := s.SignaturePolicy("OR(AND('Org1.admin', 'Org2.admin'), OutOf(2, 'Org1.admin', 'Org2.member', 'Org3.admin'))")
+	require.NoError(t, err)
+
+	combinations, err := OrgCombinations(policy)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Org1", "Org2"}, {"Org1", "Org3"}, {"Org2", "Org3"}}, combinations)
+
+	policy, err = s.ChannelPolicy("/Channel/Application/Admins")
+	require.NoError(t, err)
+	combinations, err = OrgCombinations(policy)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"SampleOrg"}}, combinations)
+}
+
+func TestRoleIdentity(t *testing.T) {
+	for _, role := range []string{"", "Org1", "Org1.", ".admin", "Org1.king"} {
+		_, err := RoleIdentity(role)
+		assert.Error(t, err, role)
+	}
+
+	identity, err := RoleIdentity("Org1.example.com.peer")
+	assert.NoError(t, err)
+	assert.Equal(t, "Org1.example.com", identity.GetMSPIdentifier())
+	assert.Equal(t, &msp.IdentityIdentifier{Mspid: "Org1.example.com", Id: "peer"}, identity.GetIdentifier())
+}
+
+func TestSignaturePolicyString(t *testing.T) {
+	s := newTestSimulator(t)
+
+	for _, expression := range []string{
+		"OR('Org1.admin', 'Org2.peer')",
+		"AND('Org1.member', OR('Org2.client', 'Org3.orderer'))",
+		"OutOf(2, 'Org1.admin', 'Org2.admin', 'Org3.admin')",
+	} {
+		policy, err := s.SignaturePolicy(expression)
+		require.NoError(t, err)
+		spe, err := policy.(interface {
+			Convert() (*cb.SignaturePolicyEnvelope, error)
+		}).Convert()
+		require.NoError(t, err)
+		assert.Equal(t, expression, SignaturePolicyString(spe))
+	}
+}
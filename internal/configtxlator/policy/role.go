@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policy
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+// roleIdentity is a synthetic identity which satisfies the role principals
+// of its MSP matching either its role or the member role, as any identity
+// of an MSP is a member of it. It allows evaluating policies without
+// holding certificates for the members of an organization.
+type roleIdentity struct {
+	mspID string
+	role  mb.MSPRole_MSPRoleType
+}
+
+// RoleIdentity returns a synthetic identity for a role expressed as
+// in the policy language, e.g. 'Org1MSP.admin'
+func RoleIdentity(role string) (msp.Identity, error) {
+	i := strings.LastIndex(role, ".")
+	if i <= 0 || i == len(role)-1 {
+		return nil, errors.Errorf("invalid role '%s', expected <MSP ID>.<role>", role)
+	}
+
+	roleType, ok := mb.MSPRole_MSPRoleType_value[strings.ToUpper(role[i+1:])]
+	if !ok {
+		return nil, errors.Errorf("invalid role '%s', unknown role type '%s'", role, role[i+1:])
+	}
+
+	return &roleIdentity{
+		mspID: role[:i],
+		role:  mb.MSPRole_MSPRoleType(roleType),
+	}, nil
+}
+
+func (r *roleIdentity) ExpiresAt() time.Time {
+	return time.Time{}
+}
+
+func (r *roleIdentity) GetIdentifier() *msp.IdentityIdentifier {
+	return &msp.IdentityIdentifier{
+		Mspid: r.mspID,
+		Id:    strings.ToLower(r.role.String()),
+	}
+}
+
+func (r *roleIdentity) GetMSPIdentifier() string {
+	return r.mspID
+}
+
+func (r *roleIdentity) Validate() error {
+	return nil
+}
+
+func (r *roleIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return nil
+}
+
+func (r *roleIdentity) Anonymous() bool {
+	return false
+}
+
+func (r *roleIdentity) Verify(msg []byte, sig []byte) error {
+	return errors.New("role identities cannot verify signatures")
+}
+
+func (r *roleIdentity) Serialize() ([]byte, error) {
+	return nil, errors.New("role identities cannot be serialized")
+}
+
+// SatisfiesPrincipal checks whether the principal is a role of the same
+// MSP which is either the role of this identity or the member role
+func (r *roleIdentity) SatisfiesPrincipal(principal *mb.MSPPrincipal) error {
+	if principal.PrincipalClassification != mb.MSPPrincipal_ROLE {
+		return errors.Errorf("role identities only satisfy role principals, not %s", principal.PrincipalClassification)
+	}
+
+	role := &mb.MSPRole{}
+	if err := proto.Unmarshal(principal.Principal, role); err != nil {
+		return errors.Wrap(err, "failed to unmarshal role principal")
+	}
+
+	if role.MspIdentifier != r.mspID {
+		return errors.Errorf("the identity is a member of a different MSP (expected %s, got %s)", role.MspIdentifier, r.mspID)
+	}
+
+	if role.Role != mb.MSPRole_MEMBER && role.Role != r.role {
+		return errors.Errorf("the identity has role %s, not %s", r.role, role.Role)
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ package rest
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/hyperledger/fabric/internal/configtxlator/workflow"
 )
 
 func NewRouter() *mux.Router {
@@ -25,3 +26,37 @@ func NewRouter() *mux.Router {
 
 	return router
 }
+
+// NewWorkflowRouter returns a router serving, in addition to the routes of
+// NewRouter, the config update workflow backed by the given service
+func NewWorkflowRouter(service *workflow.Service) *mux.Router {
+	router := NewRouter()
+	handlers := &WorkflowHandlers{Service: service}
+
+	router.
+		HandleFunc("/configtxlator/updates", handlers.CreateUpdate).
+		Methods("POST")
+	router.
+		HandleFunc("/configtxlator/updates", handlers.ListUpdates).
+		Methods("GET")
+	router.
+		HandleFunc("/configtxlator/updates/{id}", handlers.GetUpdate).
+		Methods("GET")
+	router.
+		HandleFunc("/configtxlator/updates/{id}", handlers.DeleteUpdate).
+		Methods("DELETE")
+	router.
+		HandleFunc("/configtxlator/updates/{id}/diff", handlers.GetDiff).
+		Methods("GET")
+	router.
+		HandleFunc("/configtxlator/updates/{id}/envelope", handlers.GetEnvelope).
+		Methods("GET")
+	router.
+		HandleFunc("/configtxlator/updates/{id}/signatures", handlers.AddSignatures).
+		Methods("POST")
+	router.
+		HandleFunc("/configtxlator/updates/{id}/final", handlers.GetFinal).
+		Methods("GET")
+
+	return router
+}
@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/fabric-config/protolator"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/internal/configtxlator/workflow"
+	"github.com/pkg/errors"
+)
+
+// WorkflowHandlers exposes a workflow.Service, which collects the signatures
+// of pending config updates, through the REST API
+type WorkflowHandlers struct {
+	Service *workflow.Service
+}
+
+// CreateUpdate stores a new pending config update. The multipart form must
+// contain the current 'config_block' of the channel and either the
+// 'config_update' to apply or the 'updated' config to compute it from.
+func (h *WorkflowHandlers) CreateUpdate(w http.ResponseWriter, r *http.Request) {
+	blockBytes, err := fieldBytes("config_block", r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Error with field 'config_block': %s\n", err)
+		return
+	}
+
+	block := &cb.Block{}
+	if err := proto.Unmarshal(blockBytes, block); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Error with field 'config_block': error unmarshaling field bytes: %s\n", err)
+		return
+	}
+
+	description := r.FormValue("description")
+
+	var status *workflow.Status
+	if updateBytes, ferr := fieldBytes("config_update", r); ferr == nil {
+		configUpdate := &cb.ConfigUpdate{}
+		if err := proto.Unmarshal(updateBytes, configUpdate); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Error with field 'config_update': error unmarshaling field bytes: %s\n", err)
+			return
+		}
+		status, err = h.Service.Create(block, configUpdate, description)
+	} else {
+		updated, ferr := fieldConfigProto("updated", r)
+		if ferr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Error with field 'updated': %s\n", ferr)
+			return
+		}
+		status, err = h.Service.CreateFromConfigs(block, updated, description)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Error creating config update: %s\n", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, status)
+}
+
+// ListUpdates returns the status of every pending config update
+func (h *WorkflowHandlers) ListUpdates(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.Service.List())
+}
+
+// GetUpdate returns the status of a pending config update, including
+// which of the modified elements have their mod_policy satisfied
+func (h *WorkflowHandlers) GetUpdate(w http.ResponseWriter, r *http.Request) {
+	status, err := h.Service.Status(mux.Vars(r)["id"])
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GetDiff returns the config update of a pending config update as JSON
+func (h *WorkflowHandlers) GetDiff(w http.ResponseWriter, r *http.Request) {
+	configUpdate, err := h.Service.ConfigUpdate(mux.Vars(r)["id"])
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := protolator.DeepMarshalJSON(&buffer, configUpdate); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error encoding config update: %s\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	buffer.WriteTo(w)
+}
+
+// GetEnvelope returns the CONFIG_UPDATE envelope to be signed with
+// 'peer channel signconfigtx'
+func (h *WorkflowHandlers) GetEnvelope(w http.ResponseWriter, r *http.Request) {
+	envelope, err := h.Service.Envelope(mux.Vars(r)["id"])
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	writeProto(w, envelope)
+}
+
+// AddSignatures merges the signatures of the CONFIG_UPDATE envelope
+// in the request body into those of the pending config update
+func (h *WorkflowHandlers) AddSignatures(w http.ResponseWriter, r *http.Request) {
+	envelopeBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+
+	envelope := &cb.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Error unmarshaling envelope: %s\n", err)
+		return
+	}
+
+	status, err := h.Service.AddSignatures(mux.Vars(r)["id"], envelope)
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GetFinal returns the CONFIG_UPDATE envelope, ready to be submitted with
+// 'peer channel update', once the pending config update is authorized
+func (h *WorkflowHandlers) GetFinal(w http.ResponseWriter, r *http.Request) {
+	envelope, err := h.Service.Final(mux.Vars(r)["id"])
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	writeProto(w, envelope)
+}
+
+// DeleteUpdate removes a pending config update
+func (h *WorkflowHandlers) DeleteUpdate(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.Delete(mux.Vars(r)["id"]); err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeWorkflowError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, workflow.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, workflow.ErrNotSatisfied):
+		w.WriteHeader(http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	fmt.Fprintln(w, err)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	encoded, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error encoding response: %s\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(encoded)
+}
+
+func writeProto(w http.ResponseWriter, msg proto.Message) {
+	encoded, err := proto.Marshal(msg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error marshaling %T: %s\n", msg, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(encoded)
+}
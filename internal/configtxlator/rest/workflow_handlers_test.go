@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/internal/configtxlator/workflow"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowRoutes(t *testing.T) {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	service, err := workflow.NewService("", cryptoProvider)
+	require.NoError(t, err)
+	router := NewWorkflowRouter(service)
+
+	block, err := configtxtest.MakeGenesisBlock("testchannel")
+	require.NoError(t, err)
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	require.NoError(t, err)
+	bundle, err := channelconfig.NewBundleFromEnvelope(envelope, cryptoProvider)
+	require.NoError(t, err)
+
+	updated := proto.Clone(bundle.ConfigtxValidator().ConfigProto()).(*cb.Config)
+	updated.ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Values[channelconfig.ACLsKey] = &cb.ConfigValue{
+		ModPolicy: channelconfig.AdminsPolicyKey,
+		Value: protoutil.MarshalOrPanic(&pb.ACLs{
+			Acls: map[string]*pb.APIResource{"foo/bar": {PolicyRef: "Readers"}},
+		}),
+	}
+
+	buffer := &bytes.Buffer{}
+	mpw := multipart.NewWriter(buffer)
+	ffw, err := mpw.CreateFormFile("config_block", "config_block.pb")
+	require.NoError(t, err)
+	_, err = ffw.Write(protoutil.MarshalOrPanic(block))
+	require.NoError(t, err)
+	ffw, err = mpw.CreateFormFile("updated", "updated.pb")
+	require.NoError(t, err)
+	_, err = ffw.Write(protoutil.MarshalOrPanic(updated))
+	require.NoError(t, err)
+	require.NoError(t, mpw.WriteField("description", "update ACLs"))
+	require.NoError(t, mpw.Close())
+
+	serve := func(method, url string, body []byte, contentType string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		require.NoError(t, err)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("POST", "/configtxlator/updates", buffer.Bytes(), mpw.FormDataContentType())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	status := &workflow.Status{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), status))
+	assert.Equal(t, "testchannel", status.ChannelID)
+	assert.Equal(t, "update ACLs", status.Description)
+	assert.False(t, status.Satisfied)
+
+	rec = serve("GET", "/configtxlator/updates", nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var statuses []*workflow.Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+	assert.Len(t, statuses, 1)
+
+	rec = serve("GET", "/configtxlator/updates/"+status.ID, nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "/Channel/Application/Admins")
+
+	rec = serve("GET", "/configtxlator/updates/"+status.ID+"/diff", nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"foo/bar"`)
+
+	rec = serve("GET", "/configtxlator/updates/"+status.ID+"/envelope", nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	envelopeBytes := rec.Body.Bytes()
+
+	rec = serve("POST", "/configtxlator/updates/"+status.ID+"/signatures", envelopeBytes, "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = serve("POST", "/configtxlator/updates/"+status.ID+"/signatures", []byte("garbage"), "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	rec = serve("GET", "/configtxlator/updates/"+status.ID+"/final", nil, "")
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	rec = serve("DELETE", "/configtxlator/updates/"+status.ID, nil, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = serve("GET", "/configtxlator/updates/"+status.ID, nil, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestWorkflowCreateMissingBlock(t *testing.T) {
+	service, err := workflow.NewService("", nil)
+	require.NoError(t, err)
+
+	buffer := &bytes.Buffer{}
+	mpw := multipart.NewWriter(buffer)
+	require.NoError(t, mpw.Close())
+
+	req, err := http.NewRequest("POST", "/configtxlator/updates", buffer)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", mpw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	NewWorkflowRouter(service).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Error with field 'config_block'")
+}
@@ -0,0 +1,598 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workflow
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/internal/configtxlator/update"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+var logger = flogging.MustGetLogger("configtxlator.workflow")
+
+var (
+	// ErrNotFound is returned when no pending config update exists for an ID
+	ErrNotFound = errors.New("not found")
+
+	// ErrNotSatisfied is returned when the final envelope of a config update
+	// is requested before its signatures satisfy the policies of the channel
+	ErrNotSatisfied = errors.New("signatures do not satisfy the policies of the channel")
+)
+
+// PolicyStatus describes whether the mod_policy of a config element
+// modified by a pending update is satisfied by the collected signatures
+type PolicyStatus struct {
+	Key         string                `json:"key"`
+	Policy      string                `json:"policy"`
+	Satisfied   bool                  `json:"satisfied"`
+	Explanation *policies.Explanation `json:"explanation,omitempty"`
+}
+
+// Status describes a pending config update and the progress of the
+// collection of its signatures
+type Status struct {
+	ID          string          `json:"id"`
+	ChannelID   string          `json:"channel_id"`
+	Description string          `json:"description,omitempty"`
+	Created     time.Time       `json:"created"`
+	Signers     []string        `json:"signers"`
+	Policies    []*PolicyStatus `json:"policies"`
+	Satisfied   bool            `json:"satisfied"`
+	Reason      string          `json:"reason,omitempty"`
+}
+
+// record is the persisted form of a pending config update
+type record struct {
+	ID           string    `json:"id"`
+	ChannelID    string    `json:"channel_id"`
+	Description  string    `json:"description,omitempty"`
+	Created      time.Time `json:"created"`
+	ConfigBlock  []byte    `json:"config_block"`
+	ConfigUpdate []byte    `json:"config_update"`
+	Signatures   [][]byte  `json:"signatures,omitempty"`
+}
+
+type pendingUpdate struct {
+	record     *record
+	bundle     *channelconfig.Bundle
+	validator  *configtx.ValidatorImpl
+	signatures []*cb.ConfigSignature
+}
+
+// Service stores pending config updates and collects the signatures of
+// the organization admins until the update is authorized by the policies
+// of the channel config it was computed against
+type Service struct {
+	dir            string
+	cryptoProvider bccsp.BCCSP
+
+	mutex   sync.Mutex
+	updates map[string]*pendingUpdate
+}
+
+// NewService creates a Service. If dir is not empty, pending updates are
+// persisted in, and reloaded from, that directory.
+func NewService(dir string, cryptoProvider bccsp.BCCSP) (*Service, error) {
+	s := &Service{
+		dir:            dir,
+		cryptoProvider: cryptoProvider,
+		updates:        map[string]*pendingUpdate{},
+	}
+
+	if dir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read directory %s", dir)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		pu, err := s.load(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s.updates[pu.record.ID] = pu
+	}
+
+	logger.Infof("Loaded %d pending config updates from %s", len(s.updates), dir)
+	return s, nil
+}
+
+func (s *Service) load(path string) (*pendingUpdate, error) {
+	recordBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	r := &record{}
+	if err := json.Unmarshal(recordBytes, r); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", path)
+	}
+
+	pu, err := s.newPendingUpdate(r)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to load %s", path)
+	}
+
+	for _, sigBytes := range r.Signatures {
+		sig := &cb.ConfigSignature{}
+		if err := proto.Unmarshal(sigBytes, sig); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal signature in %s", path)
+		}
+		pu.signatures = append(pu.signatures, sig)
+	}
+
+	return pu, nil
+}
+
+func (s *Service) newPendingUpdate(r *record) (*pendingUpdate, error) {
+	block, err := protoutil.UnmarshalBlock(r.ConfigBlock)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config block")
+	}
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to extract config envelope from block")
+	}
+
+	bundle, err := channelconfig.NewBundleFromEnvelope(envelope, s.cryptoProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load channel config")
+	}
+
+	validator, err := configtx.NewValidatorImpl(
+		bundle.ConfigtxValidator().ChannelID(),
+		bundle.ConfigtxValidator().ConfigProto(),
+		channelconfig.ChannelGroupKey,
+		bundle.PolicyManager(),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create config validator")
+	}
+
+	return &pendingUpdate{
+		record:    r,
+		bundle:    bundle,
+		validator: validator,
+	}, nil
+}
+
+// Create stores a new pending config update for the channel whose current
+// config is contained in the given config block
+func (s *Service) Create(configBlock *cb.Block, configUpdate *cb.ConfigUpdate, description string) (*Status, error) {
+	blockBytes, err := proto.Marshal(configBlock)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal config block")
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &record{
+		ID:          id,
+		Description: description,
+		Created:     time.Now().UTC(),
+		ConfigBlock: blockBytes,
+	}
+
+	pu, err := s.newPendingUpdate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ChannelID = pu.validator.ChannelID()
+	if configUpdate.ChannelId == "" {
+		configUpdate.ChannelId = r.ChannelID
+	}
+	if configUpdate.ChannelId != r.ChannelID {
+		return nil, errors.Errorf("config update is for channel %s but config block is for channel %s", configUpdate.ChannelId, r.ChannelID)
+	}
+
+	r.ConfigUpdate, err = proto.Marshal(configUpdate)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal config update")
+	}
+
+	// Reject updates which could never be authorized against this config
+	if _, err := pu.validator.ModPolicyStatuses(pu.configUpdateEnvelope()); err != nil {
+		return nil, errors.WithMessage(err, "invalid config update")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.persist(pu); err != nil {
+		return nil, err
+	}
+	s.updates[r.ID] = pu
+
+	logger.Infof("Created config update %s for channel %s", r.ID, r.ChannelID)
+	return pu.status(), nil
+}
+
+// CreateFromConfigs computes the config update between the config contained
+// in the config block and the updated config, and stores it as a pending
+// config update
+func (s *Service) CreateFromConfigs(configBlock *cb.Block, updated *cb.Config, description string) (*Status, error) {
+	configEnvelope, err := protoutil.ExtractEnvelope(configBlock, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to extract config envelope from block")
+	}
+
+	payload, err := protoutil.UnmarshalPayload(configEnvelope.Payload)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config envelope payload")
+	}
+
+	original, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config envelope")
+	}
+
+	configUpdate, err := update.Compute(original.Config, updated)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to compute config update")
+	}
+
+	return s.Create(configBlock, configUpdate, description)
+}
+
+// List returns the status of every pending config update, oldest first
+func (s *Service) List() []*Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statuses := make([]*Status, 0, len(s.updates))
+	for _, pu := range s.updates {
+		statuses = append(statuses, pu.status())
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Created.Equal(statuses[j].Created) {
+			return statuses[i].ID < statuses[j].ID
+		}
+		return statuses[i].Created.Before(statuses[j].Created)
+	})
+	return statuses
+}
+
+// Status returns the status of a pending config update
+func (s *Service) Status(id string) (*Status, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pu, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return pu.status(), nil
+}
+
+// ConfigUpdate returns the config update of a pending config update
+func (s *Service) ConfigUpdate(id string) (*cb.ConfigUpdate, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pu, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return configtx.UnmarshalConfigUpdate(pu.record.ConfigUpdate)
+}
+
+// Envelope returns an unsigned CONFIG_UPDATE envelope which carries the
+// signatures collected so far. It may be signed with
+// 'peer channel signconfigtx' and uploaded with AddSignatures.
+func (s *Service) Envelope(id string) (*cb.Envelope, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pu, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return pu.envelope()
+}
+
+// AddSignatures merges the config signatures carried by a CONFIG_UPDATE
+// envelope, which must embed the exact config update of the pending config
+// update, into the signatures collected so far
+func (s *Service) AddSignatures(id string, envelope *cb.Envelope) (*Status, error) {
+	configUpdateEnv, err := configUpdateEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pu, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(configUpdateEnv.ConfigUpdate, pu.record.ConfigUpdate) {
+		return nil, errors.Errorf("envelope does not contain the config update %s", id)
+	}
+
+	signatures := pu.signatures
+	for _, sig := range configUpdateEnv.Signatures {
+		creator, err := pu.verify(sig)
+		if err != nil {
+			return nil, err
+		}
+		signatures = addSignature(signatures, creator, sig)
+	}
+
+	previous := pu.signatures
+	pu.signatures = signatures
+	if err := s.persist(pu); err != nil {
+		pu.signatures = previous
+		return nil, err
+	}
+
+	logger.Infof("Config update %s now has %d signatures", id, len(pu.signatures))
+	return pu.status(), nil
+}
+
+// Final returns the CONFIG_UPDATE envelope of a pending config update once
+// its signatures satisfy the policies of the channel config. The envelope
+// may then be submitted with 'peer channel update'.
+func (s *Service) Final(id string) (*cb.Envelope, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pu, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := pu.envelope()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := pu.validator.ProposeConfigUpdate(envelope); err != nil {
+		logger.Debugf("Config update %s is not authorized: %s", id, err)
+		return nil, errors.WithMessagef(ErrNotSatisfied, "config update %s cannot be finalized", id)
+	}
+
+	return envelope, nil
+}
+
+// Delete removes a pending config update
+func (s *Service) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.get(id); err != nil {
+		return err
+	}
+
+	if s.dir != "" {
+		if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove config update %s", id)
+		}
+	}
+	delete(s.updates, id)
+
+	logger.Infof("Deleted config update %s", id)
+	return nil
+}
+
+func (s *Service) get(id string) (*pendingUpdate, error) {
+	pu, ok := s.updates[id]
+	if !ok {
+		return nil, errors.WithMessagef(ErrNotFound, "config update %s", id)
+	}
+	return pu, nil
+}
+
+func (s *Service) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *Service) persist(pu *pendingUpdate) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	pu.record.Signatures = nil
+	for _, sig := range pu.signatures {
+		sigBytes, err := proto.Marshal(sig)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal signature")
+		}
+		pu.record.Signatures = append(pu.record.Signatures, sigBytes)
+	}
+
+	recordBytes, err := json.Marshal(pu.record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config update")
+	}
+
+	tmp := s.path(pu.record.ID) + ".tmp"
+	if err := ioutil.WriteFile(tmp, recordBytes, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write config update %s", pu.record.ID)
+	}
+	if err := os.Rename(tmp, s.path(pu.record.ID)); err != nil {
+		return errors.Wrapf(err, "failed to write config update %s", pu.record.ID)
+	}
+
+	return nil
+}
+
+func (pu *pendingUpdate) configUpdateEnvelope() *cb.ConfigUpdateEnvelope {
+	return &cb.ConfigUpdateEnvelope{
+		ConfigUpdate: pu.record.ConfigUpdate,
+		Signatures:   pu.signatures,
+	}
+}
+
+func (pu *pendingUpdate) envelope() (*cb.Envelope, error) {
+	return protoutil.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, pu.record.ChannelID, nil, pu.configUpdateEnvelope(), 0, 0)
+}
+
+// verify checks that the signature was produced over the config update by
+// an identity of the channel, and returns the serialized creator
+func (pu *pendingUpdate) verify(sig *cb.ConfigSignature) ([]byte, error) {
+	sigHeader, err := protoutil.UnmarshalSignatureHeader(sig.SignatureHeader)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid signature header")
+	}
+
+	identity, err := pu.bundle.MSPManager().DeserializeIdentity(sigHeader.Creator)
+	if err != nil {
+		return nil, errors.WithMessage(err, "signature was not produced by an identity of the channel")
+	}
+
+	data := append(append([]byte{}, sig.SignatureHeader...), pu.record.ConfigUpdate...)
+	if err := identity.Verify(data, sig.Signature); err != nil {
+		return nil, errors.WithMessagef(err, "invalid signature of identity %s", policies.IdentityLabel(identity))
+	}
+
+	return sigHeader.Creator, nil
+}
+
+func (pu *pendingUpdate) status() *Status {
+	status := &Status{
+		ID:          pu.record.ID,
+		ChannelID:   pu.record.ChannelID,
+		Description: pu.record.Description,
+		Created:     pu.record.Created,
+		Signers:     []string{},
+		Policies:    []*PolicyStatus{},
+	}
+
+	for _, sig := range pu.signatures {
+		status.Signers = append(status.Signers, signerMSPID(sig))
+	}
+
+	modPolicyStatuses, err := pu.validator.ModPolicyStatuses(pu.configUpdateEnvelope())
+	if err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+	for _, mps := range modPolicyStatuses {
+		status.Policies = append(status.Policies, &PolicyStatus{
+			Key:         mps.Key,
+			Policy:      mps.PolicyPath,
+			Satisfied:   mps.Satisfied,
+			Explanation: mps.Explanation,
+		})
+	}
+
+	envelope, err := pu.envelope()
+	if err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+	if _, err := pu.validator.ProposeConfigUpdate(envelope); err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+
+	status.Satisfied = true
+	return status
+}
+
+// addSignature adds sig to signatures, replacing any previous signature
+// of the same creator
+func addSignature(signatures []*cb.ConfigSignature, creator []byte, sig *cb.ConfigSignature) []*cb.ConfigSignature {
+	var res []*cb.ConfigSignature
+	for _, existing := range signatures {
+		sigHeader, err := protoutil.UnmarshalSignatureHeader(existing.SignatureHeader)
+		if err == nil && bytes.Equal(sigHeader.Creator, creator) {
+			continue
+		}
+		res = append(res, existing)
+	}
+	return append(res, sig)
+}
+
+func signerMSPID(sig *cb.ConfigSignature) string {
+	sigHeader, err := protoutil.UnmarshalSignatureHeader(sig.SignatureHeader)
+	if err != nil {
+		return "<invalid>"
+	}
+
+	sid := &mb.SerializedIdentity{}
+	if err := proto.Unmarshal(sigHeader.Creator, sid); err != nil {
+		return "<invalid>"
+	}
+
+	return sid.Mspid
+}
+
+func configUpdateEnvelope(envelope *cb.Envelope) (*cb.ConfigUpdateEnvelope, error) {
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Header == nil {
+		return nil, errors.New("envelope has no header")
+	}
+
+	channelHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if channelHeader.Type != int32(cb.HeaderType_CONFIG_UPDATE) {
+		return nil, errors.Errorf("envelope is of type %s, not CONFIG_UPDATE", cb.HeaderType(channelHeader.Type))
+	}
+
+	return configtx.UnmarshalConfigUpdateEnvelope(payload.Data)
+}
+
+// randReader is the source of the IDs of the config updates
+var randReader io.Reader = rand.Reader
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return "", errors.Wrap(err, "failed to generate config update ID")
+	}
+	return hex.EncodeToString(b), nil
+}
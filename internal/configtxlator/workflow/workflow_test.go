@@ -0,0 +1,249 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workflow
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	msptesttools "github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSetup struct {
+	cryptoProvider bccsp.BCCSP
+	block          *cb.Block
+	updated        *cb.Config
+	signer         msp.SigningIdentity
+}
+
+func newTestSetup(t *testing.T) *testSetup {
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+
+	block, err := configtxtest.MakeGenesisBlock("testchannel")
+	require.NoError(t, err)
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	require.NoError(t, err)
+	bundle, err := channelconfig.NewBundleFromEnvelope(envelope, cryptoProvider)
+	require.NoError(t, err)
+
+	updated := proto.Clone(bundle.ConfigtxValidator().ConfigProto()).(*cb.Config)
+	updated.ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Values["Foo"] = &cb.ConfigValue{
+		ModPolicy: channelconfig.AdminsPolicyKey,
+		Value:     []byte("bar"),
+	}
+
+	require.NoError(t, msptesttools.LoadMSPSetupForTesting())
+	signer, err := mgmt.GetLocalMSP(cryptoProvider).GetDefaultSigningIdentity()
+	require.NoError(t, err)
+
+	return &testSetup{
+		cryptoProvider: cryptoProvider,
+		block:          block,
+		updated:        updated,
+		signer:         signer,
+	}
+}
+
+func (ts *testSetup) sign(t *testing.T, envelope *cb.Envelope) *cb.Envelope {
+	configUpdateEnv, err := configUpdateEnvelope(envelope)
+	require.NoError(t, err)
+
+	creator, err := ts.signer.Serialize()
+	require.NoError(t, err)
+
+	sig := &cb.ConfigSignature{
+		SignatureHeader: protoutil.MarshalOrPanic(&cb.SignatureHeader{Creator: creator, Nonce: []byte("nonce")}),
+	}
+	sig.Signature, err = ts.signer.Sign(append(append([]byte{}, sig.SignatureHeader...), configUpdateEnv.ConfigUpdate...))
+	require.NoError(t, err)
+	configUpdateEnv.Signatures = append(configUpdateEnv.Signatures, sig)
+
+	signed, err := protoutil.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, "testchannel", nil, configUpdateEnv, 0, 0)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestWorkflow(t *testing.T) {
+	ts := newTestSetup(t)
+
+	s, err := NewService("", ts.cryptoProvider)
+	require.NoError(t, err)
+
+	status, err := s.CreateFromConfigs(ts.block, ts.updated, "add Foo")
+	require.NoError(t, err)
+	assert.Equal(t, "testchannel", status.ChannelID)
+	assert.Equal(t, "add Foo", status.Description)
+	assert.Empty(t, status.Signers)
+	assert.False(t, status.Satisfied)
+	require.Len(t, status.Policies, 1)
+	assert.Equal(t, "[Group]  /Channel/Application", status.Policies[0].Key)
+	assert.Equal(t, "/Channel/Application/Admins", status.Policies[0].Policy)
+	assert.False(t, status.Policies[0].Satisfied)
+	assert.NotNil(t, status.Policies[0].Explanation)
+
+	configUpdate, err := s.ConfigUpdate(status.ID)
+	require.NoError(t, err)
+	assert.Contains(t, configUpdate.WriteSet.Groups[channelconfig.ApplicationGroupKey].Values, "Foo")
+
+	_, err = s.Final(status.ID)
+	assert.True(t, errors.Is(err, ErrNotSatisfied))
+
+	envelope, err := s.Envelope(status.ID)
+	require.NoError(t, err)
+
+	status, err = s.AddSignatures(status.ID, ts.sign(t, envelope))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SampleOrg"}, status.Signers)
+	assert.True(t, status.Policies[0].Satisfied)
+	assert.True(t, status.Satisfied)
+	assert.Empty(t, status.Reason)
+
+	// signing again replaces the previous signature of the same identity
+	status, err = s.AddSignatures(status.ID, ts.sign(t, envelope))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SampleOrg"}, status.Signers)
+
+	final, err := s.Final(status.ID)
+	require.NoError(t, err)
+	configUpdateEnv, err := configUpdateEnvelope(final)
+	require.NoError(t, err)
+	assert.Len(t, configUpdateEnv.Signatures, 1)
+
+	assert.Len(t, s.List(), 1)
+	require.NoError(t, s.Delete(status.ID))
+	assert.Empty(t, s.List())
+
+	_, err = s.Status(status.ID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(s.Delete(status.ID), ErrNotFound))
+}
+
+func TestAddSignaturesErrors(t *testing.T) {
+	ts := newTestSetup(t)
+
+	s, err := NewService("", ts.cryptoProvider)
+	require.NoError(t, err)
+
+	status, err := s.CreateFromConfigs(ts.block, ts.updated, "")
+	require.NoError(t, err)
+
+	envelope, err := s.Envelope(status.ID)
+	require.NoError(t, err)
+
+	t.Run("Different config update", func(t *testing.T) {
+		configUpdateEnv, err := configUpdateEnvelope(envelope)
+		require.NoError(t, err)
+		configUpdateEnv.ConfigUpdate = []byte("garbage")
+		env, err := protoutil.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, "testchannel", nil, configUpdateEnv, 0, 0)
+		require.NoError(t, err)
+
+		_, err = s.AddSignatures(status.ID, env)
+		assert.EqualError(t, err, "envelope does not contain the config update "+status.ID)
+	})
+
+	t.Run("Bad signature", func(t *testing.T) {
+		signed := ts.sign(t, envelope)
+		configUpdateEnv, err := configUpdateEnvelope(signed)
+		require.NoError(t, err)
+		configUpdateEnv.Signatures[0].Signature = []byte("garbage")
+		env, err := protoutil.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, "testchannel", nil, configUpdateEnv, 0, 0)
+		require.NoError(t, err)
+
+		_, err = s.AddSignatures(status.ID, env)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid signature of identity SampleOrg:")
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		env, err := protoutil.CreateSignedEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, "testchannel", nil, &cb.ConfigUpdateEnvelope{}, 0, 0)
+		require.NoError(t, err)
+
+		_, err = s.AddSignatures(status.ID, env)
+		assert.EqualError(t, err, "envelope is of type ENDORSER_TRANSACTION, not CONFIG_UPDATE")
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := s.AddSignatures("missing", envelope)
+		assert.EqualError(t, err, "config update missing: not found")
+	})
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy exhausted")
+}
+
+func TestCreateErrors(t *testing.T) {
+	ts := newTestSetup(t)
+
+	s, err := NewService("", ts.cryptoProvider)
+	require.NoError(t, err)
+
+	_, err = s.Create(ts.block, &cb.ConfigUpdate{ChannelId: "otherchannel"}, "")
+	assert.EqualError(t, err, "config update is for channel otherchannel but config block is for channel testchannel")
+
+	_, err = s.Create(ts.block, &cb.ConfigUpdate{}, "")
+	assert.EqualError(t, err, "invalid config update: delta set was empty -- update would have no effect")
+
+	_, err = s.Create(&cb.Block{}, &cb.ConfigUpdate{}, "")
+	assert.Error(t, err)
+
+	randReader = failingReader{}
+	defer func() { randReader = rand.Reader }()
+	_, err = s.Create(ts.block, &cb.ConfigUpdate{}, "")
+	assert.EqualError(t, err, "failed to generate config update ID: entropy exhausted")
+}
+
+func TestPersistence(t *testing.T) {
+	ts := newTestSetup(t)
+
+	dir, err := ioutil.TempDir("", "workflow")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewService(dir, ts.cryptoProvider)
+	require.NoError(t, err)
+
+	status, err := s.CreateFromConfigs(ts.block, ts.updated, "persisted")
+	require.NoError(t, err)
+
+	envelope, err := s.Envelope(status.ID)
+	require.NoError(t, err)
+	_, err = s.AddSignatures(status.ID, ts.sign(t, envelope))
+	require.NoError(t, err)
+
+	reloaded, err := NewService(dir, ts.cryptoProvider)
+	require.NoError(t, err)
+
+	reloadedStatus, err := reloaded.Status(status.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "persisted", reloadedStatus.Description)
+	assert.Equal(t, []string{"SampleOrg"}, reloadedStatus.Signers)
+	assert.True(t, reloadedStatus.Satisfied)
+
+	require.NoError(t, reloaded.Delete(status.ID))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
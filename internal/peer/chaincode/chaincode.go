@@ -20,7 +20,7 @@ import (
 
 const (
 	chainFuncName = "chaincode"
-	chainCmdDes   = "Operate a chaincode: install|instantiate|invoke|package|query|signpackage|upgrade|list."
+	chainCmdDes   = "Operate a chaincode: install|instantiate|invoke|package|query|signpackage|upgrade|list|replay."
 )
 
 var logger = flogging.MustGetLogger("chaincodeCmd")
@@ -49,6 +49,7 @@ func Cmd(cf *ChaincodeCmdFactory, cryptoProvider bccsp.BCCSP) *cobra.Command {
 	chaincodeCmd.AddCommand(signpackageCmd(cf, cryptoProvider))
 	chaincodeCmd.AddCommand(upgradeCmd(cf, cryptoProvider))
 	chaincodeCmd.AddCommand(listCmd(cf, cryptoProvider))
+	chaincodeCmd.AddCommand(replayCmd())
 
 	return chaincodeCmd
 }
@@ -77,6 +78,10 @@ var (
 	connectionProfile     string
 	waitForEvent          bool
 	waitForEventTimeout   time.Duration
+
+	replayListenAddress    string
+	replayChaincodeAddress string
+	replayTimeout          time.Duration
 )
 
 var chaincodeCmd = &cobra.Command{
@@ -143,6 +148,12 @@ func resetFlags() {
 		"if creating CC deployment spec package for owner endorsements, also sign it with local MSP")
 	flags.StringVarP(&instantiationPolicy, "instantiate-policy", "i", "",
 		"instantiation policy for the chaincode")
+	flags.StringVar(&replayListenAddress, "listenAddress", "",
+		"The address to listen on for the chaincode to replay, which connects to it like to a peer in development mode")
+	flags.StringVar(&replayChaincodeAddress, "chaincodeAddress", "",
+		"The address of the chaincode server to replay")
+	flags.DurationVar(&replayTimeout, "replayTimeout", 0,
+		"The time to wait for the replay to complete, 0 to wait until the chaincode completes")
 }
 
 func attachFlags(cmd *cobra.Command, names []string) {
@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/common/policydsl"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
 	"github.com/hyperledger/fabric/internal/peer/common"
 	"github.com/hyperledger/fabric/internal/pkg/identity"
 	"github.com/hyperledger/fabric/protoutil"
@@ -686,6 +687,10 @@ func ChaincodeInvokeOrQuery(
 			if err != nil {
 				return proposalResp, errors.WithMessage(err, "could not assemble transaction")
 			}
+			crossChannelEnvs, err := createCrossChannelTxs(responses, signer)
+			if err != nil {
+				return proposalResp, errors.WithMessage(err, "could not assemble cross-channel transactions")
+			}
 			var dg *DeliverGroup
 			var ctx context.Context
 			if waitForEvent {
@@ -713,6 +718,13 @@ func ChaincodeInvokeOrQuery(
 				return proposalResp, errors.WithMessagef(err, "error sending transaction for %s", funcName)
 			}
 
+			// send the transactions carrying the writes on other channels
+			for _, crossChannelEnv := range crossChannelEnvs {
+				if err = bc.Send(crossChannelEnv); err != nil {
+					return proposalResp, errors.WithMessagef(err, "error sending cross-channel transaction for %s", funcName)
+				}
+			}
+
 			if dg != nil && ctx != nil {
 				// wait for event that contains the txid from all peers
 				err = dg.Wait(ctx)
@@ -726,6 +738,51 @@ func ChaincodeInvokeOrQuery(
 	return proposalResp, nil
 }
 
+// createCrossChannelTxs assembles the transactions carrying the writes of the
+// chaincodes invoked on other channels from the cross-channel responses of
+// the endorsers, one transaction per channel.
+func createCrossChannelTxs(responses []*pb.ProposalResponse, signer identity.SignerSerializer) ([]*pcommon.Envelope, error) {
+	var channels []string
+	channelResponses := map[string][]*msgs.CrossChannelResponse{}
+	for _, resp := range responses {
+		crossChannelResponses, err := msgs.GetCrossChannelResponses(resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, ccr := range crossChannelResponses {
+			if _, ok := channelResponses[ccr.ChannelId]; !ok {
+				channels = append(channels, ccr.ChannelId)
+			}
+			channelResponses[ccr.ChannelId] = append(channelResponses[ccr.ChannelId], ccr)
+		}
+	}
+
+	var envs []*pcommon.Envelope
+	for _, channel := range channels {
+		if len(channelResponses[channel]) != len(responses) {
+			return nil, errors.Errorf("not all endorsers returned a response for channel %s", channel)
+		}
+		prop := &pb.Proposal{}
+		if err := proto.Unmarshal(channelResponses[channel][0].Proposal, prop); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshaling proposal for channel %s", channel)
+		}
+		var resps []*pb.ProposalResponse
+		for _, ccr := range channelResponses[channel] {
+			resp := &pb.ProposalResponse{}
+			if err := proto.Unmarshal(ccr.ProposalResponse, resp); err != nil {
+				return nil, errors.Wrapf(err, "error unmarshaling proposal response for channel %s", channel)
+			}
+			resps = append(resps, resp)
+		}
+		env, err := protoutil.CreateSignedTx(prop, signer, resps...)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "could not assemble transaction for channel %s", channel)
+		}
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
 // DeliverGroup holds all of the information needed to connect
 // to a set of peers to wait for the interested txid to be
 // committed to the ledgers of all peers. This functionality
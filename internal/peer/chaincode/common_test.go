@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
 	"github.com/hyperledger/fabric/core/config/configtest"
 	"github.com/hyperledger/fabric/internal/configtxgen/encoder"
 	"github.com/hyperledger/fabric/internal/configtxgen/genesisconfig"
@@ -904,3 +905,79 @@ func TestProcessProposals(t *testing.T) {
 		assert.Nil(t, responses)
 	})
 }
+
+func TestCreateCrossChannelTxs(t *testing.T) {
+	signer, err := common.GetDefaultSigner()
+	require.NoError(t, err)
+	creator, err := signer.Serialize()
+	require.NoError(t, err)
+
+	prop, _, err := protoutil.CreateChaincodeProposalWithTxIDAndTransient(
+		cb.HeaderType_ENDORSER_TRANSACTION,
+		"settlement",
+		&pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "asset"}}},
+		creator,
+		"txid0",
+		nil,
+	)
+	require.NoError(t, err)
+	crossChannelResponse := &msgs.CrossChannelResponse{
+		ChannelId: "settlement",
+		Proposal:  protoutil.MarshalOrPanic(prop),
+		ProposalResponse: protoutil.MarshalOrPanic(&pb.ProposalResponse{
+			Response:    &pb.Response{Status: 200},
+			Payload:     []byte("payload"),
+			Endorsement: &pb.Endorsement{Endorser: []byte("endorser"), Signature: []byte("signature")},
+		}),
+	}
+
+	newResponses := func() []*pb.ProposalResponse {
+		responses := []*pb.ProposalResponse{
+			{Response: &pb.Response{Status: 200}},
+			{Response: &pb.Response{Status: 200}},
+		}
+		for _, resp := range responses {
+			require.NoError(t, msgs.AddCrossChannelResponses(resp, []*msgs.CrossChannelResponse{crossChannelResponse}))
+		}
+		return responses
+	}
+
+	t.Run("success", func(t *testing.T) {
+		envs, err := createCrossChannelTxs(newResponses(), signer)
+		require.NoError(t, err)
+		require.Len(t, envs, 1)
+
+		payload, err := protoutil.UnmarshalPayload(envs[0].Payload)
+		require.NoError(t, err)
+		chdr, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		require.NoError(t, err)
+		require.Equal(t, "settlement", chdr.ChannelId)
+		require.Equal(t, "txid0", chdr.TxId)
+
+		tx, err := protoutil.UnmarshalTransaction(payload.Data)
+		require.NoError(t, err)
+		cap, err := protoutil.UnmarshalChaincodeActionPayload(tx.Actions[0].Payload)
+		require.NoError(t, err)
+		require.Len(t, cap.Action.Endorsements, 2)
+	})
+
+	t.Run("no cross-channel responses", func(t *testing.T) {
+		envs, err := createCrossChannelTxs([]*pb.ProposalResponse{{Response: &pb.Response{Status: 200}}}, signer)
+		require.NoError(t, err)
+		require.Empty(t, envs)
+	})
+
+	t.Run("missing response", func(t *testing.T) {
+		responses := append(newResponses(), &pb.ProposalResponse{Response: &pb.Response{Status: 200}})
+		_, err := createCrossChannelTxs(responses, signer)
+		require.EqualError(t, err, "not all endorsers returned a response for channel settlement")
+	})
+
+	t.Run("bad cross-channel responses", func(t *testing.T) {
+		responses := newResponses()
+		responses[1].XXX_unrecognized = []byte("garbage")
+		_, err := createCrossChannelTxs(responses, signer)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal cross-channel responses")
+	})
+}
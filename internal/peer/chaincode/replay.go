@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/chaincode/msgs"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
+	"github.com/hyperledger/fabric/internal/peer/common"
+	"github.com/hyperledger/fabric/internal/pkg/comm"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// replayConnTimeout is the time to wait for the connection to a chaincode
+// server
+const replayConnTimeout = 3 * time.Second
+
+// replayCmd returns the cobra command for replaying a recorded chaincode
+// execution
+func replayCmd() *cobra.Command {
+	chaincodeReplayCmd := &cobra.Command{
+		Use:   "replay <recording>",
+		Short: "Replay a recorded chaincode execution.",
+		Long: "Replay an execution recorded by a peer with chaincode.recording.enabled against a chaincode, without a ledger. " +
+			"The chaincode receives the recorded input and the recorded responses to the messages it sends, and the messages which do not match the recording are reported. " +
+			"The chaincode either connects to the listen address like to a peer in development mode, or runs as a chaincode server at the chaincode address.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parsing of the command line is done so silence cmd usage
+			cmd.SilenceUsage = true
+			return replay(args[0], os.Stdout)
+		},
+	}
+
+	flagList := []string{
+		"name",
+		"listenAddress",
+		"chaincodeAddress",
+		"replayTimeout",
+	}
+	attachFlags(chaincodeReplayCmd, flagList)
+
+	return chaincodeReplayCmd
+}
+
+func replay(path string, out io.Writer) error {
+	if (replayListenAddress == "") == (replayChaincodeAddress == "") {
+		return errors.New("exactly one of --listenAddress and --chaincodeAddress must be specified")
+	}
+
+	rec, err := recording.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	execution, err := findExecution(rec, chaincodeName)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Replaying execution of chaincode %s of transaction %s on channel %s\n", execution.ChaincodeName, rec.TxId, rec.ChannelId)
+
+	ctx := context.Background()
+	if replayTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, replayTimeout)
+		defer cancel()
+	}
+
+	var report *recording.Report
+	if replayListenAddress != "" {
+		report, err = replayListening(ctx, execution, out)
+	} else {
+		report, err = replayConnecting(ctx, execution)
+	}
+	if report != nil {
+		printReport(out, report, len(execution.Exchanges))
+	}
+	if err != nil {
+		return errors.WithMessage(err, "replay failed")
+	}
+	if report.Diverged() {
+		return errors.New("the chaincode did not behave as recorded")
+	}
+	return nil
+}
+
+// findExecution returns the execution of the named chaincode, or the first
+// execution if no name is given.
+func findExecution(rec *msgs.TransactionRecording, name string) (*msgs.ExecutionRecording, error) {
+	for _, execution := range rec.Executions {
+		if name == "" || name == common.UndefinedParamValue || execution.ChaincodeName == name {
+			return execution, nil
+		}
+	}
+	if len(rec.Executions) == 0 {
+		return nil, errors.New("the recording holds no chaincode execution")
+	}
+	return nil, errors.Errorf("the recording holds no execution of chaincode %s", name)
+}
+
+func replayListening(ctx context.Context, execution *msgs.ExecutionRecording, out io.Writer) (*recording.Report, error) {
+	server, err := comm.NewGRPCServer(replayListenAddress, comm.ServerConfig{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create server")
+	}
+	replayServer := recording.NewServer(execution)
+	pb.RegisterChaincodeSupportServer(server.Server(), replayServer)
+	go server.Start()
+	defer server.Stop()
+
+	fmt.Fprintf(out, "Waiting for the chaincode to connect to %s\n", server.Address())
+	return replayServer.Report(ctx)
+}
+
+func replayConnecting(ctx context.Context, execution *msgs.ExecutionRecording) (*recording.Report, error) {
+	client, err := comm.NewGRPCClient(comm.ClientConfig{Timeout: replayConnTimeout})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create client")
+	}
+	conn, err := client.NewConnection(replayChaincodeAddress)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to connect to chaincode at %s", replayChaincodeAddress)
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewChaincodeClient(conn).Connect(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to chaincode at %s", replayChaincodeAddress)
+	}
+	defer stream.CloseSend()
+
+	return recording.Replay(stream, execution)
+}
+
+func printReport(out io.Writer, report *recording.Report, recorded int) {
+	fmt.Fprintf(out, "%d of %d recorded messages matched\n", report.Exchanges, recorded)
+	for _, d := range report.Divergences {
+		if d.Expected == nil {
+			fmt.Fprintf(out, "message %d: unexpected %s message: %s\n", d.Index, d.Actual.Type, d.Actual.Payload)
+			continue
+		}
+		fmt.Fprintf(out, "message %d: expected %s message: %s\n", d.Index, d.Expected.Type, d.Expected.Payload)
+		fmt.Fprintf(out, "message %d: received %s message: %s\n", d.Index, d.Actual.Type, d.Actual.Payload)
+	}
+	if report.Missing != 0 {
+		fmt.Fprintf(out, "%d recorded messages were not sent\n", report.Missing)
+	}
+	if report.Result == nil {
+		return
+	}
+	fmt.Fprintf(out, "result: %s %s\n", report.Result.Type, report.Result.Payload)
+	if report.Expected != nil && (report.Expected.Type != report.Result.Type || string(report.Expected.Payload) != string(report.Result.Payload)) {
+		fmt.Fprintf(out, "recorded result: %s %s\n", report.Expected.Type, report.Expected.Payload)
+	}
+}
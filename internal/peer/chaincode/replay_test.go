@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// scriptedChaincodeServer is a chaincode server which reads the key of the
+// input payload and returns its value.
+type scriptedChaincodeServer struct{}
+
+func (scriptedChaincodeServer) Connect(stream pb.Chaincode_ConnectServer) error {
+	if err := stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER}); err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch msg.Type {
+		case pb.ChaincodeMessage_TRANSACTION:
+			err = stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Txid: msg.Txid, ChannelId: msg.ChannelId, Payload: msg.Payload})
+		case pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR:
+			err = stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: msg.Txid, ChannelId: msg.ChannelId, Payload: msg.Payload})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	defer resetFlags()
+
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterChaincodeServer(server, scriptedChaincodeServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	record := func(key, value string) string {
+		recorder := &recording.Recorder{Path: dir}
+		tx := recorder.NewTransaction("mychannel", "txid-"+key)
+		execution := tx.StartExecution("mycc", &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "txid-" + key, ChannelId: "mychannel", Payload: []byte(key)})
+		execution.Exchange(
+			&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Txid: "txid-" + key, ChannelId: "mychannel", Payload: []byte("key")},
+			&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: "txid-" + key, ChannelId: "mychannel", Payload: []byte(value)},
+		)
+		execution.Finish(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "txid-" + key, ChannelId: "mychannel", Payload: []byte(value)})
+		path, err := recorder.Write(tx, nil)
+		require.NoError(t, err)
+		return path
+	}
+
+	resetFlags()
+	chaincodeName = ""
+	replayChaincodeAddress = lis.Addr().String()
+
+	t.Run("matching execution", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := replay(record("key", "value"), out)
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "Replaying execution of chaincode mycc of transaction txid-key on channel mychannel\n")
+		require.Contains(t, out.String(), "1 of 1 recorded messages matched\n")
+		require.Contains(t, out.String(), "result: COMPLETED value\n")
+	})
+
+	t.Run("diverging execution", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := replay(record("other-key", "value"), out)
+		require.EqualError(t, err, "the chaincode did not behave as recorded")
+		require.Contains(t, out.String(), "0 of 1 recorded messages matched\n")
+		require.Contains(t, out.String(), "message 0: expected GET_STATE message: key\n")
+		require.Contains(t, out.String(), "message 0: received GET_STATE message: other-key\n")
+		require.Contains(t, out.String(), "recorded result: COMPLETED value\n")
+	})
+
+	t.Run("unknown chaincode", func(t *testing.T) {
+		chaincodeName = "othercc"
+		defer func() { chaincodeName = "" }()
+		err := replay(record("key", "value"), &bytes.Buffer{})
+		require.EqualError(t, err, "the recording holds no execution of chaincode othercc")
+	})
+
+	t.Run("missing recording", func(t *testing.T) {
+		err := replay("missing.rec", &bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read recording")
+	})
+
+	t.Run("both addresses", func(t *testing.T) {
+		replayListenAddress = "127.0.0.1:0"
+		defer func() { replayListenAddress = "" }()
+		err := replay(record("key", "value"), &bytes.Buffer{})
+		require.EqualError(t, err, "exactly one of --listenAddress and --chaincodeAddress must be specified")
+	})
+}
@@ -308,9 +308,10 @@ func InitCmd(cmd *cobra.Command, args []string) {
 		LogSpec: loggingSpec,
 	})
 
-	// chaincode packaging does not require material from the local MSP
-	if cmd.CommandPath() == "peer lifecycle chaincode package" {
-		mainLogger.Debug("peer lifecycle chaincode package does not need to init crypto")
+	// chaincode packaging and replay do not require material from the local MSP
+	switch cmd.CommandPath() {
+	case "peer lifecycle chaincode package", "peer chaincode replay":
+		mainLogger.Debugf("%s does not need to init crypto", cmd.CommandPath())
 		return
 	}
 
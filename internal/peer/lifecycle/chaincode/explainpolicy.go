@@ -0,0 +1,223 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PolicyExplainer holds the dependencies needed to explain, offline,
+// the evaluation of an endorsement policy against a set of
+// proposal responses
+type PolicyExplainer struct {
+	Command        *cobra.Command
+	Input          *ExplainPolicyInput
+	Reader         Reader
+	Writer         io.Writer
+	CryptoProvider bccsp.BCCSP
+}
+
+// ExplainPolicyInput holds the input parameters for explaining
+// an endorsement policy evaluation
+type ExplainPolicyInput struct {
+	ConfigBlockFile       string
+	SignaturePolicy       string
+	ChannelConfigPolicy   string
+	ProposalResponseFiles []string
+	OutputFormat          string
+}
+
+// Validate checks for the required inputs
+func (e *ExplainPolicyInput) Validate() error {
+	if e.ConfigBlockFile == "" {
+		return errors.New("the channel config block must be specified")
+	}
+
+	if e.SignaturePolicy == "" && e.ChannelConfigPolicy == "" {
+		return errors.New("an endorsement policy must be specified")
+	}
+
+	if e.SignaturePolicy != "" && e.ChannelConfigPolicy != "" {
+		return errors.New("cannot specify both \"--signature-policy\" and \"--channel-config-policy\"")
+	}
+
+	if len(e.ProposalResponseFiles) == 0 {
+		return errors.New("at least one proposal response must be specified")
+	}
+
+	return nil
+}
+
+// ExplainPolicyCmd returns the cobra command for explaining the
+// evaluation of an endorsement policy against proposal responses
+func ExplainPolicyCmd(e *PolicyExplainer, cryptoProvider bccsp.BCCSP) *cobra.Command {
+	chaincodeExplainPolicyCmd := &cobra.Command{
+		Use:   "explainpolicy [proposalresponsefile...]",
+		Short: "Explain the evaluation of an endorsement policy against a set of proposal responses.",
+		Long: "Explain, without connecting to a peer, which principals of an endorsement policy are satisfied " +
+			"by the endorsements in a set of marshaled proposal responses. The MSPs and channel policies are " +
+			"loaded from the supplied channel config block.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if e == nil {
+				e = &PolicyExplainer{
+					Reader:         &persistence.FilesystemIO{},
+					Writer:         os.Stdout,
+					CryptoProvider: cryptoProvider,
+				}
+			}
+			e.Command = cmd
+			e.Input = &ExplainPolicyInput{
+				ConfigBlockFile:       configBlockFile,
+				SignaturePolicy:       signaturePolicy,
+				ChannelConfigPolicy:   channelConfigPolicy,
+				ProposalResponseFiles: args,
+				OutputFormat:          output,
+			}
+
+			return e.Explain()
+		},
+	}
+	flagList := []string{
+		"config-block",
+		"signature-policy",
+		"channel-config-policy",
+		"output",
+	}
+	attachFlags(chaincodeExplainPolicyCmd, flagList)
+
+	return chaincodeExplainPolicyCmd
+}
+
+// Explain evaluates the endorsement policy against the endorsements
+// of the proposal responses and prints the resulting explanation
+func (e *PolicyExplainer) Explain() error {
+	err := e.Input.Validate()
+	if err != nil {
+		return err
+	}
+
+	if e.Command != nil {
+		// Parsing of the command line is done so silence cmd usage
+		e.Command.SilenceUsage = true
+	}
+
+	bundle, err := e.loadBundle()
+	if err != nil {
+		return err
+	}
+
+	policy, err := e.policy(bundle)
+	if err != nil {
+		return err
+	}
+
+	signatureSet, err := e.signatureSet()
+	if err != nil {
+		return err
+	}
+
+	explanation := policies.ExplainSignedData(policy, signatureSet)
+
+	if strings.ToLower(e.Input.OutputFormat) == "json" {
+		explanationJSON, err := json.MarshalIndent(explanation, "", "\t")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal explanation")
+		}
+		fmt.Fprintf(e.Writer, "%s\n", explanationJSON)
+		return nil
+	}
+
+	fmt.Fprintf(e.Writer, "%s\n", explanation)
+	return nil
+}
+
+func (e *PolicyExplainer) loadBundle() (*channelconfig.Bundle, error) {
+	blockBytes, err := e.Reader.ReadFile(e.Input.ConfigBlockFile)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read config block at '%s'", e.Input.ConfigBlockFile)
+	}
+
+	block, err := protoutil.UnmarshalBlock(blockBytes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal config block")
+	}
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to extract config envelope")
+	}
+
+	bundle, err := channelconfig.NewBundleFromEnvelope(envelope, e.CryptoProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load channel config")
+	}
+
+	return bundle, nil
+}
+
+func (e *PolicyExplainer) policy(bundle *channelconfig.Bundle) (policies.Policy, error) {
+	if e.Input.ChannelConfigPolicy != "" {
+		policy, ok := bundle.PolicyManager().GetPolicy(e.Input.ChannelConfigPolicy)
+		if !ok {
+			return nil, errors.Errorf("channel config policy '%s' not found", e.Input.ChannelConfigPolicy)
+		}
+		return policy, nil
+	}
+
+	signaturePolicyEnvelope, err := policydsl.FromString(e.Input.SignaturePolicy)
+	if err != nil {
+		return nil, errors.Errorf("invalid signature policy: %s", e.Input.SignaturePolicy)
+	}
+
+	provider := &cauthdsl.EnvelopeBasedPolicyProvider{Deserializer: bundle.MSPManager()}
+	return provider.NewPolicy(signaturePolicyEnvelope)
+}
+
+// signatureSet returns the signed data of the endorsements in
+// the proposal responses, as they are checked during validation
+func (e *PolicyExplainer) signatureSet() ([]*protoutil.SignedData, error) {
+	var signatureSet []*protoutil.SignedData
+	for _, file := range e.Input.ProposalResponseFiles {
+		responseBytes, err := e.Reader.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read proposal response at '%s'", file)
+		}
+
+		response := &pb.ProposalResponse{}
+		if err := proto.Unmarshal(responseBytes, response); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal proposal response at '%s'", file)
+		}
+
+		if response.Endorsement == nil {
+			return nil, errors.Errorf("proposal response at '%s' has no endorsement", file)
+		}
+
+		signatureSet = append(signatureSet, &protoutil.SignedData{
+			Data:      append(append([]byte{}, response.Payload...), response.Endorsement.Endorser...),
+			Identity:  response.Endorsement.Endorser,
+			Signature: response.Endorsement.Signature,
+		})
+	}
+
+	return signatureSet, nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/internal/peer/lifecycle/chaincode"
+	"github.com/hyperledger/fabric/internal/peer/lifecycle/chaincode/mock"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("ExplainPolicy", func() {
+	Describe("PolicyExplainer", func() {
+		var (
+			mockReader *mock.Reader
+			input      *chaincode.ExplainPolicyInput
+			buffer     *gbytes.Buffer
+			explainer  *chaincode.PolicyExplainer
+			files      map[string][]byte
+		)
+
+		BeforeEach(func() {
+			cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+			Expect(err).NotTo(HaveOccurred())
+
+			block, err := configtxtest.MakeGenesisBlock("testchannel")
+			Expect(err).NotTo(HaveOccurred())
+
+			signer, err := mgmt.GetLocalMSP(cryptoProvider).GetDefaultSigningIdentity()
+			Expect(err).NotTo(HaveOccurred())
+			endorser, err := signer.Serialize()
+			Expect(err).NotTo(HaveOccurred())
+			payload := []byte("proposal-response-payload")
+			signature, err := signer.Sign(append(append([]byte{}, payload...), endorser...))
+			Expect(err).NotTo(HaveOccurred())
+
+			files = map[string][]byte{
+				"config.block": protoutil.MarshalOrPanic(block),
+				"response.pb": protoutil.MarshalOrPanic(&pb.ProposalResponse{
+					Payload: payload,
+					Endorsement: &pb.Endorsement{
+						Endorser:  endorser,
+						Signature: signature,
+					},
+				}),
+				"noendorsement.pb": protoutil.MarshalOrPanic(&pb.ProposalResponse{
+					Payload: payload,
+				}),
+			}
+
+			mockReader = &mock.Reader{}
+			mockReader.ReadFileStub = func(name string) ([]byte, error) {
+				if b, ok := files[name]; ok {
+					return b, nil
+				}
+				return nil, errors.New("file not found")
+			}
+
+			input = &chaincode.ExplainPolicyInput{
+				ConfigBlockFile:       "config.block",
+				SignaturePolicy:       "AND('SampleOrg.member', 'SampleOrg.admin')",
+				ProposalResponseFiles: []string{"response.pb"},
+			}
+
+			buffer = gbytes.NewBuffer()
+
+			explainer = &chaincode.PolicyExplainer{
+				Input:          input,
+				Reader:         mockReader,
+				Writer:         buffer,
+				CryptoProvider: cryptoProvider,
+			}
+		})
+
+		It("explains a signature policy", func() {
+			err := explainer.Explain()
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(buffer).Should(gbytes.Say(`OutOf\(2\): NOT SATISFIED \(1 of 2 required\)`))
+			Eventually(buffer).Should(gbytes.Say(`SignedBy\('SampleOrg.member'\): SATISFIED by \[SampleOrg:`))
+			Eventually(buffer).Should(gbytes.Say(`SignedBy\('SampleOrg.admin'\): NOT SATISFIED`))
+		})
+
+		It("explains a channel config policy", func() {
+			input.SignaturePolicy = ""
+			input.ChannelConfigPolicy = "/Channel/Application/Endorsement"
+
+			err := explainer.Explain()
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(buffer).Should(gbytes.Say(`/Channel/Application/Endorsement: SATISFIED`))
+			Eventually(buffer).Should(gbytes.Say(`ImplicitMeta\('Endorsement'\): SATISFIED \(1 of 1 required\)`))
+			Eventually(buffer).Should(gbytes.Say(`/Channel/Application/SampleOrg/Endorsement: SATISFIED`))
+		})
+
+		Context("when the output format is json", func() {
+			BeforeEach(func() {
+				input.OutputFormat = "json"
+			})
+
+			It("prints the explanation as json", func() {
+				err := explainer.Explain()
+				Expect(err).NotTo(HaveOccurred())
+
+				explanation := &policies.Explanation{}
+				err = json.Unmarshal(buffer.Contents(), explanation)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(explanation.Satisfied).To(BeFalse())
+				Expect(explanation.SubExplanations).To(HaveLen(2))
+				Expect(explanation.SubExplanations[0].Satisfied).To(BeTrue())
+			})
+		})
+
+		Context("when the config block is not specified", func() {
+			BeforeEach(func() {
+				input.ConfigBlockFile = ""
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("the channel config block must be specified"))
+			})
+		})
+
+		Context("when no policy is specified", func() {
+			BeforeEach(func() {
+				input.SignaturePolicy = ""
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("an endorsement policy must be specified"))
+			})
+		})
+
+		Context("when both policy types are specified", func() {
+			BeforeEach(func() {
+				input.ChannelConfigPolicy = "/Channel/Application/Endorsement"
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError(`cannot specify both "--signature-policy" and "--channel-config-policy"`))
+			})
+		})
+
+		Context("when no proposal responses are specified", func() {
+			BeforeEach(func() {
+				input.ProposalResponseFiles = nil
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("at least one proposal response must be specified"))
+			})
+		})
+
+		Context("when the config block cannot be read", func() {
+			BeforeEach(func() {
+				input.ConfigBlockFile = "missing.block"
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("failed to read config block at 'missing.block': file not found"))
+			})
+		})
+
+		Context("when the channel config policy does not exist", func() {
+			BeforeEach(func() {
+				input.SignaturePolicy = ""
+				input.ChannelConfigPolicy = "/Channel/Application/Missing"
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("channel config policy '/Channel/Application/Missing' not found"))
+			})
+		})
+
+		Context("when the signature policy is invalid", func() {
+			BeforeEach(func() {
+				input.SignaturePolicy = "NOT('SampleOrg.member')"
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("invalid signature policy: NOT('SampleOrg.member')"))
+			})
+		})
+
+		Context("when a proposal response has no endorsement", func() {
+			BeforeEach(func() {
+				input.ProposalResponseFiles = []string{"noendorsement.pb"}
+			})
+
+			It("returns an error", func() {
+				err := explainer.Explain()
+				Expect(err).To(MatchError("proposal response at 'noendorsement.pb' has no endorsement"))
+			})
+		})
+	})
+
+	Describe("ExplainPolicyCmd", func() {
+		var explainPolicyCmd *cobra.Command
+
+		BeforeEach(func() {
+			explainPolicyCmd = chaincode.ExplainPolicyCmd(nil, nil)
+			explainPolicyCmd.SetArgs([]string{
+				"--signature-policy=OR('SampleOrg.member')",
+			})
+		})
+
+		AfterEach(func() {
+			chaincode.ResetFlags()
+		})
+
+		It("sets up the explainer and attempts to explain the policy", func() {
+			err := explainPolicyCmd.Execute()
+			Expect(err).To(MatchError("the channel config block must be specified"))
+		})
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"path/filepath"
+
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/internal/peer/common"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PackageSigner holds the dependencies needed to sign
+// a chaincode package and write it
+type PackageSigner struct {
+	Command *cobra.Command
+	Input   *SignInput
+	Reader  Reader
+	Writer  Writer
+	Signer  Signer
+}
+
+// SignInput holds the input parameters for signing
+// a chaincode package
+type SignInput struct {
+	PackageFile string
+	OutputFile  string
+}
+
+// Validate checks for the required inputs
+func (s *SignInput) Validate() error {
+	if s.PackageFile == "" {
+		return errors.New("chaincode install package must be provided")
+	}
+	if s.OutputFile == "" {
+		return errors.New("output file must be specified")
+	}
+
+	return nil
+}
+
+// SignCmd returns the cobra command for signing a chaincode package
+func SignCmd(s *PackageSigner) *cobra.Command {
+	chaincodeSignCmd := &cobra.Command{
+		Use:   "sign [packagefile] [outputfile]",
+		Short: "Sign a chaincode package",
+		Long: "Add the signature of the local MSP identity over the metadata and code of a chaincode " +
+			"package and write the signed package to a file. Peers may require the signatures of " +
+			"specific principals to install a package. Signing a package changes its package ID.",
+		ValidArgs: []string{"2"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if s == nil {
+				signer, err := common.GetDefaultSigner()
+				if err != nil {
+					return errors.WithMessage(err, "failed to retrieve default signer")
+				}
+
+				s = &PackageSigner{
+					Reader: &persistence.FilesystemIO{},
+					Writer: &persistence.FilesystemIO{},
+					Signer: signer,
+				}
+			}
+			s.Command = cmd
+
+			return s.SignChaincodePackage(args)
+		},
+	}
+
+	return chaincodeSignCmd
+}
+
+// SignChaincodePackage signs a chaincode package.
+func (s *PackageSigner) SignChaincodePackage(args []string) error {
+	if s.Command != nil {
+		// Parsing of the command line is done so silence cmd usage
+		s.Command.SilenceUsage = true
+	}
+
+	if len(args) != 2 {
+		return errors.New("invalid number of args. expected the chaincode package and the output file")
+	}
+	s.Input = &SignInput{
+		PackageFile: args[0],
+		OutputFile:  args[1],
+	}
+
+	return s.Sign()
+}
+
+// Sign adds the signature of the signer to the chaincode
+// package and writes the signed package to disk
+func (s *PackageSigner) Sign() error {
+	err := s.Input.Validate()
+	if err != nil {
+		return err
+	}
+
+	pkgBytes, err := s.Reader.ReadFile(s.Input.PackageFile)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to read chaincode package at '%s'", s.Input.PackageFile)
+	}
+
+	signedBytes, err := persistence.SignChaincodePackage(pkgBytes, s.Signer)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to sign chaincode package at '%s'", s.Input.PackageFile)
+	}
+
+	dir, name := filepath.Split(s.Input.OutputFile)
+	if dir, err = filepath.Abs(dir); err != nil {
+		return err
+	}
+	err = s.Writer.WriteFile(dir, name, signedBytes)
+	if err != nil {
+		err = errors.Wrapf(err, "error writing signed chaincode package to %s", s.Input.OutputFile)
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric/core/chaincode/persistence"
+	"github.com/hyperledger/fabric/internal/peer/lifecycle/chaincode"
+	"github.com/hyperledger/fabric/internal/peer/lifecycle/chaincode/mock"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sign", func() {
+	Describe("PackageSigner", func() {
+		var (
+			mockReader    *mock.Reader
+			mockWriter    *mock.Writer
+			mockSigner    *mock.Signer
+			input         *chaincode.SignInput
+			packageSigner *chaincode.PackageSigner
+		)
+
+		BeforeEach(func() {
+			mockReader = &mock.Reader{}
+			mockReader.ReadFileReturns(chaincodePackage(), nil)
+
+			mockWriter = &mock.Writer{}
+
+			mockSigner = &mock.Signer{}
+			mockSigner.SerializeReturns([]byte("identity"), nil)
+			mockSigner.SignReturns([]byte("signature"), nil)
+
+			input = &chaincode.SignInput{
+				PackageFile: "pkgFile",
+				OutputFile:  "testDir/signedPackage",
+			}
+
+			packageSigner = &chaincode.PackageSigner{
+				Input:  input,
+				Reader: mockReader,
+				Writer: mockWriter,
+				Signer: mockSigner,
+			}
+		})
+
+		It("signs the chaincode package and writes it", func() {
+			err := packageSigner.Sign()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mockReader.ReadFileCallCount()).To(Equal(1))
+			Expect(mockReader.ReadFileArgsForCall(0)).To(Equal("pkgFile"))
+
+			Expect(mockWriter.WriteFileCallCount()).To(Equal(1))
+			dir, name, signedBytes := mockWriter.WriteFileArgsForCall(0)
+			wd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dir).To(Equal(filepath.Join(wd, "testDir")))
+			Expect(name).To(Equal("signedPackage"))
+
+			pkg, err := persistence.ChaincodePackageParser{MetadataProvider: &noDBArtifacts{}}.Parse(signedBytes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.Metadata.Label).To(Equal("testLabel"))
+			Expect(pkg.Signatures).To(Equal([]*persistence.ChaincodePackageSignature{
+				{Identity: []byte("identity"), Signature: []byte("signature")},
+			}))
+		})
+
+		Context("when the package file is not provided", func() {
+			BeforeEach(func() {
+				input.PackageFile = ""
+			})
+
+			It("returns an error", func() {
+				err := packageSigner.Sign()
+				Expect(err).To(MatchError("chaincode install package must be provided"))
+			})
+		})
+
+		Context("when the output file is not provided", func() {
+			BeforeEach(func() {
+				input.OutputFile = ""
+			})
+
+			It("returns an error", func() {
+				err := packageSigner.Sign()
+				Expect(err).To(MatchError("output file must be specified"))
+			})
+		})
+
+		Context("when the package cannot be read", func() {
+			BeforeEach(func() {
+				mockReader.ReadFileReturns(nil, errors.New("coffee"))
+			})
+
+			It("returns an error", func() {
+				err := packageSigner.Sign()
+				Expect(err).To(MatchError("failed to read chaincode package at 'pkgFile': coffee"))
+			})
+		})
+
+		Context("when the package cannot be signed", func() {
+			BeforeEach(func() {
+				mockSigner.SignReturns(nil, errors.New("tea"))
+			})
+
+			It("returns an error", func() {
+				err := packageSigner.Sign()
+				Expect(err).To(MatchError("failed to sign chaincode package at 'pkgFile': failed to create signature: tea"))
+			})
+		})
+
+		Context("when writing the signed package fails", func() {
+			BeforeEach(func() {
+				mockWriter.WriteFileReturns(errors.New("juice"))
+			})
+
+			It("returns an error", func() {
+				err := packageSigner.Sign()
+				Expect(err).To(MatchError(ContainSubstring("error writing signed chaincode package to testDir/signedPackage: juice")))
+			})
+		})
+	})
+
+	Describe("SignCmd", func() {
+		var (
+			signCmd    *cobra.Command
+			mockReader *mock.Reader
+			mockWriter *mock.Writer
+		)
+
+		BeforeEach(func() {
+			mockReader = &mock.Reader{}
+			mockReader.ReadFileReturns(chaincodePackage(), nil)
+			mockWriter = &mock.Writer{}
+			mockSigner := &mock.Signer{}
+			packageSigner := &chaincode.PackageSigner{
+				Reader: mockReader,
+				Writer: mockWriter,
+				Signer: mockSigner,
+			}
+			signCmd = chaincode.SignCmd(packageSigner)
+			signCmd.SetArgs([]string{"pkgFile", "outputFile"})
+		})
+
+		It("sets up the package signer and attempts to sign the package", func() {
+			err := signCmd.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockWriter.WriteFileCallCount()).To(Equal(1))
+		})
+
+		Context("when the output file is missing", func() {
+			BeforeEach(func() {
+				signCmd.SetArgs([]string{"pkgFile"})
+			})
+
+			It("returns an error", func() {
+				err := signCmd.Execute()
+				Expect(err).To(MatchError("invalid number of args. expected the chaincode package and the output file"))
+			})
+		})
+	})
+})
+
+type noDBArtifacts struct{}
+
+func (*noDBArtifacts) GetDBArtifacts(codePackage []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func chaincodePackage() []byte {
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+	for name, content := range map[string]string{
+		"metadata.json": `{"path":"testPath","type":"golang","label":"testLabel"}`,
+		"code.tar.gz":   "code",
+	} {
+		err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(content)), Mode: 0100644})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tw.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gw.Close()).To(Succeed())
+	return payload.Bytes()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	gossipservice "github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/internal/peer/common"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func gossipCmd() *cobra.Command {
+	gossipCommand.ResetFlags()
+	flags := gossipCommand.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", common.UndefinedParamValue, "Channel to report the gossip network of. All the channels of the peer are reported if not specified.")
+	addOperationsFlags(flags)
+	flags.StringVarP(&outputFormat, "output", "O", "", "The output format for the gossip network. Available formats: json. The default is a human readable format.")
+
+	return gossipCommand
+}
+
+var gossipCommand = &cobra.Command{
+	Use:   "gossip",
+	Short: "Shows the gossip network as seen by the peer.",
+	Long: `Shows, for each channel, the alive members known to the peer with their endpoints, PKI-IDs, ` +
+		`last alive times, ledger heights and whether the peer is connected to them, the state of the leader ` +
+		`election and the reachability of the anchor peers, followed by the members considered dead. ` +
+		`The peer must be running; the gossip network is retrieved from its operations service.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return errors.Errorf("trailing args detected: %s", strings.Join(args, " "))
+		}
+		cmd.SilenceUsage = true
+
+		client, err := newOperationsClient()
+		if err != nil {
+			return err
+		}
+		query := url.Values{}
+		if channelID != common.UndefinedParamValue {
+			query.Set("channel", channelID)
+		}
+		topology := &gossipservice.Topology{}
+		if err := client.get("/gossip/topology", query, topology); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch outputFormat {
+		case "json":
+			return printJSON(out, topology)
+		case "":
+			printGossipTopology(out, topology)
+			return nil
+		default:
+			return errors.Errorf("unsupported output format '%s'", outputFormat)
+		}
+	},
+}
+
+func printGossipTopology(out io.Writer, topology *gossipservice.Topology) {
+	if topology.Self != nil {
+		fmt.Fprintf(out, "Self: %s, PKI-ID: %s\n", topology.Self.Endpoint, topology.Self.PKIID)
+	}
+	for _, ch := range topology.Channels {
+		fmt.Fprintf(out, "Channel: %s\n", ch.Channel)
+		fmt.Fprintf(out, "  Ledger height: %d\n", ch.LedgerHeight)
+		if le := ch.LeaderElection; le != nil {
+			fmt.Fprintf(out, "  Leader election: %s, Leader: %t", le.Mode, le.Leader)
+			if le.Strategy != "" {
+				fmt.Fprintf(out, ", Strategy: %s", le.Strategy)
+			}
+			if le.PreferredLeader != "" {
+				fmt.Fprintf(out, ", Preferred leader: %s", le.PreferredLeader)
+			}
+			fmt.Fprintln(out)
+		}
+		if len(ch.Members) == 0 {
+			fmt.Fprintln(out, "  No alive members")
+		} else {
+			fmt.Fprintln(out, "  Members:")
+			for _, member := range ch.Members {
+				fmt.Fprintf(out, "    Endpoint: %s, PKI-ID: %s, Ledger height: %d, Last seen: %s, Connected: %t\n",
+					member.Endpoint, member.PKIID, member.LedgerHeight, formatTime(member.LastSeen), member.Connected)
+			}
+		}
+		if len(ch.AnchorPeers) > 0 {
+			fmt.Fprintln(out, "  Anchor peers:")
+			for _, anchorPeer := range ch.AnchorPeers {
+				fmt.Fprintf(out, "    Endpoint: %s, Alive: %t, Reachable: %t", anchorPeer.Endpoint, anchorPeer.Alive, anchorPeer.Reachable)
+				if anchorPeer.Error != "" {
+					fmt.Fprintf(out, ", Error: %s", anchorPeer.Error)
+				}
+				fmt.Fprintln(out)
+			}
+		}
+	}
+	if len(topology.DeadMembers) > 0 {
+		fmt.Fprintln(out, "Dead members:")
+		for _, member := range topology.DeadMembers {
+			fmt.Fprintf(out, "  Endpoint: %s, PKI-ID: %s, Last seen: %s\n", member.Endpoint, member.PKIID, formatTime(member.LastSeen))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGossipCmd(t *testing.T) {
+	var requestedURIs []string
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requestedURIs = append(requestedURIs, req.URL.RequestURI())
+		if req.URL.Query().Get("channel") == "unknown" {
+			http.Error(resp, "channel not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(resp, `{
+			"self": {"pki_id": "7030", "endpoint": "peer0:7051", "connected": false},
+			"channels": [
+				{"channel": "ch1", "ledger_height": 10, "leader_election": {"mode": "dynamic", "leader": true, "strategy": "priority", "preferred_leader": "peer0:7051"},
+				 "members": [{"pki_id": "7031", "endpoint": "peer1:7051", "ledger_height": 9, "last_seen": "2020-05-07T14:30:52Z", "connected": true}],
+				 "anchor_peers": [
+					{"endpoint": "peer1:7051", "alive": true, "reachable": true},
+					{"endpoint": "peer3:7051", "alive": false, "reachable": false, "error": "connection refused"}
+				 ]},
+				{"channel": "ch2", "ledger_height": 1, "leader_election": {"mode": "static", "leader": false},
+				 "members": [], "anchor_peers": []}
+			],
+			"dead_members": [{"pki_id": "7033", "endpoint": "peer3:7051", "last_seen": "2020-05-07T14:20:52Z", "connected": false}]
+		}`)
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	execute := func(args ...string) (string, error) {
+		cmd := gossipCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOutput(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("human readable", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address)
+		require.NoError(t, err)
+		require.Equal(t, "/gossip/topology", requestedURIs[len(requestedURIs)-1])
+		require.Equal(t, `Self: peer0:7051, PKI-ID: 7030
+Channel: ch1
+  Ledger height: 10
+  Leader election: dynamic, Leader: true, Strategy: priority, Preferred leader: peer0:7051
+  Members:
+    Endpoint: peer1:7051, PKI-ID: 7031, Ledger height: 9, Last seen: 2020-05-07T14:30:52Z, Connected: true
+  Anchor peers:
+    Endpoint: peer1:7051, Alive: true, Reachable: true
+    Endpoint: peer3:7051, Alive: false, Reachable: false, Error: connection refused
+Channel: ch2
+  Ledger height: 1
+  Leader election: static, Leader: false
+  No alive members
+Dead members:
+  Endpoint: peer3:7051, PKI-ID: 7033, Last seen: 2020-05-07T14:20:52Z
+`, out)
+	})
+
+	t.Run("json for a channel", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address, "-c", "ch1", "-O", "json")
+		require.NoError(t, err)
+		require.Equal(t, "/gossip/topology?channel=ch1", requestedURIs[len(requestedURIs)-1])
+		require.Contains(t, out, `"reachable": true`)
+	})
+
+	t.Run("unsupported output format", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-O", "yaml")
+		require.EqualError(t, err, "unsupported output format 'yaml'")
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-c", "unknown")
+		require.EqualError(t, err, "operations service of the peer returned 404 Not Found: channel not found")
+	})
+
+	t.Run("trailing args", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "ch1")
+		require.EqualError(t, err, "trailing args detected: ch1")
+	})
+}
@@ -11,6 +11,18 @@ import (
 )
 
 type PeerLedger struct {
+	CheckpointBlockStub        func(*common.Block, func()) error
+	checkpointBlockMutex       sync.RWMutex
+	checkpointBlockArgsForCall []struct {
+		arg1 *common.Block
+		arg2 func()
+	}
+	checkpointBlockReturns struct {
+		result1 error
+	}
+	checkpointBlockReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CloseStub        func()
 	closeMutex       sync.RWMutex
 	closeArgsForCall []struct {
@@ -118,6 +130,18 @@ type PeerLedger struct {
 		result1 ledgera.ResultsIterator
 		result2 error
 	}
+	GetCollsPvtdataRemovalInfoStub        func() ([]*ledger.CollPvtdataRemovalInfo, error)
+	getCollsPvtdataRemovalInfoMutex       sync.RWMutex
+	getCollsPvtdataRemovalInfoArgsForCall []struct {
+	}
+	getCollsPvtdataRemovalInfoReturns struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}
+	getCollsPvtdataRemovalInfoReturnsOnCall map[int]struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}
 	GetConfigHistoryRetrieverStub        func() (ledger.ConfigHistoryRetriever, error)
 	getConfigHistoryRetrieverMutex       sync.RWMutex
 	getConfigHistoryRetrieverArgsForCall []struct {
@@ -237,13 +261,76 @@ type PeerLedger struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *PeerLedger) CheckpointBlock(arg1 *common.Block, arg2 func()) error {
+	fake.checkpointBlockMutex.Lock()
+	ret, specificReturn := fake.checkpointBlockReturnsOnCall[len(fake.checkpointBlockArgsForCall)]
+	fake.checkpointBlockArgsForCall = append(fake.checkpointBlockArgsForCall, struct {
+		arg1 *common.Block
+		arg2 func()
+	}{arg1, arg2})
+	stub := fake.CheckpointBlockStub
+	fakeReturns := fake.checkpointBlockReturns
+	fake.recordInvocation("CheckpointBlock", []interface{}{arg1, arg2})
+	fake.checkpointBlockMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *PeerLedger) CheckpointBlockCallCount() int {
+	fake.checkpointBlockMutex.RLock()
+	defer fake.checkpointBlockMutex.RUnlock()
+	return len(fake.checkpointBlockArgsForCall)
+}
+
+func (fake *PeerLedger) CheckpointBlockCalls(stub func(*common.Block, func()) error) {
+	fake.checkpointBlockMutex.Lock()
+	defer fake.checkpointBlockMutex.Unlock()
+	fake.CheckpointBlockStub = stub
+}
+
+func (fake *PeerLedger) CheckpointBlockArgsForCall(i int) (*common.Block, func()) {
+	fake.checkpointBlockMutex.RLock()
+	defer fake.checkpointBlockMutex.RUnlock()
+	argsForCall := fake.checkpointBlockArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *PeerLedger) CheckpointBlockReturns(result1 error) {
+	fake.checkpointBlockMutex.Lock()
+	defer fake.checkpointBlockMutex.Unlock()
+	fake.CheckpointBlockStub = nil
+	fake.checkpointBlockReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *PeerLedger) CheckpointBlockReturnsOnCall(i int, result1 error) {
+	fake.checkpointBlockMutex.Lock()
+	defer fake.checkpointBlockMutex.Unlock()
+	fake.CheckpointBlockStub = nil
+	if fake.checkpointBlockReturnsOnCall == nil {
+		fake.checkpointBlockReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkpointBlockReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *PeerLedger) Close() {
 	fake.closeMutex.Lock()
 	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
 	}{})
+	stub := fake.CloseStub
 	fake.recordInvocation("Close", []interface{}{})
 	fake.closeMutex.Unlock()
-	if fake.CloseStub != nil {
+	if stub != nil {
 		fake.CloseStub()
 	}
 }
@@ -267,15 +354,16 @@ func (fake *PeerLedger) CommitLegacy(arg1 *ledger.BlockAndPvtData, arg2 *ledger.
 		arg1 *ledger.BlockAndPvtData
 		arg2 *ledger.CommitOptions
 	}{arg1, arg2})
+	stub := fake.CommitLegacyStub
+	fakeReturns := fake.commitLegacyReturns
 	fake.recordInvocation("CommitLegacy", []interface{}{arg1, arg2})
 	fake.commitLegacyMutex.Unlock()
-	if fake.CommitLegacyStub != nil {
-		return fake.CommitLegacyStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.commitLegacyReturns
 	return fakeReturns.result1
 }
 
@@ -333,15 +421,16 @@ func (fake *PeerLedger) CommitPvtDataOfOldBlocks(arg1 []*ledger.ReconciledPvtdat
 		arg1 []*ledger.ReconciledPvtdata
 		arg2 ledger.MissingPvtDataInfo
 	}{arg1Copy, arg2})
+	stub := fake.CommitPvtDataOfOldBlocksStub
+	fakeReturns := fake.commitPvtDataOfOldBlocksReturns
 	fake.recordInvocation("CommitPvtDataOfOldBlocks", []interface{}{arg1Copy, arg2})
 	fake.commitPvtDataOfOldBlocksMutex.Unlock()
-	if fake.CommitPvtDataOfOldBlocksStub != nil {
-		return fake.CommitPvtDataOfOldBlocksStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.commitPvtDataOfOldBlocksReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -396,15 +485,16 @@ func (fake *PeerLedger) DoesPvtDataInfoExist(arg1 uint64) (bool, error) {
 	fake.doesPvtDataInfoExistArgsForCall = append(fake.doesPvtDataInfoExistArgsForCall, struct {
 		arg1 uint64
 	}{arg1})
+	stub := fake.DoesPvtDataInfoExistStub
+	fakeReturns := fake.doesPvtDataInfoExistReturns
 	fake.recordInvocation("DoesPvtDataInfoExist", []interface{}{arg1})
 	fake.doesPvtDataInfoExistMutex.Unlock()
-	if fake.DoesPvtDataInfoExistStub != nil {
-		return fake.DoesPvtDataInfoExistStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.doesPvtDataInfoExistReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -464,15 +554,16 @@ func (fake *PeerLedger) GetBlockByHash(arg1 []byte) (*common.Block, error) {
 	fake.getBlockByHashArgsForCall = append(fake.getBlockByHashArgsForCall, struct {
 		arg1 []byte
 	}{arg1Copy})
+	stub := fake.GetBlockByHashStub
+	fakeReturns := fake.getBlockByHashReturns
 	fake.recordInvocation("GetBlockByHash", []interface{}{arg1Copy})
 	fake.getBlockByHashMutex.Unlock()
-	if fake.GetBlockByHashStub != nil {
-		return fake.GetBlockByHashStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getBlockByHashReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -527,15 +618,16 @@ func (fake *PeerLedger) GetBlockByNumber(arg1 uint64) (*common.Block, error) {
 	fake.getBlockByNumberArgsForCall = append(fake.getBlockByNumberArgsForCall, struct {
 		arg1 uint64
 	}{arg1})
+	stub := fake.GetBlockByNumberStub
+	fakeReturns := fake.getBlockByNumberReturns
 	fake.recordInvocation("GetBlockByNumber", []interface{}{arg1})
 	fake.getBlockByNumberMutex.Unlock()
-	if fake.GetBlockByNumberStub != nil {
-		return fake.GetBlockByNumberStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getBlockByNumberReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -590,15 +682,16 @@ func (fake *PeerLedger) GetBlockByTxID(arg1 string) (*common.Block, error) {
 	fake.getBlockByTxIDArgsForCall = append(fake.getBlockByTxIDArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetBlockByTxIDStub
+	fakeReturns := fake.getBlockByTxIDReturns
 	fake.recordInvocation("GetBlockByTxID", []interface{}{arg1})
 	fake.getBlockByTxIDMutex.Unlock()
-	if fake.GetBlockByTxIDStub != nil {
-		return fake.GetBlockByTxIDStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getBlockByTxIDReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -652,15 +745,16 @@ func (fake *PeerLedger) GetBlockchainInfo() (*common.BlockchainInfo, error) {
 	ret, specificReturn := fake.getBlockchainInfoReturnsOnCall[len(fake.getBlockchainInfoArgsForCall)]
 	fake.getBlockchainInfoArgsForCall = append(fake.getBlockchainInfoArgsForCall, struct {
 	}{})
+	stub := fake.GetBlockchainInfoStub
+	fakeReturns := fake.getBlockchainInfoReturns
 	fake.recordInvocation("GetBlockchainInfo", []interface{}{})
 	fake.getBlockchainInfoMutex.Unlock()
-	if fake.GetBlockchainInfoStub != nil {
-		return fake.GetBlockchainInfoStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getBlockchainInfoReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -708,15 +802,16 @@ func (fake *PeerLedger) GetBlocksIterator(arg1 uint64) (ledgera.ResultsIterator,
 	fake.getBlocksIteratorArgsForCall = append(fake.getBlocksIteratorArgsForCall, struct {
 		arg1 uint64
 	}{arg1})
+	stub := fake.GetBlocksIteratorStub
+	fakeReturns := fake.getBlocksIteratorReturns
 	fake.recordInvocation("GetBlocksIterator", []interface{}{arg1})
 	fake.getBlocksIteratorMutex.Unlock()
-	if fake.GetBlocksIteratorStub != nil {
-		return fake.GetBlocksIteratorStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getBlocksIteratorReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -765,20 +860,77 @@ func (fake *PeerLedger) GetBlocksIteratorReturnsOnCall(i int, result1 ledgera.Re
 	}{result1, result2}
 }
 
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfo() ([]*ledger.CollPvtdataRemovalInfo, error) {
+	fake.getCollsPvtdataRemovalInfoMutex.Lock()
+	ret, specificReturn := fake.getCollsPvtdataRemovalInfoReturnsOnCall[len(fake.getCollsPvtdataRemovalInfoArgsForCall)]
+	fake.getCollsPvtdataRemovalInfoArgsForCall = append(fake.getCollsPvtdataRemovalInfoArgsForCall, struct {
+	}{})
+	stub := fake.GetCollsPvtdataRemovalInfoStub
+	fakeReturns := fake.getCollsPvtdataRemovalInfoReturns
+	fake.recordInvocation("GetCollsPvtdataRemovalInfo", []interface{}{})
+	fake.getCollsPvtdataRemovalInfoMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoCallCount() int {
+	fake.getCollsPvtdataRemovalInfoMutex.RLock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.RUnlock()
+	return len(fake.getCollsPvtdataRemovalInfoArgsForCall)
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoCalls(stub func() ([]*ledger.CollPvtdataRemovalInfo, error)) {
+	fake.getCollsPvtdataRemovalInfoMutex.Lock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.Unlock()
+	fake.GetCollsPvtdataRemovalInfoStub = stub
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoReturns(result1 []*ledger.CollPvtdataRemovalInfo, result2 error) {
+	fake.getCollsPvtdataRemovalInfoMutex.Lock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.Unlock()
+	fake.GetCollsPvtdataRemovalInfoStub = nil
+	fake.getCollsPvtdataRemovalInfoReturns = struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PeerLedger) GetCollsPvtdataRemovalInfoReturnsOnCall(i int, result1 []*ledger.CollPvtdataRemovalInfo, result2 error) {
+	fake.getCollsPvtdataRemovalInfoMutex.Lock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.Unlock()
+	fake.GetCollsPvtdataRemovalInfoStub = nil
+	if fake.getCollsPvtdataRemovalInfoReturnsOnCall == nil {
+		fake.getCollsPvtdataRemovalInfoReturnsOnCall = make(map[int]struct {
+			result1 []*ledger.CollPvtdataRemovalInfo
+			result2 error
+		})
+	}
+	fake.getCollsPvtdataRemovalInfoReturnsOnCall[i] = struct {
+		result1 []*ledger.CollPvtdataRemovalInfo
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *PeerLedger) GetConfigHistoryRetriever() (ledger.ConfigHistoryRetriever, error) {
 	fake.getConfigHistoryRetrieverMutex.Lock()
 	ret, specificReturn := fake.getConfigHistoryRetrieverReturnsOnCall[len(fake.getConfigHistoryRetrieverArgsForCall)]
 	fake.getConfigHistoryRetrieverArgsForCall = append(fake.getConfigHistoryRetrieverArgsForCall, struct {
 	}{})
+	stub := fake.GetConfigHistoryRetrieverStub
+	fakeReturns := fake.getConfigHistoryRetrieverReturns
 	fake.recordInvocation("GetConfigHistoryRetriever", []interface{}{})
 	fake.getConfigHistoryRetrieverMutex.Unlock()
-	if fake.GetConfigHistoryRetrieverStub != nil {
-		return fake.GetConfigHistoryRetrieverStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getConfigHistoryRetrieverReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -825,15 +977,16 @@ func (fake *PeerLedger) GetMissingPvtDataTracker() (ledger.MissingPvtDataTracker
 	ret, specificReturn := fake.getMissingPvtDataTrackerReturnsOnCall[len(fake.getMissingPvtDataTrackerArgsForCall)]
 	fake.getMissingPvtDataTrackerArgsForCall = append(fake.getMissingPvtDataTrackerArgsForCall, struct {
 	}{})
+	stub := fake.GetMissingPvtDataTrackerStub
+	fakeReturns := fake.getMissingPvtDataTrackerReturns
 	fake.recordInvocation("GetMissingPvtDataTracker", []interface{}{})
 	fake.getMissingPvtDataTrackerMutex.Unlock()
-	if fake.GetMissingPvtDataTrackerStub != nil {
-		return fake.GetMissingPvtDataTrackerStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getMissingPvtDataTrackerReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -882,15 +1035,16 @@ func (fake *PeerLedger) GetPvtDataAndBlockByNum(arg1 uint64, arg2 ledger.PvtNsCo
 		arg1 uint64
 		arg2 ledger.PvtNsCollFilter
 	}{arg1, arg2})
+	stub := fake.GetPvtDataAndBlockByNumStub
+	fakeReturns := fake.getPvtDataAndBlockByNumReturns
 	fake.recordInvocation("GetPvtDataAndBlockByNum", []interface{}{arg1, arg2})
 	fake.getPvtDataAndBlockByNumMutex.Unlock()
-	if fake.GetPvtDataAndBlockByNumStub != nil {
-		return fake.GetPvtDataAndBlockByNumStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getPvtDataAndBlockByNumReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -946,15 +1100,16 @@ func (fake *PeerLedger) GetPvtDataByNum(arg1 uint64, arg2 ledger.PvtNsCollFilter
 		arg1 uint64
 		arg2 ledger.PvtNsCollFilter
 	}{arg1, arg2})
+	stub := fake.GetPvtDataByNumStub
+	fakeReturns := fake.getPvtDataByNumReturns
 	fake.recordInvocation("GetPvtDataByNum", []interface{}{arg1, arg2})
 	fake.getPvtDataByNumMutex.Unlock()
-	if fake.GetPvtDataByNumStub != nil {
-		return fake.GetPvtDataByNumStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getPvtDataByNumReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1009,15 +1164,16 @@ func (fake *PeerLedger) GetTransactionByID(arg1 string) (*peer.ProcessedTransact
 	fake.getTransactionByIDArgsForCall = append(fake.getTransactionByIDArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetTransactionByIDStub
+	fakeReturns := fake.getTransactionByIDReturns
 	fake.recordInvocation("GetTransactionByID", []interface{}{arg1})
 	fake.getTransactionByIDMutex.Unlock()
-	if fake.GetTransactionByIDStub != nil {
-		return fake.GetTransactionByIDStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getTransactionByIDReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1072,15 +1228,16 @@ func (fake *PeerLedger) GetTxValidationCodeByTxID(arg1 string) (peer.TxValidatio
 	fake.getTxValidationCodeByTxIDArgsForCall = append(fake.getTxValidationCodeByTxIDArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetTxValidationCodeByTxIDStub
+	fakeReturns := fake.getTxValidationCodeByTxIDReturns
 	fake.recordInvocation("GetTxValidationCodeByTxID", []interface{}{arg1})
 	fake.getTxValidationCodeByTxIDMutex.Unlock()
-	if fake.GetTxValidationCodeByTxIDStub != nil {
-		return fake.GetTxValidationCodeByTxIDStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getTxValidationCodeByTxIDReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1134,15 +1291,16 @@ func (fake *PeerLedger) NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor,
 	ret, specificReturn := fake.newHistoryQueryExecutorReturnsOnCall[len(fake.newHistoryQueryExecutorArgsForCall)]
 	fake.newHistoryQueryExecutorArgsForCall = append(fake.newHistoryQueryExecutorArgsForCall, struct {
 	}{})
+	stub := fake.NewHistoryQueryExecutorStub
+	fakeReturns := fake.newHistoryQueryExecutorReturns
 	fake.recordInvocation("NewHistoryQueryExecutor", []interface{}{})
 	fake.newHistoryQueryExecutorMutex.Unlock()
-	if fake.NewHistoryQueryExecutorStub != nil {
-		return fake.NewHistoryQueryExecutorStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.newHistoryQueryExecutorReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1189,15 +1347,16 @@ func (fake *PeerLedger) NewQueryExecutor() (ledger.QueryExecutor, error) {
 	ret, specificReturn := fake.newQueryExecutorReturnsOnCall[len(fake.newQueryExecutorArgsForCall)]
 	fake.newQueryExecutorArgsForCall = append(fake.newQueryExecutorArgsForCall, struct {
 	}{})
+	stub := fake.NewQueryExecutorStub
+	fakeReturns := fake.newQueryExecutorReturns
 	fake.recordInvocation("NewQueryExecutor", []interface{}{})
 	fake.newQueryExecutorMutex.Unlock()
-	if fake.NewQueryExecutorStub != nil {
-		return fake.NewQueryExecutorStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.newQueryExecutorReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1245,15 +1404,16 @@ func (fake *PeerLedger) NewTxSimulator(arg1 string) (ledger.TxSimulator, error)
 	fake.newTxSimulatorArgsForCall = append(fake.newTxSimulatorArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.NewTxSimulatorStub
+	fakeReturns := fake.newTxSimulatorReturns
 	fake.recordInvocation("NewTxSimulator", []interface{}{arg1})
 	fake.newTxSimulatorMutex.Unlock()
-	if fake.NewTxSimulatorStub != nil {
-		return fake.NewTxSimulatorStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.newTxSimulatorReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1305,6 +1465,8 @@ func (fake *PeerLedger) NewTxSimulatorReturnsOnCall(i int, result1 ledger.TxSimu
 func (fake *PeerLedger) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.checkpointBlockMutex.RLock()
+	defer fake.checkpointBlockMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
 	fake.commitLegacyMutex.RLock()
@@ -1323,6 +1485,8 @@ func (fake *PeerLedger) Invocations() map[string][][]interface{} {
 	defer fake.getBlockchainInfoMutex.RUnlock()
 	fake.getBlocksIteratorMutex.RLock()
 	defer fake.getBlocksIteratorMutex.RUnlock()
+	fake.getCollsPvtdataRemovalInfoMutex.RLock()
+	defer fake.getCollsPvtdataRemovalInfoMutex.RUnlock()
 	fake.getConfigHistoryRetrieverMutex.RLock()
 	defer fake.getConfigHistoryRetrieverMutex.RUnlock()
 	fake.getMissingPvtDataTrackerMutex.RLock()
@@ -1359,7 +1523,3 @@ func (fake *PeerLedger) recordInvocation(key string, args []interface{}) {
 	}
 	fake.invocations[key] = append(fake.invocations[key], args)
 }
-
-func (fake *PeerLedger) CheckpointBlock(*common.Block, func()) error {
-	return nil
-}
@@ -0,0 +1,403 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
+	gossipprivdata "github.com/hyperledger/fabric/gossip/privdata"
+	"github.com/hyperledger/fabric/internal/peer/common"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	viper "github.com/spf13/viper2015"
+)
+
+var (
+	operationsAddress string
+	operationsCAFile  string
+	operationsCert    string
+	operationsKey     string
+	outputFormat      string
+
+	accessLogChaincode  string
+	accessLogCollection string
+	accessLogKey        string
+	accessLogSince      string
+	accessLogLimit      int
+)
+
+func pvtdataCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pvtdata",
+		Short: "Inspects the private data of the peer.",
+		Long:  "Inspects the private data of the peer: status|removal|accesslog|genkey.",
+	}
+	cmd.AddCommand(pvtdataStatusCmd())
+	cmd.AddCommand(pvtdataRemovalCmd())
+	cmd.AddCommand(pvtdataAccessLogCmd())
+	cmd.AddCommand(pvtdataGenKeyCmd(factory.GetDefault))
+	return cmd
+}
+
+func pvtdataStatusCmd() *cobra.Command {
+	pvtdataStatusCommand.ResetFlags()
+	flags := pvtdataStatusCommand.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", common.UndefinedParamValue, "Channel to report the status of. All the channels of the peer are reported if not specified.")
+	addOperationsFlags(flags)
+	flags.StringVarP(&outputFormat, "output", "O", "", "The output format for the status. Available formats: json. The default is a human readable format.")
+
+	return pvtdataStatusCommand
+}
+
+var pvtdataStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the status of the reconciliation of missing private data.",
+	Long: `Shows, for each channel, the private data of eligible collections that is missing on the peer, ` +
+		`the outcome of the last reconciliation attempt and the peers from which missing private data is ` +
+		`currently not requested. The peer must be running; the status is retrieved from its operations service.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := newOperationsClient()
+		if err != nil {
+			return err
+		}
+		query := url.Values{}
+		if channelID != common.UndefinedParamValue {
+			query.Set("channel", channelID)
+		}
+		var statuses []*gossipprivdata.ReconciliationStatus
+		if err := client.get("/pvtdata/status", query, &statuses); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch outputFormat {
+		case "json":
+			return printJSON(out, statuses)
+		case "":
+			printPvtDataStatus(out, statuses)
+			return nil
+		default:
+			return errors.Errorf("unsupported output format '%s'", outputFormat)
+		}
+	},
+}
+
+func pvtdataRemovalCmd() *cobra.Command {
+	pvtdataRemovalCommand.ResetFlags()
+	flags := pvtdataRemovalCommand.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", common.UndefinedParamValue, "Channel to report the status of. All the channels of the peer are reported if not specified.")
+	addOperationsFlags(flags)
+	flags.StringVarP(&outputFormat, "output", "O", "", "The output format for the status. Available formats: json. The default is a human readable format.")
+
+	return pvtdataRemovalCommand
+}
+
+var pvtdataRemovalCommand = &cobra.Command{
+	Use:   "removal",
+	Short: "Shows the status of the removal of the private data of ineligible collections.",
+	Long: `Shows, for each channel, the collections for which the peer lost the eligibility, the block in which ` +
+		`it lost the eligibility and whether the private data of the collection is still to be removed from the peer. ` +
+		`The peer must be running; the status is retrieved from its operations service.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := newOperationsClient()
+		if err != nil {
+			return err
+		}
+		query := url.Values{}
+		if channelID != common.UndefinedParamValue {
+			query.Set("channel", channelID)
+		}
+		var statuses []*peer.PvtdataRemovalStatus
+		if err := client.get("/pvtdata/removal", query, &statuses); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch outputFormat {
+		case "json":
+			return printJSON(out, statuses)
+		case "":
+			printPvtDataRemoval(out, statuses)
+			return nil
+		default:
+			return errors.Errorf("unsupported output format '%s'", outputFormat)
+		}
+	},
+}
+
+func pvtdataAccessLogCmd() *cobra.Command {
+	pvtdataAccessLogCommand.ResetFlags()
+	flags := pvtdataAccessLogCommand.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", common.UndefinedParamValue, "Channel of the private data.")
+	flags.StringVarP(&accessLogChaincode, "chaincode", "n", "", "Chaincode of the private data. All the chaincodes are reported if not specified.")
+	flags.StringVar(&accessLogCollection, "collection", "", "Collection of the private data. Requires --chaincode. All the collections are reported if not specified.")
+	flags.StringVar(&accessLogKey, "key", "", "Key of the private data. Requires --collection. All the keys are reported if not specified.")
+	flags.StringVar(&accessLogSince, "since", "", "Only reports the accesses since the given time, in RFC3339 format.")
+	flags.IntVar(&accessLogLimit, "limit", 0, "The maximum number of accesses reported. All the accesses are reported if not specified.")
+	addOperationsFlags(flags)
+	flags.StringVarP(&outputFormat, "output", "O", "", "The output format for the accesses. Available formats: json. The default is a human readable format.")
+
+	return pvtdataAccessLogCommand
+}
+
+var pvtdataAccessLogCommand = &cobra.Command{
+	Use:   "accesslog",
+	Short: "Shows the recorded accesses to private data.",
+	Long: `Shows the accesses to the private data of a channel recorded in the private data access log of the peer: ` +
+		`the keys read by chaincode on behalf of clients and the keys sent to other peers which pulled the private data. ` +
+		`The peer must be running; the accesses are retrieved from its operations service.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if channelID == common.UndefinedParamValue {
+			return errors.New("Must supply channel ID")
+		}
+		if accessLogSince != "" {
+			if _, err := time.Parse(time.RFC3339, accessLogSince); err != nil {
+				return errors.Wrap(err, "invalid --since")
+			}
+		}
+		cmd.SilenceUsage = true
+
+		client, err := newOperationsClient()
+		if err != nil {
+			return err
+		}
+		query := url.Values{}
+		query.Set("channel", channelID)
+		for param, value := range map[string]string{
+			"chaincode":  accessLogChaincode,
+			"collection": accessLogCollection,
+			"key":        accessLogKey,
+			"since":      accessLogSince,
+		} {
+			if value != "" {
+				query.Set(param, value)
+			}
+		}
+		if accessLogLimit > 0 {
+			query.Set("limit", strconv.Itoa(accessLogLimit))
+		}
+		var entries []*pvtdataaccesslog.Entry
+		if err := client.get("/pvtdata/accesslog", query, &entries); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch outputFormat {
+		case "json":
+			return printJSON(out, entries)
+		case "":
+			printPvtDataAccessLog(out, entries)
+			return nil
+		default:
+			return errors.Errorf("unsupported output format '%s'", outputFormat)
+		}
+	},
+}
+
+// pvtdataGenKeyCmd returns the command which generates a key with the crypto provider
+// of the peer, which is obtained once the command line is parsed and the peer configured
+func pvtdataGenKeyCmd(cryptoProvider func() bccsp.BCCSP) *cobra.Command {
+	return &cobra.Command{
+		Use:   "genkey",
+		Short: "Generates a key for the encryption of private data.",
+		Long: `Generates an AES-256 key in the key store of the BCCSP of the peer and prints its identifier, ` +
+			`which is to be configured as ledger.pvtdataEncryption.keyID to encrypt the private data stored by the peer. ` +
+			`The peer does not need to be running.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.Errorf("trailing args detected: %s", strings.Join(args, " "))
+			}
+			cmd.SilenceUsage = true
+
+			key, err := cryptoProvider().KeyGen(&bccsp.AES256KeyGenOpts{})
+			if err != nil {
+				return errors.WithMessage(err, "failed to generate the key")
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), hex.EncodeToString(key.SKI()))
+			return nil
+		},
+	}
+}
+
+func printPvtDataAccessLog(out io.Writer, entries []*pvtdataaccesslog.Entry) {
+	for _, entry := range entries {
+		accessor := fmt.Sprintf("Client: %s %s", entry.MSPID, entry.CertHash)
+		if entry.Endpoint != "" || entry.PKIID != "" {
+			accessor = fmt.Sprintf("Peer: %s %s", entry.Endpoint, entry.PKIID)
+		}
+		fmt.Fprintf(out, "%s Chaincode: %s, Collection: %s, Key: %s, TxID: %s, %s\n",
+			entry.Time.Format(time.RFC3339), entry.Chaincode, entry.Collection, entry.Key, entry.TxID, accessor)
+	}
+}
+
+func printPvtDataStatus(out io.Writer, statuses []*gossipprivdata.ReconciliationStatus) {
+	for _, status := range statuses {
+		fmt.Fprintf(out, "Channel: %s\n", status.Channel)
+		if !status.Enabled {
+			fmt.Fprintln(out, "  Reconciliation is disabled")
+			continue
+		}
+		fmt.Fprintf(out, "  Last attempt: %s, Last success: %s, Reconciled in last attempt: %d\n",
+			formatTime(status.LastAttempt), formatTime(status.LastSuccess), status.LastReconciled)
+		if status.LastError != "" {
+			fmt.Fprintf(out, "  Last error: %s\n", status.LastError)
+		}
+		if status.OldestMissingBlock == nil {
+			fmt.Fprintln(out, "  No missing private data")
+		} else {
+			fmt.Fprintf(out, "  Oldest missing block: %d\n", *status.OldestMissingBlock)
+			fmt.Fprintln(out, "  Missing private data:")
+			for _, missing := range status.Missing {
+				fmt.Fprintf(out, "    Chaincode: %s, Collection: %s, Priority: %d, Transactions: %d, Deprioritized: %d, Oldest block: %d\n",
+					missing.Chaincode, missing.Collection, missing.Priority, missing.Count, missing.Deprioritized, missing.OldestBlock)
+			}
+		}
+		if len(status.BackedOffPeers) > 0 {
+			fmt.Fprintln(out, "  Peers backed off:")
+			for _, peer := range status.BackedOffPeers {
+				fmt.Fprintf(out, "    Endpoint: %s, Failures: %d, Until: %s\n", peer.Endpoint, peer.Failures, peer.Until.Format(time.RFC3339))
+			}
+		}
+	}
+}
+
+func printPvtDataRemoval(out io.Writer, statuses []*peer.PvtdataRemovalStatus) {
+	for _, status := range statuses {
+		fmt.Fprintf(out, "Channel: %s\n", status.Channel)
+		if len(status.Collections) == 0 {
+			fmt.Fprintln(out, "  No ineligible collections")
+			continue
+		}
+		for _, coll := range status.Collections {
+			removal := "done"
+			if coll.Pending {
+				removal = "pending"
+			}
+			fmt.Fprintf(out, "  Chaincode: %s, Collection: %s, Ineligible since block: %d, Removal: %s\n",
+				coll.Chaincode, coll.Collection, coll.BlockNum, removal)
+		}
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	bytes, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal output")
+	}
+	fmt.Fprintln(out, string(bytes))
+	return nil
+}
+
+// addOperationsFlags adds the flags of the commands which
+// retrieve information from the operations service of the peer
+func addOperationsFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&operationsAddress, "operationsAddress", "", "The address of the operations service of the peer. Defaults to operations.listenAddress.")
+	flags.StringVar(&operationsCAFile, "cafile", "", "Path to the PEM encoded CA certificates trusted to verify the operations service of the peer when TLS is enabled.")
+	flags.StringVar(&operationsCert, "certfile", "", "Path to the PEM encoded client certificate for the operations service of the peer.")
+	flags.StringVar(&operationsKey, "keyfile", "", "Path to the PEM encoded client key for the operations service of the peer.")
+}
+
+// operationsClient retrieves information from the operations service of the peer
+type operationsClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newOperationsClient() (*operationsClient, error) {
+	address := operationsAddress
+	if address == "" {
+		address = viper.GetString("operations.listenAddress")
+	}
+	if address == "" {
+		return nil, errors.New("the address of the operations service must be specified with --operationsAddress or operations.listenAddress")
+	}
+
+	if !viper.GetBool("operations.tls.enabled") {
+		return &operationsClient{
+			baseURL:    "http://" + address,
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if operationsCAFile != "" {
+		caPEM, err := ioutil.ReadFile(operationsCAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read CA certificates from %s", operationsCAFile)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.Errorf("no CA certificates found in %s", operationsCAFile)
+		}
+	}
+	if operationsCert != "" || operationsKey != "" {
+		cert, err := tls.LoadX509KeyPair(operationsCert, operationsKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load the client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return &operationsClient{
+		baseURL: "https://" + address,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// get retrieves the resource at the given path and decodes the JSON response into v
+func (c *operationsClient) get(path string, query url.Values, v interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	resp, err := c.httpClient.Get(target)
+	if err != nil {
+		return errors.Wrap(err, "failed to contact the operations service of the peer")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the response of the operations service of the peer")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("operations service of the peer returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.Wrap(err, "failed to decode the response of the operations service of the peer")
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	viper "github.com/spf13/viper2015"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPvtdataStatusCmd(t *testing.T) {
+	var requestedURIs []string
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requestedURIs = append(requestedURIs, req.URL.RequestURI())
+		if req.URL.Query().Get("channel") == "unknown" {
+			http.Error(resp, "channel not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(resp, `[
+			{"channel": "ch1", "enabled": false, "missing": null},
+			{"channel": "ch2", "enabled": true, "last_attempt": "2020-05-07T14:30:52Z", "last_reconciled": 2,
+			 "last_error": "failed to commit private data",
+			 "oldest_missing_block": 3,
+			 "missing": [{"chaincode": "cc1", "collection": "coll1", "priority": 5, "count": 4, "deprioritized": 1, "oldest_block": 3}],
+			 "backed_off_peers": [{"endpoint": "peer1:7051", "failures": 2, "until": "2020-05-07T14:32:52Z"}]}
+		]`)
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	execute := func(args ...string) (string, error) {
+		cmd := pvtdataStatusCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOutput(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("human readable", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address)
+		require.NoError(t, err)
+		require.Equal(t, "/pvtdata/status", requestedURIs[len(requestedURIs)-1])
+		require.Equal(t, `Channel: ch1
+  Reconciliation is disabled
+Channel: ch2
+  Last attempt: 2020-05-07T14:30:52Z, Last success: never, Reconciled in last attempt: 2
+  Last error: failed to commit private data
+  Oldest missing block: 3
+  Missing private data:
+    Chaincode: cc1, Collection: coll1, Priority: 5, Transactions: 4, Deprioritized: 1, Oldest block: 3
+  Peers backed off:
+    Endpoint: peer1:7051, Failures: 2, Until: 2020-05-07T14:32:52Z
+`, out)
+	})
+
+	t.Run("json for a channel", func(t *testing.T) {
+		viper.Set("operations.listenAddress", address)
+		defer viper.Set("operations.listenAddress", "")
+
+		out, err := execute("-c", "ch2", "-O", "json")
+		require.NoError(t, err)
+		require.Equal(t, "/pvtdata/status?channel=ch2", requestedURIs[len(requestedURIs)-1])
+		require.Contains(t, out, `"oldest_missing_block": 3`)
+	})
+
+	t.Run("unsupported output format", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-O", "yaml")
+		require.EqualError(t, err, "unsupported output format 'yaml'")
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-c", "unknown")
+		require.EqualError(t, err, "operations service of the peer returned 404 Not Found: channel not found")
+	})
+
+	t.Run("missing address", func(t *testing.T) {
+		_, err := execute()
+		require.EqualError(t, err, "the address of the operations service must be specified with --operationsAddress or operations.listenAddress")
+	})
+
+	t.Run("invalid TLS configuration", func(t *testing.T) {
+		viper.Set("operations.tls.enabled", true)
+		defer viper.Set("operations.tls.enabled", false)
+
+		_, err := execute("--operationsAddress", address, "--cafile", "testdata/missing.pem")
+		require.EqualError(t, err, "failed to read CA certificates from testdata/missing.pem: open testdata/missing.pem: no such file or directory")
+	})
+}
+
+func TestPvtdataRemovalCmd(t *testing.T) {
+	var requestedURIs []string
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requestedURIs = append(requestedURIs, req.URL.RequestURI())
+		if req.URL.Query().Get("channel") == "unknown" {
+			http.Error(resp, "channel not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(resp, `[
+			{"channel": "ch1", "collections": []},
+			{"channel": "ch2", "collections": [
+				{"chaincode": "cc1", "collection": "coll2", "block_num": 12, "pending": true},
+				{"chaincode": "cc1", "collection": "coll1", "block_num": 7, "pending": false}
+			]}
+		]`)
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	execute := func(args ...string) (string, error) {
+		cmd := pvtdataRemovalCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOutput(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("human readable", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address)
+		require.NoError(t, err)
+		require.Equal(t, "/pvtdata/removal", requestedURIs[len(requestedURIs)-1])
+		require.Equal(t, `Channel: ch1
+  No ineligible collections
+Channel: ch2
+  Chaincode: cc1, Collection: coll2, Ineligible since block: 12, Removal: pending
+  Chaincode: cc1, Collection: coll1, Ineligible since block: 7, Removal: done
+`, out)
+	})
+
+	t.Run("json for a channel", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address, "-c", "ch2", "-O", "json")
+		require.NoError(t, err)
+		require.Equal(t, "/pvtdata/removal?channel=ch2", requestedURIs[len(requestedURIs)-1])
+		require.Contains(t, out, `"block_num": 12`)
+		require.Contains(t, out, `"pending": true`)
+	})
+
+	t.Run("unsupported output format", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-O", "yaml")
+		require.EqualError(t, err, "unsupported output format 'yaml'")
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-c", "unknown")
+		require.EqualError(t, err, "operations service of the peer returned 404 Not Found: channel not found")
+	})
+}
+
+func TestPvtdataAccessLogCmd(t *testing.T) {
+	var requestedURIs []string
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requestedURIs = append(requestedURIs, req.URL.RequestURI())
+		fmt.Fprint(resp, `[
+			{"time": "2020-05-07T14:30:52Z", "channel": "ch1", "tx_id": "tx1", "chaincode": "cc1", "collection": "coll1", "key": "key1",
+			 "msp_id": "Org1MSP", "cert_hash": "ab01"},
+			{"time": "2020-05-07T14:31:52Z", "channel": "ch1", "tx_id": "tx2", "chaincode": "cc1", "collection": "coll1", "key": "key1",
+			 "pki_id": "0a0b", "endpoint": "peer1:7051"}
+		]`)
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	execute := func(args ...string) (string, error) {
+		cmd := pvtdataAccessLogCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOutput(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("human readable", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address, "-c", "ch1", "-n", "cc1", "--collection", "coll1", "--key", "key1",
+			"--since", "2020-05-07T00:00:00Z", "--limit", "10")
+		require.NoError(t, err)
+		require.Equal(t, "/pvtdata/accesslog?chaincode=cc1&channel=ch1&collection=coll1&key=key1&limit=10&since=2020-05-07T00%3A00%3A00Z",
+			requestedURIs[len(requestedURIs)-1])
+		require.Equal(t, `2020-05-07T14:30:52Z Chaincode: cc1, Collection: coll1, Key: key1, TxID: tx1, Client: Org1MSP ab01
+2020-05-07T14:31:52Z Chaincode: cc1, Collection: coll1, Key: key1, TxID: tx2, Peer: peer1:7051 0a0b
+`, out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := execute("--operationsAddress", address, "-c", "ch1", "-O", "json")
+		require.NoError(t, err)
+		require.Equal(t, "/pvtdata/accesslog?channel=ch1", requestedURIs[len(requestedURIs)-1])
+		require.Contains(t, out, `"endpoint": "peer1:7051"`)
+	})
+
+	t.Run("missing channel", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address)
+		require.EqualError(t, err, "Must supply channel ID")
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		_, err := execute("--operationsAddress", address, "-c", "ch1", "--since", "yesterday")
+		require.EqualError(t, err, `invalid --since: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`)
+	})
+}
+
+func TestPvtdataGenKeyCmd(t *testing.T) {
+	csp, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewInMemoryKeyStore())
+	require.NoError(t, err)
+
+	execute := func(cryptoProvider bccsp.BCCSP, args ...string) (string, error) {
+		cmd := pvtdataGenKeyCmd(func() bccsp.BCCSP { return cryptoProvider })
+		out := &bytes.Buffer{}
+		cmd.SetOutput(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := execute(csp)
+	require.NoError(t, err)
+	keyID, err := hex.DecodeString(strings.TrimSuffix(out, "\n"))
+	require.NoError(t, err)
+	key, err := csp.GetKey(keyID)
+	require.NoError(t, err)
+	require.True(t, key.Symmetric())
+
+	_, err = execute(csp, "arg")
+	require.EqualError(t, err, "trailing args detected: arg")
+
+	readOnlyCSP, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	require.NoError(t, err)
+	_, err = execute(readOnlyCSP)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to generate the key")
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	ccdef "github.com/hyperledger/fabric/common/chaincode"
+	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/crypto/tlsgen"
 	"github.com/hyperledger/fabric/common/deliver"
@@ -48,6 +49,8 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/lifecycle"
 	"github.com/hyperledger/fabric/core/chaincode/persistence"
 	"github.com/hyperledger/fabric/core/chaincode/platforms"
+	"github.com/hyperledger/fabric/core/chaincode/platforms/golang"
+	"github.com/hyperledger/fabric/core/chaincode/recording"
 	"github.com/hyperledger/fabric/core/committer/txvalidator/plugin"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/privdata"
@@ -55,6 +58,8 @@ import (
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/container/dockercontroller"
 	"github.com/hyperledger/fabric/core/container/externalbuilder"
+	"github.com/hyperledger/fabric/core/container/processcontroller"
+	"github.com/hyperledger/fabric/core/container/wasmcontroller"
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/core/dispatcher"
 	"github.com/hyperledger/fabric/core/endorser"
@@ -69,6 +74,8 @@ import (
 	"github.com/hyperledger/fabric/core/operations"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
+	"github.com/hyperledger/fabric/core/pvtdataaccesslog"
+	"github.com/hyperledger/fabric/core/pvtdataencryption"
 	"github.com/hyperledger/fabric/core/scc"
 	"github.com/hyperledger/fabric/core/scc/lscc"
 	"github.com/hyperledger/fabric/core/scc/qscc"
@@ -95,6 +102,7 @@ import (
 	peergossip "github.com/hyperledger/fabric/internal/peer/gossip"
 	"github.com/hyperledger/fabric/internal/peer/version"
 	"github.com/hyperledger/fabric/internal/pkg/comm"
+	"github.com/hyperledger/fabric/internal/pkg/wasm"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protoutil"
@@ -184,9 +192,13 @@ type custodianLauncherAdapter struct {
 	launcher       chaincode.Launcher
 	streamHandler  extcc.StreamHandler
 	inProcLauncher inProcLauncher
+	supervisor     *chaincode.Supervisor
 }
 
 func (c custodianLauncherAdapter) Launch(ccid string) error {
+	if c.supervisor != nil {
+		return c.supervisor.Launch(ccid)
+	}
 	return c.launcher.Launch(ccid, c.streamHandler)
 }
 
@@ -297,13 +309,28 @@ func serve(args []string) error {
 		cs.SetClientCertificate(clientCert)
 	}
 
+	pvtdataEncryptor, err := pvtdataencryption.New(factory.GetDefault(), pvtdataencryption.GlobalConfig())
+	if err != nil {
+		return errors.WithMessage(err, "failed to initialize the encryption of private data")
+	}
+
 	transientStoreProvider, err := transientstoreext.NewStoreProvider(
 		filepath.Join(coreconfig.GetPath("peer.fileSystemPath"), "transientstore"),
+		pvtdataEncryptor,
 	)
 	if err != nil {
 		return errors.WithMessage(err, "failed to open transient store")
 	}
 
+	pvtdataAccessLog, err := pvtdataaccesslog.New(
+		filepath.Join(coreconfig.GetPath("peer.fileSystemPath"), "pvtdataAccessLog"),
+		pvtdataaccesslog.GlobalConfig(),
+	)
+	if err != nil {
+		return errors.WithMessage(err, "failed to open private data access log")
+	}
+	opsSystem.RegisterHandler("/pvtdata/accesslog", &pvtdataaccesslog.Handler{AccessLog: pvtdataAccessLog})
+
 	deliverServiceConfig := deliverservice.GlobalConfig()
 
 	peerInstance := &peer.Peer{
@@ -312,6 +339,16 @@ func serve(args []string) error {
 		StoreProvider:            transientStoreProvider,
 		CryptoProvider:           factory.GetDefault(),
 		OrdererEndpointOverrides: deliverServiceConfig.OrdererEndpointOverrides,
+		PvtDataAccessLog:         pvtdataAccessLog,
+	}
+	opsSystem.RegisterHandler("/pvtdata/removal", &peer.PvtdataRemovalHandler{Peer: peerInstance})
+
+	channelConfigChecker := &channelconfig.LintHealthChecker{
+		Channels: peerInstance.ChannelConfigs,
+		MSPID:    mspID,
+	}
+	if err := opsSystem.RegisterChecker("channelconfig", channelConfigChecker); err != nil {
+		logger.Panicf("failed to register channel config health check: %s", err)
 	}
 
 	localMSP := mgmt.GetLocalMSP(factory.GetDefault())
@@ -450,6 +487,7 @@ func serve(args []string) error {
 			HashProvider:                    factory.GetDefault(),
 			EbMetadataProvider:              ebMetadataProvider,
 			CollDataProvider:                collDataProvider,
+			PvtdataEncryptor:                pvtdataEncryptor,
 		},
 	)
 
@@ -477,6 +515,9 @@ func serve(args []string) error {
 	defer gossipService.Stop()
 
 	peerInstance.GossipService = gossipService
+	opsSystem.RegisterHandler("/pvtdata/status", &gossipservice.PvtDataStatusHandler{GossipService: gossipService})
+	opsSystem.RegisterHandler("/gossip/topology", &gossipservice.TopologyHandler{GossipService: gossipService})
+	opsSystem.RegisterHandler("/gossip/election/preferredleader", &gossipservice.PreferredLeaderHandler{GossipService: gossipService})
 
 	// NOTE: InitializeLocalChaincodes is called after the resource.Initialize below
 	// so that in-process user chaincodes are added to the cache.
@@ -536,11 +577,16 @@ func serve(args []string) error {
 		HandlerRegistry: chaincodeHandlerRegistry,
 	}
 
-	if coreConfig.VMEndpoint == "" && len(coreConfig.ExternalBuilders) == 0 {
-		logger.Panic("VMEndpoint not set and no ExternalBuilders defined")
+	if coreConfig.VMEndpoint == "" && len(coreConfig.ExternalBuilders) == 0 && !coreConfig.ChaincodeProcess.Enabled {
+		logger.Warning("VMEndpoint not set, no ExternalBuilders defined and chaincode processes disabled, only wasm chaincode can be run")
 	}
 
 	chaincodeConfig := chaincode.GlobalConfig()
+	chaincodeLoggingEnv := []string{
+		"CORE_CHAINCODE_LOGGING_LEVEL=" + chaincodeConfig.LogLevel,
+		"CORE_CHAINCODE_LOGGING_SHIM=" + chaincodeConfig.ShimLogLevel,
+		"CORE_CHAINCODE_LOGGING_FORMAT=" + chaincodeConfig.LogFormat,
+	}
 
 	var dockerBuilder container.DockerBuilder
 	if coreConfig.VMEndpoint != "" {
@@ -565,12 +611,8 @@ func serve(args []string) error {
 			// This field is superfluous for chaincodes built with v2.0+ binaries
 			// however, to prevent users from being forced to rebuild leaving for now
 			// but it should be removed in the future.
-			LoggingEnv: []string{
-				"CORE_CHAINCODE_LOGGING_LEVEL=" + chaincodeConfig.LogLevel,
-				"CORE_CHAINCODE_LOGGING_SHIM=" + chaincodeConfig.ShimLogLevel,
-				"CORE_CHAINCODE_LOGGING_FORMAT=" + chaincodeConfig.LogFormat,
-			},
-			MSPID: mspID,
+			LoggingEnv: chaincodeLoggingEnv,
+			MSPID:      mspID,
 		}
 		if err := opsSystem.RegisterChecker("docker", dockerVM); err != nil {
 			logger.Panicf("failed to register docker health check: %s", err)
@@ -601,6 +643,31 @@ func serve(args []string) error {
 		},
 	}
 
+	// wasm chaincode is always run inside the peer
+	containerRouter.WasmBuilder = &wasmcontroller.WasmVM{
+		Config: wasm.Config{
+			Gas:            coreConfig.ChaincodeWasm.Gas,
+			MaxMemoryPages: uint32((coreConfig.ChaincodeWasm.MaxMemory + wasm.PageSize - 1) / wasm.PageSize),
+			MaxCallDepth:   coreConfig.ChaincodeWasm.MaxCallDepth,
+		},
+	}
+
+	// Go chaincode is built and run as child processes of the peer when
+	// enabled, other chaincode falls back to docker
+	if coreConfig.ChaincodeProcess.Enabled {
+		containerRouter.ProcessBuilder = &processcontroller.ProcessVM{
+			Builder:    &golang.Platform{},
+			BuildDir:   filepath.Join(coreconfig.GetPath("peer.fileSystemPath"), "processcontroller", "builds"),
+			RunDir:     filepath.Join(coreconfig.GetPath("peer.fileSystemPath"), "processcontroller", "run"),
+			LoggingEnv: chaincodeLoggingEnv,
+			MSPID:      mspID,
+			Limits: processcontroller.Limits{
+				MaxOpenFiles: coreConfig.ChaincodeProcess.MaxOpenFiles,
+				MaxMemory:    coreConfig.ChaincodeProcess.MaxMemory,
+			},
+		}
+	}
+
 	builtinSCCs := map[string]struct{}{
 		"lscc":       {},
 		"qscc":       {},
@@ -643,6 +710,20 @@ func serve(args []string) error {
 		ChaincodeBuilder:          containerRouter,
 		BuildRegistry:             buildRegistry,
 	}
+	if chaincodeConfig.PackageSignaturePolicy != "" {
+		packageVerifier, err := lifecycle.NewPackageSignaturePolicy(
+			chaincodeConfig.PackageSignaturePolicy,
+			&lifecycle.PackageSignerDeserializer{
+				LocalDeserializer:    mgmt.GetLocalMSP(factory.GetDefault()),
+				ChannelDeserializers: mgmt.GetDeserializers,
+			},
+		)
+		if err != nil {
+			logger.Panicf("Failed to create the chaincode package signature policy: %s", err)
+		}
+		lifecycleFunctions.PackageVerifier = packageVerifier
+		logger.Infof("Chaincode packages must satisfy the package signature policy %s to be installed", chaincodeConfig.PackageSignaturePolicy)
+	}
 
 	lifecycleSCC := &lifecycle.SCC{
 		Dispatcher: &dispatcher.Dispatcher{
@@ -672,9 +753,28 @@ func serve(args []string) error {
 		chaincodeLauncher.CertGenerator = nil
 	}
 
+	var chaincodeSupervisor *chaincode.Supervisor
+	if chaincodeConfig.Supervisor.Enabled && !userRunsCC {
+		chaincodeSupervisor = &chaincode.Supervisor{
+			InitialBackoff: chaincodeConfig.Supervisor.InitialBackoff,
+			MaxBackoff:     chaincodeConfig.Supervisor.MaxBackoff,
+			MaxRestarts:    chaincodeConfig.Supervisor.MaxRestarts,
+			Metrics:        chaincode.NewSupervisorMetrics(opsSystem.Provider),
+		}
+		chaincodeLauncher.Observer = chaincodeSupervisor
+	}
+
+	var crossChannelResolver *chaincode.CrossChannelResolver
+	if chaincodeConfig.CrossChannel.Enabled {
+		crossChannelResolver = &chaincode.CrossChannelResolver{
+			LedgerGetter: peerInstance,
+		}
+	}
+
 	chaincodeSupport := &chaincode.ChaincodeSupport{
 		ACLProvider:            aclProvider,
 		AppConfig:              peerInstance,
+		CrossChannelResolver:   crossChannelResolver,
 		DeployedCCInfoProvider: lifecycleValidatorCommitter,
 		ExecuteTimeout:         chaincodeConfig.ExecuteTimeout,
 		InstallTimeout:         chaincodeConfig.InstallTimeout,
@@ -683,7 +783,10 @@ func serve(args []string) error {
 		Keepalive:              chaincodeConfig.Keepalive,
 		Launcher:               chaincodeLauncher,
 		Lifecycle:              chaincodeEndorsementInfo,
+		MaxBulkQueryBytes:      chaincodeConfig.MaxBulkQueryBytes,
 		Peer:                   peerInstance,
+		PvtDataAccessLog:       pvtdataAccessLog,
+		Quotas:                 chaincode.NewQuotaManager(chaincodeConfig.Quotas),
 		Runtime:                containerRuntime,
 		BuiltinSCCs:            builtinSCCs,
 		TotalQueryLimit:        chaincodeConfig.TotalQueryLimit,
@@ -695,6 +798,18 @@ func serve(args []string) error {
 		streamHandler:  chaincodeSupport,
 		inProcLauncher: chaincodeSupport,
 	}
+	if chaincodeSupervisor != nil {
+		// the supervisor restarts chaincode through a copy of the adapter
+		// which does not refer back to the supervisor
+		chaincodeSupervisor.Launcher = custodianLauncher
+		custodianLauncher.supervisor = chaincodeSupervisor
+		opsSystem.RegisterHandler("/chaincodes", chaincodeSupervisor)
+		if chaincodeConfig.Supervisor.HealthCheck {
+			if err := opsSystem.RegisterChecker("chaincode", chaincodeSupervisor); err != nil {
+				logger.Panicf("failed to register chaincode health check: %s", err)
+			}
+		}
+	}
 	go chaincodeCustodian.Work(buildRegistry, containerRouter, custodianLauncher)
 
 	ccSupSrv := pb.ChaincodeSupportServer(chaincodeSupport)
@@ -763,6 +878,10 @@ func serve(args []string) error {
 		Metrics:                endorser.NewMetrics(metricsProvider),
 		SkipCheckForDupTxnID:   skipCheckForDupTxnID,
 	}
+	if chaincodeConfig.Recording.Enabled {
+		logger.Warningf("Recording endorsements in %s, recordings contain the data read and written by chaincodes", chaincodeConfig.Recording.Path)
+		serverEndorser.Recorder = &recording.Recorder{Path: chaincodeConfig.Recording.Path}
+	}
 
 	// Initialize all of the registered resources
 	err = resource.Initialize(
@@ -849,6 +968,16 @@ func serve(args []string) error {
 	// this is expected to disappear with FAB-15061
 	legacyMetadataManager.AddListener(metadataManager)
 
+	if chaincodeSupervisor != nil && chaincodeConfig.Supervisor.Prelaunch {
+		// the legacy metadata manager notifies the chaincodes deployed on a
+		// channel when the channel is initialized and when they are deployed
+		legacyMetadataManager.AddListener(&chaincode.Prelauncher{
+			Launcher:     custodianLauncher,
+			Lifecycle:    chaincodeEndorsementInfo,
+			LedgerGetter: peerInstance,
+		})
+	}
+
 	// register gossip as a listener for updates from lifecycleMetadataManager
 	metadataManager.AddListener(lifecycle.HandleMetadataUpdateFunc(func(channel string, chaincodes ccdef.MetadataSet) {
 		gossipService.UpdateChaincodes(chaincodes.AsChaincodes(), gossipcommon.ChannelID(channel))
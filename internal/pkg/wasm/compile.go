@@ -0,0 +1,341 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasm
+
+// Opcodes of the instructions supported by the interpreter. Instructions
+// with the 0xfc prefix are identified by the prefix followed by the
+// instruction index.
+const (
+	opUnreachable  = 0x00
+	opNop          = 0x01
+	opBlock        = 0x02
+	opLoop         = 0x03
+	opIf           = 0x04
+	opElse         = 0x05
+	opEnd          = 0x0b
+	opBr           = 0x0c
+	opBrIf         = 0x0d
+	opBrTable      = 0x0e
+	opReturn       = 0x0f
+	opCall         = 0x10
+	opCallIndirect = 0x11
+
+	opDrop        = 0x1a
+	opSelect      = 0x1b
+	opSelectTyped = 0x1c
+
+	opLocalGet  = 0x20
+	opLocalSet  = 0x21
+	opLocalTee  = 0x22
+	opGlobalGet = 0x23
+	opGlobalSet = 0x24
+
+	opI32Load    = 0x28
+	opI64Load    = 0x29
+	opI32Load8S  = 0x2c
+	opI32Load8U  = 0x2d
+	opI32Load16S = 0x2e
+	opI32Load16U = 0x2f
+	opI64Load8S  = 0x30
+	opI64Load8U  = 0x31
+	opI64Load16S = 0x32
+	opI64Load16U = 0x33
+	opI64Load32S = 0x34
+	opI64Load32U = 0x35
+	opI32Store   = 0x36
+	opI64Store   = 0x37
+	opI32Store8  = 0x3a
+	opI32Store16 = 0x3b
+	opI64Store8  = 0x3c
+	opI64Store16 = 0x3d
+	opI64Store32 = 0x3e
+	opMemorySize = 0x3f
+	opMemoryGrow = 0x40
+
+	opI32Const = 0x41
+	opI64Const = 0x42
+
+	opI32Eqz = 0x45
+	opI32Eq  = 0x46
+	opI32Ne  = 0x47
+	opI32LtS = 0x48
+	opI32LtU = 0x49
+	opI32GtS = 0x4a
+	opI32GtU = 0x4b
+	opI32LeS = 0x4c
+	opI32LeU = 0x4d
+	opI32GeS = 0x4e
+	opI32GeU = 0x4f
+
+	opI64Eqz = 0x50
+	opI64Eq  = 0x51
+	opI64Ne  = 0x52
+	opI64LtS = 0x53
+	opI64LtU = 0x54
+	opI64GtS = 0x55
+	opI64GtU = 0x56
+	opI64LeS = 0x57
+	opI64LeU = 0x58
+	opI64GeS = 0x59
+	opI64GeU = 0x5a
+
+	opI32Clz    = 0x67
+	opI32Ctz    = 0x68
+	opI32Popcnt = 0x69
+	opI32Add    = 0x6a
+	opI32Sub    = 0x6b
+	opI32Mul    = 0x6c
+	opI32DivS   = 0x6d
+	opI32DivU   = 0x6e
+	opI32RemS   = 0x6f
+	opI32RemU   = 0x70
+	opI32And    = 0x71
+	opI32Or     = 0x72
+	opI32Xor    = 0x73
+	opI32Shl    = 0x74
+	opI32ShrS   = 0x75
+	opI32ShrU   = 0x76
+	opI32Rotl   = 0x77
+	opI32Rotr   = 0x78
+
+	opI64Clz    = 0x79
+	opI64Ctz    = 0x7a
+	opI64Popcnt = 0x7b
+	opI64Add    = 0x7c
+	opI64Sub    = 0x7d
+	opI64Mul    = 0x7e
+	opI64DivS   = 0x7f
+	opI64DivU   = 0x80
+	opI64RemS   = 0x81
+	opI64RemU   = 0x82
+	opI64And    = 0x83
+	opI64Or     = 0x84
+	opI64Xor    = 0x85
+	opI64Shl    = 0x86
+	opI64ShrS   = 0x87
+	opI64ShrU   = 0x88
+	opI64Rotl   = 0x89
+	opI64Rotr   = 0x8a
+
+	opI32WrapI64    = 0xa7
+	opI64ExtendI32S = 0xac
+	opI64ExtendI32U = 0xad
+
+	opI32Extend8S  = 0xc0
+	opI32Extend16S = 0xc1
+	opI64Extend8S  = 0xc2
+	opI64Extend16S = 0xc3
+	opI64Extend32S = 0xc4
+
+	opPrefix     = 0xfc
+	opMemoryCopy = 0xfc0a
+	opMemoryFill = 0xfc0b
+)
+
+// instr is a decoded instruction. The targets of structured control
+// instructions are resolved when the function is compiled.
+type instr struct {
+	op uint16
+	// imm is the immediate of the instruction: a constant, an index, a
+	// label depth or the offset of a memory access.
+	imm uint64
+	// end is the position of the end instruction of a block, loop or if
+	// and of the if to which an else belongs.
+	end int
+	// els is the position of the else instruction of an if, or -1.
+	els int
+	// params and results are the arity of a block, loop or if.
+	params  int
+	results int
+	// labels are the label depths of br_table, the last being the default.
+	labels []uint32
+}
+
+// isFloat returns true for the floating point instructions which are not
+// supported as their results may differ between platforms.
+func isFloat(op byte) bool {
+	switch {
+	case op == 0x2a, op == 0x2b, op == 0x38, op == 0x39, op == 0x43, op == 0x44:
+		return true // loads, stores and constants
+	case op >= 0x5b && op <= 0x66:
+		return true // comparisons
+	case op >= 0x8b && op <= 0xa6:
+		return true // arithmetic
+	case op >= 0xa8 && op <= 0xab, op >= 0xae && op <= 0xbf:
+		return true // conversions
+	}
+	return false
+}
+
+// compileBody decodes the instructions of a function body which has the
+// given number of locals, including its parameters.
+func compileBody(r *reader, m *Module, numLocals int) []instr {
+	var code []instr
+	var blocks []int
+
+	for {
+		op := r.byte()
+		in := instr{op: uint16(op), els: -1}
+
+		switch {
+		case op == opBlock, op == opLoop, op == opIf:
+			in.params, in.results = blockType(r, m)
+			blocks = append(blocks, len(code))
+
+		case op == opElse:
+			if len(blocks) == 0 {
+				fail("else outside of if")
+			}
+			opener := &code[blocks[len(blocks)-1]]
+			if opener.op != opIf || opener.els >= 0 {
+				fail("else outside of if")
+			}
+			opener.els = len(code)
+
+		case op == opEnd:
+			if len(blocks) == 0 {
+				code = append(code, in)
+				if !r.done() {
+					fail("unexpected instructions after end of function")
+				}
+				return code
+			}
+			opener := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			code[opener].end = len(code)
+			if els := code[opener].els; els >= 0 {
+				code[els].end = len(code)
+			}
+
+		case op == opBr, op == opBrIf:
+			in.imm = uint64(r.u32())
+			if in.imm > uint64(len(blocks)) {
+				fail("unknown label %d", in.imm)
+			}
+
+		case op == opBrTable:
+			in.labels = append(r.vecU32(), r.u32())
+			for _, l := range in.labels {
+				if l > uint32(len(blocks)) {
+					fail("unknown label %d", l)
+				}
+			}
+
+		case op == opCall:
+			in.imm = uint64(r.u32())
+			if in.imm >= uint64(m.numFuncs()) {
+				fail("call to unknown function %d", in.imm)
+			}
+
+		case op == opCallIndirect:
+			in.imm = uint64(r.u32())
+			if in.imm >= uint64(len(m.types)) {
+				fail("call_indirect with unknown type %d", in.imm)
+			}
+			if table := r.byte(); table != 0 || m.table == nil {
+				fail("call_indirect without table")
+			}
+
+		case op == opSelectTyped:
+			if types := r.valueTypes(); len(types) != 1 {
+				fail("select must have one result type")
+			}
+			in.op = opSelect
+
+		case op == opLocalGet, op == opLocalSet, op == opLocalTee:
+			in.imm = uint64(r.u32())
+			if in.imm >= uint64(numLocals) {
+				fail("unknown local %d", in.imm)
+			}
+
+		case op == opGlobalGet, op == opGlobalSet:
+			in.imm = uint64(r.u32())
+			if in.imm >= uint64(len(m.globals)) {
+				fail("unknown global %d", in.imm)
+			}
+			if op == opGlobalSet && !m.globals[in.imm].mutable {
+				fail("global %d is immutable", in.imm)
+			}
+
+		case isFloat(op):
+			fail("floating point instruction 0x%x is not supported", op)
+
+		case op >= opI32Load && op <= opI64Store32:
+			if m.memory == nil {
+				fail("memory instruction without memory")
+			}
+			r.u32() // alignment hint
+			in.imm = uint64(r.u32())
+
+		case op == opMemorySize, op == opMemoryGrow:
+			if reserved := r.byte(); reserved != 0 || m.memory == nil {
+				fail("memory instruction without memory")
+			}
+
+		case op == opI32Const:
+			in.imm = uint64(uint32(r.s32()))
+
+		case op == opI64Const:
+			in.imm = uint64(r.s64())
+
+		case op == opPrefix:
+			switch sub := r.u32(); sub {
+			case 10:
+				in.op = opMemoryCopy
+				if r.byte() != 0 || r.byte() != 0 || m.memory == nil {
+					fail("memory instruction without memory")
+				}
+			case 11:
+				in.op = opMemoryFill
+				if r.byte() != 0 || m.memory == nil {
+					fail("memory instruction without memory")
+				}
+			default:
+				if sub <= 7 {
+					fail("floating point instruction 0xfc %d is not supported", sub)
+				}
+				fail("unsupported instruction 0xfc %d", sub)
+			}
+
+		case op == opUnreachable, op == opNop, op == opReturn, op == opDrop, op == opSelect,
+			op >= opI32Eqz && op <= opI64GeU,
+			op >= opI32Clz && op <= opI64Rotr,
+			op == opI32WrapI64, op == opI64ExtendI32S, op == opI64ExtendI32U,
+			op >= opI32Extend8S && op <= opI64Extend32S:
+			// no immediates
+
+		default:
+			fail("unsupported instruction 0x%x", op)
+		}
+
+		code = append(code, in)
+	}
+}
+
+// blockType returns the number of parameters and results of a block.
+func blockType(r *reader, m *Module) (params, results int) {
+	if r.done() {
+		fail("unexpected end of input")
+	}
+	switch t := ValueType(r.buf[r.pos]); t {
+	case 0x40:
+		r.pos++
+		return 0, 0
+	case I32, I64:
+		r.pos++
+		return 0, 1
+	case f32, f64:
+		fail("floating point type %s is not supported", t)
+	}
+
+	index := r.signed(33)
+	if index < 0 || index >= int64(len(m.types)) {
+		fail("unknown block type %d", index)
+	}
+	t := m.types[index]
+	return len(t.Params), len(t.Results)
+}
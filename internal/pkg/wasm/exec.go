@@ -0,0 +1,523 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// label is the target of a branch.
+type label struct {
+	// cont is the position execution continues at when branching to the
+	// label.
+	cont int
+	// height is the height of the stack when the block was entered, less
+	// its parameters.
+	height int
+	// arity is the number of values passed when branching to the label.
+	arity int
+	// results is the number of values left when the block ends.
+	results int
+	loop    bool
+}
+
+func (i *Instance) push(v uint64) {
+	i.stack = append(i.stack, v)
+}
+
+func (i *Instance) pop() uint64 {
+	v := i.stack[len(i.stack)-1]
+	i.stack = i.stack[:len(i.stack)-1]
+	return v
+}
+
+func (i *Instance) push32(v uint32) {
+	i.push(uint64(v))
+}
+
+func (i *Instance) pop32() uint32 {
+	return uint32(i.pop())
+}
+
+func (i *Instance) pushBool(b bool) {
+	if b {
+		i.push(1)
+	} else {
+		i.push(0)
+	}
+}
+
+// unwind removes the values above height from the stack, keeping the top
+// arity values.
+func (i *Instance) unwind(height, arity int) {
+	n := len(i.stack)
+	if n-arity < height {
+		i.trap("stack underflow")
+	}
+	copy(i.stack[height:], i.stack[n-arity:])
+	i.stack = i.stack[:height+arity]
+}
+
+// branch branches to the label at depth, returning the remaining labels and
+// the position to continue at.
+func (i *Instance) branch(labels []label, depth int) ([]label, int) {
+	l := labels[len(labels)-1-depth]
+	i.unwind(l.height, l.arity)
+	if l.loop {
+		return labels[:len(labels)-depth], l.cont
+	}
+	return labels[:len(labels)-1-depth], l.cont
+}
+
+// invoke calls the function at index with its arguments on the stack.
+func (i *Instance) invoke(index uint32) {
+	if index < uint32(len(i.host)) {
+		i.invokeHost(index)
+		return
+	}
+
+	fn := &i.module.funcs[index-uint32(len(i.host))]
+	t := i.module.types[fn.typ]
+	numLocals := len(t.Params) + fn.locals
+
+	if i.depth >= i.config.MaxCallDepth {
+		i.trap("call stack exhausted")
+	}
+	if len(i.stack)+i.locals+numLocals > maxStack {
+		i.trap("stack exhausted")
+	}
+
+	locals := make([]uint64, numLocals)
+	n := len(i.stack) - len(t.Params)
+	copy(locals, i.stack[n:])
+	i.stack = i.stack[:n]
+
+	i.depth++
+	i.locals += numLocals
+	i.execute(fn.code, locals, len(t.Results))
+	i.locals -= numLocals
+	i.depth--
+}
+
+func (i *Instance) invokeHost(index uint32) {
+	fn := i.host[index]
+	n := len(i.stack) - len(fn.Type.Params)
+	args := append([]uint64(nil), i.stack[n:]...)
+	i.stack = i.stack[:n]
+
+	results, err := fn.Func(i, args)
+	if err != nil {
+		panic(trap{err})
+	}
+	if len(results) != len(fn.Type.Results) {
+		i.trap("host function returned %d results, expected %d", len(results), len(fn.Type.Results))
+	}
+	i.stack = append(i.stack, results...)
+}
+
+func (i *Instance) execute(code []instr, locals []uint64, results int) {
+	labels := []label{{cont: len(code), height: len(i.stack), arity: results, results: results}}
+
+	for pc := 0; pc < len(code); {
+		in := &code[pc]
+
+		if i.gas == 0 {
+			panic(trap{ErrOutOfGas})
+		}
+		i.gas--
+
+		switch in.op {
+		case opUnreachable:
+			i.trap("unreachable executed")
+		case opNop:
+		case opBlock:
+			labels = append(labels, label{cont: in.end + 1, height: len(i.stack) - in.params, arity: in.results, results: in.results})
+		case opLoop:
+			labels = append(labels, label{cont: pc + 1, height: len(i.stack) - in.params, arity: in.params, results: in.results, loop: true})
+		case opIf:
+			l := label{cont: in.end + 1, arity: in.results, results: in.results}
+			cond := i.pop32()
+			l.height = len(i.stack) - in.params
+			switch {
+			case cond != 0:
+				labels = append(labels, l)
+			case in.els >= 0:
+				labels = append(labels, l)
+				pc = in.els + 1
+				continue
+			default:
+				pc = in.end + 1
+				continue
+			}
+		case opElse:
+			// the end of the if branch
+			pc = in.end
+			continue
+		case opEnd:
+			l := labels[len(labels)-1]
+			labels = labels[:len(labels)-1]
+			i.unwind(l.height, l.results)
+		case opBr:
+			labels, pc = i.branch(labels, int(in.imm))
+			continue
+		case opBrIf:
+			if i.pop32() != 0 {
+				labels, pc = i.branch(labels, int(in.imm))
+				continue
+			}
+		case opBrTable:
+			index := i.pop32()
+			depth := in.labels[len(in.labels)-1]
+			if index < uint32(len(in.labels)-1) {
+				depth = in.labels[index]
+			}
+			labels, pc = i.branch(labels, int(depth))
+			continue
+		case opReturn:
+			i.unwind(labels[0].height, results)
+			return
+		case opCall:
+			i.invoke(uint32(in.imm))
+		case opCallIndirect:
+			elem := i.pop32()
+			if elem >= uint32(len(i.table)) || i.table[elem] < 0 {
+				i.trap("undefined element %d", elem)
+			}
+			f := uint32(i.table[elem])
+			if !i.module.types[i.module.funcType(f)].Equal(i.module.types[in.imm]) {
+				i.trap("indirect call type mismatch")
+			}
+			i.invoke(f)
+
+		case opDrop:
+			i.pop()
+		case opSelect:
+			cond := i.pop32()
+			b, a := i.pop(), i.pop()
+			if cond != 0 {
+				i.push(a)
+			} else {
+				i.push(b)
+			}
+
+		case opLocalGet:
+			i.push(locals[in.imm])
+		case opLocalSet:
+			locals[in.imm] = i.pop()
+		case opLocalTee:
+			locals[in.imm] = i.stack[len(i.stack)-1]
+		case opGlobalGet:
+			i.push(i.globals[in.imm])
+		case opGlobalSet:
+			i.globals[in.imm] = i.pop()
+
+		case opI32Const, opI64Const:
+			i.push(in.imm)
+
+		case opMemorySize:
+			i.push32(uint32(len(i.memory) / PageSize))
+		case opMemoryGrow:
+			i.grow(i.pop32())
+		case opMemoryCopy:
+			n, src, dst := i.pop32(), i.pop32(), i.pop32()
+			i.checkBounds(src, uint64(n))
+			i.checkBounds(dst, uint64(n))
+			i.useGas(uint64(n) / 64)
+			copy(i.memory[dst:uint64(dst)+uint64(n)], i.memory[src:uint64(src)+uint64(n)])
+		case opMemoryFill:
+			n, val, dst := i.pop32(), byte(i.pop32()), i.pop32()
+			i.checkBounds(dst, uint64(n))
+			i.useGas(uint64(n) / 64)
+			mem := i.memory[dst : uint64(dst)+uint64(n)]
+			for j := range mem {
+				mem[j] = val
+			}
+
+		default:
+			if in.op >= opI32Load && in.op <= opI64Store32 {
+				i.memoryAccess(in)
+			} else {
+				i.numeric(in.op)
+			}
+		}
+
+		pc++
+	}
+}
+
+func (i *Instance) useGas(amount uint64) {
+	if i.UseGas(amount) != nil {
+		panic(trap{ErrOutOfGas})
+	}
+}
+
+func (i *Instance) grow(pages uint32) {
+	old := uint32(len(i.memory) / PageSize)
+	if uint64(old)+uint64(pages) > uint64(i.maxPages) {
+		i.push32(math.MaxUint32)
+		return
+	}
+	i.memory = append(i.memory, make([]byte, int(pages)*PageSize)...)
+	i.push32(old)
+}
+
+func (i *Instance) checkBounds(addr uint32, size uint64) {
+	if uint64(addr)+size > uint64(len(i.memory)) {
+		i.trap("out of bounds memory access")
+	}
+}
+
+// address pops the base address of a memory access and returns the
+// effective address.
+func (i *Instance) address(in *instr, size uint64) uint64 {
+	addr := uint64(i.pop32()) + in.imm
+	if addr+size > uint64(len(i.memory)) {
+		i.trap("out of bounds memory access")
+	}
+	return addr
+}
+
+func (i *Instance) memoryAccess(in *instr) {
+	le := binary.LittleEndian
+
+	switch in.op {
+	case opI32Load:
+		i.push32(le.Uint32(i.memory[i.address(in, 4):]))
+	case opI64Load:
+		i.push(le.Uint64(i.memory[i.address(in, 8):]))
+	case opI32Load8S:
+		i.push32(uint32(int32(int8(i.memory[i.address(in, 1)]))))
+	case opI32Load8U:
+		i.push32(uint32(i.memory[i.address(in, 1)]))
+	case opI32Load16S:
+		i.push32(uint32(int32(int16(le.Uint16(i.memory[i.address(in, 2):])))))
+	case opI32Load16U:
+		i.push32(uint32(le.Uint16(i.memory[i.address(in, 2):])))
+	case opI64Load8S:
+		i.push(uint64(int64(int8(i.memory[i.address(in, 1)]))))
+	case opI64Load8U:
+		i.push(uint64(i.memory[i.address(in, 1)]))
+	case opI64Load16S:
+		i.push(uint64(int64(int16(le.Uint16(i.memory[i.address(in, 2):])))))
+	case opI64Load16U:
+		i.push(uint64(le.Uint16(i.memory[i.address(in, 2):])))
+	case opI64Load32S:
+		i.push(uint64(int64(int32(le.Uint32(i.memory[i.address(in, 4):])))))
+	case opI64Load32U:
+		i.push(uint64(le.Uint32(i.memory[i.address(in, 4):])))
+
+	case opI32Store, opI64Store32:
+		v := uint32(i.pop())
+		le.PutUint32(i.memory[i.address(in, 4):], v)
+	case opI64Store:
+		v := i.pop()
+		le.PutUint64(i.memory[i.address(in, 8):], v)
+	case opI32Store8, opI64Store8:
+		v := byte(i.pop())
+		i.memory[i.address(in, 1)] = v
+	case opI32Store16, opI64Store16:
+		v := uint16(i.pop())
+		le.PutUint16(i.memory[i.address(in, 2):], v)
+
+	default:
+		i.trap("unsupported instruction 0x%x", in.op)
+	}
+}
+
+func (i *Instance) numeric(op uint16) {
+	switch op {
+	case opI32Eqz:
+		i.pushBool(i.pop32() == 0)
+	case opI64Eqz:
+		i.pushBool(i.pop() == 0)
+
+	case opI32Clz:
+		i.push32(uint32(bits.LeadingZeros32(i.pop32())))
+	case opI32Ctz:
+		i.push32(uint32(bits.TrailingZeros32(i.pop32())))
+	case opI32Popcnt:
+		i.push32(uint32(bits.OnesCount32(i.pop32())))
+	case opI64Clz:
+		i.push(uint64(bits.LeadingZeros64(i.pop())))
+	case opI64Ctz:
+		i.push(uint64(bits.TrailingZeros64(i.pop())))
+	case opI64Popcnt:
+		i.push(uint64(bits.OnesCount64(i.pop())))
+
+	case opI32WrapI64:
+		i.push32(uint32(i.pop()))
+	case opI64ExtendI32S:
+		i.push(uint64(int64(int32(i.pop32()))))
+	case opI64ExtendI32U:
+		i.push(uint64(i.pop32()))
+	case opI32Extend8S:
+		i.push32(uint32(int32(int8(i.pop32()))))
+	case opI32Extend16S:
+		i.push32(uint32(int32(int16(i.pop32()))))
+	case opI64Extend8S:
+		i.push(uint64(int64(int8(i.pop()))))
+	case opI64Extend16S:
+		i.push(uint64(int64(int16(i.pop()))))
+	case opI64Extend32S:
+		i.push(uint64(int64(int32(i.pop()))))
+
+	default:
+		if op >= opI32Eq && op <= opI32GeU || op >= opI32Add && op <= opI32Rotr {
+			b, a := i.pop32(), i.pop32()
+			i.binary32(op, a, b)
+		} else if op >= opI64Eq && op <= opI64GeU || op >= opI64Add && op <= opI64Rotr {
+			b, a := i.pop(), i.pop()
+			i.binary64(op, a, b)
+		} else {
+			i.trap("unsupported instruction 0x%x", op)
+		}
+	}
+}
+
+func (i *Instance) binary32(op uint16, a, b uint32) {
+	switch op {
+	case opI32Eq:
+		i.pushBool(a == b)
+	case opI32Ne:
+		i.pushBool(a != b)
+	case opI32LtS:
+		i.pushBool(int32(a) < int32(b))
+	case opI32LtU:
+		i.pushBool(a < b)
+	case opI32GtS:
+		i.pushBool(int32(a) > int32(b))
+	case opI32GtU:
+		i.pushBool(a > b)
+	case opI32LeS:
+		i.pushBool(int32(a) <= int32(b))
+	case opI32LeU:
+		i.pushBool(a <= b)
+	case opI32GeS:
+		i.pushBool(int32(a) >= int32(b))
+	case opI32GeU:
+		i.pushBool(a >= b)
+
+	case opI32Add:
+		i.push32(a + b)
+	case opI32Sub:
+		i.push32(a - b)
+	case opI32Mul:
+		i.push32(a * b)
+	case opI32DivS:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			i.trap("integer overflow")
+		}
+		i.push32(uint32(int32(a) / int32(b)))
+	case opI32DivU:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		i.push32(a / b)
+	case opI32RemS:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		i.push32(uint32(int32(a) % int32(b)))
+	case opI32RemU:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		i.push32(a % b)
+	case opI32And:
+		i.push32(a & b)
+	case opI32Or:
+		i.push32(a | b)
+	case opI32Xor:
+		i.push32(a ^ b)
+	case opI32Shl:
+		i.push32(a << (b & 31))
+	case opI32ShrS:
+		i.push32(uint32(int32(a) >> (b & 31)))
+	case opI32ShrU:
+		i.push32(a >> (b & 31))
+	case opI32Rotl:
+		i.push32(bits.RotateLeft32(a, int(b&31)))
+	case opI32Rotr:
+		i.push32(bits.RotateLeft32(a, -int(b&31)))
+	}
+}
+
+func (i *Instance) binary64(op uint16, a, b uint64) {
+	switch op {
+	case opI64Eq:
+		i.pushBool(a == b)
+	case opI64Ne:
+		i.pushBool(a != b)
+	case opI64LtS:
+		i.pushBool(int64(a) < int64(b))
+	case opI64LtU:
+		i.pushBool(a < b)
+	case opI64GtS:
+		i.pushBool(int64(a) > int64(b))
+	case opI64GtU:
+		i.pushBool(a > b)
+	case opI64LeS:
+		i.pushBool(int64(a) <= int64(b))
+	case opI64LeU:
+		i.pushBool(a <= b)
+	case opI64GeS:
+		i.pushBool(int64(a) >= int64(b))
+	case opI64GeU:
+		i.pushBool(a >= b)
+
+	case opI64Add:
+		i.push(a + b)
+	case opI64Sub:
+		i.push(a - b)
+	case opI64Mul:
+		i.push(a * b)
+	case opI64DivS:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			i.trap("integer overflow")
+		}
+		i.push(uint64(int64(a) / int64(b)))
+	case opI64DivU:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		i.push(a / b)
+	case opI64RemS:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		i.push(uint64(int64(a) % int64(b)))
+	case opI64RemU:
+		if b == 0 {
+			i.trap("integer divide by zero")
+		}
+		i.push(a % b)
+	case opI64And:
+		i.push(a & b)
+	case opI64Or:
+		i.push(a | b)
+	case opI64Xor:
+		i.push(a ^ b)
+	case opI64Shl:
+		i.push(a << (b & 63))
+	case opI64ShrS:
+		i.push(uint64(int64(a) >> (b & 63)))
+	case opI64ShrU:
+		i.push(a >> (b & 63))
+	case opI64Rotl:
+		i.push(bits.RotateLeft64(a, int(b&63)))
+	case opI64Rotr:
+		i.push(bits.RotateLeft64(a, -int(b&63)))
+	}
+}
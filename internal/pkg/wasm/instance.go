@@ -0,0 +1,244 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasm
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// PageSize is the size of a page of memory.
+	PageSize = 65536
+	// maxPages is the number of pages addressable with 32 bit addresses.
+	maxPages = 65536
+	// maxStack bounds the number of values and locals on the stack.
+	maxStack = 1 << 20
+)
+
+// Defaults used for the zero values of Config.
+const (
+	DefaultGas            = 100000000
+	DefaultMaxMemoryPages = 256
+	DefaultMaxCallDepth   = 1000
+)
+
+// ErrOutOfGas is returned when execution runs out of gas.
+var ErrOutOfGas = errors.New("out of gas")
+
+// Config bounds the execution of an instance.
+type Config struct {
+	// Gas is the number of instructions the instance may execute over its
+	// lifetime, including the start function. Host functions may consume
+	// additional gas.
+	Gas uint64
+	// MaxMemoryPages is the maximum size of the memory of the instance.
+	MaxMemoryPages uint32
+	// MaxCallDepth is the maximum depth of nested function calls.
+	MaxCallDepth int
+}
+
+// HostFunction is a function provided by the host which may be imported by
+// a module. An error returned by the function aborts the execution of the
+// instance.
+type HostFunction struct {
+	Type FuncType
+	Func func(inst *Instance, args []uint64) ([]uint64, error)
+}
+
+// Imports are the host functions which may be imported, by module and name.
+type Imports map[string]map[string]HostFunction
+
+// Instance is an instantiated module. An instance must not be used
+// concurrently.
+type Instance struct {
+	module   *Module
+	config   Config
+	host     []HostFunction
+	memory   []byte
+	maxPages uint32
+	globals  []uint64
+	table    []int64
+	stack    []uint64
+	locals   int
+	depth    int
+	gas      uint64
+}
+
+// trap aborts execution of an instance.
+type trap struct {
+	err error
+}
+
+func (i *Instance) trap(format string, args ...interface{}) {
+	panic(trap{errors.Errorf(format, args...)})
+}
+
+// Instantiate creates an instance of the module. The imports of the module
+// are resolved from the host functions given. The start function of the
+// module, if any, is executed.
+func Instantiate(m *Module, imports Imports, config Config) (*Instance, error) {
+	if config.Gas == 0 {
+		config.Gas = DefaultGas
+	}
+	if config.MaxMemoryPages == 0 {
+		config.MaxMemoryPages = DefaultMaxMemoryPages
+	}
+	if config.MaxCallDepth == 0 {
+		config.MaxCallDepth = DefaultMaxCallDepth
+	}
+
+	inst := &Instance{
+		module: m,
+		config: config,
+		gas:    config.Gas,
+	}
+
+	for _, imp := range m.imports {
+		fn, ok := imports[imp.module][imp.name]
+		if !ok {
+			return nil, errors.Errorf("unknown import %s.%s", imp.module, imp.name)
+		}
+		if !fn.Type.Equal(m.types[imp.typ]) {
+			return nil, errors.Errorf("import %s.%s has wrong signature", imp.module, imp.name)
+		}
+		inst.host = append(inst.host, fn)
+	}
+
+	if m.memory != nil {
+		if m.memory.min > config.MaxMemoryPages {
+			return nil, errors.Errorf("module requires %d pages of memory, limit is %d", m.memory.min, config.MaxMemoryPages)
+		}
+		inst.memory = make([]byte, int(m.memory.min)*PageSize)
+		inst.maxPages = config.MaxMemoryPages
+		if m.memory.hasMax && m.memory.max < inst.maxPages {
+			inst.maxPages = m.memory.max
+		}
+	}
+
+	for _, g := range m.globals {
+		inst.globals = append(inst.globals, inst.eval(g.init))
+	}
+
+	if m.table != nil {
+		inst.table = make([]int64, m.table.min)
+		for j := range inst.table {
+			inst.table[j] = -1
+		}
+	}
+	for _, elem := range m.elements {
+		offset := inst.eval(elem.offset)
+		if offset+uint64(len(elem.funcs)) > uint64(len(inst.table)) {
+			return nil, errors.New("element segment does not fit into table")
+		}
+		for j, f := range elem.funcs {
+			inst.table[offset+uint64(j)] = int64(f)
+		}
+	}
+
+	for _, seg := range m.data {
+		offset := inst.eval(seg.offset)
+		if offset+uint64(len(seg.data)) > uint64(len(inst.memory)) {
+			return nil, errors.New("data segment does not fit into memory")
+		}
+		copy(inst.memory[offset:], seg.data)
+	}
+
+	if m.start != nil {
+		if err := inst.run(*m.start, nil); err != nil {
+			return nil, errors.WithMessage(err, "start function failed")
+		}
+	}
+
+	return inst, nil
+}
+
+func (i *Instance) eval(expr constExpr) uint64 {
+	if expr.op == opGlobalGet {
+		return i.globals[expr.value]
+	}
+	return expr.value
+}
+
+// Call calls an exported function with the arguments given and returns its
+// results. Arguments and results of type i32 are passed in the lower 32
+// bits.
+func (i *Instance) Call(name string, args ...uint64) ([]uint64, error) {
+	exp, ok := i.module.exports[name]
+	if !ok || exp.kind != externalFunc {
+		return nil, errors.Errorf("function %s is not exported", name)
+	}
+	t := i.module.types[i.module.funcType(exp.index)]
+	if len(args) != len(t.Params) {
+		return nil, errors.Errorf("function %s expects %d arguments, got %d", name, len(t.Params), len(args))
+	}
+
+	if err := i.run(exp.index, args); err != nil {
+		return nil, err
+	}
+	return append([]uint64(nil), i.stack[len(i.stack)-len(t.Results):]...), nil
+}
+
+// run executes a function. Traps and errors of host functions are returned.
+func (i *Instance) run(index uint32, args []uint64) (err error) {
+	i.stack = append(i.stack[:0], args...)
+	i.locals = 0
+	i.depth = 0
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch t := r.(type) {
+			case trap:
+				err = t.err
+			case runtime.Error:
+				// the module was not validated completely, e.g. the stack
+				// of a malformed function underflowed
+				err = errors.Errorf("invalid execution: %s", t)
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	i.invoke(index)
+	return nil
+}
+
+// UseGas consumes gas on behalf of a host function. ErrOutOfGas is returned
+// when not enough gas is left, in which case the remaining gas is consumed.
+func (i *Instance) UseGas(amount uint64) error {
+	if amount > i.gas {
+		i.gas = 0
+		return ErrOutOfGas
+	}
+	i.gas -= amount
+	return nil
+}
+
+// GasUsed returns the gas consumed by the instance.
+func (i *Instance) GasUsed() uint64 {
+	return i.config.Gas - i.gas
+}
+
+// Read returns a copy of a range of the memory of the instance.
+func (i *Instance) Read(ptr, length uint32) ([]byte, error) {
+	end := uint64(ptr) + uint64(length)
+	if end > uint64(len(i.memory)) {
+		return nil, errors.Errorf("out of bounds memory access at %d, length %d", ptr, length)
+	}
+	return append([]byte(nil), i.memory[ptr:end]...), nil
+}
+
+// Write copies data into the memory of the instance.
+func (i *Instance) Write(ptr uint32, data []byte) error {
+	if uint64(ptr)+uint64(len(data)) > uint64(len(i.memory)) {
+		return errors.Errorf("out of bounds memory access at %d, length %d", ptr, len(data))
+	}
+	copy(i.memory[ptr:], data)
+	return nil
+}
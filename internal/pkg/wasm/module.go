@@ -0,0 +1,620 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package wasm is an interpreter for WebAssembly modules which executes
+// deterministically. Only the integer subset of WebAssembly is supported:
+// modules using floating point types or instructions are rejected when they
+// are compiled. Execution is bounded by the gas given to an instance, where
+// each instruction costs one unit of gas, and by limits on the memory and
+// call depth of the instance. The only imports a module may have are host
+// functions.
+package wasm
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// ValueType is the type of a WebAssembly value.
+type ValueType byte
+
+// The value types supported by the interpreter.
+const (
+	I32 ValueType = 0x7f
+	I64 ValueType = 0x7e
+)
+
+const (
+	f32 ValueType = 0x7d
+	f64 ValueType = 0x7c
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case f32:
+		return "f32"
+	case f64:
+		return "f64"
+	default:
+		return "unknown"
+	}
+}
+
+// FuncType is the signature of a function.
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+// Equal returns whether the types have the same parameters and results.
+func (t FuncType) Equal(o FuncType) bool {
+	if len(t.Params) != len(o.Params) || len(t.Results) != len(o.Results) {
+		return false
+	}
+	for i := range t.Params {
+		if t.Params[i] != o.Params[i] {
+			return false
+		}
+	}
+	for i := range t.Results {
+		if t.Results[i] != o.Results[i] {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	magic   = "\x00asm"
+	version = 1
+
+	// maxLocals bounds the locals of a function so that a module cannot
+	// make the interpreter allocate unbounded memory.
+	maxLocals = 50000
+	// maxTableSize bounds the size of the function table.
+	maxTableSize = 100000
+)
+
+const (
+	externalFunc byte = iota
+	externalTable
+	externalMemory
+	externalGlobal
+)
+
+type funcImport struct {
+	module string
+	name   string
+	typ    uint32
+}
+
+type function struct {
+	typ    uint32
+	locals int
+	code   []instr
+}
+
+type limits struct {
+	min    uint32
+	max    uint32
+	hasMax bool
+}
+
+type constExpr struct {
+	op    byte
+	value uint64
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    constExpr
+}
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+type element struct {
+	offset constExpr
+	funcs  []uint32
+}
+
+type dataSegment struct {
+	offset constExpr
+	data   []byte
+}
+
+// Module is a compiled WebAssembly module which may be instantiated any
+// number of times.
+type Module struct {
+	types    []FuncType
+	imports  []funcImport
+	funcs    []function
+	table    *limits
+	memory   *limits
+	globals  []global
+	exports  map[string]export
+	start    *uint32
+	elements []element
+	data     []dataSegment
+}
+
+// Import is a function imported by a module.
+type Import struct {
+	Module string
+	Name   string
+	Type   FuncType
+}
+
+// Imports returns the functions imported by the module.
+func (m *Module) Imports() []Import {
+	var imports []Import
+	for _, imp := range m.imports {
+		imports = append(imports, Import{Module: imp.module, Name: imp.name, Type: m.types[imp.typ]})
+	}
+	return imports
+}
+
+// ExportedFunc returns the signature of an exported function.
+func (m *Module) ExportedFunc(name string) (FuncType, bool) {
+	exp, ok := m.exports[name]
+	if !ok || exp.kind != externalFunc {
+		return FuncType{}, false
+	}
+	return m.types[m.funcType(exp.index)], true
+}
+
+func (m *Module) funcType(index uint32) uint32 {
+	if index < uint32(len(m.imports)) {
+		return m.imports[index].typ
+	}
+	return m.funcs[index-uint32(len(m.imports))].typ
+}
+
+func (m *Module) numFuncs() uint32 {
+	return uint32(len(m.imports) + len(m.funcs))
+}
+
+// decodeError is used to abort decoding of a malformed module.
+type decodeError struct {
+	err error
+}
+
+func fail(format string, args ...interface{}) {
+	panic(decodeError{errors.Errorf(format, args...)})
+}
+
+// Compile decodes and validates a module in the WebAssembly binary format.
+func Compile(code []byte) (m *Module, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			de, ok := r.(decodeError)
+			if !ok {
+				panic(r)
+			}
+			m, err = nil, errors.WithMessage(de.err, "invalid wasm module")
+		}
+	}()
+
+	r := &reader{buf: code}
+	if len(code) < 8 || string(code[:4]) != magic {
+		fail("missing magic number")
+	}
+	if v := binary.LittleEndian.Uint32(code[4:8]); v != version {
+		fail("unsupported version %d", v)
+	}
+	r.pos = 8
+
+	m = &Module{exports: map[string]export{}}
+	var codeDecoded bool
+	lastID := byte(0)
+	for !r.done() {
+		id := r.byte()
+		size := r.u32()
+		section := &reader{buf: r.bytes(size)}
+
+		if id != 0 {
+			// the data count section precedes the code section
+			if id != 12 && id <= lastID && !(lastID == 12 && id >= 10) {
+				fail("section %d out of order", id)
+			}
+			lastID = id
+		}
+
+		switch id {
+		case 0:
+			// custom sections are ignored
+			continue
+		case 1:
+			m.types = decodeTypes(section)
+		case 2:
+			m.imports = decodeImports(section, m)
+		case 3:
+			for _, typ := range section.vecU32() {
+				if typ >= uint32(len(m.types)) {
+					fail("unknown type %d", typ)
+				}
+				m.funcs = append(m.funcs, function{typ: typ})
+			}
+		case 4:
+			m.table = decodeTable(section)
+		case 5:
+			m.memory = decodeMemory(section)
+		case 6:
+			m.globals = decodeGlobals(section, m)
+		case 7:
+			decodeExports(section, m)
+		case 8:
+			start := section.u32()
+			m.start = &start
+		case 9:
+			m.elements = decodeElements(section, m)
+		case 10:
+			decodeCode(section, m)
+			codeDecoded = true
+		case 11:
+			m.data = decodeData(section, m)
+		case 12:
+			// the data count is not needed as passive data segments are
+			// not supported
+			section.u32()
+		default:
+			fail("unknown section %d", id)
+		}
+
+		if !section.done() {
+			fail("section %d has %d unexpected bytes", id, len(section.buf)-section.pos)
+		}
+	}
+
+	if len(m.funcs) > 0 && !codeDecoded {
+		fail("%d functions declared but none defined", len(m.funcs))
+	}
+	if m.start != nil {
+		if *m.start >= m.numFuncs() {
+			fail("unknown start function %d", *m.start)
+		}
+		if t := m.types[m.funcType(*m.start)]; len(t.Params) != 0 || len(t.Results) != 0 {
+			fail("start function must not have parameters or results")
+		}
+	}
+
+	return m, nil
+}
+
+func decodeTypes(r *reader) []FuncType {
+	var types []FuncType
+	for n := r.count(); n > 0; n-- {
+		if form := r.byte(); form != 0x60 {
+			fail("unexpected function type form 0x%x", form)
+		}
+		types = append(types, FuncType{
+			Params:  r.valueTypes(),
+			Results: r.valueTypes(),
+		})
+	}
+	return types
+}
+
+func decodeImports(r *reader, m *Module) []funcImport {
+	var imports []funcImport
+	for n := r.count(); n > 0; n-- {
+		module, name := r.name(), r.name()
+		if kind := r.byte(); kind != externalFunc {
+			fail("import %s.%s is not a function, only functions may be imported", module, name)
+		}
+		typ := r.u32()
+		if typ >= uint32(len(m.types)) {
+			fail("unknown type %d", typ)
+		}
+		imports = append(imports, funcImport{module: module, name: name, typ: typ})
+	}
+	return imports
+}
+
+func decodeTable(r *reader) *limits {
+	if r.count() != 1 {
+		fail("only one table is supported")
+	}
+	if elemType := r.byte(); elemType != 0x70 {
+		fail("unsupported table element type 0x%x", elemType)
+	}
+	l := r.limits()
+	if l.min > maxTableSize {
+		fail("table size %d exceeds the maximum of %d", l.min, maxTableSize)
+	}
+	return &l
+}
+
+func decodeMemory(r *reader) *limits {
+	if r.count() != 1 {
+		fail("only one memory is supported")
+	}
+	l := r.limits()
+	if l.min > maxPages || (l.hasMax && l.max > maxPages) {
+		fail("memory size exceeds %d pages", maxPages)
+	}
+	return &l
+}
+
+func decodeGlobals(r *reader, m *Module) []global {
+	var globals []global
+	for n := r.count(); n > 0; n-- {
+		g := global{typ: r.valueType()}
+		switch mut := r.byte(); mut {
+		case 0:
+		case 1:
+			g.mutable = true
+		default:
+			fail("unexpected global mutability 0x%x", mut)
+		}
+		// a global may only be initialized from the globals before it
+		g.init = r.constExpr(g.typ, globals)
+		globals = append(globals, g)
+	}
+	return globals
+}
+
+func decodeExports(r *reader, m *Module) {
+	for n := r.count(); n > 0; n-- {
+		name := r.name()
+		exp := export{kind: r.byte(), index: r.u32()}
+		if _, ok := m.exports[name]; ok {
+			fail("duplicate export %s", name)
+		}
+
+		var ok bool
+		switch exp.kind {
+		case externalFunc:
+			ok = exp.index < m.numFuncs()
+		case externalTable:
+			ok = exp.index == 0 && m.table != nil
+		case externalMemory:
+			ok = exp.index == 0 && m.memory != nil
+		case externalGlobal:
+			ok = exp.index < uint32(len(m.globals))
+		default:
+			fail("unknown export kind 0x%x", exp.kind)
+		}
+		if !ok {
+			fail("export %s refers to unknown index %d", name, exp.index)
+		}
+		m.exports[name] = exp
+	}
+}
+
+func decodeElements(r *reader, m *Module) []element {
+	var elements []element
+	for n := r.count(); n > 0; n-- {
+		if flags := r.u32(); flags != 0 {
+			fail("unsupported element segment kind %d", flags)
+		}
+		if m.table == nil {
+			fail("element segment without table")
+		}
+		elem := element{offset: r.constExpr(I32, m.globals)}
+		for _, f := range r.vecU32() {
+			if f >= m.numFuncs() {
+				fail("element segment refers to unknown function %d", f)
+			}
+			elem.funcs = append(elem.funcs, f)
+		}
+		elements = append(elements, elem)
+	}
+	return elements
+}
+
+func decodeCode(r *reader, m *Module) {
+	n := r.count()
+	if int(n) != len(m.funcs) {
+		fail("%d functions declared but %d defined", len(m.funcs), n)
+	}
+	for i := range m.funcs {
+		body := &reader{buf: r.bytes(r.u32())}
+
+		fn := &m.funcs[i]
+		numLocals := uint64(len(m.types[fn.typ].Params))
+		for groups := body.count(); groups > 0; groups-- {
+			count := body.u32()
+			body.valueType()
+			numLocals += uint64(count)
+			if numLocals > maxLocals {
+				fail("function %d has more than %d locals", i, maxLocals)
+			}
+		}
+		fn.locals = int(numLocals) - len(m.types[fn.typ].Params)
+		fn.code = compileBody(body, m, int(numLocals))
+	}
+}
+
+func decodeData(r *reader, m *Module) []dataSegment {
+	var data []dataSegment
+	for n := r.count(); n > 0; n-- {
+		if flags := r.u32(); flags != 0 {
+			fail("unsupported data segment kind %d", flags)
+		}
+		if m.memory == nil {
+			fail("data segment without memory")
+		}
+		seg := dataSegment{offset: r.constExpr(I32, m.globals)}
+		seg.data = r.bytes(r.u32())
+		data = append(data, seg)
+	}
+	return data
+}
+
+// reader reads the WebAssembly binary format. Malformed input aborts
+// decoding with a decodeError.
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func (r *reader) done() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *reader) byte() byte {
+	if r.pos >= len(r.buf) {
+		fail("unexpected end of input")
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n uint32) []byte {
+	if uint64(n) > uint64(len(r.buf)-r.pos) {
+		fail("unexpected end of input")
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b
+}
+
+func (r *reader) name() string {
+	return string(r.bytes(r.u32()))
+}
+
+// count reads the length of a vector. As each element takes at least one
+// byte, a length beyond the remaining input is rejected before anything is
+// allocated for the elements.
+func (r *reader) count() uint32 {
+	n := r.u32()
+	if uint64(n) > uint64(len(r.buf)-r.pos) {
+		fail("vector length %d exceeds input", n)
+	}
+	return n
+}
+
+func (r *reader) vecU32() []uint32 {
+	var v []uint32
+	for n := r.count(); n > 0; n-- {
+		v = append(v, r.u32())
+	}
+	return v
+}
+
+func (r *reader) u32() uint32 {
+	var result uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 35 {
+			fail("integer representation too long")
+		}
+		b := r.byte()
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if result > 0xffffffff {
+		fail("integer too large")
+	}
+	return uint32(result)
+}
+
+func (r *reader) signed(size uint) int64 {
+	var result int64
+	var shift uint
+	for {
+		if shift >= size {
+			fail("integer representation too long")
+		}
+		b := r.byte()
+		result |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				result |= -1 << shift
+			}
+			return result
+		}
+	}
+}
+
+func (r *reader) s32() int32 {
+	return int32(r.signed(32))
+}
+
+func (r *reader) s64() int64 {
+	return r.signed(64)
+}
+
+func (r *reader) valueType() ValueType {
+	t := ValueType(r.byte())
+	switch t {
+	case I32, I64:
+		return t
+	case f32, f64:
+		fail("floating point type %s is not supported", t)
+	default:
+		fail("unknown value type 0x%x", byte(t))
+	}
+	return 0
+}
+
+func (r *reader) valueTypes() []ValueType {
+	var types []ValueType
+	for n := r.count(); n > 0; n-- {
+		types = append(types, r.valueType())
+	}
+	return types
+}
+
+func (r *reader) limits() limits {
+	var l limits
+	switch flag := r.byte(); flag {
+	case 0:
+		l.min = r.u32()
+	case 1:
+		l.min, l.max, l.hasMax = r.u32(), r.u32(), true
+		if l.max < l.min {
+			fail("maximum %d less than minimum %d", l.max, l.min)
+		}
+	default:
+		fail("unexpected limits flag 0x%x", flag)
+	}
+	return l
+}
+
+// constExpr reads a constant expression of the given type which may refer
+// to the immutable globals given.
+func (r *reader) constExpr(typ ValueType, globals []global) constExpr {
+	var expr constExpr
+	switch expr.op = r.byte(); expr.op {
+	case opI32Const:
+		expr.value = uint64(uint32(r.s32()))
+		if typ != I32 {
+			fail("constant expression of type i32 where %s expected", typ)
+		}
+	case opI64Const:
+		expr.value = uint64(r.s64())
+		if typ != I64 {
+			fail("constant expression of type i64 where %s expected", typ)
+		}
+	case opGlobalGet:
+		expr.value = uint64(r.u32())
+		if expr.value >= uint64(len(globals)) {
+			fail("constant expression refers to unknown global %d", expr.value)
+		}
+		if g := globals[expr.value]; g.mutable || g.typ != typ {
+			fail("constant expression refers to global %d of wrong type or mutability", expr.value)
+		}
+	default:
+		fail("unsupported constant expression 0x%x", expr.op)
+	}
+	if end := r.byte(); end != opEnd {
+		fail("constant expression not terminated")
+	}
+	return expr
+}
@@ -0,0 +1,612 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The helpers below assemble modules in the binary format.
+
+func leb(v uint64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if v == 0 {
+			return b
+		}
+	}
+}
+
+func sleb(v int64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func cat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func vec(items ...[]byte) []byte {
+	return cat(leb(uint64(len(items))), cat(items...))
+}
+
+func str(s string) []byte {
+	return cat(leb(uint64(len(s))), []byte(s))
+}
+
+func rawSection(id byte, content []byte) []byte {
+	return cat([]byte{id}, leb(uint64(len(content))), content)
+}
+
+func section(id byte, items ...[]byte) []byte {
+	return rawSection(id, vec(items...))
+}
+
+func module(sections ...[]byte) []byte {
+	return cat([]byte("\x00asm\x01\x00\x00\x00"), cat(sections...))
+}
+
+func funcType(params, results []ValueType) []byte {
+	var p, r []byte
+	for _, t := range params {
+		p = append(p, byte(t))
+	}
+	for _, t := range results {
+		r = append(r, byte(t))
+	}
+	return cat([]byte{0x60}, leb(uint64(len(p))), p, leb(uint64(len(r))), r)
+}
+
+// body encodes a function body with a number of i64 locals.
+func body(i64Locals int, code ...[]byte) []byte {
+	locals := []byte{0}
+	if i64Locals > 0 {
+		locals = cat([]byte{1}, leb(uint64(i64Locals)), []byte{byte(I64)})
+	}
+	b := cat(locals, cat(code...), []byte{opEnd})
+	return cat(leb(uint64(len(b))), b)
+}
+
+func exportFunc(name string, index uint32) []byte {
+	return cat(str(name), []byte{externalFunc}, leb(uint64(index)))
+}
+
+func op(ops ...byte) []byte {
+	return ops
+}
+
+func i32c(v int32) []byte {
+	return cat([]byte{opI32Const}, sleb(int64(v)))
+}
+
+func i64c(v int64) []byte {
+	return cat([]byte{opI64Const}, sleb(v))
+}
+
+func local(op byte, index uint32) []byte {
+	return cat([]byte{op}, leb(uint64(index)))
+}
+
+var (
+	i32  = []ValueType{I32}
+	i64  = []ValueType{I64}
+	none = []ValueType{}
+)
+
+// singleFunc returns a module exporting one function as "f".
+func singleFunc(params, results []ValueType, locals int, code ...[]byte) []byte {
+	return module(
+		section(1, funcType(params, results)),
+		section(3, leb(0)),
+		section(7, exportFunc("f", 0)),
+		section(10, body(locals, code...)),
+	)
+}
+
+func instantiate(t *testing.T, code []byte, config Config) *Instance {
+	m, err := Compile(code)
+	require.NoError(t, err)
+	inst, err := Instantiate(m, nil, config)
+	require.NoError(t, err)
+	return inst
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   []byte
+		errMsg string
+	}{
+		{
+			name:   "missing magic",
+			code:   []byte("\x00elf\x01\x00\x00\x00"),
+			errMsg: "invalid wasm module: missing magic number",
+		},
+		{
+			name:   "unsupported version",
+			code:   []byte("\x00asm\x02\x00\x00\x00"),
+			errMsg: "invalid wasm module: unsupported version 2",
+		},
+		{
+			name:   "truncated",
+			code:   module(section(1, funcType(i32, i32)))[:12],
+			errMsg: "invalid wasm module: unexpected end of input",
+		},
+		{
+			name:   "float parameter",
+			code:   module(section(1, funcType([]ValueType{f64}, none))),
+			errMsg: "invalid wasm module: floating point type f64 is not supported",
+		},
+		{
+			name:   "float instruction",
+			code:   singleFunc(none, none, 0, op(0x43, 0, 0, 0, 0, opDrop)),
+			errMsg: "invalid wasm module: floating point instruction 0x43 is not supported",
+		},
+		{
+			name:   "saturating truncation",
+			code:   singleFunc(none, none, 0, op(opPrefix, 0)),
+			errMsg: "invalid wasm module: floating point instruction 0xfc 0 is not supported",
+		},
+		{
+			name:   "memory import",
+			code:   module(section(2, cat(str("env"), str("memory"), []byte{externalMemory, 0, 1}))),
+			errMsg: "invalid wasm module: import env.memory is not a function, only functions may be imported",
+		},
+		{
+			name:   "sections out of order",
+			code:   module(section(3), section(1)),
+			errMsg: "invalid wasm module: section 1 out of order",
+		},
+		{
+			name:   "unknown local",
+			code:   singleFunc(none, none, 0, local(opLocalGet, 0), op(opDrop)),
+			errMsg: "invalid wasm module: unknown local 0",
+		},
+		{
+			name:   "unknown label",
+			code:   singleFunc(none, none, 0, op(opBr, 1)),
+			errMsg: "invalid wasm module: unknown label 1",
+		},
+		{
+			name:   "memory instruction without memory",
+			code:   singleFunc(none, i32, 0, op(opMemorySize, 0)),
+			errMsg: "invalid wasm module: memory instruction without memory",
+		},
+		{
+			name:   "function without body",
+			code:   module(section(1, funcType(none, none)), section(3, leb(0))),
+			errMsg: "invalid wasm module: 1 functions declared but none defined",
+		},
+		{
+			name:   "too many locals",
+			code:   singleFunc(none, none, maxLocals+1),
+			errMsg: "invalid wasm module: function 0 has more than 50000 locals",
+		},
+		{
+			name:   "huge vector",
+			code:   module(rawSection(1, leb(math.MaxUint32))),
+			errMsg: "invalid wasm module: vector length 4294967295 exceeds input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.code)
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	binary := func(t ValueType, code byte) []byte {
+		return singleFunc([]ValueType{t, t}, []ValueType{t}, 0, local(opLocalGet, 0), local(opLocalGet, 1), op(code))
+	}
+	compare := func(t ValueType, code byte) []byte {
+		return singleFunc([]ValueType{t, t}, i32, 0, local(opLocalGet, 0), local(opLocalGet, 1), op(code))
+	}
+	unary := func(t, r ValueType, code byte) []byte {
+		return singleFunc([]ValueType{t}, []ValueType{r}, 0, local(opLocalGet, 0), op(code))
+	}
+	minus := func(v int64) uint64 { return uint64(v) }
+	minus32 := func(v int32) uint64 { return uint64(uint32(v)) }
+
+	tests := []struct {
+		name     string
+		code     []byte
+		args     []uint64
+		expected uint64
+		errMsg   string
+	}{
+		{name: "i32.add wraps", code: binary(I32, opI32Add), args: []uint64{math.MaxUint32, 2}, expected: 1},
+		{name: "i32.sub", code: binary(I32, opI32Sub), args: []uint64{1, 2}, expected: math.MaxUint32},
+		{name: "i32.mul", code: binary(I32, opI32Mul), args: []uint64{6, 7}, expected: 42},
+		{name: "i32.div_s", code: binary(I32, opI32DivS), args: []uint64{minus32(-7), 2}, expected: minus32(-3)},
+		{name: "i32.div_s by zero", code: binary(I32, opI32DivS), args: []uint64{1, 0}, errMsg: "integer divide by zero"},
+		{name: "i32.div_s overflow", code: binary(I32, opI32DivS), args: []uint64{minus32(math.MinInt32), minus32(-1)}, errMsg: "integer overflow"},
+		{name: "i32.div_u", code: binary(I32, opI32DivU), args: []uint64{minus32(-7), 2}, expected: 0x7ffffffc},
+		{name: "i32.rem_s", code: binary(I32, opI32RemS), args: []uint64{minus32(-7), 2}, expected: minus32(-1)},
+		{name: "i32.rem_s of minimum", code: binary(I32, opI32RemS), args: []uint64{minus32(math.MinInt32), minus32(-1)}, expected: 0},
+		{name: "i32.rem_u by zero", code: binary(I32, opI32RemU), args: []uint64{1, 0}, errMsg: "integer divide by zero"},
+		{name: "i32.and", code: binary(I32, opI32And), args: []uint64{0xff00, 0x0ff0}, expected: 0x0f00},
+		{name: "i32.or", code: binary(I32, opI32Or), args: []uint64{0xff00, 0x0ff0}, expected: 0xfff0},
+		{name: "i32.xor", code: binary(I32, opI32Xor), args: []uint64{0xff00, 0x0ff0}, expected: 0xf0f0},
+		{name: "i32.shl masks shift", code: binary(I32, opI32Shl), args: []uint64{1, 33}, expected: 2},
+		{name: "i32.shr_s", code: binary(I32, opI32ShrS), args: []uint64{minus32(-8), 1}, expected: minus32(-4)},
+		{name: "i32.shr_u", code: binary(I32, opI32ShrU), args: []uint64{minus32(-8), 1}, expected: 0x7ffffffc},
+		{name: "i32.rotl", code: binary(I32, opI32Rotl), args: []uint64{0x80000001, 1}, expected: 3},
+		{name: "i32.rotr", code: binary(I32, opI32Rotr), args: []uint64{3, 1}, expected: 0x80000001},
+		{name: "i32.lt_s", code: compare(I32, opI32LtS), args: []uint64{minus32(-1), 0}, expected: 1},
+		{name: "i32.lt_u", code: compare(I32, opI32LtU), args: []uint64{minus32(-1), 0}, expected: 0},
+		{name: "i32.ge_s", code: compare(I32, opI32GeS), args: []uint64{5, 5}, expected: 1},
+		{name: "i32.eqz", code: unary(I32, I32, opI32Eqz), args: []uint64{0}, expected: 1},
+		{name: "i32.clz", code: unary(I32, I32, opI32Clz), args: []uint64{1}, expected: 31},
+		{name: "i32.ctz", code: unary(I32, I32, opI32Ctz), args: []uint64{0}, expected: 32},
+		{name: "i32.popcnt", code: unary(I32, I32, opI32Popcnt), args: []uint64{0xf0f0}, expected: 8},
+		{name: "i32.extend8_s", code: unary(I32, I32, opI32Extend8S), args: []uint64{0x80}, expected: 0xffffff80},
+		{name: "i32.extend16_s", code: unary(I32, I32, opI32Extend16S), args: []uint64{0x7fff}, expected: 0x7fff},
+		{name: "i64.add", code: binary(I64, opI64Add), args: []uint64{math.MaxUint64, 2}, expected: 1},
+		{name: "i64.mul", code: binary(I64, opI64Mul), args: []uint64{1 << 32, 1 << 31}, expected: 1 << 63},
+		{name: "i64.div_s", code: binary(I64, opI64DivS), args: []uint64{minus(-9), 2}, expected: minus(-4)},
+		{name: "i64.div_s overflow", code: binary(I64, opI64DivS), args: []uint64{minus(math.MinInt64), minus(-1)}, errMsg: "integer overflow"},
+		{name: "i64.div_u by zero", code: binary(I64, opI64DivU), args: []uint64{1, 0}, errMsg: "integer divide by zero"},
+		{name: "i64.rem_s", code: binary(I64, opI64RemS), args: []uint64{minus(-9), 2}, expected: minus(-1)},
+		{name: "i64.shr_s", code: binary(I64, opI64ShrS), args: []uint64{minus(-16), 65}, expected: minus(-8)},
+		{name: "i64.rotl", code: binary(I64, opI64Rotl), args: []uint64{1 << 63, 1}, expected: 1},
+		{name: "i64.gt_u", code: compare(I64, opI64GtU), args: []uint64{minus(-1), 1}, expected: 1},
+		{name: "i64.le_s", code: compare(I64, opI64LeS), args: []uint64{minus(-1), 1}, expected: 1},
+		{name: "i64.eqz", code: unary(I64, I32, opI64Eqz), args: []uint64{1}, expected: 0},
+		{name: "i64.clz", code: unary(I64, I64, opI64Clz), args: []uint64{1}, expected: 63},
+		{name: "i64.extend32_s", code: unary(I64, I64, opI64Extend32S), args: []uint64{0x80000000}, expected: 0xffffffff80000000},
+		{name: "i32.wrap_i64", code: unary(I64, I32, opI32WrapI64), args: []uint64{0x100000002}, expected: 2},
+		{name: "i64.extend_i32_s", code: unary(I32, I64, opI64ExtendI32S), args: []uint64{0xffffffff}, expected: math.MaxUint64},
+		{name: "i64.extend_i32_u", code: unary(I32, I64, opI64ExtendI32U), args: []uint64{0xffffffff}, expected: 0xffffffff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := instantiate(t, tt.code, Config{})
+			results, err := inst.Call("f", tt.args...)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []uint64{tt.expected}, results)
+		})
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	t.Run("loop", func(t *testing.T) {
+		// sums the numbers from 1 to n
+		code := singleFunc(i64, i64, 1,
+			op(opBlock, 0x40, opLoop, 0x40),
+			local(opLocalGet, 0), op(opI64Eqz, opBrIf, 1),
+			local(opLocalGet, 1), local(opLocalGet, 0), op(opI64Add), local(opLocalSet, 1),
+			local(opLocalGet, 0), i64c(1), op(opI64Sub), local(opLocalSet, 0),
+			op(opBr, 0, opEnd, opEnd),
+			local(opLocalGet, 1),
+		)
+		inst := instantiate(t, code, Config{})
+		results, err := inst.Call("f", 100)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{5050}, results)
+	})
+
+	t.Run("recursion", func(t *testing.T) {
+		// factorial
+		code := singleFunc(i64, i64, 0,
+			local(opLocalGet, 0), i64c(2), op(opI64LtU, opIf, byte(I64)),
+			i64c(1),
+			op(opElse),
+			local(opLocalGet, 0), local(opLocalGet, 0), i64c(1), op(opI64Sub, opCall, 0, opI64Mul),
+			op(opEnd),
+		)
+		inst := instantiate(t, code, Config{})
+		results, err := inst.Call("f", 20)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2432902008176640000}, results)
+	})
+
+	t.Run("br_table", func(t *testing.T) {
+		code := singleFunc(i32, i32, 0,
+			op(opBlock, 0x40, opBlock, 0x40, opBlock, 0x40),
+			local(opLocalGet, 0), op(opBrTable, 2, 0, 1, 2),
+			op(opEnd), i32c(10), op(opReturn),
+			op(opEnd), i32c(11), op(opReturn),
+			op(opEnd), i32c(12),
+		)
+		inst := instantiate(t, code, Config{})
+		for arg, expected := range []uint64{10, 11, 12, 12} {
+			results, err := inst.Call("f", uint64(arg))
+			require.NoError(t, err)
+			assert.Equal(t, []uint64{expected}, results, "argument %d", arg)
+		}
+	})
+
+	t.Run("block with parameters", func(t *testing.T) {
+		code := module(
+			section(1, funcType(i32, i32), funcType([]ValueType{I32, I32}, i32)),
+			section(3, leb(0)),
+			section(7, exportFunc("f", 0)),
+			section(10, body(0,
+				local(opLocalGet, 0), i32c(3),
+				op(opBlock, 1, opI32Mul, opEnd),
+				i32c(1), op(opI32Add),
+			)),
+		)
+		inst := instantiate(t, code, Config{})
+		results, err := inst.Call("f", 5)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{16}, results)
+	})
+
+	t.Run("br out of nested block with value", func(t *testing.T) {
+		code := singleFunc(none, i32, 0,
+			op(opBlock, byte(I32)),
+			i32c(1), i32c(2),
+			op(opBlock, 0x40), i32c(7), op(opBr, 1, opEnd),
+			op(opDrop, opDrop, opEnd),
+		)
+		inst := instantiate(t, code, Config{})
+		results, err := inst.Call("f")
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{7}, results)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		code := singleFunc(i32, i32, 0, i32c(1), i32c(2), local(opLocalGet, 0), op(opSelect))
+		inst := instantiate(t, code, Config{})
+		results, err := inst.Call("f", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2}, results)
+		results, err = inst.Call("f", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1}, results)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		inst := instantiate(t, singleFunc(none, none, 0, op(opUnreachable)), Config{})
+		_, err := inst.Call("f")
+		assert.EqualError(t, err, "unreachable executed")
+	})
+}
+
+func TestMemory(t *testing.T) {
+	code := module(
+		section(1, funcType([]ValueType{I32, I64}, none), funcType(i32, i64), funcType(i32, i32), funcType([]ValueType{I32, I32, I32}, none)),
+		section(3, leb(0), leb(1), leb(2), leb(2), leb(3), leb(3)),
+		section(5, []byte{1, 1, 3}),
+		section(7,
+			exportFunc("store", 0), exportFunc("load", 1), exportFunc("load8_s", 2),
+			exportFunc("grow", 3), exportFunc("copy", 4), exportFunc("fill", 5),
+		),
+		section(10,
+			body(0, local(opLocalGet, 0), local(opLocalGet, 1), op(opI64Store, 3, 0)),
+			body(0, local(opLocalGet, 0), op(opI64Load, 3, 0)),
+			body(0, local(opLocalGet, 0), op(opI32Load8S, 0, 4)),
+			body(0, local(opLocalGet, 0), op(opMemoryGrow, 0)),
+			body(0, local(opLocalGet, 0), local(opLocalGet, 1), local(opLocalGet, 2), op(opPrefix, 10, 0, 0)),
+			body(0, local(opLocalGet, 0), local(opLocalGet, 1), local(opLocalGet, 2), op(opPrefix, 11, 0)),
+		),
+		section(11, cat(leb(0), i32c(16), op(opEnd), str("hello"))),
+	)
+	inst := instantiate(t, code, Config{MaxMemoryPages: 2})
+
+	data, err := inst.Read(16, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+
+	_, err = inst.Call("store", 8, 0x1122334455667788)
+	require.NoError(t, err)
+	results, err := inst.Call("load", 8)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0x1122334455667788}, results)
+
+	require.NoError(t, inst.Write(100, []byte{0xfe}))
+	results, err = inst.Call("load8_s", 96)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0xfffffffe}, results)
+
+	_, err = inst.Call("load", PageSize-4)
+	assert.EqualError(t, err, "out of bounds memory access")
+	_, err = inst.Read(PageSize-4, 8)
+	assert.EqualError(t, err, "out of bounds memory access at 65532, length 8")
+
+	results, err = inst.Call("grow", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{math.MaxUint32}, results, "growing beyond the limit fails")
+	results, err = inst.Call("grow", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, results)
+	_, err = inst.Call("load", PageSize-4)
+	assert.NoError(t, err)
+
+	_, err = inst.Call("copy", 200, 16, 5)
+	require.NoError(t, err)
+	_, err = inst.Call("fill", 202, 'x', 2)
+	require.NoError(t, err)
+	data, err = inst.Read(200, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hexxo"), data)
+
+	_, err = inst.Call("copy", 2*PageSize-2, 16, 5)
+	assert.EqualError(t, err, "out of bounds memory access")
+
+	t.Run("memory larger than limit", func(t *testing.T) {
+		m, err := Compile(module(section(5, []byte{0, 3})))
+		require.NoError(t, err)
+		_, err = Instantiate(m, nil, Config{MaxMemoryPages: 2})
+		assert.EqualError(t, err, "module requires 3 pages of memory, limit is 2")
+	})
+}
+
+func TestGas(t *testing.T) {
+	loop := singleFunc(none, none, 0, op(opLoop, 0x40, opBr, 0, opEnd))
+
+	inst := instantiate(t, loop, Config{Gas: 1000})
+	_, err := inst.Call("f")
+	assert.Equal(t, ErrOutOfGas, err)
+	assert.Equal(t, uint64(1000), inst.GasUsed())
+
+	inst = instantiate(t, singleFunc(none, i32, 0, i32c(1), i32c(2), op(opI32Add)), Config{Gas: 1000})
+	_, err = inst.Call("f")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), inst.GasUsed())
+
+	assert.NoError(t, inst.UseGas(990))
+	assert.Equal(t, ErrOutOfGas, inst.UseGas(7))
+	assert.Equal(t, uint64(1000), inst.GasUsed())
+}
+
+func TestCallDepth(t *testing.T) {
+	code := singleFunc(none, none, 0, op(opCall, 0))
+	inst := instantiate(t, code, Config{MaxCallDepth: 100})
+	_, err := inst.Call("f")
+	assert.EqualError(t, err, "call stack exhausted")
+}
+
+func TestHostFunctions(t *testing.T) {
+	code := module(
+		section(1, funcType(i32, i32)),
+		section(2, cat(str("env"), str("double"), []byte{externalFunc, 0})),
+		section(3, leb(0)),
+		section(7, exportFunc("f", 1)),
+		section(10, body(0, local(opLocalGet, 0), op(opCall, 0), i32c(1), op(opI32Add))),
+	)
+	m, err := Compile(code)
+	require.NoError(t, err)
+	assert.Equal(t, []Import{{Module: "env", Name: "double", Type: FuncType{Params: i32, Results: i32}}}, m.Imports())
+	ft, ok := m.ExportedFunc("f")
+	assert.True(t, ok)
+	assert.Equal(t, FuncType{Params: i32, Results: i32}, ft)
+
+	double := HostFunction{
+		Type: FuncType{Params: i32, Results: i32},
+		Func: func(inst *Instance, args []uint64) ([]uint64, error) {
+			if args[0] == 0 {
+				return nil, errors.New("zero")
+			}
+			return []uint64{args[0] * 2}, inst.UseGas(10)
+		},
+	}
+
+	inst, err := Instantiate(m, Imports{"env": {"double": double}}, Config{})
+	require.NoError(t, err)
+	results, err := inst.Call("f", 21)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{43}, results)
+	assert.Equal(t, uint64(15), inst.GasUsed())
+
+	_, err = inst.Call("f", 0)
+	assert.EqualError(t, err, "zero")
+
+	_, err = Instantiate(m, Imports{}, Config{})
+	assert.EqualError(t, err, "unknown import env.double")
+
+	double.Type.Results = i64
+	_, err = Instantiate(m, Imports{"env": {"double": double}}, Config{})
+	assert.EqualError(t, err, "import env.double has wrong signature")
+}
+
+func TestCallIndirect(t *testing.T) {
+	code := module(
+		section(1, funcType(none, i32), funcType(i32, i32), funcType(i32, none)),
+		section(3, leb(0), leb(0), leb(1), leb(2)),
+		section(4, []byte{0x70, 0, 3}),
+		section(7, exportFunc("call", 2), exportFunc("mismatch", 3)),
+		section(9, cat(leb(0), i32c(0), op(opEnd), vec(leb(0), leb(1)))),
+		section(10,
+			body(0, i32c(1)),
+			body(0, i32c(2)),
+			body(0, local(opLocalGet, 0), op(opCallIndirect, 0, 0)),
+			body(0, local(opLocalGet, 0), op(opCallIndirect, 1, 0, opDrop)),
+		),
+	)
+	inst := instantiate(t, code, Config{})
+
+	results, err := inst.Call("call", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2}, results)
+
+	_, err = inst.Call("call", 2)
+	assert.EqualError(t, err, "undefined element 2")
+	_, err = inst.Call("call", 5)
+	assert.EqualError(t, err, "undefined element 5")
+	_, err = inst.Call("mismatch", 0)
+	assert.EqualError(t, err, "indirect call type mismatch")
+}
+
+func TestGlobalsAndStart(t *testing.T) {
+	code := module(
+		section(1, funcType(none, none), funcType(none, i64)),
+		section(3, leb(0), leb(1)),
+		section(6,
+			cat([]byte{byte(I64), 0}, i64c(40), op(opEnd)),
+			cat([]byte{byte(I64), 1}, op(opGlobalGet, 0, opEnd)),
+		),
+		section(7, exportFunc("get", 1)),
+		rawSection(8, leb(0)),
+		section(10,
+			body(0, op(opGlobalGet, 1), i64c(2), op(opI64Add, opGlobalSet, 1)),
+			body(0, op(opGlobalGet, 1)),
+		),
+	)
+	inst := instantiate(t, code, Config{})
+	results, err := inst.Call("get")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{42}, results)
+
+	_, err = inst.Call("missing")
+	assert.EqualError(t, err, "function missing is not exported")
+	_, err = inst.Call("get", 1)
+	assert.EqualError(t, err, "function get expects 0 arguments, got 1")
+
+	t.Run("failing start function", func(t *testing.T) {
+		code := module(
+			section(1, funcType(none, none)),
+			section(3, leb(0)),
+			rawSection(8, leb(0)),
+			section(10, body(0, op(opUnreachable))),
+		)
+		m, err := Compile(code)
+		require.NoError(t, err)
+		_, err = Instantiate(m, nil, Config{})
+		assert.EqualError(t, err, "start function failed: unreachable executed")
+	})
+}
+
+func TestMalformedExecution(t *testing.T) {
+	// the stack of the function underflows, which is not detected when the
+	// module is compiled
+	inst := instantiate(t, singleFunc(none, none, 0, op(opI32Add, opDrop)), Config{})
+	_, err := inst.Call("f")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid execution")
+}
@@ -0,0 +1,223 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package wasmtest assembles WebAssembly modules in the binary format for
+// use in tests.
+package wasmtest
+
+// Value types.
+const (
+	I32 byte = 0x7f
+	I64 byte = 0x7e
+)
+
+// Instructions without immediates.
+const (
+	Unreachable byte = 0x00
+	Else        byte = 0x05
+	End         byte = 0x0b
+	Return      byte = 0x0f
+	Drop        byte = 0x1a
+	I32Eqz      byte = 0x45
+	I32Eq       byte = 0x46
+	I32Ne       byte = 0x47
+	I32LtS      byte = 0x48
+	I32Add      byte = 0x6a
+	I32Sub      byte = 0x6b
+)
+
+// Import is a function imported by a module.
+type Import struct {
+	Module  string
+	Name    string
+	Params  []byte
+	Results []byte
+}
+
+// Func is a function defined by a module. Locals are of type i32.
+type Func struct {
+	Export  string
+	Params  []byte
+	Results []byte
+	Locals  int
+	Code    []byte
+}
+
+// Data initializes memory at an offset.
+type Data struct {
+	Offset int32
+	Bytes  []byte
+}
+
+// Module describes a module. Imported functions are indexed before the
+// functions defined by the module.
+type Module struct {
+	Imports []Import
+	Funcs   []Func
+	// Memory is the initial number of pages of memory. Memory is exported
+	// as "memory" if it is not zero.
+	Memory uint32
+	Data   []Data
+}
+
+// Bytes encodes the module in the binary format.
+func (m *Module) Bytes() []byte {
+	var types, imports, funcs, exports, code, data [][]byte
+	for i, imp := range m.Imports {
+		types = append(types, funcType(imp.Params, imp.Results))
+		imports = append(imports, cat(str(imp.Module), str(imp.Name), []byte{0}, leb(uint64(i))))
+	}
+	for i, f := range m.Funcs {
+		typ := uint64(len(types))
+		types = append(types, funcType(f.Params, f.Results))
+		funcs = append(funcs, leb(typ))
+		if f.Export != "" {
+			exports = append(exports, cat(str(f.Export), []byte{0}, leb(uint64(len(m.Imports)+i))))
+		}
+		locals := []byte{0}
+		if f.Locals > 0 {
+			locals = cat([]byte{1}, leb(uint64(f.Locals)), []byte{I32})
+		}
+		b := cat(locals, f.Code, []byte{End})
+		code = append(code, cat(leb(uint64(len(b))), b))
+	}
+	var memory [][]byte
+	if m.Memory > 0 {
+		memory = append(memory, cat([]byte{0}, leb(uint64(m.Memory))))
+		exports = append(exports, cat(str("memory"), []byte{2}, leb(0)))
+	}
+	for _, d := range m.Data {
+		data = append(data, cat(leb(0), I32Const(d.Offset), []byte{End}, str(string(d.Bytes))))
+	}
+
+	return cat(
+		[]byte("\x00asm\x01\x00\x00\x00"),
+		section(1, types),
+		section(2, imports),
+		section(3, funcs),
+		section(5, memory),
+		section(7, exports),
+		section(10, code),
+		section(11, data),
+	)
+}
+
+// Code concatenates instructions. Each part must be a byte or a []byte.
+func Code(parts ...interface{}) []byte {
+	var b []byte
+	for _, p := range parts {
+		switch p := p.(type) {
+		case byte:
+			b = append(b, p)
+		case []byte:
+			b = append(b, p...)
+		default:
+			panic("instructions must be bytes")
+		}
+	}
+	return b
+}
+
+// I32Const pushes a constant.
+func I32Const(v int32) []byte {
+	return cat([]byte{0x41}, sleb(int64(v)))
+}
+
+// LocalGet pushes a local.
+func LocalGet(index uint32) []byte {
+	return cat([]byte{0x20}, leb(uint64(index)))
+}
+
+// LocalSet pops into a local.
+func LocalSet(index uint32) []byte {
+	return cat([]byte{0x21}, leb(uint64(index)))
+}
+
+// LocalTee sets a local without popping.
+func LocalTee(index uint32) []byte {
+	return cat([]byte{0x22}, leb(uint64(index)))
+}
+
+// Call calls a function.
+func Call(index uint32) []byte {
+	return cat([]byte{0x10}, leb(uint64(index)))
+}
+
+// Block starts a block without results.
+func Block() []byte {
+	return []byte{0x02, 0x40}
+}
+
+// Loop starts a loop without results.
+func Loop() []byte {
+	return []byte{0x03, 0x40}
+}
+
+// If starts an if without results.
+func If() []byte {
+	return []byte{0x04, 0x40}
+}
+
+// Br branches to an enclosing label.
+func Br(depth uint32) []byte {
+	return cat([]byte{0x0c}, leb(uint64(depth)))
+}
+
+// BrIf branches to an enclosing label if the popped value is not zero.
+func BrIf(depth uint32) []byte {
+	return cat([]byte{0x0d}, leb(uint64(depth)))
+}
+
+func section(id byte, items [][]byte) []byte {
+	if len(items) == 0 {
+		return nil
+	}
+	content := cat(leb(uint64(len(items))), cat(items...))
+	return cat([]byte{id}, leb(uint64(len(content))), content)
+}
+
+func funcType(params, results []byte) []byte {
+	return cat([]byte{0x60}, leb(uint64(len(params))), params, leb(uint64(len(results))), results)
+}
+
+func str(s string) []byte {
+	return cat(leb(uint64(len(s))), []byte(s))
+}
+
+func cat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func leb(v uint64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if v == 0 {
+			return b
+		}
+	}
+}
+
+func sleb(v int64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
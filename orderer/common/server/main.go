@@ -738,9 +738,38 @@ func initializeMultichannelRegistrar(
 	// closes if we wished to cleanup this routine on exit.
 	go kafkaMetrics.PollGoMetricsUntilStop(time.Minute, nil)
 	registrar.Initialize(consenters)
+
+	channelConfigChecker := &channelconfig.LintHealthChecker{
+		Channels: func() map[string]channelconfig.Resources {
+			return channelConfigs(registrar)
+		},
+		MSPID:   conf.General.LocalMSPID,
+		TLSCert: srvConf.SecOpts.Certificate,
+	}
+	if err := healthChecker.RegisterChecker("channelconfig", channelConfigChecker); err != nil {
+		logger.Panicf("Failed to register channel config health check: %s", err)
+	}
 	return registrar
 }
 
+// channelConfigs returns the config of every channel served by the
+// registrar by channel ID
+func channelConfigs(registrar *multichannel.Registrar) map[string]channelconfig.Resources {
+	list := registrar.ChannelList()
+	channels := list.Channels
+	if list.SystemChannel != nil {
+		channels = append(channels, *list.SystemChannel)
+	}
+
+	configs := map[string]channelconfig.Resources{}
+	for _, channel := range channels {
+		if cs := registrar.GetChain(channel.Name); cs != nil {
+			configs[channel.Name] = cs
+		}
+	}
+	return configs
+}
+
 func initializeEtcdraftConsenter(
 	consenters map[string]consensus.Consenter,
 	conf *localconfig.TopLevel,
@@ -44,14 +44,14 @@ generateHelpText \
         docs/wrappers/license_postscript.md \
         "${commands[@]}"
 
-commands=("peer chaincode install" "peer chaincode instantiate" "peer chaincode invoke" "peer chaincode list" "peer chaincode package" "peer chaincode query" "peer chaincode signpackage" "peer chaincode upgrade")
+commands=("peer chaincode install" "peer chaincode instantiate" "peer chaincode invoke" "peer chaincode list" "peer chaincode package" "peer chaincode query" "peer chaincode replay" "peer chaincode signpackage" "peer chaincode upgrade")
 generateHelpText \
         docs/source/commands/peerchaincode.md \
         docs/wrappers/peer_chaincode_preamble.md \
         docs/wrappers/peer_chaincode_postscript.md \
         "${commands[@]}"
 
-commands=("peer lifecycle" "peer lifecycle chaincode" "peer lifecycle chaincode package" "peer lifecycle chaincode install" "peer lifecycle chaincode queryinstalled" "peer lifecycle chaincode getinstalledpackage" "peer lifecycle chaincode approveformyorg" "peer lifecycle chaincode queryapproved" "peer lifecycle chaincode checkcommitreadiness" "peer lifecycle chaincode commit" "peer lifecycle chaincode querycommitted")
+commands=("peer lifecycle" "peer lifecycle chaincode" "peer lifecycle chaincode package" "peer lifecycle chaincode sign" "peer lifecycle chaincode install" "peer lifecycle chaincode queryinstalled" "peer lifecycle chaincode getinstalledpackage" "peer lifecycle chaincode approveformyorg" "peer lifecycle chaincode queryapproved" "peer lifecycle chaincode checkcommitreadiness" "peer lifecycle chaincode commit" "peer lifecycle chaincode querycommitted" "peer lifecycle chaincode explainpolicy")
 generateHelpText \
         docs/source/commands/peerlifecycle.md \
         docs/wrappers/peer_lifecycle_chaincode_preamble.md \
@@ -65,7 +65,7 @@ generateHelpText \
         docs/wrappers/peer_channel_postscript.md \
         "${commands[@]}"
 
-commands=("peer node start" "peer node reset" "peer node rollback")
+commands=("peer node start" "peer node reset" "peer node rollback" "peer node pvtdata status" "peer node pvtdata removal" "peer node pvtdata accesslog" "peer node pvtdata genkey" "peer node gossip")
 generateHelpText \
         docs/source/commands/peernode.md \
         docs/wrappers/peer_node_preamble.md \
@@ -86,7 +86,7 @@ generateHelpText \
         docs/wrappers/cryptogen_postscript.md \
         "${commands[@]}"
 
-commands=("configtxlator start" "configtxlator proto_encode" "configtxlator proto_decode" "configtxlator compute_update" "configtxlator version")
+commands=("configtxlator start" "configtxlator proto_encode" "configtxlator proto_decode" "configtxlator compute_update" "configtxlator policy list" "configtxlator policy evaluate" "configtxlator policy inquire" "configtxlator edit add_org" "configtxlator edit remove_org" "configtxlator edit anchor_peers" "configtxlator edit batch" "configtxlator edit add_consenter" "configtxlator edit remove_consenter" "configtxlator edit capabilities" "configtxlator lint" "configtxlator version")
 generateHelpText \
         docs/source/commands/configtxlator.md \
         docs/wrappers/configtxlator_preamble.md \